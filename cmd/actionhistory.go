@@ -0,0 +1,186 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/keep-network/keep-core/config"
+	"github.com/keep-network/keep-core/internal/hexutils"
+	"github.com/keep-network/keep-core/pkg/storage"
+	"github.com/keep-network/keep-core/pkg/tbtc"
+)
+
+var (
+	// ActionHistoryCommand:
+	jsonFlagName = "json"
+)
+
+// ActionHistoryCommand contains the definition of the action-history
+// command-line subcommand.
+var ActionHistoryCommand = &cobra.Command{
+	Use:   "action-history",
+	Short: "Prints the wallet action history",
+	Long: "Reads the history of wallet actions executed by the node from " +
+		"the local storage, verifies its hash chain, and prints it. " +
+		"The command exits with an error if the history has been tampered with.",
+	PreRun: func(cmd *cobra.Command, args []string) {
+		if err := clientConfig.ReadConfig(
+			configFilePath,
+			cmd.Flags(),
+			config.Storage,
+		); err != nil {
+			logger.Fatalf("error reading config: %v", err)
+		}
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		wallet, err := cmd.Flags().GetString(walletFlagName)
+		if err != nil {
+			return fmt.Errorf("failed to find wallet flag: %v", err)
+		}
+
+		head, err := cmd.Flags().GetInt(headFlagName)
+		if err != nil {
+			return fmt.Errorf("failed to find head flag: %v", err)
+		}
+
+		printJSON, err := cmd.Flags().GetBool(jsonFlagName)
+		if err != nil {
+			return fmt.Errorf("failed to find json flag: %v", err)
+		}
+
+		storage, err := storage.Initialize(
+			clientConfig.Storage,
+			clientConfig.Ethereum.KeyFilePassword,
+		)
+		if err != nil {
+			return fmt.Errorf("cannot initialize storage: [%w]", err)
+		}
+
+		tbtcDataPersistence, err := storage.InitializeWorkPersistence("tbtc")
+		if err != nil {
+			return fmt.Errorf(
+				"cannot initialize tbtc data persistence: [%w]",
+				err,
+			)
+		}
+
+		records, err := tbtc.ReadWalletActionHistory(tbtcDataPersistence)
+		if err != nil {
+			return fmt.Errorf("cannot read wallet action history: [%w]", err)
+		}
+
+		// Verify the whole history before any filtering as the hash chain
+		// spans records of all wallets.
+		verificationErr := tbtc.VerifyWalletActionHistory(records)
+
+		if len(wallet) > 0 {
+			walletPublicKeyHash, err := newWalletPublicKeyHash(
+				wallet,
+				clientConfig.Bitcoin.Network,
+			)
+			if err != nil {
+				return fmt.Errorf("failed to parse wallet: %v", err)
+			}
+
+			filtered := make([]*tbtc.WalletActionRecord, 0)
+			for _, record := range records {
+				if record.WalletPublicKeyHash ==
+					hexutils.Encode(walletPublicKeyHash[:]) {
+					filtered = append(filtered, record)
+				}
+			}
+			records = filtered
+		}
+
+		if head > 0 && len(records) > head {
+			records = records[len(records)-head:]
+		}
+
+		if printJSON {
+			recordsJSON, err := json.MarshalIndent(records, "", "  ")
+			if err != nil {
+				return fmt.Errorf("cannot marshal records: [%w]", err)
+			}
+
+			fmt.Println(string(recordsJSON))
+		} else {
+			printWalletActionHistory(records)
+		}
+
+		if verificationErr != nil {
+			return fmt.Errorf(
+				"wallet action history verification failed: [%w]",
+				verificationErr,
+			)
+		}
+
+		fmt.Fprintln(os.Stderr, "wallet action history verified successfully")
+
+		return nil
+	},
+}
+
+func init() {
+	initFlags(ActionHistoryCommand, &configFilePath, clientConfig, config.Storage)
+
+	ActionHistoryCommand.Flags().String(
+		walletFlagName,
+		"",
+		"wallet public key hash",
+	)
+
+	ActionHistoryCommand.Flags().Int(
+		headFlagName,
+		0,
+		"get head of records",
+	)
+
+	ActionHistoryCommand.Flags().Bool(
+		jsonFlagName,
+		false,
+		"print records in the JSON format",
+	)
+}
+
+func printWalletActionHistory(records []*tbtc.WalletActionRecord) {
+	writer := tabwriter.NewWriter(os.Stdout, 2, 4, 1, ' ', 0)
+
+	fmt.Fprintf(
+		writer,
+		"sequence\taction sequence\tstage\twallet\taction\t"+
+			"coordination block\tleader\ttransaction\tbroadcast\t"+
+			"finished at\terror\t\n",
+	)
+
+	for _, record := range records {
+		finishedAt := "-"
+		if record.Stage == tbtc.WalletActionStageFinished {
+			finishedAt = time.Unix(record.FinishedAt, 0).UTC().Format(
+				time.RFC3339,
+			)
+		}
+
+		fmt.Fprintf(
+			writer,
+			"%d\t%d\t%s\t%s\t%s\t%d\t%s\t%s\t%t\t%s\t%s\t\n",
+			record.Sequence,
+			record.ActionSequence,
+			record.Stage,
+			record.WalletPublicKeyHash,
+			record.ActionType,
+			record.CoordinationBlock,
+			record.Leader,
+			record.TransactionHash,
+			record.Broadcast,
+			finishedAt,
+			record.Error,
+		)
+	}
+
+	writer.Flush()
+}
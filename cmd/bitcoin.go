@@ -0,0 +1,99 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/keep-network/keep-core/config"
+	"github.com/keep-network/keep-core/pkg/bitcoin"
+	"github.com/keep-network/keep-core/pkg/bitcoin/bitcoind"
+	"github.com/keep-network/keep-core/pkg/bitcoin/electrum"
+	"github.com/keep-network/keep-core/pkg/bitcoin/esplora"
+	"github.com/keep-network/keep-core/pkg/bitcoin/feeestimator"
+	"github.com/keep-network/keep-core/pkg/bitcoin/quorum"
+)
+
+// connectBitcoin connects to the Bitcoin chain using the backend selected
+// in the Bitcoin configuration.
+func connectBitcoin(
+	ctx context.Context,
+	bitcoinConfig config.BitcoinConfig,
+) (bitcoin.Chain, error) {
+	if bitcoinConfig.Backend == config.QuorumBackend {
+		return connectBitcoinQuorum(ctx, bitcoinConfig)
+	}
+
+	return connectBitcoinBackend(ctx, bitcoinConfig, bitcoinConfig.Backend)
+}
+
+// connectBitcoinBackend connects to the Bitcoin chain using the given
+// single backend.
+func connectBitcoinBackend(
+	ctx context.Context,
+	bitcoinConfig config.BitcoinConfig,
+	backend string,
+) (bitcoin.Chain, error) {
+	switch backend {
+	case "", config.ElectrumBackend:
+		return electrum.Connect(ctx, bitcoinConfig.Electrum)
+	case config.BitcoindBackend:
+		return bitcoind.Connect(ctx, bitcoinConfig.Bitcoind)
+	case config.EsploraBackend:
+		return esplora.Connect(ctx, bitcoinConfig.Esplora)
+	default:
+		return nil, fmt.Errorf(
+			"unsupported Bitcoin backend: [%s]",
+			backend,
+		)
+	}
+}
+
+// connectBitcoinQuorum connects to all Bitcoin chain backends configured
+// for the quorum and combines them into a quorum-verified chain.
+func connectBitcoinQuorum(
+	ctx context.Context,
+	bitcoinConfig config.BitcoinConfig,
+) (bitcoin.Chain, error) {
+	backends := make([]quorum.Backend, 0, len(bitcoinConfig.Quorum.Backends))
+	for _, backend := range bitcoinConfig.Quorum.Backends {
+		// Each quorum backend has its own connection config that replaces
+		// the one from the section of the given backend type.
+		backendConfig := bitcoinConfig
+		backendConfig.Electrum = backend.Electrum
+		backendConfig.Bitcoind = backend.Bitcoind
+		backendConfig.Esplora = backend.Esplora
+
+		chain, err := connectBitcoinBackend(ctx, backendConfig, backend.Type)
+		if err != nil {
+			return nil, fmt.Errorf(
+				"could not connect to quorum backend [%s]: [%w]",
+				backend.Name,
+				err,
+			)
+		}
+
+		backends = append(backends, quorum.Backend{
+			Name:  backend.Name,
+			Chain: chain,
+		})
+	}
+
+	return quorum.New(backends, bitcoinConfig.Quorum.Threshold)
+}
+
+// newBitcoinFeeRateEstimator creates the fee rate estimator selected in the
+// Bitcoin configuration. In the node fee estimation mode, the Bitcoin chain
+// itself is used as it relies on the estimate of its backend node anyway.
+func newBitcoinFeeRateEstimator(
+	btcChain bitcoin.Chain,
+	bitcoinConfig config.BitcoinConfig,
+) (bitcoin.FeeRateEstimator, error) {
+	feeEstimationConfig := bitcoinConfig.FeeEstimation
+
+	if feeEstimationConfig.Mode == "" ||
+		feeEstimationConfig.Mode == feeestimator.NodeMode {
+		return btcChain, nil
+	}
+
+	return feeestimator.New(btcChain, feeEstimationConfig)
+}
@@ -12,7 +12,10 @@ import (
 	"github.com/keep-network/keep-common/pkg/rate"
 	"github.com/keep-network/keep-core/config"
 	"github.com/keep-network/keep-core/config/network"
+	"github.com/keep-network/keep-core/pkg/bitcoin/bitcoind"
 	"github.com/keep-network/keep-core/pkg/bitcoin/electrum"
+	"github.com/keep-network/keep-core/pkg/bitcoin/esplora"
+	"github.com/keep-network/keep-core/pkg/bitcoin/feeestimator"
 	chainEthereum "github.com/keep-network/keep-core/pkg/chain/ethereum"
 	"github.com/keep-network/keep-core/pkg/clientinfo"
 	"github.com/keep-network/keep-core/pkg/maintainer/spv"
@@ -157,6 +160,13 @@ func initEthereumFlags(cmd *cobra.Command, cfg *config.Config) {
 
 // Initialize flags for Bitcoin electrum configuration.
 func initBitcoinElectrumFlags(cmd *cobra.Command, cfg *config.Config) {
+	cmd.Flags().StringVar(
+		&cfg.Bitcoin.Backend,
+		"bitcoin.backend",
+		config.ElectrumBackend,
+		"Bitcoin chain backend, one of: electrum, bitcoind, esplora, quorum.",
+	)
+
 	cmd.Flags().StringVar(
 		&cfg.Bitcoin.Electrum.URL,
 		"bitcoin.electrum.url",
@@ -198,6 +208,90 @@ func initBitcoinElectrumFlags(cmd *cobra.Command, cfg *config.Config) {
 		electrum.DefaultKeepAliveInterval,
 		"Interval for connection keep alive requests.",
 	)
+
+	cmd.Flags().StringVar(
+		&cfg.Bitcoin.Bitcoind.URL,
+		"bitcoin.bitcoind.url",
+		"",
+		"URL to the bitcoind JSON-RPC server in format: `scheme://hostname:port`.",
+	)
+
+	cmd.Flags().StringVar(
+		&cfg.Bitcoin.Bitcoind.Wallet,
+		"bitcoin.bitcoind.wallet",
+		"",
+		"Name of the bitcoind watch-only descriptor wallet.",
+	)
+
+	cmd.Flags().DurationVar(
+		&cfg.Bitcoin.Bitcoind.RequestTimeout,
+		"bitcoin.bitcoind.requestTimeout",
+		bitcoind.DefaultRequestTimeout,
+		"Timeout for a single attempt of bitcoind JSON-RPC request.",
+	)
+
+	cmd.Flags().DurationVar(
+		&cfg.Bitcoin.Bitcoind.RequestRetryTimeout,
+		"bitcoin.bitcoind.requestRetryTimeout",
+		bitcoind.DefaultRequestRetryTimeout,
+		"Timeout for bitcoind JSON-RPC request retries.",
+	)
+
+	cmd.Flags().DurationVar(
+		&cfg.Bitcoin.Bitcoind.ScanTimeout,
+		"bitcoin.bitcoind.scanTimeout",
+		bitcoind.DefaultScanTimeout,
+		"Timeout for bitcoind requests scanning the UTXO set or rescanning the wallet.",
+	)
+
+	cmd.Flags().StringVar(
+		&cfg.Bitcoin.Esplora.URL,
+		"bitcoin.esplora.url",
+		"",
+		"URL to the Esplora REST API in format: `scheme://hostname:port/path`.",
+	)
+
+	cmd.Flags().DurationVar(
+		&cfg.Bitcoin.Esplora.RequestTimeout,
+		"bitcoin.esplora.requestTimeout",
+		esplora.DefaultRequestTimeout,
+		"Timeout for a single attempt of Esplora REST API request.",
+	)
+
+	cmd.Flags().DurationVar(
+		&cfg.Bitcoin.Esplora.RequestRetryTimeout,
+		"bitcoin.esplora.requestRetryTimeout",
+		esplora.DefaultRequestRetryTimeout,
+		"Timeout for Esplora REST API request retries.",
+	)
+
+	cmd.Flags().IntVar(
+		&cfg.Bitcoin.Quorum.Threshold,
+		"bitcoin.quorum.threshold",
+		0,
+		"Number of quorum backends that must agree on a result. Defaults to a simple majority.",
+	)
+
+	cmd.Flags().StringVar(
+		&cfg.Bitcoin.FeeEstimation.Mode,
+		"bitcoin.feeEstimation.mode",
+		feeestimator.NodeMode,
+		"Bitcoin fee estimation mode, one of: node, deadline, percentile.",
+	)
+
+	cmd.Flags().IntVar(
+		&cfg.Bitcoin.FeeEstimation.Percentile,
+		"bitcoin.feeEstimation.percentile",
+		feeestimator.DefaultPercentile,
+		"Fee rate percentile targeted in the percentile fee estimation mode.",
+	)
+
+	cmd.Flags().IntVar(
+		&cfg.Bitcoin.FeeEstimation.RecentBlocks,
+		"bitcoin.feeEstimation.recentBlocks",
+		feeestimator.DefaultRecentBlocks,
+		"Number of recent blocks whose fee rates are taken into account in fee estimation.",
+	)
 }
 
 // Initialize flags for Network configuration.
@@ -308,6 +402,41 @@ func initTbtcFlags(cmd *cobra.Command, cfg *config.Config) {
 		tbtc.DefaultKeyGenerationConcurrency,
 		"tECDSA key generation concurrency.",
 	)
+
+	cmd.Flags().StringVar(
+		&cfg.Tbtc.ProtocolProfile,
+		"tbtc.protocolProfile",
+		tbtc.MainnetProtocolProfile,
+		"Protocol profile determining protocol timings.",
+	)
+
+	cmd.Flags().StringVar(
+		&cfg.Tbtc.SigningPolicyFile,
+		"tbtc.signingPolicyFile",
+		"",
+		"Path to the JSON file with the signing policy evaluated against wallet transactions.",
+	)
+
+	cmd.Flags().BoolVar(
+		&cfg.Tbtc.ObserverMode,
+		"tbtc.observerMode",
+		false,
+		"Record signing and chain submissions instead of executing them.",
+	)
+
+	cmd.Flags().UintVar(
+		&cfg.Tbtc.FeeBumpMinPendingBlocks,
+		"tbtc.feeBumpMinPendingBlocks",
+		tbtc.DefaultFeeBumpMinPendingBlocks,
+		"Minimum number of Bitcoin blocks a wallet transaction must remain unconfirmed before its fee is bumped.",
+	)
+
+	cmd.Flags().BoolVar(
+		&cfg.Tbtc.KeyRefreshEnabled,
+		"tbtc.keyRefreshEnabled",
+		false,
+		"Take part in proactive key refreshes of live wallets.",
+	)
 }
 
 // Initialize flags for Maintainer configuration.
@@ -386,4 +515,5 @@ func initDeveloperFlags(command *cobra.Command) {
 	initContractAddressFlag(chainEthereum.TokenStakingContractName)
 	initContractAddressFlag(chainEthereum.WalletRegistryContractName)
 	initContractAddressFlag(chainEthereum.WalletProposalValidatorContractName)
+	initContractAddressFlag(chainEthereum.EcdsaDkgValidatorContractName)
 }
@@ -17,6 +17,7 @@ import (
 
 	commonEthereum "github.com/keep-network/keep-common/pkg/chain/ethereum"
 	"github.com/keep-network/keep-core/config"
+	"github.com/keep-network/keep-core/config/network"
 	chainEthereum "github.com/keep-network/keep-core/pkg/chain/ethereum"
 	ethereumBeacon "github.com/keep-network/keep-core/pkg/chain/ethereum/beacon/gen"
 	ethereumEcdsa "github.com/keep-network/keep-core/pkg/chain/ethereum/ecdsa/gen"
@@ -122,6 +123,93 @@ var cmdFlagsTests = map[string]struct {
 		expectedValueFromFlag: 660 * time.Second,
 		defaultValue:          300 * time.Second,
 	},
+	"bitcoin.backend": {
+		readValueFunc: func(c *config.Config) interface{} { return c.Bitcoin.Backend },
+		flagName:      "--bitcoin.backend",
+		flagValue:     "bitcoind",
+		defaultValue:  "electrum",
+	},
+	"bitcoin.bitcoind.url": {
+		readValueFunc: func(c *config.Config) interface{} { return c.Bitcoin.Bitcoind.URL },
+		flagName:      "--bitcoin.bitcoind.url",
+		flagValue:     "http://url.to.bitcoind:18332",
+		defaultValue:  "",
+	},
+	"bitcoin.bitcoind.wallet": {
+		readValueFunc: func(c *config.Config) interface{} { return c.Bitcoin.Bitcoind.Wallet },
+		flagName:      "--bitcoin.bitcoind.wallet",
+		flagValue:     "keep-watch-only",
+		defaultValue:  "",
+	},
+	"bitcoin.bitcoind.requestTimeout": {
+		readValueFunc:         func(c *config.Config) interface{} { return c.Bitcoin.Bitcoind.RequestTimeout },
+		flagName:              "--bitcoin.bitcoind.requestTimeout",
+		flagValue:             "41s",
+		expectedValueFromFlag: 41 * time.Second,
+		defaultValue:          30 * time.Second,
+	},
+	"bitcoin.bitcoind.requestRetryTimeout": {
+		readValueFunc:         func(c *config.Config) interface{} { return c.Bitcoin.Bitcoind.RequestRetryTimeout },
+		flagName:              "--bitcoin.bitcoind.requestRetryTimeout",
+		flagValue:             "7m",
+		expectedValueFromFlag: 420 * time.Second,
+		defaultValue:          120 * time.Second,
+	},
+	"bitcoin.bitcoind.scanTimeout": {
+		readValueFunc:         func(c *config.Config) interface{} { return c.Bitcoin.Bitcoind.ScanTimeout },
+		flagName:              "--bitcoin.bitcoind.scanTimeout",
+		flagValue:             "45m",
+		expectedValueFromFlag: 2700 * time.Second,
+		defaultValue:          1800 * time.Second,
+	},
+	"bitcoin.esplora.url": {
+		readValueFunc: func(c *config.Config) interface{} { return c.Bitcoin.Esplora.URL },
+		flagName:      "--bitcoin.esplora.url",
+		flagValue:     "https://url.to.esplora/api",
+		defaultValue:  "",
+	},
+	"bitcoin.esplora.requestTimeout": {
+		readValueFunc:         func(c *config.Config) interface{} { return c.Bitcoin.Esplora.RequestTimeout },
+		flagName:              "--bitcoin.esplora.requestTimeout",
+		flagValue:             "43s",
+		expectedValueFromFlag: 43 * time.Second,
+		defaultValue:          30 * time.Second,
+	},
+	"bitcoin.esplora.requestRetryTimeout": {
+		readValueFunc:         func(c *config.Config) interface{} { return c.Bitcoin.Esplora.RequestRetryTimeout },
+		flagName:              "--bitcoin.esplora.requestRetryTimeout",
+		flagValue:             "6m",
+		expectedValueFromFlag: 360 * time.Second,
+		defaultValue:          120 * time.Second,
+	},
+	"bitcoin.quorum.threshold": {
+		readValueFunc:         func(c *config.Config) interface{} { return c.Bitcoin.Quorum.Threshold },
+		flagName:              "--bitcoin.quorum.threshold",
+		flagValue:             "2",
+		expectedValueFromFlag: 2,
+		defaultValue:          0,
+	},
+	"bitcoin.feeEstimation.mode": {
+		readValueFunc:         func(c *config.Config) interface{} { return c.Bitcoin.FeeEstimation.Mode },
+		flagName:              "--bitcoin.feeEstimation.mode",
+		flagValue:             "percentile",
+		expectedValueFromFlag: "percentile",
+		defaultValue:          "node",
+	},
+	"bitcoin.feeEstimation.percentile": {
+		readValueFunc:         func(c *config.Config) interface{} { return c.Bitcoin.FeeEstimation.Percentile },
+		flagName:              "--bitcoin.feeEstimation.percentile",
+		flagValue:             "75",
+		expectedValueFromFlag: 75,
+		defaultValue:          50,
+	},
+	"bitcoin.feeEstimation.recentBlocks": {
+		readValueFunc:         func(c *config.Config) interface{} { return c.Bitcoin.FeeEstimation.RecentBlocks },
+		flagName:              "--bitcoin.feeEstimation.recentBlocks",
+		flagValue:             "12",
+		expectedValueFromFlag: 12,
+		defaultValue:          6,
+	},
 	"network.bootstrap": {
 		readValueFunc:         func(c *config.Config) interface{} { return c.LibP2P.Bootstrap },
 		flagName:              "--network.bootstrap",
@@ -225,6 +313,41 @@ var cmdFlagsTests = map[string]struct {
 		expectedValueFromFlag: 101,
 		defaultValue:          runtime.GOMAXPROCS(0),
 	},
+	"tbtc.protocolProfile": {
+		readValueFunc:         func(c *config.Config) interface{} { return c.Tbtc.ProtocolProfile },
+		flagName:              "--tbtc.protocolProfile",
+		flagValue:             "developer",
+		expectedValueFromFlag: "developer",
+		defaultValue:          "mainnet",
+	},
+	"tbtc.signingPolicyFile": {
+		readValueFunc:         func(c *config.Config) interface{} { return c.Tbtc.SigningPolicyFile },
+		flagName:              "--tbtc.signingPolicyFile",
+		flagValue:             "./policy.json",
+		expectedValueFromFlag: "./policy.json",
+		defaultValue:          "",
+	},
+	"tbtc.observerMode": {
+		readValueFunc:         func(c *config.Config) interface{} { return c.Tbtc.ObserverMode },
+		flagName:              "--tbtc.observerMode",
+		flagValue:             "", // don't provide any value
+		expectedValueFromFlag: true,
+		defaultValue:          false,
+	},
+	"tbtc.feeBumpMinPendingBlocks": {
+		readValueFunc:         func(c *config.Config) interface{} { return c.Tbtc.FeeBumpMinPendingBlocks },
+		flagName:              "--tbtc.feeBumpMinPendingBlocks",
+		flagValue:             "12",
+		expectedValueFromFlag: uint(12),
+		defaultValue:          uint(6),
+	},
+	"tbtc.keyRefreshEnabled": {
+		readValueFunc:         func(c *config.Config) interface{} { return c.Tbtc.KeyRefreshEnabled },
+		flagName:              "--tbtc.keyRefreshEnabled",
+		flagValue:             "", // don't provide any value
+		expectedValueFromFlag: true,
+		defaultValue:          false,
+	},
 	"maintainer.bitcoinDifficulty": {
 		readValueFunc:         func(c *config.Config) interface{} { return c.Maintainer.BitcoinDifficulty.Enabled },
 		flagName:              "--bitcoinDifficulty",
@@ -354,12 +477,24 @@ var cmdFlagsTests = map[string]struct {
 		expectedValueFromFlag: common.HexToAddress("0xE7d33d8AA55B73a93059a24b900366894684a497"),
 		defaultValue:          common.HexToAddress(ethereumTbtc.WalletProposalValidatorAddress),
 	},
+	"developer.ecdsaDkgValidatorAddress": {
+		readValueFunc: func(c *config.Config) interface{} {
+			address, _ := c.Ethereum.ContractAddress(chainEthereum.EcdsaDkgValidatorContractName)
+			return address
+		},
+		flagName:              "--developer.ecdsaDkgValidatorAddress",
+		flagValue:             "0x0125c8977a02b2Fa3970b1ED9AF02f5Bedd4eF27",
+		expectedValueFromFlag: common.HexToAddress("0x0125c8977a02b2Fa3970b1ED9AF02f5Bedd4eF27"),
+		defaultValue:          common.HexToAddress(ethereumEcdsa.EcdsaDkgValidatorAddress),
+	},
 }
 
 func TestFlags_ReadConfigFromFlags(t *testing.T) {
 	testCommand, testConfig, _ := initTestCommand()
 
-	args := []string{}
+	// Protocol profiles other than the mainnet one are not allowed on
+	// mainnet so run the client against the developer network.
+	args := []string{"--" + network.Developer.String()}
 	for _, test := range cmdFlagsTests {
 		args = append(args, []string{test.flagName, test.flagValue}...)
 	}
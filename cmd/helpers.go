@@ -100,6 +100,7 @@ func buildContractAddresses(lineLength int, prefix, suffix string, ethereumConfi
 		chainEthereum.WalletRegistryContractName,
 		chainEthereum.TokenStakingContractName,
 		chainEthereum.WalletProposalValidatorContractName,
+		chainEthereum.EcdsaDkgValidatorContractName,
 	}
 
 	entries := []string{}
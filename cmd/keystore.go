@@ -0,0 +1,459 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"syscall"
+	"text/tabwriter"
+
+	"github.com/keep-network/keep-common/pkg/persistence"
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+
+	"github.com/keep-network/keep-core/config"
+	"github.com/keep-network/keep-core/pkg/chain/ethereum"
+	"github.com/keep-network/keep-core/pkg/storage"
+	"github.com/keep-network/keep-core/pkg/tbtc"
+)
+
+const (
+	// keyStoreBundlePassphraseEnvVariable can be used to provide the key
+	// store bundle passphrase without the interactive prompt.
+	// This line doesn't contain any credentials.
+	// It's just the name of the environment variable.
+	keyStoreBundlePassphraseEnvVariable = "KEEP_KEYSTORE_BUNDLE_PASSPHRASE"
+)
+
+var (
+	// exportKeyStoreCommand:
+	outputFlagName = "output"
+
+	// importKeyStoreCommand:
+	// verifyKeyStoreCommand:
+	// listKeyStoreCommand:
+	bundleFlagName = "bundle"
+)
+
+// KeyStoreCommand contains the definition of the keystore command-line
+// subcommand and its own subcommands.
+var KeyStoreCommand = &cobra.Command{
+	Use:   "keystore",
+	Short: "Manages tBTC signer key shares",
+	Long: "Backs up, restores, verifies, and lists the tBTC signer key " +
+		"shares held in the local key store. Backups are single " +
+		"passphrase-encrypted bundles. The passphrase is read from the " +
+		keyStoreBundlePassphraseEnvVariable + " environment variable or " +
+		"from the prompt.",
+	TraverseChildren: true,
+}
+
+var exportKeyStoreCommand = &cobra.Command{
+	Use:   "export",
+	Short: "Exports signer key shares to an encrypted bundle",
+	Long: "Exports all key shares of active wallets from the local key " +
+		"store to a single passphrase-encrypted bundle file. The command " +
+		"refuses to overwrite an existing file.",
+	PreRun: readKeyStoreCommandConfig(config.Storage),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		output, err := cmd.Flags().GetString(outputFlagName)
+		if err != nil {
+			return fmt.Errorf("failed to find output flag: %v", err)
+		}
+
+		keyStorePersistence, err := initializeTbtcKeyStorePersistence()
+		if err != nil {
+			return err
+		}
+
+		shares, err := tbtc.ReadKeyStoreShares(keyStorePersistence)
+		if err != nil {
+			return fmt.Errorf("cannot read key store: [%w]", err)
+		}
+
+		passphrase, err := readKeyStoreBundlePassphrase(true)
+		if err != nil {
+			return err
+		}
+
+		bundle, err := tbtc.ExportKeyStoreBundle(shares, passphrase)
+		if err != nil {
+			return fmt.Errorf("cannot export key store bundle: [%w]", err)
+		}
+
+		file, err := os.OpenFile(
+			output,
+			os.O_WRONLY|os.O_CREATE|os.O_EXCL,
+			0600,
+		)
+		if err != nil {
+			return fmt.Errorf("cannot create bundle file: [%w]", err)
+		}
+		defer file.Close()
+
+		if _, err := file.Write(bundle); err != nil {
+			return fmt.Errorf("cannot write bundle file: [%w]", err)
+		}
+
+		printKeyStoreShares(shares)
+
+		fmt.Fprintf(
+			os.Stderr,
+			"exported [%v] key shares to [%v]\n",
+			len(shares),
+			output,
+		)
+
+		return nil
+	},
+}
+
+var importKeyStoreCommand = &cobra.Command{
+	Use:   "import",
+	Short: "Imports signer key shares from an encrypted bundle",
+	Long: "Imports key shares from a passphrase-encrypted bundle into " +
+		"the local key store. Shares already present in the key store are " +
+		"skipped. Stale shares, replaced by a key refresh, are rejected. " +
+		"The client must be stopped during the import.",
+	PreRun: readKeyStoreCommandConfig(config.Storage),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		shares, err := openKeyStoreBundle(cmd)
+		if err != nil {
+			return err
+		}
+
+		keyStorePersistence, err := initializeTbtcKeyStorePersistence()
+		if err != nil {
+			return err
+		}
+
+		imported, skipped, err := tbtc.ImportKeyStoreShares(
+			keyStorePersistence,
+			shares,
+		)
+		if err != nil {
+			return fmt.Errorf("cannot import key shares: [%w]", err)
+		}
+
+		fmt.Fprintf(
+			os.Stderr,
+			"imported [%v] key shares; skipped [%v] key shares already "+
+				"present in the key store\n",
+			imported,
+			skipped,
+		)
+
+		return nil
+	},
+}
+
+var verifyKeyStoreCommand = &cobra.Command{
+	Use:   "verify",
+	Short: "Verifies signer key shares against the chain",
+	Long: "Verifies key shares from the given bundle or, if no bundle is " +
+		"given, from the local key store. Each share must unmarshal, its " +
+		"public key must match the wallet public key, and the wallet must " +
+		"be registered on chain. A share is also rejected as stale if " +
+		"another verified share of the same wallet has a higher refresh " +
+		"epoch. The command exits with an error if any of the shares " +
+		"fails the verification.",
+	PreRun: readKeyStoreCommandConfig(config.Storage, config.Ethereum),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		shares, err := readKeyStoreCommandShares(cmd)
+		if err != nil {
+			return err
+		}
+
+		_, tbtcChain, _, _, _, err := ethereum.Connect(
+			cmd.Context(),
+			clientConfig.Ethereum,
+		)
+		if err != nil {
+			return fmt.Errorf(
+				"could not connect to Ethereum chain: [%v]",
+				err,
+			)
+		}
+
+		writer := tabwriter.NewWriter(os.Stdout, 2, 4, 1, ' ', 0)
+
+		fmt.Fprintf(
+			writer,
+			"wallet\tmember index\tgroup size\trefresh epoch\tstatus\t\n",
+		)
+
+		verificationErrors := tbtc.VerifyKeyStoreShares(shares, tbtcChain)
+
+		failures := 0
+		for i, share := range shares {
+			status := "ok"
+			if err := verificationErrors[i]; err != nil {
+				status = err.Error()
+				failures++
+			}
+
+			walletPublicKeyHash := share.WalletPublicKeyHash()
+
+			fmt.Fprintf(
+				writer,
+				"0x%x\t%d\t%d\t%d\t%s\t\n",
+				walletPublicKeyHash,
+				share.MemberIndex(),
+				share.GroupSize(),
+				share.RefreshEpoch(),
+				status,
+			)
+		}
+
+		writer.Flush()
+
+		if failures > 0 {
+			return fmt.Errorf(
+				"[%v] of [%v] key shares failed the verification",
+				failures,
+				len(shares),
+			)
+		}
+
+		fmt.Fprintf(
+			os.Stderr,
+			"[%v] key shares verified successfully\n",
+			len(shares),
+		)
+
+		return nil
+	},
+}
+
+var listKeyStoreCommand = &cobra.Command{
+	Use:   "list",
+	Short: "Lists signer key shares",
+	Long: "Lists key shares from the given bundle or, if no bundle is " +
+		"given, from the local key store.",
+	PreRun: readKeyStoreCommandConfig(config.Storage),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		shares, err := readKeyStoreCommandShares(cmd)
+		if err != nil {
+			return err
+		}
+
+		printKeyStoreShares(shares)
+
+		return nil
+	},
+}
+
+func init() {
+	initFlags(exportKeyStoreCommand, &configFilePath, clientConfig, config.Storage)
+
+	exportKeyStoreCommand.Flags().String(
+		outputFlagName,
+		"",
+		"path to the bundle file that will be created",
+	)
+
+	if err := exportKeyStoreCommand.MarkFlagRequired(outputFlagName); err != nil {
+		logger.Fatalf("failed to mark flag required: [%v]", err)
+	}
+
+	KeyStoreCommand.AddCommand(exportKeyStoreCommand)
+
+	initFlags(importKeyStoreCommand, &configFilePath, clientConfig, config.Storage)
+
+	importKeyStoreCommand.Flags().String(
+		bundleFlagName,
+		"",
+		"path to the bundle file",
+	)
+
+	if err := importKeyStoreCommand.MarkFlagRequired(bundleFlagName); err != nil {
+		logger.Fatalf("failed to mark flag required: [%v]", err)
+	}
+
+	KeyStoreCommand.AddCommand(importKeyStoreCommand)
+
+	initFlags(
+		verifyKeyStoreCommand,
+		&configFilePath,
+		clientConfig,
+		config.Storage, config.Ethereum,
+	)
+
+	verifyKeyStoreCommand.Flags().String(
+		bundleFlagName,
+		"",
+		"(optional) path to the bundle file; the local key store is "+
+			"verified if not set",
+	)
+
+	KeyStoreCommand.AddCommand(verifyKeyStoreCommand)
+
+	initFlags(listKeyStoreCommand, &configFilePath, clientConfig, config.Storage)
+
+	listKeyStoreCommand.Flags().String(
+		bundleFlagName,
+		"",
+		"(optional) path to the bundle file; the local key store is "+
+			"listed if not set",
+	)
+
+	KeyStoreCommand.AddCommand(listKeyStoreCommand)
+}
+
+// readKeyStoreCommandConfig returns a function reading the client
+// configuration of the given categories.
+func readKeyStoreCommandConfig(
+	categories ...config.Category,
+) func(cmd *cobra.Command, args []string) {
+	return func(cmd *cobra.Command, args []string) {
+		if err := clientConfig.ReadConfig(
+			configFilePath,
+			cmd.Flags(),
+			categories...,
+		); err != nil {
+			logger.Fatalf("error reading config: %v", err)
+		}
+	}
+}
+
+// initializeTbtcKeyStorePersistence initializes the tBTC key store
+// persistence the same way the client does upon startup.
+func initializeTbtcKeyStorePersistence() (
+	persistence.ProtectedHandle,
+	error,
+) {
+	storage, err := storage.Initialize(
+		clientConfig.Storage,
+		clientConfig.Ethereum.KeyFilePassword,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("cannot initialize storage: [%w]", err)
+	}
+
+	keyStorePersistence, err := storage.InitializeKeyStorePersistence("tbtc")
+	if err != nil {
+		return nil, fmt.Errorf(
+			"cannot initialize tbtc keystore persistence: [%w]",
+			err,
+		)
+	}
+
+	return keyStorePersistence, nil
+}
+
+// readKeyStoreCommandShares reads key shares from the bundle given with
+// the bundle flag or, if the flag is not set, from the local key store.
+func readKeyStoreCommandShares(cmd *cobra.Command) ([]*tbtc.KeyStoreShare, error) {
+	bundle, err := cmd.Flags().GetString(bundleFlagName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find bundle flag: %v", err)
+	}
+
+	if len(bundle) > 0 {
+		return openKeyStoreBundle(cmd)
+	}
+
+	keyStorePersistence, err := initializeTbtcKeyStorePersistence()
+	if err != nil {
+		return nil, err
+	}
+
+	shares, err := tbtc.ReadKeyStoreShares(keyStorePersistence)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read key store: [%w]", err)
+	}
+
+	return shares, nil
+}
+
+// openKeyStoreBundle reads and decrypts the bundle given with the bundle flag.
+func openKeyStoreBundle(cmd *cobra.Command) ([]*tbtc.KeyStoreShare, error) {
+	bundlePath, err := cmd.Flags().GetString(bundleFlagName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find bundle flag: %v", err)
+	}
+
+	bundle, err := os.ReadFile(bundlePath)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read bundle file: [%w]", err)
+	}
+
+	passphrase, err := readKeyStoreBundlePassphrase(false)
+	if err != nil {
+		return nil, err
+	}
+
+	shares, err := tbtc.OpenKeyStoreBundle(bundle, passphrase)
+	if err != nil {
+		return nil, fmt.Errorf("cannot open key store bundle: [%w]", err)
+	}
+
+	return shares, nil
+}
+
+// readKeyStoreBundlePassphrase reads the bundle passphrase from the
+// environment variable or, if not set, from the prompt. If confirm is true,
+// the prompted passphrase must be entered twice.
+func readKeyStoreBundlePassphrase(confirm bool) (string, error) {
+	if passphrase := os.Getenv(keyStoreBundlePassphraseEnvVariable); len(passphrase) > 0 {
+		return passphrase, nil
+	}
+
+	passphrase, err := readKeyStoreBundlePassphrasePrompt(
+		"Enter key store bundle passphrase: ",
+	)
+	if err != nil {
+		return "", err
+	}
+
+	if len(passphrase) == 0 {
+		return "", fmt.Errorf("passphrase must not be empty")
+	}
+
+	if confirm {
+		confirmation, err := readKeyStoreBundlePassphrasePrompt(
+			"Confirm key store bundle passphrase: ",
+		)
+		if err != nil {
+			return "", err
+		}
+
+		if confirmation != passphrase {
+			return "", fmt.Errorf("passphrases do not match")
+		}
+	}
+
+	return passphrase, nil
+}
+
+func readKeyStoreBundlePassphrasePrompt(prompt string) (string, error) {
+	fmt.Fprint(os.Stderr, prompt)
+	passphrase, err := term.ReadPassword(int(syscall.Stdin))
+	fmt.Fprint(os.Stderr, "\n")
+	if err != nil {
+		return "", fmt.Errorf("unable to read passphrase: [%v]", err)
+	}
+
+	return strings.TrimSpace(string(passphrase)), nil
+}
+
+func printKeyStoreShares(shares []*tbtc.KeyStoreShare) {
+	writer := tabwriter.NewWriter(os.Stdout, 2, 4, 1, ' ', 0)
+
+	fmt.Fprintf(writer, "wallet\tmember index\tgroup size\trefresh epoch\t\n")
+
+	for _, share := range shares {
+		walletPublicKeyHash := share.WalletPublicKeyHash()
+
+		fmt.Fprintf(
+			writer,
+			"0x%x\t%d\t%d\t%d\t\n",
+			walletPublicKeyHash,
+			share.MemberIndex(),
+			share.GroupSize(),
+			share.RefreshEpoch(),
+		)
+	}
+
+	writer.Flush()
+}
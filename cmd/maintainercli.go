@@ -1,19 +1,31 @@
 package cmd
 
 import (
+	"context"
+	"crypto/ecdsa"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
 	"fmt"
 	"os"
 	"sort"
+	"strings"
 	"text/tabwriter"
 
+	"github.com/btcsuite/btcd/btcec"
+	"github.com/btcsuite/btcutil"
 	"github.com/spf13/cobra"
 
 	"github.com/keep-network/keep-core/config"
 	"github.com/keep-network/keep-core/internal/hexutils"
 	"github.com/keep-network/keep-core/pkg/bitcoin"
-	"github.com/keep-network/keep-core/pkg/bitcoin/electrum"
+	"github.com/keep-network/keep-core/pkg/chain"
 	"github.com/keep-network/keep-core/pkg/chain/ethereum"
+	"github.com/keep-network/keep-core/pkg/maintainer"
+	"github.com/keep-network/keep-core/pkg/maintainer/btcdiff"
 	"github.com/keep-network/keep-core/pkg/maintainer/spv"
+	"github.com/keep-network/keep-core/pkg/tbtc"
 	"github.com/keep-network/keep-core/pkg/tbtcpg"
 )
 
@@ -30,8 +42,29 @@ var (
 
 	// submitDepositSweepProofCommand:
 	// submitRedemptionProofCommand:
+	// inspectTransactionCommand:
 	transactionHashFlagName = "transaction-hash"
 	confirmationsFlagName   = "confirmations"
+
+	// decodePsbtCommand:
+	psbtFlagName = "psbt"
+
+	// buildDepositRefundCommand:
+	fundingTransactionHashFlagName = "funding-transaction-hash"
+	fundingOutputIndexFlagName     = "funding-output-index"
+	depositorFlagName              = "depositor"
+	blindingFactorFlagName         = "blinding-factor"
+	refundLocktimeFlagName         = "refund-locktime"
+	extraDataFlagName              = "extra-data"
+	refundKeyFileFlagName          = "refund-key-file"
+	recipientFlagName              = "recipient"
+	feeFlagName                    = "fee"
+
+	// listWalletDescriptorsCommand:
+	importFormatFlagName = "import-format"
+
+	// proofOfReservesCommand:
+	signFlagName = "sign"
 )
 
 // MaintainerCliCommand contains the definition of tools associated with maintainers
@@ -86,15 +119,18 @@ var listDepositsCommand = cobra.Command{
 			)
 		}
 
-		btcChain, err := electrum.Connect(ctx, clientConfig.Bitcoin.Electrum)
+		btcChain, err := connectBitcoin(ctx, clientConfig.Bitcoin)
 		if err != nil {
-			return fmt.Errorf("could not connect to Electrum chain: [%v]", err)
+			return fmt.Errorf("could not connect to Bitcoin chain: [%v]", err)
 		}
 
 		var walletPublicKeyHash [20]byte
 		if len(wallet) > 0 {
 			var err error
-			walletPublicKeyHash, err = newWalletPublicKeyHash(wallet)
+			walletPublicKeyHash, err = newWalletPublicKeyHash(
+				wallet,
+				clientConfig.Bitcoin.Network,
+			)
 			if err != nil {
 				return fmt.Errorf(
 					"failed to extract wallet public key hash: %v",
@@ -122,7 +158,10 @@ var listDepositsCommand = cobra.Command{
 			return fmt.Errorf("no deposits found")
 		}
 
-		if err := printDepositsTable(deposits); err != nil {
+		if err := printDepositsTable(
+			deposits,
+			clientConfig.Bitcoin.Network,
+		); err != nil {
 			return fmt.Errorf("failed to print deposits table: %v", err)
 		}
 
@@ -130,14 +169,18 @@ var listDepositsCommand = cobra.Command{
 	},
 }
 
-func printDepositsTable(deposits []*tbtcpg.Deposit) error {
+func printDepositsTable(
+	deposits []*tbtcpg.Deposit,
+	network bitcoin.Network,
+) error {
 	w := tabwriter.NewWriter(os.Stdout, 2, 4, 1, ' ', tabwriter.AlignRight)
-	fmt.Fprintf(w, "index\twallet\tvalue (BTC)\tdeposit key\trevealed deposit data\tconfirmations\tswept\t\n")
+	fmt.Fprintf(w, "index\twallet\twallet address\tvalue (BTC)\tdeposit key\trevealed deposit data\tconfirmations\tswept\t\n")
 
 	for i, deposit := range deposits {
-		fmt.Fprintf(w, "%d\t%s\t%.5f\t%s\t%s\t%d\t%t\t\n",
+		fmt.Fprintf(w, "%d\t%s\t%s\t%.5f\t%s\t%s\t%d\t%t\t\n",
 			i,
 			hexutils.Encode(deposit.WalletPublicKeyHash[:]),
+			walletAddress(deposit.WalletPublicKeyHash, network),
 			deposit.AmountBtc,
 			deposit.DepositKey,
 			fmt.Sprintf(
@@ -179,14 +222,22 @@ var estimateDepositsSweepFeeCommand = cobra.Command{
 			)
 		}
 
-		btcChain, err := electrum.Connect(ctx, clientConfig.Bitcoin.Electrum)
+		btcChain, err := connectBitcoin(ctx, clientConfig.Bitcoin)
+		if err != nil {
+			return fmt.Errorf("could not connect to Bitcoin chain: [%v]", err)
+		}
+
+		feeRateEstimator, err := newBitcoinFeeRateEstimator(
+			btcChain,
+			clientConfig.Bitcoin,
+		)
 		if err != nil {
-			return fmt.Errorf("could not connect to Electrum chain: [%v]", err)
+			return fmt.Errorf("could not create fee rate estimator: [%v]", err)
 		}
 
 		fees, err := tbtcpg.EstimateDepositsSweepFee(
 			tbtcChain,
-			btcChain,
+			feeRateEstimator,
 			depositsCount,
 		)
 		if err != nil {
@@ -296,9 +347,17 @@ var submitDepositSweepProofCommand = cobra.Command{
 			)
 		}
 
-		btcChain, err := electrum.Connect(ctx, clientConfig.Bitcoin.Electrum)
+		btcChain, err := connectBitcoin(ctx, clientConfig.Bitcoin)
+		if err != nil {
+			return fmt.Errorf("could not connect to Bitcoin chain: [%v]", err)
+		}
+
+		btcDiffChain, err := connectBitcoinDifficultyReadOnly(ctx)
 		if err != nil {
-			return fmt.Errorf("could not connect to Electrum chain: [%v]", err)
+			return fmt.Errorf(
+				"could not connect to Bitcoin difficulty chain: [%v]",
+				err,
+			)
 		}
 
 		transactionHashFlag, err := cmd.Flags().GetString(transactionHashFlagName)
@@ -353,6 +412,7 @@ var submitDepositSweepProofCommand = cobra.Command{
 			transactionHash,
 			requiredConfirmations,
 			btcChain,
+			btcDiffChain,
 			tbtcChain,
 		); err != nil {
 			return fmt.Errorf("failed to submit deposit sweep proof [%v]", err)
@@ -386,9 +446,17 @@ var submitRedemptionProofCommand = cobra.Command{
 			)
 		}
 
-		btcChain, err := electrum.Connect(ctx, clientConfig.Bitcoin.Electrum)
+		btcChain, err := connectBitcoin(ctx, clientConfig.Bitcoin)
 		if err != nil {
-			return fmt.Errorf("could not connect to Electrum chain: [%v]", err)
+			return fmt.Errorf("could not connect to Bitcoin chain: [%v]", err)
+		}
+
+		btcDiffChain, err := connectBitcoinDifficultyReadOnly(ctx)
+		if err != nil {
+			return fmt.Errorf(
+				"could not connect to Bitcoin difficulty chain: [%v]",
+				err,
+			)
 		}
 
 		transactionHashFlag, err := cmd.Flags().GetString(transactionHashFlagName)
@@ -443,6 +511,7 @@ var submitRedemptionProofCommand = cobra.Command{
 			transactionHash,
 			requiredConfirmations,
 			btcChain,
+			btcDiffChain,
 			tbtcChain,
 		); err != nil {
 			return fmt.Errorf("failed to submit redemption proof [%v]", err)
@@ -457,112 +526,1523 @@ var submitRedemptionProofCommand = cobra.Command{
 	},
 }
 
-func init() {
-	initFlags(
-		MaintainerCliCommand,
-		&configFilePath,
-		clientConfig,
-		config.General, config.Ethereum, config.BitcoinElectrum,
-	)
+var depositSweepPsbtCommand = cobra.Command{
+	Use:              "deposit-sweep-psbt",
+	Short:            "get PSBT of pending deposit sweep",
+	Long:             depositSweepPsbtCommandDescription,
+	TraverseChildren: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
 
-	// Deposits Subcommand
-	listDepositsCommand.Flags().String(
-		walletFlagName,
-		"",
-		"wallet public key hash",
-	)
+		wallet, err := cmd.Flags().GetString(walletFlagName)
+		if err != nil {
+			return fmt.Errorf("failed to find wallet flag: %v", err)
+		}
 
-	listDepositsCommand.Flags().Bool(
-		hideSweptFlagName,
-		false,
-		"hide swept deposits",
-	)
+		walletPublicKeyHash, err := newWalletPublicKeyHash(
+			wallet,
+			clientConfig.Bitcoin.Network,
+		)
+		if err != nil {
+			return fmt.Errorf(
+				"failed to extract wallet public key hash: %v",
+				err,
+			)
+		}
 
-	listDepositsCommand.Flags().Int(
-		headFlagName,
-		0,
-		"get head of deposits",
-	)
+		_, tbtcChain, _, _, _, err := ethereum.Connect(
+			ctx,
+			clientConfig.Ethereum,
+		)
+		if err != nil {
+			return fmt.Errorf(
+				"could not connect to Ethereum chain: [%v]",
+				err,
+			)
+		}
 
-	MaintainerCliCommand.AddCommand(&listDepositsCommand)
+		btcChain, err := connectBitcoin(ctx, clientConfig.Bitcoin)
+		if err != nil {
+			return fmt.Errorf("could not connect to Bitcoin chain: [%v]", err)
+		}
 
-	// Estimate Deposits Sweep Fee Subcommand.
-	estimateDepositsSweepFeeCommand.Flags().Int(
-		depositsCountFlagName,
-		0,
-		"get estimation for a specific count of input deposits",
-	)
+		feeRateEstimator, err := newBitcoinFeeRateEstimator(
+			btcChain,
+			clientConfig.Bitcoin,
+		)
+		if err != nil {
+			return fmt.Errorf("could not create fee rate estimator: [%v]", err)
+		}
 
-	MaintainerCliCommand.AddCommand(&estimateDepositsSweepFeeCommand)
+		depositSweepMaxSize, err := tbtcChain.GetDepositSweepMaxSize()
+		if err != nil {
+			return fmt.Errorf("failed to get deposit sweep max size: [%v]", err)
+		}
 
-	// Submit Deposit Sweep Proof Subcommand.
+		task := tbtcpg.NewDepositSweepTask(
+			tbtcChain,
+			btcChain,
+			feeRateEstimator,
+		)
 
-	submitDepositSweepProofCommand.Flags().String(
-		transactionHashFlagName,
-		"",
-		"transaction hash the proof will be prepared for (the format should "+
-			"be the same as in Bitcoin explorers).",
-	)
+		deposits, err := task.FindDepositsToSweep(
+			logger,
+			walletPublicKeyHash,
+			depositSweepMaxSize,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to find deposits to sweep: [%v]", err)
+		}
 
-	if err := submitDepositSweepProofCommand.MarkFlagRequired(
-		transactionHashFlagName,
-	); err != nil {
-		logger.Fatalf("failed to mark flag required: [%v]", err)
-	}
+		if len(deposits) == 0 {
+			return fmt.Errorf("no deposits to sweep")
+		}
 
-	submitDepositSweepProofCommand.Flags().Uint(
-		confirmationsFlagName,
-		0,
-		"(optional) number of confirmations that will be provided in the proof. "+
-			"This is an optional parameter that can be used in a rare event when "+
-			"more confirmations are required to perform a successful proof "+
-			"validation. If this parameter is not provided, the default value, "+
-			"retrieved from the Bridge will be used.",
-	)
+		proposal, err := task.ProposeDepositsSweep(
+			logger,
+			walletPublicKeyHash,
+			deposits,
+			0,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to prepare deposit sweep proposal: [%v]", err)
+		}
 
-	MaintainerCliCommand.AddCommand(&submitDepositSweepProofCommand)
+		psbt, err := tbtc.AssembleDepositSweepPSBT(
+			logger,
+			walletPublicKeyHash,
+			proposal,
+			tbtcChain,
+			btcChain,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to assemble deposit sweep PSBT: [%v]", err)
+		}
 
-	// Submit Redemption Proof Subcommand.
+		return printPsbt(psbt, clientConfig.Bitcoin.Network)
+	},
+}
 
-	submitRedemptionProofCommand.Flags().String(
-		transactionHashFlagName,
-		"",
-		"transaction hash the proof will be prepared for (the format should "+
-			"be the same as in Bitcoin explorers).",
-	)
+var depositSweepPsbtCommandDescription = "Prepares the deposit sweep " +
+	"proposal for the given wallet, the same way the wallet coordinator " +
+	"would do, and prints the unsigned deposit sweep transaction as " +
+	"a base64-encoded BIP-0174 PSBT along with its decoded content. The " +
+	"PSBT can be inspected using standard Bitcoin tooling, e.g. the " +
+	"decodepsbt RPC of Bitcoin Core"
+
+var redemptionPsbtCommand = cobra.Command{
+	Use:              "redemption-psbt",
+	Short:            "get PSBT of pending redemption",
+	Long:             redemptionPsbtCommandDescription,
+	TraverseChildren: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
 
-	if err := submitRedemptionProofCommand.MarkFlagRequired(
-		transactionHashFlagName,
-	); err != nil {
-		logger.Fatalf("failed to mark flag required: [%v]", err)
-	}
+		wallet, err := cmd.Flags().GetString(walletFlagName)
+		if err != nil {
+			return fmt.Errorf("failed to find wallet flag: %v", err)
+		}
 
-	submitRedemptionProofCommand.Flags().Uint(
-		confirmationsFlagName,
-		0,
-		"(optional) number of confirmations that will be provided in the proof. "+
-			"This is an optional parameter that can be used in a rare event when "+
-			"more confirmations are required to perform a successful proof "+
-			"validation. If this parameter is not provided, the default value, "+
-			"retrieved from the Bridge will be used.",
-	)
+		walletPublicKeyHash, err := newWalletPublicKeyHash(
+			wallet,
+			clientConfig.Bitcoin.Network,
+		)
+		if err != nil {
+			return fmt.Errorf(
+				"failed to extract wallet public key hash: %v",
+				err,
+			)
+		}
 
-	MaintainerCliCommand.AddCommand(&submitRedemptionProofCommand)
+		_, tbtcChain, _, _, _, err := ethereum.Connect(
+			ctx,
+			clientConfig.Ethereum,
+		)
+		if err != nil {
+			return fmt.Errorf(
+				"could not connect to Ethereum chain: [%v]",
+				err,
+			)
+		}
+
+		btcChain, err := connectBitcoin(ctx, clientConfig.Bitcoin)
+		if err != nil {
+			return fmt.Errorf("could not connect to Bitcoin chain: [%v]", err)
+		}
+
+		feeRateEstimator, err := newBitcoinFeeRateEstimator(
+			btcChain,
+			clientConfig.Bitcoin,
+		)
+		if err != nil {
+			return fmt.Errorf("could not create fee rate estimator: [%v]", err)
+		}
+
+		redemptionMaxSize, err := tbtcChain.GetRedemptionMaxSize()
+		if err != nil {
+			return fmt.Errorf("failed to get redemption max size: [%v]", err)
+		}
+
+		task := tbtcpg.NewRedemptionTask(
+			tbtcChain,
+			btcChain,
+			feeRateEstimator,
+		)
+
+		redeemersOutputScripts, err := task.FindPendingRedemptions(
+			logger,
+			walletPublicKeyHash,
+			redemptionMaxSize,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to find pending redemptions: [%v]", err)
+		}
+
+		if len(redeemersOutputScripts) == 0 {
+			return fmt.Errorf("no pending redemptions")
+		}
+
+		proposal, err := task.ProposeRedemption(
+			logger,
+			walletPublicKeyHash,
+			redeemersOutputScripts,
+			0,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to prepare redemption proposal: [%v]", err)
+		}
+
+		psbt, err := tbtc.AssembleRedemptionPSBT(
+			logger,
+			walletPublicKeyHash,
+			proposal,
+			tbtcChain,
+			btcChain,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to assemble redemption PSBT: [%v]", err)
+		}
+
+		return printPsbt(psbt, clientConfig.Bitcoin.Network)
+	},
 }
 
-func newWalletPublicKeyHash(str string) ([20]byte, error) {
-	var result [20]byte
+var redemptionPsbtCommandDescription = "Prepares the redemption proposal " +
+	"for the given wallet, the same way the wallet coordinator would do, " +
+	"and prints the unsigned redemption transaction as a base64-encoded " +
+	"BIP-0174 PSBT along with its decoded content. The PSBT can be " +
+	"inspected using standard Bitcoin tooling, e.g. the decodepsbt RPC " +
+	"of Bitcoin Core"
+
+var decodePsbtCommand = cobra.Command{
+	Use:              "decode-psbt",
+	Short:            "decode PSBT",
+	Long:             "Decodes the given base64-encoded BIP-0174 PSBT of a wallet transaction and prints its content.",
+	TraverseChildren: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		psbtFlag, err := cmd.Flags().GetString(psbtFlagName)
+		if err != nil {
+			return fmt.Errorf("failed to find PSBT flag: [%v]", err)
+		}
 
-	walletHex, err := hexutils.Decode(str)
-	if err != nil {
-		return result, err
-	}
+		psbtBytes, err := base64.StdEncoding.DecodeString(psbtFlag)
+		if err != nil {
+			return fmt.Errorf("failed to decode PSBT flag: [%v]", err)
+		}
 
-	if len(walletHex) != 20 {
-		return result, fmt.Errorf("invalid bytes length: [%d], expected: [%d]", len(walletHex), 20)
-	}
+		psbt := new(bitcoin.PSBT)
+		if err := psbt.Deserialize(psbtBytes); err != nil {
+			return fmt.Errorf("failed to deserialize PSBT: [%v]", err)
+		}
 
-	copy(result[:], walletHex)
+		// Make sure the PSBT describes a transaction the wallet is able
+		// to work with.
+		if _, err := bitcoin.NewTransactionBuilderFromPSBT(nil, psbt); err != nil {
+			return fmt.Errorf("unsupported PSBT: [%v]", err)
+		}
 
-	return result, nil
+		return printPsbt(psbt, clientConfig.Bitcoin.Network)
+	},
+}
+
+var buildDepositRefundCommand = cobra.Command{
+	Use:              "build-deposit-refund",
+	Short:            "build deposit refund transaction",
+	Long:             buildDepositRefundCommandDescription,
+	TraverseChildren: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
+
+		network := clientConfig.Bitcoin.Network
+
+		deposit, err := newDepositFromFlags(cmd, network)
+		if err != nil {
+			return err
+		}
+
+		refundKeyFile, err := cmd.Flags().GetString(refundKeyFileFlagName)
+		if err != nil {
+			return fmt.Errorf("failed to find refund key file flag: [%v]", err)
+		}
+
+		refundKeyBytes, err := os.ReadFile(refundKeyFile)
+		if err != nil {
+			return fmt.Errorf("failed to read refund key file: [%v]", err)
+		}
+
+		refundPrivateKey, err := newRefundPrivateKey(
+			strings.TrimSpace(string(refundKeyBytes)),
+		)
+		if err != nil {
+			return fmt.Errorf("failed to parse refund key: [%v]", err)
+		}
+
+		deposit.RefundPublicKeyHash = bitcoin.PublicKeyHash(
+			&refundPrivateKey.PublicKey,
+		)
+
+		recipient, err := cmd.Flags().GetString(recipientFlagName)
+		if err != nil {
+			return fmt.Errorf("failed to find recipient flag: [%v]", err)
+		}
+
+		var outputScript bitcoin.Script
+		if len(recipient) > 0 {
+			outputScript, err = newOutputScript(recipient, network)
+			if err != nil {
+				return fmt.Errorf("failed to parse recipient: [%v]", err)
+			}
+		} else {
+			outputScript, err = bitcoin.PayToWitnessPublicKeyHash(
+				deposit.RefundPublicKeyHash,
+			)
+			if err != nil {
+				return fmt.Errorf("failed to compute output script: [%v]", err)
+			}
+		}
+
+		fee, err := cmd.Flags().GetInt64(feeFlagName)
+		if err != nil {
+			return fmt.Errorf("failed to find fee flag: [%v]", err)
+		}
+
+		btcChain, err := connectBitcoin(ctx, clientConfig.Bitcoin)
+		if err != nil {
+			return fmt.Errorf("could not connect to Bitcoin chain: [%v]", err)
+		}
+
+		fundingTransaction, err := btcChain.GetTransaction(
+			deposit.Utxo.Outpoint.TransactionHash,
+		)
+		if err != nil {
+			return fmt.Errorf(
+				"failed to get deposit funding transaction: [%v]",
+				err,
+			)
+		}
+
+		outputIndex := deposit.Utxo.Outpoint.OutputIndex
+		if int(outputIndex) >= len(fundingTransaction.Outputs) {
+			return fmt.Errorf(
+				"deposit funding transaction has no output with index [%v]",
+				outputIndex,
+			)
+		}
+		deposit.Utxo.Value = fundingTransaction.Outputs[outputIndex].Value
+
+		if fee == 0 {
+			// Build the transaction once to learn its virtual size.
+			transaction, err := tbtc.BuildDepositRefundTransaction(
+				btcChain,
+				deposit,
+				refundPrivateKey,
+				outputScript,
+				0,
+			)
+			if err != nil {
+				return fmt.Errorf(
+					"failed to build deposit refund transaction: [%v]",
+					err,
+				)
+			}
+
+			feeRateEstimator, err := newBitcoinFeeRateEstimator(
+				btcChain,
+				clientConfig.Bitcoin,
+			)
+			if err != nil {
+				return fmt.Errorf(
+					"could not create fee rate estimator: [%v]",
+					err,
+				)
+			}
+
+			fee, err = bitcoin.NewTransactionFeeEstimator(
+				feeRateEstimator,
+			).EstimateFee(transaction.VirtualSize())
+			if err != nil {
+				return fmt.Errorf("failed to estimate fee: [%v]", err)
+			}
+		}
+
+		if fee >= deposit.Utxo.Value {
+			return fmt.Errorf(
+				"fee [%v] exceeds deposit value [%v]",
+				fee,
+				deposit.Utxo.Value,
+			)
+		}
+
+		transaction, err := tbtc.BuildDepositRefundTransaction(
+			btcChain,
+			deposit,
+			refundPrivateKey,
+			outputScript,
+			fee,
+		)
+		if err != nil {
+			return fmt.Errorf(
+				"failed to build deposit refund transaction: [%v]",
+				err,
+			)
+		}
+
+		fmt.Printf(
+			"transaction hash: %s\n",
+			transaction.Hash().Hex(bitcoin.ReversedByteOrder),
+		)
+		fmt.Printf("fee (satoshis): %v\n", fee)
+		fmt.Printf("locktime: %v\n\n", transaction.Locktime)
+		fmt.Printf("%s\n", hex.EncodeToString(transaction.Serialize()))
+
+		return nil
+	},
+}
+
+var buildDepositRefundCommandDescription = "Builds a transaction refunding " +
+	"the given deposit to the depositor, signs it using the given refund " +
+	"key and prints the signed raw transaction in hex. The deposit is " +
+	"identified by its funding outpoint and the parameters used to build " +
+	"the deposit script. The refund public key hash is derived from the " +
+	"refund key. The transaction can be broadcast using standard Bitcoin " +
+	"tooling once the refund locktime passes, i.e. once the median time " +
+	"past of the latest Bitcoin blocks exceeds it. If the fee is not " +
+	"given, it is estimated based on the current Bitcoin network conditions."
+
+var inspectTransactionCommand = cobra.Command{
+	Use:              "inspect-transaction",
+	Short:            "inspect tBTC wallet transaction",
+	Long:             inspectTransactionCommandDescription,
+	TraverseChildren: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
+
+		_, tbtcChain, _, _, _, err := ethereum.Connect(
+			ctx,
+			clientConfig.Ethereum,
+		)
+		if err != nil {
+			return fmt.Errorf(
+				"could not connect to Ethereum chain: [%v]",
+				err,
+			)
+		}
+
+		btcChain, err := connectBitcoin(ctx, clientConfig.Bitcoin)
+		if err != nil {
+			return fmt.Errorf("could not connect to Bitcoin chain: [%v]", err)
+		}
+
+		btcDiffChain, err := connectBitcoinDifficultyReadOnly(ctx)
+		if err != nil {
+			return fmt.Errorf(
+				"could not connect to Bitcoin difficulty chain: [%v]",
+				err,
+			)
+		}
+
+		transactionHashFlag, err := cmd.Flags().GetString(transactionHashFlagName)
+		if err != nil {
+			return fmt.Errorf("failed to find transaction hash flag: [%v]", err)
+		}
+
+		transactionHash, err := bitcoin.NewHashFromString(
+			transactionHashFlag,
+			bitcoin.ReversedByteOrder,
+		)
+		if err != nil {
+			return fmt.Errorf(
+				"failed to parse transaction hash flag: [%v]",
+				err,
+			)
+		}
+
+		inspection, err := spv.InspectTransaction(
+			transactionHash,
+			btcChain,
+			tbtcChain,
+			btcDiffChain,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to inspect transaction: [%v]", err)
+		}
+
+		return printTransactionInspection(
+			inspection,
+			clientConfig.Bitcoin.Network,
+		)
+	},
+}
+
+var inspectTransactionCommandDescription = "Fetches the given Bitcoin " +
+	"transaction and classifies it as a deposit funding, deposit sweep, " +
+	"redemption, moving funds or moved funds sweep transaction. For each " +
+	"input spending a deposit, the deposit script is decoded back into the " +
+	"deposit parameters. Inputs and outputs are matched against the Bridge " +
+	"state and, for wallet transactions not proven yet, the SPV proof " +
+	"status is shown. Deposit funding transactions are recognized only once " +
+	"at least one of their deposits is revealed to the Bridge."
+
+// printTransactionInspection prints the transaction inspection in a human
+// readable form, for example:
+//
+// transaction: 0a1b...
+// kind: redemption
+// wallet: 0x8db5... (bc1q...)
+// wallet state: Live
+// confirmations: 3
+// proven: false
+// proof: within relay range, 3 of 6 confirmations
+//
+// input outpoint   value (satoshis) type   deposit request moved funds sweep request
+// 0     c3d4...:1 100000           P2WPKH -               -
+//
+// output value (satoshis) type   address    wallet change redemption request deposit request moved funds sweep request
+// 0      30000            P2WPKH bc1q...    false         31000               -               -
+func printTransactionInspection(
+	inspection *spv.TransactionInspection,
+	network bitcoin.Network,
+) error {
+	fmt.Printf(
+		"transaction: %s\n",
+		inspection.Transaction.Hash().Hex(bitcoin.ReversedByteOrder),
+	)
+	fmt.Printf("kind: %s\n", inspection.Kind)
+
+	if inspection.WalletPublicKeyHash != nil {
+		fmt.Printf(
+			"wallet: %s (%s)\n",
+			hexutils.Encode(inspection.WalletPublicKeyHash[:]),
+			walletAddress(*inspection.WalletPublicKeyHash, network),
+		)
+
+		walletState := "not registered"
+		if inspection.Wallet != nil {
+			walletState = inspection.Wallet.State.String()
+		}
+		fmt.Printf("wallet state: %s\n", walletState)
+	}
+
+	fmt.Printf("confirmations: %v\n", inspection.Confirmations)
+
+	if inspection.WalletPublicKeyHash != nil {
+		fmt.Printf("proven: %t\n", inspection.Proven)
+	}
+
+	if inspection.ProofStatus != nil {
+		if inspection.ProofStatus.WithinRelayRange {
+			fmt.Printf(
+				"proof: within relay range, %v of %v confirmations\n",
+				inspection.ProofStatus.AccumulatedConfirmations,
+				inspection.ProofStatus.RequiredConfirmations,
+			)
+		} else {
+			fmt.Printf("proof: outside of relay range\n")
+		}
+	}
+
+	writer := tabwriter.NewWriter(os.Stdout, 2, 4, 1, ' ', 0)
+
+	_, err := fmt.Fprintf(
+		writer,
+		"\ninput\toutpoint\tvalue (satoshis)\ttype\tdeposit request\t"+
+			"moved funds sweep request\t\n",
+	)
+	if err != nil {
+		return err
+	}
+
+	for i, input := range inspection.Inputs {
+		value := "-"
+		scriptType := "-"
+		if input.PreviousOutput != nil {
+			value = fmt.Sprintf("%v", input.PreviousOutput.Value)
+			scriptType = bitcoin.GetScriptType(
+				input.PreviousOutput.PublicKeyScript,
+			).String()
+		}
+
+		_, err := fmt.Fprintf(
+			writer,
+			"%v\t%s:%v\t%s\t%s\t%s\t%s\t\n",
+			i,
+			input.Outpoint.TransactionHash.Hex(bitcoin.ReversedByteOrder),
+			input.Outpoint.OutputIndex,
+			value,
+			scriptType,
+			depositRequestSummary(input.DepositRequest),
+			movedFundsSweepRequestSummary(input.MovedFundsSweepRequest),
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	_, err = fmt.Fprintf(
+		writer,
+		"\noutput\tvalue (satoshis)\ttype\taddress\twallet change\t"+
+			"redemption request\tdeposit request\tmoved funds sweep request\t\n",
+	)
+	if err != nil {
+		return err
+	}
+
+	for i, output := range inspection.Outputs {
+		redemptionRequest := "-"
+		if output.RedemptionRequest != nil {
+			redemptionRequest = fmt.Sprintf(
+				"%v",
+				output.RedemptionRequest.RequestedAmount,
+			)
+		}
+
+		_, err := fmt.Fprintf(
+			writer,
+			"%v\t%v\t%s\t%s\t%t\t%s\t%s\t%s\t\n",
+			i,
+			output.Output.Value,
+			bitcoin.GetScriptType(output.Output.PublicKeyScript),
+			scriptAddress(output.Output.PublicKeyScript, network),
+			output.WalletChange,
+			redemptionRequest,
+			depositRequestSummary(output.DepositRequest),
+			movedFundsSweepRequestSummary(output.MovedFundsSweepRequest),
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	if err := writer.Flush(); err != nil {
+		return fmt.Errorf("failed to flush the writer: %v", err)
+	}
+
+	for i, input := range inspection.Inputs {
+		if input.Deposit == nil {
+			continue
+		}
+
+		deposit := input.Deposit
+
+		fmt.Printf("\ninput %v deposit:\n", i)
+		fmt.Printf("  depositor: %s\n", deposit.Depositor)
+		fmt.Printf(
+			"  blinding factor: %s\n",
+			hexutils.Encode(deposit.BlindingFactor[:]),
+		)
+		fmt.Printf(
+			"  wallet public key hash: %s (%s)\n",
+			hexutils.Encode(deposit.WalletPublicKeyHash[:]),
+			walletAddress(deposit.WalletPublicKeyHash, network),
+		)
+		fmt.Printf(
+			"  refund public key hash: %s (%s)\n",
+			hexutils.Encode(deposit.RefundPublicKeyHash[:]),
+			publicKeyHashAddress(deposit.RefundPublicKeyHash, network),
+		)
+		fmt.Printf(
+			"  refund locktime: %s (%v)\n",
+			hexutils.Encode(deposit.RefundLocktime[:]),
+			binary.LittleEndian.Uint32(deposit.RefundLocktime[:]),
+		)
+		if deposit.ExtraData != nil {
+			fmt.Printf(
+				"  extra data: %s\n",
+				hexutils.Encode(deposit.ExtraData[:]),
+			)
+		}
+	}
+
+	return nil
+}
+
+// depositRequestSummary returns a short description of the given Bridge
+// deposit request or a dash if the request is nil.
+func depositRequestSummary(request *tbtc.DepositChainRequest) string {
+	if request == nil {
+		return "-"
+	}
+
+	if request.SweptAt.Unix() == 0 {
+		return fmt.Sprintf("revealed at %v", request.RevealedAt.Unix())
+	}
+
+	return fmt.Sprintf("swept at %v", request.SweptAt.Unix())
+}
+
+// movedFundsSweepRequestSummary returns a short description of the given
+// Bridge moved funds sweep request or a dash if the request is nil.
+func movedFundsSweepRequestSummary(
+	request *tbtc.MovedFundsSweepRequest,
+) string {
+	if request == nil {
+		return "-"
+	}
+
+	switch request.State {
+	case tbtc.MovedFundsStatePending:
+		return "pending"
+	case tbtc.MovedFundsStateProcessed:
+		return "processed"
+	case tbtc.MovedFundsStateTimedOut:
+		return "timed out"
+	default:
+		return "unknown"
+	}
+}
+
+var listWalletDescriptorsCommand = cobra.Command{
+	Use:              "list-wallet-descriptors",
+	Short:            "get output descriptors of wallets",
+	Long:             listWalletDescriptorsCommandDescription,
+	TraverseChildren: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
+
+		importFormat, err := cmd.Flags().GetBool(importFormatFlagName)
+		if err != nil {
+			return fmt.Errorf("failed to find import format flag: [%v]", err)
+		}
+
+		_, tbtcChain, _, _, _, err := ethereum.Connect(
+			ctx,
+			clientConfig.Ethereum,
+		)
+		if err != nil {
+			return fmt.Errorf(
+				"could not connect to Ethereum chain: [%v]",
+				err,
+			)
+		}
+
+		btcChain, err := connectBitcoin(ctx, clientConfig.Bitcoin)
+		if err != nil {
+			return fmt.Errorf("could not connect to Bitcoin chain: [%v]", err)
+		}
+
+		walletsDescriptors, err := tbtcpg.FindWalletDescriptors(
+			tbtcChain,
+			btcChain,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to get wallet descriptors: [%v]", err)
+		}
+
+		if importFormat {
+			return printImportDescriptorsRequest(walletsDescriptors)
+		}
+
+		if err := printWalletDescriptorsTable(
+			walletsDescriptors,
+			clientConfig.Bitcoin.Network,
+		); err != nil {
+			return fmt.Errorf(
+				"failed to print wallet descriptors table: [%v]",
+				err,
+			)
+		}
+
+		return nil
+	},
+}
+
+var listWalletDescriptorsCommandDescription = "Gets all wallets registered " +
+	"in the Bridge that may hold funds, i.e. Live and MovingFunds wallets, " +
+	"and prints the output descriptors of their P2WPKH and P2PKH scripts " +
+	"along with their current main UTXOs. With the --" + importFormatFlagName +
+	" flag, the descriptors are printed as a JSON array that can be passed " +
+	"directly to the importdescriptors RPC of a watch-only Bitcoin Core " +
+	"wallet. The rescan timestamp of each descriptor is the wallet " +
+	"creation time."
+
+// importDescriptorsRequest is a single request of the Bitcoin Core
+// importdescriptors RPC.
+type importDescriptorsRequest struct {
+	Descriptor string `json:"desc"`
+	Timestamp  int64  `json:"timestamp"`
+	Label      string `json:"label"`
+}
+
+// printImportDescriptorsRequest prints the descriptors of the given wallets
+// as a JSON array of importdescriptors RPC requests.
+func printImportDescriptorsRequest(
+	walletsDescriptors []*tbtcpg.WalletDescriptors,
+) error {
+	requests := make([]*importDescriptorsRequest, 0)
+
+	for _, walletDescriptors := range walletsDescriptors {
+		label := fmt.Sprintf(
+			"tbtc-wallet-%s",
+			hex.EncodeToString(walletDescriptors.WalletPublicKeyHash[:]),
+		)
+
+		for _, descriptor := range []string{
+			walletDescriptors.WitnessPublicKeyHashDescriptor,
+			walletDescriptors.PublicKeyHashDescriptor,
+		} {
+			requests = append(requests, &importDescriptorsRequest{
+				Descriptor: descriptor,
+				Timestamp:  walletDescriptors.CreatedAt.Unix(),
+				Label:      label,
+			})
+		}
+	}
+
+	requestsJSON, err := json.MarshalIndent(requests, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal descriptors: [%v]", err)
+	}
+
+	fmt.Printf("%s\n", requestsJSON)
+
+	return nil
+}
+
+func printWalletDescriptorsTable(
+	walletsDescriptors []*tbtcpg.WalletDescriptors,
+	network bitcoin.Network,
+) error {
+	w := tabwriter.NewWriter(os.Stdout, 2, 4, 1, ' ', 0)
+	fmt.Fprintf(w, "wallet\twallet address\tstate\tdescriptor\tmain utxo\tmain utxo value (satoshis)\t\n")
+
+	for _, walletDescriptors := range walletsDescriptors {
+		mainUtxo := "-"
+		mainUtxoValue := "-"
+		if walletDescriptors.MainUtxo != nil {
+			mainUtxo = fmt.Sprintf(
+				"%s:%d",
+				walletDescriptors.MainUtxo.Outpoint.TransactionHash.Hex(
+					bitcoin.ReversedByteOrder,
+				),
+				walletDescriptors.MainUtxo.Outpoint.OutputIndex,
+			)
+			mainUtxoValue = fmt.Sprintf("%d", walletDescriptors.MainUtxo.Value)
+		}
+
+		for _, descriptor := range []string{
+			walletDescriptors.WitnessPublicKeyHashDescriptor,
+			walletDescriptors.PublicKeyHashDescriptor,
+		} {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t\n",
+				hexutils.Encode(walletDescriptors.WalletPublicKeyHash[:]),
+				walletAddress(walletDescriptors.WalletPublicKeyHash, network),
+				walletDescriptors.State,
+				descriptor,
+				mainUtxo,
+				mainUtxoValue,
+			)
+		}
+	}
+
+	if err := w.Flush(); err != nil {
+		return fmt.Errorf("failed to flush the writer: %v", err)
+	}
+
+	return nil
+}
+
+var proofOfReservesCommand = cobra.Command{
+	Use:              "proof-of-reserves",
+	Short:            "build proof of reserves report",
+	Long:             proofOfReservesCommandDescription,
+	TraverseChildren: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
+
+		sign, err := cmd.Flags().GetBool(signFlagName)
+		if err != nil {
+			return fmt.Errorf("failed to find sign flag: [%v]", err)
+		}
+
+		_, tbtcChain, _, signing, _, err := ethereum.Connect(
+			ctx,
+			clientConfig.Ethereum,
+		)
+		if err != nil {
+			return fmt.Errorf(
+				"could not connect to Ethereum chain: [%v]",
+				err,
+			)
+		}
+
+		btcChain, err := connectBitcoin(ctx, clientConfig.Bitcoin)
+		if err != nil {
+			return fmt.Errorf("could not connect to Bitcoin chain: [%v]", err)
+		}
+
+		report, err := tbtcpg.BuildReservesReport(tbtcChain, btcChain)
+		if err != nil {
+			return fmt.Errorf(
+				"failed to build proof of reserves report: [%v]",
+				err,
+			)
+		}
+
+		var output interface{} = report
+		if sign {
+			output, err = tbtcpg.SignReservesReport(report, signing)
+			if err != nil {
+				return fmt.Errorf(
+					"failed to sign proof of reserves report: [%v]",
+					err,
+				)
+			}
+		}
+
+		outputJSON, err := json.MarshalIndent(output, "", "  ")
+		if err != nil {
+			return fmt.Errorf(
+				"failed to marshal proof of reserves report: [%v]",
+				err,
+			)
+		}
+
+		fmt.Printf("%s\n", outputJSON)
+
+		return nil
+	},
+}
+
+var proofOfReservesCommandDescription = "Reconciles the Bridge accounting " +
+	"with the actual Bitcoin holdings of all wallets that may hold funds, " +
+	"i.e. Live and MovingFunds wallets, and prints the result as a JSON " +
+	"report. For each wallet, the report compares the value of the main " +
+	"UTXO registered in the Bridge with the value of UTXOs controlled by " +
+	"the wallet on the Bitcoin chain, and lists unswept deposits, in-flight " +
+	"mempool transactions and found discrepancies. With the --" +
+	signFlagName + " flag, the report is signed using the operator key. " +
+	"The signature is computed over the compact JSON encoding of the report."
+
+// newDepositFromFlags parses deposit parameters given to the command,
+// except the refund public key hash and the deposit value.
+func newDepositFromFlags(
+	cmd *cobra.Command,
+	network bitcoin.Network,
+) (*tbtc.Deposit, error) {
+	fundingTransactionHash, err := cmd.Flags().GetString(
+		fundingTransactionHashFlagName,
+	)
+	if err != nil {
+		return nil, fmt.Errorf(
+			"failed to find funding transaction hash flag: [%v]",
+			err,
+		)
+	}
+
+	fundingOutputIndex, err := cmd.Flags().GetUint32(
+		fundingOutputIndexFlagName,
+	)
+	if err != nil {
+		return nil, fmt.Errorf(
+			"failed to find funding output index flag: [%v]",
+			err,
+		)
+	}
+
+	depositor, err := cmd.Flags().GetString(depositorFlagName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find depositor flag: [%v]", err)
+	}
+
+	blindingFactor, err := cmd.Flags().GetString(blindingFactorFlagName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find blinding factor flag: [%v]", err)
+	}
+
+	wallet, err := cmd.Flags().GetString(walletFlagName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find wallet flag: [%v]", err)
+	}
+
+	refundLocktime, err := cmd.Flags().GetString(refundLocktimeFlagName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find refund locktime flag: [%v]", err)
+	}
+
+	extraData, err := cmd.Flags().GetString(extraDataFlagName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find extra data flag: [%v]", err)
+	}
+
+	transactionHash, err := bitcoin.NewHashFromString(
+		fundingTransactionHash,
+		bitcoin.ReversedByteOrder,
+	)
+	if err != nil {
+		return nil, fmt.Errorf(
+			"failed to parse funding transaction hash: [%v]",
+			err,
+		)
+	}
+
+	depositorBytes, err := decodeFixedLengthHex(depositor, 20)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse depositor: [%v]", err)
+	}
+
+	blindingFactorBytes, err := decodeFixedLengthHex(blindingFactor, 8)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse blinding factor: [%v]", err)
+	}
+
+	walletPublicKeyHash, err := newWalletPublicKeyHash(wallet, network)
+	if err != nil {
+		return nil, fmt.Errorf(
+			"failed to extract wallet public key hash: [%v]",
+			err,
+		)
+	}
+
+	refundLocktimeBytes, err := decodeFixedLengthHex(refundLocktime, 4)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse refund locktime: [%v]", err)
+	}
+
+	deposit := &tbtc.Deposit{
+		Utxo: &bitcoin.UnspentTransactionOutput{
+			Outpoint: &bitcoin.TransactionOutpoint{
+				TransactionHash: transactionHash,
+				OutputIndex:     fundingOutputIndex,
+			},
+		},
+		Depositor:           chain.Address(hexutils.Encode(depositorBytes)),
+		WalletPublicKeyHash: walletPublicKeyHash,
+	}
+	copy(deposit.BlindingFactor[:], blindingFactorBytes)
+	copy(deposit.RefundLocktime[:], refundLocktimeBytes)
+
+	if len(extraData) > 0 {
+		extraDataBytes, err := decodeFixedLengthHex(extraData, 32)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse extra data: [%v]", err)
+		}
+
+		deposit.ExtraData = new([32]byte)
+		copy(deposit.ExtraData[:], extraDataBytes)
+	}
+
+	return deposit, nil
+}
+
+// connectBitcoinDifficultyReadOnly connects to the Bitcoin difficulty chain
+// used to verify SPV proofs before they are submitted. The relay is only read
+// so the maintainer proxy contract is not needed.
+func connectBitcoinDifficultyReadOnly(
+	ctx context.Context,
+) (btcdiff.Chain, error) {
+	return ethereum.ConnectBitcoinDifficulty(
+		ctx,
+		clientConfig.Ethereum,
+		maintainer.Config{
+			BitcoinDifficulty: btcdiff.Config{
+				DisableProxy: true,
+			},
+		},
+	)
+}
+
+// printPsbt prints the given PSBT encoded in base64 and its decoded inputs
+// and outputs to the standard output. For example:
+//
+// psbt: cHNidP8BAH0CAAAAAb...
+//
+// input  outpoint                                                             value (satoshis) type   finalized
+//
+// 0      b3f1...4a7e:0                                                        100000           P2WPKH false
+// 1      8a0c...11d2:1                                                        15000            P2WSH  false
+//
+// output value (satoshis) type   script  address
+//
+// 0      112000           P2WPKH 0014... bc1q...
+//
+// fee (satoshis): 3000
+func printPsbt(psbt *bitcoin.PSBT, network bitcoin.Network) error {
+	psbtBytes, err := psbt.Serialize()
+	if err != nil {
+		return fmt.Errorf("failed to serialize PSBT: [%v]", err)
+	}
+
+	fmt.Printf("psbt: %s\n\n", base64.StdEncoding.EncodeToString(psbtBytes))
+
+	writer := tabwriter.NewWriter(os.Stdout, 2, 4, 1, ' ', 0)
+
+	_, err = fmt.Fprintf(writer, "input\toutpoint\tvalue (satoshis)\ttype\tfinalized\t\n")
+	if err != nil {
+		return err
+	}
+
+	totalInputsValue := int64(0)
+	for i, input := range psbt.Inputs {
+		outpoint := psbt.UnsignedTransaction.Inputs[i].Outpoint
+
+		var utxo *bitcoin.TransactionOutput
+		if input.WitnessUtxo != nil {
+			utxo = input.WitnessUtxo
+		} else if input.NonWitnessUtxo != nil &&
+			int(outpoint.OutputIndex) < len(input.NonWitnessUtxo.Outputs) {
+			utxo = input.NonWitnessUtxo.Outputs[outpoint.OutputIndex]
+		}
+
+		if utxo == nil {
+			return fmt.Errorf("UTXO spent by input [%v] is unknown", i)
+		}
+
+		totalInputsValue += utxo.Value
+
+		_, err := fmt.Fprintf(
+			writer,
+			"%v\t%s:%v\t%v\t%s\t%t\t\n",
+			i,
+			outpoint.TransactionHash.Hex(bitcoin.ReversedByteOrder),
+			outpoint.OutputIndex,
+			utxo.Value,
+			bitcoin.GetScriptType(utxo.PublicKeyScript),
+			input.IsFinalized(),
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	_, err = fmt.Fprintf(writer, "\noutput\tvalue (satoshis)\ttype\tscript\taddress\t\n")
+	if err != nil {
+		return err
+	}
+
+	totalOutputsValue := int64(0)
+	for i, output := range psbt.UnsignedTransaction.Outputs {
+		totalOutputsValue += output.Value
+
+		_, err := fmt.Fprintf(
+			writer,
+			"%v\t%v\t%s\t%s\t%s\t\n",
+			i,
+			output.Value,
+			bitcoin.GetScriptType(output.PublicKeyScript),
+			hexutils.Encode(output.PublicKeyScript),
+			scriptAddress(output.PublicKeyScript, network),
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	if err := writer.Flush(); err != nil {
+		return fmt.Errorf("failed to flush the writer: %v", err)
+	}
+
+	fmt.Printf("\nfee (satoshis): %v\n", totalInputsValue-totalOutputsValue)
+
+	return nil
+}
+
+func init() {
+	initFlags(
+		MaintainerCliCommand,
+		&configFilePath,
+		clientConfig,
+		config.General, config.Ethereum, config.BitcoinElectrum,
+	)
+
+	// Deposits Subcommand
+	listDepositsCommand.Flags().String(
+		walletFlagName,
+		"",
+		"wallet public key hash or P2PKH/P2WPKH address",
+	)
+
+	listDepositsCommand.Flags().Bool(
+		hideSweptFlagName,
+		false,
+		"hide swept deposits",
+	)
+
+	listDepositsCommand.Flags().Int(
+		headFlagName,
+		0,
+		"get head of deposits",
+	)
+
+	MaintainerCliCommand.AddCommand(&listDepositsCommand)
+
+	// Estimate Deposits Sweep Fee Subcommand.
+	estimateDepositsSweepFeeCommand.Flags().Int(
+		depositsCountFlagName,
+		0,
+		"get estimation for a specific count of input deposits",
+	)
+
+	MaintainerCliCommand.AddCommand(&estimateDepositsSweepFeeCommand)
+
+	// Submit Deposit Sweep Proof Subcommand.
+
+	submitDepositSweepProofCommand.Flags().String(
+		transactionHashFlagName,
+		"",
+		"transaction hash the proof will be prepared for (the format should "+
+			"be the same as in Bitcoin explorers).",
+	)
+
+	if err := submitDepositSweepProofCommand.MarkFlagRequired(
+		transactionHashFlagName,
+	); err != nil {
+		logger.Fatalf("failed to mark flag required: [%v]", err)
+	}
+
+	submitDepositSweepProofCommand.Flags().Uint(
+		confirmationsFlagName,
+		0,
+		"(optional) number of confirmations that will be provided in the proof. "+
+			"This is an optional parameter that can be used in a rare event when "+
+			"more confirmations are required to perform a successful proof "+
+			"validation. If this parameter is not provided, the default value, "+
+			"retrieved from the Bridge will be used.",
+	)
+
+	MaintainerCliCommand.AddCommand(&submitDepositSweepProofCommand)
+
+	// Submit Redemption Proof Subcommand.
+
+	submitRedemptionProofCommand.Flags().String(
+		transactionHashFlagName,
+		"",
+		"transaction hash the proof will be prepared for (the format should "+
+			"be the same as in Bitcoin explorers).",
+	)
+
+	if err := submitRedemptionProofCommand.MarkFlagRequired(
+		transactionHashFlagName,
+	); err != nil {
+		logger.Fatalf("failed to mark flag required: [%v]", err)
+	}
+
+	submitRedemptionProofCommand.Flags().Uint(
+		confirmationsFlagName,
+		0,
+		"(optional) number of confirmations that will be provided in the proof. "+
+			"This is an optional parameter that can be used in a rare event when "+
+			"more confirmations are required to perform a successful proof "+
+			"validation. If this parameter is not provided, the default value, "+
+			"retrieved from the Bridge will be used.",
+	)
+
+	MaintainerCliCommand.AddCommand(&submitRedemptionProofCommand)
+
+	// Deposit Sweep PSBT Subcommand.
+
+	depositSweepPsbtCommand.Flags().String(
+		walletFlagName,
+		"",
+		"wallet public key hash or P2PKH/P2WPKH address",
+	)
+
+	if err := depositSweepPsbtCommand.MarkFlagRequired(
+		walletFlagName,
+	); err != nil {
+		logger.Fatalf("failed to mark flag required: [%v]", err)
+	}
+
+	MaintainerCliCommand.AddCommand(&depositSweepPsbtCommand)
+
+	// Redemption PSBT Subcommand.
+
+	redemptionPsbtCommand.Flags().String(
+		walletFlagName,
+		"",
+		"wallet public key hash or P2PKH/P2WPKH address",
+	)
+
+	if err := redemptionPsbtCommand.MarkFlagRequired(
+		walletFlagName,
+	); err != nil {
+		logger.Fatalf("failed to mark flag required: [%v]", err)
+	}
+
+	MaintainerCliCommand.AddCommand(&redemptionPsbtCommand)
+
+	// Decode PSBT Subcommand.
+
+	decodePsbtCommand.Flags().String(
+		psbtFlagName,
+		"",
+		"base64-encoded PSBT",
+	)
+
+	if err := decodePsbtCommand.MarkFlagRequired(
+		psbtFlagName,
+	); err != nil {
+		logger.Fatalf("failed to mark flag required: [%v]", err)
+	}
+
+	MaintainerCliCommand.AddCommand(&decodePsbtCommand)
+
+	// Build Deposit Refund Subcommand.
+
+	buildDepositRefundCommand.Flags().String(
+		fundingTransactionHashFlagName,
+		"",
+		"deposit funding transaction hash (the format should be the same "+
+			"as in Bitcoin explorers)",
+	)
+
+	buildDepositRefundCommand.Flags().Uint32(
+		fundingOutputIndexFlagName,
+		0,
+		"deposit funding output index",
+	)
+
+	buildDepositRefundCommand.Flags().String(
+		depositorFlagName,
+		"",
+		"depositor address on the host chain",
+	)
+
+	buildDepositRefundCommand.Flags().String(
+		blindingFactorFlagName,
+		"",
+		"hex-encoded 8-byte blinding factor",
+	)
+
+	buildDepositRefundCommand.Flags().String(
+		walletFlagName,
+		"",
+		"wallet public key hash or P2PKH/P2WPKH address",
+	)
+
+	buildDepositRefundCommand.Flags().String(
+		refundLocktimeFlagName,
+		"",
+		"hex-encoded 4-byte refund locktime, in the little-endian byte "+
+			"order used by the deposit script",
+	)
+
+	buildDepositRefundCommand.Flags().String(
+		extraDataFlagName,
+		"",
+		"(optional) hex-encoded 32-byte extra data of the deposit",
+	)
+
+	buildDepositRefundCommand.Flags().String(
+		refundKeyFileFlagName,
+		"",
+		"path to the file holding the refund private key, either in the "+
+			"WIF format or hex-encoded",
+	)
+
+	buildDepositRefundCommand.Flags().String(
+		recipientFlagName,
+		"",
+		"(optional) address or hex-encoded output script the refund is "+
+			"paid to; the P2WPKH address of the refund key is used by default",
+	)
+
+	buildDepositRefundCommand.Flags().Int64(
+		feeFlagName,
+		0,
+		"(optional) transaction fee in satoshis; estimated if not given",
+	)
+
+	for _, flagName := range []string{
+		fundingTransactionHashFlagName,
+		depositorFlagName,
+		blindingFactorFlagName,
+		walletFlagName,
+		refundLocktimeFlagName,
+		refundKeyFileFlagName,
+	} {
+		if err := buildDepositRefundCommand.MarkFlagRequired(
+			flagName,
+		); err != nil {
+			logger.Fatalf("failed to mark flag required: [%v]", err)
+		}
+	}
+
+	MaintainerCliCommand.AddCommand(&buildDepositRefundCommand)
+
+	// Inspect Transaction Subcommand.
+
+	inspectTransactionCommand.Flags().String(
+		transactionHashFlagName,
+		"",
+		"transaction hash to inspect (the format should be the same as in "+
+			"Bitcoin explorers).",
+	)
+
+	if err := inspectTransactionCommand.MarkFlagRequired(
+		transactionHashFlagName,
+	); err != nil {
+		logger.Fatalf("failed to mark flag required: [%v]", err)
+	}
+
+	MaintainerCliCommand.AddCommand(&inspectTransactionCommand)
+
+	// List Wallet Descriptors Subcommand.
+
+	listWalletDescriptorsCommand.Flags().Bool(
+		importFormatFlagName,
+		false,
+		"print descriptors as a JSON array of Bitcoin Core importdescriptors "+
+			"RPC requests",
+	)
+
+	MaintainerCliCommand.AddCommand(&listWalletDescriptorsCommand)
+
+	// Proof Of Reserves Subcommand.
+
+	proofOfReservesCommand.Flags().Bool(
+		signFlagName,
+		false,
+		"sign the report using the operator key",
+	)
+
+	MaintainerCliCommand.AddCommand(&proofOfReservesCommand)
+}
+
+// newWalletPublicKeyHash parses the given wallet public key hash. The
+// wallet can be given either as a hex-encoded public key hash or as
+// a P2PKH or P2WPKH address valid for the given Bitcoin network.
+func newWalletPublicKeyHash(
+	str string,
+	network bitcoin.Network,
+) ([20]byte, error) {
+	var result [20]byte
+
+	walletHex, err := hexutils.Decode(str)
+	if err != nil {
+		// Input that is not a hex string is expected to be an address.
+		if len(str) == 0 || strings.HasPrefix(strings.ToLower(str), "0x") {
+			return result, err
+		}
+
+		script, err := bitcoin.NewScriptFromAddress(str, network)
+		if err != nil {
+			return result, err
+		}
+
+		return bitcoin.ExtractPublicKeyHash(script)
+	}
+
+	if len(walletHex) != 20 {
+		return result, fmt.Errorf("invalid bytes length: [%d], expected: [%d]", len(walletHex), 20)
+	}
+
+	copy(result[:], walletHex)
+
+	return result, nil
+}
+
+// walletAddress returns the P2WPKH address of the wallet with the given
+// public key hash. Wallets hold their funds in P2WPKH outputs.
+func walletAddress(
+	walletPublicKeyHash [20]byte,
+	network bitcoin.Network,
+) string {
+	return publicKeyHashAddress(walletPublicKeyHash, network)
+}
+
+// publicKeyHashAddress returns the P2WPKH address of the given public key
+// hash or an empty string if the address cannot be constructed.
+func publicKeyHashAddress(
+	publicKeyHash [20]byte,
+	network bitcoin.Network,
+) string {
+	script, err := bitcoin.PayToWitnessPublicKeyHash(publicKeyHash)
+	if err != nil {
+		return ""
+	}
+
+	return scriptAddress(script, network)
+}
+
+// scriptAddress returns the address of the given output script for the
+// given Bitcoin network or an empty string if the script has no standard
+// address form.
+func scriptAddress(script bitcoin.Script, network bitcoin.Network) string {
+	address, err := script.Address(network)
+	if err != nil {
+		return ""
+	}
+
+	return address
+}
+
+// decodeFixedLengthHex decodes the given hex string, optionally prefixed
+// with 0x, and makes sure the result has the given byte length.
+func decodeFixedLengthHex(str string, length int) ([]byte, error) {
+	result, err := hexutils.Decode(str)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(result) != length {
+		return nil, fmt.Errorf(
+			"invalid bytes length: [%d], expected: [%d]",
+			len(result),
+			length,
+		)
+	}
+
+	return result, nil
+}
+
+// newRefundPrivateKey parses the given refund private key. The key can be
+// given either in the WIF format or as a hex-encoded 32-byte private key.
+func newRefundPrivateKey(str string) (*ecdsa.PrivateKey, error) {
+	if wif, err := btcutil.DecodeWIF(str); err == nil {
+		return wif.PrivKey.ToECDSA(), nil
+	}
+
+	privateKeyBytes, err := decodeFixedLengthHex(str, 32)
+	if err != nil {
+		return nil, fmt.Errorf(
+			"key is neither a WIF nor a hex-encoded private key: [%v]",
+			err,
+		)
+	}
+
+	privateKey, _ := btcec.PrivKeyFromBytes(btcec.S256(), privateKeyBytes)
+
+	return privateKey.ToECDSA(), nil
+}
+
+// newOutputScript parses the given output script. The script can be given
+// either as an address valid for the given Bitcoin network or as
+// a hex-encoded script.
+func newOutputScript(
+	str string,
+	network bitcoin.Network,
+) (bitcoin.Script, error) {
+	if script, err := bitcoin.NewScriptFromAddress(str, network); err == nil {
+		return script, nil
+	}
+
+	script, err := hexutils.Decode(str)
+	if err != nil {
+		return nil, fmt.Errorf(
+			"value is neither an address nor a hex-encoded script: [%v]",
+			err,
+		)
+	}
+
+	return script, nil
 }
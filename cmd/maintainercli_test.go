@@ -1,11 +1,13 @@
 package cmd
 
 import (
+	"encoding/hex"
 	"fmt"
 	"reflect"
 	"testing"
 
 	"github.com/keep-network/keep-core/internal/testutils"
+	"github.com/keep-network/keep-core/pkg/bitcoin"
 )
 
 var walletPublicKeyHashTests = []struct {
@@ -26,12 +28,17 @@ var walletPublicKeyHashTests = []struct {
 	{input: `0x48b88e1074c33c7a934f781220e1a4523f1768c0`, expectedResult: [20]byte{72, 184, 142, 16, 116, 195, 60, 122, 147, 79, 120, 18, 32, 225, 164, 82, 63, 23, 104, 192}},
 	{input: `0x00008e1074c33c7a934f781220e1a4523f1768c0`, expectedResult: [20]byte{00, 00, 142, 16, 116, 195, 60, 122, 147, 79, 120, 18, 32, 225, 164, 82, 63, 23, 104, 192}},
 	{input: `0x48b88e1074c33c7a934f781220e1a4523f000000`, expectedResult: [20]byte{72, 184, 142, 16, 116, 195, 60, 122, 147, 79, 120, 18, 32, 225, 164, 82, 63, 00, 00, 00}},
+	// addresses
+	{input: `bc1qw508d6qejxtdg4y5r3zarvary0c5xw7kv8f3t4`, expectedResult: [20]byte{117, 30, 118, 232, 25, 145, 150, 212, 84, 148, 28, 69, 209, 179, 163, 35, 241, 67, 59, 214}},
+	{input: `1BgGZ9tcN4rm9KBzDn7KprQz87SZ26SAMH`, expectedResult: [20]byte{117, 30, 118, 232, 25, 145, 150, 212, 84, 148, 28, 69, 209, 179, 163, 35, 241, 67, 59, 214}},
+	{input: `3QJmV3qfvL9SuYo34YihAf3sRCW3qSinyC`, wantErr: fmt.Errorf("not a P2WPKH or P2PKH script")},
+	{input: `tb1qw508d6qejxtdg4y5r3zarvary0c5xw7kxpjzsx`, wantErr: fmt.Errorf("address human-readable part [tb] is not valid for the network")},
 }
 
 func TestNewWalletPublicKeyHash(t *testing.T) {
 	for _, test := range walletPublicKeyHashTests {
 		t.Run(test.input, func(t *testing.T) {
-			actualResult, err := newWalletPublicKeyHash(test.input)
+			actualResult, err := newWalletPublicKeyHash(test.input, bitcoin.Mainnet)
 			if !reflect.DeepEqual(err, test.wantErr) {
 				t.Fatalf("unexpected error\nexpected: %v\nactual:   %v", test.wantErr, err)
 			}
@@ -40,3 +47,99 @@ func TestNewWalletPublicKeyHash(t *testing.T) {
 		})
 	}
 }
+
+func TestNewRefundPrivateKey(t *testing.T) {
+	var tests = map[string]struct {
+		input       string
+		expectedKey string
+		expectedErr error
+	}{
+		"uncompressed WIF": {
+			input:       "5HueCGU8rMjxEXxiPuD5BDku4MkFqeZyd4dZ1jvhTVqvbTLvyTJ",
+			expectedKey: "0c28fca386c7a227600b2fe50b7cae11ec86d3bf1fbe471be89827e19d72aa1d",
+		},
+		"compressed WIF": {
+			input:       "KwdMAjGmerYanjeui5SHS7JkmpZvVipYvB2LJGU1ZxJwYvP98617",
+			expectedKey: "0c28fca386c7a227600b2fe50b7cae11ec86d3bf1fbe471be89827e19d72aa1d",
+		},
+		"hex": {
+			input:       "0x0c28fca386c7a227600b2fe50b7cae11ec86d3bf1fbe471be89827e19d72aa1d",
+			expectedKey: "0c28fca386c7a227600b2fe50b7cae11ec86d3bf1fbe471be89827e19d72aa1d",
+		},
+		"invalid length": {
+			input: "0c28fca386c7a227600b2fe50b7cae11",
+			expectedErr: fmt.Errorf(
+				"key is neither a WIF nor a hex-encoded private key: " +
+					"[invalid bytes length: [16], expected: [32]]",
+			),
+		},
+	}
+
+	for testName, test := range tests {
+		t.Run(testName, func(t *testing.T) {
+			privateKey, err := newRefundPrivateKey(test.input)
+			if !reflect.DeepEqual(test.expectedErr, err) {
+				t.Fatalf(
+					"unexpected error\nexpected: %v\nactual:   %v",
+					test.expectedErr,
+					err,
+				)
+			}
+
+			if test.expectedErr == nil {
+				testutils.AssertStringsEqual(
+					t,
+					"private key",
+					test.expectedKey,
+					fmt.Sprintf("%064x", privateKey.D),
+				)
+			}
+		})
+	}
+}
+
+func TestNewOutputScript(t *testing.T) {
+	var tests = map[string]struct {
+		input          string
+		expectedScript string
+		expectedErr    error
+	}{
+		"address": {
+			input:          "bc1qw508d6qejxtdg4y5r3zarvary0c5xw7kv8f3t4",
+			expectedScript: "0014751e76e8199196d454941c45d1b3a323f1433bd6",
+		},
+		"hex script": {
+			input:          "0x0014751e76e8199196d454941c45d1b3a323f1433bd6",
+			expectedScript: "0014751e76e8199196d454941c45d1b3a323f1433bd6",
+		},
+		"address of another network": {
+			input: "tb1qw508d6qejxtdg4y5r3zarvary0c5xw7kxpjzsx",
+			expectedErr: fmt.Errorf(
+				"value is neither an address nor a hex-encoded script: " +
+					"[failed to decode string [tb1qw508d6qejxtdg4y5r3zarvary0c5xw7kxpjzsx]]",
+			),
+		},
+	}
+
+	for testName, test := range tests {
+		t.Run(testName, func(t *testing.T) {
+			script, err := newOutputScript(test.input, bitcoin.Mainnet)
+			if !reflect.DeepEqual(test.expectedErr, err) {
+				t.Fatalf(
+					"unexpected error\nexpected: %v\nactual:   %v",
+					test.expectedErr,
+					err,
+				)
+			}
+
+			if test.expectedErr == nil {
+				testutils.AssertStringsEqual(
+					t,
+					"output script",
+					test.expectedScript,
+					hex.EncodeToString(script),
+				)
+			}
+		})
+	}
+}
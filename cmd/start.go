@@ -7,7 +7,6 @@ import (
 
 	"github.com/keep-network/keep-common/pkg/persistence"
 	"github.com/keep-network/keep-core/build"
-	"github.com/keep-network/keep-core/pkg/bitcoin/electrum"
 	"github.com/keep-network/keep-core/pkg/operator"
 	"github.com/keep-network/keep-core/pkg/storage"
 
@@ -15,6 +14,7 @@ import (
 
 	"github.com/keep-network/keep-core/config"
 	"github.com/keep-network/keep-core/pkg/beacon"
+	"github.com/keep-network/keep-core/pkg/bitcoin/quorum"
 	"github.com/keep-network/keep-core/pkg/chain"
 	"github.com/keep-network/keep-core/pkg/chain/ethereum"
 	"github.com/keep-network/keep-core/pkg/clientinfo"
@@ -91,9 +91,9 @@ func start(cmd *cobra.Command) error {
 	// Skip initialization for bootstrap nodes as they are only used for network
 	// discovery.
 	if !isBootstrap() {
-		btcChain, err := electrum.Connect(ctx, clientConfig.Bitcoin.Electrum)
+		btcChain, err := connectBitcoin(ctx, clientConfig.Bitcoin)
 		if err != nil {
-			return fmt.Errorf("could not connect to Electrum chain: [%v]", err)
+			return fmt.Errorf("could not connect to Bitcoin chain: [%v]", err)
 		}
 
 		beaconKeyStorePersistence,
@@ -113,6 +113,11 @@ func start(cmd *cobra.Command) error {
 
 		clientInfoRegistry.RegisterBtcChainInfoSource(btcChain)
 
+		if quorumChain, ok := btcChain.(*quorum.Chain); ok &&
+			clientInfoRegistry != nil {
+			observeBitcoinQuorum(clientInfoRegistry, quorumChain)
+		}
+
 		err = beacon.Initialize(
 			ctx,
 			beaconChain,
@@ -124,9 +129,19 @@ func start(cmd *cobra.Command) error {
 			return fmt.Errorf("error initializing beacon: [%v]", err)
 		}
 
+		feeRateEstimator, err := newBitcoinFeeRateEstimator(
+			btcChain,
+			clientConfig.Bitcoin,
+		)
+		if err != nil {
+			return fmt.Errorf("could not create fee rate estimator: [%v]", err)
+		}
+
 		proposalGenerator := tbtcpg.NewProposalGenerator(
 			tbtcChain,
 			btcChain,
+			feeRateEstimator,
+			clientConfig.Tbtc,
 		)
 
 		err = tbtc.Initialize(
@@ -157,6 +172,27 @@ func start(cmd *cobra.Command) error {
 	return fmt.Errorf("shutting down the node because its context has ended")
 }
 
+// observeBitcoinQuorum registers metrics tracking the number of failures and
+// disagreements with the quorum of each Bitcoin chain backend.
+func observeBitcoinQuorum(
+	registry *clientinfo.Registry,
+	quorumChain *quorum.Chain,
+) {
+	sources := make(map[string]clientinfo.Source)
+	for _, backend := range quorumChain.BackendNames() {
+		backend := backend
+
+		sources[backend+"_disagreements"] = func() float64 {
+			return float64(quorumChain.Disagreements(backend))
+		}
+		sources[backend+"_failures"] = func() float64 {
+			return float64(quorumChain.Failures(backend))
+		}
+	}
+
+	registry.ObserveApplicationSource("btc_quorum", sources)
+}
+
 func isBootstrap() bool {
 	return clientConfig.LibP2P.Bootstrap
 }
@@ -19,7 +19,11 @@ import (
 	"golang.org/x/term"
 
 	commonEthereum "github.com/keep-network/keep-common/pkg/chain/ethereum"
+	"github.com/keep-network/keep-core/pkg/bitcoin/bitcoind"
 	"github.com/keep-network/keep-core/pkg/bitcoin/electrum"
+	"github.com/keep-network/keep-core/pkg/bitcoin/esplora"
+	"github.com/keep-network/keep-core/pkg/bitcoin/feeestimator"
+	"github.com/keep-network/keep-core/pkg/bitcoin/quorum"
 	"github.com/keep-network/keep-core/pkg/clientinfo"
 	"github.com/keep-network/keep-core/pkg/maintainer"
 	"github.com/keep-network/keep-core/pkg/net/libp2p"
@@ -43,6 +47,23 @@ const (
 	PubsubLogLevelEnvVariable = "PUBSUB_LOG_LEVEL"
 )
 
+const (
+	// ElectrumBackend is the name of the Electrum Bitcoin chain backend.
+	ElectrumBackend = "electrum"
+
+	// BitcoindBackend is the name of the bitcoind JSON-RPC Bitcoin chain
+	// backend.
+	BitcoindBackend = "bitcoind"
+
+	// EsploraBackend is the name of the Esplora REST Bitcoin chain backend.
+	EsploraBackend = "esplora"
+
+	// QuorumBackend is the name of the Bitcoin chain backend querying
+	// multiple other backends and accepting only results agreed by
+	// a quorum of them.
+	QuorumBackend = "quorum"
+)
+
 // Config is the top level config structure.
 type Config struct {
 	Ethereum   commonEthereum.Config
@@ -57,8 +78,22 @@ type Config struct {
 // BitcoinConfig defines the configuration for Bitcoin.
 type BitcoinConfig struct {
 	bitcoin.Network
+	// Backend determines the Bitcoin chain backend used by the client.
+	// Supported values are `electrum`, `bitcoind`, `esplora` and `quorum`.
+	// If empty, the Electrum backend is used.
+	Backend string
 	// Electrum defines the configuration for the Electrum client.
 	Electrum electrum.Config
+	// Bitcoind defines the configuration for the bitcoind JSON-RPC client.
+	Bitcoind bitcoind.Config
+	// Esplora defines the configuration for the Esplora REST client.
+	Esplora esplora.Config
+	// Quorum defines the configuration for the quorum-verified chain
+	// combining multiple backends.
+	Quorum quorum.Config
+	// FeeEstimation defines the configuration for the estimation of fees
+	// paid by wallet transactions.
+	FeeEstimation feeestimator.Config
 }
 
 // Bind the flags to the viper configuration. Viper reads configuration from
@@ -158,8 +193,11 @@ func (c *Config) ReadConfig(configFilePath string, flagSet *pflag.FlagSet, categ
 		return fmt.Errorf("failed to resolve Electrum: %w", err)
 	}
 
+	// Resolve quorum backends.
+	c.resolveQuorumBackends()
+
 	// Validate configuration.
-	if err := validateConfig(c, categories...); err != nil {
+	if err := validateConfig(c, clientNetwork, categories...); err != nil {
 		return fmt.Errorf("validation failed: %w", err)
 	}
 
@@ -192,7 +230,11 @@ func (c *Config) ReadConfig(configFilePath string, flagSet *pflag.FlagSet, categ
 	return nil
 }
 
-func validateConfig(config *Config, categories ...Category) error {
+func validateConfig(
+	config *Config,
+	clientNetwork network.Type,
+	categories ...Category,
+) error {
 	var result *multierror.Error
 
 	for _, category := range categories {
@@ -210,10 +252,18 @@ func validateConfig(config *Config, categories ...Category) error {
 				))
 			}
 		case BitcoinElectrum:
-			if config.Bitcoin.Electrum.URL == "" {
-				result = multierror.Append(result, fmt.Errorf(
-					"missing value for bitcoin.electrum.url; see bitcoin electrum section in configuration",
-				))
+			switch config.Bitcoin.Backend {
+			case QuorumBackend:
+				if err := validateQuorum(&config.Bitcoin.Quorum); err != nil {
+					result = multierror.Append(result, err)
+				}
+			default:
+				if err := validateBitcoinBackend(
+					&config.Bitcoin,
+					config.Bitcoin.Backend,
+				); err != nil {
+					result = multierror.Append(result, err)
+				}
 			}
 		case Network:
 			if config.LibP2P.Port == 0 {
@@ -227,12 +277,80 @@ func validateConfig(config *Config, categories ...Category) error {
 					"missing value for storage.dir; see storage section in configuration",
 				))
 			}
+		case Tbtc:
+			if clientNetwork == network.Mainnet {
+				if err := validateMainnetTbtc(&config.Tbtc); err != nil {
+					result = multierror.Append(result, err)
+				}
+			}
 		}
 	}
 
 	return result.ErrorOrNil()
 }
 
+// validateMainnetTbtc checks whether the tBTC config does not deviate from
+// the protocol timings used on mainnet. Custom protocol profiles are meant
+// only for development networks.
+func validateMainnetTbtc(config *tbtc.Config) error {
+	if config.ProtocolProfile != "" &&
+		config.ProtocolProfile != tbtc.MainnetProtocolProfile {
+		return fmt.Errorf(
+			"protocol profile [%v] is not allowed on mainnet; "+
+				"see tbtc section in configuration",
+			config.ProtocolProfile,
+		)
+	}
+
+	return nil
+}
+
+// validateBitcoinBackend checks whether the given Bitcoin chain backend is
+// supported and properly configured.
+func validateBitcoinBackend(config *BitcoinConfig, backend string) error {
+	switch backend {
+	case "", ElectrumBackend:
+		if config.Electrum.URL == "" {
+			return fmt.Errorf(
+				"missing value for bitcoin.electrum.url; see bitcoin electrum section in configuration",
+			)
+		}
+	case BitcoindBackend:
+		if config.Bitcoind.URL == "" {
+			return fmt.Errorf(
+				"missing value for bitcoin.bitcoind.url; see bitcoin bitcoind section in configuration",
+			)
+		}
+	case EsploraBackend:
+		if config.Esplora.URL == "" {
+			return fmt.Errorf(
+				"missing value for bitcoin.esplora.url; see bitcoin esplora section in configuration",
+			)
+		}
+	default:
+		return fmt.Errorf(
+			"unsupported Bitcoin chain backend: [%s]; expected one of: [%s, %s, %s]",
+			backend,
+			ElectrumBackend,
+			BitcoindBackend,
+			EsploraBackend,
+		)
+	}
+
+	return nil
+}
+
+// usesBackend checks whether the given Bitcoin chain backend is used directly.
+// Backends of the quorum have their own configuration so the quorum does not
+// use any of the single backends.
+func (bc *BitcoinConfig) usesBackend(backend string) bool {
+	if bc.Backend == "" {
+		return backend == ElectrumBackend
+	}
+
+	return bc.Backend == backend
+}
+
 // readConfigFile uses viper to read configuration from a config file. The config file
 // is not mandatory, if the path is
 func readConfigFile(configFilePath string) error {
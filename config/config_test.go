@@ -13,11 +13,16 @@ import (
 	"github.com/ethereum/go-ethereum/common"
 	"golang.org/x/exp/slices"
 
+	"github.com/keep-network/keep-core/config/network"
+	"github.com/keep-network/keep-core/pkg/bitcoin/electrum"
+	"github.com/keep-network/keep-core/pkg/bitcoin/esplora"
+	"github.com/keep-network/keep-core/pkg/bitcoin/quorum"
 	"github.com/keep-network/keep-core/pkg/chain/ethereum"
 	ethereumBeacon "github.com/keep-network/keep-core/pkg/chain/ethereum/beacon/gen"
 	ethereumEcdsa "github.com/keep-network/keep-core/pkg/chain/ethereum/ecdsa/gen"
 	ethereumTbtc "github.com/keep-network/keep-core/pkg/chain/ethereum/tbtc/gen"
 	ethereumThreshold "github.com/keep-network/keep-core/pkg/chain/ethereum/threshold/gen"
+	"github.com/keep-network/keep-core/pkg/tbtc"
 )
 
 func TestReadConfigFromFile(t *testing.T) {
@@ -74,6 +79,7 @@ func TestReadConfigFromFile(t *testing.T) {
 				"lightrelay":                "0x68e20afD773fDF1231B5cbFeA7040e73e79cAc36",
 				"lightrelaymaintainerproxy": "0x30cd93828613D5945A2916a22E0f0e9bC561EAB5",
 				"walletproposalvalidator":   "0xfdc315b0e608b7cDE9166D9D69a1506779e3E0CA",
+				"ecdsadkgvalidator":         "0x0125c8977a02b2Fa3970b1ED9AF02f5Bedd4eF27",
 			},
 		},
 		"Developer - RandomBeacon": {
@@ -136,6 +142,13 @@ func TestReadConfigFromFile(t *testing.T) {
 			},
 			expectedValue: "0xfdc315b0e608b7cDE9166D9D69a1506779e3E0CA",
 		},
+		"Ethereum.Developer - EcdsaDkgValidator": {
+			readValueFunc: func(c *Config) interface{} {
+				address, _ := c.Ethereum.ContractAddress(ethereum.EcdsaDkgValidatorContractName)
+				return address.String()
+			},
+			expectedValue: "0x0125c8977a02b2Fa3970b1ED9AF02f5Bedd4eF27",
+		},
 		"Bitcoin.Electrum.URL": {
 			readValueFunc: func(c *Config) interface{} { return c.Bitcoin.Electrum.URL },
 			expectedValue: "ssl://url.to.electrum:18332",
@@ -161,6 +174,104 @@ func TestReadConfigFromFile(t *testing.T) {
 			readValueFunc: func(c *Config) interface{} { return c.Bitcoin.Electrum.KeepAliveInterval },
 			expectedValue: 720 * time.Second,
 		},
+		"Bitcoin.Bitcoind.URL": {
+			readValueFunc: func(c *Config) interface{} { return c.Bitcoin.Bitcoind.URL },
+			expectedValue: "http://url.to.bitcoind:18332",
+		},
+		"Bitcoin.Bitcoind.Username": {
+			readValueFunc: func(c *Config) interface{} { return c.Bitcoin.Bitcoind.Username },
+			expectedValue: "bitcoinrpc",
+		},
+		"Bitcoin.Bitcoind.Password": {
+			readValueFunc: func(c *Config) interface{} { return c.Bitcoin.Bitcoind.Password },
+			expectedValue: "THIS IS TEST! Password should be kept secret",
+		},
+		"Bitcoin.Bitcoind.Wallet": {
+			readValueFunc: func(c *Config) interface{} { return c.Bitcoin.Bitcoind.Wallet },
+			expectedValue: "keep-watch-only",
+		},
+		"Bitcoin.Bitcoind.RescanTimestamp": {
+			readValueFunc: func(c *Config) interface{} { return c.Bitcoin.Bitcoind.RescanTimestamp },
+			expectedValue: int64(1672531200),
+		},
+		"Bitcoin.Bitcoind.RequestTimeout": {
+			readValueFunc: func(c *Config) interface{} { return c.Bitcoin.Bitcoind.RequestTimeout },
+			expectedValue: 47 * time.Second,
+		},
+		"Bitcoin.Bitcoind.RequestRetryTimeout": {
+			readValueFunc: func(c *Config) interface{} { return c.Bitcoin.Bitcoind.RequestRetryTimeout },
+			expectedValue: 270 * time.Second,
+		},
+		"Bitcoin.Bitcoind.ScanTimeout": {
+			readValueFunc: func(c *Config) interface{} { return c.Bitcoin.Bitcoind.ScanTimeout },
+			expectedValue: 3600 * time.Second,
+		},
+		"Bitcoin.Esplora.URL": {
+			readValueFunc: func(c *Config) interface{} { return c.Bitcoin.Esplora.URL },
+			expectedValue: "https://url.to.esplora/api",
+		},
+		"Bitcoin.Esplora.RequestTimeout": {
+			readValueFunc: func(c *Config) interface{} { return c.Bitcoin.Esplora.RequestTimeout },
+			expectedValue: 53 * time.Second,
+		},
+		"Bitcoin.Esplora.RequestRetryTimeout": {
+			readValueFunc: func(c *Config) interface{} { return c.Bitcoin.Esplora.RequestRetryTimeout },
+			expectedValue: 210 * time.Second,
+		},
+		"Bitcoin.Quorum.Backends": {
+			readValueFunc: func(c *Config) interface{} { return c.Bitcoin.Quorum.Backends },
+			expectedValue: []quorum.BackendConfig{
+				{
+					Name: "electrum-1",
+					Type: "electrum",
+					Electrum: electrum.Config{
+						URL:                 "ssl://url.to.electrum-1:50002",
+						ConnectTimeout:      54 * time.Second,
+						ConnectRetryTimeout: 192 * time.Second,
+						RequestTimeout:      41 * time.Second,
+						RequestRetryTimeout: 5 * time.Minute,
+						KeepAliveInterval:   12 * time.Minute,
+					},
+				},
+				{
+					Name: "electrum-2",
+					Type: "electrum",
+					Electrum: electrum.Config{
+						URL:                 "ssl://url.to.electrum-2:50002",
+						ConnectTimeout:      54 * time.Second,
+						ConnectRetryTimeout: 192 * time.Second,
+						RequestTimeout:      94 * time.Second,
+						RequestRetryTimeout: 5 * time.Minute,
+						KeepAliveInterval:   12 * time.Minute,
+					},
+				},
+				{
+					Name: "esplora",
+					Type: "esplora",
+					Esplora: esplora.Config{
+						URL:                 "https://url.to.esplora/api",
+						RequestTimeout:      53 * time.Second,
+						RequestRetryTimeout: 210 * time.Second,
+					},
+				},
+			},
+		},
+		"Bitcoin.Quorum.Threshold": {
+			readValueFunc: func(c *Config) interface{} { return c.Bitcoin.Quorum.Threshold },
+			expectedValue: 2,
+		},
+		"Bitcoin.FeeEstimation.Mode": {
+			readValueFunc: func(c *Config) interface{} { return c.Bitcoin.FeeEstimation.Mode },
+			expectedValue: "percentile",
+		},
+		"Bitcoin.FeeEstimation.Percentile": {
+			readValueFunc: func(c *Config) interface{} { return c.Bitcoin.FeeEstimation.Percentile },
+			expectedValue: 75,
+		},
+		"Bitcoin.FeeEstimation.RecentBlocks": {
+			readValueFunc: func(c *Config) interface{} { return c.Bitcoin.FeeEstimation.RecentBlocks },
+			expectedValue: 12,
+		},
 		"Network.Port": {
 			readValueFunc: func(c *Config) interface{} { return c.LibP2P.Port },
 			expectedValue: 27001,
@@ -404,3 +515,58 @@ func TestReadConfig_ReadContracts(t *testing.T) {
 		})
 	}
 }
+
+func TestValidateConfig_Tbtc(t *testing.T) {
+	var tests = map[string]struct {
+		network       network.Type
+		tbtcConfig    tbtc.Config
+		expectedError string
+	}{
+		"mainnet with default profile": {
+			network:    network.Mainnet,
+			tbtcConfig: tbtc.Config{},
+		},
+		"mainnet with mainnet profile": {
+			network:    network.Mainnet,
+			tbtcConfig: tbtc.Config{ProtocolProfile: tbtc.MainnetProtocolProfile},
+		},
+		"mainnet with developer profile": {
+			network: network.Mainnet,
+			tbtcConfig: tbtc.Config{
+				ProtocolProfile: tbtc.DeveloperProtocolProfile,
+			},
+			expectedError: "protocol profile [developer] is not allowed on mainnet",
+		},
+		"developer network with developer profile": {
+			network: network.Developer,
+			tbtcConfig: tbtc.Config{
+				ProtocolProfile: tbtc.DeveloperProtocolProfile,
+			},
+		},
+	}
+
+	for testName, test := range tests {
+		t.Run(testName, func(t *testing.T) {
+			err := validateConfig(
+				&Config{Tbtc: test.tbtcConfig},
+				test.network,
+				Tbtc,
+			)
+
+			if len(test.expectedError) == 0 {
+				if err != nil {
+					t.Fatalf("unexpected error: [%v]", err)
+				}
+				return
+			}
+
+			if err == nil || !strings.Contains(err.Error(), test.expectedError) {
+				t.Errorf(
+					"unexpected error\nexpected to contain: %s\nactual:              %v",
+					test.expectedError,
+					err,
+				)
+			}
+		})
+	}
+}
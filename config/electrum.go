@@ -39,6 +39,11 @@ func (c *Config) resolveElectrum(rng *rand.Rand) error {
 		return nil
 	}
 
+	// Return if Electrum is not used as the Bitcoin chain backend.
+	if !c.Bitcoin.usesBackend(ElectrumBackend) {
+		return nil
+	}
+
 	// For unknown and regtest networks we don't expect the Electrum configs to be
 	// embedded in the client. The user should configure it in the config file.
 	if network == bitcoin.Regtest || network == bitcoin.Unknown {
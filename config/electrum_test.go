@@ -9,6 +9,7 @@ import (
 
 	"github.com/keep-network/keep-core/pkg/bitcoin"
 	"github.com/keep-network/keep-core/pkg/bitcoin/electrum"
+	"github.com/keep-network/keep-core/pkg/bitcoin/quorum"
 )
 
 func TestResolveElectrum(t *testing.T) {
@@ -83,6 +84,63 @@ func TestResolveElectrum(t *testing.T) {
 	}
 }
 
+func TestResolveElectrum_Backend(t *testing.T) {
+	var tests = map[string]struct {
+		bitcoinConfig BitcoinConfig
+		expectedURL   string
+	}{
+		"default backend": {
+			bitcoinConfig: BitcoinConfig{},
+			expectedURL:   "wss://electrumx-server.test.tbtc.network:8443",
+		},
+		"bitcoind backend": {
+			bitcoinConfig: BitcoinConfig{
+				Backend: BitcoindBackend,
+			},
+			expectedURL: "",
+		},
+		"quorum backend": {
+			// Quorum backends have their own connection configs so the
+			// Electrum section is not used.
+			bitcoinConfig: BitcoinConfig{
+				Backend: QuorumBackend,
+				Quorum: quorum.Config{
+					Backends: []quorum.BackendConfig{
+						{
+							Name: "electrum-1",
+							Type: ElectrumBackend,
+							Electrum: electrum.Config{
+								URL: "ssl://electrum-1.example.com:50002",
+							},
+						},
+					},
+				},
+			},
+			expectedURL: "",
+		},
+	}
+
+	for testName, test := range tests {
+		t.Run(testName, func(t *testing.T) {
+			cfg := &Config{Bitcoin: test.bitcoinConfig}
+			cfg.Bitcoin.Network = bitcoin.Testnet
+
+			err := cfg.resolveElectrum(rand.New(&fakeRandSource{0}))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if cfg.Bitcoin.Electrum.URL != test.expectedURL {
+				t.Errorf(
+					"unexpected Electrum URL\nexpected: %s\nactual:   %s\n",
+					test.expectedURL,
+					cfg.Bitcoin.Electrum.URL,
+				)
+			}
+		})
+	}
+}
+
 type fakeRandSource struct {
 	expectedValue int64
 }
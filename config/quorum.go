@@ -0,0 +1,125 @@
+package config
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/hashicorp/go-multierror"
+
+	"github.com/keep-network/keep-core/pkg/bitcoin/quorum"
+)
+
+// resolveQuorumBackends sets timeouts of the quorum backends that are not
+// configured explicitly. Such timeouts take values from the section of the
+// given backend type, e.g. Electrum backends of the quorum use timeouts from
+// the Electrum section that are set to defaults unless configured otherwise.
+func (c *Config) resolveQuorumBackends() {
+	for i := range c.Bitcoin.Quorum.Backends {
+		backend := &c.Bitcoin.Quorum.Backends[i]
+
+		switch backend.Type {
+		case ElectrumBackend:
+			defaults := c.Bitcoin.Electrum
+			resolveDuration(&backend.Electrum.ConnectTimeout, defaults.ConnectTimeout)
+			resolveDuration(&backend.Electrum.ConnectRetryTimeout, defaults.ConnectRetryTimeout)
+			resolveDuration(&backend.Electrum.RequestTimeout, defaults.RequestTimeout)
+			resolveDuration(&backend.Electrum.RequestRetryTimeout, defaults.RequestRetryTimeout)
+			resolveDuration(&backend.Electrum.KeepAliveInterval, defaults.KeepAliveInterval)
+		case BitcoindBackend:
+			defaults := c.Bitcoin.Bitcoind
+			resolveDuration(&backend.Bitcoind.RequestTimeout, defaults.RequestTimeout)
+			resolveDuration(&backend.Bitcoind.RequestRetryTimeout, defaults.RequestRetryTimeout)
+			resolveDuration(&backend.Bitcoind.ScanTimeout, defaults.ScanTimeout)
+		case EsploraBackend:
+			defaults := c.Bitcoin.Esplora
+			resolveDuration(&backend.Esplora.RequestTimeout, defaults.RequestTimeout)
+			resolveDuration(&backend.Esplora.RequestRetryTimeout, defaults.RequestRetryTimeout)
+		}
+	}
+}
+
+// resolveDuration sets the given duration to the default value if it is
+// not set.
+func resolveDuration(duration *time.Duration, defaultValue time.Duration) {
+	if *duration == 0 {
+		*duration = defaultValue
+	}
+}
+
+// validateQuorum checks whether the quorum backends are supported and properly
+// configured and whether the quorum threshold can be reached.
+func validateQuorum(config *quorum.Config) error {
+	var result *multierror.Error
+
+	if len(config.Backends) == 0 {
+		result = multierror.Append(result, fmt.Errorf(
+			"missing value for bitcoin.quorum.backends; see bitcoin quorum section in configuration",
+		))
+	}
+
+	names := make(map[string]bool)
+	for i, backend := range config.Backends {
+		if backend.Name == "" {
+			result = multierror.Append(result, fmt.Errorf(
+				"missing name of bitcoin.quorum.backends entry [%v]; "+
+					"see bitcoin quorum section in configuration",
+				i,
+			))
+		} else if names[backend.Name] {
+			result = multierror.Append(result, fmt.Errorf(
+				"duplicated name in bitcoin.quorum.backends: [%s]",
+				backend.Name,
+			))
+		}
+		names[backend.Name] = true
+
+		if err := validateQuorumBackend(&backend); err != nil {
+			result = multierror.Append(result, err)
+		}
+	}
+
+	if config.Threshold < 0 || config.Threshold > len(config.Backends) {
+		result = multierror.Append(result, fmt.Errorf(
+			"invalid value for bitcoin.quorum.threshold: [%v]; "+
+				"must not exceed the number of quorum backends",
+			config.Threshold,
+		))
+	}
+
+	return result.ErrorOrNil()
+}
+
+// validateQuorumBackend checks whether the given quorum backend is supported
+// and properly configured.
+func validateQuorumBackend(backend *quorum.BackendConfig) error {
+	var url string
+	switch backend.Type {
+	case ElectrumBackend:
+		url = backend.Electrum.URL
+	case BitcoindBackend:
+		url = backend.Bitcoind.URL
+	case EsploraBackend:
+		url = backend.Esplora.URL
+	default:
+		return fmt.Errorf(
+			"unsupported type of bitcoin quorum backend [%s]: [%s]; "+
+				"expected one of: [%s, %s, %s]",
+			backend.Name,
+			backend.Type,
+			ElectrumBackend,
+			BitcoindBackend,
+			EsploraBackend,
+		)
+	}
+
+	if url == "" {
+		return fmt.Errorf(
+			"missing %s URL of bitcoin quorum backend [%s]; "+
+				"see bitcoin quorum section in configuration",
+			backend.Type,
+			backend.Name,
+		)
+	}
+
+	return nil
+}
@@ -0,0 +1,142 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/hashicorp/go-multierror"
+
+	"github.com/keep-network/keep-core/pkg/bitcoin/bitcoind"
+	"github.com/keep-network/keep-core/pkg/bitcoin/electrum"
+	"github.com/keep-network/keep-core/pkg/bitcoin/esplora"
+	"github.com/keep-network/keep-core/pkg/bitcoin/quorum"
+)
+
+func TestValidateQuorum(t *testing.T) {
+	electrumBackend := func(name string) quorum.BackendConfig {
+		return quorum.BackendConfig{
+			Name: name,
+			Type: ElectrumBackend,
+			Electrum: electrum.Config{
+				URL: fmt.Sprintf("ssl://%s.example.com:50002", name),
+			},
+		}
+	}
+
+	var tests = map[string]struct {
+		config        quorum.Config
+		expectedError error
+	}{
+		"backends of different types": {
+			config: quorum.Config{
+				Backends: []quorum.BackendConfig{
+					electrumBackend("electrum"),
+					{
+						Name:     "bitcoind",
+						Type:     BitcoindBackend,
+						Bitcoind: bitcoind.Config{URL: "http://bitcoind:8332"},
+					},
+					{
+						Name:    "esplora",
+						Type:    EsploraBackend,
+						Esplora: esplora.Config{URL: "https://esplora/api"},
+					},
+				},
+				Threshold: 3,
+			},
+		},
+		"backends of the same type": {
+			config: quorum.Config{
+				Backends: []quorum.BackendConfig{
+					electrumBackend("electrum-1"),
+					electrumBackend("electrum-2"),
+					electrumBackend("electrum-3"),
+				},
+			},
+		},
+		"no backends": {
+			config: quorum.Config{},
+			expectedError: multierror.Append(nil, fmt.Errorf(
+				"missing value for bitcoin.quorum.backends; see bitcoin quorum section in configuration",
+			)),
+		},
+		"missing name": {
+			config: quorum.Config{
+				Backends: []quorum.BackendConfig{
+					electrumBackend("electrum-1"),
+					electrumBackend(""),
+				},
+			},
+			expectedError: multierror.Append(nil, fmt.Errorf(
+				"missing name of bitcoin.quorum.backends entry [1]; "+
+					"see bitcoin quorum section in configuration",
+			)),
+		},
+		"duplicated name": {
+			config: quorum.Config{
+				Backends: []quorum.BackendConfig{
+					electrumBackend("electrum"),
+					electrumBackend("electrum"),
+				},
+			},
+			expectedError: multierror.Append(nil, fmt.Errorf(
+				"duplicated name in bitcoin.quorum.backends: [electrum]",
+			)),
+		},
+		"unsupported type": {
+			config: quorum.Config{
+				Backends: []quorum.BackendConfig{
+					{Name: "btcd", Type: "btcd"},
+				},
+			},
+			expectedError: multierror.Append(nil, fmt.Errorf(
+				"unsupported type of bitcoin quorum backend [btcd]: [btcd]; "+
+					"expected one of: [electrum, bitcoind, esplora]",
+			)),
+		},
+		"missing URL": {
+			config: quorum.Config{
+				Backends: []quorum.BackendConfig{
+					{
+						Name: "esplora",
+						Type: EsploraBackend,
+						// URL of other type must not be taken into account.
+						Electrum: electrum.Config{URL: "ssl://electrum:50002"},
+					},
+				},
+			},
+			expectedError: multierror.Append(nil, fmt.Errorf(
+				"missing esplora URL of bitcoin quorum backend [esplora]; "+
+					"see bitcoin quorum section in configuration",
+			)),
+		},
+		"threshold exceeding the number of backends": {
+			config: quorum.Config{
+				Backends: []quorum.BackendConfig{
+					electrumBackend("electrum-1"),
+					electrumBackend("electrum-2"),
+				},
+				Threshold: 3,
+			},
+			expectedError: multierror.Append(nil, fmt.Errorf(
+				"invalid value for bitcoin.quorum.threshold: [3]; "+
+					"must not exceed the number of quorum backends",
+			)),
+		},
+	}
+
+	for testName, test := range tests {
+		t.Run(testName, func(t *testing.T) {
+			err := validateQuorum(&test.config)
+
+			if !reflect.DeepEqual(test.expectedError, err) {
+				t.Errorf(
+					"unexpected error\nexpected: [%v]\nactual:   [%v]",
+					test.expectedError,
+					err,
+				)
+			}
+		})
+	}
+}
@@ -0,0 +1,295 @@
+package bitcoin
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/btcsuite/btcd/btcutil/bech32"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcutil/base58"
+)
+
+// addressParams holds the address encoding parameters specific to the
+// given Bitcoin network.
+type addressParams struct {
+	// publicKeyHashVersion is the Base58Check version byte of P2PKH addresses.
+	publicKeyHashVersion byte
+	// scriptHashVersion is the Base58Check version byte of P2SH addresses.
+	scriptHashVersion byte
+	// humanReadablePart is the bech32 human-readable part of segwit addresses.
+	humanReadablePart string
+}
+
+// getAddressParams returns the address encoding parameters of the given
+// Bitcoin network.
+func getAddressParams(network Network) (*addressParams, error) {
+	switch network {
+	case Mainnet:
+		return &addressParams{
+			publicKeyHashVersion: 0x00,
+			scriptHashVersion:    0x05,
+			humanReadablePart:    "bc",
+		}, nil
+	case Testnet:
+		return &addressParams{
+			publicKeyHashVersion: 0x6f,
+			scriptHashVersion:    0xc4,
+			humanReadablePart:    "tb",
+		}, nil
+	case Regtest:
+		return &addressParams{
+			publicKeyHashVersion: 0x6f,
+			scriptHashVersion:    0xc4,
+			humanReadablePart:    "bcrt",
+		}, nil
+	default:
+		return nil, fmt.Errorf("unsupported network: [%v]", network)
+	}
+}
+
+const (
+	// minWitnessProgramLength is the minimum byte length of a witness program
+	// according to BIP-141.
+	minWitnessProgramLength = 2
+	// maxWitnessProgramLength is the maximum byte length of a witness program
+	// according to BIP-141.
+	maxWitnessProgramLength = 40
+)
+
+// Address returns the address corresponding to the script for the given
+// network. P2PKH and P2SH scripts are encoded using Base58Check. Witness
+// version 0 scripts (P2WPKH and P2WSH) are encoded using bech32 as defined
+// by BIP-173. Scripts of witness version 1 and above (e.g. P2TR) are encoded
+// using bech32m as defined by BIP-350. An error is returned if the script
+// has no standard address form.
+func (s Script) Address(network Network) (string, error) {
+	params, err := getAddressParams(network)
+	if err != nil {
+		return "", err
+	}
+
+	switch GetScriptType(s) {
+	case P2PKHScript:
+		// The public key hash is at the [3:23] range of the script:
+		// OP_DUP OP_HASH160 <20-byte hash> OP_EQUALVERIFY OP_CHECKSIG
+		return base58.CheckEncode(s[3:23], params.publicKeyHashVersion), nil
+	case P2SHScript:
+		// The script hash is at the [2:22] range of the script:
+		// OP_HASH160 <20-byte hash> OP_EQUAL
+		return base58.CheckEncode(s[2:22], params.scriptHashVersion), nil
+	}
+
+	witnessVersion, witnessProgram, ok := extractWitnessProgram(s)
+	if !ok {
+		return "", fmt.Errorf("script has no standard address form")
+	}
+
+	return encodeSegwitAddress(
+		params.humanReadablePart,
+		witnessVersion,
+		witnessProgram,
+	)
+}
+
+// NewScriptFromAddress constructs the output script paying to the given
+// address. The address must be a Base58Check P2PKH or P2SH address or a
+// bech32/bech32m segwit address valid for the given network.
+func NewScriptFromAddress(address string, network Network) (Script, error) {
+	params, err := getAddressParams(network)
+	if err != nil {
+		return nil, err
+	}
+
+	if isSegwitAddress(address) {
+		return decodeSegwitAddress(address, params.humanReadablePart)
+	}
+
+	payload, version, err := base58.CheckDecode(address)
+	if err != nil {
+		return nil, fmt.Errorf("cannot decode address: [%v]", err)
+	}
+
+	if len(payload) != 20 {
+		return nil, fmt.Errorf(
+			"wrong address payload length; expected 20 bytes, got [%v]",
+			len(payload),
+		)
+	}
+
+	var hash [20]byte
+	copy(hash[:], payload)
+
+	switch version {
+	case params.publicKeyHashVersion:
+		return PayToPublicKeyHash(hash)
+	case params.scriptHashVersion:
+		return PayToScriptHash(hash)
+	default:
+		return nil, fmt.Errorf(
+			"address version [0x%x] is not valid for network [%v]",
+			version,
+			network,
+		)
+	}
+}
+
+// isSegwitAddress checks whether the given address looks like a segwit
+// address of any supported network, i.e. whether it starts with a known
+// human-readable part followed by the bech32 separator.
+func isSegwitAddress(address string) bool {
+	separatorIndex := strings.LastIndexByte(address, '1')
+	if separatorIndex <= 0 {
+		return false
+	}
+
+	humanReadablePart := strings.ToLower(address[:separatorIndex])
+	for _, network := range []Network{Mainnet, Testnet, Regtest} {
+		params, err := getAddressParams(network)
+		if err != nil {
+			continue
+		}
+
+		if params.humanReadablePart == humanReadablePart {
+			return true
+		}
+	}
+
+	return false
+}
+
+// extractWitnessProgram extracts the witness version and witness program
+// from the given script. The last returned value is false if the script is
+// not a witness program script as defined by BIP-141.
+func extractWitnessProgram(script Script) (byte, []byte, bool) {
+	if len(script) < 2+minWitnessProgramLength ||
+		len(script) > 2+maxWitnessProgramLength {
+		return 0, nil, false
+	}
+
+	var witnessVersion byte
+	switch {
+	case script[0] == txscript.OP_0:
+		witnessVersion = 0
+	case script[0] >= txscript.OP_1 && script[0] <= txscript.OP_16:
+		witnessVersion = script[0] - txscript.OP_1 + 1
+	default:
+		return 0, nil, false
+	}
+
+	// The second byte is the push opcode of the witness program.
+	if int(script[1]) != len(script)-2 {
+		return 0, nil, false
+	}
+
+	witnessProgram := script[2:]
+
+	// Witness version 0 programs are either P2WPKH or P2WSH.
+	if witnessVersion == 0 &&
+		len(witnessProgram) != 20 && len(witnessProgram) != 32 {
+		return 0, nil, false
+	}
+
+	return witnessVersion, witnessProgram, true
+}
+
+// encodeSegwitAddress encodes the given witness program as a segwit address.
+func encodeSegwitAddress(
+	humanReadablePart string,
+	witnessVersion byte,
+	witnessProgram []byte,
+) (string, error) {
+	converted, err := bech32.ConvertBits(witnessProgram, 8, 5, true)
+	if err != nil {
+		return "", fmt.Errorf("cannot convert witness program: [%v]", err)
+	}
+
+	data := append([]byte{witnessVersion}, converted...)
+
+	if witnessVersion == 0 {
+		return bech32.Encode(humanReadablePart, data)
+	}
+
+	return bech32.EncodeM(humanReadablePart, data)
+}
+
+// decodeSegwitAddress decodes the given segwit address and constructs the
+// output script paying to it.
+func decodeSegwitAddress(address string, humanReadablePart string) (
+	Script,
+	error,
+) {
+	// BIP-173 disallows mixed-case addresses.
+	if address != strings.ToLower(address) &&
+		address != strings.ToUpper(address) {
+		return nil, fmt.Errorf("address must not be mixed-case")
+	}
+
+	decodedHumanReadablePart, data, bech32Version, err :=
+		bech32.DecodeGeneric(address)
+	if err != nil {
+		return nil, fmt.Errorf("cannot decode address: [%v]", err)
+	}
+
+	if decodedHumanReadablePart != humanReadablePart {
+		return nil, fmt.Errorf(
+			"address human-readable part [%v] is not valid for the network",
+			decodedHumanReadablePart,
+		)
+	}
+
+	if len(data) == 0 {
+		return nil, fmt.Errorf("address has no witness version")
+	}
+
+	witnessVersion := data[0]
+	if witnessVersion > 16 {
+		return nil, fmt.Errorf(
+			"invalid witness version: [%v]",
+			witnessVersion,
+		)
+	}
+
+	// Witness version 0 addresses must use bech32 and addresses of witness
+	// version 1 and above must use bech32m, as defined by BIP-350.
+	if witnessVersion == 0 && bech32Version != bech32.Version0 {
+		return nil, fmt.Errorf("witness version 0 address must use bech32")
+	}
+	if witnessVersion != 0 && bech32Version != bech32.VersionM {
+		return nil, fmt.Errorf(
+			"witness version [%v] address must use bech32m",
+			witnessVersion,
+		)
+	}
+
+	witnessProgram, err := bech32.ConvertBits(data[1:], 5, 8, false)
+	if err != nil {
+		return nil, fmt.Errorf("cannot convert witness program: [%v]", err)
+	}
+
+	if len(witnessProgram) < minWitnessProgramLength ||
+		len(witnessProgram) > maxWitnessProgramLength {
+		return nil, fmt.Errorf(
+			"invalid witness program length: [%v]",
+			len(witnessProgram),
+		)
+	}
+
+	if witnessVersion == 0 &&
+		len(witnessProgram) != 20 && len(witnessProgram) != 32 {
+		return nil, fmt.Errorf(
+			"invalid witness version 0 program length: [%v]",
+			len(witnessProgram),
+		)
+	}
+
+	opcode := byte(txscript.OP_0)
+	if witnessVersion != 0 {
+		opcode = txscript.OP_1 + witnessVersion - 1
+	}
+
+	script := make(Script, 0, 2+len(witnessProgram))
+	script = append(script, opcode, byte(len(witnessProgram)))
+	script = append(script, witnessProgram...)
+
+	return script, nil
+}
@@ -0,0 +1,222 @@
+package bitcoin
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/keep-network/keep-core/internal/testutils"
+)
+
+// Test vectors of segwit addresses come from BIP-173 and BIP-350.
+var addressTestVectors = map[string]struct {
+	address string
+	network Network
+	script  string
+}{
+	"mainnet p2pkh": {
+		address: "1BgGZ9tcN4rm9KBzDn7KprQz87SZ26SAMH",
+		network: Mainnet,
+		script:  "76a914751e76e8199196d454941c45d1b3a323f1433bd688ac",
+	},
+	"testnet p2pkh": {
+		address: "mrCDrCybB6J1vRfbwM5hemdJz73FwDBC8r",
+		network: Testnet,
+		script:  "76a914751e76e8199196d454941c45d1b3a323f1433bd688ac",
+	},
+	"mainnet p2sh": {
+		address: "3QJmV3qfvL9SuYo34YihAf3sRCW3qSinyC",
+		network: Mainnet,
+		script:  "a914f815b036d9bbbce5e9f2a00abd1bf3dc91e9551087",
+	},
+	"testnet p2sh": {
+		address: "2NFryYnmhXneo7LRajgLZnc38dYiDePvf3G",
+		network: Testnet,
+		script:  "a914f815b036d9bbbce5e9f2a00abd1bf3dc91e9551087",
+	},
+	"mainnet p2wpkh": {
+		address: "bc1qw508d6qejxtdg4y5r3zarvary0c5xw7kv8f3t4",
+		network: Mainnet,
+		script:  "0014751e76e8199196d454941c45d1b3a323f1433bd6",
+	},
+	"testnet p2wsh": {
+		address: "tb1qrp33g0q5c5txsp9arysrx4k6zdkfs4nce4xj0gdcccefvpysxf3q0sl5k7",
+		network: Testnet,
+		script:  "00201863143c14c5166804bd19203356da136c985678cd4d27a1b8c6329604903262",
+	},
+	"regtest p2wpkh": {
+		address: "bcrt1qw508d6qejxtdg4y5r3zarvary0c5xw7kygt080",
+		network: Regtest,
+		script:  "0014751e76e8199196d454941c45d1b3a323f1433bd6",
+	},
+	"mainnet p2tr": {
+		address: "bc1p0xlxvlhemja6c4dqv22uapctqupfhlxm9h8z3k2e72q4k9hcz7vqzk5jj0",
+		network: Mainnet,
+		script:  "512079be667ef9dcbbac55a06295ce870b07029bfcdb2dce28d959f2815b16f81798",
+	},
+	"mainnet witness version 1 with 40-byte program": {
+		address: "bc1pw508d6qejxtdg4y5r3zarvary0c5xw7kw508d6qejxtdg4y5r3zarvary0c5xw7kt5nd6y",
+		network: Mainnet,
+		script:  "5128751e76e8199196d454941c45d1b3a323f1433bd6751e76e8199196d454941c45d1b3a323f1433bd6",
+	},
+	"mainnet witness version 16": {
+		address: "bc1sw50qgdz25j",
+		network: Mainnet,
+		script:  "6002751e",
+	},
+	"mainnet witness version 2": {
+		address: "bc1zw508d6qejxtdg4y5r3zarvaryvaxxpcs",
+		network: Mainnet,
+		script:  "5210751e76e8199196d454941c45d1b3a323",
+	},
+}
+
+func TestScript_Address(t *testing.T) {
+	for testName, test := range addressTestVectors {
+		t.Run(testName, func(t *testing.T) {
+			script, err := hex.DecodeString(test.script)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			address, err := Script(script).Address(test.network)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			testutils.AssertStringsEqual(t, "address", test.address, address)
+		})
+	}
+}
+
+func TestScript_Address_Errors(t *testing.T) {
+	fromHex := func(hexString string) []byte {
+		bytes, err := hex.DecodeString(hexString)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return bytes
+	}
+
+	var tests = map[string]struct {
+		script  Script
+		network Network
+	}{
+		"unknown network": {
+			script:  fromHex("0014751e76e8199196d454941c45d1b3a323f1433bd6"),
+			network: Unknown,
+		},
+		"witness version 0 with wrong program length": {
+			script:  fromHex("0010751e76e8199196d454941c45d1b3a323"),
+			network: Mainnet,
+		},
+		"non-standard script": {
+			script:  fromHex("6a0401020304"),
+			network: Mainnet,
+		},
+	}
+
+	for testName, test := range tests {
+		t.Run(testName, func(t *testing.T) {
+			_, err := test.script.Address(test.network)
+			if err == nil {
+				t.Fatal("expected error")
+			}
+		})
+	}
+}
+
+func TestNewScriptFromAddress(t *testing.T) {
+	for testName, test := range addressTestVectors {
+		t.Run(testName, func(t *testing.T) {
+			script, err := NewScriptFromAddress(test.address, test.network)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			testutils.AssertStringsEqual(
+				t,
+				"script",
+				test.script,
+				hex.EncodeToString(script),
+			)
+		})
+	}
+}
+
+func TestNewScriptFromAddress_UpperCase(t *testing.T) {
+	script, err := NewScriptFromAddress(
+		"BC1QW508D6QEJXTDG4Y5R3ZARVARY0C5XW7KV8F3T4",
+		Mainnet,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testutils.AssertStringsEqual(
+		t,
+		"script",
+		"0014751e76e8199196d454941c45d1b3a323f1433bd6",
+		hex.EncodeToString(script),
+	)
+}
+
+func TestNewScriptFromAddress_Errors(t *testing.T) {
+	var tests = map[string]struct {
+		address string
+		network Network
+	}{
+		"unknown network": {
+			address: "bc1qw508d6qejxtdg4y5r3zarvary0c5xw7kv8f3t4",
+			network: Unknown,
+		},
+		"segwit address of another network": {
+			address: "tb1qrp33g0q5c5txsp9arysrx4k6zdkfs4nce4xj0gdcccefvpysxf3q0sl5k7",
+			network: Mainnet,
+		},
+		"base58 address of another network": {
+			address: "1BgGZ9tcN4rm9KBzDn7KprQz87SZ26SAMH",
+			network: Testnet,
+		},
+		"invalid human-readable part": {
+			address: "tc1p0xlxvlhemja6c4dqv22uapctqupfhlxm9h8z3k2e72q4k9hcz7vq5zuyut",
+			network: Mainnet,
+		},
+		"witness version 1 with bech32 checksum": {
+			address: "bc1p0xlxvlhemja6c4dqv22uapctqupfhlxm9h8z3k2e72q4k9hcz7vqh2y7hd",
+			network: Mainnet,
+		},
+		"witness version 0 with bech32m checksum": {
+			address: "bc1qw508d6qejxtdg4y5r3zarvary0c5xw7kemeawh",
+			network: Mainnet,
+		},
+		"invalid program length": {
+			address: "bc1rw5uspcuh",
+			network: Mainnet,
+		},
+		"invalid witness version 0 program length": {
+			address: "BC1QR508D6QEJXTDG4Y5R3ZARVARYV98GJ9P",
+			network: Mainnet,
+		},
+		"mixed case": {
+			address: "tb1qrp33g0q5c5txsp9arysrx4k6zdkfs4nce4xj0gdcccefvpysxf3q0sL5k7",
+			network: Testnet,
+		},
+		"invalid base58 checksum": {
+			address: "1BgGZ9tcN4rm9KBzDn7KprQz87SZ26SAMJ",
+			network: Mainnet,
+		},
+		"empty address": {
+			address: "",
+			network: Mainnet,
+		},
+	}
+
+	for testName, test := range tests {
+		t.Run(testName, func(t *testing.T) {
+			_, err := NewScriptFromAddress(test.address, test.network)
+			if err == nil {
+				t.Fatal("expected error")
+			}
+		})
+	}
+}
@@ -0,0 +1,1328 @@
+package bitcoind
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+
+	"github.com/ipfs/go-log"
+	"go.uber.org/zap"
+
+	"github.com/keep-network/keep-core/pkg/bitcoin"
+)
+
+// listTransactionsPageSize determines the number of wallet transactions
+// fetched with a single `listtransactions` call.
+const listTransactionsPageSize = 1000
+
+var logger = log.Logger("keep-bitcoind")
+
+// Connection is a handle for interactions with bitcoind JSON-RPC server.
+type Connection struct {
+	parentCtx  context.Context
+	httpClient *http.Client
+	config     Config
+
+	requestIDMutex sync.Mutex
+	requestID      uint64
+
+	watchedDescriptorsMutex sync.Mutex
+	// watchedDescriptors holds descriptors already imported to the wallet.
+	// It is nil until the wallet descriptors are fetched for the first time.
+	watchedDescriptors map[string]bool
+
+	walletTransactionsCacheMutex sync.Mutex
+	walletTransactionsCache      map[string]*bitcoin.Transaction
+}
+
+// Connect initializes handle with provided Config.
+func Connect(parentCtx context.Context, config Config) (bitcoin.Chain, error) {
+	if config.RequestTimeout == 0 {
+		config.RequestTimeout = DefaultRequestTimeout
+	}
+	if config.RequestRetryTimeout == 0 {
+		config.RequestRetryTimeout = DefaultRequestRetryTimeout
+	}
+	if config.ScanTimeout == 0 {
+		config.ScanTimeout = DefaultScanTimeout
+	}
+
+	c := &Connection{
+		parentCtx:  parentCtx,
+		httpClient: &http.Client{},
+		config:     config,
+	}
+
+	if err := c.verifyServer(); err != nil {
+		return nil, fmt.Errorf("failed to verify bitcoind server: [%w]", err)
+	}
+
+	return c, nil
+}
+
+// GetTransaction gets the transaction with the given transaction hash.
+// If the transaction with the given hash was not found on the chain,
+// this function returns an error. Note that bitcoind must run with the
+// `txindex` option enabled to serve transactions that are not in the mempool
+// nor in the configured wallet.
+func (c *Connection) GetTransaction(
+	transactionHash bitcoin.Hash,
+) (*bitcoin.Transaction, error) {
+	txID := transactionHash.Hex(bitcoin.ReversedByteOrder)
+
+	rawTransaction, err := requestWithRetry(
+		c,
+		func(ctx context.Context) (string, error) {
+			var rawTransaction string
+			err := c.call(
+				ctx,
+				false,
+				"getrawtransaction",
+				[]interface{}{txID, false},
+				&rawTransaction,
+			)
+			if err != nil {
+				if isNotFoundErr(err) {
+					// The transaction was not found on the chain. There is
+					// no point in retrying the request and losing time.
+					return "", nil
+				}
+
+				return "", err
+			}
+
+			return rawTransaction, nil
+		},
+		"getrawtransaction",
+	)
+	if err != nil {
+		return nil, fmt.Errorf(
+			"failed to get raw transaction with ID [%s]: [%w]",
+			txID,
+			err,
+		)
+	}
+	if len(rawTransaction) == 0 {
+		return nil, fmt.Errorf(
+			"failed to get raw transaction with ID [%s]: [%v]",
+			txID,
+			fmt.Errorf("not found"),
+		)
+	}
+
+	result, err := convertRawTransaction(rawTransaction)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert transaction: [%w]", err)
+	}
+
+	return result, nil
+}
+
+// GetTransactionConfirmations gets the number of confirmations for the
+// transaction with the given transaction hash. If the transaction with the
+// given hash was not found on the chain, this function returns an error.
+func (c *Connection) GetTransactionConfirmations(
+	transactionHash bitcoin.Hash,
+) (uint, error) {
+	txID := transactionHash.Hex(bitcoin.ReversedByteOrder)
+
+	type verboseTransaction struct {
+		TxID string `json:"txid"`
+		// Confirmations field is omitted for mempool transactions.
+		Confirmations uint `json:"confirmations"`
+	}
+
+	transaction, err := requestWithRetry(
+		c,
+		func(ctx context.Context) (*verboseTransaction, error) {
+			transaction := &verboseTransaction{}
+			err := c.call(
+				ctx,
+				false,
+				"getrawtransaction",
+				[]interface{}{txID, true},
+				transaction,
+			)
+			if err != nil {
+				if isNotFoundErr(err) {
+					// The transaction was not found on the chain. There is
+					// no point in retrying the request and losing time.
+					return nil, nil
+				}
+
+				return nil, err
+			}
+
+			return transaction, nil
+		},
+		"getrawtransaction",
+	)
+	if err != nil {
+		return 0, fmt.Errorf(
+			"failed to get raw transaction with ID [%s]: [%w]",
+			txID,
+			err,
+		)
+	}
+	if transaction == nil {
+		return 0, fmt.Errorf(
+			"failed to get raw transaction with ID [%s]: [%v]",
+			txID,
+			fmt.Errorf("not found"),
+		)
+	}
+
+	return transaction.Confirmations, nil
+}
+
+// BroadcastTransaction broadcasts the given transaction over the
+// network of the Bitcoin chain nodes. If the broadcast action could not be
+// done, this function returns an error. This function does not give any
+// guarantees regarding transaction mining. The transaction may be mined or
+// rejected eventually.
+func (c *Connection) BroadcastTransaction(
+	transaction *bitcoin.Transaction,
+) error {
+	rawTx := hex.EncodeToString(transaction.Serialize())
+
+	rawTxLogger := logger.With(
+		zap.String("rawTx", rawTx),
+	)
+	rawTxLogger.Debugf("broadcasting transaction")
+
+	response, err := requestWithRetry(
+		c,
+		func(ctx context.Context) (string, error) {
+			var txID string
+			err := c.call(
+				ctx,
+				false,
+				"sendrawtransaction",
+				[]interface{}{rawTx},
+				&txID,
+			)
+			return txID, err
+		},
+		"sendrawtransaction",
+	)
+	if err != nil {
+		return fmt.Errorf("failed to broadcast the transaction: [%w]", err)
+	}
+
+	rawTxLogger.Infof("transaction broadcast successful: [%s]", response)
+
+	return nil
+}
+
+// GetLatestBlockHeight gets the height of the latest block (tip). If the
+// latest block was not determined, this function returns an error.
+func (c *Connection) GetLatestBlockHeight() (uint, error) {
+	blockHeight, err := requestWithRetry(
+		c,
+		func(ctx context.Context) (int64, error) {
+			var blockCount int64
+			err := c.call(ctx, false, "getblockcount", nil, &blockCount)
+			return blockCount, err
+		},
+		"getblockcount",
+	)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get block count: [%w]", err)
+	}
+
+	if blockHeight > 0 {
+		return uint(blockHeight), nil
+	}
+
+	return 0, nil
+}
+
+// GetBlockHeader gets the block header for the given block height. If the
+// block with the given height was not found on the chain, this function
+// returns an error.
+func (c *Connection) GetBlockHeader(
+	blockHeight uint,
+) (*bitcoin.BlockHeader, error) {
+	blockHash, err := c.getBlockHash(blockHeight)
+	if err != nil {
+		return nil, err
+	}
+
+	rawBlockHeader, err := requestWithRetry(
+		c,
+		func(ctx context.Context) (string, error) {
+			var rawBlockHeader string
+			err := c.call(
+				ctx,
+				false,
+				"getblockheader",
+				[]interface{}{blockHash, false},
+				&rawBlockHeader,
+			)
+			return rawBlockHeader, err
+		},
+		"getblockheader",
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get block header: [%w]", err)
+	}
+
+	blockHeader, err := convertBlockHeader(rawBlockHeader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert block header: %w", err)
+	}
+
+	return blockHeader, nil
+}
+
+// GetTransactionMerkleProof gets the Merkle proof for a given transaction.
+// The transaction's hash and the block the transaction was included in the
+// blockchain need to be provided.
+func (c *Connection) GetTransactionMerkleProof(
+	transactionHash bitcoin.Hash,
+	blockHeight uint,
+) (*bitcoin.TransactionMerkleProof, error) {
+	txID := transactionHash.Hex(bitcoin.ReversedByteOrder)
+
+	blockHash, err := c.getBlockHash(blockHeight)
+	if err != nil {
+		return nil, err
+	}
+
+	rawMerkleBlock, err := requestWithRetry(
+		c,
+		func(ctx context.Context) (string, error) {
+			var rawMerkleBlock string
+			err := c.call(
+				ctx,
+				false,
+				"gettxoutproof",
+				[]interface{}{[]string{txID}, blockHash},
+				&rawMerkleBlock,
+			)
+			return rawMerkleBlock, err
+		},
+		"gettxoutproof",
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get merkle proof: [%w]", err)
+	}
+
+	merkleProof, err := convertMerkleProof(
+		rawMerkleBlock,
+		transactionHash,
+		blockHeight,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert merkle proof: [%w]", err)
+	}
+
+	return merkleProof, nil
+}
+
+// GetTransactionsForPublicKeyHash gets confirmed transactions that pays the
+// given public key hash using either a P2PKH or P2WPKH script. The returned
+// transactions are ordered by block height in the ascending order, i.e.
+// the latest transaction is at the end of the list. The returned list does
+// not contain unconfirmed transactions living in the mempool at the moment
+// of request. The returned transactions list can be limited using the
+// `limit` parameter. For example, if `limit` is set to `5`, only the
+// latest five transactions will be returned. Note that taking an unlimited
+// transaction history may be time-consuming as this function fetches
+// complete transactions with all necessary data.
+func (c *Connection) GetTransactionsForPublicKeyHash(
+	publicKeyHash [20]byte,
+	limit int,
+) ([]*bitcoin.Transaction, error) {
+	txHashes, err := c.GetTxHashesForPublicKeyHash(publicKeyHash)
+	if err != nil {
+		return nil, err
+	}
+
+	var selectedTxHashes []bitcoin.Hash
+	if len(txHashes) > limit {
+		selectedTxHashes = txHashes[len(txHashes)-limit:]
+	} else {
+		selectedTxHashes = txHashes
+	}
+
+	transactions := make([]*bitcoin.Transaction, len(selectedTxHashes))
+	for i, txHash := range selectedTxHashes {
+		transaction, err := c.GetTransaction(txHash)
+		if err != nil {
+			return nil, fmt.Errorf("cannot get transaction: [%v]", err)
+		}
+
+		transactions[i] = transaction
+	}
+
+	return transactions, nil
+}
+
+// GetTxHashesForPublicKeyHash gets hashes of confirmed transactions that pays
+// the given public key hash using either a P2PKH or P2WPKH script. The returned
+// transactions hashes are ordered by block height in the ascending order, i.e.
+// the latest transaction hash is at the end of the list. The returned list does
+// not contain unconfirmed transactions hashes living in the mempool at the
+// moment of request. This function requires a watch-only wallet to be
+// configured.
+func (c *Connection) GetTxHashesForPublicKeyHash(
+	publicKeyHash [20]byte,
+) ([]bitcoin.Hash, error) {
+	items, err := c.getPublicKeyHashHistory(publicKeyHash)
+	if err != nil {
+		return nil, fmt.Errorf(
+			"cannot get history for public key hash [0x%x]: [%v]",
+			publicKeyHash,
+			err,
+		)
+	}
+
+	confirmedItems := make([]*walletTransactionItem, 0)
+	for _, item := range items {
+		if item.Confirmations > 0 {
+			confirmedItems = append(confirmedItems, item)
+		}
+	}
+
+	sort.SliceStable(
+		confirmedItems,
+		func(i, j int) bool {
+			return confirmedItems[i].BlockHeight < confirmedItems[j].BlockHeight
+		},
+	)
+
+	txHashes := make([]bitcoin.Hash, len(confirmedItems))
+	for i, item := range confirmedItems {
+		txHash, err := bitcoin.NewHashFromString(
+			item.TxID,
+			bitcoin.ReversedByteOrder,
+		)
+		if err != nil {
+			return nil, fmt.Errorf(
+				"cannot parse hash [%s]: [%v]",
+				item.TxID,
+				err,
+			)
+		}
+
+		txHashes[i] = txHash
+	}
+
+	return txHashes, nil
+}
+
+// GetMempoolForPublicKeyHash gets the unconfirmed mempool transactions
+// that pays the given public key hash using either a P2PKH or P2WPKH script.
+// The returned transactions are in an indefinite order. This function
+// requires a watch-only wallet to be configured.
+func (c *Connection) GetMempoolForPublicKeyHash(
+	publicKeyHash [20]byte,
+) ([]*bitcoin.Transaction, error) {
+	items, err := c.getPublicKeyHashHistory(publicKeyHash)
+	if err != nil {
+		return nil, fmt.Errorf(
+			"cannot get history for public key hash [0x%x]: [%v]",
+			publicKeyHash,
+			err,
+		)
+	}
+
+	transactions := make([]*bitcoin.Transaction, 0)
+	for _, item := range items {
+		// Conflicted transactions have a negative number of confirmations
+		// and are not considered as mempool items.
+		if item.Confirmations != 0 {
+			continue
+		}
+
+		txHash, err := bitcoin.NewHashFromString(
+			item.TxID,
+			bitcoin.ReversedByteOrder,
+		)
+		if err != nil {
+			return nil, fmt.Errorf(
+				"cannot parse hash [%s]: [%v]",
+				item.TxID,
+				err,
+			)
+		}
+
+		transaction, err := c.GetTransaction(txHash)
+		if err != nil {
+			return nil, fmt.Errorf("cannot get transaction: [%v]", err)
+		}
+
+		transactions = append(transactions, transaction)
+	}
+
+	return transactions, nil
+}
+
+type walletTransactionItem struct {
+	TxID          string `json:"txid"`
+	Category      string `json:"category"`
+	Confirmations int64  `json:"confirmations"`
+	BlockHeight   int64  `json:"blockheight"`
+}
+
+// getPublicKeyHashHistory returns the wallet transactions paying the given
+// public key hash or spending outputs locked on it, including the unconfirmed
+// ones. Each transaction is returned only once even if it pays the public key
+// hash multiple times. The returned list is in an indefinite order.
+func (c *Connection) getPublicKeyHashHistory(
+	publicKeyHash [20]byte,
+) ([]*walletTransactionItem, error) {
+	label, err := c.watchPublicKeyHash(publicKeyHash)
+	if err != nil {
+		return nil, fmt.Errorf("cannot watch public key hash: [%v]", err)
+	}
+
+	// Listing transactions by label returns only the incoming ones, i.e.
+	// transactions paying the public key hash.
+	incomingItems, err := c.listWalletTransactions(label)
+	if err != nil {
+		return nil, fmt.Errorf(
+			"failed to list incoming wallet transactions: [%v]",
+			err,
+		)
+	}
+
+	items := make([]*walletTransactionItem, 0)
+	seenTxIDs := make(map[string]bool)
+
+	for _, item := range incomingItems {
+		// A transaction paying both P2PKH and P2WPKH scripts of the
+		// public key hash, or paying the same script multiple times,
+		// is listed once for each output.
+		if seenTxIDs[item.TxID] {
+			continue
+		}
+		seenTxIDs[item.TxID] = true
+
+		items = append(items, item)
+	}
+
+	outgoingItems, err := c.getPublicKeyHashSpends(publicKeyHash, items)
+	if err != nil {
+		return nil, fmt.Errorf(
+			"failed to determine outgoing wallet transactions: [%v]",
+			err,
+		)
+	}
+
+	for _, item := range outgoingItems {
+		if seenTxIDs[item.TxID] {
+			continue
+		}
+		seenTxIDs[item.TxID] = true
+
+		items = append(items, item)
+	}
+
+	return items, nil
+}
+
+// getPublicKeyHashSpends returns the wallet transactions spending outputs
+// locked on the given public key hash. The outputs are determined based on
+// the given incoming transactions of the public key hash. This is needed
+// because bitcoind lists only incoming transactions for a label and a
+// transaction spending the public key hash outputs without paying any change
+// back would be missed otherwise.
+func (c *Connection) getPublicKeyHashSpends(
+	publicKeyHash [20]byte,
+	incomingItems []*walletTransactionItem,
+) ([]*walletTransactionItem, error) {
+	scripts, err := publicKeyHashScripts(publicKeyHash)
+	if err != nil {
+		return nil, err
+	}
+
+	isPublicKeyHashScript := func(script []byte) bool {
+		for _, s := range scripts {
+			if bytes.Equal(s, script) {
+				return true
+			}
+		}
+		return false
+	}
+
+	outpoints := make(map[bitcoin.TransactionOutpoint]bool)
+	for _, item := range incomingItems {
+		// Conflicted transactions will never produce spendable outputs.
+		if item.Confirmations < 0 {
+			continue
+		}
+
+		transaction, err := c.getWalletTransaction(item.TxID)
+		if err != nil {
+			return nil, err
+		}
+
+		for i, output := range transaction.Outputs {
+			if isPublicKeyHashScript(output.PublicKeyScript) {
+				outpoints[bitcoin.TransactionOutpoint{
+					TransactionHash: transaction.Hash(),
+					OutputIndex:     uint32(i),
+				}] = true
+			}
+		}
+	}
+
+	if len(outpoints) == 0 {
+		return []*walletTransactionItem{}, nil
+	}
+
+	// The wallet may watch scripts of multiple public key hashes so all
+	// outgoing transactions must be inspected.
+	allItems, err := c.listWalletTransactions("*")
+	if err != nil {
+		return nil, fmt.Errorf(
+			"failed to list all wallet transactions: [%v]",
+			err,
+		)
+	}
+
+	items := make([]*walletTransactionItem, 0)
+	inspectedTxIDs := make(map[string]bool)
+
+	for _, item := range allItems {
+		if item.Category != "send" || inspectedTxIDs[item.TxID] {
+			continue
+		}
+		inspectedTxIDs[item.TxID] = true
+
+		transaction, err := c.getWalletTransaction(item.TxID)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, input := range transaction.Inputs {
+			if outpoints[*input.Outpoint] {
+				items = append(items, item)
+				break
+			}
+		}
+	}
+
+	return items, nil
+}
+
+// getWalletTransaction gets the wallet transaction with the given ID. Wallet
+// transactions are immutable so they are cached after the first fetch.
+func (c *Connection) getWalletTransaction(
+	txID string,
+) (*bitcoin.Transaction, error) {
+	c.walletTransactionsCacheMutex.Lock()
+	transaction, ok := c.walletTransactionsCache[txID]
+	c.walletTransactionsCacheMutex.Unlock()
+
+	if ok {
+		return transaction, nil
+	}
+
+	txHash, err := bitcoin.NewHashFromString(txID, bitcoin.ReversedByteOrder)
+	if err != nil {
+		return nil, fmt.Errorf("cannot parse hash [%s]: [%v]", txID, err)
+	}
+
+	transaction, err = c.GetTransaction(txHash)
+	if err != nil {
+		return nil, fmt.Errorf("cannot get transaction: [%v]", err)
+	}
+
+	c.walletTransactionsCacheMutex.Lock()
+	if c.walletTransactionsCache == nil {
+		c.walletTransactionsCache = make(map[string]*bitcoin.Transaction)
+	}
+	c.walletTransactionsCache[txID] = transaction
+	c.walletTransactionsCacheMutex.Unlock()
+
+	return transaction, nil
+}
+
+// listWalletTransactions lists all wallet transaction entries with the given
+// label, including the watch-only ones. The `*` label lists entries of
+// all labels. A single transaction may be listed multiple times.
+func (c *Connection) listWalletTransactions(
+	label string,
+) ([]*walletTransactionItem, error) {
+	items := make([]*walletTransactionItem, 0)
+
+	for skip := 0; ; skip += listTransactionsPageSize {
+		page, err := requestWithRetry(
+			c,
+			func(ctx context.Context) ([]*walletTransactionItem, error) {
+				var page []*walletTransactionItem
+				err := c.call(
+					ctx,
+					true,
+					"listtransactions",
+					[]interface{}{label, listTransactionsPageSize, skip, true},
+					&page,
+				)
+				return page, err
+			},
+			"listtransactions",
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		items = append(items, page...)
+
+		if len(page) < listTransactionsPageSize {
+			break
+		}
+	}
+
+	return items, nil
+}
+
+// watchPublicKeyHash makes sure the P2PKH and P2WPKH scripts of the given
+// public key hash are watched by the configured wallet. Scripts are imported
+// as `raw` descriptors labeled with the public key hash. The label is
+// returned and can be used to filter wallet transactions.
+func (c *Connection) watchPublicKeyHash(
+	publicKeyHash [20]byte,
+) (string, error) {
+	if len(c.config.Wallet) == 0 {
+		return "", fmt.Errorf(
+			"bitcoind wallet is not configured; see bitcoin bitcoind " +
+				"section in configuration",
+		)
+	}
+
+	label := hex.EncodeToString(publicKeyHash[:])
+
+	scripts, err := publicKeyHashScripts(publicKeyHash)
+	if err != nil {
+		return "", err
+	}
+
+	c.watchedDescriptorsMutex.Lock()
+	defer c.watchedDescriptorsMutex.Unlock()
+
+	if c.watchedDescriptors == nil {
+		watchedDescriptors, err := c.listWalletDescriptors()
+		if err != nil {
+			return "", fmt.Errorf(
+				"failed to list wallet descriptors: [%v]",
+				err,
+			)
+		}
+
+		c.watchedDescriptors = watchedDescriptors
+	}
+
+	for _, script := range scripts {
+		descriptor, err := c.getDescriptorWithChecksum(
+			fmt.Sprintf("raw(%s)", hex.EncodeToString(script)),
+		)
+		if err != nil {
+			return "", fmt.Errorf(
+				"failed to get descriptor info: [%v]",
+				err,
+			)
+		}
+
+		if c.watchedDescriptors[descriptor] {
+			continue
+		}
+
+		logger.Infof(
+			"importing descriptor [%s] to the bitcoind wallet [%s]; "+
+				"this may take a while as the wallet needs to rescan the chain",
+			descriptor,
+			c.config.Wallet,
+		)
+
+		if err := c.importDescriptor(descriptor, label); err != nil {
+			return "", fmt.Errorf(
+				"failed to import descriptor [%s]: [%v]",
+				descriptor,
+				err,
+			)
+		}
+
+		c.watchedDescriptors[descriptor] = true
+	}
+
+	return label, nil
+}
+
+// listWalletDescriptors returns a set of descriptors imported to the
+// configured wallet.
+func (c *Connection) listWalletDescriptors() (map[string]bool, error) {
+	type listDescriptorsResult struct {
+		Descriptors []struct {
+			Desc string `json:"desc"`
+		} `json:"descriptors"`
+	}
+
+	result, err := requestWithRetry(
+		c,
+		func(ctx context.Context) (*listDescriptorsResult, error) {
+			result := &listDescriptorsResult{}
+			err := c.call(ctx, true, "listdescriptors", nil, result)
+			return result, err
+		},
+		"listdescriptors",
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	descriptors := make(map[string]bool)
+	for _, descriptor := range result.Descriptors {
+		descriptors[descriptor.Desc] = true
+	}
+
+	return descriptors, nil
+}
+
+// getDescriptorWithChecksum returns the canonical form of the given
+// descriptor, along with its checksum, as computed by bitcoind.
+func (c *Connection) getDescriptorWithChecksum(
+	descriptor string,
+) (string, error) {
+	type descriptorInfo struct {
+		Descriptor string `json:"descriptor"`
+	}
+
+	info, err := requestWithRetry(
+		c,
+		func(ctx context.Context) (*descriptorInfo, error) {
+			info := &descriptorInfo{}
+			err := c.call(
+				ctx,
+				false,
+				"getdescriptorinfo",
+				[]interface{}{descriptor},
+				info,
+			)
+			return info, err
+		},
+		"getdescriptorinfo",
+	)
+	if err != nil {
+		return "", err
+	}
+
+	return info.Descriptor, nil
+}
+
+// importDescriptor imports the given descriptor to the configured wallet
+// and rescans the chain starting from the configured rescan timestamp.
+func (c *Connection) importDescriptor(descriptor string, label string) error {
+	type importRequest struct {
+		Desc      string `json:"desc"`
+		Timestamp int64  `json:"timestamp"`
+		Label     string `json:"label"`
+	}
+
+	type importResult struct {
+		Success bool      `json:"success"`
+		Error   *rpcError `json:"error"`
+	}
+
+	results, err := scanWithRetry(
+		c,
+		func(ctx context.Context) ([]*importResult, error) {
+			var results []*importResult
+			err := c.call(
+				ctx,
+				true,
+				"importdescriptors",
+				[]interface{}{
+					[]*importRequest{
+						{
+							Desc:      descriptor,
+							Timestamp: c.config.RescanTimestamp,
+							Label:     label,
+						},
+					},
+				},
+				&results,
+			)
+			return results, err
+		},
+		"importdescriptors",
+	)
+	if err != nil {
+		return err
+	}
+
+	if len(results) != 1 {
+		return fmt.Errorf(
+			"unexpected number of import results: [%v]",
+			len(results),
+		)
+	}
+
+	if !results[0].Success {
+		return fmt.Errorf("import was not successful: [%v]", results[0].Error)
+	}
+
+	return nil
+}
+
+// GetUtxosForPublicKeyHash gets unspent outputs of confirmed transactions that
+// are controlled by the given public key hash (either a P2PKH or P2WPKH script).
+// The returned UTXOs are ordered by block height in the ascending order, i.e.
+// the latest UTXO is at the end of the list. The returned list does not contain
+// unspent outputs of unconfirmed transactions living in the mempool at the
+// moment of request. Outputs used as inputs of confirmed or mempool
+// transactions are not returned as well because they are no longer UTXOs.
+func (c *Connection) GetUtxosForPublicKeyHash(
+	publicKeyHash [20]byte,
+) ([]*bitcoin.UnspentTransactionOutput, error) {
+	scripts, err := publicKeyHashScripts(publicKeyHash)
+	if err != nil {
+		return nil, err
+	}
+
+	descriptors := make([]string, len(scripts))
+	for i, script := range scripts {
+		descriptors[i] = fmt.Sprintf("raw(%s)", hex.EncodeToString(script))
+	}
+
+	type scanResult struct {
+		Success  bool `json:"success"`
+		Unspents []struct {
+			TxID   string  `json:"txid"`
+			Vout   uint32  `json:"vout"`
+			Amount float64 `json:"amount"`
+			Height uint    `json:"height"`
+		} `json:"unspents"`
+	}
+
+	result, err := scanWithRetry(
+		c,
+		func(ctx context.Context) (*scanResult, error) {
+			result := &scanResult{}
+			err := c.call(
+				ctx,
+				false,
+				"scantxoutset",
+				[]interface{}{"start", descriptors},
+				result,
+			)
+			if err != nil {
+				return nil, err
+			}
+			if !result.Success {
+				return nil, fmt.Errorf("scan was not successful")
+			}
+
+			return result, nil
+		},
+		"scantxoutset",
+	)
+	if err != nil {
+		return nil, fmt.Errorf(
+			"cannot scan UTXO set for public key hash [0x%x]: [%v]",
+			publicKeyHash,
+			err,
+		)
+	}
+
+	sort.SliceStable(
+		result.Unspents,
+		func(i, j int) bool {
+			return result.Unspents[i].Height < result.Unspents[j].Height
+		},
+	)
+
+	utxos := make([]*bitcoin.UnspentTransactionOutput, 0)
+	for _, item := range result.Unspents {
+		txHash, err := bitcoin.NewHashFromString(
+			item.TxID,
+			bitcoin.ReversedByteOrder,
+		)
+		if err != nil {
+			return nil, fmt.Errorf(
+				"cannot parse hash [%s]: [%v]",
+				item.TxID,
+				err,
+			)
+		}
+
+		// The UTXO set scan does not take the mempool into account.
+		// Make sure the output was not spent by a mempool transaction.
+		unspent, err := c.isUnspent(item.TxID, item.Vout)
+		if err != nil {
+			return nil, fmt.Errorf(
+				"cannot check output [%s:%d]: [%v]",
+				item.TxID,
+				item.Vout,
+				err,
+			)
+		}
+		if !unspent {
+			continue
+		}
+
+		utxos = append(utxos, &bitcoin.UnspentTransactionOutput{
+			Outpoint: &bitcoin.TransactionOutpoint{
+				TransactionHash: txHash,
+				OutputIndex:     item.Vout,
+			},
+			Value: convertBtcToSat(item.Amount),
+		})
+	}
+
+	return utxos, nil
+}
+
+// isUnspent checks whether the given output is still unspent, taking the
+// mempool into account.
+func (c *Connection) isUnspent(txID string, outputIndex uint32) (bool, error) {
+	return requestWithRetry(
+		c,
+		func(ctx context.Context) (bool, error) {
+			// The gettxout call returns null if the output is spent.
+			var result *struct {
+				Confirmations uint `json:"confirmations"`
+			}
+			err := c.call(
+				ctx,
+				false,
+				"gettxout",
+				[]interface{}{txID, outputIndex, true},
+				&result,
+			)
+			if err != nil {
+				return false, err
+			}
+
+			return result != nil, nil
+		},
+		"gettxout",
+	)
+}
+
+// GetMempoolUtxosForPublicKeyHash gets unspent outputs of unconfirmed transactions
+// that are controlled by the given public key hash (either a P2PKH or P2WPKH script).
+// The returned UTXOs are in an indefinite order. The returned list does not
+// contain unspent outputs of confirmed transactions. Outputs used as inputs of
+// confirmed or mempool transactions are not returned as well because they are
+// no longer UTXOs. This function requires a watch-only wallet to be configured.
+func (c *Connection) GetMempoolUtxosForPublicKeyHash(
+	publicKeyHash [20]byte,
+) ([]*bitcoin.UnspentTransactionOutput, error) {
+	if _, err := c.watchPublicKeyHash(publicKeyHash); err != nil {
+		return nil, fmt.Errorf("cannot watch public key hash: [%v]", err)
+	}
+
+	scripts, err := publicKeyHashScripts(publicKeyHash)
+	if err != nil {
+		return nil, err
+	}
+
+	watchedScripts := make(map[string]bool)
+	for _, script := range scripts {
+		watchedScripts[hex.EncodeToString(script)] = true
+	}
+
+	type unspentItem struct {
+		TxID         string  `json:"txid"`
+		Vout         uint32  `json:"vout"`
+		ScriptPubKey string  `json:"scriptPubKey"`
+		Amount       float64 `json:"amount"`
+	}
+
+	items, err := requestWithRetry(
+		c,
+		func(ctx context.Context) ([]*unspentItem, error) {
+			var items []*unspentItem
+			// Take only outputs with zero confirmations, including those
+			// not considered safe to spend by the wallet.
+			err := c.call(
+				ctx,
+				true,
+				"listunspent",
+				[]interface{}{0, 0, []string{}, true},
+				&items,
+			)
+			return items, err
+		},
+		"listunspent",
+	)
+	if err != nil {
+		return nil, fmt.Errorf(
+			"cannot list unspent outputs for public key hash [0x%x]: [%v]",
+			publicKeyHash,
+			err,
+		)
+	}
+
+	utxos := make([]*bitcoin.UnspentTransactionOutput, 0)
+	for _, item := range items {
+		if !watchedScripts[item.ScriptPubKey] {
+			continue
+		}
+
+		txHash, err := bitcoin.NewHashFromString(
+			item.TxID,
+			bitcoin.ReversedByteOrder,
+		)
+		if err != nil {
+			return nil, fmt.Errorf(
+				"cannot parse hash [%s]: [%v]",
+				item.TxID,
+				err,
+			)
+		}
+
+		utxos = append(utxos, &bitcoin.UnspentTransactionOutput{
+			Outpoint: &bitcoin.TransactionOutpoint{
+				TransactionHash: txHash,
+				OutputIndex:     item.Vout,
+			},
+			Value: convertBtcToSat(item.Amount),
+		})
+	}
+
+	return utxos, nil
+}
+
+// EstimateSatPerVByteFee returns the estimated sat/vbyte fee for a
+// transaction to be confirmed within the given number of blocks.
+func (c *Connection) EstimateSatPerVByteFee(blocks uint32) (int64, error) {
+	type estimateResult struct {
+		// FeeRate is expressed in BTC/kvB. It is omitted if the node
+		// does not have enough information to make an estimate.
+		FeeRate *float64 `json:"feerate"`
+		Errors  []string `json:"errors"`
+	}
+
+	result, err := requestWithRetry(
+		c,
+		func(ctx context.Context) (*estimateResult, error) {
+			result := &estimateResult{}
+			err := c.call(
+				ctx,
+				false,
+				"estimatesmartfee",
+				[]interface{}{blocks},
+				result,
+			)
+			return result, err
+		},
+		"estimatesmartfee",
+	)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get fee: [%v]", err)
+	}
+
+	if result.FeeRate == nil {
+		return 0, fmt.Errorf(
+			"node does not have enough information to make an estimate: [%v]",
+			result.Errors,
+		)
+	}
+
+	return convertBtcKbToSatVByte(*result.FeeRate), nil
+}
+
+// GetBlockFeeRates returns fee rates paid by transactions included in
+// the block with the given height.
+func (c *Connection) GetBlockFeeRates(
+	blockHeight uint,
+) (*bitcoin.BlockFeeRates, error) {
+	type blockStats struct {
+		Height uint `json:"height"`
+		// FeeRatePercentiles holds sat/vbyte fee rates at the 10th, 25th,
+		// 50th, 75th and 90th percentile of the block's weight.
+		FeeRatePercentiles []int64 `json:"feerate_percentiles"`
+	}
+
+	result, err := requestWithRetry(
+		c,
+		func(ctx context.Context) (*blockStats, error) {
+			result := &blockStats{}
+			err := c.call(
+				ctx,
+				false,
+				"getblockstats",
+				[]interface{}{
+					blockHeight,
+					[]string{"height", "feerate_percentiles"},
+				},
+				result,
+			)
+			return result, err
+		},
+		"getblockstats",
+	)
+	if err != nil {
+		return nil, fmt.Errorf(
+			"failed to get stats for block height [%v]: [%v]",
+			blockHeight,
+			err,
+		)
+	}
+
+	feeRates := &bitcoin.BlockFeeRates{Height: result.Height}
+
+	if len(result.FeeRatePercentiles) != len(feeRates.Percentiles) {
+		return nil, fmt.Errorf(
+			"unexpected number of fee rate percentiles: [%v]",
+			len(result.FeeRatePercentiles),
+		)
+	}
+
+	copy(feeRates.Percentiles[:], result.FeeRatePercentiles)
+
+	return feeRates, nil
+}
+
+// GetCoinbaseTxHash gets the hash of the coinbase transaction for the given
+// block height.
+func (c *Connection) GetCoinbaseTxHash(blockHeight uint) (bitcoin.Hash, error) {
+	blockHash, err := c.getBlockHash(blockHeight)
+	if err != nil {
+		return bitcoin.Hash{}, err
+	}
+
+	type block struct {
+		Tx []string `json:"tx"`
+	}
+
+	result, err := requestWithRetry(
+		c,
+		func(ctx context.Context) (*block, error) {
+			result := &block{}
+			err := c.call(
+				ctx,
+				false,
+				"getblock",
+				[]interface{}{blockHash, 1},
+				result,
+			)
+			return result, err
+		},
+		"getblock",
+	)
+	if err != nil {
+		return bitcoin.Hash{}, fmt.Errorf(
+			"failed to get coinbase tx hash for block height [%v]: [%v]",
+			blockHeight,
+			err,
+		)
+	}
+
+	if len(result.Tx) == 0 {
+		return bitcoin.Hash{}, fmt.Errorf(
+			"block at height [%v] has no transactions",
+			blockHeight,
+		)
+	}
+
+	txHash, err := bitcoin.NewHashFromString(
+		result.Tx[0],
+		bitcoin.ReversedByteOrder,
+	)
+	if err != nil {
+		return bitcoin.Hash{}, fmt.Errorf(
+			"cannot parse hash [%s]: [%v]",
+			result.Tx[0],
+			err,
+		)
+	}
+
+	return txHash, nil
+}
+
+// getBlockHash gets the hash of the block at the given height, in the
+// reversed byte order used by bitcoind.
+func (c *Connection) getBlockHash(blockHeight uint) (string, error) {
+	blockHash, err := requestWithRetry(
+		c,
+		func(ctx context.Context) (string, error) {
+			var blockHash string
+			err := c.call(
+				ctx,
+				false,
+				"getblockhash",
+				[]interface{}{blockHeight},
+				&blockHash,
+			)
+			return blockHash, err
+		},
+		"getblockhash",
+	)
+	if err != nil {
+		return "", fmt.Errorf(
+			"failed to get block hash for block height [%v]: [%w]",
+			blockHeight,
+			err,
+		)
+	}
+
+	return blockHash, nil
+}
+
+func (c *Connection) verifyServer() error {
+	type blockchainInfo struct {
+		Chain  string `json:"chain"`
+		Blocks uint   `json:"blocks"`
+	}
+
+	info, err := requestWithRetry(
+		c,
+		func(ctx context.Context) (*blockchainInfo, error) {
+			info := &blockchainInfo{}
+			err := c.call(ctx, false, "getblockchaininfo", nil, info)
+			return info, err
+		},
+		"getblockchaininfo",
+	)
+	if err != nil {
+		return fmt.Errorf("failed to get blockchain info: [%w]", err)
+	}
+
+	logger.Infof(
+		"connected to bitcoind server [chain: [%s], blocks: [%v]]",
+		info.Chain,
+		info.Blocks,
+	)
+
+	if len(c.config.Wallet) == 0 {
+		logger.Warnf(
+			"bitcoind wallet is not configured; public key hash history " +
+				"and mempool queries will not be supported",
+		)
+	}
+
+	return nil
+}
+
+// publicKeyHashScripts returns the P2PKH and P2WPKH scripts for the given
+// public key hash.
+func publicKeyHashScripts(publicKeyHash [20]byte) ([]bitcoin.Script, error) {
+	p2pkh, err := bitcoin.PayToPublicKeyHash(publicKeyHash)
+	if err != nil {
+		return nil, fmt.Errorf(
+			"cannot build P2PKH for public key hash [0x%x]: [%v]",
+			publicKeyHash,
+			err,
+		)
+	}
+
+	p2wpkh, err := bitcoin.PayToWitnessPublicKeyHash(publicKeyHash)
+	if err != nil {
+		return nil, fmt.Errorf(
+			"cannot build P2WPKH for public key hash [0x%x]: [%v]",
+			publicKeyHash,
+			err,
+		)
+	}
+
+	return []bitcoin.Script{p2pkh, p2wpkh}, nil
+}
@@ -0,0 +1,1142 @@
+package bitcoind
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/btcsuite/btcutil"
+	"github.com/btcsuite/btcutil/bloom"
+
+	"github.com/keep-network/keep-core/internal/testutils"
+	"github.com/keep-network/keep-core/pkg/bitcoin"
+)
+
+const (
+	testUsername = "user"
+	testPassword = "pass"
+	testWallet   = "keep-watch-only"
+)
+
+// rpcHandler handles a single JSON-RPC method call of the stub server.
+type rpcHandler func(params []json.RawMessage) (interface{}, *rpcError)
+
+// stubServer is a stub bitcoind JSON-RPC server.
+type stubServer struct {
+	t *testing.T
+
+	mutex          sync.Mutex
+	handlers       map[string]rpcHandler
+	walletHandlers map[string]rpcHandler
+	calls          []string
+}
+
+func newStubServer(t *testing.T) (*stubServer, *httptest.Server) {
+	stub := &stubServer{
+		t:              t,
+		handlers:       make(map[string]rpcHandler),
+		walletHandlers: make(map[string]rpcHandler),
+	}
+
+	stub.handle("getblockchaininfo", func([]json.RawMessage) (interface{}, *rpcError) {
+		return map[string]interface{}{"chain": "regtest", "blocks": 100}, nil
+	})
+
+	server := httptest.NewServer(stub)
+	t.Cleanup(server.Close)
+
+	return stub, server
+}
+
+func (ss *stubServer) handle(method string, handler rpcHandler) {
+	ss.handlers[method] = handler
+}
+
+func (ss *stubServer) handleWallet(method string, handler rpcHandler) {
+	ss.walletHandlers[method] = handler
+}
+
+func (ss *stubServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	username, password, ok := r.BasicAuth()
+	if !ok || username != testUsername || password != testPassword {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	var request struct {
+		ID     uint64            `json:"id"`
+		Method string            `json:"method"`
+		Params []json.RawMessage `json:"params"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		ss.t.Errorf("cannot decode request: [%v]", err)
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	handlers := ss.handlers
+	if r.URL.Path == fmt.Sprintf("/wallet/%s", testWallet) {
+		handlers = ss.walletHandlers
+	} else if r.URL.Path != "/" && r.URL.Path != "" {
+		ss.t.Errorf("unexpected path: [%s]", r.URL.Path)
+	}
+
+	ss.mutex.Lock()
+	ss.calls = append(ss.calls, request.Method)
+	ss.mutex.Unlock()
+
+	response := map[string]interface{}{
+		"id":     request.ID,
+		"result": nil,
+		"error":  nil,
+	}
+
+	handler, ok := handlers[request.Method]
+	if !ok {
+		w.WriteHeader(http.StatusNotFound)
+		response["error"] = &rpcError{Code: -32601, Message: "Method not found"}
+	} else {
+		result, rpcErr := handler(request.Params)
+		if rpcErr != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			response["error"] = rpcErr
+		} else {
+			response["result"] = result
+		}
+	}
+
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		ss.t.Errorf("cannot encode response: [%v]", err)
+	}
+}
+
+func (ss *stubServer) callsCount(method string) int {
+	ss.mutex.Lock()
+	defer ss.mutex.Unlock()
+
+	count := 0
+	for _, call := range ss.calls {
+		if call == method {
+			count++
+		}
+	}
+	return count
+}
+
+func connectToStub(t *testing.T, server *httptest.Server) *Connection {
+	chain, err := Connect(context.Background(), Config{
+		URL:                 server.URL,
+		Username:            testUsername,
+		Password:            testPassword,
+		Wallet:              testWallet,
+		RequestTimeout:      1 * time.Second,
+		RequestRetryTimeout: 1 * time.Second,
+		ScanTimeout:         1 * time.Second,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return chain.(*Connection)
+}
+
+func unmarshalParam(t *testing.T, params []json.RawMessage, index int, v interface{}) {
+	if len(params) <= index {
+		t.Fatalf("missing param at index [%v]", index)
+	}
+	if err := json.Unmarshal(params[index], v); err != nil {
+		t.Fatalf("cannot unmarshal param at index [%v]: [%v]", index, err)
+	}
+}
+
+func hashFromString(t *testing.T, s string) bitcoin.Hash {
+	hash, err := bitcoin.NewHashFromString(s, bitcoin.ReversedByteOrder)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return hash
+}
+
+var testTransaction = &bitcoin.Transaction{
+	Version: 1,
+	Inputs: []*bitcoin.TransactionInput{
+		{
+			Outpoint: &bitcoin.TransactionOutpoint{
+				TransactionHash: bitcoin.Hash{0x01},
+				OutputIndex:     1,
+			},
+			SignatureScript: []byte{},
+			Witness: [][]byte{
+				{0x02, 0x03},
+				{0x04},
+			},
+			Sequence: 0xffffffff,
+		},
+	},
+	Outputs: []*bitcoin.TransactionOutput{
+		{
+			Value:           50000,
+			PublicKeyScript: []byte{0x00, 0x14, 0x05},
+		},
+	},
+	Locktime: 0,
+}
+
+func TestConnect_Unauthorized(t *testing.T) {
+	_, server := newStubServer(t)
+
+	_, err := Connect(context.Background(), Config{
+		URL:                 server.URL,
+		Username:            testUsername,
+		Password:            "wrong",
+		RequestTimeout:      1 * time.Second,
+		RequestRetryTimeout: 1 * time.Second,
+	})
+	if err == nil {
+		t.Fatal("expected error")
+	}
+}
+
+func TestGetTransaction(t *testing.T) {
+	stub, server := newStubServer(t)
+
+	txID := testTransaction.Hash().Hex(bitcoin.ReversedByteOrder)
+
+	stub.handle("getrawtransaction", func(params []json.RawMessage) (interface{}, *rpcError) {
+		var requestedTxID string
+		unmarshalParam(t, params, 0, &requestedTxID)
+		if requestedTxID != txID {
+			return nil, &rpcError{
+				Code:    rpcInvalidAddressOrKeyCode,
+				Message: "No such mempool or blockchain transaction",
+			}
+		}
+
+		return hex.EncodeToString(testTransaction.Serialize()), nil
+	})
+
+	connection := connectToStub(t, server)
+
+	transaction, err := connection.GetTransaction(testTransaction.Hash())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(testTransaction, transaction) {
+		t.Errorf(
+			"unexpected transaction\nexpected: %+v\nactual:   %+v",
+			testTransaction,
+			transaction,
+		)
+	}
+
+	_, err = connection.GetTransaction(bitcoin.Hash{0xff})
+	if err == nil {
+		t.Fatal("expected error")
+	}
+	testutils.AssertIntsEqual(
+		t,
+		"getrawtransaction calls count",
+		2,
+		stub.callsCount("getrawtransaction"),
+	)
+}
+
+func TestGetTransactionConfirmations(t *testing.T) {
+	stub, server := newStubServer(t)
+
+	stub.handle("getrawtransaction", func(params []json.RawMessage) (interface{}, *rpcError) {
+		var verbose bool
+		unmarshalParam(t, params, 1, &verbose)
+		if !verbose {
+			t.Errorf("expected verbose request")
+		}
+
+		return map[string]interface{}{
+			"txid":          testTransaction.Hash().Hex(bitcoin.ReversedByteOrder),
+			"confirmations": 7,
+		}, nil
+	})
+
+	connection := connectToStub(t, server)
+
+	confirmations, err := connection.GetTransactionConfirmations(
+		testTransaction.Hash(),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testutils.AssertUintsEqual(t, "confirmations", 7, uint64(confirmations))
+}
+
+func TestBroadcastTransaction(t *testing.T) {
+	stub, server := newStubServer(t)
+
+	var broadcastTx string
+	stub.handle("sendrawtransaction", func(params []json.RawMessage) (interface{}, *rpcError) {
+		unmarshalParam(t, params, 0, &broadcastTx)
+		return testTransaction.Hash().Hex(bitcoin.ReversedByteOrder), nil
+	})
+
+	connection := connectToStub(t, server)
+
+	err := connection.BroadcastTransaction(testTransaction)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testutils.AssertStringsEqual(
+		t,
+		"broadcast transaction",
+		hex.EncodeToString(testTransaction.Serialize()),
+		broadcastTx,
+	)
+}
+
+func TestGetLatestBlockHeight(t *testing.T) {
+	stub, server := newStubServer(t)
+
+	stub.handle("getblockcount", func([]json.RawMessage) (interface{}, *rpcError) {
+		return 812345, nil
+	})
+
+	connection := connectToStub(t, server)
+
+	blockHeight, err := connection.GetLatestBlockHeight()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testutils.AssertUintsEqual(t, "block height", 812345, uint64(blockHeight))
+}
+
+func TestGetBlockHeader(t *testing.T) {
+	stub, server := newStubServer(t)
+
+	expectedBlockHeader := &bitcoin.BlockHeader{
+		Version:                 536870916,
+		PreviousBlockHeaderHash: bitcoin.Hash{0x01, 0x02},
+		MerkleRootHash:          bitcoin.Hash{0x03, 0x04},
+		Time:                    1641914003,
+		Bits:                    436256810,
+		Nonce:                   778087099,
+	}
+	blockHash := "00000000000000000000000000000000000000000000000000000000000000aa"
+
+	stub.handle("getblockhash", func(params []json.RawMessage) (interface{}, *rpcError) {
+		var height uint
+		unmarshalParam(t, params, 0, &height)
+		testutils.AssertUintsEqual(t, "block height", 2135502, uint64(height))
+		return blockHash, nil
+	})
+	stub.handle("getblockheader", func(params []json.RawMessage) (interface{}, *rpcError) {
+		var requestedHash string
+		unmarshalParam(t, params, 0, &requestedHash)
+		testutils.AssertStringsEqual(t, "block hash", blockHash, requestedHash)
+
+		serialized := expectedBlockHeader.Serialize()
+		return hex.EncodeToString(serialized[:]), nil
+	})
+
+	connection := connectToStub(t, server)
+
+	blockHeader, err := connection.GetBlockHeader(2135502)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(expectedBlockHeader, blockHeader) {
+		t.Errorf(
+			"unexpected block header\nexpected: %+v\nactual:   %+v",
+			expectedBlockHeader,
+			blockHeader,
+		)
+	}
+}
+
+func TestGetTransactionMerkleProof(t *testing.T) {
+	var tests = map[string]struct {
+		transactionsCount int
+		position          int
+	}{
+		"single transaction block": {
+			transactionsCount: 1,
+			position:          0,
+		},
+		"first transaction": {
+			transactionsCount: 7,
+			position:          0,
+		},
+		"middle transaction": {
+			transactionsCount: 7,
+			position:          3,
+		},
+		"last transaction in odd-width level": {
+			transactionsCount: 7,
+			position:          6,
+		},
+		"last transaction in even-width level": {
+			transactionsCount: 8,
+			position:          7,
+		},
+	}
+
+	for testName, test := range tests {
+		t.Run(testName, func(t *testing.T) {
+			stub, server := newStubServer(t)
+
+			block := newTestBlock(test.transactionsCount)
+			transactionHash := bitcoin.Hash(block.Transactions[test.position].TxHash())
+			rawMerkleBlock := newTestMerkleBlock(t, block, test.position)
+
+			stub.handle("getblockhash", func([]json.RawMessage) (interface{}, *rpcError) {
+				return "00000000000000000000000000000000000000000000000000000000000000aa", nil
+			})
+			stub.handle("gettxoutproof", func(params []json.RawMessage) (interface{}, *rpcError) {
+				var txIDs []string
+				unmarshalParam(t, params, 0, &txIDs)
+				if len(txIDs) != 1 ||
+					txIDs[0] != transactionHash.Hex(bitcoin.ReversedByteOrder) {
+					t.Errorf("unexpected transaction IDs: [%v]", txIDs)
+				}
+
+				return rawMerkleBlock, nil
+			})
+
+			connection := connectToStub(t, server)
+
+			merkleProof, err := connection.GetTransactionMerkleProof(
+				transactionHash,
+				1000,
+			)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			expectedMerkleProof := &bitcoin.TransactionMerkleProof{
+				BlockHeight: 1000,
+				MerkleNodes: computeMerkleBranch(block, test.position),
+				Position:    uint(test.position),
+			}
+
+			if !reflect.DeepEqual(expectedMerkleProof, merkleProof) {
+				t.Errorf(
+					"unexpected merkle proof\nexpected: %+v\nactual:   %+v",
+					expectedMerkleProof,
+					merkleProof,
+				)
+			}
+		})
+	}
+}
+
+func TestGetTransactionMerkleProof_WrongTransaction(t *testing.T) {
+	stub, server := newStubServer(t)
+
+	block := newTestBlock(5)
+	rawMerkleBlock := newTestMerkleBlock(t, block, 2)
+
+	stub.handle("getblockhash", func([]json.RawMessage) (interface{}, *rpcError) {
+		return "00000000000000000000000000000000000000000000000000000000000000aa", nil
+	})
+	stub.handle("gettxoutproof", func([]json.RawMessage) (interface{}, *rpcError) {
+		return rawMerkleBlock, nil
+	})
+
+	connection := connectToStub(t, server)
+
+	_, err := connection.GetTransactionMerkleProof(
+		bitcoin.Hash(block.Transactions[3].TxHash()),
+		1000,
+	)
+	if err == nil {
+		t.Fatal("expected error")
+	}
+}
+
+func TestGetTxHashesForPublicKeyHash(t *testing.T) {
+	stub, server := newStubServer(t)
+
+	publicKeyHash := [20]byte{0x8d, 0xb5, 0x0e, 0xb5, 0x20}
+	label := hex.EncodeToString(publicKeyHash[:])
+
+	stub.handle("getdescriptorinfo", func(params []json.RawMessage) (interface{}, *rpcError) {
+		var descriptor string
+		unmarshalParam(t, params, 0, &descriptor)
+		return map[string]interface{}{
+			"descriptor": descriptor + "#checksum",
+		}, nil
+	})
+
+	// One of the descriptors is already watched by the wallet.
+	p2wpkh, err := bitcoin.PayToWitnessPublicKeyHash(publicKeyHash)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stub.handleWallet("listdescriptors", func([]json.RawMessage) (interface{}, *rpcError) {
+		return map[string]interface{}{
+			"descriptors": []map[string]interface{}{
+				{"desc": fmt.Sprintf("raw(%x)#checksum", p2wpkh)},
+			},
+		}, nil
+	})
+
+	var importedDescriptors []string
+	stub.handleWallet("importdescriptors", func(params []json.RawMessage) (interface{}, *rpcError) {
+		var requests []struct {
+			Desc  string `json:"desc"`
+			Label string `json:"label"`
+		}
+		unmarshalParam(t, params, 0, &requests)
+		for _, request := range requests {
+			testutils.AssertStringsEqual(t, "label", label, request.Label)
+			importedDescriptors = append(importedDescriptors, request.Desc)
+		}
+
+		return []map[string]interface{}{{"success": true}}, nil
+	})
+
+	p2pkh, err := bitcoin.PayToPublicKeyHash(publicKeyHash)
+	if err != nil {
+		t.Fatal(err)
+	}
+	otherScript := []byte{0x00, 0x14, 0xaa, 0xbb}
+
+	// Confirmed transaction paying the P2WPKH script.
+	tx1 := newTestTransaction(
+		[]*bitcoin.TransactionOutpoint{{TransactionHash: bitcoin.Hash{0x01}}},
+		[][]byte{p2wpkh},
+	)
+	// Confirmed transaction paying the P2PKH script twice.
+	tx2 := newTestTransaction(
+		[]*bitcoin.TransactionOutpoint{{TransactionHash: bitcoin.Hash{0x02}}},
+		[][]byte{p2pkh, p2pkh},
+	)
+	// Mempool transaction paying the P2WPKH script.
+	tx3 := newTestTransaction(
+		[]*bitcoin.TransactionOutpoint{{TransactionHash: bitcoin.Hash{0x03}}},
+		[][]byte{p2wpkh},
+	)
+	// Conflicted transaction paying the P2WPKH script.
+	tx4 := newTestTransaction(
+		[]*bitcoin.TransactionOutpoint{{TransactionHash: bitcoin.Hash{0x04}}},
+		[][]byte{p2wpkh},
+	)
+	// Confirmed transaction spending the public key hash output without
+	// paying any change back.
+	tx5 := newTestTransaction(
+		[]*bitcoin.TransactionOutpoint{
+			{TransactionHash: tx2.Hash(), OutputIndex: 1},
+		},
+		[][]byte{otherScript},
+	)
+	// Confirmed transaction spending an output of another watched script.
+	tx6 := newTestTransaction(
+		[]*bitcoin.TransactionOutpoint{{TransactionHash: bitcoin.Hash{0x06}}},
+		[][]byte{otherScript},
+	)
+
+	transactions := make(map[string]*bitcoin.Transaction)
+	for _, tx := range []*bitcoin.Transaction{tx1, tx2, tx3, tx4, tx5, tx6} {
+		transactions[tx.Hash().Hex(bitcoin.ReversedByteOrder)] = tx
+	}
+
+	stub.handle("getrawtransaction", func(params []json.RawMessage) (interface{}, *rpcError) {
+		var txID string
+		unmarshalParam(t, params, 0, &txID)
+
+		transaction, ok := transactions[txID]
+		if !ok {
+			return nil, &rpcError{
+				Code:    rpcInvalidAddressOrKeyCode,
+				Message: "No such mempool or blockchain transaction",
+			}
+		}
+
+		return hex.EncodeToString(transaction.Serialize()), nil
+	})
+
+	txID := func(tx *bitcoin.Transaction) string {
+		return tx.Hash().Hex(bitcoin.ReversedByteOrder)
+	}
+
+	incomingItems := []map[string]interface{}{
+		{
+			"txid":          txID(tx1),
+			"category":      "receive",
+			"confirmations": 3,
+			"blockheight":   200,
+		},
+		{
+			"txid":          txID(tx2),
+			"category":      "receive",
+			"confirmations": 10,
+			"blockheight":   193,
+		},
+		// The same transaction paying the public key hash twice.
+		{
+			"txid":          txID(tx2),
+			"category":      "receive",
+			"confirmations": 10,
+			"blockheight":   193,
+		},
+		{
+			"txid":          txID(tx3),
+			"category":      "receive",
+			"confirmations": 0,
+		},
+		{
+			"txid":          txID(tx4),
+			"category":      "receive",
+			"confirmations": -1,
+		},
+	}
+	outgoingItems := []map[string]interface{}{
+		{
+			"txid":          txID(tx5),
+			"category":      "send",
+			"confirmations": 1,
+			"blockheight":   202,
+		},
+		{
+			"txid":          txID(tx6),
+			"category":      "send",
+			"confirmations": 2,
+			"blockheight":   201,
+		},
+	}
+
+	stub.handleWallet("listtransactions", func(params []json.RawMessage) (interface{}, *rpcError) {
+		var requestedLabel string
+		unmarshalParam(t, params, 0, &requestedLabel)
+
+		switch requestedLabel {
+		case label:
+			return incomingItems, nil
+		case "*":
+			return append(incomingItems, outgoingItems...), nil
+		default:
+			t.Errorf("unexpected label: [%s]", requestedLabel)
+			return []interface{}{}, nil
+		}
+	})
+
+	connection := connectToStub(t, server)
+
+	txHashes, err := connection.GetTxHashesForPublicKeyHash(publicKeyHash)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expectedTxHashes := []bitcoin.Hash{
+		tx2.Hash(),
+		tx1.Hash(),
+		tx5.Hash(),
+	}
+	if !reflect.DeepEqual(expectedTxHashes, txHashes) {
+		t.Errorf(
+			"unexpected transaction hashes\nexpected: %v\nactual:   %v",
+			expectedTxHashes,
+			txHashes,
+		)
+	}
+
+	expectedImportedDescriptors := []string{
+		fmt.Sprintf("raw(%x)#checksum", p2pkh),
+	}
+	if !reflect.DeepEqual(expectedImportedDescriptors, importedDescriptors) {
+		t.Errorf(
+			"unexpected imported descriptors\nexpected: %v\nactual:   %v",
+			expectedImportedDescriptors,
+			importedDescriptors,
+		)
+	}
+
+	// Subsequent calls should not import the descriptors again.
+	_, err = connection.GetTxHashesForPublicKeyHash(publicKeyHash)
+	if err != nil {
+		t.Fatal(err)
+	}
+	testutils.AssertIntsEqual(
+		t,
+		"importdescriptors calls count",
+		1,
+		stub.callsCount("importdescriptors"),
+	)
+	testutils.AssertIntsEqual(
+		t,
+		"listdescriptors calls count",
+		1,
+		stub.callsCount("listdescriptors"),
+	)
+}
+
+func TestGetTxHashesForPublicKeyHash_NoWallet(t *testing.T) {
+	_, server := newStubServer(t)
+
+	chain, err := Connect(context.Background(), Config{
+		URL:                 server.URL,
+		Username:            testUsername,
+		Password:            testPassword,
+		RequestTimeout:      1 * time.Second,
+		RequestRetryTimeout: 1 * time.Second,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = chain.GetTxHashesForPublicKeyHash([20]byte{0x01})
+	if err == nil {
+		t.Fatal("expected error")
+	}
+}
+
+func TestGetUtxosForPublicKeyHash(t *testing.T) {
+	stub, server := newStubServer(t)
+
+	publicKeyHash := [20]byte{0x8d, 0xb5, 0x0e, 0xb5, 0x20}
+
+	p2pkh, err := bitcoin.PayToPublicKeyHash(publicKeyHash)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p2wpkh, err := bitcoin.PayToWitnessPublicKeyHash(publicKeyHash)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	stub.handle("scantxoutset", func(params []json.RawMessage) (interface{}, *rpcError) {
+		var action string
+		unmarshalParam(t, params, 0, &action)
+		testutils.AssertStringsEqual(t, "action", "start", action)
+
+		var descriptors []string
+		unmarshalParam(t, params, 1, &descriptors)
+		expectedDescriptors := []string{
+			fmt.Sprintf("raw(%x)", p2pkh),
+			fmt.Sprintf("raw(%x)", p2wpkh),
+		}
+		if !reflect.DeepEqual(expectedDescriptors, descriptors) {
+			t.Errorf(
+				"unexpected descriptors\nexpected: %v\nactual:   %v",
+				expectedDescriptors,
+				descriptors,
+			)
+		}
+
+		return map[string]interface{}{
+			"success": true,
+			"unspents": []map[string]interface{}{
+				{
+					"txid":   "1111111111111111111111111111111111111111111111111111111111111111",
+					"vout":   1,
+					"amount": 0.0001,
+					"height": 300,
+				},
+				{
+					"txid":   "2222222222222222222222222222222222222222222222222222222222222222",
+					"vout":   0,
+					"amount": 1.23456789,
+					"height": 200,
+				},
+				// Spent by a mempool transaction.
+				{
+					"txid":   "3333333333333333333333333333333333333333333333333333333333333333",
+					"vout":   2,
+					"amount": 0.5,
+					"height": 100,
+				},
+			},
+		}, nil
+	})
+	stub.handle("gettxout", func(params []json.RawMessage) (interface{}, *rpcError) {
+		var txID string
+		unmarshalParam(t, params, 0, &txID)
+
+		var includeMempool bool
+		unmarshalParam(t, params, 2, &includeMempool)
+		if !includeMempool {
+			t.Errorf("expected mempool to be included")
+		}
+
+		if txID == "3333333333333333333333333333333333333333333333333333333333333333" {
+			return nil, nil
+		}
+
+		return map[string]interface{}{"confirmations": 1}, nil
+	})
+
+	connection := connectToStub(t, server)
+
+	utxos, err := connection.GetUtxosForPublicKeyHash(publicKeyHash)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expectedUtxos := []*bitcoin.UnspentTransactionOutput{
+		{
+			Outpoint: &bitcoin.TransactionOutpoint{
+				TransactionHash: hashFromString(t, "2222222222222222222222222222222222222222222222222222222222222222"),
+				OutputIndex:     0,
+			},
+			Value: 123456789,
+		},
+		{
+			Outpoint: &bitcoin.TransactionOutpoint{
+				TransactionHash: hashFromString(t, "1111111111111111111111111111111111111111111111111111111111111111"),
+				OutputIndex:     1,
+			},
+			Value: 10000,
+		},
+	}
+	if !reflect.DeepEqual(expectedUtxos, utxos) {
+		t.Errorf(
+			"unexpected UTXOs\nexpected: %v\nactual:   %v",
+			expectedUtxos,
+			utxos,
+		)
+	}
+}
+
+func TestGetMempoolUtxosForPublicKeyHash(t *testing.T) {
+	stub, server := newStubServer(t)
+
+	publicKeyHash := [20]byte{0x8d, 0xb5, 0x0e, 0xb5, 0x20}
+
+	p2wpkh, err := bitcoin.PayToWitnessPublicKeyHash(publicKeyHash)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	stub.handle("getdescriptorinfo", func(params []json.RawMessage) (interface{}, *rpcError) {
+		var descriptor string
+		unmarshalParam(t, params, 0, &descriptor)
+		return map[string]interface{}{
+			"descriptor": descriptor + "#checksum",
+		}, nil
+	})
+	stub.handleWallet("listdescriptors", func([]json.RawMessage) (interface{}, *rpcError) {
+		return map[string]interface{}{"descriptors": []interface{}{}}, nil
+	})
+	stub.handleWallet("importdescriptors", func([]json.RawMessage) (interface{}, *rpcError) {
+		return []map[string]interface{}{{"success": true}}, nil
+	})
+	stub.handleWallet("listunspent", func(params []json.RawMessage) (interface{}, *rpcError) {
+		var minConf, maxConf int
+		unmarshalParam(t, params, 0, &minConf)
+		unmarshalParam(t, params, 1, &maxConf)
+		testutils.AssertIntsEqual(t, "minimum confirmations", 0, minConf)
+		testutils.AssertIntsEqual(t, "maximum confirmations", 0, maxConf)
+
+		return []map[string]interface{}{
+			{
+				"txid":         "1111111111111111111111111111111111111111111111111111111111111111",
+				"vout":         3,
+				"scriptPubKey": hex.EncodeToString(p2wpkh),
+				"amount":       0.00025,
+			},
+			// Output controlled by another script watched by the wallet.
+			{
+				"txid":         "2222222222222222222222222222222222222222222222222222222222222222",
+				"vout":         0,
+				"scriptPubKey": "0014aabbccddeeff00112233445566778899aabbccdd",
+				"amount":       1,
+			},
+		}, nil
+	})
+
+	connection := connectToStub(t, server)
+
+	utxos, err := connection.GetMempoolUtxosForPublicKeyHash(publicKeyHash)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expectedUtxos := []*bitcoin.UnspentTransactionOutput{
+		{
+			Outpoint: &bitcoin.TransactionOutpoint{
+				TransactionHash: hashFromString(t, "1111111111111111111111111111111111111111111111111111111111111111"),
+				OutputIndex:     3,
+			},
+			Value: 25000,
+		},
+	}
+	if !reflect.DeepEqual(expectedUtxos, utxos) {
+		t.Errorf(
+			"unexpected UTXOs\nexpected: %v\nactual:   %v",
+			expectedUtxos,
+			utxos,
+		)
+	}
+}
+
+func TestEstimateSatPerVByteFee(t *testing.T) {
+	var tests = map[string]struct {
+		result                 map[string]interface{}
+		expectedSatPerVByteFee int64
+		expectedErr            bool
+	}{
+		"fee rate available": {
+			result:                 map[string]interface{}{"feerate": 0.0012351, "blocks": 6},
+			expectedSatPerVByteFee: 124,
+		},
+		"fee rate below 1 sat/vbyte": {
+			result:                 map[string]interface{}{"feerate": 0.000001, "blocks": 6},
+			expectedSatPerVByteFee: 1,
+		},
+		"insufficient data": {
+			result: map[string]interface{}{
+				"errors": []string{"Insufficient data or no feerate found"},
+				"blocks": 6,
+			},
+			expectedErr: true,
+		},
+	}
+
+	for testName, test := range tests {
+		t.Run(testName, func(t *testing.T) {
+			stub, server := newStubServer(t)
+
+			stub.handle("estimatesmartfee", func(params []json.RawMessage) (interface{}, *rpcError) {
+				var blocks uint32
+				unmarshalParam(t, params, 0, &blocks)
+				testutils.AssertUintsEqual(t, "blocks", 6, uint64(blocks))
+				return test.result, nil
+			})
+
+			connection := connectToStub(t, server)
+
+			satPerVByteFee, err := connection.EstimateSatPerVByteFee(6)
+			if test.expectedErr {
+				if err == nil {
+					t.Fatal("expected error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			testutils.AssertIntsEqual(
+				t,
+				"sat/vbyte fee",
+				int(test.expectedSatPerVByteFee),
+				int(satPerVByteFee),
+			)
+		})
+	}
+}
+
+func TestGetBlockFeeRates(t *testing.T) {
+	stub, server := newStubServer(t)
+
+	stub.handle("getblockstats", func(params []json.RawMessage) (interface{}, *rpcError) {
+		var blockHeight uint
+		unmarshalParam(t, params, 0, &blockHeight)
+		testutils.AssertUintsEqual(t, "block height", 800000, uint64(blockHeight))
+
+		return map[string]interface{}{
+			"height":              800000,
+			"feerate_percentiles": []int64{12, 15, 20, 31, 52},
+		}, nil
+	})
+
+	connection := connectToStub(t, server)
+
+	feeRates, err := connection.GetBlockFeeRates(800000)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expectedFeeRates := &bitcoin.BlockFeeRates{
+		Height:      800000,
+		Percentiles: [5]int64{12, 15, 20, 31, 52},
+	}
+
+	if !reflect.DeepEqual(expectedFeeRates, feeRates) {
+		t.Errorf(
+			"unexpected block fee rates\nexpected: %v\nactual:   %v",
+			expectedFeeRates,
+			feeRates,
+		)
+	}
+}
+func TestGetCoinbaseTxHash(t *testing.T) {
+	stub, server := newStubServer(t)
+
+	blockHash := "00000000000000000000000000000000000000000000000000000000000000aa"
+	coinbaseTxID := "5555555555555555555555555555555555555555555555555555555555555555"
+
+	stub.handle("getblockhash", func([]json.RawMessage) (interface{}, *rpcError) {
+		return blockHash, nil
+	})
+	stub.handle("getblock", func(params []json.RawMessage) (interface{}, *rpcError) {
+		var requestedHash string
+		unmarshalParam(t, params, 0, &requestedHash)
+		testutils.AssertStringsEqual(t, "block hash", blockHash, requestedHash)
+
+		return map[string]interface{}{
+			"tx": []string{
+				coinbaseTxID,
+				"6666666666666666666666666666666666666666666666666666666666666666",
+			},
+		}, nil
+	})
+
+	connection := connectToStub(t, server)
+
+	txHash, err := connection.GetCoinbaseTxHash(1000)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testutils.AssertStringsEqual(
+		t,
+		"coinbase tx hash",
+		coinbaseTxID,
+		txHash.Hex(bitcoin.ReversedByteOrder),
+	)
+}
+
+// newTestBlock creates a block with the given number of distinct
+// transactions and a valid merkle root.
+func newTestTransaction(
+	outpoints []*bitcoin.TransactionOutpoint,
+	outputScripts [][]byte,
+) *bitcoin.Transaction {
+	transaction := &bitcoin.Transaction{Version: 1}
+
+	for _, outpoint := range outpoints {
+		transaction.Inputs = append(
+			transaction.Inputs,
+			&bitcoin.TransactionInput{
+				Outpoint:        outpoint,
+				SignatureScript: []byte{},
+				Sequence:        0xffffffff,
+			},
+		)
+	}
+
+	for _, outputScript := range outputScripts {
+		transaction.Outputs = append(
+			transaction.Outputs,
+			&bitcoin.TransactionOutput{
+				Value:           10000,
+				PublicKeyScript: outputScript,
+			},
+		)
+	}
+
+	return transaction
+}
+
+func newTestBlock(transactionsCount int) *wire.MsgBlock {
+	block := &wire.MsgBlock{}
+
+	for i := 0; i < transactionsCount; i++ {
+		tx := wire.NewMsgTx(1)
+		tx.AddTxIn(wire.NewTxIn(
+			&wire.OutPoint{Hash: chainhash.Hash{byte(i)}, Index: uint32(i)},
+			nil,
+			nil,
+		))
+		tx.AddTxOut(wire.NewTxOut(int64(i+1)*1000, []byte{0x51}))
+		block.Transactions = append(block.Transactions, tx)
+	}
+
+	var merkleRoot chainhash.Hash
+	copy(merkleRoot[:], computeMerkleRoot(block))
+	block.Header.MerkleRoot = merkleRoot
+
+	return block
+}
+
+// newTestMerkleBlock creates a serialized merkle block proving the inclusion
+// of the transaction at the given position in the given block.
+func newTestMerkleBlock(
+	t *testing.T,
+	block *wire.MsgBlock,
+	position int,
+) string {
+	filter := bloom.NewFilter(1, 0, 0.0000001, wire.BloomUpdateNone)
+	txHash := block.Transactions[position].TxHash()
+	filter.AddHash(&txHash)
+
+	merkleBlock, matched := bloom.NewMerkleBlock(btcutil.NewBlock(block), filter)
+	if len(matched) != 1 || matched[0] != uint32(position) {
+		t.Fatalf("unexpected matched transactions: [%v]", matched)
+	}
+
+	var buffer bytes.Buffer
+	if err := merkleBlock.BtcEncode(
+		&buffer,
+		wire.ProtocolVersion,
+		wire.BaseEncoding,
+	); err != nil {
+		t.Fatal(err)
+	}
+
+	return hex.EncodeToString(buffer.Bytes())
+}
+
+// computeMerkleLevels computes all levels of the merkle tree of the given
+// block, starting from the transaction hashes.
+func computeMerkleLevels(block *wire.MsgBlock) [][]bitcoin.Hash {
+	level := make([]bitcoin.Hash, len(block.Transactions))
+	for i, tx := range block.Transactions {
+		level[i] = bitcoin.Hash(tx.TxHash())
+	}
+
+	levels := [][]bitcoin.Hash{level}
+	for len(level) > 1 {
+		nextLevel := make([]bitcoin.Hash, 0)
+		for i := 0; i < len(level); i += 2 {
+			left := level[i]
+			right := left
+			if i+1 < len(level) {
+				right = level[i+1]
+			}
+			nextLevel = append(
+				nextLevel,
+				bitcoin.ComputeHash(append(left[:], right[:]...)),
+			)
+		}
+		level = nextLevel
+		levels = append(levels, level)
+	}
+
+	return levels
+}
+
+func computeMerkleRoot(block *wire.MsgBlock) []byte {
+	levels := computeMerkleLevels(block)
+	root := levels[len(levels)-1][0]
+	return root[:]
+}
+
+func computeMerkleBranch(block *wire.MsgBlock, position int) []string {
+	levels := computeMerkleLevels(block)
+
+	branch := make([]string, 0)
+	for _, level := range levels[:len(levels)-1] {
+		sibling := position ^ 1
+		if sibling >= len(level) {
+			sibling = position
+		}
+		branch = append(branch, level[sibling].Hex(bitcoin.ReversedByteOrder))
+		position /= 2
+	}
+
+	return branch
+}
@@ -0,0 +1,41 @@
+package bitcoind
+
+import "time"
+
+const (
+	// DefaultRequestTimeout is a default timeout used for a single attempt of
+	// bitcoind JSON-RPC request.
+	DefaultRequestTimeout = 30 * time.Second
+	// DefaultRequestRetryTimeout is a default timeout used for bitcoind
+	// JSON-RPC request retries.
+	DefaultRequestRetryTimeout = 2 * time.Minute
+	// DefaultScanTimeout is a default timeout used for long-running bitcoind
+	// requests scanning the UTXO set or rescanning the wallet.
+	DefaultScanTimeout = 30 * time.Minute
+)
+
+// Config holds configurable properties.
+type Config struct {
+	// URL to the bitcoind JSON-RPC server in format: `scheme://hostname:port`.
+	URL string
+	// Username used to authenticate against the bitcoind JSON-RPC server.
+	Username string
+	// Password used to authenticate against the bitcoind JSON-RPC server.
+	Password string
+	// Wallet is the name of a watch-only descriptor wallet loaded in bitcoind.
+	// The wallet is used to track transaction history and mempool of public
+	// key hashes as bitcoind does not index transactions by script. If empty,
+	// history and mempool queries are not supported.
+	Wallet string
+	// RescanTimestamp is the Unix timestamp from which the wallet rescans the
+	// chain when watching a new public key hash. Transactions older than this
+	// timestamp are not visible in the history. Zero rescans from genesis.
+	RescanTimestamp int64
+	// Timeout for a single attempt of bitcoind JSON-RPC request.
+	RequestTimeout time.Duration
+	// Timeout for bitcoind JSON-RPC request retries.
+	RequestRetryTimeout time.Duration
+	// Timeout for long-running requests scanning the UTXO set or rescanning
+	// the wallet.
+	ScanTimeout time.Duration
+}
@@ -0,0 +1,222 @@
+package bitcoind
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/keep-network/keep-common/pkg/wrappers"
+)
+
+// Error codes returned by bitcoind JSON-RPC server. For reference, see:
+// https://github.com/bitcoin/bitcoin/blob/master/src/rpc/protocol.h
+const (
+	// rpcInvalidAddressOrKeyCode is returned, among others, when the requested
+	// transaction or block was not found.
+	rpcInvalidAddressOrKeyCode = -5
+)
+
+type rpcRequest struct {
+	JSONRPC string        `json:"jsonrpc"`
+	ID      uint64        `json:"id"`
+	Method  string        `json:"method"`
+	Params  []interface{} `json:"params"`
+}
+
+type rpcResponse struct {
+	Result json.RawMessage `json:"result"`
+	Error  *rpcError       `json:"error"`
+	ID     uint64          `json:"id"`
+}
+
+// rpcError represents an error returned by the bitcoind JSON-RPC server.
+type rpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (re *rpcError) Error() string {
+	return fmt.Sprintf("rpc error [code: [%d], message: [%s]]", re.Code, re.Message)
+}
+
+// isNotFoundErr checks whether the given error was returned by the bitcoind
+// JSON-RPC server because the requested item does not exist.
+func isNotFoundErr(err error) bool {
+	var rpcErr *rpcError
+	if !errors.As(err, &rpcErr) {
+		return false
+	}
+
+	return rpcErr.Code == rpcInvalidAddressOrKeyCode
+}
+
+// call executes a single JSON-RPC request against the bitcoind node and
+// unmarshals the result into the provided result pointer. If the walletScoped
+// flag is set, the request is routed to the configured wallet endpoint.
+func (c *Connection) call(
+	ctx context.Context,
+	walletScoped bool,
+	method string,
+	params []interface{},
+	result interface{},
+) error {
+	if params == nil {
+		params = []interface{}{}
+	}
+
+	c.requestIDMutex.Lock()
+	c.requestID++
+	requestID := c.requestID
+	c.requestIDMutex.Unlock()
+
+	requestBody, err := json.Marshal(&rpcRequest{
+		JSONRPC: "1.0",
+		ID:      requestID,
+		Method:  method,
+		Params:  params,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal request: [%w]", err)
+	}
+
+	endpoint := c.config.URL
+	if walletScoped {
+		endpoint = fmt.Sprintf(
+			"%s/wallet/%s",
+			endpoint,
+			url.PathEscape(c.config.Wallet),
+		)
+	}
+
+	request, err := http.NewRequestWithContext(
+		ctx,
+		http.MethodPost,
+		endpoint,
+		bytes.NewReader(requestBody),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create request: [%w]", err)
+	}
+	request.Header.Set("Content-Type", "application/json")
+	if len(c.config.Username) > 0 || len(c.config.Password) > 0 {
+		request.SetBasicAuth(c.config.Username, c.config.Password)
+	}
+
+	response, err := c.httpClient.Do(request)
+	if err != nil {
+		return fmt.Errorf("failed to execute request: [%w]", err)
+	}
+	defer response.Body.Close()
+
+	responseBody, err := io.ReadAll(response.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response: [%w]", err)
+	}
+
+	// bitcoind responds with non-2xx status codes along with a regular
+	// JSON-RPC error body if the request failed. Try to decode the body
+	// first and fall back to the status code if that is not possible.
+	var rpcResult rpcResponse
+	if err := json.Unmarshal(responseBody, &rpcResult); err != nil {
+		if response.StatusCode != http.StatusOK {
+			return fmt.Errorf(
+				"unexpected response status: [%s]",
+				response.Status,
+			)
+		}
+
+		return fmt.Errorf("failed to unmarshal response: [%w]", err)
+	}
+
+	if rpcResult.Error != nil {
+		return rpcResult.Error
+	}
+
+	if result == nil {
+		return nil
+	}
+
+	if err := json.Unmarshal(rpcResult.Result, result); err != nil {
+		return fmt.Errorf("failed to unmarshal result: [%w]", err)
+	}
+
+	return nil
+}
+
+func requestWithRetry[K interface{}](
+	c *Connection,
+	requestFn func(ctx context.Context) (K, error),
+	requestName string,
+) (K, error) {
+	return requestWithTimeouts(
+		c,
+		c.config.RequestTimeout,
+		c.config.RequestRetryTimeout,
+		requestFn,
+		requestName,
+	)
+}
+
+func scanWithRetry[K interface{}](
+	c *Connection,
+	requestFn func(ctx context.Context) (K, error),
+	requestName string,
+) (K, error) {
+	return requestWithTimeouts(
+		c,
+		c.config.ScanTimeout,
+		c.config.ScanTimeout,
+		requestFn,
+		requestName,
+	)
+}
+
+func requestWithTimeouts[K interface{}](
+	c *Connection,
+	requestTimeout time.Duration,
+	requestRetryTimeout time.Duration,
+	requestFn func(ctx context.Context) (K, error),
+	requestName string,
+) (K, error) {
+	startTime := time.Now()
+	logger.Debugf("starting [%s] request to bitcoind", requestName)
+
+	var result K
+
+	err := wrappers.DoWithDefaultRetry(
+		c.parentCtx,
+		requestRetryTimeout,
+		func(ctx context.Context) error {
+			requestCtx, requestCancel := context.WithTimeout(ctx, requestTimeout)
+			defer requestCancel()
+
+			r, err := requestFn(requestCtx)
+			if err != nil {
+				return fmt.Errorf("request failed: [%w]", err)
+			}
+
+			result = r
+			return nil
+		})
+
+	solveRequestOutcome := func(err error) string {
+		if err != nil {
+			return fmt.Sprintf("error: [%v]", err)
+		}
+		return "success"
+	}
+
+	logger.Debugf("[%s] request to bitcoind completed with [%s] after [%s]",
+		requestName,
+		solveRequestOutcome(err),
+		time.Since(startTime),
+	)
+
+	return result, err
+}
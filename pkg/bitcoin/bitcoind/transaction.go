@@ -0,0 +1,258 @@
+package bitcoind
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"math"
+
+	"github.com/btcsuite/btcd/v2/wire"
+
+	"github.com/keep-network/keep-core/pkg/bitcoin"
+)
+
+// convertRawTransaction transforms a transaction provided in the hexadecimal
+// serialized string to the format expected by the bitcoin.Chain interface.
+func convertRawTransaction(rawTx string) (*bitcoin.Transaction, error) {
+	transactionBytes, err := hex.DecodeString(rawTx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode a hex string: [%w]", err)
+	}
+
+	transaction := new(bitcoin.Transaction)
+	if err := transaction.Deserialize(transactionBytes); err != nil {
+		return nil, fmt.Errorf(
+			"failed to deserialize a transaction: [%w]",
+			err,
+		)
+	}
+
+	return transaction, nil
+}
+
+// convertBlockHeader transforms a block header provided in the hexadecimal
+// serialized string to the format expected by the bitcoin.Chain interface.
+func convertBlockHeader(rawHeader string) (*bitcoin.BlockHeader, error) {
+	headerBytes, err := hex.DecodeString(rawHeader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode a hex string: [%w]", err)
+	}
+
+	if len(headerBytes) != bitcoin.BlockHeaderByteLength {
+		return nil, fmt.Errorf(
+			"wrong block header length; expected [%v], got [%v]",
+			bitcoin.BlockHeaderByteLength,
+			len(headerBytes),
+		)
+	}
+
+	var rawBlockHeader [bitcoin.BlockHeaderByteLength]byte
+	copy(rawBlockHeader[:], headerBytes)
+
+	blockHeader := new(bitcoin.BlockHeader)
+	blockHeader.Deserialize(rawBlockHeader)
+
+	return blockHeader, nil
+}
+
+// convertMerkleProof transforms a serialized merkle block returned by the
+// `gettxoutproof` call to the format expected by the bitcoin.Chain interface.
+// The merkle block holds a partial merkle tree as described in BIP-0037:
+// https://github.com/bitcoin/bips/blob/master/bip-0037.mediawiki#partial-merkle-branch-format
+// The tree is traversed in order to find the position of the given transaction
+// and collect the merkle branch leading to it.
+func convertMerkleProof(
+	rawMerkleBlock string,
+	transactionHash bitcoin.Hash,
+	blockHeight uint,
+) (*bitcoin.TransactionMerkleProof, error) {
+	merkleBlockBytes, err := hex.DecodeString(rawMerkleBlock)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode a hex string: [%w]", err)
+	}
+
+	var merkleBlock wire.MsgMerkleBlock
+	if err := merkleBlock.BtcDecode(
+		bytes.NewReader(merkleBlockBytes),
+		wire.ProtocolVersion,
+		wire.BaseEncoding,
+	); err != nil {
+		return nil, fmt.Errorf(
+			"failed to deserialize a merkle block: [%w]",
+			err,
+		)
+	}
+
+	tree := &partialMerkleTree{
+		transactionsCount: merkleBlock.Transactions,
+		flags:             merkleBlock.Flags,
+		nodes:             make(map[merkleNodeKey]bitcoin.Hash),
+		matchedLeaf:       -1,
+	}
+	for _, hash := range merkleBlock.Hashes {
+		tree.hashes = append(tree.hashes, bitcoin.Hash(*hash))
+	}
+
+	if merkleBlock.Transactions == 0 {
+		return nil, fmt.Errorf("merkle block does not contain transactions")
+	}
+
+	root, err := tree.traverse(tree.height(), 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to traverse partial merkle tree: [%w]", err)
+	}
+
+	if root != bitcoin.Hash(merkleBlock.Header.MerkleRoot) {
+		return nil, fmt.Errorf(
+			"computed merkle root [%s] does not match the block header one [%s]",
+			root.Hex(bitcoin.ReversedByteOrder),
+			bitcoin.Hash(merkleBlock.Header.MerkleRoot).Hex(bitcoin.ReversedByteOrder),
+		)
+	}
+
+	if tree.matchedLeaf < 0 || tree.matchedHash != transactionHash {
+		return nil, fmt.Errorf(
+			"merkle block does not match transaction [%s]",
+			transactionHash.Hex(bitcoin.ReversedByteOrder),
+		)
+	}
+
+	merkleNodes := make([]string, 0)
+	position := uint32(tree.matchedLeaf)
+	for height := uint32(0); height < tree.height(); height++ {
+		siblingPosition := position ^ 1
+		if siblingPosition >= tree.width(height) {
+			// The last node on the given level is paired with itself if
+			// the level has an odd number of nodes.
+			siblingPosition = position
+		}
+
+		sibling, ok := tree.nodes[merkleNodeKey{height, siblingPosition}]
+		if !ok {
+			return nil, fmt.Errorf(
+				"missing merkle node at height [%v] and position [%v]",
+				height,
+				siblingPosition,
+			)
+		}
+
+		merkleNodes = append(
+			merkleNodes,
+			sibling.Hex(bitcoin.ReversedByteOrder),
+		)
+
+		position = position / 2
+	}
+
+	return &bitcoin.TransactionMerkleProof{
+		BlockHeight: blockHeight,
+		MerkleNodes: merkleNodes,
+		Position:    uint(tree.matchedLeaf),
+	}, nil
+}
+
+type merkleNodeKey struct {
+	height   uint32
+	position uint32
+}
+
+// partialMerkleTree is a helper structure used to traverse a BIP-0037
+// partial merkle tree.
+type partialMerkleTree struct {
+	transactionsCount uint32
+	hashes            []bitcoin.Hash
+	flags             []byte
+
+	usedHashes uint32
+	usedFlags  uint32
+
+	nodes       map[merkleNodeKey]bitcoin.Hash
+	matchedLeaf int64
+	matchedHash bitcoin.Hash
+}
+
+// width returns the number of nodes at the given height of the tree.
+func (pmt *partialMerkleTree) width(height uint32) uint32 {
+	return (pmt.transactionsCount + (1 << height) - 1) >> height
+}
+
+// height returns the height of the tree root.
+func (pmt *partialMerkleTree) height() uint32 {
+	height := uint32(0)
+	for pmt.width(height) > 1 {
+		height++
+	}
+	return height
+}
+
+// traverse walks the tree depth-first, starting from the node at the given
+// height and position, and returns the hash of that node. All visited node
+// hashes are recorded in the nodes map.
+func (pmt *partialMerkleTree) traverse(
+	height uint32,
+	position uint32,
+) (bitcoin.Hash, error) {
+	if pmt.usedFlags >= uint32(len(pmt.flags))*8 {
+		return bitcoin.Hash{}, fmt.Errorf("ran out of flag bits")
+	}
+
+	flag := pmt.flags[pmt.usedFlags/8]&(1<<(pmt.usedFlags%8)) != 0
+	pmt.usedFlags++
+
+	var hash bitcoin.Hash
+
+	if height == 0 || !flag {
+		if pmt.usedHashes >= uint32(len(pmt.hashes)) {
+			return bitcoin.Hash{}, fmt.Errorf("ran out of hashes")
+		}
+
+		hash = pmt.hashes[pmt.usedHashes]
+		pmt.usedHashes++
+
+		if height == 0 && flag {
+			if pmt.matchedLeaf >= 0 {
+				return bitcoin.Hash{}, fmt.Errorf(
+					"more than one transaction matched",
+				)
+			}
+
+			pmt.matchedLeaf = int64(position)
+			pmt.matchedHash = hash
+		}
+	} else {
+		left, err := pmt.traverse(height-1, position*2)
+		if err != nil {
+			return bitcoin.Hash{}, err
+		}
+
+		right := left
+		if position*2+1 < pmt.width(height-1) {
+			right, err = pmt.traverse(height-1, position*2+1)
+			if err != nil {
+				return bitcoin.Hash{}, err
+			}
+		}
+
+		hash = bitcoin.ComputeHash(append(left[:], right[:]...))
+	}
+
+	pmt.nodes[merkleNodeKey{height, position}] = hash
+
+	return hash, nil
+}
+
+// convertBtcKbToSatVByte converts the BTC/kvB fee rate returned by bitcoind
+// to the sat/vbyte fee rate.
+func convertBtcKbToSatVByte(btcPerKbFee float64) int64 {
+	// To convert from BTC/KB to sat/vbyte, we need to multiply by 1e8/1e3.
+	satPerVByte := (1e8 / 1e3) * btcPerKbFee
+	// Make sure the minimum returned sat/vbyte fee is always 1.
+	satPerVByte = math.Max(satPerVByte, 1)
+	// Round the returned fee to be an integer.
+	return int64(math.Round(satPerVByte))
+}
+
+// convertBtcToSat converts the BTC amount returned by bitcoind to satoshis.
+func convertBtcToSat(btcAmount float64) int64 {
+	return int64(math.Round(btcAmount * 1e8))
+}
@@ -1,5 +1,7 @@
 package bitcoin
 
+import "github.com/keep-network/keep-core/pkg/subscription"
+
 // Chain defines an interface meant to be used for interaction with the
 // Bitcoin chain.
 type Chain interface {
@@ -96,3 +98,90 @@ type Chain interface {
 	// block height.
 	GetCoinbaseTxHash(blockHeight uint) (Hash, error)
 }
+
+// ChainNotifier defines an interface meant to be used for receiving push
+// notifications about changes happening on the Bitcoin chain. It lets
+// consumers react to new blocks and script activity immediately instead of
+// polling the Chain on their own timers.
+type ChainNotifier interface {
+	// OnNewBlockHeader registers a callback that is invoked when a new block
+	// header becomes the tip of the Bitcoin chain.
+	OnNewBlockHeader(
+		handler func(event *NewBlockHeaderEvent),
+	) subscription.EventSubscription
+
+	// OnScriptStatusChanged registers a callback that is invoked when the
+	// status of the given script changes, i.e. when a transaction paying to
+	// or spending from the script enters the mempool or gets confirmed.
+	// The first status seen after the script starts being watched is
+	// reported as well.
+	OnScriptStatusChanged(
+		script Script,
+		handler func(event *ScriptStatusChangedEvent),
+	) subscription.EventSubscription
+}
+
+// NewBlockHeaderEvent represents a new block header that became the tip
+// of the Bitcoin chain.
+type NewBlockHeaderEvent struct {
+	Height uint
+	Header *BlockHeader
+}
+
+// ScriptStatusChangedEvent represents a change of the given script's status.
+// The status is an opaque value that changes each time the script's
+// transaction history, including the mempool, changes. An empty status
+// means the script has no history.
+type ScriptStatusChangedEvent struct {
+	Script Script
+	Status string
+}
+
+// FeeRateEstimator defines an interface meant to be used for estimating
+// the fee rate a transaction should pay to be confirmed in time. The Chain
+// satisfies this interface by returning the estimate of its backend node.
+type FeeRateEstimator interface {
+	// EstimateSatPerVByteFee returns the estimated sat/vbyte fee for a
+	// transaction to be confirmed within the given number of blocks.
+	EstimateSatPerVByteFee(blocks uint32) (int64, error)
+}
+
+// FeeHistogramSource defines an interface meant to be implemented by
+// Chain backends able to describe fee rates paid by transactions waiting
+// in the mempool.
+type FeeHistogramSource interface {
+	// GetMempoolFeeHistogram returns the histogram of fee rates paid by
+	// mempool transactions, weighted by their virtual size. The returned
+	// bins are ordered by fee rate in the descending order.
+	GetMempoolFeeHistogram() ([]*FeeHistogramBin, error)
+}
+
+// FeeHistogramBin represents a single bin of the mempool fee histogram.
+// It holds the total virtual size of mempool transactions paying a fee rate
+// greater than or equal to the bin's fee rate and lower than the fee rate
+// of the previous bin.
+type FeeHistogramBin struct {
+	SatPerVByteFee float64
+	VirtualSize    int64
+}
+
+// BlockFeeRatesSource defines an interface meant to be implemented by Chain
+// backends able to describe fee rates paid by transactions included in
+// mined blocks.
+type BlockFeeRatesSource interface {
+	// GetBlockFeeRates returns fee rates paid by transactions included in
+	// the block with the given height.
+	GetBlockFeeRates(blockHeight uint) (*BlockFeeRates, error)
+}
+
+// BlockFeeRatePercentiles holds percentiles of the block's weight at which
+// fee rates are reported in BlockFeeRates.
+var BlockFeeRatePercentiles = [5]int{10, 25, 50, 75, 90}
+
+// BlockFeeRates holds sat/vbyte fee rates paid by transactions included
+// in a block, at the percentiles of the block's weight determined by
+// BlockFeeRatePercentiles.
+type BlockFeeRates struct {
+	Height      uint
+	Percentiles [5]int64
+}
@@ -0,0 +1,141 @@
+package bitcoin
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+const (
+	// descriptorInputCharset is the set of characters allowed in output
+	// descriptors, ordered as defined by BIP-380. The position of a character
+	// determines the symbols it is expanded to when computing the checksum.
+	descriptorInputCharset = "0123456789()[],'/*abcdefgh@:$%{}" +
+		"IJKLMNOPQRSTUVWXYZ&+-.;<=>?!^_|~" +
+		"ijklmnopqrstuvwxyzABCDEFGH`#\"\\ "
+	// descriptorChecksumCharset is the set of characters used to encode
+	// the descriptor checksum.
+	descriptorChecksumCharset = "qpzry9x8gf2tvdw0s3jn54khce6mua7l"
+	// descriptorChecksumLength is the number of characters of the descriptor
+	// checksum.
+	descriptorChecksumLength = 8
+)
+
+// descriptorChecksumGenerator holds the generator of the BCH code used to
+// compute descriptor checksums, as defined by BIP-380.
+var descriptorChecksumGenerator = [5]uint64{
+	0xf5dee51989,
+	0xa9fdca3312,
+	0x1bab10e32d,
+	0x3706b1677a,
+	0x644d626ffd,
+}
+
+// DescriptorChecksum computes the BIP-380 checksum of the given output
+// descriptor. The descriptor must not contain a checksum already.
+func DescriptorChecksum(descriptor string) (string, error) {
+	checksum := uint64(1)
+	groups := make([]uint64, 0, 3)
+
+	for i, character := range descriptor {
+		position := strings.IndexRune(descriptorInputCharset, character)
+		if position < 0 {
+			return "", fmt.Errorf(
+				"invalid descriptor character [%q] at position [%v]",
+				character,
+				i,
+			)
+		}
+
+		checksum = descriptorChecksumPolymod(checksum, uint64(position&31))
+
+		groups = append(groups, uint64(position>>5))
+		if len(groups) == 3 {
+			checksum = descriptorChecksumPolymod(
+				checksum,
+				groups[0]*9+groups[1]*3+groups[2],
+			)
+			groups = groups[:0]
+		}
+	}
+
+	switch len(groups) {
+	case 1:
+		checksum = descriptorChecksumPolymod(checksum, groups[0])
+	case 2:
+		checksum = descriptorChecksumPolymod(checksum, groups[0]*3+groups[1])
+	}
+
+	for i := 0; i < descriptorChecksumLength; i++ {
+		checksum = descriptorChecksumPolymod(checksum, 0)
+	}
+
+	checksum ^= 1
+
+	result := make([]byte, descriptorChecksumLength)
+	for i := range result {
+		shift := 5 * (descriptorChecksumLength - 1 - i)
+		result[i] = descriptorChecksumCharset[(checksum>>shift)&31]
+	}
+
+	return string(result), nil
+}
+
+// descriptorChecksumPolymod feeds the given symbol to the checksum
+// computation and returns the updated checksum.
+func descriptorChecksumPolymod(checksum uint64, symbol uint64) uint64 {
+	top := checksum >> 35
+	checksum = (checksum&0x7ffffffff)<<5 ^ symbol
+
+	for i, generator := range descriptorChecksumGenerator {
+		if (top>>i)&1 == 1 {
+			checksum ^= generator
+		}
+	}
+
+	return checksum
+}
+
+// PayToPublicKeyHashDescriptor constructs the `pkh(<pubkey>)` output
+// descriptor, with the checksum appended, describing the P2PKH output
+// script of the given public key. The public key is serialized in the
+// compressed form.
+func PayToPublicKeyHashDescriptor(publicKey *ecdsa.PublicKey) (string, error) {
+	return publicKeyDescriptor("pkh", publicKey)
+}
+
+// PayToWitnessPublicKeyHashDescriptor constructs the `wpkh(<pubkey>)` output
+// descriptor, with the checksum appended, describing the P2WPKH output
+// script of the given public key. The public key is serialized in the
+// compressed form.
+func PayToWitnessPublicKeyHashDescriptor(
+	publicKey *ecdsa.PublicKey,
+) (string, error) {
+	return publicKeyDescriptor("wpkh", publicKey)
+}
+
+func publicKeyDescriptor(
+	function string,
+	publicKey *ecdsa.PublicKey,
+) (string, error) {
+	publicKeyBytes := elliptic.MarshalCompressed(
+		publicKey.Curve,
+		publicKey.X,
+		publicKey.Y,
+	)
+
+	descriptor := fmt.Sprintf(
+		"%s(%s)",
+		function,
+		hex.EncodeToString(publicKeyBytes),
+	)
+
+	checksum, err := DescriptorChecksum(descriptor)
+	if err != nil {
+		return "", fmt.Errorf("cannot compute descriptor checksum: [%v]", err)
+	}
+
+	return descriptor + "#" + checksum, nil
+}
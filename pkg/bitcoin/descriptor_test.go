@@ -0,0 +1,113 @@
+package bitcoin
+
+import (
+	"crypto/ecdsa"
+	"encoding/hex"
+	"testing"
+
+	"github.com/btcsuite/btcd/btcec"
+
+	"github.com/keep-network/keep-core/internal/testutils"
+)
+
+func TestDescriptorChecksum(t *testing.T) {
+	var tests = map[string]struct {
+		descriptor       string
+		expectedChecksum string
+		expectedErr      string
+	}{
+		// Test vector comes from BIP-380.
+		"raw descriptor": {
+			descriptor:       "raw(deadbeef)",
+			expectedChecksum: "89f8spxm",
+		},
+		"nested descriptor": {
+			descriptor:       "sh(wpkh(03a34b99f22c790c4e36b2b3c2c35a36db06226e41c692fc82b8b56ac1c540c5bd))",
+			expectedChecksum: "0aua3a8r",
+		},
+		"descriptor with key origin and derivation path": {
+			descriptor:       "wpkh([d34db33f/84h/0h/0h]xpub6DJ2dNUysrn5Vt36jH2KLBT2i1auw1tTSSomg8PhqNiUtx8QX2SvC9nrHu81fT41fvDUnhMjEzQgXnQjKEu3oaqMSzhSrHMxyyoEAmUHQbY/0/*)",
+			expectedChecksum: "cjjspncu",
+		},
+		"invalid character": {
+			descriptor:  "raw(deadbeef)\n",
+			expectedErr: "invalid descriptor character ['\\n'] at position [13]",
+		},
+	}
+
+	for testName, test := range tests {
+		t.Run(testName, func(t *testing.T) {
+			checksum, err := DescriptorChecksum(test.descriptor)
+
+			if test.expectedErr != "" {
+				if err == nil {
+					t.Fatal("expected error")
+				}
+
+				testutils.AssertStringsEqual(
+					t,
+					"error",
+					test.expectedErr,
+					err.Error(),
+				)
+				return
+			}
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			testutils.AssertStringsEqual(
+				t,
+				"checksum",
+				test.expectedChecksum,
+				checksum,
+			)
+		})
+	}
+}
+
+func TestPublicKeyHashDescriptors(t *testing.T) {
+	publicKey := descriptorTestPublicKey(
+		t,
+		"02c6047f9441ed7d6d3045406e95c07cd85c778e4b8cef3ca7abac09b95c709ee5",
+	)
+
+	p2pkhDescriptor, err := PayToPublicKeyHashDescriptor(publicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testutils.AssertStringsEqual(
+		t,
+		"P2PKH descriptor",
+		"pkh(02c6047f9441ed7d6d3045406e95c07cd85c778e4b8cef3ca7abac09b95c709ee5)#8fhd9pwu",
+		p2pkhDescriptor,
+	)
+
+	p2wpkhDescriptor, err := PayToWitnessPublicKeyHashDescriptor(publicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testutils.AssertStringsEqual(
+		t,
+		"P2WPKH descriptor",
+		"wpkh(02c6047f9441ed7d6d3045406e95c07cd85c778e4b8cef3ca7abac09b95c709ee5)#wg9vgf99",
+		p2wpkhDescriptor,
+	)
+}
+
+func descriptorTestPublicKey(t *testing.T, publicKeyHex string) *ecdsa.PublicKey {
+	publicKeyBytes, err := hex.DecodeString(publicKeyHex)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	publicKey, err := btcec.ParsePubKey(publicKeyBytes, btcec.S256())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return publicKey.ToECDSA()
+}
@@ -13,7 +13,13 @@ import (
 // convertBlockHeader transforms a BlockHeader returned from Electrum protocol to
 // the format expected by the bitcoin.Chain interface.
 func convertBlockHeader(electrumResult *electrum.GetBlockHeaderResult) (*bitcoin.BlockHeader, error) {
-	headerBytes, err := hex.DecodeString(electrumResult.Header)
+	return decodeBlockHeader(electrumResult.Header)
+}
+
+// decodeBlockHeader decodes a hex-encoded serialized block header returned
+// from Electrum protocol.
+func decodeBlockHeader(headerHex string) (*bitcoin.BlockHeader, error) {
+	headerBytes, err := hex.DecodeString(headerHex)
 	if err != nil {
 		return nil, err
 	}
@@ -32,6 +32,12 @@ type Connection struct {
 	client      *electrum.Client
 	clientMutex *sync.Mutex
 	config      Config
+
+	notifications     *notifications
+	notificationsOnce sync.Once
+	// reconnected is signalled each time the connection to the Electrum
+	// server is re-established so notifications can be resubscribed.
+	reconnected chan struct{}
 }
 
 // Connect initializes handle with provided Config.
@@ -53,9 +59,11 @@ func Connect(parentCtx context.Context, config Config) (bitcoin.Chain, error) {
 	}
 
 	c := &Connection{
-		parentCtx:   parentCtx,
-		config:      config,
-		clientMutex: &sync.Mutex{},
+		parentCtx:     parentCtx,
+		config:        config,
+		clientMutex:   &sync.Mutex{},
+		notifications: newNotifications(),
+		reconnected:   make(chan struct{}, 1),
 	}
 
 	if err := c.electrumConnect(); err != nil {
@@ -994,6 +1002,46 @@ func (c *Connection) EstimateSatPerVByteFee(blocks uint32) (int64, error) {
 	return convertBtcKbToSatVByte(btcPerKbFee), nil
 }
 
+// GetMempoolFeeHistogram returns the histogram of fee rates paid by mempool
+// transactions, weighted by their virtual size. The returned bins are
+// ordered by fee rate in the descending order.
+func (c *Connection) GetMempoolFeeHistogram() ([]*bitcoin.FeeHistogramBin, error) {
+	histogram, err := requestWithRetry(
+		c,
+		func(
+			ctx context.Context,
+			client *electrum.Client,
+		) (map[uint32]uint64, error) {
+			return client.GetFeeHistogram(ctx)
+		},
+		"GetFeeHistogram",
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get fee histogram: [%v]", err)
+	}
+
+	return convertFeeHistogram(histogram), nil
+}
+
+// convertFeeHistogram converts the fee histogram returned by the Electrum
+// server, i.e. a map of sat/vbyte fee rates to virtual sizes, to the list
+// of histogram bins ordered by fee rate in the descending order.
+func convertFeeHistogram(histogram map[uint32]uint64) []*bitcoin.FeeHistogramBin {
+	bins := make([]*bitcoin.FeeHistogramBin, 0, len(histogram))
+	for feeRate, virtualSize := range histogram {
+		bins = append(bins, &bitcoin.FeeHistogramBin{
+			SatPerVByteFee: float64(feeRate),
+			VirtualSize:    int64(virtualSize),
+		})
+	}
+
+	sort.Slice(bins, func(i, j int) bool {
+		return bins[i].SatPerVByteFee > bins[j].SatPerVByteFee
+	})
+
+	return bins
+}
+
 func convertBtcKbToSatVByte(btcPerKbFee float32) int64 {
 	// To convert from BTC/KB to sat/vbyte, we need to multiply by 1e8/1e3.
 	satPerVByte := (1e8 / 1e3) * float64(btcPerKbFee)
@@ -1181,6 +1229,12 @@ func (c *Connection) reconnectIfShutdown() error {
 			return fmt.Errorf("failed to reconnect to electrum server: [%w]", err)
 		}
 		logger.Info("reconnected to electrum server")
+
+		select {
+		case c.reconnected <- struct{}{}:
+		default:
+			// Resubscription is already pending.
+		}
 	}
 
 	return nil
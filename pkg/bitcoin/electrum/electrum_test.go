@@ -1,9 +1,11 @@
 package electrum
 
 import (
+	"reflect"
 	"testing"
 
 	"github.com/keep-network/keep-core/internal/testutils"
+	"github.com/keep-network/keep-core/pkg/bitcoin"
 )
 
 func TestConvertBtcKbToSatVByte(t *testing.T) {
@@ -62,3 +64,29 @@ func TestConvertBtcKbToSatVByte(t *testing.T) {
 		})
 	}
 }
+
+func TestConvertFeeHistogram(t *testing.T) {
+	histogram := map[uint32]uint64{
+		1:  22890421,
+		12: 128812,
+		4:  92524,
+		2:  6478638,
+	}
+
+	expectedBins := []*bitcoin.FeeHistogramBin{
+		{SatPerVByteFee: 12, VirtualSize: 128812},
+		{SatPerVByteFee: 4, VirtualSize: 92524},
+		{SatPerVByteFee: 2, VirtualSize: 6478638},
+		{SatPerVByteFee: 1, VirtualSize: 22890421},
+	}
+
+	bins := convertFeeHistogram(histogram)
+
+	if !reflect.DeepEqual(expectedBins, bins) {
+		t.Errorf(
+			"unexpected histogram bins\nexpected: %v\nactual:   %v\n",
+			expectedBins,
+			bins,
+		)
+	}
+}
@@ -0,0 +1,359 @@
+package electrum
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/checksum0/go-electrum/electrum"
+
+	"github.com/keep-network/keep-core/pkg/bitcoin"
+	"github.com/keep-network/keep-core/pkg/internal/byteutils"
+	"github.com/keep-network/keep-core/pkg/subscription"
+)
+
+// notifications holds the state of push notifications received from the
+// Electrum server using the `blockchain.headers.subscribe` and
+// `blockchain.scripthash.subscribe` methods.
+type notifications struct {
+	mutex sync.Mutex
+
+	nextHandlerID uint64
+
+	headerHandlers map[uint64]func(event *bitcoin.NewBlockHeaderEvent)
+	lastHeader     string
+
+	// Script-related state is keyed by the Electrum script hash.
+	scriptHandlers map[string]map[uint64]func(event *bitcoin.ScriptStatusChangedEvent)
+	scripts        map[string]bitcoin.Script
+	scriptStatuses map[string]string
+
+	// client and scripthashSubscription are set once notifications are
+	// subscribed on the given client and replaced upon each resubscription.
+	client                 *electrum.Client
+	scripthashSubscription *electrum.ScripthashSubscription
+}
+
+func newNotifications() *notifications {
+	return &notifications{
+		headerHandlers: make(map[uint64]func(event *bitcoin.NewBlockHeaderEvent)),
+		scriptHandlers: make(map[string]map[uint64]func(event *bitcoin.ScriptStatusChangedEvent)),
+		scripts:        make(map[string]bitcoin.Script),
+		scriptStatuses: make(map[string]string),
+	}
+}
+
+// OnNewBlockHeader registers a callback that is invoked when a new block
+// header becomes the tip of the Bitcoin chain.
+func (c *Connection) OnNewBlockHeader(
+	handler func(event *bitcoin.NewBlockHeaderEvent),
+) subscription.EventSubscription {
+	c.notificationsOnce.Do(func() { go c.watchNotifications() })
+
+	n := c.notifications
+
+	n.mutex.Lock()
+	defer n.mutex.Unlock()
+
+	handlerID := n.nextHandlerID
+	n.nextHandlerID++
+	n.headerHandlers[handlerID] = handler
+
+	return subscription.NewEventSubscription(func() {
+		n.mutex.Lock()
+		defer n.mutex.Unlock()
+
+		delete(n.headerHandlers, handlerID)
+	})
+}
+
+// OnScriptStatusChanged registers a callback that is invoked when the
+// status of the given script changes. The first status seen after the
+// script starts being watched is reported as well.
+func (c *Connection) OnScriptStatusChanged(
+	script bitcoin.Script,
+	handler func(event *bitcoin.ScriptStatusChangedEvent),
+) subscription.EventSubscription {
+	c.notificationsOnce.Do(func() { go c.watchNotifications() })
+
+	n := c.notifications
+	scriptHash := computeScriptHash(script)
+
+	n.mutex.Lock()
+	handlerID := n.nextHandlerID
+	n.nextHandlerID++
+
+	handlers, watched := n.scriptHandlers[scriptHash]
+	if !watched {
+		handlers = make(map[uint64]func(event *bitcoin.ScriptStatusChangedEvent))
+		n.scriptHandlers[scriptHash] = handlers
+		n.scripts[scriptHash] = script
+	}
+	handlers[handlerID] = handler
+
+	scripthashSubscription, client := n.scripthashSubscription, n.client
+	n.mutex.Unlock()
+
+	// If notifications are not subscribed yet, the script hash will be
+	// subscribed along with all other watched script hashes once they are.
+	if !watched && scripthashSubscription != nil {
+		go func() {
+			err := c.subscribeScriptHash(
+				scripthashSubscription,
+				client,
+				scriptHash,
+			)
+			if err != nil {
+				logger.Errorf(
+					"failed to subscribe for status of script [0x%x]: [%v]",
+					script,
+					err,
+				)
+			}
+		}()
+	}
+
+	return subscription.NewEventSubscription(func() {
+		n.mutex.Lock()
+		defer n.mutex.Unlock()
+
+		delete(n.scriptHandlers[scriptHash], handlerID)
+
+		// Electrum protocol 1.4 does not support unsubscribing script hashes
+		// so we just stop tracking the script. Notifications for it are
+		// ignored from now on.
+		if len(n.scriptHandlers[scriptHash]) == 0 {
+			delete(n.scriptHandlers, scriptHash)
+			delete(n.scripts, scriptHash)
+			delete(n.scriptStatuses, scriptHash)
+		}
+	})
+}
+
+// watchNotifications subscribes for push notifications from the Electrum
+// server and resubscribes each time the connection is re-established by
+// reconnectIfShutdown. It runs until the parent context is done.
+func (c *Connection) watchNotifications() {
+	for {
+		stopForwarding := make(chan struct{})
+
+		var retry <-chan time.Time
+		if err := c.subscribeNotifications(stopForwarding); err != nil {
+			logger.Errorf(
+				"failed to subscribe for electrum server notifications; "+
+					"retrying in [%v]: [%v]",
+				c.config.KeepAliveInterval,
+				err,
+			)
+			retry = time.After(c.config.KeepAliveInterval)
+		}
+
+		select {
+		case <-c.reconnected:
+			logger.Info("resubscribing for electrum server notifications")
+		case <-retry:
+		case <-c.parentCtx.Done():
+			close(stopForwarding)
+			return
+		}
+
+		close(stopForwarding)
+	}
+}
+
+// subscribeNotifications subscribes for new block headers and statuses of
+// all watched scripts on the current client. Received notifications are
+// forwarded to the registered handlers until the stopForwarding channel
+// is closed.
+func (c *Connection) subscribeNotifications(
+	stopForwarding chan struct{},
+) error {
+	type subscribed struct {
+		client                 *electrum.Client
+		headers                <-chan *electrum.SubscribeHeadersResult
+		scripthashSubscription *electrum.ScripthashSubscription
+		statuses               <-chan *electrum.SubscribeNotif
+	}
+
+	result, err := requestWithRetry(
+		c,
+		func(ctx context.Context, client *electrum.Client) (*subscribed, error) {
+			headers, err := client.SubscribeHeaders(ctx)
+			if err != nil {
+				return nil, err
+			}
+
+			scripthashSubscription, statuses := client.SubscribeScripthash()
+
+			return &subscribed{
+				client:                 client,
+				headers:                headers,
+				scripthashSubscription: scripthashSubscription,
+				statuses:               statuses,
+			}, nil
+		},
+		"SubscribeHeaders",
+	)
+	if err != nil {
+		return fmt.Errorf("failed to subscribe for headers: [%w]", err)
+	}
+
+	// The Electrum client pushes notifications to channels with a limited
+	// buffer and blocks until they are read so the forwarding must start
+	// before any script hash is subscribed.
+	go c.forwardNotifications(result.headers, result.statuses, stopForwarding)
+
+	n := c.notifications
+
+	n.mutex.Lock()
+	n.client = result.client
+	n.scripthashSubscription = result.scripthashSubscription
+	scriptHashes := make([]string, 0, len(n.scripts))
+	for scriptHash := range n.scripts {
+		scriptHashes = append(scriptHashes, scriptHash)
+	}
+	n.mutex.Unlock()
+
+	for _, scriptHash := range scriptHashes {
+		err := c.subscribeScriptHash(
+			result.scripthashSubscription,
+			result.client,
+			scriptHash,
+		)
+		if err != nil {
+			logger.Errorf(
+				"failed to resubscribe for status of script hash [%s]: [%v]",
+				scriptHash,
+				err,
+			)
+		}
+	}
+
+	return nil
+}
+
+// subscribeScriptHash subscribes for status of the given script hash using
+// the scripthash subscription established on the given client.
+func (c *Connection) subscribeScriptHash(
+	scripthashSubscription *electrum.ScripthashSubscription,
+	subscriptionClient *electrum.Client,
+	scriptHash string,
+) error {
+	_, err := requestWithRetry(
+		c,
+		func(ctx context.Context, client *electrum.Client) (interface{}, error) {
+			if client != subscriptionClient {
+				// The client was replaced by a reconnect. The script hash
+				// will be subscribed on the new client upon resubscription.
+				return nil, nil
+			}
+
+			return nil, scripthashSubscription.Add(ctx, scriptHash)
+		},
+		"SubscribeScripthash",
+	)
+
+	return err
+}
+
+// forwardNotifications forwards notifications received from the Electrum
+// client to the registered handlers until the stop channel is closed.
+func (c *Connection) forwardNotifications(
+	headers <-chan *electrum.SubscribeHeadersResult,
+	statuses <-chan *electrum.SubscribeNotif,
+	stop <-chan struct{},
+) {
+	for {
+		select {
+		case header := <-headers:
+			if header == nil {
+				continue
+			}
+
+			if err := c.notifications.notifyBlockHeader(
+				header.Height,
+				header.Hex,
+			); err != nil {
+				logger.Errorf("failed to process block header notification: [%v]", err)
+			}
+		case status := <-statuses:
+			if status == nil {
+				continue
+			}
+
+			c.notifications.notifyScriptStatus(status.Params[0], status.Params[1])
+		case <-stop:
+			return
+		}
+	}
+}
+
+// notifyBlockHeader passes the given block header to all registered
+// handlers unless it is the same header that was seen most recently, which
+// is the case right after resubscription.
+func (n *notifications) notifyBlockHeader(height int32, headerHex string) error {
+	n.mutex.Lock()
+	defer n.mutex.Unlock()
+
+	if headerHex == n.lastHeader {
+		return nil
+	}
+
+	header, err := decodeBlockHeader(headerHex)
+	if err != nil {
+		return fmt.Errorf("failed to decode block header: [%w]", err)
+	}
+
+	n.lastHeader = headerHex
+
+	event := &bitcoin.NewBlockHeaderEvent{
+		Height: uint(height),
+		Header: header,
+	}
+
+	for _, handler := range n.headerHandlers {
+		go handler(event)
+	}
+
+	return nil
+}
+
+// notifyScriptStatus passes the given script status to all handlers
+// registered for the script with the given hash if the status differs from
+// the one seen most recently. Statuses of scripts that are no longer
+// watched are ignored.
+func (n *notifications) notifyScriptStatus(scriptHash string, status string) {
+	n.mutex.Lock()
+	defer n.mutex.Unlock()
+
+	script, watched := n.scripts[scriptHash]
+	if !watched {
+		return
+	}
+
+	if lastStatus, ok := n.scriptStatuses[scriptHash]; ok && lastStatus == status {
+		return
+	}
+
+	n.scriptStatuses[scriptHash] = status
+
+	event := &bitcoin.ScriptStatusChangedEvent{
+		Script: script,
+		Status: status,
+	}
+
+	for _, handler := range n.scriptHandlers[scriptHash] {
+		go handler(event)
+	}
+}
+
+// computeScriptHash computes the script hash used by the Electrum protocol
+// to identify scripts, i.e. the SHA-256 hash of the script in the reversed
+// byte order.
+func computeScriptHash(script []byte) string {
+	scriptHash := sha256.Sum256(script)
+	return hex.EncodeToString(byteutils.Reverse(scriptHash[:]))
+}
@@ -0,0 +1,127 @@
+package electrum
+
+import (
+	"testing"
+	"time"
+
+	"github.com/keep-network/keep-core/internal/testutils"
+	"github.com/keep-network/keep-core/pkg/bitcoin"
+)
+
+const (
+	testHeaderHex = "04000020a5a3501e6ba1f3e2a1ee5d29327a549524ed33f272dfef30004566000000" +
+		"0000e27d241ca36de831ab17e6729056c14a383e7a3f43d56254f846b4964977" +
+		"5112939edd612ac0001abbaa602e"
+	testEventTimeout = time.Second
+)
+
+func TestNotifications_NotifyBlockHeader(t *testing.T) {
+	n := newNotifications()
+
+	events := make(chan *bitcoin.NewBlockHeaderEvent, 10)
+	n.headerHandlers[0] = func(event *bitcoin.NewBlockHeaderEvent) {
+		events <- event
+	}
+
+	if err := n.notifyBlockHeader(2135502, testHeaderHex); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case event := <-events:
+		testutils.AssertUintsEqual(t, "height", 2135502, uint64(event.Height))
+		testutils.AssertUintsEqual(t, "nonce", 0x2e60aabb, uint64(event.Header.Nonce))
+	case <-time.After(testEventTimeout):
+		t.Fatal("expected block header event")
+	}
+
+	// The same header is seen again after resubscription and must not
+	// be reported twice.
+	if err := n.notifyBlockHeader(2135502, testHeaderHex); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case event := <-events:
+		t.Fatalf("unexpected block header event: [%+v]", event)
+	case <-time.After(testEventTimeout):
+	}
+
+	if err := n.notifyBlockHeader(2135503, "ff"); err == nil {
+		t.Fatal("expected error for malformed header")
+	}
+}
+
+func TestNotifications_NotifyScriptStatus(t *testing.T) {
+	n := newNotifications()
+
+	script := bitcoin.Script{0x00, 0x14, 0x01}
+	scriptHash := computeScriptHash(script)
+
+	events := make(chan *bitcoin.ScriptStatusChangedEvent, 10)
+	n.scripts[scriptHash] = script
+	n.scriptHandlers[scriptHash] = map[uint64]func(event *bitcoin.ScriptStatusChangedEvent){
+		0: func(event *bitcoin.ScriptStatusChangedEvent) {
+			events <- event
+		},
+	}
+
+	expectEvent := func(expectedStatus string) {
+		select {
+		case event := <-events:
+			testutils.AssertBytesEqual(t, script, event.Script)
+			if event.Status != expectedStatus {
+				t.Errorf(
+					"unexpected status\nexpected: %s\nactual:   %s\n",
+					expectedStatus,
+					event.Status,
+				)
+			}
+		case <-time.After(testEventTimeout):
+			t.Fatalf("expected script status event [%s]", expectedStatus)
+		}
+	}
+
+	expectNoEvent := func() {
+		select {
+		case event := <-events:
+			t.Fatalf("unexpected script status event: [%+v]", event)
+		case <-time.After(testEventTimeout):
+		}
+	}
+
+	n.notifyScriptStatus(scriptHash, "status1")
+	expectEvent("status1")
+
+	// Resubscription reports an unchanged status.
+	n.notifyScriptStatus(scriptHash, "status1")
+	expectNoEvent()
+
+	n.notifyScriptStatus(scriptHash, "status2")
+	expectEvent("status2")
+
+	// Statuses of scripts that are not watched are ignored.
+	n.notifyScriptStatus(computeScriptHash(bitcoin.Script{0xff}), "status3")
+	expectNoEvent()
+}
+
+func TestComputeScriptHash(t *testing.T) {
+	// Example from the Electrum protocol documentation:
+	// https://electrumx.readthedocs.io/en/latest/protocol-basics.html#script-hashes
+	script := bitcoin.Script{
+		0x76, 0xa9, 0x14, 0x62, 0xe9, 0x07, 0xb1, 0x5c, 0xbf, 0x27, 0xd5,
+		0x42, 0x53, 0x99, 0xeb, 0xf6, 0xf0, 0xfb, 0x50, 0xeb, 0xb8, 0x8f,
+		0x18, 0x88, 0xac,
+	}
+
+	scriptHash := computeScriptHash(script)
+
+	expectedScriptHash := "8b01df4e368ea28f8dc0423bcf7a4923e3a12d307c875e47a0cfbf90b5c39161"
+	if scriptHash != expectedScriptHash {
+		t.Errorf(
+			"unexpected script hash\nexpected: %s\nactual:   %s\n",
+			expectedScriptHash,
+			scriptHash,
+		)
+	}
+}
@@ -0,0 +1,23 @@
+package esplora
+
+import "time"
+
+const (
+	// DefaultRequestTimeout is a default timeout used for a single attempt of
+	// Esplora REST API request.
+	DefaultRequestTimeout = 30 * time.Second
+	// DefaultRequestRetryTimeout is a default timeout used for Esplora REST API
+	// request retries.
+	DefaultRequestRetryTimeout = 2 * time.Minute
+)
+
+// Config holds configurable properties.
+type Config struct {
+	// URL to the Esplora REST API in format: `scheme://hostname:port/path`,
+	// e.g. `https://blockstream.info/api`.
+	URL string
+	// Timeout for a single attempt of Esplora REST API request.
+	RequestTimeout time.Duration
+	// Timeout for Esplora REST API request retries.
+	RequestRetryTimeout time.Duration
+}
@@ -0,0 +1,909 @@
+package esplora
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/ipfs/go-log"
+	"go.uber.org/zap"
+
+	"github.com/keep-network/keep-core/pkg/bitcoin"
+)
+
+var logger = log.Logger("keep-esplora")
+
+// Connection is a handle for interactions with Esplora REST API.
+type Connection struct {
+	parentCtx  context.Context
+	httpClient *http.Client
+	config     Config
+}
+
+// Connect initializes handle with provided Config.
+func Connect(parentCtx context.Context, config Config) (bitcoin.Chain, error) {
+	if config.RequestTimeout == 0 {
+		config.RequestTimeout = DefaultRequestTimeout
+	}
+	if config.RequestRetryTimeout == 0 {
+		config.RequestRetryTimeout = DefaultRequestRetryTimeout
+	}
+
+	// Drop the trailing slash as API paths are appended to the URL.
+	config.URL = strings.TrimSuffix(config.URL, "/")
+
+	c := &Connection{
+		parentCtx:  parentCtx,
+		httpClient: &http.Client{},
+		config:     config,
+	}
+
+	if err := c.verifyServer(); err != nil {
+		return nil, fmt.Errorf("failed to verify esplora server: [%w]", err)
+	}
+
+	return c, nil
+}
+
+// GetTransaction gets the transaction with the given transaction hash.
+// If the transaction with the given hash was not found on the chain,
+// this function returns an error.
+func (c *Connection) GetTransaction(
+	transactionHash bitcoin.Hash,
+) (*bitcoin.Transaction, error) {
+	txID := transactionHash.Hex(bitcoin.ReversedByteOrder)
+
+	rawTransaction, err := requestWithRetry(
+		c,
+		func(ctx context.Context) (string, error) {
+			rawTransaction, err := c.getText(
+				ctx,
+				fmt.Sprintf("/tx/%s/hex", txID),
+			)
+			if err != nil {
+				if err == errNotFound {
+					// The transaction was not found on the chain. There is
+					// no point in retrying the request and losing time.
+					return "", nil
+				}
+
+				return "", err
+			}
+
+			return rawTransaction, nil
+		},
+		"GetTransactionHex",
+	)
+	if err != nil {
+		return nil, fmt.Errorf(
+			"failed to get raw transaction with ID [%s]: [%w]",
+			txID,
+			err,
+		)
+	}
+	if len(rawTransaction) == 0 {
+		return nil, fmt.Errorf(
+			"failed to get raw transaction with ID [%s]: [%v]",
+			txID,
+			fmt.Errorf("not found"),
+		)
+	}
+
+	result, err := convertRawTransaction(rawTransaction)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert transaction: [%w]", err)
+	}
+
+	return result, nil
+}
+
+// GetTransactionConfirmations gets the number of confirmations for the
+// transaction with the given transaction hash. If the transaction with the
+// given hash was not found on the chain, this function returns an error.
+func (c *Connection) GetTransactionConfirmations(
+	transactionHash bitcoin.Hash,
+) (uint, error) {
+	txID := transactionHash.Hex(bitcoin.ReversedByteOrder)
+
+	transaction, err := requestWithRetry(
+		c,
+		func(ctx context.Context) (*transactionResult, error) {
+			transaction := &transactionResult{}
+			err := c.getJSON(ctx, fmt.Sprintf("/tx/%s", txID), transaction)
+			if err != nil {
+				if err == errNotFound {
+					// The transaction was not found on the chain. There is
+					// no point in retrying the request and losing time.
+					return nil, nil
+				}
+
+				return nil, err
+			}
+
+			return transaction, nil
+		},
+		"GetTransaction",
+	)
+	if err != nil {
+		return 0, fmt.Errorf(
+			"failed to get transaction with ID [%s]: [%w]",
+			txID,
+			err,
+		)
+	}
+	if transaction == nil {
+		return 0, fmt.Errorf(
+			"failed to get transaction with ID [%s]: [%v]",
+			txID,
+			fmt.Errorf("not found"),
+		)
+	}
+
+	// Mempool transactions have no confirmations.
+	if !transaction.Status.Confirmed {
+		return 0, nil
+	}
+
+	latestBlockHeight, err := c.GetLatestBlockHeight()
+	if err != nil {
+		return 0, fmt.Errorf(
+			"failed to get the latest block height: [%w]",
+			err,
+		)
+	}
+
+	if latestBlockHeight >= transaction.Status.BlockHeight {
+		// Add `1` to the calculated difference as if the transaction block
+		// height equals the latest block height the transaction is already
+		// confirmed, so it has one confirmation.
+		return latestBlockHeight - transaction.Status.BlockHeight + 1, nil
+	}
+
+	return 0, nil
+}
+
+// BroadcastTransaction broadcasts the given transaction over the
+// network of the Bitcoin chain nodes. If the broadcast action could not be
+// done, this function returns an error. This function does not give any
+// guarantees regarding transaction mining. The transaction may be mined or
+// rejected eventually.
+func (c *Connection) BroadcastTransaction(
+	transaction *bitcoin.Transaction,
+) error {
+	rawTx := hex.EncodeToString(transaction.Serialize())
+
+	rawTxLogger := logger.With(
+		zap.String("rawTx", rawTx),
+	)
+	rawTxLogger.Debugf("broadcasting transaction")
+
+	response, err := requestWithRetry(
+		c,
+		func(ctx context.Context) (string, error) {
+			return c.postText(ctx, "/tx", rawTx)
+		},
+		"PostTransaction",
+	)
+	if err != nil {
+		return fmt.Errorf("failed to broadcast the transaction: [%w]", err)
+	}
+
+	rawTxLogger.Infof("transaction broadcast successful: [%s]", response)
+
+	return nil
+}
+
+// GetLatestBlockHeight gets the height of the latest block (tip). If the
+// latest block was not determined, this function returns an error.
+func (c *Connection) GetLatestBlockHeight() (uint, error) {
+	blockHeight, err := requestWithRetry(
+		c,
+		func(ctx context.Context) (uint, error) {
+			response, err := c.getText(ctx, "/blocks/tip/height")
+			if err != nil {
+				return 0, err
+			}
+
+			blockHeight, err := strconv.ParseUint(response, 10, 64)
+			if err != nil {
+				return 0, fmt.Errorf(
+					"cannot parse block height [%s]: [%w]",
+					response,
+					err,
+				)
+			}
+
+			return uint(blockHeight), nil
+		},
+		"GetTipHeight",
+	)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get tip height: [%w]", err)
+	}
+
+	return blockHeight, nil
+}
+
+// GetBlockHeader gets the block header for the given block height. If the
+// block with the given height was not found on the chain, this function
+// returns an error.
+func (c *Connection) GetBlockHeader(
+	blockHeight uint,
+) (*bitcoin.BlockHeader, error) {
+	blockHash, err := c.getBlockHash(blockHeight)
+	if err != nil {
+		return nil, err
+	}
+
+	rawBlockHeader, err := requestWithRetry(
+		c,
+		func(ctx context.Context) (string, error) {
+			return c.getText(ctx, fmt.Sprintf("/block/%s/header", blockHash))
+		},
+		"GetBlockHeader",
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get block header: [%w]", err)
+	}
+
+	blockHeader, err := convertBlockHeader(rawBlockHeader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert block header: %w", err)
+	}
+
+	return blockHeader, nil
+}
+
+// GetTransactionMerkleProof gets the Merkle proof for a given transaction.
+// The transaction's hash and the block the transaction was included in the
+// blockchain need to be provided.
+func (c *Connection) GetTransactionMerkleProof(
+	transactionHash bitcoin.Hash,
+	blockHeight uint,
+) (*bitcoin.TransactionMerkleProof, error) {
+	txID := transactionHash.Hex(bitcoin.ReversedByteOrder)
+
+	result, err := requestWithRetry(
+		c,
+		func(ctx context.Context) (*merkleProofResult, error) {
+			result := &merkleProofResult{}
+			err := c.getJSON(
+				ctx,
+				fmt.Sprintf("/tx/%s/merkle-proof", txID),
+				result,
+			)
+			return result, err
+		},
+		"GetMerkleProof",
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get merkle proof: [%w]", err)
+	}
+
+	if result.BlockHeight != blockHeight {
+		return nil, fmt.Errorf(
+			"transaction was included at block height [%v]; expected [%v]",
+			result.BlockHeight,
+			blockHeight,
+		)
+	}
+
+	return convertMerkleProof(result), nil
+}
+
+// GetTransactionsForPublicKeyHash gets confirmed transactions that pays the
+// given public key hash using either a P2PKH or P2WPKH script. The returned
+// transactions are ordered by block height in the ascending order, i.e.
+// the latest transaction is at the end of the list. The returned list does
+// not contain unconfirmed transactions living in the mempool at the moment
+// of request. The returned transactions list can be limited using the
+// `limit` parameter. For example, if `limit` is set to `5`, only the
+// latest five transactions will be returned. Note that taking an unlimited
+// transaction history may be time-consuming as this function fetches
+// complete transactions with all necessary data.
+func (c *Connection) GetTransactionsForPublicKeyHash(
+	publicKeyHash [20]byte,
+	limit int,
+) ([]*bitcoin.Transaction, error) {
+	txHashes, err := c.GetTxHashesForPublicKeyHash(publicKeyHash)
+	if err != nil {
+		return nil, err
+	}
+
+	var selectedTxHashes []bitcoin.Hash
+	if len(txHashes) > limit {
+		selectedTxHashes = txHashes[len(txHashes)-limit:]
+	} else {
+		selectedTxHashes = txHashes
+	}
+
+	transactions := make([]*bitcoin.Transaction, len(selectedTxHashes))
+	for i, txHash := range selectedTxHashes {
+		transaction, err := c.GetTransaction(txHash)
+		if err != nil {
+			return nil, fmt.Errorf("cannot get transaction: [%v]", err)
+		}
+
+		transactions[i] = transaction
+	}
+
+	return transactions, nil
+}
+
+// GetTxHashesForPublicKeyHash gets hashes of confirmed transactions that pays
+// the given public key hash using either a P2PKH or P2WPKH script. The returned
+// transactions hashes are ordered by block height in the ascending order, i.e.
+// the latest transaction hash is at the end of the list. The returned list does
+// not contain unconfirmed transactions hashes living in the mempool at the
+// moment of request.
+func (c *Connection) GetTxHashesForPublicKeyHash(
+	publicKeyHash [20]byte,
+) ([]bitcoin.Hash, error) {
+	p2pkh, err := bitcoin.PayToPublicKeyHash(publicKeyHash)
+	if err != nil {
+		return nil, fmt.Errorf(
+			"cannot build P2PKH for public key hash [0x%x]: [%v]",
+			publicKeyHash,
+			err,
+		)
+	}
+
+	p2wpkh, err := bitcoin.PayToWitnessPublicKeyHash(publicKeyHash)
+	if err != nil {
+		return nil, fmt.Errorf(
+			"cannot build P2WPKH for public key hash [0x%x]: [%v]",
+			publicKeyHash,
+			err,
+		)
+	}
+
+	p2pkhItems, err := c.getConfirmedScriptHistory(p2pkh)
+	if err != nil {
+		return nil, fmt.Errorf(
+			"cannot get P2PKH history for public key hash [0x%x]: [%v]",
+			publicKeyHash,
+			err,
+		)
+	}
+
+	p2wpkhItems, err := c.getConfirmedScriptHistory(p2wpkh)
+	if err != nil {
+		return nil, fmt.Errorf(
+			"cannot get P2WPKH history for public key hash [0x%x]: [%v]",
+			publicKeyHash,
+			err,
+		)
+	}
+
+	items := append(p2pkhItems, p2wpkhItems...)
+
+	sort.SliceStable(
+		items,
+		func(i, j int) bool {
+			return items[i].Status.BlockHeight < items[j].Status.BlockHeight
+		},
+	)
+
+	txHashes := make([]bitcoin.Hash, len(items))
+	for i, item := range items {
+		txHash, err := bitcoin.NewHashFromString(
+			item.TxID,
+			bitcoin.ReversedByteOrder,
+		)
+		if err != nil {
+			return nil, fmt.Errorf(
+				"cannot parse hash [%s]: [%v]",
+				item.TxID,
+				err,
+			)
+		}
+
+		txHashes[i] = txHash
+	}
+
+	return txHashes, nil
+}
+
+// getConfirmedScriptHistory returns a history of confirmed transactions for
+// the given script (P2PKH, P2WPKH, P2SH, P2WSH, etc.). The returned list is
+// sorted by the block height in the ascending order, i.e. the latest
+// transaction is at the end of the list. The resulting list does not contain
+// unconfirmed transactions living in the mempool at the moment of request.
+func (c *Connection) getConfirmedScriptHistory(
+	script []byte,
+) ([]*transactionResult, error) {
+	scriptHash := computeScriptHash(script)
+
+	items := make([]*transactionResult, 0)
+
+	// Esplora returns the confirmed history in pages, starting from the
+	// newest transactions. The next page is requested by passing the last
+	// seen transaction ID. An empty page means there are no more items.
+	lastSeenTxID := ""
+	for {
+		path := fmt.Sprintf("/scripthash/%s/txs/chain", scriptHash)
+		if len(lastSeenTxID) > 0 {
+			path = fmt.Sprintf("%s/%s", path, lastSeenTxID)
+		}
+
+		page, err := requestWithRetry(
+			c,
+			func(ctx context.Context) ([]*transactionResult, error) {
+				var page []*transactionResult
+				err := c.getJSON(ctx, path, &page)
+				return page, err
+			},
+			"GetScriptHashChainTransactions",
+		)
+		if err != nil {
+			return nil, fmt.Errorf(
+				"failed to get history for script [0x%x]: [%v]",
+				script,
+				err,
+			)
+		}
+
+		if len(page) == 0 {
+			break
+		}
+
+		items = append(items, page...)
+		lastSeenTxID = page[len(page)-1].TxID
+	}
+
+	confirmedItems := make([]*transactionResult, 0)
+	for _, item := range items {
+		if item.Status.Confirmed {
+			confirmedItems = append(confirmedItems, item)
+		}
+	}
+
+	sort.SliceStable(
+		confirmedItems,
+		func(i, j int) bool {
+			return confirmedItems[i].Status.BlockHeight <
+				confirmedItems[j].Status.BlockHeight
+		},
+	)
+
+	return confirmedItems, nil
+}
+
+// GetMempoolForPublicKeyHash gets the unconfirmed mempool transactions
+// that pays the given public key hash using either a P2PKH or P2WPKH script.
+// The returned transactions are in an indefinite order.
+func (c *Connection) GetMempoolForPublicKeyHash(
+	publicKeyHash [20]byte,
+) ([]*bitcoin.Transaction, error) {
+	p2pkh, err := bitcoin.PayToPublicKeyHash(publicKeyHash)
+	if err != nil {
+		return nil, fmt.Errorf(
+			"cannot build P2PKH for public key hash [0x%x]: [%v]",
+			publicKeyHash,
+			err,
+		)
+	}
+
+	p2wpkh, err := bitcoin.PayToWitnessPublicKeyHash(publicKeyHash)
+	if err != nil {
+		return nil, fmt.Errorf(
+			"cannot build P2WPKH for public key hash [0x%x]: [%v]",
+			publicKeyHash,
+			err,
+		)
+	}
+
+	p2pkhItems, err := c.getScriptMempool(p2pkh)
+	if err != nil {
+		return nil, fmt.Errorf(
+			"cannot get P2PKH mempool items for public key hash [0x%x]: [%v]",
+			publicKeyHash,
+			err,
+		)
+	}
+
+	p2wpkhItems, err := c.getScriptMempool(p2wpkh)
+	if err != nil {
+		return nil, fmt.Errorf(
+			"cannot get P2WPKH mempool items for public key hash [0x%x]: [%v]",
+			publicKeyHash,
+			err,
+		)
+	}
+
+	items := append(p2pkhItems, p2wpkhItems...)
+
+	transactions := make([]*bitcoin.Transaction, len(items))
+	for i, item := range items {
+		txHash, err := bitcoin.NewHashFromString(
+			item.TxID,
+			bitcoin.ReversedByteOrder,
+		)
+		if err != nil {
+			return nil, fmt.Errorf(
+				"cannot parse hash [%s]: [%v]",
+				item.TxID,
+				err,
+			)
+		}
+
+		transaction, err := c.GetTransaction(txHash)
+		if err != nil {
+			return nil, fmt.Errorf("cannot get transaction: [%v]", err)
+		}
+
+		transactions[i] = transaction
+	}
+
+	return transactions, nil
+}
+
+// getScriptMempool returns unconfirmed mempool transactions for the given
+// script (P2PKH, P2WPKH, P2SH, P2WSH, etc.). The returned list is in an
+// indefinite order.
+func (c *Connection) getScriptMempool(
+	script []byte,
+) ([]*transactionResult, error) {
+	scriptHash := computeScriptHash(script)
+
+	items, err := requestWithRetry(
+		c,
+		func(ctx context.Context) ([]*transactionResult, error) {
+			var items []*transactionResult
+			err := c.getJSON(
+				ctx,
+				fmt.Sprintf("/scripthash/%s/txs/mempool", scriptHash),
+				&items,
+			)
+			return items, err
+		},
+		"GetScriptHashMempoolTransactions",
+	)
+	if err != nil {
+		return nil, fmt.Errorf(
+			"failed to get mempool for script [0x%x]: [%v]",
+			script,
+			err,
+		)
+	}
+
+	return items, nil
+}
+
+// GetUtxosForPublicKeyHash gets unspent outputs of confirmed transactions that
+// are controlled by the given public key hash (either a P2PKH or P2WPKH script).
+// The returned UTXOs are ordered by block height in the ascending order, i.e.
+// the latest UTXO is at the end of the list. The returned list does not contain
+// unspent outputs of unconfirmed transactions living in the mempool at the
+// moment of request. Outputs used as inputs of confirmed or mempool
+// transactions are not returned as well because they are no longer UTXOs.
+func (c *Connection) GetUtxosForPublicKeyHash(
+	publicKeyHash [20]byte,
+) ([]*bitcoin.UnspentTransactionOutput, error) {
+	return c.getPublicKeyHashUtxos(publicKeyHash, true)
+}
+
+// GetMempoolUtxosForPublicKeyHash gets unspent outputs of unconfirmed transactions
+// that are controlled by the given public key hash (either a P2PKH or P2WPKH script).
+// The returned UTXOs are in an indefinite order. The returned list does not
+// contain unspent outputs of confirmed transactions. Outputs used as inputs of
+// confirmed or mempool transactions are not returned as well because they are
+// no longer UTXOs.
+func (c *Connection) GetMempoolUtxosForPublicKeyHash(
+	publicKeyHash [20]byte,
+) ([]*bitcoin.UnspentTransactionOutput, error) {
+	return c.getPublicKeyHashUtxos(publicKeyHash, false)
+}
+
+// getPublicKeyHashUtxos returns unspent outputs of confirmed/unconfirmed
+// transactions that are controlled by the given public key hash (either
+// a P2PKH or P2WPKH script). See getScriptUtxos for details.
+func (c *Connection) getPublicKeyHashUtxos(
+	publicKeyHash [20]byte,
+	confirmed bool,
+) ([]*bitcoin.UnspentTransactionOutput, error) {
+	p2pkh, err := bitcoin.PayToPublicKeyHash(publicKeyHash)
+	if err != nil {
+		return nil, fmt.Errorf(
+			"cannot build P2PKH for public key hash [0x%x]: [%v]",
+			publicKeyHash,
+			err,
+		)
+	}
+
+	p2wpkh, err := bitcoin.PayToWitnessPublicKeyHash(publicKeyHash)
+	if err != nil {
+		return nil, fmt.Errorf(
+			"cannot build P2WPKH for public key hash [0x%x]: [%v]",
+			publicKeyHash,
+			err,
+		)
+	}
+
+	p2pkhItems, err := c.getScriptUtxos(p2pkh, confirmed)
+	if err != nil {
+		return nil, fmt.Errorf(
+			"cannot get P2PKH UTXOs for public key hash [0x%x]: [%v]",
+			publicKeyHash,
+			err,
+		)
+	}
+
+	p2wpkhItems, err := c.getScriptUtxos(p2wpkh, confirmed)
+	if err != nil {
+		return nil, fmt.Errorf(
+			"cannot get P2WPKH UTXOs for public key hash [0x%x]: [%v]",
+			publicKeyHash,
+			err,
+		)
+	}
+
+	items := append(p2pkhItems, p2wpkhItems...)
+
+	if confirmed {
+		sort.SliceStable(
+			items,
+			func(i, j int) bool {
+				return items[i].Status.BlockHeight < items[j].Status.BlockHeight
+			},
+		)
+	}
+
+	return convertUtxos(items)
+}
+
+// getScriptUtxos returns unspent outputs of confirmed/unconfirmed transactions
+// that are locked using the given script (P2PKH, P2WPKH, P2SH, P2WSH, etc.).
+//
+// If the `confirmed` flag is true, the returned list contains unspent outputs
+// of confirmed transactions, sorted by the block height in the ascending order,
+// i.e. the latest UTXO is at the end of the list. The resulting list does not
+// contain unspent outputs of unconfirmed transactions living in the mempool
+// at the moment of request.
+//
+// If the `confirmed` flag is false, the returned list contains unspent outputs
+// of unconfirmed transactions, in an indefinite order. The resulting list
+// does not contain unspent outputs of confirmed transactions.
+//
+// In both cases, the resulted list DOES NOT CONTAIN outputs already used as
+// inputs of confirmed or mempool transactions because they are no longer UTXOs.
+func (c *Connection) getScriptUtxos(
+	script []byte,
+	confirmed bool,
+) ([]*utxoResult, error) {
+	scriptHash := computeScriptHash(script)
+
+	items, err := requestWithRetry(
+		c,
+		func(ctx context.Context) ([]*utxoResult, error) {
+			var items []*utxoResult
+			err := c.getJSON(
+				ctx,
+				fmt.Sprintf("/scripthash/%s/utxo", scriptHash),
+				&items,
+			)
+			return items, err
+		},
+		"GetScriptHashUtxo",
+	)
+	if err != nil {
+		return nil, fmt.Errorf(
+			"failed to get UTXOs for script [0x%x]: [%v]",
+			script,
+			err,
+		)
+	}
+
+	// Esplora takes the mempool into account while determining UTXOs.
+	// Outputs spent by mempool transactions are not returned while
+	// outputs of mempool transactions are returned as unconfirmed.
+	filteredItems := make([]*utxoResult, 0)
+	for _, item := range items {
+		if item.Status.Confirmed == confirmed {
+			filteredItems = append(filteredItems, item)
+		}
+	}
+
+	if confirmed {
+		sort.SliceStable(
+			filteredItems,
+			func(i, j int) bool {
+				return filteredItems[i].Status.BlockHeight <
+					filteredItems[j].Status.BlockHeight
+			},
+		)
+	}
+
+	return filteredItems, nil
+}
+
+// EstimateSatPerVByteFee returns the estimated sat/vbyte fee for a
+// transaction to be confirmed within the given number of blocks.
+func (c *Connection) EstimateSatPerVByteFee(blocks uint32) (int64, error) {
+	// Esplora returns a map of confirmation targets (in blocks) to the
+	// estimated fee rates (in sat/vbyte).
+	feeEstimates, err := requestWithRetry(
+		c,
+		func(ctx context.Context) (map[string]float64, error) {
+			feeEstimates := make(map[string]float64)
+			err := c.getJSON(ctx, "/fee-estimates", &feeEstimates)
+			return feeEstimates, err
+		},
+		"GetFeeEstimates",
+	)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get fee estimates: [%v]", err)
+	}
+
+	satPerVByteFee, err := selectFeeEstimate(feeEstimates, blocks)
+	if err != nil {
+		return 0, err
+	}
+
+	return satPerVByteFee, nil
+}
+
+// selectFeeEstimate selects the fee estimate for the given confirmation
+// target. Esplora provides estimates only for selected targets so, if
+// there is no exact match, the estimate for the closest lower target is
+// taken. That gives a higher fee so the transaction is likely to be
+// confirmed in time.
+func selectFeeEstimate(
+	feeEstimates map[string]float64,
+	blocks uint32,
+) (int64, error) {
+	selectedTarget := uint64(0)
+	selectedFee := float64(0)
+
+	for target, fee := range feeEstimates {
+		parsedTarget, err := strconv.ParseUint(target, 10, 32)
+		if err != nil {
+			return 0, fmt.Errorf(
+				"cannot parse confirmation target [%s]: [%v]",
+				target,
+				err,
+			)
+		}
+
+		if parsedTarget <= uint64(blocks) && parsedTarget > selectedTarget {
+			selectedTarget = parsedTarget
+			selectedFee = fee
+		}
+	}
+
+	if selectedTarget == 0 {
+		return 0, fmt.Errorf(
+			"no fee estimate available for [%v] blocks confirmation target",
+			blocks,
+		)
+	}
+
+	// Make sure the minimum returned sat/vbyte fee is always 1.
+	satPerVByte := math.Max(selectedFee, 1)
+	// Round the returned fee to be an integer.
+	return int64(math.Round(satPerVByte)), nil
+}
+
+// GetMempoolFeeHistogram returns the histogram of fee rates paid by mempool
+// transactions, weighted by their virtual size. The returned bins are
+// ordered by fee rate in the descending order.
+func (c *Connection) GetMempoolFeeHistogram() ([]*bitcoin.FeeHistogramBin, error) {
+	type mempoolResult struct {
+		// FeeHistogram is a list of [feeRate, vsize] pairs, ordered by fee
+		// rate in the descending order.
+		FeeHistogram [][2]float64 `json:"fee_histogram"`
+	}
+
+	result, err := requestWithRetry(
+		c,
+		func(ctx context.Context) (*mempoolResult, error) {
+			result := &mempoolResult{}
+			err := c.getJSON(ctx, "/mempool", result)
+			return result, err
+		},
+		"GetMempool",
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get mempool: [%v]", err)
+	}
+
+	bins := make([]*bitcoin.FeeHistogramBin, len(result.FeeHistogram))
+	for i, bin := range result.FeeHistogram {
+		bins[i] = &bitcoin.FeeHistogramBin{
+			SatPerVByteFee: bin[0],
+			VirtualSize:    int64(bin[1]),
+		}
+	}
+
+	return bins, nil
+}
+
+// GetCoinbaseTxHash gets the hash of the coinbase transaction for the given
+// block height.
+func (c *Connection) GetCoinbaseTxHash(blockHeight uint) (bitcoin.Hash, error) {
+	blockHash, err := c.getBlockHash(blockHeight)
+	if err != nil {
+		return bitcoin.Hash{}, err
+	}
+
+	txHashString, err := requestWithRetry(
+		c,
+		func(ctx context.Context) (string, error) {
+			return c.getText(ctx, fmt.Sprintf("/block/%s/txid/0", blockHash))
+		},
+		"GetBlockTxID",
+	)
+	if err != nil {
+		return bitcoin.Hash{}, fmt.Errorf(
+			"failed to get coinbase tx hash for block height [%v]: [%v]",
+			blockHeight,
+			err,
+		)
+	}
+
+	txHash, err := bitcoin.NewHashFromString(
+		txHashString,
+		bitcoin.ReversedByteOrder,
+	)
+	if err != nil {
+		return bitcoin.Hash{}, fmt.Errorf(
+			"cannot parse hash [%s]: [%v]",
+			txHashString,
+			err,
+		)
+	}
+
+	return txHash, nil
+}
+
+// getBlockHash gets the hash of the block at the given height, in the
+// reversed byte order.
+func (c *Connection) getBlockHash(blockHeight uint) (string, error) {
+	blockHash, err := requestWithRetry(
+		c,
+		func(ctx context.Context) (string, error) {
+			return c.getText(ctx, fmt.Sprintf("/block-height/%d", blockHeight))
+		},
+		"GetBlockHeight",
+	)
+	if err != nil {
+		return "", fmt.Errorf(
+			"failed to get block hash for block height [%v]: [%w]",
+			blockHeight,
+			err,
+		)
+	}
+
+	return blockHash, nil
+}
+
+func (c *Connection) verifyServer() error {
+	blockHeight, err := c.GetLatestBlockHeight()
+	if err != nil {
+		return err
+	}
+
+	logger.Infof(
+		"connected to esplora server [url: [%s], tip height: [%v]]",
+		c.config.URL,
+		blockHeight,
+	)
+
+	return nil
+}
+
+// computeScriptHash computes the script hash used by the Esplora API to
+// identify scripts. Esplora's `/scripthash/:hash` endpoints are equivalents
+// of the `/address/:address` ones that work for any script. Note that,
+// unlike the Electrum protocol, the Esplora API expects the SHA-256 hash
+// of the script in the non-reversed byte order.
+func computeScriptHash(script []byte) string {
+	scriptHash := sha256.Sum256(script)
+	return hex.EncodeToString(scriptHash[:])
+}
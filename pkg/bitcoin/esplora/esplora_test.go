@@ -0,0 +1,745 @@
+package esplora
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/keep-network/keep-core/internal/testutils"
+	"github.com/keep-network/keep-core/pkg/bitcoin"
+)
+
+// stubServer is a stub Esplora REST API server.
+type stubServer struct {
+	t *testing.T
+
+	mutex    sync.Mutex
+	handlers map[string]http.HandlerFunc
+	requests map[string]int
+}
+
+func newStubServer(t *testing.T) (*stubServer, *httptest.Server) {
+	stub := &stubServer{
+		t:        t,
+		handlers: make(map[string]http.HandlerFunc),
+		requests: make(map[string]int),
+	}
+
+	stub.handleText("/blocks/tip/height", "800010")
+
+	server := httptest.NewServer(stub)
+	t.Cleanup(server.Close)
+
+	return stub, server
+}
+
+func (ss *stubServer) handle(path string, handler http.HandlerFunc) {
+	ss.handlers[path] = handler
+}
+
+func (ss *stubServer) handleText(path string, text string) {
+	ss.handle(path, func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(text))
+	})
+}
+
+func (ss *stubServer) handleJSON(path string, v interface{}) {
+	ss.handle(path, func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewEncoder(w).Encode(v); err != nil {
+			ss.t.Errorf("cannot encode response: [%v]", err)
+		}
+	})
+}
+
+func (ss *stubServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	ss.mutex.Lock()
+	ss.requests[r.URL.Path]++
+	ss.mutex.Unlock()
+
+	handler, ok := ss.handlers[r.URL.Path]
+	if !ok {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	handler(w, r)
+}
+
+func (ss *stubServer) requestsCount(path string) int {
+	ss.mutex.Lock()
+	defer ss.mutex.Unlock()
+
+	return ss.requests[path]
+}
+
+func connectToStub(t *testing.T, server *httptest.Server) *Connection {
+	chain, err := Connect(context.Background(), Config{
+		URL:                 server.URL + "/",
+		RequestTimeout:      1 * time.Second,
+		RequestRetryTimeout: 1 * time.Second,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return chain.(*Connection)
+}
+
+func hashFromString(t *testing.T, s string) bitcoin.Hash {
+	hash, err := bitcoin.NewHashFromString(s, bitcoin.ReversedByteOrder)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return hash
+}
+
+func scriptHashPath(t *testing.T, script bitcoin.Script, suffix string) string {
+	scriptHash := sha256.Sum256(script)
+	return fmt.Sprintf("/scripthash/%x/%s", scriptHash, suffix)
+}
+
+var testPublicKeyHash = [20]byte{0x8d, 0xb5, 0x0e, 0xb5, 0x20}
+
+func testScripts(t *testing.T) (bitcoin.Script, bitcoin.Script) {
+	p2pkh, err := bitcoin.PayToPublicKeyHash(testPublicKeyHash)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p2wpkh, err := bitcoin.PayToWitnessPublicKeyHash(testPublicKeyHash)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return p2pkh, p2wpkh
+}
+
+var testTransaction = &bitcoin.Transaction{
+	Version: 1,
+	Inputs: []*bitcoin.TransactionInput{
+		{
+			Outpoint: &bitcoin.TransactionOutpoint{
+				TransactionHash: bitcoin.Hash{0x01},
+				OutputIndex:     1,
+			},
+			SignatureScript: []byte{},
+			Witness: [][]byte{
+				{0x02, 0x03},
+				{0x04},
+			},
+			Sequence: 0xffffffff,
+		},
+	},
+	Outputs: []*bitcoin.TransactionOutput{
+		{
+			Value:           50000,
+			PublicKeyScript: []byte{0x00, 0x14, 0x05},
+		},
+	},
+	Locktime: 0,
+}
+
+func TestGetTransaction(t *testing.T) {
+	stub, server := newStubServer(t)
+
+	txID := testTransaction.Hash().Hex(bitcoin.ReversedByteOrder)
+	stub.handleText(
+		fmt.Sprintf("/tx/%s/hex", txID),
+		hex.EncodeToString(testTransaction.Serialize()),
+	)
+
+	connection := connectToStub(t, server)
+
+	transaction, err := connection.GetTransaction(testTransaction.Hash())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(testTransaction, transaction) {
+		t.Errorf(
+			"unexpected transaction\nexpected: %+v\nactual:   %+v",
+			testTransaction,
+			transaction,
+		)
+	}
+
+	// Not found transactions should not be retried.
+	missingHash := bitcoin.Hash{0xff}
+	_, err = connection.GetTransaction(missingHash)
+	if err == nil {
+		t.Fatal("expected error")
+	}
+	testutils.AssertIntsEqual(
+		t,
+		"requests count",
+		1,
+		stub.requestsCount(
+			fmt.Sprintf("/tx/%s/hex", missingHash.Hex(bitcoin.ReversedByteOrder)),
+		),
+	)
+}
+
+func TestGetTransaction_Retry(t *testing.T) {
+	stub, server := newStubServer(t)
+
+	txID := testTransaction.Hash().Hex(bitcoin.ReversedByteOrder)
+	path := fmt.Sprintf("/tx/%s/hex", txID)
+
+	attempts := 0
+	stub.handle(path, func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		if attempts == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		_, _ = w.Write([]byte(hex.EncodeToString(testTransaction.Serialize())))
+	})
+
+	chain, err := Connect(context.Background(), Config{
+		URL:                 server.URL,
+		RequestTimeout:      1 * time.Second,
+		RequestRetryTimeout: 5 * time.Second,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	transaction, err := chain.GetTransaction(testTransaction.Hash())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testutils.AssertStringsEqual(
+		t,
+		"transaction hash",
+		txID,
+		transaction.Hash().Hex(bitcoin.ReversedByteOrder),
+	)
+	testutils.AssertIntsEqual(t, "attempts", 2, attempts)
+}
+
+func TestGetTransactionConfirmations(t *testing.T) {
+	var tests = map[string]struct {
+		status                statusResult
+		expectedConfirmations uint
+	}{
+		"confirmed transaction": {
+			status: statusResult{
+				Confirmed:   true,
+				BlockHeight: 800000,
+			},
+			expectedConfirmations: 11,
+		},
+		"confirmed in the tip block": {
+			status: statusResult{
+				Confirmed:   true,
+				BlockHeight: 800010,
+			},
+			expectedConfirmations: 1,
+		},
+		"mempool transaction": {
+			status: statusResult{
+				Confirmed: false,
+			},
+			expectedConfirmations: 0,
+		},
+	}
+
+	for testName, test := range tests {
+		t.Run(testName, func(t *testing.T) {
+			stub, server := newStubServer(t)
+
+			txID := testTransaction.Hash().Hex(bitcoin.ReversedByteOrder)
+			stub.handleJSON(
+				fmt.Sprintf("/tx/%s", txID),
+				&transactionResult{TxID: txID, Status: test.status},
+			)
+
+			connection := connectToStub(t, server)
+
+			confirmations, err := connection.GetTransactionConfirmations(
+				testTransaction.Hash(),
+			)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			testutils.AssertUintsEqual(
+				t,
+				"confirmations",
+				uint64(test.expectedConfirmations),
+				uint64(confirmations),
+			)
+		})
+	}
+}
+
+func TestBroadcastTransaction(t *testing.T) {
+	stub, server := newStubServer(t)
+
+	var broadcastTx string
+	stub.handle("/tx", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			t.Errorf("unexpected method: [%s]", r.Method)
+		}
+
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		broadcastTx = string(body)
+
+		_, _ = w.Write([]byte(testTransaction.Hash().Hex(bitcoin.ReversedByteOrder)))
+	})
+
+	connection := connectToStub(t, server)
+
+	err := connection.BroadcastTransaction(testTransaction)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testutils.AssertStringsEqual(
+		t,
+		"broadcast transaction",
+		hex.EncodeToString(testTransaction.Serialize()),
+		broadcastTx,
+	)
+}
+
+func TestGetLatestBlockHeight(t *testing.T) {
+	_, server := newStubServer(t)
+
+	connection := connectToStub(t, server)
+
+	blockHeight, err := connection.GetLatestBlockHeight()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testutils.AssertUintsEqual(t, "block height", 800010, uint64(blockHeight))
+}
+
+func TestGetBlockHeader(t *testing.T) {
+	stub, server := newStubServer(t)
+
+	expectedBlockHeader := &bitcoin.BlockHeader{
+		Version:                 536870916,
+		PreviousBlockHeaderHash: bitcoin.Hash{0x01, 0x02},
+		MerkleRootHash:          bitcoin.Hash{0x03, 0x04},
+		Time:                    1641914003,
+		Bits:                    436256810,
+		Nonce:                   778087099,
+	}
+	blockHash := "00000000000000000000000000000000000000000000000000000000000000aa"
+	serializedBlockHeader := expectedBlockHeader.Serialize()
+
+	stub.handleText("/block-height/2135502", blockHash)
+	stub.handleText(
+		fmt.Sprintf("/block/%s/header", blockHash),
+		hex.EncodeToString(serializedBlockHeader[:]),
+	)
+
+	connection := connectToStub(t, server)
+
+	blockHeader, err := connection.GetBlockHeader(2135502)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(expectedBlockHeader, blockHeader) {
+		t.Errorf(
+			"unexpected block header\nexpected: %+v\nactual:   %+v",
+			expectedBlockHeader,
+			blockHeader,
+		)
+	}
+}
+
+func TestGetTransactionMerkleProof(t *testing.T) {
+	stub, server := newStubServer(t)
+
+	txHash := hashFromString(
+		t,
+		"72e7fd57c2adb1ed2305c4247486ff79aec363296f02ec65be141904f80d214e",
+	)
+
+	stub.handleJSON(
+		fmt.Sprintf(
+			"/tx/%s/merkle-proof",
+			txHash.Hex(bitcoin.ReversedByteOrder),
+		),
+		map[string]interface{}{
+			"block_height": 1569342,
+			"merkle": []string{
+				"8b5bbb5bdf6727bf70fad4f46fe4eaab04c98119ffbd2d95c29adf32d26f8452",
+				"53637bacb07965e4a8220836861d1b16c6da29f10ea9ab53fc4eca73074f98b9",
+			},
+			"pos": 176,
+		},
+	)
+
+	connection := connectToStub(t, server)
+
+	merkleProof, err := connection.GetTransactionMerkleProof(txHash, 1569342)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expectedMerkleProof := &bitcoin.TransactionMerkleProof{
+		BlockHeight: 1569342,
+		MerkleNodes: []string{
+			"8b5bbb5bdf6727bf70fad4f46fe4eaab04c98119ffbd2d95c29adf32d26f8452",
+			"53637bacb07965e4a8220836861d1b16c6da29f10ea9ab53fc4eca73074f98b9",
+		},
+		Position: 176,
+	}
+	if !reflect.DeepEqual(expectedMerkleProof, merkleProof) {
+		t.Errorf(
+			"unexpected merkle proof\nexpected: %+v\nactual:   %+v",
+			expectedMerkleProof,
+			merkleProof,
+		)
+	}
+
+	_, err = connection.GetTransactionMerkleProof(txHash, 1569343)
+	if err == nil {
+		t.Fatal("expected error for wrong block height")
+	}
+}
+
+func TestGetTxHashesForPublicKeyHash(t *testing.T) {
+	stub, server := newStubServer(t)
+
+	p2pkh, p2wpkh := testScripts(t)
+
+	confirmedTx := func(txID string, blockHeight uint) *transactionResult {
+		return &transactionResult{
+			TxID:   txID,
+			Status: statusResult{Confirmed: true, BlockHeight: blockHeight},
+		}
+	}
+
+	// The P2PKH history spans two pages, newest transactions first.
+	stub.handleJSON(
+		scriptHashPath(t, p2pkh, "txs/chain"),
+		[]*transactionResult{
+			confirmedTx("3333333333333333333333333333333333333333333333333333333333333333", 300),
+			confirmedTx("1111111111111111111111111111111111111111111111111111111111111111", 100),
+		},
+	)
+	stub.handleJSON(
+		scriptHashPath(
+			t,
+			p2pkh,
+			"txs/chain/1111111111111111111111111111111111111111111111111111111111111111",
+		),
+		[]*transactionResult{
+			confirmedTx("0000000000000000000000000000000000000000000000000000000000000000", 50),
+		},
+	)
+	stub.handleJSON(
+		scriptHashPath(
+			t,
+			p2pkh,
+			"txs/chain/0000000000000000000000000000000000000000000000000000000000000000",
+		),
+		[]*transactionResult{},
+	)
+	stub.handleJSON(
+		scriptHashPath(t, p2wpkh, "txs/chain"),
+		[]*transactionResult{
+			confirmedTx("2222222222222222222222222222222222222222222222222222222222222222", 200),
+		},
+	)
+	stub.handleJSON(
+		scriptHashPath(
+			t,
+			p2wpkh,
+			"txs/chain/2222222222222222222222222222222222222222222222222222222222222222",
+		),
+		[]*transactionResult{},
+	)
+
+	connection := connectToStub(t, server)
+
+	txHashes, err := connection.GetTxHashesForPublicKeyHash(testPublicKeyHash)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expectedTxHashes := []bitcoin.Hash{
+		hashFromString(t, "0000000000000000000000000000000000000000000000000000000000000000"),
+		hashFromString(t, "1111111111111111111111111111111111111111111111111111111111111111"),
+		hashFromString(t, "2222222222222222222222222222222222222222222222222222222222222222"),
+		hashFromString(t, "3333333333333333333333333333333333333333333333333333333333333333"),
+	}
+	if !reflect.DeepEqual(expectedTxHashes, txHashes) {
+		t.Errorf(
+			"unexpected transaction hashes\nexpected: %v\nactual:   %v",
+			expectedTxHashes,
+			txHashes,
+		)
+	}
+}
+
+func TestGetMempoolForPublicKeyHash(t *testing.T) {
+	stub, server := newStubServer(t)
+
+	p2pkh, p2wpkh := testScripts(t)
+
+	txID := testTransaction.Hash().Hex(bitcoin.ReversedByteOrder)
+
+	stub.handleJSON(
+		scriptHashPath(t, p2pkh, "txs/mempool"),
+		[]*transactionResult{},
+	)
+	stub.handleJSON(
+		scriptHashPath(t, p2wpkh, "txs/mempool"),
+		[]*transactionResult{{TxID: txID}},
+	)
+	stub.handleText(
+		fmt.Sprintf("/tx/%s/hex", txID),
+		hex.EncodeToString(testTransaction.Serialize()),
+	)
+
+	connection := connectToStub(t, server)
+
+	transactions, err := connection.GetMempoolForPublicKeyHash(testPublicKeyHash)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual([]*bitcoin.Transaction{testTransaction}, transactions) {
+		t.Errorf("unexpected transactions: [%v]", transactions)
+	}
+}
+
+func TestGetUtxosForPublicKeyHash(t *testing.T) {
+	stub, server := newStubServer(t)
+
+	p2pkh, p2wpkh := testScripts(t)
+
+	stub.handleJSON(
+		scriptHashPath(t, p2pkh, "utxo"),
+		[]*utxoResult{
+			{
+				TxID:   "1111111111111111111111111111111111111111111111111111111111111111",
+				Vout:   1,
+				Value:  10000,
+				Status: statusResult{Confirmed: true, BlockHeight: 300},
+			},
+			{
+				TxID:   "3333333333333333333333333333333333333333333333333333333333333333",
+				Vout:   0,
+				Value:  30000,
+				Status: statusResult{Confirmed: false},
+			},
+		},
+	)
+	stub.handleJSON(
+		scriptHashPath(t, p2wpkh, "utxo"),
+		[]*utxoResult{
+			{
+				TxID:   "2222222222222222222222222222222222222222222222222222222222222222",
+				Vout:   2,
+				Value:  20000,
+				Status: statusResult{Confirmed: true, BlockHeight: 200},
+			},
+			{
+				TxID:   "4444444444444444444444444444444444444444444444444444444444444444",
+				Vout:   4,
+				Value:  40000,
+				Status: statusResult{Confirmed: false},
+			},
+		},
+	)
+
+	connection := connectToStub(t, server)
+
+	utxos, err := connection.GetUtxosForPublicKeyHash(testPublicKeyHash)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expectedUtxos := []*bitcoin.UnspentTransactionOutput{
+		{
+			Outpoint: &bitcoin.TransactionOutpoint{
+				TransactionHash: hashFromString(t, "2222222222222222222222222222222222222222222222222222222222222222"),
+				OutputIndex:     2,
+			},
+			Value: 20000,
+		},
+		{
+			Outpoint: &bitcoin.TransactionOutpoint{
+				TransactionHash: hashFromString(t, "1111111111111111111111111111111111111111111111111111111111111111"),
+				OutputIndex:     1,
+			},
+			Value: 10000,
+		},
+	}
+	if !reflect.DeepEqual(expectedUtxos, utxos) {
+		t.Errorf(
+			"unexpected confirmed UTXOs\nexpected: %v\nactual:   %v",
+			expectedUtxos,
+			utxos,
+		)
+	}
+
+	mempoolUtxos, err := connection.GetMempoolUtxosForPublicKeyHash(
+		testPublicKeyHash,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expectedMempoolUtxos := []*bitcoin.UnspentTransactionOutput{
+		{
+			Outpoint: &bitcoin.TransactionOutpoint{
+				TransactionHash: hashFromString(t, "3333333333333333333333333333333333333333333333333333333333333333"),
+				OutputIndex:     0,
+			},
+			Value: 30000,
+		},
+		{
+			Outpoint: &bitcoin.TransactionOutpoint{
+				TransactionHash: hashFromString(t, "4444444444444444444444444444444444444444444444444444444444444444"),
+				OutputIndex:     4,
+			},
+			Value: 40000,
+		},
+	}
+	if !reflect.DeepEqual(expectedMempoolUtxos, mempoolUtxos) {
+		t.Errorf(
+			"unexpected mempool UTXOs\nexpected: %v\nactual:   %v",
+			expectedMempoolUtxos,
+			mempoolUtxos,
+		)
+	}
+}
+
+func TestEstimateSatPerVByteFee(t *testing.T) {
+	stub, server := newStubServer(t)
+
+	stub.handleJSON("/fee-estimates", map[string]float64{
+		"1":   25.4,
+		"2":   20.1,
+		"3":   15.6,
+		"6":   10.2,
+		"144": 0.5,
+	})
+
+	connection := connectToStub(t, server)
+
+	var tests = map[string]struct {
+		blocks                 uint32
+		expectedSatPerVByteFee int64
+		expectedErr            bool
+	}{
+		"exact target": {
+			blocks:                 3,
+			expectedSatPerVByteFee: 16,
+		},
+		"no exact target": {
+			blocks:                 5,
+			expectedSatPerVByteFee: 16,
+		},
+		"fee below 1 sat/vbyte": {
+			blocks:                 1008,
+			expectedSatPerVByteFee: 1,
+		},
+		"target lower than all available": {
+			blocks:      0,
+			expectedErr: true,
+		},
+	}
+
+	for testName, test := range tests {
+		t.Run(testName, func(t *testing.T) {
+			satPerVByteFee, err := connection.EstimateSatPerVByteFee(test.blocks)
+			if test.expectedErr {
+				if err == nil {
+					t.Fatal("expected error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			testutils.AssertIntsEqual(
+				t,
+				"sat/vbyte fee",
+				int(test.expectedSatPerVByteFee),
+				int(satPerVByteFee),
+			)
+		})
+	}
+}
+
+func TestGetMempoolFeeHistogram(t *testing.T) {
+	stub, server := newStubServer(t)
+
+	stub.handleJSON("/mempool", map[string]interface{}{
+		"count":     8134,
+		"vsize":     3444604,
+		"total_fee": 29204625,
+		"fee_histogram": [][2]float64{
+			{53.01, 102131},
+			{38.56, 110990},
+			{1.0, 3231483},
+		},
+	})
+
+	connection := connectToStub(t, server)
+
+	bins, err := connection.GetMempoolFeeHistogram()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expectedBins := []*bitcoin.FeeHistogramBin{
+		{SatPerVByteFee: 53.01, VirtualSize: 102131},
+		{SatPerVByteFee: 38.56, VirtualSize: 110990},
+		{SatPerVByteFee: 1.0, VirtualSize: 3231483},
+	}
+
+	if !reflect.DeepEqual(expectedBins, bins) {
+		t.Errorf(
+			"unexpected histogram bins\nexpected: %v\nactual:   %v",
+			expectedBins,
+			bins,
+		)
+	}
+}
+
+func TestGetCoinbaseTxHash(t *testing.T) {
+	stub, server := newStubServer(t)
+
+	blockHash := "00000000000000000000000000000000000000000000000000000000000000aa"
+	coinbaseTxID := "5555555555555555555555555555555555555555555555555555555555555555"
+
+	stub.handleText("/block-height/1000", blockHash)
+	stub.handleText(fmt.Sprintf("/block/%s/txid/0", blockHash), coinbaseTxID)
+
+	connection := connectToStub(t, server)
+
+	txHash, err := connection.GetCoinbaseTxHash(1000)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testutils.AssertStringsEqual(
+		t,
+		"coinbase tx hash",
+		coinbaseTxID,
+		txHash.Hex(bitcoin.ReversedByteOrder),
+	)
+}
@@ -0,0 +1,161 @@
+package esplora
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/keep-network/keep-common/pkg/wrappers"
+)
+
+// maxErrorBodyLength is the maximum length of the response body attached
+// to errors returned for unsuccessful responses.
+const maxErrorBodyLength = 256
+
+// errNotFound is returned when the Esplora REST API responds with the
+// 404 Not Found status.
+var errNotFound = fmt.Errorf("not found")
+
+// getText executes a GET request for the given API path and returns the
+// response body as a trimmed string.
+func (c *Connection) getText(ctx context.Context, path string) (string, error) {
+	body, err := c.do(ctx, http.MethodGet, path, nil)
+	if err != nil {
+		return "", err
+	}
+
+	return strings.TrimSpace(string(body)), nil
+}
+
+// getJSON executes a GET request for the given API path and unmarshals
+// the response body into the provided result pointer.
+func (c *Connection) getJSON(
+	ctx context.Context,
+	path string,
+	result interface{},
+) error {
+	body, err := c.do(ctx, http.MethodGet, path, nil)
+	if err != nil {
+		return err
+	}
+
+	if err := json.Unmarshal(body, result); err != nil {
+		return fmt.Errorf("failed to unmarshal response: [%w]", err)
+	}
+
+	return nil
+}
+
+// postText executes a POST request with the given plain text body for the
+// given API path and returns the response body as a trimmed string.
+func (c *Connection) postText(
+	ctx context.Context,
+	path string,
+	text string,
+) (string, error) {
+	body, err := c.do(ctx, http.MethodPost, path, strings.NewReader(text))
+	if err != nil {
+		return "", err
+	}
+
+	return strings.TrimSpace(string(body)), nil
+}
+
+func (c *Connection) do(
+	ctx context.Context,
+	method string,
+	path string,
+	requestBody io.Reader,
+) ([]byte, error) {
+	request, err := http.NewRequestWithContext(
+		ctx,
+		method,
+		c.config.URL+path,
+		requestBody,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: [%w]", err)
+	}
+	if requestBody != nil {
+		request.Header.Set("Content-Type", "text/plain")
+	}
+
+	response, err := c.httpClient.Do(request)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute request: [%w]", err)
+	}
+	defer response.Body.Close()
+
+	responseBody, err := io.ReadAll(response.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: [%w]", err)
+	}
+
+	if response.StatusCode == http.StatusNotFound {
+		return nil, errNotFound
+	}
+
+	if response.StatusCode != http.StatusOK {
+		errorBody := string(responseBody)
+		if len(errorBody) > maxErrorBodyLength {
+			errorBody = errorBody[:maxErrorBodyLength]
+		}
+
+		return nil, fmt.Errorf(
+			"unexpected response status [%s]: [%s]",
+			response.Status,
+			strings.TrimSpace(errorBody),
+		)
+	}
+
+	return responseBody, nil
+}
+
+func requestWithRetry[K interface{}](
+	c *Connection,
+	requestFn func(ctx context.Context) (K, error),
+	requestName string,
+) (K, error) {
+	startTime := time.Now()
+	logger.Debugf("starting [%s] request to Esplora server", requestName)
+
+	var result K
+
+	err := wrappers.DoWithDefaultRetry(
+		c.parentCtx,
+		c.config.RequestRetryTimeout,
+		func(ctx context.Context) error {
+			requestCtx, requestCancel := context.WithTimeout(
+				ctx,
+				c.config.RequestTimeout,
+			)
+			defer requestCancel()
+
+			r, err := requestFn(requestCtx)
+			if err != nil {
+				return fmt.Errorf("request failed: [%w]", err)
+			}
+
+			result = r
+			return nil
+		})
+
+	solveRequestOutcome := func(err error) string {
+		if err != nil {
+			return fmt.Sprintf("error: [%v]", err)
+		}
+		return "success"
+	}
+
+	logger.Debugf("[%s] request to Esplora server completed with [%s] after [%s]",
+		requestName,
+		solveRequestOutcome(err),
+		time.Since(startTime),
+	)
+
+	return result, err
+}
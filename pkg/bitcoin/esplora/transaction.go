@@ -0,0 +1,127 @@
+package esplora
+
+import (
+	"encoding/hex"
+	"fmt"
+
+	"github.com/keep-network/keep-core/pkg/bitcoin"
+)
+
+// statusResult represents the confirmation status of a transaction or
+// an output returned by the Esplora REST API.
+type statusResult struct {
+	Confirmed   bool `json:"confirmed"`
+	BlockHeight uint `json:"block_height"`
+}
+
+// transactionResult represents a transaction returned by the Esplora REST
+// API. Only fields relevant for the client are decoded.
+type transactionResult struct {
+	TxID   string       `json:"txid"`
+	Status statusResult `json:"status"`
+}
+
+// utxoResult represents an unspent output returned by the Esplora REST API.
+type utxoResult struct {
+	TxID   string       `json:"txid"`
+	Vout   uint32       `json:"vout"`
+	Value  int64        `json:"value"`
+	Status statusResult `json:"status"`
+}
+
+// merkleProofResult represents a transaction merkle proof returned by the
+// Esplora REST API.
+type merkleProofResult struct {
+	BlockHeight uint     `json:"block_height"`
+	Merkle      []string `json:"merkle"`
+	Pos         uint     `json:"pos"`
+}
+
+// convertRawTransaction transforms a transaction provided in the hexadecimal
+// serialized string to the format expected by the bitcoin.Chain interface.
+func convertRawTransaction(rawTx string) (*bitcoin.Transaction, error) {
+	transactionBytes, err := hex.DecodeString(rawTx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode a hex string: [%w]", err)
+	}
+
+	transaction := new(bitcoin.Transaction)
+	if err := transaction.Deserialize(transactionBytes); err != nil {
+		return nil, fmt.Errorf(
+			"failed to deserialize a transaction: [%w]",
+			err,
+		)
+	}
+
+	return transaction, nil
+}
+
+// convertBlockHeader transforms a block header provided in the hexadecimal
+// serialized string to the format expected by the bitcoin.Chain interface.
+func convertBlockHeader(rawHeader string) (*bitcoin.BlockHeader, error) {
+	headerBytes, err := hex.DecodeString(rawHeader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode a hex string: [%w]", err)
+	}
+
+	if len(headerBytes) != bitcoin.BlockHeaderByteLength {
+		return nil, fmt.Errorf(
+			"wrong block header length; expected [%v], got [%v]",
+			bitcoin.BlockHeaderByteLength,
+			len(headerBytes),
+		)
+	}
+
+	var rawBlockHeader [bitcoin.BlockHeaderByteLength]byte
+	copy(rawBlockHeader[:], headerBytes)
+
+	blockHeader := new(bitcoin.BlockHeader)
+	blockHeader.Deserialize(rawBlockHeader)
+
+	return blockHeader, nil
+}
+
+// convertMerkleProof transforms a merkle proof returned by the Esplora REST
+// API to the format expected by the bitcoin.Chain interface. Esplora uses
+// the same format as the Electrum protocol so merkle nodes are hashes in
+// the reversed byte order.
+func convertMerkleProof(
+	result *merkleProofResult,
+) *bitcoin.TransactionMerkleProof {
+	return &bitcoin.TransactionMerkleProof{
+		BlockHeight: result.BlockHeight,
+		MerkleNodes: result.Merkle,
+		Position:    result.Pos,
+	}
+}
+
+// convertUtxos transforms unspent outputs returned by the Esplora REST API
+// to the format expected by the bitcoin.Chain interface.
+func convertUtxos(
+	items []*utxoResult,
+) ([]*bitcoin.UnspentTransactionOutput, error) {
+	utxos := make([]*bitcoin.UnspentTransactionOutput, len(items))
+	for i, item := range items {
+		txHash, err := bitcoin.NewHashFromString(
+			item.TxID,
+			bitcoin.ReversedByteOrder,
+		)
+		if err != nil {
+			return nil, fmt.Errorf(
+				"cannot parse hash [%s]: [%v]",
+				item.TxID,
+				err,
+			)
+		}
+
+		utxos[i] = &bitcoin.UnspentTransactionOutput{
+			Outpoint: &bitcoin.TransactionOutpoint{
+				TransactionHash: txHash,
+				OutputIndex:     item.Vout,
+			},
+			Value: item.Value,
+		}
+	}
+
+	return utxos, nil
+}
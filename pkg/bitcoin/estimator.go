@@ -17,6 +17,11 @@ var signaturePlaceholder = make([]byte, 72)
 // Compressed public keys have always 33 bytes.
 var publicKeyPlaceholder = make([]byte, 33)
 
+// Schnorr signatures used by taproot key-path spends have 64 bytes if the
+// default sighash type is used and 65 bytes otherwise. For fee estimation
+// purposes, we take the greatest possible value.
+var schnorrSignaturePlaceholder = make([]byte, 65)
+
 // TransactionSizeEstimator is a component allowing to estimate the size
 // of a Bitcoin transaction of the provided shape, without constructing it.
 type TransactionSizeEstimator struct {
@@ -127,6 +132,34 @@ func (tse *TransactionSizeEstimator) AddScriptHashInputs(
 	return tse
 }
 
+// AddTaprootKeyPathInputs adds the provided count of P2TR inputs spent using
+// the key path to the estimation. If the estimator already errored out during
+// previous actions, this method does nothing.
+func (tse *TransactionSizeEstimator) AddTaprootKeyPathInputs(
+	count int,
+) *TransactionSizeEstimator {
+	if tse.err != nil {
+		return tse
+	}
+
+	// Key path spends carry just the Schnorr signature in the witness.
+	witness := wire.TxWitness{
+		schnorrSignaturePlaceholder,
+	}
+
+	for i := 0; i < count; i++ {
+		tse.internal.AddTxIn(
+			wire.NewTxIn(
+				wire.NewOutPoint((*chainhash.Hash)(&[32]byte{}), 0),
+				nil,
+				witness,
+			),
+		)
+	}
+
+	return tse
+}
+
 // AddPublicKeyHashOutputs adds the provided count of P2WPKH (isWitness is true)
 // or P2PKH (isWitness is false) outputs to the estimation. If the estimator
 // already errored out during previous actions, this method does nothing.
@@ -201,6 +234,31 @@ func (tse *TransactionSizeEstimator) AddScriptHashOutputs(
 	return tse
 }
 
+// AddTaprootOutputs adds the provided count of P2TR outputs to the estimation.
+// If the estimator already errored out during previous actions, this method
+// does nothing.
+func (tse *TransactionSizeEstimator) AddTaprootOutputs(
+	count int,
+) *TransactionSizeEstimator {
+	if tse.err != nil {
+		return tse
+	}
+
+	scriptPlaceholder, err := PayToTaproot([32]byte{})
+	if err != nil {
+		tse.err = err
+		return tse
+	}
+
+	for i := 0; i < count; i++ {
+		tse.internal.AddTxOut(
+			wire.NewTxOut(0, scriptPlaceholder),
+		)
+	}
+
+	return tse
+}
+
 // VirtualSize returns the virtual size of the transaction whose shape was
 // provided to the estimator. If any errors occurred while building the
 // transaction shape, the first error will be returned.
@@ -219,13 +277,16 @@ func (tse *TransactionSizeEstimator) VirtualSize() (int64, error) {
 }
 
 // TransactionFeeEstimator is a component allowing to estimate the total fee
-// for the given transaction virtual size.
+// for the given transaction virtual size. The fee rate is provided by the
+// given FeeRateEstimator which is usually the Chain itself.
 type TransactionFeeEstimator struct {
-	chain Chain
+	feeRateEstimator FeeRateEstimator
 }
 
-func NewTransactionFeeEstimator(chain Chain) *TransactionFeeEstimator {
-	return &TransactionFeeEstimator{chain: chain}
+func NewTransactionFeeEstimator(
+	feeRateEstimator FeeRateEstimator,
+) *TransactionFeeEstimator {
+	return &TransactionFeeEstimator{feeRateEstimator: feeRateEstimator}
 }
 
 // EstimateFee estimates the total fee for the given transaction virtual size,
@@ -242,7 +303,9 @@ func (tfe *TransactionFeeEstimator) EstimateFee(
 		resolvedBlocks = blocks[0]
 	}
 
-	satPerVByteFee, err := tfe.chain.EstimateSatPerVByteFee(resolvedBlocks)
+	satPerVByteFee, err := tfe.feeRateEstimator.EstimateSatPerVByteFee(
+		resolvedBlocks,
+	)
 	if err != nil {
 		return 0, fmt.Errorf("cannot get estimated sat/vbyte fee: [%v]", err)
 	}
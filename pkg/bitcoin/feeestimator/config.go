@@ -0,0 +1,35 @@
+package feeestimator
+
+const (
+	// NodeMode is the fee estimation mode relying solely on the estimate
+	// returned by the Bitcoin chain backend's node.
+	NodeMode = "node"
+	// DeadlineMode is the fee estimation mode targeting confirmation
+	// within the requested number of blocks.
+	DeadlineMode = "deadline"
+	// PercentileMode is the fee estimation mode targeting the configured
+	// percentile of fee rates paid for the block space.
+	PercentileMode = "percentile"
+)
+
+const (
+	// DefaultPercentile is the default fee rate percentile targeted in the
+	// percentile mode.
+	DefaultPercentile = 50
+	// DefaultRecentBlocks is the default number of recent blocks whose fee
+	// rates are taken into account.
+	DefaultRecentBlocks = 6
+)
+
+// Config holds configurable properties of the fee estimator.
+type Config struct {
+	// Mode determines the fee estimation mode. Supported values are `node`,
+	// `deadline` and `percentile`. If empty, the `node` mode is used.
+	Mode string
+	// Percentile is the fee rate percentile, between 1 and 99, targeted
+	// in the percentile mode.
+	Percentile int
+	// RecentBlocks is the number of recent blocks whose fee rates are taken
+	// into account. If zero, fee rates of recent blocks are not used.
+	RecentBlocks int
+}
@@ -0,0 +1,278 @@
+// Package feeestimator provides a bitcoin.FeeRateEstimator combining several
+// fee rate signals: the distribution of fee rates in the mempool, fee rates
+// paid in recent blocks and the estimate of the Bitcoin chain backend's node.
+// The node estimate alone often overshoots or undershoots when the mempool
+// shifts quickly. Taking the median of independent signals makes the
+// estimate resilient to a single signal going off.
+package feeestimator
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/ipfs/go-log"
+
+	"github.com/keep-network/keep-core/pkg/bitcoin"
+)
+
+var logger = log.Logger("keep-bitcoin-feeestimator")
+
+// maxBlockVirtualSize is the maximum virtual size of a Bitcoin block, i.e.
+// the maximum block weight divided by the witness scale factor.
+const maxBlockVirtualSize = 1000000
+
+// minSatPerVByteFee is the minimum fee rate relayed by the Bitcoin network.
+const minSatPerVByteFee = 1
+
+// Estimator is a bitcoin.FeeRateEstimator combining fee rate signals
+// provided by the Bitcoin chain. The mempool signal is available only if
+// the chain implements bitcoin.FeeHistogramSource. The recent blocks signal
+// is available only if the chain implements bitcoin.BlockFeeRatesSource.
+type Estimator struct {
+	chain  bitcoin.Chain
+	config Config
+}
+
+// New creates a new fee estimator using the given Bitcoin chain as the
+// source of fee rate signals.
+func New(chain bitcoin.Chain, config Config) (*Estimator, error) {
+	switch config.Mode {
+	case "", NodeMode, DeadlineMode:
+	case PercentileMode:
+		if config.Percentile < 1 || config.Percentile > 99 {
+			return nil, fmt.Errorf(
+				"percentile [%v] must be between 1 and 99",
+				config.Percentile,
+			)
+		}
+	default:
+		return nil, fmt.Errorf(
+			"unsupported fee estimation mode: [%s]; expected one of: [%s, %s, %s]",
+			config.Mode,
+			NodeMode,
+			DeadlineMode,
+			PercentileMode,
+		)
+	}
+
+	if config.RecentBlocks < 0 {
+		return nil, fmt.Errorf(
+			"recent blocks [%v] must not be negative",
+			config.RecentBlocks,
+		)
+	}
+
+	return &Estimator{
+		chain:  chain,
+		config: config,
+	}, nil
+}
+
+// EstimateSatPerVByteFee returns the estimated sat/vbyte fee for a
+// transaction to be confirmed within the given number of blocks. Signals
+// that cannot be obtained are skipped. An error is returned only if none
+// of the signals is available.
+func (e *Estimator) EstimateSatPerVByteFee(blocks uint32) (int64, error) {
+	if e.config.Mode == "" || e.config.Mode == NodeMode {
+		return e.chain.EstimateSatPerVByteFee(blocks)
+	}
+
+	if blocks == 0 {
+		blocks = 1
+	}
+
+	signals := make([]float64, 0, 3)
+
+	nodeFeeRate, err := e.chain.EstimateSatPerVByteFee(blocks)
+	if err != nil {
+		logger.Warnf("cannot get node fee rate estimate: [%v]", err)
+	} else {
+		signals = append(signals, float64(nodeFeeRate))
+	}
+
+	mempoolFeeRate, ok, err := e.mempoolFeeRate(blocks)
+	if err != nil {
+		logger.Warnf("cannot get mempool fee rate: [%v]", err)
+	} else if ok {
+		signals = append(signals, mempoolFeeRate)
+	}
+
+	recentBlocksFeeRate, ok, err := e.recentBlocksFeeRate()
+	if err != nil {
+		logger.Warnf("cannot get recent blocks fee rate: [%v]", err)
+	} else if ok {
+		signals = append(signals, recentBlocksFeeRate)
+	}
+
+	if len(signals) == 0 {
+		return 0, fmt.Errorf("no fee rate signal is available")
+	}
+
+	logger.Debugf(
+		"fee rate signals for [%v] blocks in [%s] mode: node [%v], "+
+			"mempool [%v], recent blocks [%v]",
+		blocks,
+		e.config.Mode,
+		nodeFeeRate,
+		mempoolFeeRate,
+		recentBlocksFeeRate,
+	)
+
+	feeRate := math.Ceil(median(signals))
+
+	return int64(math.Max(feeRate, minSatPerVByteFee)), nil
+}
+
+// mempoolFeeRate computes the fee rate signal based on the mempool fee
+// histogram. The second returned value is false if the chain does not
+// provide the mempool fee histogram.
+func (e *Estimator) mempoolFeeRate(blocks uint32) (float64, bool, error) {
+	source, ok := e.chain.(bitcoin.FeeHistogramSource)
+	if !ok {
+		return 0, false, nil
+	}
+
+	histogram, err := source.GetMempoolFeeHistogram()
+	if err != nil {
+		return 0, false, err
+	}
+
+	switch e.config.Mode {
+	case PercentileMode:
+		return histogramPercentileFeeRate(
+			histogram,
+			blocks,
+			e.config.Percentile,
+		), true, nil
+	default:
+		return histogramDeadlineFeeRate(histogram, blocks), true, nil
+	}
+}
+
+// histogramDeadlineFeeRate returns the lowest fee rate of mempool
+// transactions that still fit in the given number of blocks, assuming
+// miners include transactions with the highest fee rates first. If the
+// whole mempool fits in the given number of blocks, the minimum relay fee
+// rate is returned.
+func histogramDeadlineFeeRate(
+	histogram []*bitcoin.FeeHistogramBin,
+	blocks uint32,
+) float64 {
+	capacity := int64(blocks) * maxBlockVirtualSize
+	cumulativeSize := int64(0)
+
+	for _, bin := range histogram {
+		cumulativeSize += bin.VirtualSize
+		if cumulativeSize >= capacity {
+			return bin.SatPerVByteFee
+		}
+	}
+
+	return minSatPerVByteFee
+}
+
+// histogramPercentileFeeRate returns the fee rate at the given percentile,
+// weighted by virtual size, of mempool transactions projected to be
+// included in the given number of blocks, assuming miners include
+// transactions with the highest fee rates first.
+func histogramPercentileFeeRate(
+	histogram []*bitcoin.FeeHistogramBin,
+	blocks uint32,
+	percentile int,
+) float64 {
+	capacity := int64(blocks) * maxBlockVirtualSize
+
+	projectedSize := int64(0)
+	for _, bin := range histogram {
+		projectedSize += bin.VirtualSize
+	}
+	if projectedSize > capacity {
+		projectedSize = capacity
+	}
+
+	// Bins are ordered by fee rate in the descending order so the position
+	// of the percentile must be counted from the top.
+	position := projectedSize * int64(100-percentile) / 100
+	cumulativeSize := int64(0)
+
+	for _, bin := range histogram {
+		cumulativeSize += bin.VirtualSize
+		if cumulativeSize > position {
+			return bin.SatPerVByteFee
+		}
+	}
+
+	return minSatPerVByteFee
+}
+
+// recentBlocksFeeRate computes the fee rate signal based on fee rates paid
+// in recent blocks. The signal is the median, across recent blocks, of the
+// fee rate at the percentile of the block's weight determined by the mode.
+// In the deadline mode, this is the lowest reported percentile which
+// approximates the fee rate sufficient to get into a block. In the percentile
+// mode, this is the lowest reported percentile not lower than the configured
+// one. The second returned value is false if the chain does not provide
+// block fee rates or recent blocks are not taken into account.
+func (e *Estimator) recentBlocksFeeRate() (float64, bool, error) {
+	source, ok := e.chain.(bitcoin.BlockFeeRatesSource)
+	if !ok || e.config.RecentBlocks == 0 {
+		return 0, false, nil
+	}
+
+	percentileIndex := 0
+	if e.config.Mode == PercentileMode {
+		percentileIndex = len(bitcoin.BlockFeeRatePercentiles) - 1
+		for i, percentile := range bitcoin.BlockFeeRatePercentiles {
+			if percentile >= e.config.Percentile {
+				percentileIndex = i
+				break
+			}
+		}
+	}
+
+	latestBlockHeight, err := e.chain.GetLatestBlockHeight()
+	if err != nil {
+		return 0, false, fmt.Errorf(
+			"cannot get latest block height: [%v]",
+			err,
+		)
+	}
+
+	feeRates := make([]float64, 0, e.config.RecentBlocks)
+	for i := 0; i < e.config.RecentBlocks && uint(i) <= latestBlockHeight; i++ {
+		blockFeeRates, err := source.GetBlockFeeRates(latestBlockHeight - uint(i))
+		if err != nil {
+			return 0, false, fmt.Errorf(
+				"cannot get fee rates of block [%v]: [%v]",
+				latestBlockHeight-uint(i),
+				err,
+			)
+		}
+
+		feeRates = append(
+			feeRates,
+			float64(blockFeeRates.Percentiles[percentileIndex]),
+		)
+	}
+
+	if len(feeRates) == 0 {
+		return 0, false, nil
+	}
+
+	return median(feeRates), true, nil
+}
+
+// median returns the median of the given non-empty list of values.
+func median(values []float64) float64 {
+	sorted := make([]float64, len(values))
+	copy(sorted, values)
+	sort.Float64s(sorted)
+
+	middle := len(sorted) / 2
+	if len(sorted)%2 == 0 {
+		return (sorted[middle-1] + sorted[middle]) / 2
+	}
+
+	return sorted[middle]
+}
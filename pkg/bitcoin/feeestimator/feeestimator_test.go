@@ -0,0 +1,285 @@
+package feeestimator
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/keep-network/keep-core/internal/testutils"
+	"github.com/keep-network/keep-core/pkg/bitcoin"
+)
+
+// stubChain is a bitcoin.Chain stub returning preconfigured results. Calls
+// of methods that are not stubbed panic.
+type stubChain struct {
+	bitcoin.Chain
+
+	satPerVByteFee    int64
+	satPerVByteFeeErr error
+	latestBlockHeight uint
+}
+
+func (sc *stubChain) EstimateSatPerVByteFee(blocks uint32) (int64, error) {
+	return sc.satPerVByteFee, sc.satPerVByteFeeErr
+}
+
+func (sc *stubChain) GetLatestBlockHeight() (uint, error) {
+	return sc.latestBlockHeight, nil
+}
+
+// stubFeeSourcesChain is a stubChain implementing bitcoin.FeeHistogramSource
+// and bitcoin.BlockFeeRatesSource.
+type stubFeeSourcesChain struct {
+	*stubChain
+
+	histogram     []*bitcoin.FeeHistogramBin
+	histogramErr  error
+	blockFeeRates map[uint]*bitcoin.BlockFeeRates
+}
+
+func (sfsc *stubFeeSourcesChain) GetMempoolFeeHistogram() (
+	[]*bitcoin.FeeHistogramBin,
+	error,
+) {
+	return sfsc.histogram, sfsc.histogramErr
+}
+
+func (sfsc *stubFeeSourcesChain) GetBlockFeeRates(
+	blockHeight uint,
+) (*bitcoin.BlockFeeRates, error) {
+	feeRates, ok := sfsc.blockFeeRates[blockHeight]
+	if !ok {
+		return nil, fmt.Errorf("block not found")
+	}
+
+	return feeRates, nil
+}
+
+var testHistogram = []*bitcoin.FeeHistogramBin{
+	{SatPerVByteFee: 50, VirtualSize: 300000},
+	{SatPerVByteFee: 20, VirtualSize: 500000},
+	{SatPerVByteFee: 10, VirtualSize: 700000},
+	{SatPerVByteFee: 2, VirtualSize: 2000000},
+}
+
+var testBlockFeeRates = map[uint]*bitcoin.BlockFeeRates{
+	100: {Height: 100, Percentiles: [5]int64{12, 15, 20, 31, 52}},
+	99:  {Height: 99, Percentiles: [5]int64{8, 11, 18, 25, 40}},
+	98:  {Height: 98, Percentiles: [5]int64{14, 16, 22, 35, 60}},
+}
+
+func TestNew(t *testing.T) {
+	var tests = map[string]struct {
+		config      Config
+		expectedErr bool
+	}{
+		"default mode": {
+			config: Config{},
+		},
+		"deadline mode": {
+			config: Config{Mode: DeadlineMode, RecentBlocks: 6},
+		},
+		"percentile mode": {
+			config: Config{Mode: PercentileMode, Percentile: 50},
+		},
+		"percentile out of range": {
+			config:      Config{Mode: PercentileMode, Percentile: 100},
+			expectedErr: true,
+		},
+		"negative recent blocks": {
+			config:      Config{Mode: DeadlineMode, RecentBlocks: -1},
+			expectedErr: true,
+		},
+		"unsupported mode": {
+			config:      Config{Mode: "unknown"},
+			expectedErr: true,
+		},
+	}
+
+	for testName, test := range tests {
+		t.Run(testName, func(t *testing.T) {
+			_, err := New(&stubChain{}, test.config)
+			if test.expectedErr != (err != nil) {
+				t.Errorf("unexpected error: [%v]", err)
+			}
+		})
+	}
+}
+
+func TestHistogramDeadlineFeeRate(t *testing.T) {
+	var tests = map[string]struct {
+		blocks          uint32
+		expectedFeeRate float64
+	}{
+		"one block": {
+			blocks:          1,
+			expectedFeeRate: 10,
+		},
+		"two blocks": {
+			blocks:          2,
+			expectedFeeRate: 2,
+		},
+		"whole mempool fits": {
+			blocks:          4,
+			expectedFeeRate: 1,
+		},
+	}
+
+	for testName, test := range tests {
+		t.Run(testName, func(t *testing.T) {
+			feeRate := histogramDeadlineFeeRate(testHistogram, test.blocks)
+
+			if test.expectedFeeRate != feeRate {
+				t.Errorf(
+					"unexpected fee rate\nexpected: %v\nactual:   %v\n",
+					test.expectedFeeRate,
+					feeRate,
+				)
+			}
+		})
+	}
+}
+
+func TestHistogramPercentileFeeRate(t *testing.T) {
+	var tests = map[string]struct {
+		percentile      int
+		expectedFeeRate float64
+	}{
+		"10th percentile": {
+			percentile:      10,
+			expectedFeeRate: 10,
+		},
+		"50th percentile": {
+			percentile:      50,
+			expectedFeeRate: 20,
+		},
+		"90th percentile": {
+			percentile:      90,
+			expectedFeeRate: 50,
+		},
+	}
+
+	for testName, test := range tests {
+		t.Run(testName, func(t *testing.T) {
+			feeRate := histogramPercentileFeeRate(
+				testHistogram,
+				1,
+				test.percentile,
+			)
+
+			if test.expectedFeeRate != feeRate {
+				t.Errorf(
+					"unexpected fee rate\nexpected: %v\nactual:   %v\n",
+					test.expectedFeeRate,
+					feeRate,
+				)
+			}
+		})
+	}
+}
+
+func TestEstimator_EstimateSatPerVByteFee(t *testing.T) {
+	var tests = map[string]struct {
+		chain                  bitcoin.Chain
+		config                 Config
+		expectedSatPerVByteFee int64
+		expectedErr            bool
+	}{
+		"node mode": {
+			chain: &stubFeeSourcesChain{
+				stubChain: &stubChain{satPerVByteFee: 30},
+				histogram: testHistogram,
+			},
+			config:                 Config{Mode: NodeMode},
+			expectedSatPerVByteFee: 30,
+		},
+		"deadline mode with all signals": {
+			chain: &stubFeeSourcesChain{
+				stubChain: &stubChain{
+					satPerVByteFee:    30,
+					latestBlockHeight: 100,
+				},
+				histogram:     testHistogram,
+				blockFeeRates: testBlockFeeRates,
+			},
+			config:                 Config{Mode: DeadlineMode, RecentBlocks: 3},
+			expectedSatPerVByteFee: 12,
+		},
+		"percentile mode with all signals": {
+			chain: &stubFeeSourcesChain{
+				stubChain: &stubChain{
+					satPerVByteFee:    30,
+					latestBlockHeight: 100,
+				},
+				histogram:     testHistogram,
+				blockFeeRates: testBlockFeeRates,
+			},
+			// Mempool: 20, recent blocks (75th percentile): 31.
+			config: Config{
+				Mode:         PercentileMode,
+				Percentile:   60,
+				RecentBlocks: 3,
+			},
+			expectedSatPerVByteFee: 30,
+		},
+		"deadline mode with node and mempool signals": {
+			chain: &stubFeeSourcesChain{
+				stubChain: &stubChain{satPerVByteFee: 31},
+				histogram: testHistogram,
+			},
+			config:                 Config{Mode: DeadlineMode},
+			expectedSatPerVByteFee: 21,
+		},
+		"deadline mode with node signal only": {
+			chain:                  &stubChain{satPerVByteFee: 30},
+			config:                 Config{Mode: DeadlineMode, RecentBlocks: 3},
+			expectedSatPerVByteFee: 30,
+		},
+		"deadline mode with failing node": {
+			chain: &stubFeeSourcesChain{
+				stubChain: &stubChain{
+					satPerVByteFeeErr: fmt.Errorf("node error"),
+				},
+				histogram: testHistogram,
+			},
+			config:                 Config{Mode: DeadlineMode},
+			expectedSatPerVByteFee: 10,
+		},
+		"deadline mode with all signals failing": {
+			chain: &stubFeeSourcesChain{
+				stubChain: &stubChain{
+					satPerVByteFeeErr: fmt.Errorf("node error"),
+				},
+				histogramErr: fmt.Errorf("histogram error"),
+			},
+			config:      Config{Mode: DeadlineMode},
+			expectedErr: true,
+		},
+	}
+
+	for testName, test := range tests {
+		t.Run(testName, func(t *testing.T) {
+			estimator, err := New(test.chain, test.config)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			satPerVByteFee, err := estimator.EstimateSatPerVByteFee(1)
+			if test.expectedErr {
+				if err == nil {
+					t.Fatal("expected error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			testutils.AssertIntsEqual(
+				t,
+				"sat/vbyte fee",
+				int(test.expectedSatPerVByteFee),
+				int(satPerVByteFee),
+			)
+		})
+	}
+}
@@ -0,0 +1,804 @@
+package bitcoin
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+)
+
+// psbtMagic is the magic sequence every PSBT starts with. It is the ASCII
+// string `psbt` followed by the 0xff separator.
+var psbtMagic = []byte{0x70, 0x73, 0x62, 0x74, 0xff}
+
+// Key types of the PSBT key-value maps supported by this implementation.
+// For reference, see:
+// https://github.com/bitcoin/bips/blob/master/bip-0174.mediawiki#specification
+const (
+	psbtGlobalUnsignedTxType = 0x00
+
+	psbtInputNonWitnessUtxoType     = 0x00
+	psbtInputWitnessUtxoType        = 0x01
+	psbtInputPartialSigType         = 0x02
+	psbtInputSigHashType            = 0x03
+	psbtInputRedeemScriptType       = 0x04
+	psbtInputWitnessScriptType      = 0x05
+	psbtInputFinalScriptSigType     = 0x07
+	psbtInputFinalScriptWitnessType = 0x08
+
+	psbtOutputRedeemScriptType  = 0x00
+	psbtOutputWitnessScriptType = 0x01
+)
+
+// psbtMaxValueLength is the maximum byte length of a single PSBT value
+// accepted during deserialization. It matches the maximum size of a
+// Bitcoin message.
+const psbtMaxValueLength = wire.MaxMessagePayload
+
+// PSBT represents a Partially Signed Bitcoin Transaction as defined by
+// BIP-0174. Only the key types relevant for transactions built by this
+// package are supported. Records of other types are skipped during
+// deserialization. For reference, see:
+// https://github.com/bitcoin/bips/blob/master/bip-0174.mediawiki
+type PSBT struct {
+	// UnsignedTransaction is the transaction being described by the PSBT.
+	// All inputs of this transaction have empty signature scripts and
+	// witnesses.
+	UnsignedTransaction *Transaction
+	// Inputs holds per-input data. An element at the given index matches
+	// the unsigned transaction input with the same index.
+	Inputs []*PSBTInput
+	// Outputs holds per-output data. An element at the given index matches
+	// the unsigned transaction output with the same index.
+	Outputs []*PSBTOutput
+}
+
+// PSBTInput holds the PSBT data of a single transaction input.
+type PSBTInput struct {
+	// NonWitnessUtxo is the full transaction holding the UTXO spent by
+	// the input. Optional for witness inputs.
+	NonWitnessUtxo *Transaction
+	// WitnessUtxo is the UTXO spent by the input. Set only for witness inputs.
+	WitnessUtxo *TransactionOutput
+	// PartialSignatures holds the signatures collected for the input but
+	// not finalized yet.
+	PartialSignatures []*PSBTPartialSignature
+	// SigHashType is the sighash type that should be used to sign the input.
+	// Zero value means the sighash type is not set.
+	SigHashType uint32
+	// RedeemScript is the plain-text redeem script of a P2SH input.
+	RedeemScript Script
+	// WitnessScript is the plain-text witness script of a P2WSH input.
+	WitnessScript Script
+	// FinalScriptSig is the finalized signature script of the input.
+	FinalScriptSig Script
+	// FinalScriptWitness is the finalized witness of the input.
+	FinalScriptWitness [][]byte
+}
+
+// IsFinalized checks whether the input holds its final signature data.
+func (pi *PSBTInput) IsFinalized() bool {
+	return len(pi.FinalScriptSig) > 0 || len(pi.FinalScriptWitness) > 0
+}
+
+// PSBTPartialSignature holds a signature collected for a PSBT input.
+type PSBTPartialSignature struct {
+	// PublicKey is the serialized public key the signature corresponds to.
+	PublicKey []byte
+	// Signature is the DER-encoded signature with the sighash type byte
+	// appended.
+	Signature []byte
+}
+
+// PSBTOutput holds the PSBT data of a single transaction output.
+type PSBTOutput struct {
+	// RedeemScript is the plain-text redeem script of a P2SH output.
+	RedeemScript Script
+	// WitnessScript is the plain-text witness script of a P2WSH output.
+	WitnessScript Script
+}
+
+// Serialize serializes the PSBT to a byte array according to the BIP-0174
+// binary format.
+func (p *PSBT) Serialize() ([]byte, error) {
+	if p.UnsignedTransaction == nil {
+		return nil, fmt.Errorf("unsigned transaction is required")
+	}
+
+	if len(p.Inputs) != len(p.UnsignedTransaction.Inputs) {
+		return nil, fmt.Errorf("wrong inputs count")
+	}
+
+	if len(p.Outputs) != len(p.UnsignedTransaction.Outputs) {
+		return nil, fmt.Errorf("wrong outputs count")
+	}
+
+	buffer := new(bytes.Buffer)
+	buffer.Write(psbtMagic)
+
+	err := writePsbtRecord(
+		buffer,
+		psbtGlobalUnsignedTxType,
+		nil,
+		p.UnsignedTransaction.Serialize(Standard),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("cannot write unsigned transaction: [%v]", err)
+	}
+	buffer.WriteByte(0x00)
+
+	for i, input := range p.Inputs {
+		if err := input.serialize(buffer); err != nil {
+			return nil, fmt.Errorf("cannot write input [%v]: [%v]", i, err)
+		}
+		buffer.WriteByte(0x00)
+	}
+
+	for i, output := range p.Outputs {
+		if err := output.serialize(buffer); err != nil {
+			return nil, fmt.Errorf("cannot write output [%v]: [%v]", i, err)
+		}
+		buffer.WriteByte(0x00)
+	}
+
+	return buffer.Bytes(), nil
+}
+
+func (pi *PSBTInput) serialize(writer io.Writer) error {
+	if pi.NonWitnessUtxo != nil {
+		err := writePsbtRecord(
+			writer,
+			psbtInputNonWitnessUtxoType,
+			nil,
+			pi.NonWitnessUtxo.Serialize(),
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	if pi.WitnessUtxo != nil {
+		value, err := serializePsbtWitnessUtxo(pi.WitnessUtxo)
+		if err != nil {
+			return err
+		}
+
+		err = writePsbtRecord(writer, psbtInputWitnessUtxoType, nil, value)
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, partialSignature := range pi.PartialSignatures {
+		err := writePsbtRecord(
+			writer,
+			psbtInputPartialSigType,
+			partialSignature.PublicKey,
+			partialSignature.Signature,
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	if pi.SigHashType != 0 {
+		value := make([]byte, 4)
+		binary.LittleEndian.PutUint32(value, pi.SigHashType)
+
+		err := writePsbtRecord(writer, psbtInputSigHashType, nil, value)
+		if err != nil {
+			return err
+		}
+	}
+
+	if len(pi.RedeemScript) > 0 {
+		err := writePsbtRecord(
+			writer,
+			psbtInputRedeemScriptType,
+			nil,
+			pi.RedeemScript,
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	if len(pi.WitnessScript) > 0 {
+		err := writePsbtRecord(
+			writer,
+			psbtInputWitnessScriptType,
+			nil,
+			pi.WitnessScript,
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	if len(pi.FinalScriptSig) > 0 {
+		err := writePsbtRecord(
+			writer,
+			psbtInputFinalScriptSigType,
+			nil,
+			pi.FinalScriptSig,
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	if len(pi.FinalScriptWitness) > 0 {
+		value := new(bytes.Buffer)
+
+		err := wire.WriteVarInt(value, 0, uint64(len(pi.FinalScriptWitness)))
+		if err != nil {
+			return err
+		}
+
+		for _, item := range pi.FinalScriptWitness {
+			if err := wire.WriteVarBytes(value, 0, item); err != nil {
+				return err
+			}
+		}
+
+		err = writePsbtRecord(
+			writer,
+			psbtInputFinalScriptWitnessType,
+			nil,
+			value.Bytes(),
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (po *PSBTOutput) serialize(writer io.Writer) error {
+	if len(po.RedeemScript) > 0 {
+		err := writePsbtRecord(
+			writer,
+			psbtOutputRedeemScriptType,
+			nil,
+			po.RedeemScript,
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	if len(po.WitnessScript) > 0 {
+		err := writePsbtRecord(
+			writer,
+			psbtOutputWitnessScriptType,
+			nil,
+			po.WitnessScript,
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Deserialize deserializes the given byte array, being a PSBT in the BIP-0174
+// binary format, to a PSBT.
+func (p *PSBT) Deserialize(data []byte) error {
+	if !bytes.HasPrefix(data, psbtMagic) {
+		return fmt.Errorf("missing PSBT magic bytes")
+	}
+
+	reader := bytes.NewReader(data[len(psbtMagic):])
+
+	globalRecords, err := readPsbtMap(reader)
+	if err != nil {
+		return fmt.Errorf("cannot read global map: [%v]", err)
+	}
+
+	unsignedTransactionBytes, ok := globalRecords[psbtRecordKey{
+		keyType: psbtGlobalUnsignedTxType,
+	}]
+	if !ok {
+		return fmt.Errorf("missing unsigned transaction")
+	}
+
+	// The unsigned transaction is always serialized in the Standard format.
+	// Deserialize it explicitly without the witness data as the witness
+	// marker would be ambiguous for a transaction without inputs.
+	internal := newInternalTransaction()
+	err = internal.DeserializeNoWitness(
+		bytes.NewReader(unsignedTransactionBytes),
+	)
+	if err != nil {
+		return fmt.Errorf("cannot deserialize unsigned transaction: [%v]", err)
+	}
+
+	unsignedTransaction := internal.toTransaction()
+	for i, input := range unsignedTransaction.Inputs {
+		if len(input.SignatureScript) > 0 || len(input.Witness) > 0 {
+			return fmt.Errorf(
+				"input [%v] of the unsigned transaction is not empty",
+				i,
+			)
+		}
+	}
+
+	inputs := make([]*PSBTInput, len(unsignedTransaction.Inputs))
+	for i := range inputs {
+		records, err := readPsbtMap(reader)
+		if err != nil {
+			return fmt.Errorf("cannot read map of input [%v]: [%v]", i, err)
+		}
+
+		inputs[i], err = newPsbtInput(records)
+		if err != nil {
+			return fmt.Errorf("cannot parse input [%v]: [%v]", i, err)
+		}
+	}
+
+	outputs := make([]*PSBTOutput, len(unsignedTransaction.Outputs))
+	for i := range outputs {
+		records, err := readPsbtMap(reader)
+		if err != nil {
+			return fmt.Errorf("cannot read map of output [%v]: [%v]", i, err)
+		}
+
+		outputs[i] = &PSBTOutput{
+			RedeemScript: records[psbtRecordKey{
+				keyType: psbtOutputRedeemScriptType,
+			}],
+			WitnessScript: records[psbtRecordKey{
+				keyType: psbtOutputWitnessScriptType,
+			}],
+		}
+	}
+
+	p.UnsignedTransaction = unsignedTransaction
+	p.Inputs = inputs
+	p.Outputs = outputs
+
+	return nil
+}
+
+func newPsbtInput(records map[psbtRecordKey][]byte) (*PSBTInput, error) {
+	input := &PSBTInput{}
+
+	for key, value := range records {
+		switch key.keyType {
+		case psbtInputNonWitnessUtxoType:
+			transaction := new(Transaction)
+			if err := transaction.Deserialize(value); err != nil {
+				return nil, fmt.Errorf(
+					"cannot deserialize non-witness UTXO: [%v]",
+					err,
+				)
+			}
+			input.NonWitnessUtxo = transaction
+		case psbtInputWitnessUtxoType:
+			output, err := deserializePsbtWitnessUtxo(value)
+			if err != nil {
+				return nil, fmt.Errorf(
+					"cannot deserialize witness UTXO: [%v]",
+					err,
+				)
+			}
+			input.WitnessUtxo = output
+		case psbtInputPartialSigType:
+			input.PartialSignatures = append(
+				input.PartialSignatures,
+				&PSBTPartialSignature{
+					PublicKey: []byte(key.keyData),
+					Signature: value,
+				},
+			)
+		case psbtInputSigHashType:
+			if len(value) != 4 {
+				return nil, fmt.Errorf("wrong sighash type length")
+			}
+			input.SigHashType = binary.LittleEndian.Uint32(value)
+		case psbtInputRedeemScriptType:
+			input.RedeemScript = value
+		case psbtInputWitnessScriptType:
+			input.WitnessScript = value
+		case psbtInputFinalScriptSigType:
+			input.FinalScriptSig = value
+		case psbtInputFinalScriptWitnessType:
+			reader := bytes.NewReader(value)
+
+			count, err := wire.ReadVarInt(reader, 0)
+			if err != nil {
+				return nil, fmt.Errorf(
+					"cannot read final witness items count: [%v]",
+					err,
+				)
+			}
+
+			witness := make([][]byte, 0)
+			for i := uint64(0); i < count; i++ {
+				item, err := wire.ReadVarBytes(
+					reader,
+					0,
+					psbtMaxValueLength,
+					"witness item",
+				)
+				if err != nil {
+					return nil, fmt.Errorf(
+						"cannot read final witness item [%v]: [%v]",
+						i,
+						err,
+					)
+				}
+				witness = append(witness, item)
+			}
+			input.FinalScriptWitness = witness
+		}
+	}
+
+	// Records are not read in any particular order so make sure partial
+	// signatures are always ordered the same way.
+	sort.Slice(input.PartialSignatures, func(i, j int) bool {
+		return bytes.Compare(
+			input.PartialSignatures[i].PublicKey,
+			input.PartialSignatures[j].PublicKey,
+		) < 0
+	})
+
+	return input, nil
+}
+
+// psbtRecordKey identifies a single record of a PSBT key-value map.
+type psbtRecordKey struct {
+	keyType byte
+	keyData string
+}
+
+// readPsbtMap reads a single PSBT key-value map until its 0x00 separator.
+// Records with duplicated keys are considered an error.
+func readPsbtMap(reader *bytes.Reader) (map[psbtRecordKey][]byte, error) {
+	records := make(map[psbtRecordKey][]byte)
+
+	for {
+		key, err := wire.ReadVarBytes(reader, 0, psbtMaxValueLength, "key")
+		if err != nil {
+			return nil, fmt.Errorf("cannot read key: [%v]", err)
+		}
+
+		// Zero-length key is the map separator.
+		if len(key) == 0 {
+			return records, nil
+		}
+
+		value, err := wire.ReadVarBytes(reader, 0, psbtMaxValueLength, "value")
+		if err != nil {
+			return nil, fmt.Errorf("cannot read value: [%v]", err)
+		}
+
+		recordKey := psbtRecordKey{
+			keyType: key[0],
+			keyData: string(key[1:]),
+		}
+
+		if _, exists := records[recordKey]; exists {
+			return nil, fmt.Errorf("duplicated key of type [%v]", key[0])
+		}
+
+		records[recordKey] = value
+	}
+}
+
+func writePsbtRecord(
+	writer io.Writer,
+	keyType byte,
+	keyData []byte,
+	value []byte,
+) error {
+	key := append([]byte{keyType}, keyData...)
+
+	if err := wire.WriteVarBytes(writer, 0, key); err != nil {
+		return err
+	}
+
+	return wire.WriteVarBytes(writer, 0, value)
+}
+
+func serializePsbtWitnessUtxo(output *TransactionOutput) ([]byte, error) {
+	buffer := new(bytes.Buffer)
+
+	value := make([]byte, 8)
+	binary.LittleEndian.PutUint64(value, uint64(output.Value))
+	buffer.Write(value)
+
+	if err := wire.WriteVarBytes(buffer, 0, output.PublicKeyScript); err != nil {
+		return nil, err
+	}
+
+	return buffer.Bytes(), nil
+}
+
+func deserializePsbtWitnessUtxo(data []byte) (*TransactionOutput, error) {
+	if len(data) < 8 {
+		return nil, fmt.Errorf("wrong witness UTXO length")
+	}
+
+	script, err := NewScriptFromVarLenData(data[8:])
+	if err != nil {
+		return nil, err
+	}
+
+	return &TransactionOutput{
+		Value:           int64(binary.LittleEndian.Uint64(data[:8])),
+		PublicKeyScript: script,
+	}, nil
+}
+
+// ToPSBT describes the transaction held by the builder as a PSBT. If the
+// builder does not hold signatures yet, the returned PSBT contains all data
+// required to sign the transaction, i.e. the spent UTXOs, the sighash type,
+// and the plain-text redeem scripts of P2SH/P2WSH inputs. If signatures
+// were already added, the returned PSBT is finalized, i.e. its inputs
+// contain the final signature scripts and witnesses.
+func (tb *TransactionBuilder) ToPSBT() (*PSBT, error) {
+	unsignedTransaction := tb.internal.toTransaction()
+	for _, input := range unsignedTransaction.Inputs {
+		input.SignatureScript = nil
+		input.Witness = nil
+	}
+
+	inputs := make([]*PSBTInput, len(tb.sigHashArgs))
+	for i, sigHashArgs := range tb.sigHashArgs {
+		previousOutput := tb.previousOutputs[i]
+
+		// The full previous transaction is attached for witness inputs
+		// as well, if known. That allows signers to verify the value of
+		// the spent UTXO. For reference, see:
+		// https://github.com/bitcoin/bips/blob/master/bip-0174.mediawiki#cite_note-7
+		input := &PSBTInput{
+			NonWitnessUtxo: previousOutput.transaction,
+		}
+
+		if sigHashArgs.witness {
+			input.WitnessUtxo = &TransactionOutput{
+				Value:           sigHashArgs.value,
+				PublicKeyScript: previousOutput.script,
+			}
+		} else if previousOutput.transaction == nil {
+			return nil, fmt.Errorf(
+				"previous transaction of non-witness input [%v] is unknown",
+				i,
+			)
+		}
+
+		if tb.signed {
+			// The finalizer role requires all other data to be cleared
+			// from finalized inputs.
+			input.FinalScriptSig = tb.internal.TxIn[i].SignatureScript
+			input.FinalScriptWitness = tb.internal.TxIn[i].Witness
+		} else {
+			input.SigHashType = uint32(txscript.SigHashAll)
+
+			if len(previousOutput.redeemScript) > 0 {
+				if sigHashArgs.witness {
+					input.WitnessScript = previousOutput.redeemScript
+				} else {
+					input.RedeemScript = previousOutput.redeemScript
+				}
+			}
+		}
+
+		inputs[i] = input
+	}
+
+	outputs := make([]*PSBTOutput, len(unsignedTransaction.Outputs))
+	for i := range outputs {
+		outputs[i] = &PSBTOutput{}
+	}
+
+	return &PSBT{
+		UnsignedTransaction: unsignedTransaction,
+		Inputs:              inputs,
+		Outputs:             outputs,
+	}, nil
+}
+
+// NewTransactionBuilderFromPSBT constructs a new TransactionBuilder instance
+// holding the transaction described by the given PSBT. All inputs of the PSBT
+// must spend P2PKH, P2WPKH, P2SH or P2WSH UTXOs. The PSBT must be either
+// not finalized or fully finalized. In the latter case, the builder holds
+// a signed transaction.
+func NewTransactionBuilderFromPSBT(
+	chain Chain,
+	psbt *PSBT,
+) (*TransactionBuilder, error) {
+	if psbt.UnsignedTransaction == nil {
+		return nil, fmt.Errorf("unsigned transaction is required")
+	}
+
+	if len(psbt.Inputs) != len(psbt.UnsignedTransaction.Inputs) {
+		return nil, fmt.Errorf("wrong inputs count")
+	}
+
+	finalizedInputs := 0
+	for _, input := range psbt.Inputs {
+		if input.IsFinalized() {
+			finalizedInputs++
+		}
+	}
+
+	if finalizedInputs != 0 && finalizedInputs != len(psbt.Inputs) {
+		return nil, fmt.Errorf("partially finalized PSBT is not supported")
+	}
+
+	builder := NewTransactionBuilder(chain)
+	builder.internal.fromTransaction(psbt.UnsignedTransaction)
+	builder.signed = finalizedInputs > 0
+
+	for i, input := range psbt.Inputs {
+		internalInput := builder.internal.TxIn[i]
+
+		sigHashArgs, previousOutput, err := newPsbtInputArgs(
+			input,
+			psbt.UnsignedTransaction.Inputs[i].Outpoint,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("invalid input [%v]: [%v]", i, err)
+		}
+
+		// Restore the same state of the internal input as the one produced
+		// by AddPublicKeyHashInput/AddScriptHashInput and AddSignatures.
+		if input.IsFinalized() {
+			internalInput.SignatureScript = input.FinalScriptSig
+			internalInput.Witness = input.FinalScriptWitness
+		} else if len(previousOutput.redeemScript) > 0 {
+			if sigHashArgs.witness {
+				internalInput.Witness = [][]byte{previousOutput.redeemScript}
+			} else {
+				internalInput.SignatureScript = previousOutput.redeemScript
+			}
+		}
+
+		builder.sigHashArgs = append(builder.sigHashArgs, sigHashArgs)
+		builder.previousOutputs = append(builder.previousOutputs, previousOutput)
+	}
+
+	return builder, nil
+}
+
+// newPsbtInputArgs determines the sighash arguments and the previous output
+// data of the given PSBT input spending the UTXO pointed by the given outpoint.
+func newPsbtInputArgs(
+	input *PSBTInput,
+	outpoint *TransactionOutpoint,
+) (*inputSigHashArgs, *inputPreviousOutput, error) {
+	var utxo *TransactionOutput
+
+	if input.NonWitnessUtxo != nil {
+		if input.NonWitnessUtxo.Hash() != outpoint.TransactionHash {
+			return nil, nil, fmt.Errorf(
+				"non-witness UTXO does not match the outpoint",
+			)
+		}
+
+		if int(outpoint.OutputIndex) >= len(input.NonWitnessUtxo.Outputs) {
+			return nil, nil, fmt.Errorf("outpoint index out of range")
+		}
+
+		utxo = input.NonWitnessUtxo.Outputs[outpoint.OutputIndex]
+	}
+
+	if input.WitnessUtxo != nil {
+		if utxo != nil &&
+			(utxo.Value != input.WitnessUtxo.Value ||
+				!bytes.Equal(utxo.PublicKeyScript, input.WitnessUtxo.PublicKeyScript)) {
+			return nil, nil, fmt.Errorf(
+				"witness UTXO does not match the non-witness UTXO",
+			)
+		}
+
+		utxo = input.WitnessUtxo
+	}
+
+	if utxo == nil {
+		return nil, nil, fmt.Errorf("spent UTXO is unknown")
+	}
+
+	previousOutput := &inputPreviousOutput{
+		transaction: input.NonWitnessUtxo,
+		script:      utxo.PublicKeyScript,
+	}
+
+	switch scriptType := GetScriptType(utxo.PublicKeyScript); scriptType {
+	case P2PKHScript:
+		if input.NonWitnessUtxo == nil {
+			return nil, nil, fmt.Errorf("non-witness UTXO is required")
+		}
+
+		return &inputSigHashArgs{
+			value:      utxo.Value,
+			scriptCode: utxo.PublicKeyScript,
+			witness:    false,
+		}, previousOutput, nil
+	case P2WPKHScript:
+		return &inputSigHashArgs{
+			value:      utxo.Value,
+			scriptCode: utxo.PublicKeyScript,
+			witness:    true,
+		}, previousOutput, nil
+	case P2SHScript:
+		if input.NonWitnessUtxo == nil {
+			return nil, nil, fmt.Errorf("non-witness UTXO is required")
+		}
+
+		redeemScript := input.RedeemScript
+		// The redeem script is cleared from finalized inputs but it is
+		// always the last item pushed by the final signature script.
+		if len(redeemScript) == 0 && len(input.FinalScriptSig) > 0 {
+			pushes, err := txscript.PushedData(input.FinalScriptSig)
+			if err != nil {
+				return nil, nil, fmt.Errorf(
+					"cannot parse final signature script: [%v]",
+					err,
+				)
+			}
+
+			if len(pushes) > 0 {
+				redeemScript = pushes[len(pushes)-1]
+			}
+		}
+
+		expectedScript, err := PayToScriptHash(ScriptHash(redeemScript))
+		if err != nil {
+			return nil, nil, err
+		}
+
+		if len(redeemScript) == 0 ||
+			!bytes.Equal(expectedScript, utxo.PublicKeyScript) {
+			return nil, nil, fmt.Errorf("redeem script does not match UTXO")
+		}
+
+		previousOutput.redeemScript = redeemScript
+
+		return &inputSigHashArgs{
+			value:      utxo.Value,
+			scriptCode: redeemScript,
+			witness:    false,
+		}, previousOutput, nil
+	case P2WSHScript:
+		witnessScript := input.WitnessScript
+		// The witness script is cleared from finalized inputs but it is
+		// always the last item of the final witness.
+		if len(witnessScript) == 0 && len(input.FinalScriptWitness) > 0 {
+			witnessScript = input.FinalScriptWitness[len(input.FinalScriptWitness)-1]
+		}
+
+		expectedScript, err := PayToWitnessScriptHash(
+			WitnessScriptHash(witnessScript),
+		)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		if len(witnessScript) == 0 ||
+			!bytes.Equal(expectedScript, utxo.PublicKeyScript) {
+			return nil, nil, fmt.Errorf("witness script does not match UTXO")
+		}
+
+		previousOutput.redeemScript = witnessScript
+
+		return &inputSigHashArgs{
+			value:      utxo.Value,
+			scriptCode: witnessScript,
+			witness:    true,
+		}, previousOutput, nil
+	default:
+		return nil, nil, fmt.Errorf(
+			"unsupported UTXO script type [%v]",
+			scriptType,
+		)
+	}
+}
@@ -0,0 +1,429 @@
+package bitcoin
+
+import (
+	"fmt"
+	"math/big"
+	"reflect"
+	"testing"
+
+	"github.com/keep-network/keep-core/internal/testutils"
+)
+
+// Transactions and signatures used by the tests below come from
+// TestTransactionBuilder_Signing. For reference, see:
+// https://live.blockcypher.com/btc-testnet/tx/435d4aff6d4bc34134877bd3213c17970142fdd04d4113d534120033b9eecb2e
+var psbtTestInputs = []struct {
+	transactionHex  string
+	outputIndex     uint32
+	value           int64
+	redeemScriptHex string
+}{
+	{
+		transactionHex:  "01000000000102bc187be612bc3db8cfcdec56b75e9bc0262ab6eacfe27cc1a699bacd53e3d07400000000c948304502210089a89aaf3fec97ac9ffa91cdff59829f0cb3ef852a468153e2c0e2b473466d2e022072902bb923ef016ac52e941ced78f816bf27991c2b73211e227db27ec200bc0a012103989d253b17a6a0f41838b84ff0d20e8898f9d7b1a98f2564da4cc29dcf8581d94c5c14934b98637ca318a4d6e7ca6ffd1690b8e77df6377508f9f0c90d000395237576a9148db50eb52063ea9d98b3eac91489a90f738986f68763ac6776a914e257eccafbc07c381642ce6e7e55120fb077fbed8804e0250162b175ac68ffffffffdc557e737b6688c5712649b86f7757a722dc3d42786f23b2fa826394dfec545c0000000000ffffffff01488a0000000000001600148db50eb52063ea9d98b3eac91489a90f738986f6000347304402203747f5ee31334b11ebac6a2a156b1584605de8d91a654cd703f9c8438634997402202059d680211776f93c25636266b02e059ed9fcc6209f7d3d9926c49a0d8750ed012103989d253b17a6a0f41838b84ff0d20e8898f9d7b1a98f2564da4cc29dcf8581d95c14934b98637ca318a4d6e7ca6ffd1690b8e77df6377508f9f0c90d000395237576a9148db50eb52063ea9d98b3eac91489a90f738986f68763ac6776a914e257eccafbc07c381642ce6e7e55120fb077fbed8804e0250162b175ac6800000000",
+		outputIndex:     0,
+		value:           35400,
+		redeemScriptHex: "",
+	},
+	{
+		transactionHex:  "01000000000101e37f552fc23fa0032bfd00c8eef5f5c22bf85fe4c6e735857719ff8a4ff66eb80100000000ffffffff02684200000000000017a9143ec459d0f3c29286ae5df5fcc421e2786024277e8742b7100000000000160014e257eccafbc07c381642ce6e7e55120fb077fbed0248304502210084eb60347b9aa48d9a53c6ab0fc2c2357a0df430d193507facfb2238e46f034502202a29d11e128dba3ff3a8ad9a1e820a3b58e89e37fa90d1cc2b3f05207599fef00121039d61d62dcd048d3f8550d22eb90b4af908db60231d117aeede04e7bc11907bfa00000000",
+		outputIndex:     0,
+		value:           17000,
+		redeemScriptHex: "14934b98637ca318a4d6e7ca6ffd1690b8e77df6377508f9f0c90d000395237576a9148db50eb52063ea9d98b3eac91489a90f738986f68763ac6776a914e257eccafbc07c381642ce6e7e55120fb077fbed8804e0250162b175ac68",
+	},
+	{
+		transactionHex:  "01000000000101dc557e737b6688c5712649b86f7757a722dc3d42786f23b2fa826394dfec545c0100000000ffffffff02102700000000000022002086a303cdd2e2eab1d1679f1a813835dc5a1b65321077cdccaf08f98cbf04ca962cff100000000000160014e257eccafbc07c381642ce6e7e55120fb077fbed02473044022050759dde2c84bccf3c1502b0e33a6acb570117fd27a982c0c2991c9f9737508e02201fcba5d6f6c0ab780042138a9110418b3f589d8d09a900f20ee28cfcdb14d2970121039d61d62dcd048d3f8550d22eb90b4af908db60231d117aeede04e7bc11907bfa00000000",
+		outputIndex:     0,
+		value:           10000,
+		redeemScriptHex: "14934b98637ca318a4d6e7ca6ffd1690b8e77df6377508f9f0c90d000395237576a9148db50eb52063ea9d98b3eac91489a90f738986f68763ac6776a914e257eccafbc07c381642ce6e7e55120fb077fbed8804e0250162b175ac68",
+	},
+}
+
+var psbtTestSigHashesHexes = []string{
+	"db0e8c898d3a59a23a70b3d910db720b5942445a24bce2dd96e0488a9de660a9",
+	"0730c379a7c60686255d4730afdf7ce321e83f5e4956346c19956b764a237831",
+	"126b2edd1b3c28dbff6cd48a9eb666558cb59d1008db60bb5f7bbf1a0d45e588",
+}
+
+const psbtTestSignedTransactionHex = "010000000001036896f9abcac13ce6bd2b80d125bedf997ff6330e999f2f605ea15ea542f2eaf80000000000ffffffffed0ae94da996c6f3b89dfe967675d4808251db93e81022ae9e038d06f92efed400000000c948304502210092327ddff69a2b8c7ae787c5d590a2f14586089e6339e942d56e82aa42052cd902204c0d1700ba1ac617da27fee032a57937c9607f0187199ed3c46954df845643d7012103989d253b17a6a0f41838b84ff0d20e8898f9d7b1a98f2564da4cc29dcf8581d94c5c14934b98637ca318a4d6e7ca6ffd1690b8e77df6377508f9f0c90d000395237576a9148db50eb52063ea9d98b3eac91489a90f738986f68763ac6776a914e257eccafbc07c381642ce6e7e55120fb077fbed8804e0250162b175ac68ffffffffe37f552fc23fa0032bfd00c8eef5f5c22bf85fe4c6e735857719ff8a4ff66eb80000000000ffffffff0180ed0000000000001600148db50eb52063ea9d98b3eac91489a90f738986f602483045022100baf754252d0d6a49aceba7eb0ec40b4cc568e8c659e168b96598a11cf56dc078022051117466ee998a3fc72221006817e8cfe9c2e71ad622ff811a0bf100d888d49c012103989d253b17a6a0f41838b84ff0d20e8898f9d7b1a98f2564da4cc29dcf8581d90003473044022014a535eb334656665ac69a678dbf7c019c4f13262e9ea4d195c61a00cd5f698d022023c0062913c4614bdff07f94475ceb4c585df53f71611776c3521ed8f8785913012103989d253b17a6a0f41838b84ff0d20e8898f9d7b1a98f2564da4cc29dcf8581d95c14934b98637ca318a4d6e7ca6ffd1690b8e77df6377508f9f0c90d000395237576a9148db50eb52063ea9d98b3eac91489a90f738986f68763ac6776a914e257eccafbc07c381642ce6e7e55120fb077fbed8804e0250162b175ac6800000000"
+
+func newPsbtTestSignatures(t *testing.T) []*SignatureContainer {
+	publicKey := hexToPublicKet(
+		t,
+		"04989d253b17a6a0f41838b84ff0d20e8898f9d7b1a98f2564da4cc29dcf8581d9d218b65e7d91c752f7b22eaceb771a9af3a6f3d3f010a5d471a1aeef7d7713af",
+	)
+
+	return []*SignatureContainer{
+		{
+			R:         new(big.Int).SetBytes(hexToSlice(t, "baf754252d0d6a49aceba7eb0ec40b4cc568e8c659e168b96598a11cf56dc078")),
+			S:         new(big.Int).SetBytes(hexToSlice(t, "51117466ee998a3fc72221006817e8cfe9c2e71ad622ff811a0bf100d888d49c")),
+			PublicKey: publicKey,
+		},
+		{
+			R:         new(big.Int).SetBytes(hexToSlice(t, "92327ddff69a2b8c7ae787c5d590a2f14586089e6339e942d56e82aa42052cd9")),
+			S:         new(big.Int).SetBytes(hexToSlice(t, "4c0d1700ba1ac617da27fee032a57937c9607f0187199ed3c46954df845643d7")),
+			PublicKey: publicKey,
+		},
+		{
+			R:         new(big.Int).SetBytes(hexToSlice(t, "14a535eb334656665ac69a678dbf7c019c4f13262e9ea4d195c61a00cd5f698d")),
+			S:         new(big.Int).SetBytes(hexToSlice(t, "23c0062913c4614bdff07f94475ceb4c585df53f71611776c3521ed8f8785913")),
+			PublicKey: publicKey,
+		},
+	}
+}
+
+func newPsbtTestBuilder(t *testing.T) *TransactionBuilder {
+	localChain := newLocalChain()
+	builder := NewTransactionBuilder(localChain)
+
+	for _, input := range psbtTestInputs {
+		inputTransaction := transactionFrom(t, input.transactionHex)
+
+		err := localChain.addTransaction(inputTransaction)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		utxo := &UnspentTransactionOutput{
+			Outpoint: &TransactionOutpoint{
+				TransactionHash: inputTransaction.Hash(),
+				OutputIndex:     input.outputIndex,
+			},
+			Value: input.value,
+		}
+
+		if len(input.redeemScriptHex) > 0 {
+			err = builder.AddScriptHashInput(
+				utxo,
+				hexToSlice(t, input.redeemScriptHex),
+			)
+		} else {
+			err = builder.AddPublicKeyHashInput(utxo)
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	builder.AddOutput(&TransactionOutput{
+		Value:           60800,
+		PublicKeyScript: hexToSlice(t, "00148db50eb52063ea9d98b3eac91489a90f738986f6"),
+	})
+
+	return builder
+}
+
+func TestTransactionBuilder_ToPSBT_Unsigned(t *testing.T) {
+	builder := newPsbtTestBuilder(t)
+
+	psbt, err := builder.ToPSBT()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testutils.AssertIntsEqual(t, "inputs count", 3, len(psbt.Inputs))
+	testutils.AssertIntsEqual(t, "outputs count", 1, len(psbt.Outputs))
+
+	for i, input := range psbt.UnsignedTransaction.Inputs {
+		if len(input.SignatureScript) != 0 || len(input.Witness) != 0 {
+			t.Errorf("unsigned transaction input [%v] is not empty", i)
+		}
+	}
+
+	for i, input := range psbt.Inputs {
+		previousTransaction := transactionFrom(t, psbtTestInputs[i].transactionHex)
+
+		if input.NonWitnessUtxo == nil ||
+			input.NonWitnessUtxo.Hash() != previousTransaction.Hash() {
+			t.Errorf("unexpected non-witness UTXO of input [%v]", i)
+		}
+
+		testutils.AssertIntsEqual(
+			t,
+			fmt.Sprintf("sighash type of input [%v]", i),
+			1,
+			int(input.SigHashType),
+		)
+
+		testutils.AssertBoolsEqual(
+			t,
+			fmt.Sprintf("finalized flag of input [%v]", i),
+			false,
+			input.IsFinalized(),
+		)
+	}
+
+	// P2WPKH input.
+	if psbt.Inputs[0].WitnessUtxo == nil {
+		t.Fatal("missing witness UTXO of input [0]")
+	}
+	testutils.AssertIntsEqual(
+		t,
+		"witness UTXO value of input [0]",
+		35400,
+		int(psbt.Inputs[0].WitnessUtxo.Value),
+	)
+	testutils.AssertBytesEqual(
+		t,
+		hexToSlice(t, "00148db50eb52063ea9d98b3eac91489a90f738986f6"),
+		psbt.Inputs[0].WitnessUtxo.PublicKeyScript,
+	)
+
+	// P2SH input.
+	if psbt.Inputs[1].WitnessUtxo != nil {
+		t.Error("unexpected witness UTXO of input [1]")
+	}
+	testutils.AssertBytesEqual(
+		t,
+		hexToSlice(t, psbtTestInputs[1].redeemScriptHex),
+		psbt.Inputs[1].RedeemScript,
+	)
+
+	// P2WSH input.
+	testutils.AssertBytesEqual(
+		t,
+		hexToSlice(t, psbtTestInputs[2].redeemScriptHex),
+		psbt.Inputs[2].WitnessScript,
+	)
+}
+
+func TestTransactionBuilder_ToPSBT_Signed(t *testing.T) {
+	builder := newPsbtTestBuilder(t)
+
+	if _, err := builder.ComputeSignatureHashes(); err != nil {
+		t.Fatal(err)
+	}
+
+	signedTransaction, err := builder.AddSignatures(newPsbtTestSignatures(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	psbt, err := builder.ToPSBT()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i, input := range psbt.Inputs {
+		testutils.AssertBoolsEqual(
+			t,
+			fmt.Sprintf("finalized flag of input [%v]", i),
+			true,
+			input.IsFinalized(),
+		)
+
+		if input.SigHashType != 0 ||
+			len(input.RedeemScript) != 0 ||
+			len(input.WitnessScript) != 0 {
+			t.Errorf("finalized input [%v] was not cleared", i)
+		}
+
+		signedInput := signedTransaction.Inputs[i]
+
+		testutils.AssertBytesEqual(
+			t,
+			signedInput.SignatureScript,
+			input.FinalScriptSig,
+		)
+
+		if !reflect.DeepEqual(signedInput.Witness, input.FinalScriptWitness) {
+			t.Errorf("unexpected final witness of input [%v]", i)
+		}
+	}
+
+	if !reflect.DeepEqual(
+		signedTransaction.Outputs,
+		psbt.UnsignedTransaction.Outputs,
+	) {
+		t.Errorf("unexpected outputs of the unsigned transaction")
+	}
+}
+
+func TestPSBT_SerializeDeserialize(t *testing.T) {
+	unsignedBuilder := newPsbtTestBuilder(t)
+
+	signedBuilder := newPsbtTestBuilder(t)
+	if _, err := signedBuilder.ComputeSignatureHashes(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := signedBuilder.AddSignatures(newPsbtTestSignatures(t)); err != nil {
+		t.Fatal(err)
+	}
+
+	var tests = map[string]struct {
+		builder *TransactionBuilder
+	}{
+		"unsigned transaction": {
+			builder: unsignedBuilder,
+		},
+		"signed transaction": {
+			builder: signedBuilder,
+		},
+	}
+
+	for testName, test := range tests {
+		t.Run(testName, func(t *testing.T) {
+			psbt, err := test.builder.ToPSBT()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			serialized, err := psbt.Serialize()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			testutils.AssertBytesEqual(t, psbtMagic, serialized[:len(psbtMagic)])
+
+			deserialized := new(PSBT)
+			if err := deserialized.Deserialize(serialized); err != nil {
+				t.Fatal(err)
+			}
+
+			reserialized, err := deserialized.Serialize()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			testutils.AssertBytesEqual(t, serialized, reserialized)
+		})
+	}
+}
+
+func TestPSBT_Deserialize_Errors(t *testing.T) {
+	psbt, err := newPsbtTestBuilder(t).ToPSBT()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	serialized, err := psbt.Serialize()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var tests = map[string]struct {
+		data        []byte
+		expectedErr error
+	}{
+		"missing magic bytes": {
+			data:        serialized[1:],
+			expectedErr: fmt.Errorf("missing PSBT magic bytes"),
+		},
+		"missing unsigned transaction": {
+			data:        append(append([]byte{}, psbtMagic...), 0x00),
+			expectedErr: fmt.Errorf("missing unsigned transaction"),
+		},
+	}
+
+	for testName, test := range tests {
+		t.Run(testName, func(t *testing.T) {
+			err := new(PSBT).Deserialize(test.data)
+
+			if !reflect.DeepEqual(test.expectedErr, err) {
+				t.Errorf(
+					"unexpected error\nexpected: %+v\nactual:   %+v\n",
+					test.expectedErr,
+					err,
+				)
+			}
+		})
+	}
+}
+
+func TestNewTransactionBuilderFromPSBT(t *testing.T) {
+	psbt, err := newPsbtTestBuilder(t).ToPSBT()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	serialized, err := psbt.Serialize()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	deserialized := new(PSBT)
+	if err := deserialized.Deserialize(serialized); err != nil {
+		t.Fatal(err)
+	}
+
+	builder, err := NewTransactionBuilderFromPSBT(newLocalChain(), deserialized)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sigHashes, err := builder.ComputeSignatureHashes()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testutils.AssertIntsEqual(
+		t,
+		"sighashes count",
+		len(psbtTestSigHashesHexes),
+		len(sigHashes),
+	)
+
+	for i, sigHashHex := range psbtTestSigHashesHexes {
+		testutils.AssertBigIntsEqual(
+			t,
+			fmt.Sprintf("sighash for input [%v]", i),
+			new(big.Int).SetBytes(hexToSlice(t, sigHashHex)),
+			sigHashes[i],
+		)
+	}
+
+	testutils.AssertIntsEqual(
+		t,
+		"total inputs value",
+		62400,
+		int(builder.TotalInputsValue()),
+	)
+
+	transaction, err := builder.AddSignatures(newPsbtTestSignatures(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testutils.AssertBytesEqual(
+		t,
+		hexToSlice(t, psbtTestSignedTransactionHex),
+		transaction.Serialize(),
+	)
+
+	// Export the signed transaction and load it once again. The loaded
+	// builder must hold the same signed transaction.
+	signedPsbt, err := builder.ToPSBT()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	signedBuilder, err := NewTransactionBuilderFromPSBT(newLocalChain(), signedPsbt)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testutils.AssertBytesEqual(
+		t,
+		hexToSlice(t, psbtTestSignedTransactionHex),
+		signedBuilder.internal.toTransaction().Serialize(),
+	)
+}
+
+func TestNewTransactionBuilderFromPSBT_PartiallyFinalized(t *testing.T) {
+	psbt, err := newPsbtTestBuilder(t).ToPSBT()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	psbt.Inputs[0].FinalScriptWitness = [][]byte{{0x01}}
+
+	_, err = NewTransactionBuilderFromPSBT(newLocalChain(), psbt)
+
+	expectedErr := fmt.Errorf("partially finalized PSBT is not supported")
+	if !reflect.DeepEqual(expectedErr, err) {
+		t.Errorf(
+			"unexpected error\nexpected: %+v\nactual:   %+v\n",
+			expectedErr,
+			err,
+		)
+	}
+}
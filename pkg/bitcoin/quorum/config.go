@@ -0,0 +1,35 @@
+package quorum
+
+import (
+	"github.com/keep-network/keep-core/pkg/bitcoin/bitcoind"
+	"github.com/keep-network/keep-core/pkg/bitcoin/electrum"
+	"github.com/keep-network/keep-core/pkg/bitcoin/esplora"
+)
+
+// Config holds configurable properties of the quorum-verified Bitcoin chain.
+type Config struct {
+	// Backends is the list of Bitcoin chain backends queried by the
+	// quorum-verified chain.
+	Backends []BackendConfig
+	// Threshold is the minimum number of backends that must agree on
+	// a result for it to be accepted. If zero, a simple majority of
+	// the configured backends is required.
+	Threshold int
+}
+
+// BackendConfig holds configurable properties of a single Bitcoin chain
+// backend queried by the quorum-verified chain. Multiple backends of the
+// same type can be configured as long as their names differ.
+type BackendConfig struct {
+	// Name uniquely identifies the backend among all quorum backends.
+	Name string
+	// Type is the type of the backend, one of `electrum`, `bitcoind`
+	// or `esplora`. Only the connection config of the given type is used.
+	Type string
+	// Electrum defines the configuration for the Electrum client.
+	Electrum electrum.Config
+	// Bitcoind defines the configuration for the bitcoind JSON-RPC client.
+	Bitcoind bitcoind.Config
+	// Esplora defines the configuration for the Esplora REST client.
+	Esplora esplora.Config
+}
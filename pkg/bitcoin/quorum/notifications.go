@@ -0,0 +1,126 @@
+package quorum
+
+import (
+	"sync"
+
+	"github.com/keep-network/keep-core/pkg/bitcoin"
+	"github.com/keep-network/keep-core/pkg/subscription"
+)
+
+// OnNewBlockHeader registers a callback that is invoked when a new block
+// header becomes the tip of the Bitcoin chain. Only backends implementing
+// bitcoin.ChainNotifier are subscribed. A block header is delivered once
+// at least a quorum of them reported it and only if it is higher than the
+// last delivered one.
+func (c *Chain) OnNewBlockHeader(
+	handler func(event *bitcoin.NewBlockHeaderEvent),
+) subscription.EventSubscription {
+	type headerKey struct {
+		height uint
+		hash   bitcoin.Hash
+	}
+
+	mutex := sync.Mutex{}
+	votes := make(map[headerKey]map[string]bool)
+	deliveredHeight := uint(0)
+	delivered := false
+
+	onBackendHeader := func(
+		b *backend,
+		event *bitcoin.NewBlockHeaderEvent,
+	) {
+		mutex.Lock()
+
+		if delivered && event.Height <= deliveredHeight {
+			mutex.Unlock()
+			return
+		}
+
+		key := headerKey{event.Height, event.Header.Hash()}
+		if votes[key] == nil {
+			votes[key] = make(map[string]bool)
+		}
+		votes[key][b.Name] = true
+
+		if len(votes[key]) < c.threshold {
+			mutex.Unlock()
+			return
+		}
+
+		delivered = true
+		deliveredHeight = event.Height
+
+		for k := range votes {
+			if k.height <= deliveredHeight {
+				delete(votes, k)
+			}
+		}
+
+		mutex.Unlock()
+
+		handler(event)
+	}
+
+	subscriptions := make([]subscription.EventSubscription, 0)
+	for _, b := range supportingBackends[bitcoin.ChainNotifier](c) {
+		subscriptions = append(
+			subscriptions,
+			b.Chain.(bitcoin.ChainNotifier).OnNewBlockHeader(
+				func(event *bitcoin.NewBlockHeaderEvent) {
+					onBackendHeader(b, event)
+				},
+			),
+		)
+	}
+
+	return unsubscribeAll(subscriptions)
+}
+
+// OnScriptStatusChanged registers a callback that is invoked when the
+// status of the given script changes. Only backends implementing
+// bitcoin.ChainNotifier are subscribed. Script status notifications are
+// meant to be used only as a hint to query the chain earlier so a status is
+// delivered as soon as any backend reports it. Each status is delivered
+// only once even if reported by multiple backends.
+func (c *Chain) OnScriptStatusChanged(
+	script bitcoin.Script,
+	handler func(event *bitcoin.ScriptStatusChangedEvent),
+) subscription.EventSubscription {
+	mutex := sync.Mutex{}
+	deliveredStatuses := make(map[string]bool)
+
+	onBackendStatus := func(event *bitcoin.ScriptStatusChangedEvent) {
+		mutex.Lock()
+		if deliveredStatuses[event.Status] {
+			mutex.Unlock()
+			return
+		}
+		deliveredStatuses[event.Status] = true
+		mutex.Unlock()
+
+		handler(event)
+	}
+
+	subscriptions := make([]subscription.EventSubscription, 0)
+	for _, b := range supportingBackends[bitcoin.ChainNotifier](c) {
+		subscriptions = append(
+			subscriptions,
+			b.Chain.(bitcoin.ChainNotifier).OnScriptStatusChanged(
+				script,
+				onBackendStatus,
+			),
+		)
+	}
+
+	return unsubscribeAll(subscriptions)
+}
+
+func unsubscribeAll(
+	subscriptions []subscription.EventSubscription,
+) subscription.EventSubscription {
+	return subscription.NewEventSubscription(func() {
+		for _, s := range subscriptions {
+			s.Unsubscribe()
+		}
+	})
+}
@@ -0,0 +1,744 @@
+// Package quorum provides a bitcoin.Chain implementation that fans each call
+// out to multiple Bitcoin chain backends and accepts a result only if
+// a configured quorum of backends agrees on it. This way, the client does not
+// have to trust a single, possibly third-party, backend operator.
+package quorum
+
+import (
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/ipfs/go-log"
+
+	"github.com/keep-network/keep-core/pkg/bitcoin"
+)
+
+var logger = log.Logger("keep-bitcoin-quorum")
+
+// Backend is a named Bitcoin chain backend queried by the quorum-verified
+// chain.
+type Backend struct {
+	Name  string
+	Chain bitcoin.Chain
+}
+
+// backend wraps a Backend and keeps track of its misbehavior.
+type backend struct {
+	Backend
+
+	disagreements atomic.Uint64
+	failures      atomic.Uint64
+}
+
+// Chain is a bitcoin.Chain implementation querying multiple backends and
+// accepting only results a quorum of backends agrees on. Chain also implements
+// bitcoin.ChainNotifier, bitcoin.FeeHistogramSource and
+// bitcoin.BlockFeeRatesSource using backends implementing those interfaces.
+type Chain struct {
+	backends  []*backend
+	threshold int
+}
+
+// New creates a new quorum-verified Bitcoin chain using the given backends.
+// The threshold determines the minimum number of backends that must agree
+// on a result. If the threshold is zero, a simple majority of backends is
+// required.
+func New(backends []Backend, threshold int) (*Chain, error) {
+	if len(backends) == 0 {
+		return nil, fmt.Errorf("at least one backend is required")
+	}
+
+	if threshold == 0 {
+		threshold = len(backends)/2 + 1
+	}
+
+	if threshold < 0 || threshold > len(backends) {
+		return nil, fmt.Errorf(
+			"threshold [%v] must be between 1 and the number of backends [%v]",
+			threshold,
+			len(backends),
+		)
+	}
+
+	names := make(map[string]bool)
+	wrapped := make([]*backend, len(backends))
+	for i, b := range backends {
+		if names[b.Name] {
+			return nil, fmt.Errorf("duplicated backend name [%s]", b.Name)
+		}
+		names[b.Name] = true
+
+		wrapped[i] = &backend{Backend: b}
+	}
+
+	return &Chain{
+		backends:  wrapped,
+		threshold: threshold,
+	}, nil
+}
+
+// BackendNames returns names of all backends, in the order they were
+// configured.
+func (c *Chain) BackendNames() []string {
+	names := make([]string, len(c.backends))
+	for i, b := range c.backends {
+		names[i] = b.Name
+	}
+	return names
+}
+
+// Disagreements returns the number of times the given backend returned
+// a result that differed from the one agreed by the quorum.
+func (c *Chain) Disagreements(backendName string) uint64 {
+	for _, b := range c.backends {
+		if b.Name == backendName {
+			return b.disagreements.Load()
+		}
+	}
+	return 0
+}
+
+// Failures returns the number of times the given backend failed to return
+// a result.
+func (c *Chain) Failures(backendName string) uint64 {
+	for _, b := range c.backends {
+		if b.Name == backendName {
+			return b.failures.Load()
+		}
+	}
+	return 0
+}
+
+// GetTransaction gets the transaction with the given transaction hash.
+// If the transaction with the given hash was not found on the chain,
+// this function returns an error.
+func (c *Chain) GetTransaction(
+	transactionHash bitcoin.Hash,
+) (*bitcoin.Transaction, error) {
+	return agree(
+		c,
+		fmt.Sprintf(
+			"GetTransaction(%s)",
+			transactionHash.Hex(bitcoin.ReversedByteOrder),
+		),
+		func(chain bitcoin.Chain) (*bitcoin.Transaction, error) {
+			return chain.GetTransaction(transactionHash)
+		},
+		transactionFingerprint,
+	)
+}
+
+// GetTransactionConfirmations gets the number of confirmations for the
+// transaction with the given transaction hash. If the transaction with the
+// given hash was not found on the chain, this function returns an error.
+// Backends may be a few blocks apart so the returned value is the highest
+// number of confirmations reported by at least a quorum of backends.
+func (c *Chain) GetTransactionConfirmations(
+	transactionHash bitcoin.Hash,
+) (uint, error) {
+	return atLeast(
+		c,
+		fmt.Sprintf(
+			"GetTransactionConfirmations(%s)",
+			transactionHash.Hex(bitcoin.ReversedByteOrder),
+		),
+		func(chain bitcoin.Chain) (uint, error) {
+			return chain.GetTransactionConfirmations(transactionHash)
+		},
+	)
+}
+
+// BroadcastTransaction broadcasts the given transaction using all backends.
+// The broadcast is considered successful if at least one backend accepted
+// the transaction as the transaction propagates over the network anyway.
+func (c *Chain) BroadcastTransaction(transaction *bitcoin.Transaction) error {
+	method := fmt.Sprintf(
+		"BroadcastTransaction(%s)",
+		transaction.Hash().Hex(bitcoin.ReversedByteOrder),
+	)
+
+	responses := query(
+		c,
+		method,
+		func(chain bitcoin.Chain) (struct{}, error) {
+			return struct{}{}, chain.BroadcastTransaction(transaction)
+		},
+	)
+
+	errs := make([]string, 0)
+	for _, response := range responses {
+		if response.err == nil {
+			return nil
+		}
+
+		errs = append(
+			errs,
+			fmt.Sprintf("%s: %v", response.backend.Name, response.err),
+		)
+	}
+
+	return fmt.Errorf(
+		"no backend accepted the transaction: [%s]",
+		strings.Join(errs, "; "),
+	)
+}
+
+// GetLatestBlockHeight gets the height of the latest block (tip). Backends
+// may be a few blocks apart so the returned value is the highest block height
+// reported by at least a quorum of backends.
+func (c *Chain) GetLatestBlockHeight() (uint, error) {
+	return atLeast(
+		c,
+		"GetLatestBlockHeight()",
+		func(chain bitcoin.Chain) (uint, error) {
+			return chain.GetLatestBlockHeight()
+		},
+	)
+}
+
+// GetBlockHeader gets the block header for the given block height. If the
+// block with the given height was not found on the chain, this function
+// returns an error.
+func (c *Chain) GetBlockHeader(
+	blockHeight uint,
+) (*bitcoin.BlockHeader, error) {
+	return agree(
+		c,
+		fmt.Sprintf("GetBlockHeader(%v)", blockHeight),
+		func(chain bitcoin.Chain) (*bitcoin.BlockHeader, error) {
+			return chain.GetBlockHeader(blockHeight)
+		},
+		func(blockHeader *bitcoin.BlockHeader) string {
+			serialized := blockHeader.Serialize()
+			return hex.EncodeToString(serialized[:])
+		},
+	)
+}
+
+// GetTransactionMerkleProof gets the Merkle proof for a given transaction.
+// The transaction's hash and the block the transaction was included in the
+// blockchain need to be provided.
+func (c *Chain) GetTransactionMerkleProof(
+	transactionHash bitcoin.Hash,
+	blockHeight uint,
+) (*bitcoin.TransactionMerkleProof, error) {
+	return agree(
+		c,
+		fmt.Sprintf(
+			"GetTransactionMerkleProof(%s, %v)",
+			transactionHash.Hex(bitcoin.ReversedByteOrder),
+			blockHeight,
+		),
+		func(chain bitcoin.Chain) (*bitcoin.TransactionMerkleProof, error) {
+			return chain.GetTransactionMerkleProof(transactionHash, blockHeight)
+		},
+		func(merkleProof *bitcoin.TransactionMerkleProof) string {
+			return fmt.Sprintf(
+				"%v:%v:%s",
+				merkleProof.BlockHeight,
+				merkleProof.Position,
+				strings.Join(merkleProof.MerkleNodes, ","),
+			)
+		},
+	)
+}
+
+// GetTransactionsForPublicKeyHash gets the confirmed transactions that pays the
+// given public key hash using either a P2PKH or P2WPKH script. The returned
+// transactions are ordered by block height in the ascending order, i.e.
+// the latest transaction is at the end of the list. The returned list does
+// not contain unconfirmed transactions living in the mempool at the moment
+// of request. The returned transactions list can be limited using the
+// `limit` parameter.
+func (c *Chain) GetTransactionsForPublicKeyHash(
+	publicKeyHash [20]byte,
+	limit int,
+) ([]*bitcoin.Transaction, error) {
+	return agree(
+		c,
+		fmt.Sprintf(
+			"GetTransactionsForPublicKeyHash(%x, %v)",
+			publicKeyHash,
+			limit,
+		),
+		func(chain bitcoin.Chain) ([]*bitcoin.Transaction, error) {
+			return chain.GetTransactionsForPublicKeyHash(publicKeyHash, limit)
+		},
+		transactionsFingerprint,
+	)
+}
+
+// GetTxHashesForPublicKeyHash gets hashes of confirmed transactions that pays
+// the given public key hash using either a P2PKH or P2WPKH script. The returned
+// transactions hashes are ordered by block height in the ascending order, i.e.
+// the latest transaction hash is at the end of the list. The returned list does
+// not contain unconfirmed transactions hashes living in the mempool at the
+// moment of request.
+func (c *Chain) GetTxHashesForPublicKeyHash(
+	publicKeyHash [20]byte,
+) ([]bitcoin.Hash, error) {
+	return agree(
+		c,
+		fmt.Sprintf("GetTxHashesForPublicKeyHash(%x)", publicKeyHash),
+		func(chain bitcoin.Chain) ([]bitcoin.Hash, error) {
+			return chain.GetTxHashesForPublicKeyHash(publicKeyHash)
+		},
+		func(hashes []bitcoin.Hash) string {
+			items := make([]string, len(hashes))
+			for i, hash := range hashes {
+				items[i] = hash.String()
+			}
+			return sortedFingerprint(items)
+		},
+	)
+}
+
+// GetMempoolForPublicKeyHash gets the unconfirmed mempool transactions
+// that pays the given public key hash using either a P2PKH or P2WPKH script.
+// The returned transactions are in an indefinite order.
+func (c *Chain) GetMempoolForPublicKeyHash(
+	publicKeyHash [20]byte,
+) ([]*bitcoin.Transaction, error) {
+	return agree(
+		c,
+		fmt.Sprintf("GetMempoolForPublicKeyHash(%x)", publicKeyHash),
+		func(chain bitcoin.Chain) ([]*bitcoin.Transaction, error) {
+			return chain.GetMempoolForPublicKeyHash(publicKeyHash)
+		},
+		transactionsFingerprint,
+	)
+}
+
+// GetUtxosForPublicKeyHash gets unspent outputs of confirmed transactions that
+// are controlled by the given public key hash (either a P2PKH or P2WPKH script).
+// The returned UTXOs are ordered by block height in the ascending order, i.e.
+// the latest UTXO is at the end of the list.
+func (c *Chain) GetUtxosForPublicKeyHash(
+	publicKeyHash [20]byte,
+) ([]*bitcoin.UnspentTransactionOutput, error) {
+	return agree(
+		c,
+		fmt.Sprintf("GetUtxosForPublicKeyHash(%x)", publicKeyHash),
+		func(chain bitcoin.Chain) ([]*bitcoin.UnspentTransactionOutput, error) {
+			return chain.GetUtxosForPublicKeyHash(publicKeyHash)
+		},
+		utxosFingerprint,
+	)
+}
+
+// GetMempoolUtxosForPublicKeyHash gets unspent outputs of unconfirmed
+// transactions that are controlled by the given public key hash (either
+// a P2PKH or P2WPKH script). The returned UTXOs are in an indefinite order.
+func (c *Chain) GetMempoolUtxosForPublicKeyHash(
+	publicKeyHash [20]byte,
+) ([]*bitcoin.UnspentTransactionOutput, error) {
+	return agree(
+		c,
+		fmt.Sprintf("GetMempoolUtxosForPublicKeyHash(%x)", publicKeyHash),
+		func(chain bitcoin.Chain) ([]*bitcoin.UnspentTransactionOutput, error) {
+			return chain.GetMempoolUtxosForPublicKeyHash(publicKeyHash)
+		},
+		utxosFingerprint,
+	)
+}
+
+// EstimateSatPerVByteFee returns the estimated sat/vbyte fee for a
+// transaction to be confirmed within the given number of blocks. Fee
+// estimates naturally differ between backends so the returned value is
+// the median of estimates returned by at least a quorum of backends.
+func (c *Chain) EstimateSatPerVByteFee(blocks uint32) (int64, error) {
+	method := fmt.Sprintf("EstimateSatPerVByteFee(%v)", blocks)
+
+	responses := query(
+		c,
+		method,
+		func(chain bitcoin.Chain) (int64, error) {
+			return chain.EstimateSatPerVByteFee(blocks)
+		},
+	)
+
+	fees := make([]int64, 0)
+	for _, response := range responses {
+		if response.err == nil {
+			fees = append(fees, response.result)
+		}
+	}
+
+	if len(fees) < c.threshold {
+		return 0, fmt.Errorf(
+			"quorum not reached for [%s]; [%v] backends responded "+
+				"but [%v] are required",
+			method,
+			len(fees),
+			c.threshold,
+		)
+	}
+
+	sort.Slice(fees, func(i, j int) bool { return fees[i] < fees[j] })
+
+	return fees[len(fees)/2], nil
+}
+
+// GetCoinbaseTxHash gets the hash of the coinbase transaction for the given
+// block height.
+func (c *Chain) GetCoinbaseTxHash(blockHeight uint) (bitcoin.Hash, error) {
+	return agree(
+		c,
+		fmt.Sprintf("GetCoinbaseTxHash(%v)", blockHeight),
+		func(chain bitcoin.Chain) (bitcoin.Hash, error) {
+			return chain.GetCoinbaseTxHash(blockHeight)
+		},
+		func(hash bitcoin.Hash) string {
+			return hash.String()
+		},
+	)
+}
+
+// GetMempoolFeeHistogram returns the histogram of fee rates paid by mempool
+// transactions, weighted by their virtual size. Only backends implementing
+// bitcoin.FeeHistogramSource are queried and at least a quorum of them must
+// respond. Mempools naturally differ between backends so the returned
+// histogram is the one with the median total virtual size.
+func (c *Chain) GetMempoolFeeHistogram() ([]*bitcoin.FeeHistogramBin, error) {
+	method := "GetMempoolFeeHistogram()"
+
+	responses := querySupporting(
+		c,
+		method,
+		func(source bitcoin.FeeHistogramSource) (
+			[]*bitcoin.FeeHistogramBin,
+			error,
+		) {
+			return source.GetMempoolFeeHistogram()
+		},
+	)
+
+	histograms := make([][]*bitcoin.FeeHistogramBin, 0)
+	for _, response := range responses {
+		if response.err == nil {
+			histograms = append(histograms, response.result)
+		}
+	}
+
+	if len(histograms) < c.threshold {
+		return nil, fmt.Errorf(
+			"quorum not reached for [%s]; [%v] backends responded "+
+				"but [%v] are required",
+			method,
+			len(histograms),
+			c.threshold,
+		)
+	}
+
+	totalVirtualSize := func(histogram []*bitcoin.FeeHistogramBin) int64 {
+		total := int64(0)
+		for _, bin := range histogram {
+			total += bin.VirtualSize
+		}
+		return total
+	}
+
+	sort.SliceStable(histograms, func(i, j int) bool {
+		return totalVirtualSize(histograms[i]) < totalVirtualSize(histograms[j])
+	})
+
+	return histograms[len(histograms)/2], nil
+}
+
+// GetBlockFeeRates returns fee rates paid by transactions included in the
+// block with the given height. Only backends implementing
+// bitcoin.BlockFeeRatesSource are queried and at least a quorum of them must
+// respond. Backends may compute percentiles slightly differently so each
+// returned percentile is the median of percentiles returned by backends.
+func (c *Chain) GetBlockFeeRates(
+	blockHeight uint,
+) (*bitcoin.BlockFeeRates, error) {
+	method := fmt.Sprintf("GetBlockFeeRates(%v)", blockHeight)
+
+	responses := querySupporting(
+		c,
+		method,
+		func(source bitcoin.BlockFeeRatesSource) (*bitcoin.BlockFeeRates, error) {
+			return source.GetBlockFeeRates(blockHeight)
+		},
+	)
+
+	blockFeeRates := make([]*bitcoin.BlockFeeRates, 0)
+	for _, response := range responses {
+		if response.err == nil {
+			blockFeeRates = append(blockFeeRates, response.result)
+		}
+	}
+
+	if len(blockFeeRates) < c.threshold {
+		return nil, fmt.Errorf(
+			"quorum not reached for [%s]; [%v] backends responded "+
+				"but [%v] are required",
+			method,
+			len(blockFeeRates),
+			c.threshold,
+		)
+	}
+
+	result := &bitcoin.BlockFeeRates{Height: blockHeight}
+	for i := range result.Percentiles {
+		values := make([]int64, len(blockFeeRates))
+		for j, rates := range blockFeeRates {
+			values[j] = rates.Percentiles[i]
+		}
+
+		sort.Slice(values, func(a, b int) bool { return values[a] < values[b] })
+
+		result.Percentiles[i] = values[len(values)/2]
+	}
+
+	return result, nil
+}
+
+// response holds the outcome of a call executed against a single backend.
+type response[K any] struct {
+	backend *backend
+	result  K
+	err     error
+}
+
+// query executes the given call against all backends concurrently and
+// returns their responses in the order backends were configured. Backend
+// failures are logged and counted.
+func query[K any](
+	c *Chain,
+	method string,
+	call func(chain bitcoin.Chain) (K, error),
+) []*response[K] {
+	return queryBackends(c.backends, method, call)
+}
+
+// querySupporting executes the given call concurrently against backends
+// implementing the optional S interface and returns their responses in the
+// order backends were configured. Backends not implementing the interface
+// are skipped. Backend failures are logged and counted.
+func querySupporting[S any, K any](
+	c *Chain,
+	method string,
+	call func(source S) (K, error),
+) []*response[K] {
+	return queryBackends(
+		supportingBackends[S](c),
+		method,
+		func(chain bitcoin.Chain) (K, error) {
+			return call(any(chain).(S))
+		},
+	)
+}
+
+// supportingBackends returns backends implementing the optional S interface.
+func supportingBackends[S any](c *Chain) []*backend {
+	backends := make([]*backend, 0)
+	for _, b := range c.backends {
+		if _, ok := any(b.Chain).(S); ok {
+			backends = append(backends, b)
+		}
+	}
+	return backends
+}
+
+func queryBackends[K any](
+	backends []*backend,
+	method string,
+	call func(chain bitcoin.Chain) (K, error),
+) []*response[K] {
+	responses := make([]*response[K], len(backends))
+
+	wg := sync.WaitGroup{}
+	wg.Add(len(backends))
+
+	for i, b := range backends {
+		go func(i int, b *backend) {
+			defer wg.Done()
+
+			result, err := call(b.Chain)
+			responses[i] = &response[K]{
+				backend: b,
+				result:  result,
+				err:     err,
+			}
+		}(i, b)
+	}
+
+	wg.Wait()
+
+	for _, response := range responses {
+		if response.err != nil {
+			response.backend.failures.Add(1)
+
+			logger.Warnf(
+				"backend [%s] failed to execute [%s]: [%v]",
+				response.backend.Name,
+				method,
+				response.err,
+			)
+		}
+	}
+
+	return responses
+}
+
+// agree executes the given call against all backends and returns the result
+// that at least a quorum of backends agrees on. Results are compared using
+// the given fingerprint function. Every backend that returned a different
+// result than the agreed one is logged and counted as disagreeing.
+func agree[K any](
+	c *Chain,
+	method string,
+	call func(chain bitcoin.Chain) (K, error),
+	fingerprint func(result K) string,
+) (K, error) {
+	var zero K
+
+	responses := query(c, method, call)
+
+	fingerprints := make(map[*backend]string)
+	votes := make(map[string]int)
+	for _, response := range responses {
+		if response.err != nil {
+			continue
+		}
+
+		fp := fingerprint(response.result)
+		fingerprints[response.backend] = fp
+		votes[fp]++
+	}
+
+	agreed := ""
+	agreedVotes := 0
+	tie := false
+	for fp, count := range votes {
+		if count > agreedVotes {
+			agreed = fp
+			agreedVotes = count
+			tie = false
+		} else if count == agreedVotes {
+			tie = true
+		}
+	}
+
+	// If two different results have the same number of votes, none of them
+	// can be considered as agreed.
+	if tie {
+		return zero, fmt.Errorf(
+			"quorum not reached for [%s]; conflicting results were "+
+				"returned by the same number of backends",
+			method,
+		)
+	}
+
+	if agreedVotes < c.threshold {
+		return zero, fmt.Errorf(
+			"quorum not reached for [%s]; the most common result was "+
+				"returned by [%v] backends but [%v] are required",
+			method,
+			agreedVotes,
+			c.threshold,
+		)
+	}
+
+	result := zero
+	resultSet := false
+	for _, response := range responses {
+		fp, ok := fingerprints[response.backend]
+		if !ok {
+			continue
+		}
+
+		if fp != agreed {
+			response.backend.disagreements.Add(1)
+
+			logger.Warnf(
+				"backend [%s] disagrees with the quorum for [%s]",
+				response.backend.Name,
+				method,
+			)
+			continue
+		}
+
+		if !resultSet {
+			result = response.result
+			resultSet = true
+		}
+	}
+
+	return result, nil
+}
+
+// atLeast executes the given call against all backends and returns the
+// highest value that at least a quorum of backends reported or exceeded.
+// It is meant to be used for monotonically increasing values, like the chain
+// tip or transaction confirmations, where backends may be slightly behind
+// each other without being dishonest.
+func atLeast(
+	c *Chain,
+	method string,
+	call func(chain bitcoin.Chain) (uint, error),
+) (uint, error) {
+	responses := query(c, method, call)
+
+	values := make([]uint, 0)
+	for _, response := range responses {
+		if response.err == nil {
+			values = append(values, response.result)
+		}
+	}
+
+	if len(values) < c.threshold {
+		return 0, fmt.Errorf(
+			"quorum not reached for [%s]; [%v] backends responded "+
+				"but [%v] are required",
+			method,
+			len(values),
+			c.threshold,
+		)
+	}
+
+	sort.Slice(values, func(i, j int) bool { return values[i] > values[j] })
+
+	return values[c.threshold-1], nil
+}
+
+func transactionFingerprint(transaction *bitcoin.Transaction) string {
+	return transaction.WitnessHash().String()
+}
+
+func transactionsFingerprint(transactions []*bitcoin.Transaction) string {
+	items := make([]string, len(transactions))
+	for i, transaction := range transactions {
+		items[i] = transactionFingerprint(transaction)
+	}
+	return sortedFingerprint(items)
+}
+
+func utxosFingerprint(utxos []*bitcoin.UnspentTransactionOutput) string {
+	items := make([]string, len(utxos))
+	for i, utxo := range utxos {
+		items[i] = fmt.Sprintf(
+			"%s:%v:%v",
+			utxo.Outpoint.TransactionHash.String(),
+			utxo.Outpoint.OutputIndex,
+			utxo.Value,
+		)
+	}
+	return sortedFingerprint(items)
+}
+
+// sortedFingerprint builds a fingerprint of a list regardless of its order.
+// Backends may return items confirmed in the same block, or living in the
+// mempool, in different orders.
+func sortedFingerprint(items []string) string {
+	sorted := make([]string, len(items))
+	copy(sorted, items)
+	sort.Strings(sorted)
+	return strings.Join(sorted, ",")
+}
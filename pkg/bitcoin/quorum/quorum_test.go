@@ -0,0 +1,624 @@
+package quorum
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/keep-network/keep-core/internal/testutils"
+	"github.com/keep-network/keep-core/pkg/bitcoin"
+	"github.com/keep-network/keep-core/pkg/subscription"
+)
+
+// stubChain is a bitcoin.Chain stub returning preconfigured results. Calls
+// of methods that are not stubbed panic.
+type stubChain struct {
+	bitcoin.Chain
+
+	err error
+
+	latestBlockHeight uint
+	blockHeader       *bitcoin.BlockHeader
+	utxos             []*bitcoin.UnspentTransactionOutput
+	satPerVByteFee    int64
+
+	broadcastCount int
+}
+
+func (sc *stubChain) GetLatestBlockHeight() (uint, error) {
+	return sc.latestBlockHeight, sc.err
+}
+
+func (sc *stubChain) GetBlockHeader(blockHeight uint) (*bitcoin.BlockHeader, error) {
+	return sc.blockHeader, sc.err
+}
+
+func (sc *stubChain) GetUtxosForPublicKeyHash(
+	publicKeyHash [20]byte,
+) ([]*bitcoin.UnspentTransactionOutput, error) {
+	return sc.utxos, sc.err
+}
+
+func (sc *stubChain) EstimateSatPerVByteFee(blocks uint32) (int64, error) {
+	return sc.satPerVByteFee, sc.err
+}
+
+func (sc *stubChain) BroadcastTransaction(transaction *bitcoin.Transaction) error {
+	sc.broadcastCount++
+	return sc.err
+}
+
+func newQuorumChain(
+	t *testing.T,
+	threshold int,
+	chains ...*stubChain,
+) *Chain {
+	backends := make([]Backend, len(chains))
+	for i, chain := range chains {
+		backends[i] = Backend{
+			Name:  fmt.Sprintf("backend%v", i),
+			Chain: chain,
+		}
+	}
+
+	quorumChain, err := New(backends, threshold)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return quorumChain
+}
+
+func TestNew(t *testing.T) {
+	chain := &stubChain{}
+
+	var tests = map[string]struct {
+		backends          []Backend
+		threshold         int
+		expectedThreshold int
+		expectedErr       bool
+	}{
+		"default majority threshold for odd backends count": {
+			backends: []Backend{
+				{"a", chain}, {"b", chain}, {"c", chain},
+			},
+			threshold:         0,
+			expectedThreshold: 2,
+		},
+		"default majority threshold for even backends count": {
+			backends: []Backend{
+				{"a", chain}, {"b", chain}, {"c", chain}, {"d", chain},
+			},
+			threshold:         0,
+			expectedThreshold: 3,
+		},
+		"explicit threshold": {
+			backends: []Backend{
+				{"a", chain}, {"b", chain}, {"c", chain},
+			},
+			threshold:         3,
+			expectedThreshold: 3,
+		},
+		"threshold greater than backends count": {
+			backends: []Backend{
+				{"a", chain}, {"b", chain},
+			},
+			threshold:   3,
+			expectedErr: true,
+		},
+		"no backends": {
+			backends:    []Backend{},
+			expectedErr: true,
+		},
+		"duplicated backend names": {
+			backends: []Backend{
+				{"a", chain}, {"a", chain},
+			},
+			expectedErr: true,
+		},
+	}
+
+	for testName, test := range tests {
+		t.Run(testName, func(t *testing.T) {
+			quorumChain, err := New(test.backends, test.threshold)
+			if test.expectedErr {
+				if err == nil {
+					t.Fatal("expected error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			testutils.AssertIntsEqual(
+				t,
+				"threshold",
+				test.expectedThreshold,
+				quorumChain.threshold,
+			)
+		})
+	}
+}
+
+func TestGetBlockHeader(t *testing.T) {
+	honestHeader := &bitcoin.BlockHeader{Version: 1, Nonce: 100}
+	forgedHeader := &bitcoin.BlockHeader{Version: 1, Nonce: 200}
+
+	t.Run("quorum reached", func(t *testing.T) {
+		quorumChain := newQuorumChain(
+			t,
+			2,
+			&stubChain{blockHeader: honestHeader},
+			&stubChain{blockHeader: forgedHeader},
+			&stubChain{blockHeader: honestHeader},
+		)
+
+		blockHeader, err := quorumChain.GetBlockHeader(100)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if !reflect.DeepEqual(honestHeader, blockHeader) {
+			t.Errorf("unexpected block header: [%+v]", blockHeader)
+		}
+
+		testutils.AssertUintsEqual(t, "backend0 disagreements", 0, quorumChain.Disagreements("backend0"))
+		testutils.AssertUintsEqual(t, "backend1 disagreements", 1, quorumChain.Disagreements("backend1"))
+		testutils.AssertUintsEqual(t, "backend2 disagreements", 0, quorumChain.Disagreements("backend2"))
+	})
+
+	t.Run("quorum not reached due to failures", func(t *testing.T) {
+		quorumChain := newQuorumChain(
+			t,
+			2,
+			&stubChain{blockHeader: honestHeader},
+			&stubChain{err: fmt.Errorf("unavailable")},
+			&stubChain{err: fmt.Errorf("unavailable")},
+		)
+
+		_, err := quorumChain.GetBlockHeader(100)
+		if err == nil {
+			t.Fatal("expected error")
+		}
+
+		testutils.AssertUintsEqual(t, "backend0 failures", 0, quorumChain.Failures("backend0"))
+		testutils.AssertUintsEqual(t, "backend1 failures", 1, quorumChain.Failures("backend1"))
+		testutils.AssertUintsEqual(t, "backend2 failures", 1, quorumChain.Failures("backend2"))
+	})
+
+	t.Run("conflicting results with equal votes", func(t *testing.T) {
+		quorumChain := newQuorumChain(
+			t,
+			1,
+			&stubChain{blockHeader: honestHeader},
+			&stubChain{blockHeader: forgedHeader},
+		)
+
+		_, err := quorumChain.GetBlockHeader(100)
+		if err == nil {
+			t.Fatal("expected error")
+		}
+	})
+}
+
+func TestGetUtxosForPublicKeyHash(t *testing.T) {
+	utxo1 := &bitcoin.UnspentTransactionOutput{
+		Outpoint: &bitcoin.TransactionOutpoint{
+			TransactionHash: bitcoin.Hash{0x01},
+			OutputIndex:     0,
+		},
+		Value: 1000,
+	}
+	utxo2 := &bitcoin.UnspentTransactionOutput{
+		Outpoint: &bitcoin.TransactionOutpoint{
+			TransactionHash: bitcoin.Hash{0x02},
+			OutputIndex:     1,
+		},
+		Value: 2000,
+	}
+	forgedUtxo := &bitcoin.UnspentTransactionOutput{
+		Outpoint: &bitcoin.TransactionOutpoint{
+			TransactionHash: bitcoin.Hash{0x02},
+			OutputIndex:     1,
+		},
+		Value: 2000000,
+	}
+
+	quorumChain := newQuorumChain(
+		t,
+		2,
+		&stubChain{utxos: []*bitcoin.UnspentTransactionOutput{utxo1, forgedUtxo}},
+		// Honest backends may return UTXOs in a different order.
+		&stubChain{utxos: []*bitcoin.UnspentTransactionOutput{utxo1, utxo2}},
+		&stubChain{utxos: []*bitcoin.UnspentTransactionOutput{utxo2, utxo1}},
+	)
+
+	utxos, err := quorumChain.GetUtxosForPublicKeyHash([20]byte{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expectedUtxos := []*bitcoin.UnspentTransactionOutput{utxo1, utxo2}
+	if !reflect.DeepEqual(expectedUtxos, utxos) {
+		t.Errorf("unexpected UTXOs: [%v]", utxos)
+	}
+
+	testutils.AssertUintsEqual(t, "backend0 disagreements", 1, quorumChain.Disagreements("backend0"))
+	testutils.AssertUintsEqual(t, "backend1 disagreements", 0, quorumChain.Disagreements("backend1"))
+	testutils.AssertUintsEqual(t, "backend2 disagreements", 0, quorumChain.Disagreements("backend2"))
+}
+
+func TestGetLatestBlockHeight(t *testing.T) {
+	var tests = map[string]struct {
+		threshold           int
+		chains              []*stubChain
+		expectedBlockHeight uint
+		expectedErr         bool
+	}{
+		"all backends at the same height": {
+			threshold: 2,
+			chains: []*stubChain{
+				{latestBlockHeight: 100},
+				{latestBlockHeight: 100},
+				{latestBlockHeight: 100},
+			},
+			expectedBlockHeight: 100,
+		},
+		"one backend ahead": {
+			threshold: 2,
+			chains: []*stubChain{
+				{latestBlockHeight: 100},
+				{latestBlockHeight: 101},
+				{latestBlockHeight: 99},
+			},
+			expectedBlockHeight: 100,
+		},
+		"one backend reporting a fake tip": {
+			threshold: 2,
+			chains: []*stubChain{
+				{latestBlockHeight: 100},
+				{latestBlockHeight: 100000},
+				{latestBlockHeight: 100},
+			},
+			expectedBlockHeight: 100,
+		},
+		"not enough backends responded": {
+			threshold: 2,
+			chains: []*stubChain{
+				{latestBlockHeight: 100},
+				{err: fmt.Errorf("unavailable")},
+				{err: fmt.Errorf("unavailable")},
+			},
+			expectedErr: true,
+		},
+	}
+
+	for testName, test := range tests {
+		t.Run(testName, func(t *testing.T) {
+			quorumChain := newQuorumChain(t, test.threshold, test.chains...)
+
+			blockHeight, err := quorumChain.GetLatestBlockHeight()
+			if test.expectedErr {
+				if err == nil {
+					t.Fatal("expected error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			testutils.AssertUintsEqual(
+				t,
+				"block height",
+				uint64(test.expectedBlockHeight),
+				uint64(blockHeight),
+			)
+		})
+	}
+}
+
+func TestEstimateSatPerVByteFee(t *testing.T) {
+	quorumChain := newQuorumChain(
+		t,
+		2,
+		&stubChain{satPerVByteFee: 30},
+		&stubChain{satPerVByteFee: 10},
+		&stubChain{satPerVByteFee: 1000},
+	)
+
+	fee, err := quorumChain.EstimateSatPerVByteFee(6)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testutils.AssertIntsEqual(t, "fee", 30, int(fee))
+}
+
+func TestBroadcastTransaction(t *testing.T) {
+	transaction := &bitcoin.Transaction{Version: 1}
+
+	t.Run("at least one backend accepted", func(t *testing.T) {
+		chains := []*stubChain{
+			{err: fmt.Errorf("rejected")},
+			{},
+			{},
+		}
+
+		quorumChain := newQuorumChain(t, 2, chains...)
+
+		err := quorumChain.BroadcastTransaction(transaction)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for i, chain := range chains {
+			testutils.AssertIntsEqual(
+				t,
+				fmt.Sprintf("backend%v broadcast count", i),
+				1,
+				chain.broadcastCount,
+			)
+		}
+	})
+
+	t.Run("all backends rejected", func(t *testing.T) {
+		quorumChain := newQuorumChain(
+			t,
+			2,
+			&stubChain{err: fmt.Errorf("rejected")},
+			&stubChain{err: fmt.Errorf("rejected")},
+		)
+
+		err := quorumChain.BroadcastTransaction(transaction)
+		if err == nil {
+			t.Fatal("expected error")
+		}
+	})
+}
+
+// feeSourceStubChain is a stubChain additionally implementing
+// bitcoin.FeeHistogramSource and bitcoin.BlockFeeRatesSource.
+type feeSourceStubChain struct {
+	*stubChain
+
+	feeHistogram  []*bitcoin.FeeHistogramBin
+	blockFeeRates *bitcoin.BlockFeeRates
+}
+
+func (fssc *feeSourceStubChain) GetMempoolFeeHistogram() (
+	[]*bitcoin.FeeHistogramBin,
+	error,
+) {
+	return fssc.feeHistogram, fssc.err
+}
+
+func (fssc *feeSourceStubChain) GetBlockFeeRates(
+	blockHeight uint,
+) (*bitcoin.BlockFeeRates, error) {
+	return fssc.blockFeeRates, fssc.err
+}
+
+func TestGetMempoolFeeHistogram(t *testing.T) {
+	smallHistogram := []*bitcoin.FeeHistogramBin{
+		{SatPerVByteFee: 10, VirtualSize: 1000},
+	}
+	mediumHistogram := []*bitcoin.FeeHistogramBin{
+		{SatPerVByteFee: 20, VirtualSize: 5000},
+		{SatPerVByteFee: 10, VirtualSize: 5000},
+	}
+	inflatedHistogram := []*bitcoin.FeeHistogramBin{
+		{SatPerVByteFee: 1000, VirtualSize: 100000000},
+	}
+
+	t.Run("quorum reached", func(t *testing.T) {
+		quorumChain, err := New(
+			[]Backend{
+				{"a", &feeSourceStubChain{stubChain: &stubChain{}, feeHistogram: inflatedHistogram}},
+				{"b", &feeSourceStubChain{stubChain: &stubChain{}, feeHistogram: smallHistogram}},
+				{"c", &feeSourceStubChain{stubChain: &stubChain{}, feeHistogram: mediumHistogram}},
+			},
+			2,
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		histogram, err := quorumChain.GetMempoolFeeHistogram()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if !reflect.DeepEqual(mediumHistogram, histogram) {
+			t.Errorf("unexpected histogram: [%v]", histogram)
+		}
+	})
+
+	t.Run("not enough backends support histograms", func(t *testing.T) {
+		quorumChain, err := New(
+			[]Backend{
+				{"a", &feeSourceStubChain{stubChain: &stubChain{}, feeHistogram: smallHistogram}},
+				{"b", &stubChain{}},
+				{"c", &stubChain{}},
+			},
+			2,
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		_, err = quorumChain.GetMempoolFeeHistogram()
+		if err == nil {
+			t.Fatal("expected error")
+		}
+
+		// Backends not supporting histograms must not be counted as failed.
+		testutils.AssertUintsEqual(t, "b failures", 0, quorumChain.Failures("b"))
+		testutils.AssertUintsEqual(t, "c failures", 0, quorumChain.Failures("c"))
+	})
+}
+
+func TestGetBlockFeeRates(t *testing.T) {
+	quorumChain, err := New(
+		[]Backend{
+			{"a", &feeSourceStubChain{
+				stubChain:     &stubChain{},
+				blockFeeRates: &bitcoin.BlockFeeRates{Height: 100, Percentiles: [5]int64{1, 2, 3, 4, 5}},
+			}},
+			{"b", &feeSourceStubChain{
+				stubChain:     &stubChain{},
+				blockFeeRates: &bitcoin.BlockFeeRates{Height: 100, Percentiles: [5]int64{2, 3, 4, 5, 6}},
+			}},
+			{"c", &feeSourceStubChain{
+				stubChain:     &stubChain{},
+				blockFeeRates: &bitcoin.BlockFeeRates{Height: 100, Percentiles: [5]int64{100, 200, 300, 400, 500}},
+			}},
+			{"d", &stubChain{}},
+		},
+		2,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	blockFeeRates, err := quorumChain.GetBlockFeeRates(100)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expectedBlockFeeRates := &bitcoin.BlockFeeRates{
+		Height:      100,
+		Percentiles: [5]int64{2, 3, 4, 5, 6},
+	}
+	if !reflect.DeepEqual(expectedBlockFeeRates, blockFeeRates) {
+		t.Errorf("unexpected block fee rates: [%+v]", blockFeeRates)
+	}
+}
+
+// notifierStubChain is a stubChain additionally implementing
+// bitcoin.ChainNotifier. Registered handlers can be triggered manually.
+type notifierStubChain struct {
+	*stubChain
+
+	headerHandlers []func(event *bitcoin.NewBlockHeaderEvent)
+	scriptHandlers []func(event *bitcoin.ScriptStatusChangedEvent)
+	unsubscribed   int
+}
+
+func (nsc *notifierStubChain) OnNewBlockHeader(
+	handler func(event *bitcoin.NewBlockHeaderEvent),
+) subscription.EventSubscription {
+	nsc.headerHandlers = append(nsc.headerHandlers, handler)
+	return subscription.NewEventSubscription(func() { nsc.unsubscribed++ })
+}
+
+func (nsc *notifierStubChain) OnScriptStatusChanged(
+	script bitcoin.Script,
+	handler func(event *bitcoin.ScriptStatusChangedEvent),
+) subscription.EventSubscription {
+	nsc.scriptHandlers = append(nsc.scriptHandlers, handler)
+	return subscription.NewEventSubscription(func() { nsc.unsubscribed++ })
+}
+
+func (nsc *notifierStubChain) notifyHeader(event *bitcoin.NewBlockHeaderEvent) {
+	for _, handler := range nsc.headerHandlers {
+		handler(event)
+	}
+}
+
+func (nsc *notifierStubChain) notifyScriptStatus(
+	event *bitcoin.ScriptStatusChangedEvent,
+) {
+	for _, handler := range nsc.scriptHandlers {
+		handler(event)
+	}
+}
+
+func TestOnNewBlockHeader(t *testing.T) {
+	chains := []*notifierStubChain{
+		{stubChain: &stubChain{}},
+		{stubChain: &stubChain{}},
+		{stubChain: &stubChain{}},
+	}
+
+	quorumChain, err := New(
+		[]Backend{
+			{"a", chains[0]},
+			{"b", chains[1]},
+			{"c", chains[2]},
+			{"d", &stubChain{}},
+		},
+		2,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var deliveredHeights []uint
+	headerSubscription := quorumChain.OnNewBlockHeader(
+		func(event *bitcoin.NewBlockHeaderEvent) {
+			deliveredHeights = append(deliveredHeights, event.Height)
+		},
+	)
+
+	honestHeader := &bitcoin.BlockHeader{Version: 1, Nonce: 100}
+	forgedHeader := &bitcoin.BlockHeader{Version: 1, Nonce: 200}
+
+	// A forged header reported by a single backend is not delivered.
+	chains[0].notifyHeader(&bitcoin.NewBlockHeaderEvent{Height: 101, Header: forgedHeader})
+	// The honest header is delivered once the quorum is reached.
+	chains[1].notifyHeader(&bitcoin.NewBlockHeaderEvent{Height: 100, Header: honestHeader})
+	chains[2].notifyHeader(&bitcoin.NewBlockHeaderEvent{Height: 100, Header: honestHeader})
+	// The same header reported by another backend is not delivered again.
+	chains[0].notifyHeader(&bitcoin.NewBlockHeaderEvent{Height: 100, Header: honestHeader})
+
+	if !reflect.DeepEqual([]uint{100}, deliveredHeights) {
+		t.Errorf("unexpected delivered heights: [%v]", deliveredHeights)
+	}
+
+	headerSubscription.Unsubscribe()
+
+	for i, chain := range chains {
+		testutils.AssertIntsEqual(
+			t,
+			fmt.Sprintf("backend%v unsubscribed count", i),
+			1,
+			chain.unsubscribed,
+		)
+	}
+}
+
+func TestOnScriptStatusChanged(t *testing.T) {
+	chains := []*notifierStubChain{
+		{stubChain: &stubChain{}},
+		{stubChain: &stubChain{}},
+	}
+
+	quorumChain, err := New(
+		[]Backend{
+			{"a", chains[0]},
+			{"b", chains[1]},
+		},
+		2,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var deliveredStatuses []string
+	quorumChain.OnScriptStatusChanged(
+		bitcoin.Script{0x00, 0x14},
+		func(event *bitcoin.ScriptStatusChangedEvent) {
+			deliveredStatuses = append(deliveredStatuses, event.Status)
+		},
+	)
+
+	chains[0].notifyScriptStatus(&bitcoin.ScriptStatusChangedEvent{Status: "s1"})
+	chains[1].notifyScriptStatus(&bitcoin.ScriptStatusChangedEvent{Status: "s1"})
+	chains[1].notifyScriptStatus(&bitcoin.ScriptStatusChangedEvent{Status: "s2"})
+
+	if !reflect.DeepEqual([]string{"s1", "s2"}, deliveredStatuses) {
+		t.Errorf("unexpected delivered statuses: [%v]", deliveredStatuses)
+	}
+}
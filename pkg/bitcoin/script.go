@@ -19,6 +19,7 @@ const (
 	P2WPKHScript
 	P2SHScript
 	P2WSHScript
+	P2TRScript
 )
 
 func (st ScriptType) String() string {
@@ -31,6 +32,8 @@ func (st ScriptType) String() string {
 		return "P2SH"
 	case P2WSHScript:
 		return "P2WSH"
+	case P2TRScript:
+		return "P2TR"
 	default:
 		return "NonStandard"
 	}
@@ -147,8 +150,32 @@ func PayToScriptHash(scriptHash [20]byte) (Script, error) {
 		Script()
 }
 
+// PayToTaproot constructs a P2TR script for the provided 32-byte taproot
+// output key, i.e. the x-only public key being the tweaked internal key.
+// The function assumes the provided output key is valid.
+func PayToTaproot(outputKey [32]byte) (Script, error) {
+	return txscript.NewScriptBuilder().
+		AddOp(txscript.OP_1).
+		AddData(outputKey[:]).
+		Script()
+}
+
+// isPayToTaproot checks whether the given Script is a P2TR script, i.e.
+// a witness version 1 program holding a 32-byte output key.
+func isPayToTaproot(script Script) bool {
+	return len(script) == 34 &&
+		script[0] == txscript.OP_1 &&
+		script[1] == txscript.OP_DATA_32
+}
+
 // GetScriptType gets the ScriptType of the given Script.
 func GetScriptType(script Script) ScriptType {
+	// The used txscript version does not recognize witness version 1
+	// programs so P2TR scripts must be detected explicitly.
+	if isPayToTaproot(script) {
+		return P2TRScript
+	}
+
 	switch txscript.GetScriptClass(script) {
 	case txscript.PubKeyHashTy:
 		return P2PKHScript
@@ -189,3 +216,17 @@ func ExtractPublicKeyHash(script Script) ([20]byte, error) {
 
 	return publicKeyHash, nil
 }
+
+// ExtractTaprootOutputKey extracts the 32-byte taproot output key from a P2TR
+// script.
+func ExtractTaprootOutputKey(script Script) ([32]byte, error) {
+	if GetScriptType(script) != P2TRScript {
+		return [32]byte{}, fmt.Errorf("not a P2TR script")
+	}
+
+	var outputKey [32]byte
+	// Omit the first two 0x5120 bytes.
+	copy(outputKey[:], script[2:])
+
+	return outputKey, nil
+}
@@ -0,0 +1,134 @@
+package simnet
+
+import (
+	"fmt"
+
+	"github.com/keep-network/keep-core/pkg/bitcoin"
+)
+
+// Fund broadcasts a transaction paying the given value to the given script.
+// The transaction spends outputs controlled by the faucet, i.e. block
+// subsidies and changes of previous funding transactions, and pays the
+// minimum relay fee. The transaction waits in the mempool until the next
+// block is mined. The funded output is always the first output of the
+// returned transaction.
+func (c *Chain) Fund(
+	script bitcoin.Script,
+	value int64,
+) (*bitcoin.Transaction, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	faucetScript, err := faucetScript()
+	if err != nil {
+		return nil, err
+	}
+
+	transaction := &bitcoin.Transaction{
+		Version: 1,
+		Outputs: []*bitcoin.TransactionOutput{
+			{Value: value, PublicKeyScript: script},
+			{Value: 0, PublicKeyScript: faucetScript},
+		},
+	}
+
+	totalInputsValue := int64(0)
+	for _, outpoint := range c.faucetOutpoints(faucetScript) {
+		output, _, err := c.spendableOutput(outpoint, c.tipHeight()+1)
+		if err != nil {
+			// Immature coinbase outputs cannot be used yet.
+			continue
+		}
+
+		outpointCopy := outpoint
+		transaction.Inputs = append(
+			transaction.Inputs,
+			&bitcoin.TransactionInput{
+				Outpoint: &outpointCopy,
+				Witness:  [][]byte{faucetWitnessScript},
+				Sequence: 0xffffffff,
+			},
+		)
+		totalInputsValue += output.Value
+
+		fee := transaction.VirtualSize() * minRelaySatPerVByteFee
+		if totalInputsValue >= value+fee {
+			transaction.Outputs[1].Value = totalInputsValue - value - fee
+
+			if err := c.acceptTransaction(transaction); err != nil {
+				return nil, fmt.Errorf(
+					"cannot broadcast funding transaction: [%v]",
+					err,
+				)
+			}
+
+			return transaction, nil
+		}
+	}
+
+	return nil, fmt.Errorf("faucet has not enough funds")
+}
+
+// faucetOutpoints returns outpoints of confirmed and mempool outputs
+// locked using the faucet script and not spent by mempool transactions.
+func (c *Chain) faucetOutpoints(
+	faucetScript bitcoin.Script,
+) []bitcoin.TransactionOutpoint {
+	outpoints := make([]bitcoin.TransactionOutpoint, 0)
+
+	// Iterate over blocks instead of the UTXO set to have a deterministic
+	// order of outpoints.
+	for _, connectedBlock := range c.blocks {
+		for _, transaction := range connectedBlock.transactions {
+			outpoints = append(
+				outpoints,
+				outpointsOfScript(transaction, faucetScript)...,
+			)
+		}
+	}
+
+	for _, entry := range c.mempool {
+		outpoints = append(
+			outpoints,
+			outpointsOfScript(entry.transaction, faucetScript)...,
+		)
+	}
+
+	unspent := make([]bitcoin.TransactionOutpoint, 0, len(outpoints))
+	for _, outpoint := range outpoints {
+		if _, spent := c.mempoolSpender(outpoint); spent {
+			continue
+		}
+
+		if _, confirmed := c.transactionHeights[outpoint.TransactionHash]; confirmed {
+			if _, ok := c.utxos[outpoint]; !ok {
+				continue
+			}
+		}
+
+		unspent = append(unspent, outpoint)
+	}
+
+	return unspent
+}
+
+// outpointsOfScript returns outpoints of the given transaction's outputs
+// locked using the given script.
+func outpointsOfScript(
+	transaction *bitcoin.Transaction,
+	script bitcoin.Script,
+) []bitcoin.TransactionOutpoint {
+	outpoints := make([]bitcoin.TransactionOutpoint, 0)
+	transactionHash := transaction.Hash()
+
+	for i, output := range transaction.Outputs {
+		if string(output.PublicKeyScript) == string(script) {
+			outpoints = append(outpoints, bitcoin.TransactionOutpoint{
+				TransactionHash: transactionHash,
+				OutputIndex:     uint32(i),
+			})
+		}
+	}
+
+	return outpoints
+}
@@ -0,0 +1,267 @@
+package simnet
+
+import (
+	"fmt"
+	"math/big"
+	"sort"
+
+	"github.com/btcsuite/btcd/txscript"
+
+	"github.com/keep-network/keep-core/pkg/bitcoin"
+	"github.com/keep-network/keep-core/pkg/internal/byteutils"
+)
+
+// witnessCommitmentHeader is the prefix of the coinbase output holding
+// the witness commitment, as defined by BIP-141.
+var witnessCommitmentHeader = []byte{
+	txscript.OP_RETURN, txscript.OP_DATA_36, 0xaa, 0x21, 0xa9, 0xed,
+}
+
+// mineBlock mines a new block on top of the chain tip, including the given
+// mempool entries, and connects it to the chain.
+func (c *Chain) mineBlock(entries []*mempoolEntry) error {
+	height := uint(len(c.blocks))
+
+	fees := int64(0)
+	transactions := make([]*bitcoin.Transaction, 0, len(entries)+1)
+	transactions = append(transactions, nil)
+	for _, entry := range entries {
+		fees += entry.fee
+		transactions = append(transactions, entry.transaction)
+	}
+
+	coinbase, err := c.newCoinbaseTransaction(
+		height,
+		blockSubsidy(height)+fees,
+		transactions[1:],
+	)
+	if err != nil {
+		return fmt.Errorf("cannot create coinbase transaction: [%v]", err)
+	}
+	transactions[0] = coinbase
+
+	header := &bitcoin.BlockHeader{
+		Version:        0x20000000,
+		MerkleRootHash: merkleRoot(transactionHashes(transactions)),
+		Time:           uint32(genesisBlockTime + height*blockInterval),
+		Bits:           minimumDifficultyBits,
+	}
+	if height > 0 {
+		header.PreviousBlockHeaderHash = c.blocks[height-1].header.Hash()
+	}
+
+	if err := solveBlockHeader(header); err != nil {
+		return err
+	}
+
+	c.blocks = append(c.blocks, &block{
+		header:       header,
+		transactions: transactions,
+	})
+	c.connectTransactions(height, transactions)
+
+	return nil
+}
+
+// newCoinbaseTransaction creates the coinbase transaction of the block
+// at the given height, paying the given value to the faucet. If any of the
+// block's transactions has witness data, the coinbase commits to them as
+// required by BIP-141.
+func (c *Chain) newCoinbaseTransaction(
+	height uint,
+	value int64,
+	transactions []*bitcoin.Transaction,
+) (*bitcoin.Transaction, error) {
+	c.extraNonce++
+
+	// The coinbase script starts with the block height, as required
+	// by BIP-34.
+	signatureScript, err := txscript.NewScriptBuilder().
+		AddInt64(int64(height)).
+		AddInt64(c.extraNonce).
+		AddData([]byte("simnet")).
+		Script()
+	if err != nil {
+		return nil, fmt.Errorf("cannot build coinbase script: [%v]", err)
+	}
+
+	faucetScript, err := faucetScript()
+	if err != nil {
+		return nil, err
+	}
+
+	coinbase := &bitcoin.Transaction{
+		Version: 1,
+		Inputs: []*bitcoin.TransactionInput{
+			{
+				Outpoint: &bitcoin.TransactionOutpoint{
+					TransactionHash: bitcoin.Hash{},
+					OutputIndex:     0xffffffff,
+				},
+				SignatureScript: signatureScript,
+				Sequence:        0xffffffff,
+			},
+		},
+		Outputs: []*bitcoin.TransactionOutput{
+			{
+				Value:           value,
+				PublicKeyScript: faucetScript,
+			},
+		},
+	}
+
+	if !hasWitness(transactions) {
+		return coinbase, nil
+	}
+
+	// The witness reserved value is all zeros.
+	witnessReservedValue := make([]byte, bitcoin.HashByteLength)
+
+	// The coinbase's witness hash is assumed to be all zeros.
+	witnessHashes := []bitcoin.Hash{{}}
+	for _, transaction := range transactions {
+		witnessHashes = append(witnessHashes, transaction.WitnessHash())
+	}
+	witnessRoot := merkleRoot(witnessHashes)
+
+	commitment := bitcoin.ComputeHash(
+		append(witnessRoot[:], witnessReservedValue...),
+	)
+
+	coinbase.Inputs[0].Witness = [][]byte{witnessReservedValue}
+	coinbase.Outputs = append(coinbase.Outputs, &bitcoin.TransactionOutput{
+		Value: 0,
+		PublicKeyScript: append(
+			append([]byte{}, witnessCommitmentHeader...),
+			commitment[:]...,
+		),
+	})
+
+	return coinbase, nil
+}
+
+// blockSubsidy returns the block subsidy for the given height.
+func blockSubsidy(height uint) int64 {
+	halvings := height / subsidyHalvingInterval
+	if halvings >= 64 {
+		return 0
+	}
+
+	return initialBlockSubsidy >> halvings
+}
+
+// hasWitness checks whether any of the given transactions has witness data.
+func hasWitness(transactions []*bitcoin.Transaction) bool {
+	for _, transaction := range transactions {
+		for _, input := range transaction.Inputs {
+			if len(input.Witness) > 0 {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// solveBlockHeader sets the header's nonce so the header's hash meets
+// the header's target.
+func solveBlockHeader(header *bitcoin.BlockHeader) error {
+	target := header.Target()
+
+	for nonce := uint32(0); ; nonce++ {
+		header.Nonce = nonce
+
+		if hashToBig(header.Hash()).Cmp(target) <= 0 {
+			return nil
+		}
+
+		if nonce == 0xffffffff {
+			return fmt.Errorf("cannot find a valid block header nonce")
+		}
+	}
+}
+
+// hashToBig interprets the given hash, in the internal byte order, as
+// a little-endian number.
+func hashToBig(hash bitcoin.Hash) *big.Int {
+	return new(big.Int).SetBytes(byteutils.Reverse(hash[:]))
+}
+
+// merkleRoot computes the merkle root of the given transaction hashes.
+func merkleRoot(hashes []bitcoin.Hash) bitcoin.Hash {
+	level := append([]bitcoin.Hash{}, hashes...)
+
+	for len(level) > 1 {
+		level = nextMerkleLevel(level)
+	}
+
+	return level[0]
+}
+
+// merkleBranch computes the merkle branch of the transaction hash at the
+// given position, i.e. the hashes the transaction hash is paired with,
+// deepest pairing first.
+func merkleBranch(hashes []bitcoin.Hash, position int) []bitcoin.Hash {
+	branch := make([]bitcoin.Hash, 0)
+	level := append([]bitcoin.Hash{}, hashes...)
+
+	for len(level) > 1 {
+		sibling := position ^ 1
+		if sibling >= len(level) {
+			// The last hash of a level with an odd number of hashes is
+			// paired with itself.
+			sibling = position
+		}
+
+		branch = append(branch, level[sibling])
+
+		level = nextMerkleLevel(level)
+		position = position / 2
+	}
+
+	return branch
+}
+
+// nextMerkleLevel computes the next level of the merkle tree. The last
+// hash of a level with an odd number of hashes is paired with itself.
+func nextMerkleLevel(level []bitcoin.Hash) []bitcoin.Hash {
+	next := make([]bitcoin.Hash, 0, (len(level)+1)/2)
+
+	for i := 0; i < len(level); i += 2 {
+		left := level[i]
+		right := left
+		if i+1 < len(level) {
+			right = level[i+1]
+		}
+
+		next = append(next, bitcoin.ComputeHash(append(left[:], right[:]...)))
+	}
+
+	return next
+}
+
+// medianTimePast returns the median time of the given block and up to ten
+// preceding blocks, as defined by BIP-113.
+func (c *Chain) medianTimePast(height uint) uint32 {
+	times := make([]uint32, 0, 11)
+
+	for i := 0; i < 11 && uint(i) <= height; i++ {
+		times = append(times, c.blocks[height-uint(i)].header.Time)
+	}
+
+	sort.Slice(times, func(i, j int) bool { return times[i] < times[j] })
+
+	return times[len(times)/2]
+}
+
+// faucetScript returns the script the block subsidies are paid to. This is
+// a P2WSH script of a witness script consisting of a single OP_TRUE, so it
+// can be spent without any signature.
+func faucetScript() (bitcoin.Script, error) {
+	return bitcoin.PayToWitnessScriptHash(
+		bitcoin.WitnessScriptHash(faucetWitnessScript),
+	)
+}
+
+// faucetWitnessScript is the witness script of the faucet script.
+var faucetWitnessScript = []byte{txscript.OP_TRUE}
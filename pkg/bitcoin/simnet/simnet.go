@@ -0,0 +1,695 @@
+// Package simnet provides an in-memory Bitcoin network simulator implementing
+// bitcoin.Chain. Unlike canned stubs, the simulator keeps a real UTXO set,
+// a mempool and a chain of mined blocks with valid headers, proof of work
+// and merkle roots. Broadcast transactions are validated the same way
+// a Bitcoin node would do it, including script and witness signature
+// verification, so flows like deposit sweeps, redemptions and SPV proofs
+// can be exercised end-to-end against believable Bitcoin behavior.
+//
+// The simulator mimics the regtest network: blocks are mined on demand,
+// with the minimum difficulty, and coinbase outputs mature after 100 blocks.
+// Block subsidies are paid to a faucet that can fund arbitrary scripts.
+package simnet
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/keep-network/keep-core/pkg/bitcoin"
+)
+
+const (
+	// coinbaseMaturity is the number of blocks required before a coinbase
+	// output can be spent.
+	coinbaseMaturity = 100
+	// initialBlockSubsidy is the block subsidy, in satoshis, paid before
+	// the first halving.
+	initialBlockSubsidy = 50 * 100000000
+	// subsidyHalvingInterval is the number of blocks after which the block
+	// subsidy is halved. This is the regtest value.
+	subsidyHalvingInterval = 150
+	// genesisBlockTime is the Unix timestamp of the genesis block. This is
+	// the regtest value.
+	genesisBlockTime = 1296688602
+	// blockInterval is the number of seconds between subsequent blocks.
+	blockInterval = 600
+	// minimumDifficultyBits is the compact representation of the easiest
+	// target allowed by the regtest network.
+	minimumDifficultyBits = 0x207fffff
+	// minRelaySatPerVByteFee is the minimum fee rate of transactions accepted
+	// to the mempool. It is also the minimum fee rate increment required
+	// to replace mempool transactions.
+	minRelaySatPerVByteFee = 1
+	// defaultSatPerVByteFee is the fee rate returned by the fee estimation
+	// unless set explicitly.
+	defaultSatPerVByteFee = 1
+)
+
+// block represents a mined block. The first transaction is the coinbase.
+type block struct {
+	header       *bitcoin.BlockHeader
+	transactions []*bitcoin.Transaction
+}
+
+// utxo represents an unspent output of a confirmed transaction.
+type utxo struct {
+	output   *bitcoin.TransactionOutput
+	height   uint
+	coinbase bool
+}
+
+// mempoolEntry represents a transaction waiting in the mempool.
+type mempoolEntry struct {
+	transaction *bitcoin.Transaction
+	fee         int64
+}
+
+// Chain is an in-memory Bitcoin network simulator implementing bitcoin.Chain.
+type Chain struct {
+	mutex sync.Mutex
+
+	blocks []*block
+	// utxos is the UTXO set of the current chain tip.
+	utxos map[bitcoin.TransactionOutpoint]*utxo
+	// transactionHeights indexes heights of confirmed transactions.
+	transactionHeights map[bitcoin.Hash]uint
+	// mempool holds unconfirmed transactions in the order they were accepted,
+	// so parents always precede their children.
+	mempool []*mempoolEntry
+
+	satPerVByteFee int64
+	// extraNonce is included in coinbase transactions to make blocks mined
+	// at the same height, e.g. during a reorg, distinct.
+	extraNonce int64
+}
+
+var _ bitcoin.Chain = (*Chain)(nil)
+
+// New creates a new simulated Bitcoin chain. The chain starts with the
+// genesis block followed by enough blocks for the genesis coinbase output
+// to mature, so the faucet can fund scripts right away.
+func New() (*Chain, error) {
+	chain := &Chain{
+		utxos:              make(map[bitcoin.TransactionOutpoint]*utxo),
+		transactionHeights: make(map[bitcoin.Hash]uint),
+		satPerVByteFee:     defaultSatPerVByteFee,
+	}
+
+	for i := 0; i <= coinbaseMaturity; i++ {
+		if err := chain.mineBlock(nil); err != nil {
+			return nil, fmt.Errorf("cannot mine initial blocks: [%v]", err)
+		}
+	}
+
+	return chain, nil
+}
+
+// MineBlocks mines the given number of blocks. The first block includes
+// all transactions waiting in the mempool. Subsequent blocks contain only
+// the coinbase transaction.
+func (c *Chain) MineBlocks(count uint) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	for i := uint(0); i < count; i++ {
+		if err := c.mineBlock(c.mempool); err != nil {
+			return fmt.Errorf("cannot mine block: [%v]", err)
+		}
+
+		c.mempool = nil
+	}
+
+	return nil
+}
+
+// Reorg replaces the given number of blocks at the chain tip with a longer
+// chain of the given length. Transactions of disconnected blocks are returned
+// to the mempool, before transactions already waiting there, as long as they
+// remain valid on top of the new chain. Blocks of the new chain contain only
+// the coinbase transaction so the returned transactions remain unconfirmed
+// until further blocks are mined.
+func (c *Chain) Reorg(depth uint, length uint) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if depth >= uint(len(c.blocks)) {
+		return fmt.Errorf(
+			"reorg depth [%v] must be lower than the number of blocks [%v]",
+			depth,
+			len(c.blocks),
+		)
+	}
+
+	if length <= depth {
+		return fmt.Errorf(
+			"new chain length [%v] must be greater than the reorg depth [%v]",
+			length,
+			depth,
+		)
+	}
+
+	forkHeight := uint(len(c.blocks)) - depth
+	disconnectedBlocks := c.blocks[forkHeight:]
+	c.blocks = c.blocks[:forkHeight]
+	c.rebuildIndexes()
+
+	returnedTransactions := make([]*bitcoin.Transaction, 0)
+	for _, disconnectedBlock := range disconnectedBlocks {
+		// Skip the coinbase as it is no longer valid outside its block.
+		returnedTransactions = append(
+			returnedTransactions,
+			disconnectedBlock.transactions[1:]...,
+		)
+	}
+
+	for i := uint(0); i < length; i++ {
+		if err := c.mineBlock(nil); err != nil {
+			return fmt.Errorf("cannot mine block: [%v]", err)
+		}
+	}
+
+	previousMempool := c.mempool
+	c.mempool = nil
+
+	for _, entry := range previousMempool {
+		returnedTransactions = append(returnedTransactions, entry.transaction)
+	}
+
+	for _, transaction := range returnedTransactions {
+		// Transactions that are no longer valid are dropped, as a node
+		// would do.
+		_ = c.acceptTransaction(transaction)
+	}
+
+	return nil
+}
+
+// SetSatPerVByteFee sets the fee rate returned by the fee estimation.
+func (c *Chain) SetSatPerVByteFee(satPerVByteFee int64) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.satPerVByteFee = satPerVByteFee
+}
+
+// GetTransaction gets the transaction with the given transaction hash.
+// Both confirmed and mempool transactions are considered.
+func (c *Chain) GetTransaction(
+	transactionHash bitcoin.Hash,
+) (*bitcoin.Transaction, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if height, ok := c.transactionHeights[transactionHash]; ok {
+		for _, transaction := range c.blocks[height].transactions {
+			if transaction.Hash() == transactionHash {
+				return transaction, nil
+			}
+		}
+	}
+
+	if entry, ok := c.mempoolEntry(transactionHash); ok {
+		return entry.transaction, nil
+	}
+
+	return nil, fmt.Errorf(
+		"transaction [%s] not found",
+		transactionHash.Hex(bitcoin.ReversedByteOrder),
+	)
+}
+
+// GetTransactionConfirmations gets the number of confirmations for the
+// transaction with the given transaction hash. Mempool transactions have
+// zero confirmations.
+func (c *Chain) GetTransactionConfirmations(
+	transactionHash bitcoin.Hash,
+) (uint, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if height, ok := c.transactionHeights[transactionHash]; ok {
+		return c.tipHeight() - height + 1, nil
+	}
+
+	if _, ok := c.mempoolEntry(transactionHash); ok {
+		return 0, nil
+	}
+
+	return 0, fmt.Errorf(
+		"transaction [%s] not found",
+		transactionHash.Hex(bitcoin.ReversedByteOrder),
+	)
+}
+
+// BroadcastTransaction validates the given transaction and accepts it to
+// the mempool. Transactions conflicting with mempool transactions are
+// accepted only if they satisfy the replace-by-fee rules, i.e. pay a higher
+// fee than all replaced transactions and their descendants together,
+// increased by the minimum relay fee for their own size.
+func (c *Chain) BroadcastTransaction(transaction *bitcoin.Transaction) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	return c.acceptTransaction(transaction)
+}
+
+// GetLatestBlockHeight gets the height of the latest block (tip).
+func (c *Chain) GetLatestBlockHeight() (uint, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	return c.tipHeight(), nil
+}
+
+// GetBlockHeader gets the block header for the given block height.
+func (c *Chain) GetBlockHeader(blockHeight uint) (*bitcoin.BlockHeader, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if blockHeight >= uint(len(c.blocks)) {
+		return nil, fmt.Errorf("block [%v] not found", blockHeight)
+	}
+
+	header := *c.blocks[blockHeight].header
+
+	return &header, nil
+}
+
+// GetTransactionMerkleProof gets the Merkle proof for a given transaction
+// included in the block with the given height.
+func (c *Chain) GetTransactionMerkleProof(
+	transactionHash bitcoin.Hash,
+	blockHeight uint,
+) (*bitcoin.TransactionMerkleProof, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if blockHeight >= uint(len(c.blocks)) {
+		return nil, fmt.Errorf("block [%v] not found", blockHeight)
+	}
+
+	transactionHashes := transactionHashes(c.blocks[blockHeight].transactions)
+
+	for position, hash := range transactionHashes {
+		if hash != transactionHash {
+			continue
+		}
+
+		branch := merkleBranch(transactionHashes, position)
+
+		merkleNodes := make([]string, len(branch))
+		for i, node := range branch {
+			merkleNodes[i] = node.Hex(bitcoin.ReversedByteOrder)
+		}
+
+		return &bitcoin.TransactionMerkleProof{
+			BlockHeight: blockHeight,
+			MerkleNodes: merkleNodes,
+			Position:    uint(position),
+		}, nil
+	}
+
+	return nil, fmt.Errorf(
+		"transaction [%s] not found in block [%v]",
+		transactionHash.Hex(bitcoin.ReversedByteOrder),
+		blockHeight,
+	)
+}
+
+// GetTransactionsForPublicKeyHash gets the confirmed transactions paying to
+// or spending from the P2PKH or P2WPKH script of the given public key hash.
+// The returned transactions are ordered by block height in the ascending
+// order and limited to the latest `limit` ones.
+func (c *Chain) GetTransactionsForPublicKeyHash(
+	publicKeyHash [20]byte,
+	limit int,
+) ([]*bitcoin.Transaction, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	scripts, err := publicKeyHashScripts(publicKeyHash)
+	if err != nil {
+		return nil, err
+	}
+
+	transactions := c.confirmedScriptHistory(scripts)
+
+	if len(transactions) > limit {
+		transactions = transactions[len(transactions)-limit:]
+	}
+
+	return transactions, nil
+}
+
+// GetTxHashesForPublicKeyHash gets hashes of confirmed transactions paying
+// to or spending from the P2PKH or P2WPKH script of the given public key
+// hash. The returned hashes are ordered by block height in the ascending
+// order.
+func (c *Chain) GetTxHashesForPublicKeyHash(
+	publicKeyHash [20]byte,
+) ([]bitcoin.Hash, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	scripts, err := publicKeyHashScripts(publicKeyHash)
+	if err != nil {
+		return nil, err
+	}
+
+	return transactionHashes(c.confirmedScriptHistory(scripts)), nil
+}
+
+// GetMempoolForPublicKeyHash gets the mempool transactions paying to or
+// spending from the P2PKH or P2WPKH script of the given public key hash.
+func (c *Chain) GetMempoolForPublicKeyHash(
+	publicKeyHash [20]byte,
+) ([]*bitcoin.Transaction, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	scripts, err := publicKeyHashScripts(publicKeyHash)
+	if err != nil {
+		return nil, err
+	}
+
+	transactions := make([]*bitcoin.Transaction, 0)
+	for _, entry := range c.mempool {
+		if c.touchesScripts(entry.transaction, scripts) {
+			transactions = append(transactions, entry.transaction)
+		}
+	}
+
+	return transactions, nil
+}
+
+// GetUtxosForPublicKeyHash gets unspent outputs of confirmed transactions
+// locked using the P2PKH or P2WPKH script of the given public key hash.
+// Outputs spent by mempool transactions are not returned. The returned
+// UTXOs are ordered by block height in the ascending order.
+func (c *Chain) GetUtxosForPublicKeyHash(
+	publicKeyHash [20]byte,
+) ([]*bitcoin.UnspentTransactionOutput, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	scripts, err := publicKeyHashScripts(publicKeyHash)
+	if err != nil {
+		return nil, err
+	}
+
+	type heightUtxo struct {
+		height uint
+		utxo   *bitcoin.UnspentTransactionOutput
+	}
+
+	items := make([]*heightUtxo, 0)
+	for outpoint, unspent := range c.utxos {
+		if !matchesScripts(unspent.output.PublicKeyScript, scripts) {
+			continue
+		}
+
+		if _, spent := c.mempoolSpender(outpoint); spent {
+			continue
+		}
+
+		outpointCopy := outpoint
+		items = append(items, &heightUtxo{
+			height: unspent.height,
+			utxo: &bitcoin.UnspentTransactionOutput{
+				Outpoint: &outpointCopy,
+				Value:    unspent.output.Value,
+			},
+		})
+	}
+
+	// Order by height and then deterministically within the same block.
+	sort.Slice(items, func(i, j int) bool {
+		if items[i].height != items[j].height {
+			return items[i].height < items[j].height
+		}
+
+		iHash := items[i].utxo.Outpoint.TransactionHash
+		jHash := items[j].utxo.Outpoint.TransactionHash
+		if iHash != jHash {
+			return iHash.String() < jHash.String()
+		}
+
+		return items[i].utxo.Outpoint.OutputIndex <
+			items[j].utxo.Outpoint.OutputIndex
+	})
+
+	utxos := make([]*bitcoin.UnspentTransactionOutput, len(items))
+	for i, item := range items {
+		utxos[i] = item.utxo
+	}
+
+	return utxos, nil
+}
+
+// GetMempoolUtxosForPublicKeyHash gets unspent outputs of mempool
+// transactions locked using the P2PKH or P2WPKH script of the given public
+// key hash. Outputs spent by other mempool transactions are not returned.
+func (c *Chain) GetMempoolUtxosForPublicKeyHash(
+	publicKeyHash [20]byte,
+) ([]*bitcoin.UnspentTransactionOutput, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	scripts, err := publicKeyHashScripts(publicKeyHash)
+	if err != nil {
+		return nil, err
+	}
+
+	utxos := make([]*bitcoin.UnspentTransactionOutput, 0)
+	for _, entry := range c.mempool {
+		transactionHash := entry.transaction.Hash()
+
+		for i, output := range entry.transaction.Outputs {
+			if !matchesScripts(output.PublicKeyScript, scripts) {
+				continue
+			}
+
+			outpoint := bitcoin.TransactionOutpoint{
+				TransactionHash: transactionHash,
+				OutputIndex:     uint32(i),
+			}
+
+			if _, spent := c.mempoolSpender(outpoint); spent {
+				continue
+			}
+
+			utxos = append(utxos, &bitcoin.UnspentTransactionOutput{
+				Outpoint: &outpoint,
+				Value:    output.Value,
+			})
+		}
+	}
+
+	return utxos, nil
+}
+
+// EstimateSatPerVByteFee returns the fee rate set using SetSatPerVByteFee,
+// regardless of the given number of blocks.
+func (c *Chain) EstimateSatPerVByteFee(blocks uint32) (int64, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	return c.satPerVByteFee, nil
+}
+
+// GetCoinbaseTxHash gets the hash of the coinbase transaction for the given
+// block height.
+func (c *Chain) GetCoinbaseTxHash(blockHeight uint) (bitcoin.Hash, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if blockHeight >= uint(len(c.blocks)) {
+		return bitcoin.Hash{}, fmt.Errorf("block [%v] not found", blockHeight)
+	}
+
+	return c.blocks[blockHeight].transactions[0].Hash(), nil
+}
+
+// tipHeight returns the height of the chain tip.
+func (c *Chain) tipHeight() uint {
+	return uint(len(c.blocks)) - 1
+}
+
+// rebuildIndexes rebuilds the UTXO set and the transaction index from
+// scratch, based on the current blocks.
+func (c *Chain) rebuildIndexes() {
+	c.utxos = make(map[bitcoin.TransactionOutpoint]*utxo)
+	c.transactionHeights = make(map[bitcoin.Hash]uint)
+
+	for height, connectedBlock := range c.blocks {
+		c.connectTransactions(uint(height), connectedBlock.transactions)
+	}
+}
+
+// connectTransactions updates the UTXO set and the transaction index with
+// transactions of the block at the given height.
+func (c *Chain) connectTransactions(
+	height uint,
+	transactions []*bitcoin.Transaction,
+) {
+	for i, transaction := range transactions {
+		transactionHash := transaction.Hash()
+		c.transactionHeights[transactionHash] = height
+
+		// The coinbase input does not spend anything.
+		if i > 0 {
+			for _, input := range transaction.Inputs {
+				delete(c.utxos, *input.Outpoint)
+			}
+		}
+
+		for j, output := range transaction.Outputs {
+			// Provably unspendable outputs never enter the UTXO set.
+			if isUnspendable(output.PublicKeyScript) {
+				continue
+			}
+
+			c.utxos[bitcoin.TransactionOutpoint{
+				TransactionHash: transactionHash,
+				OutputIndex:     uint32(j),
+			}] = &utxo{
+				output:   output,
+				height:   height,
+				coinbase: i == 0,
+			}
+		}
+	}
+}
+
+// mempoolEntry returns the mempool entry of the transaction with the given
+// hash.
+func (c *Chain) mempoolEntry(transactionHash bitcoin.Hash) (
+	*mempoolEntry,
+	bool,
+) {
+	for _, entry := range c.mempool {
+		if entry.transaction.Hash() == transactionHash {
+			return entry, true
+		}
+	}
+
+	return nil, false
+}
+
+// mempoolSpender returns the mempool entry of the transaction spending
+// the given outpoint.
+func (c *Chain) mempoolSpender(outpoint bitcoin.TransactionOutpoint) (
+	*mempoolEntry,
+	bool,
+) {
+	for _, entry := range c.mempool {
+		for _, input := range entry.transaction.Inputs {
+			if *input.Outpoint == outpoint {
+				return entry, true
+			}
+		}
+	}
+
+	return nil, false
+}
+
+// previousOutput returns the output pointed by the given outpoint, looking
+// at confirmed and mempool transactions.
+func (c *Chain) previousOutput(
+	outpoint bitcoin.TransactionOutpoint,
+) (*bitcoin.TransactionOutput, bool) {
+	if height, ok := c.transactionHeights[outpoint.TransactionHash]; ok {
+		for _, transaction := range c.blocks[height].transactions {
+			if transaction.Hash() == outpoint.TransactionHash &&
+				int(outpoint.OutputIndex) < len(transaction.Outputs) {
+				return transaction.Outputs[outpoint.OutputIndex], true
+			}
+		}
+	}
+
+	if entry, ok := c.mempoolEntry(outpoint.TransactionHash); ok &&
+		int(outpoint.OutputIndex) < len(entry.transaction.Outputs) {
+		return entry.transaction.Outputs[outpoint.OutputIndex], true
+	}
+
+	return nil, false
+}
+
+// confirmedScriptHistory returns confirmed transactions paying to or
+// spending from any of the given scripts, ordered by block height.
+func (c *Chain) confirmedScriptHistory(
+	scripts []bitcoin.Script,
+) []*bitcoin.Transaction {
+	transactions := make([]*bitcoin.Transaction, 0)
+
+	for _, connectedBlock := range c.blocks {
+		for _, transaction := range connectedBlock.transactions {
+			if c.touchesScripts(transaction, scripts) {
+				transactions = append(transactions, transaction)
+			}
+		}
+	}
+
+	return transactions
+}
+
+// touchesScripts checks whether the given transaction pays to or spends
+// from any of the given scripts.
+func (c *Chain) touchesScripts(
+	transaction *bitcoin.Transaction,
+	scripts []bitcoin.Script,
+) bool {
+	for _, output := range transaction.Outputs {
+		if matchesScripts(output.PublicKeyScript, scripts) {
+			return true
+		}
+	}
+
+	for _, input := range transaction.Inputs {
+		previousOutput, ok := c.previousOutput(*input.Outpoint)
+		if ok && matchesScripts(previousOutput.PublicKeyScript, scripts) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// publicKeyHashScripts returns the P2PKH and P2WPKH scripts of the given
+// public key hash.
+func publicKeyHashScripts(publicKeyHash [20]byte) ([]bitcoin.Script, error) {
+	p2pkh, err := bitcoin.PayToPublicKeyHash(publicKeyHash)
+	if err != nil {
+		return nil, fmt.Errorf("cannot build P2PKH script: [%v]", err)
+	}
+
+	p2wpkh, err := bitcoin.PayToWitnessPublicKeyHash(publicKeyHash)
+	if err != nil {
+		return nil, fmt.Errorf("cannot build P2WPKH script: [%v]", err)
+	}
+
+	return []bitcoin.Script{p2pkh, p2wpkh}, nil
+}
+
+// matchesScripts checks whether the given script is equal to any of the
+// given scripts.
+func matchesScripts(script bitcoin.Script, scripts []bitcoin.Script) bool {
+	for _, candidate := range scripts {
+		if string(candidate) == string(script) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// transactionHashes returns hashes of the given transactions.
+func transactionHashes(transactions []*bitcoin.Transaction) []bitcoin.Hash {
+	hashes := make([]bitcoin.Hash, len(transactions))
+	for i, transaction := range transactions {
+		hashes[i] = transaction.Hash()
+	}
+
+	return hashes
+}
@@ -0,0 +1,550 @@
+package simnet
+
+import (
+	"crypto/ecdsa"
+	"crypto/rand"
+	"math/big"
+	"testing"
+
+	"github.com/btcsuite/btcd/btcec"
+
+	"github.com/keep-network/keep-core/internal/testutils"
+	"github.com/keep-network/keep-core/pkg/bitcoin"
+)
+
+func TestNew(t *testing.T) {
+	chain := newTestChain(t)
+
+	latestBlockHeight, err := chain.GetLatestBlockHeight()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testutils.AssertUintsEqual(
+		t,
+		"latest block height",
+		coinbaseMaturity,
+		uint64(latestBlockHeight),
+	)
+
+	for height := uint(1); height <= latestBlockHeight; height++ {
+		previousHeader, err := chain.GetBlockHeader(height - 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		header, err := chain.GetBlockHeader(height)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if header.PreviousBlockHeaderHash != previousHeader.Hash() {
+			t.Fatalf("block [%v] does not point to the previous block", height)
+		}
+
+		if hashToBig(header.Hash()).Cmp(header.Target()) > 0 {
+			t.Fatalf("block [%v] does not meet its target", height)
+		}
+	}
+}
+
+func TestChain_FundAndMine(t *testing.T) {
+	chain := newTestChain(t)
+	key := newTestKey(t)
+
+	fundingTransaction, err := chain.Fund(key.p2wpkh, 100000)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assertConfirmations(t, chain, fundingTransaction.Hash(), 0)
+
+	mempool, err := chain.GetMempoolForPublicKeyHash(key.publicKeyHash)
+	if err != nil {
+		t.Fatal(err)
+	}
+	testutils.AssertIntsEqual(t, "mempool transactions", 1, len(mempool))
+
+	mempoolUtxos, err := chain.GetMempoolUtxosForPublicKeyHash(key.publicKeyHash)
+	if err != nil {
+		t.Fatal(err)
+	}
+	testutils.AssertIntsEqual(t, "mempool UTXOs", 1, len(mempoolUtxos))
+
+	if err := chain.MineBlocks(2); err != nil {
+		t.Fatal(err)
+	}
+
+	assertConfirmations(t, chain, fundingTransaction.Hash(), 2)
+
+	utxos, err := chain.GetUtxosForPublicKeyHash(key.publicKeyHash)
+	if err != nil {
+		t.Fatal(err)
+	}
+	testutils.AssertIntsEqual(t, "UTXOs", 1, len(utxos))
+	testutils.AssertIntsEqual(t, "UTXO value", 100000, int(utxos[0].Value))
+
+	transactions, err := chain.GetTransactionsForPublicKeyHash(
+		key.publicKeyHash,
+		5,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	testutils.AssertIntsEqual(t, "transactions", 1, len(transactions))
+
+	// The funding transaction is the second transaction in its block,
+	// after the coinbase.
+	latestBlockHeight, err := chain.GetLatestBlockHeight()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	proof, err := chain.GetTransactionMerkleProof(
+		fundingTransaction.Hash(),
+		latestBlockHeight-1,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	testutils.AssertUintsEqual(t, "position", 1, uint64(proof.Position))
+}
+
+func TestChain_BroadcastTransaction(t *testing.T) {
+	chain := newTestChain(t)
+	key := newTestKey(t)
+	recipient := newTestKey(t)
+
+	utxo := fundAndConfirm(t, chain, key.p2wpkh, 100000)
+
+	transaction := key.spend(t, chain, utxo, recipient.p2wpkh, 99000)
+
+	if err := chain.BroadcastTransaction(transaction); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := chain.MineBlocks(1); err != nil {
+		t.Fatal(err)
+	}
+
+	assertConfirmations(t, chain, transaction.Hash(), 1)
+
+	utxos, err := chain.GetUtxosForPublicKeyHash(key.publicKeyHash)
+	if err != nil {
+		t.Fatal(err)
+	}
+	testutils.AssertIntsEqual(t, "sender UTXOs", 0, len(utxos))
+
+	utxos, err = chain.GetUtxosForPublicKeyHash(recipient.publicKeyHash)
+	if err != nil {
+		t.Fatal(err)
+	}
+	testutils.AssertIntsEqual(t, "recipient UTXOs", 1, len(utxos))
+
+	// The sender's history contains both the funding and spending
+	// transactions.
+	txHashes, err := chain.GetTxHashesForPublicKeyHash(key.publicKeyHash)
+	if err != nil {
+		t.Fatal(err)
+	}
+	testutils.AssertIntsEqual(t, "sender transactions", 2, len(txHashes))
+
+	if err := chain.BroadcastTransaction(transaction); err == nil {
+		t.Fatal("expected rejection of already confirmed transaction")
+	}
+}
+
+func TestChain_BroadcastTransaction_Invalid(t *testing.T) {
+	chain := newTestChain(t)
+	key := newTestKey(t)
+	recipient := newTestKey(t)
+
+	utxo := fundAndConfirm(t, chain, key.p2wpkh, 100000)
+
+	var tests = map[string]func() *bitcoin.Transaction{
+		"invalid signature": func() *bitcoin.Transaction {
+			transaction := key.spend(t, chain, utxo, recipient.p2wpkh, 99000)
+			// Changing the output after signing invalidates the signature.
+			transaction.Outputs[0].Value = 98000
+			return transaction
+		},
+		"outputs exceed inputs": func() *bitcoin.Transaction {
+			return key.spend(t, chain, utxo, recipient.p2wpkh, 100001)
+		},
+		"fee below minimum relay fee": func() *bitcoin.Transaction {
+			return key.spend(t, chain, utxo, recipient.p2wpkh, 99999)
+		},
+		"missing input": func() *bitcoin.Transaction {
+			transaction := key.spend(t, chain, utxo, recipient.p2wpkh, 99000)
+			transaction.Inputs[0].Outpoint = &bitcoin.TransactionOutpoint{
+				TransactionHash: bitcoin.Hash{1},
+				OutputIndex:     0,
+			}
+			return transaction
+		},
+		"immature coinbase": func() *bitcoin.Transaction {
+			latestBlockHeight, err := chain.GetLatestBlockHeight()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			coinbaseTxHash, err := chain.GetCoinbaseTxHash(latestBlockHeight)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			return &bitcoin.Transaction{
+				Version: 1,
+				Inputs: []*bitcoin.TransactionInput{
+					{
+						Outpoint: &bitcoin.TransactionOutpoint{
+							TransactionHash: coinbaseTxHash,
+							OutputIndex:     0,
+						},
+						Witness:  [][]byte{faucetWitnessScript},
+						Sequence: 0xffffffff,
+					},
+				},
+				Outputs: []*bitcoin.TransactionOutput{
+					{Value: 100000, PublicKeyScript: recipient.p2wpkh},
+				},
+			}
+		},
+	}
+
+	for testName, newTransaction := range tests {
+		t.Run(testName, func(t *testing.T) {
+			if err := chain.BroadcastTransaction(newTransaction()); err == nil {
+				t.Fatal("expected error")
+			}
+		})
+	}
+}
+
+func TestChain_BroadcastTransaction_Replacement(t *testing.T) {
+	chain := newTestChain(t)
+	key := newTestKey(t)
+	recipient := newTestKey(t)
+
+	utxo := fundAndConfirm(t, chain, key.p2wpkh, 100000)
+
+	original := key.spend(t, chain, utxo, recipient.p2wpkh, 99000)
+	if err := chain.BroadcastTransaction(original); err != nil {
+		t.Fatal(err)
+	}
+
+	// A replacement paying the same fee is rejected.
+	sameFee := key.spend(t, chain, utxo, key.p2wpkh, 99000)
+	if err := chain.BroadcastTransaction(sameFee); err == nil {
+		t.Fatal("expected rejection of replacement paying the same fee")
+	}
+
+	replacement := key.spend(t, chain, utxo, recipient.p2wpkh, 98000)
+	if err := chain.BroadcastTransaction(replacement); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := chain.GetTransaction(original.Hash()); err == nil {
+		t.Fatal("expected replaced transaction to be evicted")
+	}
+
+	assertConfirmations(t, chain, replacement.Hash(), 0)
+}
+
+func TestChain_BroadcastTransaction_Locktime(t *testing.T) {
+	chain := newTestChain(t)
+	recipient := newTestKey(t)
+
+	script, err := faucetScript()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Outputs locked using the faucet script can be spent without
+	// signatures so the locktime can be freely set.
+	utxo := fundAndConfirm(t, chain, script, 100000)
+
+	latestBlockHeight, err := chain.GetLatestBlockHeight()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	transaction := &bitcoin.Transaction{
+		Version: 1,
+		Inputs: []*bitcoin.TransactionInput{
+			{
+				Outpoint: utxo.Outpoint,
+				Witness:  [][]byte{faucetWitnessScript},
+				Sequence: 0xfffffffe,
+			},
+		},
+		Outputs: []*bitcoin.TransactionOutput{
+			{Value: 99000, PublicKeyScript: recipient.p2wpkh},
+		},
+		Locktime: uint32(latestBlockHeight + 3),
+	}
+
+	if err := chain.BroadcastTransaction(transaction); err == nil {
+		t.Fatal("expected rejection of non-final transaction")
+	}
+
+	// The transaction can be included in a block with a height greater
+	// than the locktime.
+	if err := chain.MineBlocks(3); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := chain.BroadcastTransaction(transaction); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestChain_Reorg(t *testing.T) {
+	chain := newTestChain(t)
+	key := newTestKey(t)
+
+	fundingTransaction, err := chain.Fund(key.p2wpkh, 100000)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := chain.MineBlocks(2); err != nil {
+		t.Fatal(err)
+	}
+
+	latestBlockHeight, err := chain.GetLatestBlockHeight()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	headerBefore, err := chain.GetBlockHeader(latestBlockHeight - 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := chain.Reorg(2, 3); err != nil {
+		t.Fatal(err)
+	}
+
+	headerAfter, err := chain.GetBlockHeader(latestBlockHeight - 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if headerBefore.Hash() == headerAfter.Hash() {
+		t.Fatal("expected block to be replaced")
+	}
+
+	newLatestBlockHeight, err := chain.GetLatestBlockHeight()
+	if err != nil {
+		t.Fatal(err)
+	}
+	testutils.AssertUintsEqual(
+		t,
+		"latest block height",
+		uint64(latestBlockHeight+1),
+		uint64(newLatestBlockHeight),
+	)
+
+	// The funding transaction returned to the mempool.
+	assertConfirmations(t, chain, fundingTransaction.Hash(), 0)
+
+	if err := chain.MineBlocks(1); err != nil {
+		t.Fatal(err)
+	}
+
+	assertConfirmations(t, chain, fundingTransaction.Hash(), 1)
+
+	if err := chain.Reorg(newLatestBlockHeight+2, newLatestBlockHeight+3); err == nil {
+		t.Fatal("expected rejection of reorg deeper than the chain")
+	}
+
+	if err := chain.Reorg(2, 2); err == nil {
+		t.Fatal("expected rejection of reorg to a chain that is not longer")
+	}
+}
+
+func TestChain_SpvProof(t *testing.T) {
+	chain := newTestChain(t)
+
+	// Put several transactions in one block so the merkle branch is
+	// not trivial.
+	var transactions []*bitcoin.Transaction
+	for i := 0; i < 4; i++ {
+		key := newTestKey(t)
+
+		transaction, err := chain.Fund(key.p2wpkh, int64(100000+i))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		transactions = append(transactions, transaction)
+	}
+
+	requiredConfirmations := uint(6)
+	if err := chain.MineBlocks(requiredConfirmations); err != nil {
+		t.Fatal(err)
+	}
+
+	for i, transaction := range transactions {
+		provenTransaction, proof, err := bitcoin.AssembleSpvProof(
+			transaction.Hash(),
+			requiredConfirmations,
+			chain,
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		testutils.AssertUintsEqual(
+			t,
+			"transaction index in block",
+			uint64(i+1),
+			uint64(proof.TxIndexInBlock),
+		)
+
+		var firstHeader bitcoin.BlockHeader
+		var rawHeader [bitcoin.BlockHeaderByteLength]byte
+		copy(rawHeader[:], proof.BitcoinHeaders)
+		firstHeader.Deserialize(rawHeader)
+
+		if err := bitcoin.VerifySpvProof(
+			provenTransaction,
+			proof,
+			requiredConfirmations,
+			big.NewInt(int64(requiredConfirmations)),
+			firstHeader.Difficulty(),
+			firstHeader.Difficulty(),
+		); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// testKey is a key controlling a P2WPKH output in tests.
+type testKey struct {
+	privateKey    *ecdsa.PrivateKey
+	publicKeyHash [20]byte
+	p2wpkh        bitcoin.Script
+}
+
+func newTestKey(t *testing.T) *testKey {
+	privateKey, err := btcec.NewPrivateKey(btcec.S256())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	publicKeyHash := bitcoin.PublicKeyHash(&privateKey.ToECDSA().PublicKey)
+
+	p2wpkh, err := bitcoin.PayToWitnessPublicKeyHash(publicKeyHash)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return &testKey{
+		privateKey:    privateKey.ToECDSA(),
+		publicKeyHash: publicKeyHash,
+		p2wpkh:        p2wpkh,
+	}
+}
+
+// spend builds a transaction spending the given UTXO controlled by the key
+// and paying the given value to the given script.
+func (tk *testKey) spend(
+	t *testing.T,
+	chain bitcoin.Chain,
+	utxo *bitcoin.UnspentTransactionOutput,
+	script bitcoin.Script,
+	value int64,
+) *bitcoin.Transaction {
+	builder := bitcoin.NewTransactionBuilder(chain)
+
+	if err := builder.AddPublicKeyHashInput(utxo); err != nil {
+		t.Fatal(err)
+	}
+
+	builder.AddOutput(&bitcoin.TransactionOutput{
+		Value:           value,
+		PublicKeyScript: script,
+	})
+
+	sigHashes, err := builder.ComputeSignatureHashes()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	signatures := make([]*bitcoin.SignatureContainer, len(sigHashes))
+	for i, sigHash := range sigHashes {
+		r, s, err := ecdsa.Sign(rand.Reader, tk.privateKey, sigHash.Bytes())
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		signatures[i] = &bitcoin.SignatureContainer{
+			R:         r,
+			S:         s,
+			PublicKey: &tk.privateKey.PublicKey,
+		}
+	}
+
+	transaction, err := builder.AddSignatures(signatures)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return transaction
+}
+
+func newTestChain(t *testing.T) *Chain {
+	chain, err := New()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return chain
+}
+
+// fundAndConfirm funds the given script with the given value and mines
+// a block confirming the funding transaction.
+func fundAndConfirm(
+	t *testing.T,
+	chain *Chain,
+	script bitcoin.Script,
+	value int64,
+) *bitcoin.UnspentTransactionOutput {
+	transaction, err := chain.Fund(script, value)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := chain.MineBlocks(1); err != nil {
+		t.Fatal(err)
+	}
+
+	return &bitcoin.UnspentTransactionOutput{
+		Outpoint: &bitcoin.TransactionOutpoint{
+			TransactionHash: transaction.Hash(),
+			OutputIndex:     0,
+		},
+		Value: value,
+	}
+}
+
+func assertConfirmations(
+	t *testing.T,
+	chain *Chain,
+	transactionHash bitcoin.Hash,
+	expectedConfirmations uint,
+) {
+	confirmations, err := chain.GetTransactionConfirmations(transactionHash)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testutils.AssertUintsEqual(
+		t,
+		"confirmations",
+		uint64(expectedConfirmations),
+		uint64(confirmations),
+	)
+}
@@ -0,0 +1,408 @@
+package simnet
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+
+	"github.com/keep-network/keep-core/pkg/bitcoin"
+)
+
+const (
+	// maxMoney is the maximum number of satoshis that can ever exist.
+	maxMoney = 21000000 * 100000000
+	// sequenceLockTimeDisabled is the BIP-68 flag disabling the relative
+	// lock-time of the input.
+	sequenceLockTimeDisabled = 1 << 31
+	// sequenceLockTimeIsSeconds is the BIP-68 flag determining the relative
+	// lock-time is expressed in units of 512 seconds instead of blocks.
+	sequenceLockTimeIsSeconds = 1 << 22
+	// sequenceLockTimeMask extracts the relative lock-time from the sequence.
+	sequenceLockTimeMask = 0x0000ffff
+	// sequenceLockTimeGranularity is the number of bits the time-based
+	// relative lock-time is shifted by.
+	sequenceLockTimeGranularity = 9
+)
+
+// acceptTransaction validates the given transaction against the chain tip
+// and the mempool and adds it to the mempool. Mempool transactions
+// conflicting with the given one are evicted, along with their descendants,
+// if the replacement rules are satisfied.
+//
+// Only P2PKH, P2SH and witness version 0 inputs are supported. Spending
+// outputs of higher witness versions, e.g. P2TR, is rejected as it would be
+// by a pre-taproot node enforcing standardness rules.
+func (c *Chain) acceptTransaction(transaction *bitcoin.Transaction) error {
+	if len(transaction.Inputs) == 0 {
+		return fmt.Errorf("transaction has no inputs")
+	}
+
+	if len(transaction.Outputs) == 0 {
+		return fmt.Errorf("transaction has no outputs")
+	}
+
+	transactionHash := transaction.Hash()
+
+	if _, ok := c.transactionHeights[transactionHash]; ok {
+		return fmt.Errorf("transaction already confirmed")
+	}
+
+	if _, ok := c.mempoolEntry(transactionHash); ok {
+		return fmt.Errorf("transaction already in the mempool")
+	}
+
+	totalOutputsValue := int64(0)
+	for i, output := range transaction.Outputs {
+		if output.Value < 0 || output.Value > maxMoney {
+			return fmt.Errorf("output [%v] has invalid value", i)
+		}
+
+		totalOutputsValue += output.Value
+		if totalOutputsValue > maxMoney {
+			return fmt.Errorf("total outputs value is out of range")
+		}
+	}
+
+	nextHeight := c.tipHeight() + 1
+	tipMedianTimePast := c.medianTimePast(c.tipHeight())
+
+	if !isFinal(transaction, nextHeight, tipMedianTimePast) {
+		return fmt.Errorf("transaction locktime is not satisfied yet")
+	}
+
+	spentOutpoints := make(map[bitcoin.TransactionOutpoint]bool)
+	previousOutputs := make(map[wire.OutPoint]*wire.TxOut)
+	conflicts := make([]*mempoolEntry, 0)
+	totalInputsValue := int64(0)
+
+	for i, input := range transaction.Inputs {
+		outpoint := *input.Outpoint
+
+		if outpoint.OutputIndex == 0xffffffff &&
+			outpoint.TransactionHash == (bitcoin.Hash{}) {
+			return fmt.Errorf("coinbase transactions cannot be broadcast")
+		}
+
+		if spentOutpoints[outpoint] {
+			return fmt.Errorf("input [%v] spends a duplicated outpoint", i)
+		}
+		spentOutpoints[outpoint] = true
+
+		previousOutput, previousHeight, err := c.spendableOutput(
+			outpoint,
+			nextHeight,
+		)
+		if err != nil {
+			return fmt.Errorf("input [%v] is invalid: [%v]", i, err)
+		}
+
+		if err := c.checkSequenceLock(
+			transaction,
+			input,
+			previousHeight,
+			nextHeight,
+			tipMedianTimePast,
+		); err != nil {
+			return fmt.Errorf("input [%v] is invalid: [%v]", i, err)
+		}
+
+		if conflict, ok := c.mempoolSpender(outpoint); ok {
+			conflicts = append(conflicts, conflict)
+		}
+
+		totalInputsValue += previousOutput.Value
+		previousOutputs[wire.OutPoint{
+			Hash:  chainhash.Hash(outpoint.TransactionHash),
+			Index: outpoint.OutputIndex,
+		}] = wire.NewTxOut(previousOutput.Value, previousOutput.PublicKeyScript)
+	}
+
+	fee := totalInputsValue - totalOutputsValue
+	if fee < 0 {
+		return fmt.Errorf(
+			"outputs value [%v] exceeds inputs value [%v]",
+			totalOutputsValue,
+			totalInputsValue,
+		)
+	}
+
+	virtualSize := transaction.VirtualSize()
+	if fee < virtualSize*minRelaySatPerVByteFee {
+		return fmt.Errorf(
+			"fee [%v] is lower than the minimum relay fee [%v]",
+			fee,
+			virtualSize*minRelaySatPerVByteFee,
+		)
+	}
+
+	if err := verifyScripts(transaction, previousOutputs); err != nil {
+		return err
+	}
+
+	evicted, err := c.checkReplacement(
+		transaction,
+		fee,
+		virtualSize,
+		conflicts,
+	)
+	if err != nil {
+		return err
+	}
+
+	c.removeFromMempool(evicted)
+	c.mempool = append(c.mempool, &mempoolEntry{
+		transaction: transaction,
+		fee:         fee,
+	})
+
+	return nil
+}
+
+// spendableOutput returns the output pointed by the given outpoint along
+// with the height of the block the output was confirmed in. Outputs of
+// mempool transactions are considered to be confirmed at the next height.
+// An error is returned if the output does not exist, is already spent by
+// a confirmed transaction or is an immature coinbase output.
+func (c *Chain) spendableOutput(
+	outpoint bitcoin.TransactionOutpoint,
+	nextHeight uint,
+) (*bitcoin.TransactionOutput, uint, error) {
+	if unspent, ok := c.utxos[outpoint]; ok {
+		if unspent.coinbase && nextHeight-unspent.height < coinbaseMaturity {
+			return nil, 0, fmt.Errorf("coinbase output is not mature yet")
+		}
+
+		return unspent.output, unspent.height, nil
+	}
+
+	if _, ok := c.transactionHeights[outpoint.TransactionHash]; ok {
+		return nil, 0, fmt.Errorf("output is already spent or does not exist")
+	}
+
+	if entry, ok := c.mempoolEntry(outpoint.TransactionHash); ok &&
+		int(outpoint.OutputIndex) < len(entry.transaction.Outputs) {
+		return entry.transaction.Outputs[outpoint.OutputIndex], nextHeight, nil
+	}
+
+	return nil, 0, fmt.Errorf("output does not exist")
+}
+
+// checkSequenceLock checks whether the relative lock-time of the given
+// input, as defined by BIP-68, is satisfied for the next block.
+func (c *Chain) checkSequenceLock(
+	transaction *bitcoin.Transaction,
+	input *bitcoin.TransactionInput,
+	previousHeight uint,
+	nextHeight uint,
+	tipMedianTimePast uint32,
+) error {
+	if transaction.Version < 2 ||
+		input.Sequence&sequenceLockTimeDisabled != 0 {
+		return nil
+	}
+
+	lockTime := input.Sequence & sequenceLockTimeMask
+
+	if input.Sequence&sequenceLockTimeIsSeconds != 0 {
+		// The time-based lock is relative to the median time past of
+		// the block preceding the one the output was confirmed in.
+		referenceHeight := previousHeight
+		if referenceHeight > 0 {
+			referenceHeight--
+		}
+		if referenceHeight > c.tipHeight() {
+			referenceHeight = c.tipHeight()
+		}
+
+		minTime := int64(c.medianTimePast(referenceHeight)) +
+			int64(lockTime)<<sequenceLockTimeGranularity - 1
+		if minTime >= int64(tipMedianTimePast) {
+			return fmt.Errorf("relative time lock is not satisfied yet")
+		}
+
+		return nil
+	}
+
+	minHeight := int64(previousHeight) + int64(lockTime) - 1
+	if minHeight >= int64(nextHeight) {
+		return fmt.Errorf("relative height lock is not satisfied yet")
+	}
+
+	return nil
+}
+
+// checkReplacement checks whether the given transaction can replace
+// the given conflicting mempool transactions and returns all mempool
+// transactions that must be evicted, i.e. conflicts and their descendants.
+func (c *Chain) checkReplacement(
+	transaction *bitcoin.Transaction,
+	fee int64,
+	virtualSize int64,
+	conflicts []*mempoolEntry,
+) ([]*mempoolEntry, error) {
+	if len(conflicts) == 0 {
+		return nil, nil
+	}
+
+	evicted := c.withDescendants(conflicts)
+
+	evictedFees := int64(0)
+	for _, entry := range evicted {
+		evictedFees += entry.fee
+
+		// The replacement cannot spend outputs of transactions it replaces.
+		evictedHash := entry.transaction.Hash()
+		for _, input := range transaction.Inputs {
+			if input.Outpoint.TransactionHash == evictedHash {
+				return nil, fmt.Errorf(
+					"replacement spends outputs of a replaced transaction",
+				)
+			}
+		}
+	}
+
+	minFee := evictedFees + virtualSize*minRelaySatPerVByteFee
+	if fee < minFee {
+		return nil, fmt.Errorf(
+			"replacement fee [%v] is lower than the required [%v]",
+			fee,
+			minFee,
+		)
+	}
+
+	return evicted, nil
+}
+
+// withDescendants returns the given mempool entries along with all their
+// mempool descendants.
+func (c *Chain) withDescendants(entries []*mempoolEntry) []*mempoolEntry {
+	selected := make(map[bitcoin.Hash]bool)
+	for _, entry := range entries {
+		selected[entry.transaction.Hash()] = true
+	}
+
+	// Children always follow their parents in the mempool so a single pass
+	// is enough.
+	for _, entry := range c.mempool {
+		for _, input := range entry.transaction.Inputs {
+			if selected[input.Outpoint.TransactionHash] {
+				selected[entry.transaction.Hash()] = true
+				break
+			}
+		}
+	}
+
+	result := make([]*mempoolEntry, 0, len(selected))
+	for _, entry := range c.mempool {
+		if selected[entry.transaction.Hash()] {
+			result = append(result, entry)
+		}
+	}
+
+	return result
+}
+
+// removeFromMempool removes the given entries from the mempool.
+func (c *Chain) removeFromMempool(entries []*mempoolEntry) {
+	if len(entries) == 0 {
+		return
+	}
+
+	removed := make(map[*mempoolEntry]bool)
+	for _, entry := range entries {
+		removed[entry] = true
+	}
+
+	mempool := make([]*mempoolEntry, 0, len(c.mempool))
+	for _, entry := range c.mempool {
+		if !removed[entry] {
+			mempool = append(mempool, entry)
+		}
+	}
+
+	c.mempool = mempool
+}
+
+// isFinal checks whether the given transaction can be included in the block
+// with the given height, according to its locktime.
+func isFinal(
+	transaction *bitcoin.Transaction,
+	height uint,
+	medianTimePast uint32,
+) bool {
+	if transaction.Locktime == 0 {
+		return true
+	}
+
+	threshold := int64(height)
+	if transaction.Locktime >= txscript.LockTimeThreshold {
+		threshold = int64(medianTimePast)
+	}
+
+	if int64(transaction.Locktime) < threshold {
+		return true
+	}
+
+	// The locktime is ignored if all inputs have the final sequence.
+	for _, input := range transaction.Inputs {
+		if input.Sequence != 0xffffffff {
+			return false
+		}
+	}
+
+	return true
+}
+
+// verifyScripts executes scripts of all inputs of the given transaction.
+func verifyScripts(
+	transaction *bitcoin.Transaction,
+	previousOutputs map[wire.OutPoint]*wire.TxOut,
+) error {
+	msgTx := wire.NewMsgTx(wire.TxVersion)
+	if err := msgTx.Deserialize(
+		bytes.NewReader(transaction.Serialize(bitcoin.Witness)),
+	); err != nil {
+		return fmt.Errorf("cannot deserialize transaction: [%v]", err)
+	}
+
+	sigHashes := txscript.NewTxSigHashes(msgTx)
+
+	for i, txIn := range msgTx.TxIn {
+		previousOutput := previousOutputs[txIn.PreviousOutPoint]
+
+		engine, err := txscript.NewEngine(
+			previousOutput.PkScript,
+			msgTx,
+			i,
+			txscript.StandardVerifyFlags,
+			nil,
+			sigHashes,
+			previousOutput.Value,
+		)
+		if err != nil {
+			return fmt.Errorf(
+				"cannot create script engine for input [%v]: [%v]",
+				i,
+				err,
+			)
+		}
+
+		if err := engine.Execute(); err != nil {
+			return fmt.Errorf(
+				"script verification failed for input [%v]: [%v]",
+				i,
+				err,
+			)
+		}
+	}
+
+	return nil
+}
+
+// isUnspendable checks whether the given script is provably unspendable.
+func isUnspendable(script bitcoin.Script) bool {
+	return len(script) > 0 && script[0] == txscript.OP_RETURN
+}
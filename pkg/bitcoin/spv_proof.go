@@ -5,6 +5,7 @@ import (
 	"crypto/sha256"
 	"encoding/hex"
 	"fmt"
+	"math/big"
 
 	"github.com/keep-network/keep-core/pkg/internal/byteutils"
 )
@@ -170,3 +171,220 @@ func getHeadersChain(
 
 	return headersChain.Bytes(), nil
 }
+
+// VerifySpvProof validates the given SPV proof locally, before it is submitted
+// to the host chain. This way, proofs built from invalid data returned by the
+// Bitcoin chain are rejected without spending any gas. The following checks
+// are performed:
+//   - the headers chain contains at least the required number of
+//     confirmations,
+//   - every header's hash meets the header's target,
+//   - every header points to the previous header in the chain,
+//   - the difficulty of the first header matches either the current or
+//     the previous epoch difficulty, as seen by the relay,
+//   - the difficulty accumulated by all headers is at least the matched
+//     epoch difficulty multiplied by the relay's proof difficulty factor,
+//   - the transaction's merkle branch reconstructs the merkle root of the
+//     first header,
+//   - the coinbase transaction's merkle branch reconstructs the same merkle
+//     root and is of the same length as the transaction's merkle branch.
+//
+// Similarly to the on-chain validation, only the first header's difficulty
+// is compared with the epoch difficulties. Subsequent headers are only
+// required to meet their own targets, which allows minimum difficulty blocks
+// on test networks.
+func VerifySpvProof(
+	transaction *Transaction,
+	proof *SpvProof,
+	requiredConfirmations uint,
+	txProofDifficultyFactor *big.Int,
+	currentEpochDifficulty *big.Int,
+	previousEpochDifficulty *big.Int,
+) error {
+	headers, err := parseHeadersChain(proof.BitcoinHeaders)
+	if err != nil {
+		return fmt.Errorf("cannot parse headers chain: [%w]", err)
+	}
+
+	if uint(len(headers)) < requiredConfirmations {
+		return fmt.Errorf(
+			"headers chain contains [%v] headers while [%v] "+
+				"confirmations are required",
+			len(headers),
+			requiredConfirmations,
+		)
+	}
+
+	if err := verifyHeadersChain(headers); err != nil {
+		return fmt.Errorf("invalid headers chain: [%w]", err)
+	}
+
+	firstHeaderDifficulty := headers[0].Difficulty()
+	if firstHeaderDifficulty.Cmp(currentEpochDifficulty) != 0 &&
+		firstHeaderDifficulty.Cmp(previousEpochDifficulty) != 0 {
+		return fmt.Errorf(
+			"first header difficulty [%v] matches neither current [%v] "+
+				"nor previous [%v] epoch difficulty",
+			firstHeaderDifficulty,
+			currentEpochDifficulty,
+			previousEpochDifficulty,
+		)
+	}
+
+	// The first header's difficulty equals the matched epoch difficulty
+	// so it can be used directly to compute the requested difficulty.
+	requestedDifficulty := new(big.Int).Mul(
+		firstHeaderDifficulty,
+		txProofDifficultyFactor,
+	)
+
+	observedDifficulty := big.NewInt(0)
+	for _, header := range headers {
+		observedDifficulty.Add(observedDifficulty, header.Difficulty())
+	}
+
+	if observedDifficulty.Cmp(requestedDifficulty) < 0 {
+		return fmt.Errorf(
+			"headers chain accumulated difficulty [%v] is lower than "+
+				"the requested difficulty [%v]",
+			observedDifficulty,
+			requestedDifficulty,
+		)
+	}
+
+	merkleRoot := headers[0].MerkleRootHash
+
+	if err := verifyMerkleProof(
+		transaction.Hash(),
+		proof.MerkleProof,
+		proof.TxIndexInBlock,
+		merkleRoot,
+	); err != nil {
+		return fmt.Errorf("invalid transaction merkle proof: [%w]", err)
+	}
+
+	if len(proof.CoinbaseProof) != len(proof.MerkleProof) {
+		return fmt.Errorf(
+			"coinbase merkle proof length [%v] is different than "+
+				"transaction merkle proof length [%v]",
+			len(proof.CoinbaseProof),
+			len(proof.MerkleProof),
+		)
+	}
+
+	coinbaseHash := Hash(sha256.Sum256(proof.CoinbasePreimage[:]))
+
+	if err := verifyMerkleProof(
+		coinbaseHash,
+		proof.CoinbaseProof,
+		0,
+		merkleRoot,
+	); err != nil {
+		return fmt.Errorf("invalid coinbase merkle proof: [%w]", err)
+	}
+
+	return nil
+}
+
+// parseHeadersChain parses the concatenated serialized block headers.
+func parseHeadersChain(headersChain []byte) ([]*BlockHeader, error) {
+	if len(headersChain) == 0 {
+		return nil, fmt.Errorf("headers chain is empty")
+	}
+
+	if len(headersChain)%BlockHeaderByteLength != 0 {
+		return nil, fmt.Errorf(
+			"headers chain length [%v] is not a multiple of [%v]",
+			len(headersChain),
+			BlockHeaderByteLength,
+		)
+	}
+
+	headers := make([]*BlockHeader, 0, len(headersChain)/BlockHeaderByteLength)
+	for offset := 0; offset < len(headersChain); offset += BlockHeaderByteLength {
+		var rawBlockHeader [BlockHeaderByteLength]byte
+		copy(rawBlockHeader[:], headersChain[offset:])
+
+		header := new(BlockHeader)
+		header.Deserialize(rawBlockHeader)
+
+		headers = append(headers, header)
+	}
+
+	return headers, nil
+}
+
+// verifyHeadersChain checks whether every header has a valid proof of work
+// and points to the previous header in the chain.
+func verifyHeadersChain(headers []*BlockHeader) error {
+	for i, header := range headers {
+		headerHash := header.Hash()
+
+		// The hash must be interpreted as a big-endian number so the bytes
+		// must be reversed from the internal byte order.
+		hashValue := new(big.Int).SetBytes(
+			byteutils.Reverse(headerHash[:]),
+		)
+		if hashValue.Cmp(header.Target()) > 0 {
+			return fmt.Errorf(
+				"header [%v] hash [%s] does not meet its target",
+				i,
+				headerHash.Hex(ReversedByteOrder),
+			)
+		}
+
+		if i > 0 {
+			previousHeaderHash := headers[i-1].Hash()
+			if header.PreviousBlockHeaderHash != previousHeaderHash {
+				return fmt.Errorf(
+					"header [%v] does not point to the previous header [%s]",
+					i,
+					previousHeaderHash.Hex(ReversedByteOrder),
+				)
+			}
+		}
+	}
+
+	return nil
+}
+
+// verifyMerkleProof checks whether the given merkle proof, being
+// a concatenation of 32-byte-long hashes in the internal byte order, leads
+// from the given leaf at the given index to the given merkle root.
+func verifyMerkleProof(
+	leaf Hash,
+	merkleProof []byte,
+	index uint,
+	merkleRoot Hash,
+) error {
+	if len(merkleProof)%HashByteLength != 0 {
+		return fmt.Errorf(
+			"merkle proof length [%v] is not a multiple of [%v]",
+			len(merkleProof),
+			HashByteLength,
+		)
+	}
+
+	current := leaf
+	for offset := 0; offset < len(merkleProof); offset += HashByteLength {
+		node := merkleProof[offset : offset+HashByteLength]
+
+		if index%2 == 0 {
+			current = ComputeHash(append(current[:], node...))
+		} else {
+			current = ComputeHash(append(append([]byte{}, node...), current[:]...))
+		}
+
+		index = index / 2
+	}
+
+	if current != merkleRoot {
+		return fmt.Errorf(
+			"computed merkle root [%s] does not match the header's one [%s]",
+			current.Hex(ReversedByteOrder),
+			merkleRoot.Hex(ReversedByteOrder),
+		)
+	}
+
+	return nil
+}
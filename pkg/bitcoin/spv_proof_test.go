@@ -2,6 +2,7 @@ package bitcoin
 
 import (
 	"golang.org/x/exp/slices"
+	"math/big"
 	"reflect"
 	"testing"
 
@@ -450,3 +451,189 @@ func TestAssembleTransactionProof(t *testing.T) {
 		})
 	}
 }
+
+func TestVerifySpvProof(t *testing.T) {
+	for testName, test := range SpvProofData {
+		t.Run(testName, func(t *testing.T) {
+			transaction := transactionFrom(t, test.BitcoinChainData.TransactionHex)
+
+			headers, err := parseHeadersChain(test.ExpectedProof.BitcoinHeaders)
+			if err != nil {
+				t.Fatal(err)
+			}
+			firstHeaderDifficulty := headers[0].Difficulty()
+			otherDifficulty := new(big.Int).Add(firstHeaderDifficulty, big.NewInt(1))
+
+			observedDifficulty := big.NewInt(0)
+			for _, header := range headers {
+				observedDifficulty.Add(observedDifficulty, header.Difficulty())
+			}
+			// The highest difficulty factor the headers chain can satisfy.
+			maxDifficultyFactor := new(big.Int).Div(
+				observedDifficulty,
+				firstHeaderDifficulty,
+			)
+			// Test vectors come from testnet where minimum difficulty blocks
+			// are allowed so the lowest difficulty factor is used by default.
+			defaultDifficultyFactor := big.NewInt(1)
+
+			copyProof := func() *SpvProof {
+				proof := *test.ExpectedProof
+				proof.MerkleProof = append([]byte{}, proof.MerkleProof...)
+				proof.BitcoinHeaders = append([]byte{}, proof.BitcoinHeaders...)
+				proof.CoinbaseProof = append([]byte{}, proof.CoinbaseProof...)
+				return &proof
+			}
+
+			// If not set, the required confirmations are taken from the test
+			// vector and the default difficulty factor is used.
+			var scenarios = map[string]struct {
+				modifyProof             func(proof *SpvProof)
+				requiredConfirmations   uint
+				txProofDifficultyFactor *big.Int
+				currentEpochDifficulty  *big.Int
+				previousEpochDifficulty *big.Int
+				expectedErr             bool
+			}{
+				"valid proof at current epoch difficulty": {
+					modifyProof:             func(proof *SpvProof) {},
+					currentEpochDifficulty:  firstHeaderDifficulty,
+					previousEpochDifficulty: otherDifficulty,
+				},
+				"valid proof at previous epoch difficulty": {
+					modifyProof:             func(proof *SpvProof) {},
+					currentEpochDifficulty:  otherDifficulty,
+					previousEpochDifficulty: firstHeaderDifficulty,
+				},
+				"headers count equal to required confirmations": {
+					modifyProof:             func(proof *SpvProof) {},
+					requiredConfirmations:   uint(len(headers)),
+					currentEpochDifficulty:  firstHeaderDifficulty,
+					previousEpochDifficulty: otherDifficulty,
+				},
+				"headers count below required confirmations": {
+					modifyProof:             func(proof *SpvProof) {},
+					requiredConfirmations:   uint(len(headers)) + 1,
+					currentEpochDifficulty:  firstHeaderDifficulty,
+					previousEpochDifficulty: otherDifficulty,
+					expectedErr:             true,
+				},
+				"accumulated difficulty meeting requested difficulty": {
+					modifyProof:             func(proof *SpvProof) {},
+					txProofDifficultyFactor: maxDifficultyFactor,
+					currentEpochDifficulty:  firstHeaderDifficulty,
+					previousEpochDifficulty: otherDifficulty,
+				},
+				"accumulated difficulty below requested difficulty": {
+					modifyProof: func(proof *SpvProof) {},
+					txProofDifficultyFactor: new(big.Int).Add(
+						maxDifficultyFactor,
+						big.NewInt(1),
+					),
+					currentEpochDifficulty:  firstHeaderDifficulty,
+					previousEpochDifficulty: otherDifficulty,
+					expectedErr:             true,
+				},
+				"difficulty not matching relay epochs": {
+					modifyProof:             func(proof *SpvProof) {},
+					currentEpochDifficulty:  otherDifficulty,
+					previousEpochDifficulty: otherDifficulty,
+					expectedErr:             true,
+				},
+				"header with invalid proof of work": {
+					modifyProof: func(proof *SpvProof) {
+						// Change the nonce of the last header.
+						proof.BitcoinHeaders[len(proof.BitcoinHeaders)-1]++
+					},
+					currentEpochDifficulty:  firstHeaderDifficulty,
+					previousEpochDifficulty: otherDifficulty,
+					expectedErr:             true,
+				},
+				"headers not forming a chain": {
+					modifyProof: func(proof *SpvProof) {
+						// Drop the second header.
+						proof.BitcoinHeaders = append(
+							proof.BitcoinHeaders[:BlockHeaderByteLength],
+							proof.BitcoinHeaders[2*BlockHeaderByteLength:]...,
+						)
+					},
+					currentEpochDifficulty:  firstHeaderDifficulty,
+					previousEpochDifficulty: otherDifficulty,
+					expectedErr:             true,
+				},
+				"truncated headers chain": {
+					modifyProof: func(proof *SpvProof) {
+						proof.BitcoinHeaders = proof.BitcoinHeaders[:BlockHeaderByteLength-1]
+					},
+					currentEpochDifficulty:  firstHeaderDifficulty,
+					previousEpochDifficulty: otherDifficulty,
+					expectedErr:             true,
+				},
+				"wrong transaction index": {
+					modifyProof: func(proof *SpvProof) {
+						proof.TxIndexInBlock++
+					},
+					currentEpochDifficulty:  firstHeaderDifficulty,
+					previousEpochDifficulty: otherDifficulty,
+					expectedErr:             true,
+				},
+				"tampered transaction merkle proof": {
+					modifyProof: func(proof *SpvProof) {
+						proof.MerkleProof[0]++
+					},
+					currentEpochDifficulty:  firstHeaderDifficulty,
+					previousEpochDifficulty: otherDifficulty,
+					expectedErr:             true,
+				},
+				"wrong coinbase preimage": {
+					modifyProof: func(proof *SpvProof) {
+						proof.CoinbasePreimage[0]++
+					},
+					currentEpochDifficulty:  firstHeaderDifficulty,
+					previousEpochDifficulty: otherDifficulty,
+					expectedErr:             true,
+				},
+				"coinbase proof of different length": {
+					modifyProof: func(proof *SpvProof) {
+						proof.CoinbaseProof = proof.CoinbaseProof[HashByteLength:]
+					},
+					currentEpochDifficulty:  firstHeaderDifficulty,
+					previousEpochDifficulty: otherDifficulty,
+					expectedErr:             true,
+				},
+			}
+
+			for scenarioName, scenario := range scenarios {
+				t.Run(scenarioName, func(t *testing.T) {
+					proof := copyProof()
+					scenario.modifyProof(proof)
+
+					requiredConfirmations := scenario.requiredConfirmations
+					if requiredConfirmations == 0 {
+						requiredConfirmations = test.RequiredConfirmations
+					}
+
+					txProofDifficultyFactor := scenario.txProofDifficultyFactor
+					if txProofDifficultyFactor == nil {
+						txProofDifficultyFactor = defaultDifficultyFactor
+					}
+
+					err := VerifySpvProof(
+						transaction,
+						proof,
+						requiredConfirmations,
+						txProofDifficultyFactor,
+						scenario.currentEpochDifficulty,
+						scenario.previousEpochDifficulty,
+					)
+					if scenario.expectedErr && err == nil {
+						t.Fatal("expected error")
+					}
+					if !scenario.expectedErr && err != nil {
+						t.Fatal(err)
+					}
+				})
+			}
+		})
+	}
+}
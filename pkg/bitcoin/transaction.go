@@ -4,7 +4,9 @@ import (
 	"bytes"
 	"encoding/binary"
 
+	"github.com/btcsuite/btcd/mempool"
 	"github.com/btcsuite/btcd/wire"
+	"github.com/btcsuite/btcutil"
 )
 
 // TransactionSerializationFormat represents the Bitcoin transaction
@@ -183,6 +185,16 @@ func (t *Transaction) WitnessHash() Hash {
 	return ComputeHash(t.Serialize(Witness))
 }
 
+// VirtualSize calculates the transaction's virtual size as defined by
+// BIP-0141, i.e. the transaction weight divided by 4 and rounded up.
+// The virtual size is the basis for computing the transaction fee rate.
+func (t *Transaction) VirtualSize() int64 {
+	internal := newInternalTransaction()
+	internal.fromTransaction(t)
+
+	return mempool.GetTxVirtualSize(btcutil.NewTx(internal.MsgTx))
+}
+
 // TransactionOutpoint represents a Bitcoin transaction outpoint.
 // For reference, see:
 // https://developer.bitcoin.org/reference/transactions.html#outpoint-the-specific-part-of-a-specific-output
@@ -17,18 +17,23 @@ import (
 // produce a full-fledged signed transaction that can be broadcast across
 // the Bitcoin network. The builder IS NOT SAFE for concurrent use.
 type TransactionBuilder struct {
-	chain       Chain
-	internal    *internalTransaction
-	sigHashArgs []*inputSigHashArgs
-	sigHashes   []*big.Int
+	chain           Chain
+	internal        *internalTransaction
+	sigHashArgs     []*inputSigHashArgs
+	previousOutputs []*inputPreviousOutput
+	sigHashes       []*big.Int
+	// signed denotes whether signatures were already applied to all inputs
+	// of the internal transaction.
+	signed bool
 }
 
 // NewTransactionBuilder constructs a new TransactionBuilder instance.
 func NewTransactionBuilder(chain Chain) *TransactionBuilder {
 	return &TransactionBuilder{
-		chain:       chain,
-		internal:    newInternalTransaction(),
-		sigHashArgs: make([]*inputSigHashArgs, 0),
+		chain:           chain,
+		internal:        newInternalTransaction(),
+		sigHashArgs:     make([]*inputSigHashArgs, 0),
+		previousOutputs: make([]*inputPreviousOutput, 0),
 	}
 }
 
@@ -37,7 +42,7 @@ func NewTransactionBuilder(chain Chain) *TransactionBuilder {
 func (tb *TransactionBuilder) AddPublicKeyHashInput(
 	utxo *UnspentTransactionOutput,
 ) error {
-	utxoScript, err := tb.getScript(utxo)
+	utxoTransaction, utxoScript, err := tb.getScript(utxo)
 	if err != nil {
 		return fmt.Errorf(
 			"cannot get locking script for UTXO pointed "+
@@ -75,6 +80,10 @@ func (tb *TransactionBuilder) AddPublicKeyHashInput(
 	tb.internal.AddTxIn(wire.NewTxIn(outpoint, nil, nil))
 
 	tb.sigHashArgs = append(tb.sigHashArgs, sigHashArgs)
+	tb.previousOutputs = append(tb.previousOutputs, &inputPreviousOutput{
+		transaction: utxoTransaction,
+		script:      utxoScript,
+	})
 
 	return nil
 }
@@ -86,7 +95,7 @@ func (tb *TransactionBuilder) AddScriptHashInput(
 	utxo *UnspentTransactionOutput,
 	redeemScript Script,
 ) error {
-	utxoScript, err := tb.getScript(utxo)
+	utxoTransaction, utxoScript, err := tb.getScript(utxo)
 	if err != nil {
 		return fmt.Errorf(
 			"cannot get locking script for UTXO pointed "+
@@ -129,26 +138,34 @@ func (tb *TransactionBuilder) AddScriptHashInput(
 	}
 
 	tb.sigHashArgs = append(tb.sigHashArgs, sigHashArgs)
+	tb.previousOutputs = append(tb.previousOutputs, &inputPreviousOutput{
+		transaction:  utxoTransaction,
+		script:       utxoScript,
+		redeemScript: redeemScript,
+	})
 
 	return nil
 }
 
 // getScript gets the locking script (PublicKeyScript) for the given unspent
-// transaction output.
+// transaction output. The transaction holding the given output is returned
+// as well.
 func (tb *TransactionBuilder) getScript(
 	utxo *UnspentTransactionOutput,
-) (Script, error) {
+) (*Transaction, Script, error) {
 	hash := utxo.Outpoint.TransactionHash
 	transaction, err := tb.chain.GetTransaction(hash)
 	if err != nil {
-		return nil, fmt.Errorf(
+		return nil, nil, fmt.Errorf(
 			"cannot get transaction with hash [%s]: [%v]",
 			hash.Hex(InternalByteOrder),
 			err,
 		)
 	}
 
-	return transaction.Outputs[utxo.Outpoint.OutputIndex].PublicKeyScript, nil
+	return transaction,
+		transaction.Outputs[utxo.Outpoint.OutputIndex].PublicKeyScript,
+		nil
 }
 
 // AddOutput adds a new transaction's output.
@@ -156,6 +173,30 @@ func (tb *TransactionBuilder) AddOutput(output *TransactionOutput) {
 	tb.internal.AddTxOut(wire.NewTxOut(output.Value, output.PublicKeyScript))
 }
 
+// SetLocktime sets the transaction's locktime. The locktime is committed
+// by the signature hashes so, this method must be called before
+// ComputeSignatureHashes. Worth noting that the locktime is enforced only
+// if at least one input has a non-final sequence number.
+func (tb *TransactionBuilder) SetLocktime(locktime uint32) {
+	tb.internal.LockTime = locktime
+}
+
+// SetInputSequence sets the sequence number of the input with the given
+// index. The sequence number is committed by the signature hashes so, this
+// method must be called before ComputeSignatureHashes.
+func (tb *TransactionBuilder) SetInputSequence(
+	inputIndex int,
+	sequence uint32,
+) error {
+	if inputIndex < 0 || inputIndex >= len(tb.internal.TxIn) {
+		return fmt.Errorf("input with index [%v] does not exist", inputIndex)
+	}
+
+	tb.internal.TxIn[inputIndex].Sequence = sequence
+
+	return nil
+}
+
 // ComputeSignatureHashes computes the signature hashes for all transaction
 // inputs and stores them into the builder's state. Elements of the returned
 // slice are ordered in the same way as the transaction inputs they correspond
@@ -295,6 +336,8 @@ func (tb *TransactionBuilder) AddSignatures(
 		}
 	}
 
+	tb.signed = true
+
 	return tb.internal.toTransaction(), nil
 }
 
@@ -323,6 +366,20 @@ type inputSigHashArgs struct {
 	witness bool
 }
 
+// inputPreviousOutput is a helper structure holding some data about the UTXO
+// pointed by the given input. Those data are not required to sign the input
+// but are necessary to describe it in a PSBT.
+type inputPreviousOutput struct {
+	// transaction is the transaction holding the UTXO. It may be nil if
+	// the builder was loaded from a PSBT that did not contain it.
+	transaction *Transaction
+	// script is the locking script of the UTXO.
+	script Script
+	// redeemScript is the plain-text redeem script whose hash was used to
+	// build the P2SH/P2WSH locking script. It is nil for P2PKH/P2WPKH UTXOs.
+	redeemScript Script
+}
+
 // internalTransaction is an internal utility representation of the Transaction
 // that expose a lot of tools helpful during transaction manipulation.
 type internalTransaction struct {
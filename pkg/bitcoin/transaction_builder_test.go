@@ -215,6 +215,72 @@ func TestTransactionBuilder_AddOutput(t *testing.T) {
 	assertInternalOutput(t, builder, 0, output)
 }
 
+func TestTransactionBuilder_SetLocktime(t *testing.T) {
+	builder := NewTransactionBuilder(nil) // chain is not relevant here
+
+	builder.SetLocktime(0x6528f8e0)
+
+	testutils.AssertUintsEqual(
+		t,
+		"internal locktime",
+		0x6528f8e0,
+		uint64(builder.internal.LockTime),
+	)
+}
+
+func TestTransactionBuilder_SetInputSequence(t *testing.T) {
+	localChain := newLocalChain()
+	builder := NewTransactionBuilder(localChain)
+
+	// https://live.blockcypher.com/btc-testnet/tx/5c54ecdf946382fab2236f78423ddc22a757776fb8492671c588667b737e55dc
+	inputTransaction := transactionFrom(
+		t,
+		"01000000000101a0367a0790e3dfc199df34ca9ce5c35591510b6525d2d5869166728a5ed554be0100000000ffffffff02e02e00000000000022002086a303cdd2e2eab1d1679f1a813835dc5a1b65321077cdccaf08f98cbf04ca962c2c110000000000160014e257eccafbc07c381642ce6e7e55120fb077fbed0247304402206dafd502aac9d4d542416664063533b1fed1d16877f0295740e1b09ec2abe05102200be28d9dd76863796addef4b9595aad23b2e9363ac2d64f75c21beb0e2ade5df0121039d61d62dcd048d3f8550d22eb90b4af908db60231d117aeede04e7bc11907bfa00000000",
+	)
+
+	err := localChain.addTransaction(inputTransaction)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = builder.AddScriptHashInput(
+		&UnspentTransactionOutput{
+			Outpoint: &TransactionOutpoint{
+				TransactionHash: inputTransaction.Hash(),
+				OutputIndex:     0,
+			},
+			Value: 12000,
+		},
+		hexToSlice(t, "14934b98637ca318a4d6e7ca6ffd1690b8e77df6377508f9f0c90d000395237576a9148db50eb52063ea9d98b3eac91489a90f738986f68763ac6776a914e257eccafbc07c381642ce6e7e55120fb077fbed8804e0250162b175ac68"),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = builder.SetInputSequence(0, 0xfffffffe)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testutils.AssertUintsEqual(
+		t,
+		"sequence",
+		0xfffffffe,
+		uint64(builder.internal.TxIn[0].Sequence),
+	)
+
+	err = builder.SetInputSequence(1, 0xfffffffe)
+	if err == nil {
+		t.Fatal("expected error")
+	}
+	testutils.AssertStringsEqual(
+		t,
+		"error",
+		"input with index [1] does not exist",
+		err.Error(),
+	)
+}
+
 // The goal of this test is making sure that the TransactionBuilder can
 // produce proper signature hashes and apply signatures for all input types,
 // i.e. P2PKH, P2WPKH, P2SH, and P2WSH. This test uses transactions that
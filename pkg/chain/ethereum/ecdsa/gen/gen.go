@@ -8,12 +8,22 @@ import (
 //go:generate make
 
 var (
-	// NOTE: The _address/WalletRegistry file is an empty placeholder committed to the repository
-	// to satisfy this go:embed directive during CI builds (go vet, staticcheck) that don't run
-	// go generate. The file gets populated with actual contract addresses during go generate.
+	// NOTE: The _address/* files are empty placeholders committed to the repository
+	// to satisfy go:embed directives during CI builds (go vet, staticcheck) that don't run
+	// go generate. The files get populated with actual contract addresses during go generate.
+
 	//go:embed _address/WalletRegistry
 	walletRegistryAddressFileContent string
 
 	// WalletRegistryAddress is a WalletRegistry contract's address read from the NPM package.
 	WalletRegistryAddress string = strings.TrimSpace(walletRegistryAddressFileContent)
+
+	//go:embed _address/EcdsaDkgValidator
+	ecdsaDkgValidatorAddressFileContent string
+
+	// EcdsaDkgValidatorAddress is a EcdsaDkgValidator contract's address read
+	// from the NPM package.
+	EcdsaDkgValidatorAddress string = strings.TrimSpace(
+		ecdsaDkgValidatorAddressFileContent,
+	)
 )
@@ -30,6 +30,7 @@ import (
 	"github.com/keep-network/keep-core/pkg/protocol/inactivity"
 	"github.com/keep-network/keep-core/pkg/subscription"
 	"github.com/keep-network/keep-core/pkg/tbtc"
+	"github.com/keep-network/keep-core/pkg/tecdsa"
 	"github.com/keep-network/keep-core/pkg/tecdsa/dkg"
 )
 
@@ -41,6 +42,7 @@ const (
 	BridgeContractName                  = "Bridge"
 	MaintainerProxyContractName         = "MaintainerProxy"
 	WalletProposalValidatorContractName = "WalletProposalValidator"
+	EcdsaDkgValidatorContractName       = "EcdsaDkgValidator"
 )
 
 const (
@@ -55,6 +57,7 @@ type TbtcChain struct {
 	maintainerProxy         *tbtccontract.MaintainerProxy
 	walletRegistry          *ecdsacontract.WalletRegistry
 	sortitionPool           *ecdsacontract.EcdsaSortitionPool
+	ecdsaDkgValidator       *ecdsacontract.EcdsaDkgValidator
 	walletProposalValidator *tbtccontract.WalletProposalValidator
 	redemptionWatchtower    *tbtccontract.RedemptionWatchtower
 
@@ -175,6 +178,37 @@ func newTbtcChain(
 		)
 	}
 
+	// The WalletRegistry contract does not expose the address of its DKG
+	// validator so it must be configured explicitly.
+	ecdsaDkgValidatorAddress, err := config.ContractAddress(
+		EcdsaDkgValidatorContractName,
+	)
+	if err != nil {
+		return nil, fmt.Errorf(
+			"failed to resolve %s contract address: [%v]",
+			EcdsaDkgValidatorContractName,
+			err,
+		)
+	}
+
+	ecdsaDkgValidator, err :=
+		ecdsacontract.NewEcdsaDkgValidator(
+			ecdsaDkgValidatorAddress,
+			baseChain.chainID,
+			baseChain.key,
+			baseChain.client,
+			baseChain.nonceManager,
+			baseChain.miningWaiter,
+			baseChain.blockCounter,
+			baseChain.transactionMutex,
+		)
+	if err != nil {
+		return nil, fmt.Errorf(
+			"failed to attach to EcdsaDkgValidator contract: [%v]",
+			err,
+		)
+	}
+
 	walletProposalValidatorAddress, err := config.ContractAddress(
 		WalletProposalValidatorContractName,
 	)
@@ -244,6 +278,7 @@ func newTbtcChain(
 		maintainerProxy:         maintainerProxy,
 		walletRegistry:          walletRegistry,
 		sortitionPool:           sortitionPool,
+		ecdsaDkgValidator:       ecdsaDkgValidator,
 		walletProposalValidator: walletProposalValidator,
 		redemptionWatchtower:    redemptionWatchtower,
 		sweptDepositsCache:      cache.NewGenericTimeCache[*tbtc.DepositChainRequest](sweptDepositsCachePeriod),
@@ -551,6 +586,66 @@ func (tc *TbtcChain) OnDKGResultSubmitted(
 		OnEvent(onEvent)
 }
 
+func (tc *TbtcChain) PastDKGResultSubmittedEvents(
+	filter *tbtc.DKGResultSubmittedEventFilter,
+) ([]*tbtc.DKGResultSubmittedEvent, error) {
+	var startBlock uint64
+	var endBlock *uint64
+	var resultHash [][32]byte
+	var seed []*big.Int
+
+	if filter != nil {
+		startBlock = filter.StartBlock
+		endBlock = filter.EndBlock
+		for _, hash := range filter.ResultHash {
+			resultHash = append(resultHash, hash)
+		}
+		seed = filter.Seed
+	}
+
+	events, err := tc.walletRegistry.PastDkgResultSubmittedEvents(
+		startBlock,
+		endBlock,
+		resultHash,
+		seed,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	dkgResultSubmittedEvents := make(
+		[]*tbtc.DKGResultSubmittedEvent,
+		0,
+		len(events),
+	)
+	for _, event := range events {
+		tbtcResult, err := convertDkgResultFromAbiType(event.Result)
+		if err != nil {
+			return nil, fmt.Errorf(
+				"unexpected DKG result in DKGResultSubmitted event: [%v]",
+				err,
+			)
+		}
+
+		dkgResultSubmittedEvents = append(
+			dkgResultSubmittedEvents,
+			&tbtc.DKGResultSubmittedEvent{
+				Seed:        event.Seed,
+				ResultHash:  event.ResultHash,
+				Result:      tbtcResult,
+				BlockNumber: event.Raw.BlockNumber,
+			},
+		)
+	}
+
+	sort.SliceStable(dkgResultSubmittedEvents, func(i, j int) bool {
+		return dkgResultSubmittedEvents[i].BlockNumber <
+			dkgResultSubmittedEvents[j].BlockNumber
+	})
+
+	return dkgResultSubmittedEvents, nil
+}
+
 // convertDkgResultFromAbiType converts the WalletRegistry-specific DKG
 // result to the format applicable for the TBTC application.
 func convertDkgResultFromAbiType(
@@ -1003,6 +1098,33 @@ func (tc *TbtcChain) DKGParameters() (*tbtc.DKGParameters, error) {
 	}, nil
 }
 
+// GroupParameters gets the group parameters enforced by the EcdsaDkgValidator
+// contract used by the WalletRegistry to validate DKG results. The group
+// quorum corresponds to the validator's active threshold and the honest
+// threshold to the validator's group threshold.
+func (tc *TbtcChain) GroupParameters() (*tbtc.GroupParameters, error) {
+	groupSize, err := tc.ecdsaDkgValidator.GroupSize()
+	if err != nil {
+		return nil, fmt.Errorf("cannot get group size: [%v]", err)
+	}
+
+	activeThreshold, err := tc.ecdsaDkgValidator.ActiveThreshold()
+	if err != nil {
+		return nil, fmt.Errorf("cannot get active threshold: [%v]", err)
+	}
+
+	groupThreshold, err := tc.ecdsaDkgValidator.GroupThreshold()
+	if err != nil {
+		return nil, fmt.Errorf("cannot get group threshold: [%v]", err)
+	}
+
+	return &tbtc.GroupParameters{
+		GroupSize:       int(groupSize.Int64()),
+		GroupQuorum:     int(activeThreshold.Int64()),
+		HonestThreshold: int(groupThreshold.Int64()),
+	}, nil
+}
+
 func (tc *TbtcChain) OnInactivityClaimed(
 	handler func(event *tbtc.InactivityClaimedEvent),
 ) subscription.EventSubscription {
@@ -1448,6 +1570,46 @@ func (tc *TbtcChain) IsWalletRegistered(EcdsaWalletID [32]byte) (bool, error) {
 	return isWalletRegistered, nil
 }
 
+func (tc *TbtcChain) GetWalletPublicKey(
+	EcdsaWalletID [32]byte,
+) (*ecdsa.PublicKey, error) {
+	walletPublicKeyBytes, err := tc.walletRegistry.GetWalletPublicKey(
+		EcdsaWalletID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf(
+			"cannot get public key of wallet with ECDSA ID [0x%x]: [%v]",
+			EcdsaWalletID,
+			err,
+		)
+	}
+
+	// The wallet registry holds the public key as 64-byte concatenation
+	// of the X and Y coordinates.
+	if len(walletPublicKeyBytes) != 64 {
+		return nil, fmt.Errorf(
+			"wrong public key length of wallet with ECDSA ID [0x%x]: [%v]",
+			EcdsaWalletID,
+			len(walletPublicKeyBytes),
+		)
+	}
+
+	walletPublicKey := &ecdsa.PublicKey{
+		Curve: tecdsa.Curve,
+		X:     new(big.Int).SetBytes(walletPublicKeyBytes[:32]),
+		Y:     new(big.Int).SetBytes(walletPublicKeyBytes[32:]),
+	}
+
+	if !walletPublicKey.Curve.IsOnCurve(walletPublicKey.X, walletPublicKey.Y) {
+		return nil, fmt.Errorf(
+			"public key of wallet with ECDSA ID [0x%x] is not on the curve",
+			EcdsaWalletID,
+		)
+	}
+
+	return walletPublicKey, nil
+}
+
 func (tc *TbtcChain) GetWallet(
 	walletPublicKeyHash [20]byte,
 ) (*tbtc.WalletChainData, error) {
@@ -2,9 +2,13 @@ package clientinfo
 
 import (
 	"context"
+	"net/http"
+	"net/url"
 	"time"
 
 	"github.com/ipfs/go-log"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/common/expfmt"
 
 	"github.com/keep-network/keep-common/pkg/clientinfo"
 )
@@ -19,14 +23,80 @@ type Config struct {
 	BitcoinMetricsTick  time.Duration
 }
 
+// metricsPath is the path under which metrics are exposed.
+const metricsPath = "/metrics"
+
 // Registry wraps keep-common clientinfo registry and exposes additional
 // functions for registering client-custom metrics and diagnostics
 type Registry struct {
 	*clientinfo.Registry
 
+	// labelledMetrics holds metrics consisting of multiple series
+	// distinguished by their labels. The keep-common registry identifies
+	// metrics by their names and supports gauges only so such metrics are
+	// kept in a separate Prometheus registry.
+	labelledMetrics *prometheus.Registry
+
 	ctx context.Context
 }
 
+// NewRegistry creates a new client info registry. Metrics and diagnostics
+// are not exposed until the server is enabled.
+func NewRegistry(ctx context.Context) *Registry {
+	return &Registry{
+		Registry:        clientinfo.NewRegistry(),
+		labelledMetrics: prometheus.NewRegistry(),
+		ctx:             ctx,
+	}
+}
+
+// EnableServer enables the client info server on the given port. Labelled
+// metrics are exposed on the same path as metrics of the keep-common
+// registry, right after them.
+func (r *Registry) EnableServer(port int) {
+	r.Registry.EnableServer(port)
+
+	// The keep-common registry handles all requests to the metrics path.
+	// A method-specific pattern takes precedence over it so GET requests
+	// are served by a handler wrapping the keep-common one.
+	keepCommonHandler, _ := http.DefaultServeMux.Handler(
+		&http.Request{Method: http.MethodGet, URL: &url.URL{Path: metricsPath}},
+	)
+	http.Handle(
+		http.MethodGet+" "+metricsPath,
+		newMetricsHandler(keepCommonHandler, r.labelledMetrics),
+	)
+}
+
+// newMetricsHandler returns a handler writing the response of the given
+// keep-common metrics handler followed by metrics gathered from the given
+// labelled metrics gatherer, in the Prometheus text format.
+func newMetricsHandler(
+	keepCommonHandler http.Handler,
+	labelledMetrics prometheus.Gatherer,
+) http.Handler {
+	return http.HandlerFunc(
+		func(response http.ResponseWriter, request *http.Request) {
+			keepCommonHandler.ServeHTTP(response, request)
+
+			metricFamilies, err := labelledMetrics.Gather()
+			if err != nil {
+				logger.Errorf("could not gather labelled metrics: [%v]", err)
+			}
+
+			for _, metricFamily := range metricFamilies {
+				if _, err := expfmt.MetricFamilyToText(
+					response,
+					metricFamily,
+				); err != nil {
+					logger.Errorf("could not write response: [%v]", err)
+					return
+				}
+			}
+		},
+	)
+}
+
 // Initialize set up the client info registry and enables metrics and
 // diagnostics server.
 func Initialize(
@@ -37,7 +107,7 @@ func Initialize(
 		return nil, false
 	}
 
-	registry := &Registry{clientinfo.NewRegistry(), ctx}
+	registry := NewRegistry(ctx)
 
 	registry.EnableServer(port)
 
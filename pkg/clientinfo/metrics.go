@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/prometheus/client_golang/prometheus"
+
 	"github.com/keep-network/keep-common/pkg/clientinfo"
 	"github.com/keep-network/keep-core/pkg/bitcoin"
 	"github.com/keep-network/keep-core/pkg/chain"
@@ -139,6 +141,48 @@ func (r *Registry) ObserveApplicationSource(
 	}
 }
 
+// NewLabelledMetricCounter creates and registers a counter metric with the
+// given description, whose series are distinguished by values of the given
+// labels. The metric is exposed along with all other metrics. In case
+// a metric already exists, an error will be returned.
+func (r *Registry) NewLabelledMetricCounter(
+	name string,
+	help string,
+	labelNames ...string,
+) (*prometheus.CounterVec, error) {
+	counter := prometheus.NewCounterVec(
+		prometheus.CounterOpts{Name: name, Help: help},
+		labelNames,
+	)
+
+	if err := r.labelledMetrics.Register(counter); err != nil {
+		return nil, fmt.Errorf("cannot register metric [%v]: [%w]", name, err)
+	}
+
+	return counter, nil
+}
+
+// NewLabelledMetricGauge creates and registers a gauge metric with the given
+// description, whose series are distinguished by values of the given labels.
+// The metric is exposed along with all other metrics. In case a metric already
+// exists, an error will be returned.
+func (r *Registry) NewLabelledMetricGauge(
+	name string,
+	help string,
+	labelNames ...string,
+) (*prometheus.GaugeVec, error) {
+	gauge := prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{Name: name, Help: help},
+		labelNames,
+	)
+
+	if err := r.labelledMetrics.Register(gauge); err != nil {
+		return nil, fmt.Errorf("cannot register metric [%v]: [%w]", name, err)
+	}
+
+	return gauge, nil
+}
+
 // RegisterMetricClientInfo registers static client information labels for metrics.
 func (r *Registry) RegisterMetricClientInfo(version string) {
 	_, err := r.NewMetricInfo(
@@ -0,0 +1,187 @@
+package clientinfo
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/keep-network/keep-core/internal/testutils"
+)
+
+func TestNewLabelledMetricCounter(t *testing.T) {
+	registry := NewRegistry(context.Background())
+
+	counter, err := registry.NewLabelledMetricCounter(
+		"test_counter_total",
+		"Test counter.",
+		"action",
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	counter.WithLabelValues("heartbeat").Inc()
+	counter.WithLabelValues("heartbeat").Inc()
+	counter.WithLabelValues("redemption").Inc()
+
+	_, err = registry.NewLabelledMetricCounter(
+		"test_counter_total",
+		"Test counter.",
+		"action",
+	)
+	if err == nil {
+		t.Fatal("expected duplicate registration error")
+	}
+
+	expectedMetrics := "# HELP test_counter_total Test counter.\n" +
+		"# TYPE test_counter_total counter\n" +
+		"test_counter_total{action=\"heartbeat\"} 2\n" +
+		"test_counter_total{action=\"redemption\"} 1\n"
+
+	testutils.AssertStringsEqual(
+		t,
+		"exposed metrics",
+		expectedMetrics,
+		exposeMetrics(t, registry, ""),
+	)
+}
+
+func TestNewLabelledMetricGauge(t *testing.T) {
+	registry := NewRegistry(context.Background())
+
+	gauge, err := registry.NewLabelledMetricGauge(
+		"test_gauge",
+		"Test gauge.",
+		"wallet",
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	gauge.WithLabelValues("0x01").Set(5)
+	gauge.WithLabelValues("0x01").Set(3)
+
+	_, err = registry.NewLabelledMetricGauge(
+		"test_gauge",
+		"Test gauge.",
+		"wallet",
+	)
+	if err == nil {
+		t.Fatal("expected duplicate registration error")
+	}
+
+	expectedMetrics := "# HELP test_gauge Test gauge.\n" +
+		"# TYPE test_gauge gauge\n" +
+		"test_gauge{wallet=\"0x01\"} 3\n"
+
+	testutils.AssertStringsEqual(
+		t,
+		"exposed metrics",
+		expectedMetrics,
+		exposeMetrics(t, registry, ""),
+	)
+}
+
+func TestMetricsHandler(t *testing.T) {
+	registry := NewRegistry(context.Background())
+
+	gauge, err := registry.NewLabelledMetricGauge(
+		"test_gauge",
+		"Test gauge.",
+		"wallet",
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	gauge.WithLabelValues("0x01").Set(1)
+
+	keepCommonMetrics := "# TYPE connected_peers_count gauge\n" +
+		"connected_peers_count 5 1623235129569\n"
+
+	expectedMetrics := keepCommonMetrics +
+		"# HELP test_gauge Test gauge.\n" +
+		"# TYPE test_gauge gauge\n" +
+		"test_gauge{wallet=\"0x01\"} 1\n"
+
+	testutils.AssertStringsEqual(
+		t,
+		"exposed metrics",
+		expectedMetrics,
+		exposeMetrics(t, registry, keepCommonMetrics),
+	)
+}
+
+func TestRegistry_EnableServer(t *testing.T) {
+	registry := NewRegistry(context.Background())
+
+	gauge, err := registry.NewLabelledMetricGauge(
+		"test_gauge",
+		"Test gauge.",
+		"wallet",
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	gauge.WithLabelValues("0x01").Set(1)
+
+	registry.EnableServer(0)
+
+	recorder := httptest.NewRecorder()
+	http.DefaultServeMux.ServeHTTP(
+		recorder,
+		httptest.NewRequest(http.MethodGet, metricsPath, nil),
+	)
+
+	body, err := io.ReadAll(recorder.Result().Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The keep-common registry holds no metrics so it exposes just
+	// an empty line.
+	expectedMetrics := "\n" +
+		"# HELP test_gauge Test gauge.\n" +
+		"# TYPE test_gauge gauge\n" +
+		"test_gauge{wallet=\"0x01\"} 1\n"
+
+	testutils.AssertStringsEqual(
+		t,
+		"exposed metrics",
+		expectedMetrics,
+		string(body),
+	)
+}
+
+// exposeMetrics serves a metrics request using a handler wrapping a mock
+// keep-common handler responding with the given metrics and returns the
+// response body.
+func exposeMetrics(
+	t *testing.T,
+	registry *Registry,
+	keepCommonMetrics string,
+) string {
+	keepCommonHandler := http.HandlerFunc(
+		func(response http.ResponseWriter, _ *http.Request) {
+			if _, err := io.WriteString(response, keepCommonMetrics); err != nil {
+				t.Fatal(err)
+			}
+		},
+	)
+
+	recorder := httptest.NewRecorder()
+	newMetricsHandler(keepCommonHandler, registry.labelledMetrics).ServeHTTP(
+		recorder,
+		httptest.NewRequest(http.MethodGet, metricsPath, nil),
+	)
+
+	body, err := io.ReadAll(recorder.Result().Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return string(body)
+}
@@ -10,15 +10,19 @@ import (
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/keep-network/keep-core/pkg/bitcoin"
 	"github.com/keep-network/keep-core/pkg/chain"
+	"github.com/keep-network/keep-core/pkg/maintainer/btcdiff"
 )
 
 // SubmitDepositSweepProof prepares deposit sweep proof for the given
 // transaction and submits it to the on-chain contract. If the number of required
 // confirmations is `0`, an error is returned.
+// The assembled proof is verified locally against the relay difficulty
+// before being submitted.
 func SubmitDepositSweepProof(
 	transactionHash bitcoin.Hash,
 	requiredConfirmations uint,
 	btcChain bitcoin.Chain,
+	btcDiffChain btcdiff.Chain,
 	spvChain Chain,
 ) error {
 	return submitDepositSweepProof(
@@ -26,7 +30,11 @@ func SubmitDepositSweepProof(
 		requiredConfirmations,
 		btcChain,
 		spvChain,
-		bitcoin.AssembleSpvProof,
+		newVerifyingSpvProofAssembler(
+			bitcoin.AssembleSpvProof,
+			spvChain,
+			btcDiffChain,
+		),
 	)
 }
 
@@ -0,0 +1,527 @@
+package spv
+
+import (
+	"fmt"
+
+	"github.com/btcsuite/btcd/txscript"
+
+	"github.com/keep-network/keep-core/pkg/bitcoin"
+	"github.com/keep-network/keep-core/pkg/maintainer/btcdiff"
+	"github.com/keep-network/keep-core/pkg/tbtc"
+)
+
+// TransactionKind represents the kind of a tBTC-related Bitcoin transaction.
+type TransactionKind int
+
+const (
+	UnknownTransaction TransactionKind = iota
+	DepositFundingTransaction
+	DepositSweepTransaction
+	RedemptionTransaction
+	MovingFundsTransaction
+	MovedFundsSweepTransaction
+)
+
+func (tk TransactionKind) String() string {
+	switch tk {
+	case UnknownTransaction:
+		return "Unknown"
+	case DepositFundingTransaction:
+		return "DepositFunding"
+	case DepositSweepTransaction:
+		return "DepositSweep"
+	case RedemptionTransaction:
+		return "Redemption"
+	case MovingFundsTransaction:
+		return "MovingFunds"
+	case MovedFundsSweepTransaction:
+		return "MovedFundsSweep"
+	default:
+		panic("unknown transaction kind")
+	}
+}
+
+// InspectedInput holds the information about an input of the inspected
+// transaction.
+type InspectedInput struct {
+	// Outpoint is the outpoint spent by the input.
+	Outpoint *bitcoin.TransactionOutpoint
+	// PreviousOutput is the output spent by the input.
+	PreviousOutput *bitcoin.TransactionOutput
+	// Deposit holds deposit parameters decoded from the deposit script
+	// revealed by the input. It is nil if the input does not spend a deposit.
+	Deposit *tbtc.Deposit
+	// DepositRequest is the Bridge deposit request of the spent output. It is
+	// nil if the spent output is not a revealed deposit.
+	DepositRequest *tbtc.DepositChainRequest
+	// MovedFundsSweepRequest is the Bridge moved funds sweep request of the
+	// spent output. It is nil if there is no such request.
+	MovedFundsSweepRequest *tbtc.MovedFundsSweepRequest
+}
+
+// InspectedOutput holds the information about an output of the inspected
+// transaction.
+type InspectedOutput struct {
+	// Output is the inspected output.
+	Output *bitcoin.TransactionOutput
+	// DepositRequest is the Bridge deposit request of the output. It is nil
+	// if the output is not a revealed deposit.
+	DepositRequest *tbtc.DepositChainRequest
+	// RedemptionRequest is the pending Bridge redemption request handled
+	// by the output. It is set only for redemption transactions and only
+	// until the redemption transaction is proven.
+	RedemptionRequest *tbtc.RedemptionRequest
+	// MovedFundsSweepRequest is the Bridge moved funds sweep request of the
+	// output. It is nil if there is no such request.
+	MovedFundsSweepRequest *tbtc.MovedFundsSweepRequest
+	// WalletChange determines whether the output transfers funds back to
+	// the wallet that made the transaction.
+	WalletChange bool
+}
+
+// ProofStatus holds the information about the SPV proof of a transaction
+// that was not proven yet.
+type ProofStatus struct {
+	// WithinRelayRange determines whether the proof's block headers range
+	// is within the previous and current difficulty epochs known to the relay.
+	WithinRelayRange bool
+	// AccumulatedConfirmations is the number of confirmations accumulated by
+	// the transaction.
+	AccumulatedConfirmations uint
+	// RequiredConfirmations is the number of confirmations required by
+	// the proof. It is set only if WithinRelayRange is true.
+	RequiredConfirmations uint
+}
+
+// TransactionInspection holds the result of a transaction inspection.
+type TransactionInspection struct {
+	// Transaction is the inspected transaction.
+	Transaction *bitcoin.Transaction
+	// Kind is the kind of the inspected transaction.
+	Kind TransactionKind
+	// WalletPublicKeyHash is the public key hash of the wallet that made
+	// the transaction. It is nil for deposit funding and unknown transactions.
+	WalletPublicKeyHash *[20]byte
+	// Wallet is the Bridge state of the wallet that made the transaction.
+	// It is nil if WalletPublicKeyHash is nil or the wallet is not
+	// registered in the Bridge.
+	Wallet *tbtc.WalletChainData
+	// Inputs holds the information about the transaction's inputs.
+	Inputs []*InspectedInput
+	// Outputs holds the information about the transaction's outputs.
+	Outputs []*InspectedOutput
+	// Confirmations is the number of confirmations of the transaction.
+	Confirmations uint
+	// Proven determines whether the wallet transaction was already proven
+	// in the Bridge. It is always false for deposit funding and unknown
+	// transactions.
+	Proven bool
+	// ProofStatus holds the information about the SPV proof of the wallet
+	// transaction. It is nil if the transaction is not a wallet transaction,
+	// is already proven or is not confirmed yet.
+	ProofStatus *ProofStatus
+}
+
+// InspectTransaction fetches the given Bitcoin transaction and classifies it
+// as one of the tBTC transaction kinds. The transaction's inputs and outputs
+// are matched against the Bridge state. Deposit scripts revealed by inputs
+// of deposit sweep transactions are decoded back into deposit parameters.
+// For wallet transactions, the proof status is determined as well.
+//
+// Deposit funding transactions can be recognized only once at least one
+// of their deposits is revealed to the Bridge, as the deposit script is not
+// known before.
+func InspectTransaction(
+	transactionHash bitcoin.Hash,
+	btcChain bitcoin.Chain,
+	spvChain Chain,
+	btcDiffChain btcdiff.Chain,
+) (*TransactionInspection, error) {
+	transaction, err := btcChain.GetTransaction(transactionHash)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get transaction: [%v]", err)
+	}
+
+	inputs, err := inspectTransactionInputs(transaction, btcChain, spvChain)
+	if err != nil {
+		return nil, err
+	}
+
+	outputs, err := inspectTransactionOutputs(transaction, spvChain)
+	if err != nil {
+		return nil, err
+	}
+
+	inspection := &TransactionInspection{
+		Transaction: transaction,
+		Inputs:      inputs,
+		Outputs:     outputs,
+	}
+
+	classifyTransaction(inspection, spvChain)
+
+	if inspection.WalletPublicKeyHash != nil {
+		if err := inspectWalletTransaction(
+			inspection,
+			btcChain,
+			spvChain,
+		); err != nil {
+			return nil, err
+		}
+	}
+
+	inspection.Confirmations, err = btcChain.GetTransactionConfirmations(
+		transactionHash,
+	)
+	if err != nil {
+		return nil, fmt.Errorf(
+			"failed to get transaction confirmations: [%v]",
+			err,
+		)
+	}
+
+	if inspection.WalletPublicKeyHash != nil &&
+		!inspection.Proven &&
+		inspection.Confirmations > 0 {
+		withinRelayRange, _, requiredConfirmations, err := getProofInfo(
+			transactionHash,
+			btcChain,
+			spvChain,
+			btcDiffChain,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get proof info: [%v]", err)
+		}
+
+		inspection.ProofStatus = &ProofStatus{
+			WithinRelayRange:         withinRelayRange,
+			AccumulatedConfirmations: inspection.Confirmations,
+			RequiredConfirmations:    requiredConfirmations,
+		}
+	}
+
+	return inspection, nil
+}
+
+// inspectTransactionInputs gathers the information about the transaction's
+// inputs.
+func inspectTransactionInputs(
+	transaction *bitcoin.Transaction,
+	btcChain bitcoin.Chain,
+	spvChain Chain,
+) ([]*InspectedInput, error) {
+	inputs := make([]*InspectedInput, len(transaction.Inputs))
+
+	for i, input := range transaction.Inputs {
+		outpoint := input.Outpoint
+
+		previousTransaction, err := btcChain.GetTransaction(
+			outpoint.TransactionHash,
+		)
+		if err != nil {
+			return nil, fmt.Errorf(
+				"failed to get previous transaction of input [%v]: [%v]",
+				i,
+				err,
+			)
+		}
+
+		if int(outpoint.OutputIndex) >= len(previousTransaction.Outputs) {
+			return nil, fmt.Errorf(
+				"output spent by input [%v] does not exist",
+				i,
+			)
+		}
+
+		inspectedInput := &InspectedInput{
+			Outpoint:       outpoint,
+			PreviousOutput: previousTransaction.Outputs[outpoint.OutputIndex],
+		}
+
+		switch bitcoin.GetScriptType(inspectedInput.PreviousOutput.PublicKeyScript) {
+		case bitcoin.P2SHScript, bitcoin.P2WSHScript:
+			// Inputs that are not deposits are left undecoded.
+			if redeemScript := extractRedeemScript(input); redeemScript != nil {
+				inspectedInput.Deposit, _ = tbtc.ParseDepositScript(redeemScript)
+			}
+
+			depositRequest, found, err := spvChain.GetDepositRequest(
+				outpoint.TransactionHash,
+				outpoint.OutputIndex,
+			)
+			if err != nil {
+				return nil, fmt.Errorf(
+					"failed to get deposit request of input [%v]: [%v]",
+					i,
+					err,
+				)
+			}
+			if found {
+				inspectedInput.DepositRequest = depositRequest
+			}
+		case bitcoin.P2PKHScript, bitcoin.P2WPKHScript:
+			movedFundsSweepRequest, found, err :=
+				spvChain.GetMovedFundsSweepRequest(
+					outpoint.TransactionHash,
+					outpoint.OutputIndex,
+				)
+			if err != nil {
+				return nil, fmt.Errorf(
+					"failed to get moved funds sweep request of "+
+						"input [%v]: [%v]",
+					i,
+					err,
+				)
+			}
+			if found {
+				inspectedInput.MovedFundsSweepRequest = movedFundsSweepRequest
+			}
+		}
+
+		inputs[i] = inspectedInput
+	}
+
+	return inputs, nil
+}
+
+// extractRedeemScript extracts the redeem script revealed by the given input
+// spending a P2SH or P2WSH output. The redeem script is always the last item
+// of the witness or the signature script. Returns nil if the input holds no
+// data.
+func extractRedeemScript(input *bitcoin.TransactionInput) []byte {
+	if len(input.Witness) > 0 {
+		return input.Witness[len(input.Witness)-1]
+	}
+
+	pushes, err := txscript.PushedData(input.SignatureScript)
+	if err != nil || len(pushes) == 0 {
+		return nil
+	}
+
+	return pushes[len(pushes)-1]
+}
+
+// inspectTransactionOutputs gathers the information about the transaction's
+// outputs that do not depend on the transaction kind.
+func inspectTransactionOutputs(
+	transaction *bitcoin.Transaction,
+	spvChain Chain,
+) ([]*InspectedOutput, error) {
+	transactionHash := transaction.Hash()
+	outputs := make([]*InspectedOutput, len(transaction.Outputs))
+
+	for i, output := range transaction.Outputs {
+		inspectedOutput := &InspectedOutput{Output: output}
+
+		switch bitcoin.GetScriptType(output.PublicKeyScript) {
+		case bitcoin.P2SHScript, bitcoin.P2WSHScript:
+			depositRequest, found, err := spvChain.GetDepositRequest(
+				transactionHash,
+				uint32(i),
+			)
+			if err != nil {
+				return nil, fmt.Errorf(
+					"failed to get deposit request of output [%v]: [%v]",
+					i,
+					err,
+				)
+			}
+			if found {
+				inspectedOutput.DepositRequest = depositRequest
+			}
+		case bitcoin.P2PKHScript, bitcoin.P2WPKHScript:
+			movedFundsSweepRequest, found, err :=
+				spvChain.GetMovedFundsSweepRequest(
+					transactionHash,
+					uint32(i),
+				)
+			if err != nil {
+				return nil, fmt.Errorf(
+					"failed to get moved funds sweep request of "+
+						"output [%v]: [%v]",
+					i,
+					err,
+				)
+			}
+			if found {
+				inspectedOutput.MovedFundsSweepRequest = movedFundsSweepRequest
+			}
+		}
+
+		outputs[i] = inspectedOutput
+	}
+
+	return outputs, nil
+}
+
+// classifyTransaction determines the kind of the inspected transaction and
+// the wallet that made it, based on the inspected inputs and outputs.
+func classifyTransaction(
+	inspection *TransactionInspection,
+	spvChain Chain,
+) {
+	// Deposits can be spent only by deposit sweep transactions or refunds.
+	// Refunds are not wallet transactions so, only revealed deposits count.
+	for _, input := range inspection.Inputs {
+		if input.Deposit != nil && input.DepositRequest != nil {
+			walletPublicKeyHash := input.Deposit.WalletPublicKeyHash
+			inspection.Kind = DepositSweepTransaction
+			inspection.WalletPublicKeyHash = &walletPublicKeyHash
+			return
+		}
+	}
+
+	// The first input of a moved funds sweep transaction always points to
+	// the moved funds sweep request.
+	if len(inspection.Inputs) > 0 {
+		if request := inspection.Inputs[0].MovedFundsSweepRequest; request != nil {
+			walletPublicKeyHash := request.WalletPublicKeyHash
+			inspection.Kind = MovedFundsSweepTransaction
+			inspection.WalletPublicKeyHash = &walletPublicKeyHash
+			return
+		}
+	}
+
+	for _, output := range inspection.Outputs {
+		if output.DepositRequest != nil {
+			inspection.Kind = DepositFundingTransaction
+			return
+		}
+	}
+
+	// Both redemption and moving funds transactions spend the single main
+	// UTXO of a registered wallet.
+	if len(inspection.Inputs) != 1 {
+		return
+	}
+
+	walletPublicKeyHash, err := bitcoin.ExtractPublicKeyHash(
+		inspection.Inputs[0].PreviousOutput.PublicKeyScript,
+	)
+	if err != nil || getRegisteredWallet(walletPublicKeyHash, spvChain) == nil {
+		return
+	}
+
+	inspection.WalletPublicKeyHash = &walletPublicKeyHash
+
+	// Moving funds transactions transfer all funds to other registered
+	// wallets. Redemption transactions pay to redeemers.
+	for _, output := range inspection.Outputs {
+		targetWalletPublicKeyHash, err := bitcoin.ExtractPublicKeyHash(
+			output.Output.PublicKeyScript,
+		)
+		if err != nil ||
+			targetWalletPublicKeyHash == walletPublicKeyHash ||
+			getRegisteredWallet(targetWalletPublicKeyHash, spvChain) == nil {
+			inspection.Kind = RedemptionTransaction
+			return
+		}
+	}
+
+	inspection.Kind = MovingFundsTransaction
+}
+
+// getRegisteredWallet gets the Bridge state of the wallet with the given
+// public key hash. Returns nil if the wallet is not registered in the Bridge.
+// The chain reports unknown wallets as errors so, any error is considered
+// as a sign the wallet is unknown.
+func getRegisteredWallet(
+	walletPublicKeyHash [20]byte,
+	spvChain Chain,
+) *tbtc.WalletChainData {
+	wallet, err := spvChain.GetWallet(walletPublicKeyHash)
+	if err != nil || wallet.State == tbtc.StateUnknown {
+		return nil
+	}
+
+	return wallet
+}
+
+// inspectWalletTransaction gathers the information specific to transactions
+// made by wallets and determines whether the transaction was already proven.
+func inspectWalletTransaction(
+	inspection *TransactionInspection,
+	btcChain bitcoin.Chain,
+	spvChain Chain,
+) error {
+	walletPublicKeyHash := *inspection.WalletPublicKeyHash
+
+	inspection.Wallet = getRegisteredWallet(walletPublicKeyHash, spvChain)
+
+	for i, output := range inspection.Outputs {
+		isChange, err := isWalletChangeOutput(walletPublicKeyHash, output.Output)
+		if err != nil {
+			return fmt.Errorf(
+				"failed to check if output [%v] is wallet change: [%v]",
+				i,
+				err,
+			)
+		}
+		output.WalletChange = isChange
+
+		if inspection.Kind != RedemptionTransaction || isChange {
+			continue
+		}
+
+		request, found, err := spvChain.GetPendingRedemptionRequest(
+			walletPublicKeyHash,
+			output.Output.PublicKeyScript,
+		)
+		if err != nil {
+			return fmt.Errorf(
+				"failed to get pending redemption request of "+
+					"output [%v]: [%v]",
+				i,
+				err,
+			)
+		}
+		if found {
+			output.RedemptionRequest = request
+		}
+	}
+
+	switch inspection.Kind {
+	case DepositSweepTransaction:
+		// The sweep is proven once all swept deposits are marked as swept.
+		proven := true
+		for _, input := range inspection.Inputs {
+			if input.Deposit == nil {
+				continue
+			}
+
+			if input.DepositRequest == nil ||
+				input.DepositRequest.SweptAt.Unix() == 0 {
+				proven = false
+			}
+		}
+		inspection.Proven = proven
+
+	case MovedFundsSweepTransaction:
+		inspection.Proven = inspection.Inputs[0].MovedFundsSweepRequest.State ==
+			tbtc.MovedFundsStateProcessed
+
+	case RedemptionTransaction, MovingFundsTransaction:
+		// The transaction spends the wallet's main UTXO. Once the
+		// transaction is proven, the Bridge updates the main UTXO.
+		if inspection.Wallet == nil {
+			return nil
+		}
+
+		isMainUtxo, err := isInputCurrentWalletsMainUTXO(
+			inspection.Inputs[0].Outpoint.TransactionHash,
+			inspection.Inputs[0].Outpoint.OutputIndex,
+			walletPublicKeyHash,
+			btcChain,
+			spvChain,
+		)
+		if err != nil {
+			return fmt.Errorf(
+				"failed to check if input is the main UTXO: [%v]",
+				err,
+			)
+		}
+		inspection.Proven = !isMainUtxo
+	}
+
+	return nil
+}
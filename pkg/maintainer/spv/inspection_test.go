@@ -0,0 +1,525 @@
+package spv
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/keep-network/keep-core/internal/testutils"
+	"github.com/keep-network/keep-core/pkg/bitcoin"
+	"github.com/keep-network/keep-core/pkg/tbtc"
+)
+
+var (
+	inspectionWalletPublicKeyHash = [20]byte{
+		0x8d, 0xb5, 0x0e, 0xb5, 0x20, 0x63, 0xea, 0x9d, 0x98, 0xb3,
+		0xea, 0xc9, 0x14, 0x89, 0xa9, 0x0f, 0x73, 0x89, 0x86, 0xf6,
+	}
+	inspectionTargetWalletPublicKeyHash = [20]byte{
+		0xe2, 0x57, 0xec, 0xca, 0xfb, 0xc0, 0x7c, 0x38, 0x16, 0x42,
+		0xce, 0x6e, 0x7e, 0x55, 0x12, 0x0f, 0xb0, 0x77, 0xfb, 0xed,
+	}
+	inspectionRefundPublicKeyHash = [20]byte{
+		0x28, 0xe0, 0x81, 0xf2, 0x85, 0x13, 0x8c, 0xcb, 0xe3, 0x89,
+		0xc1, 0xeb, 0x89, 0x85, 0x71, 0x62, 0x30, 0x12, 0x9f, 0x89,
+	}
+)
+
+func TestInspectTransaction_DepositFundingAndSweep(t *testing.T) {
+	btcChain := newLocalBitcoinChain()
+	spvChain := newLocalChain()
+
+	deposit := &tbtc.Deposit{
+		Depositor:           "0x934b98637ca318a4d6e7ca6ffd1690b8e77df637",
+		BlindingFactor:      [8]byte{0xf9, 0xf0, 0xc9, 0x0d, 0x00, 0x03, 0x95, 0x23},
+		WalletPublicKeyHash: inspectionWalletPublicKeyHash,
+		RefundPublicKeyHash: inspectionRefundPublicKeyHash,
+		RefundLocktime:      [4]byte{0x60, 0xbc, 0xea, 0x61},
+	}
+
+	depositScript, err := deposit.Script()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	depositLockingScript, err := bitcoin.PayToWitnessScriptHash(
+		bitcoin.WitnessScriptHash(depositScript),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fundingTransaction := addInspectionTransaction(
+		t,
+		btcChain,
+		[]*bitcoin.TransactionInput{
+			inspectionInput(
+				addInspectionTransaction(
+					t,
+					btcChain,
+					nil,
+					1,
+					inspectionP2WPKHOutput(t, inspectionRefundPublicKeyHash, 50000),
+				),
+				0,
+			),
+		},
+		0,
+		&bitcoin.TransactionOutput{
+			Value:           40000,
+			PublicKeyScript: depositLockingScript,
+		},
+	)
+
+	spvChain.setDepositRequest(
+		fundingTransaction.Hash(),
+		0,
+		&tbtc.DepositChainRequest{
+			Depositor: deposit.Depositor,
+			Amount:    40000,
+			SweptAt:   time.Unix(0, 0),
+		},
+	)
+
+	sweepInput := inspectionInput(fundingTransaction, 0)
+	sweepInput.Witness = [][]byte{{0x30}, {0x02}, depositScript}
+
+	sweepTransaction := addInspectionTransaction(
+		t,
+		btcChain,
+		[]*bitcoin.TransactionInput{sweepInput},
+		0,
+		inspectionP2WPKHOutput(t, inspectionWalletPublicKeyHash, 39000),
+	)
+
+	spvChain.setWallet(inspectionWalletPublicKeyHash, &tbtc.WalletChainData{
+		State: tbtc.StateLive,
+	})
+
+	fundingInspection, err := InspectTransaction(
+		fundingTransaction.Hash(),
+		btcChain,
+		spvChain,
+		spvChain,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testutils.AssertStringsEqual(
+		t,
+		"funding transaction kind",
+		DepositFundingTransaction.String(),
+		fundingInspection.Kind.String(),
+	)
+	if fundingInspection.WalletPublicKeyHash != nil {
+		t.Errorf("unexpected wallet of funding transaction")
+	}
+	if fundingInspection.Outputs[0].DepositRequest == nil {
+		t.Errorf("expected deposit request of funding output")
+	}
+
+	sweepInspection, err := InspectTransaction(
+		sweepTransaction.Hash(),
+		btcChain,
+		spvChain,
+		spvChain,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testutils.AssertStringsEqual(
+		t,
+		"sweep transaction kind",
+		DepositSweepTransaction.String(),
+		sweepInspection.Kind.String(),
+	)
+	assertInspectionWallet(t, inspectionWalletPublicKeyHash, sweepInspection)
+	testutils.AssertBoolsEqual(t, "proven", false, sweepInspection.Proven)
+	testutils.AssertBoolsEqual(
+		t,
+		"wallet change",
+		true,
+		sweepInspection.Outputs[0].WalletChange,
+	)
+
+	decodedDeposit := sweepInspection.Inputs[0].Deposit
+	if decodedDeposit == nil {
+		t.Fatal("expected decoded deposit")
+	}
+	testutils.AssertStringsEqual(
+		t,
+		"depositor",
+		deposit.Depositor.String(),
+		decodedDeposit.Depositor.String(),
+	)
+	testutils.AssertBytesEqual(
+		t,
+		deposit.RefundPublicKeyHash[:],
+		decodedDeposit.RefundPublicKeyHash[:],
+	)
+	testutils.AssertBytesEqual(
+		t,
+		deposit.RefundLocktime[:],
+		decodedDeposit.RefundLocktime[:],
+	)
+
+	// Mark the deposit as swept to simulate a submitted sweep proof.
+	spvChain.setDepositRequest(
+		fundingTransaction.Hash(),
+		0,
+		&tbtc.DepositChainRequest{
+			Depositor: deposit.Depositor,
+			Amount:    40000,
+			SweptAt:   time.Unix(1700000000, 0),
+		},
+	)
+
+	sweepInspection, err = InspectTransaction(
+		sweepTransaction.Hash(),
+		btcChain,
+		spvChain,
+		spvChain,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testutils.AssertBoolsEqual(t, "proven", true, sweepInspection.Proven)
+}
+
+func TestInspectTransaction_Redemption(t *testing.T) {
+	btcChain := newLocalBitcoinChain()
+	spvChain := newLocalChain()
+
+	mainUtxoTransaction := addInspectionTransaction(
+		t,
+		btcChain,
+		nil,
+		1,
+		inspectionP2WPKHOutput(t, inspectionWalletPublicKeyHash, 100000),
+	)
+
+	redeemerOutput := &bitcoin.TransactionOutput{
+		Value: 30000,
+		PublicKeyScript: []byte{
+			0x00, 0x14, 0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0x07, 0x08, 0x09,
+			0x0a, 0x0b, 0x0c, 0x0d, 0x0e, 0x0f, 0x10, 0x11, 0x12, 0x13, 0x14,
+		},
+	}
+
+	redemptionTransaction := addInspectionTransaction(
+		t,
+		btcChain,
+		[]*bitcoin.TransactionInput{inspectionInput(mainUtxoTransaction, 0)},
+		3,
+		redeemerOutput,
+		inspectionP2WPKHOutput(t, inspectionWalletPublicKeyHash, 69000),
+	)
+
+	spvChain.setWallet(inspectionWalletPublicKeyHash, &tbtc.WalletChainData{
+		State: tbtc.StateLive,
+		MainUtxoHash: spvChain.ComputeMainUtxoHash(
+			&bitcoin.UnspentTransactionOutput{
+				Outpoint: &bitcoin.TransactionOutpoint{
+					TransactionHash: mainUtxoTransaction.Hash(),
+					OutputIndex:     0,
+				},
+				Value: 100000,
+			},
+		),
+	})
+	spvChain.setPendingRedemptionRequest(
+		inspectionWalletPublicKeyHash,
+		&tbtc.RedemptionRequest{
+			RedeemerOutputScript: redeemerOutput.PublicKeyScript,
+			RequestedAmount:      31000,
+		},
+	)
+
+	err := btcChain.addBlockHeader(100, &bitcoin.BlockHeader{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	spvChain.setTxProofDifficultyFactor(big.NewInt(6))
+	spvChain.setCurrentEpoch(0)
+
+	inspection, err := InspectTransaction(
+		redemptionTransaction.Hash(),
+		btcChain,
+		spvChain,
+		spvChain,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testutils.AssertStringsEqual(
+		t,
+		"kind",
+		RedemptionTransaction.String(),
+		inspection.Kind.String(),
+	)
+	assertInspectionWallet(t, inspectionWalletPublicKeyHash, inspection)
+	testutils.AssertBoolsEqual(t, "proven", false, inspection.Proven)
+
+	if inspection.Outputs[0].RedemptionRequest == nil {
+		t.Errorf("expected redemption request of output [0]")
+	}
+	testutils.AssertBoolsEqual(
+		t,
+		"output [0] wallet change",
+		false,
+		inspection.Outputs[0].WalletChange,
+	)
+	testutils.AssertBoolsEqual(
+		t,
+		"output [1] wallet change",
+		true,
+		inspection.Outputs[1].WalletChange,
+	)
+
+	if inspection.ProofStatus == nil {
+		t.Fatal("expected proof status")
+	}
+	testutils.AssertBoolsEqual(
+		t,
+		"within relay range",
+		true,
+		inspection.ProofStatus.WithinRelayRange,
+	)
+	testutils.AssertUintsEqual(
+		t,
+		"accumulated confirmations",
+		3,
+		uint64(inspection.ProofStatus.AccumulatedConfirmations),
+	)
+	testutils.AssertUintsEqual(
+		t,
+		"required confirmations",
+		6,
+		uint64(inspection.ProofStatus.RequiredConfirmations),
+	)
+}
+
+func TestInspectTransaction_MovingFundsAndMovedFundsSweep(t *testing.T) {
+	btcChain := newLocalBitcoinChain()
+	spvChain := newLocalChain()
+
+	mainUtxoTransaction := addInspectionTransaction(
+		t,
+		btcChain,
+		nil,
+		1,
+		inspectionP2WPKHOutput(t, inspectionWalletPublicKeyHash, 100000),
+	)
+
+	movingFundsTransaction := addInspectionTransaction(
+		t,
+		btcChain,
+		[]*bitcoin.TransactionInput{inspectionInput(mainUtxoTransaction, 0)},
+		0,
+		inspectionP2WPKHOutput(t, inspectionTargetWalletPublicKeyHash, 99000),
+	)
+
+	movedFundsSweepTransaction := addInspectionTransaction(
+		t,
+		btcChain,
+		[]*bitcoin.TransactionInput{inspectionInput(movingFundsTransaction, 0)},
+		0,
+		inspectionP2WPKHOutput(t, inspectionTargetWalletPublicKeyHash, 98000),
+	)
+
+	// The moving funds transaction was already proven so, the wallet's main
+	// UTXO is no longer set.
+	spvChain.setWallet(inspectionWalletPublicKeyHash, &tbtc.WalletChainData{
+		State: tbtc.StateClosing,
+	})
+	spvChain.setWallet(inspectionTargetWalletPublicKeyHash, &tbtc.WalletChainData{
+		State: tbtc.StateLive,
+	})
+	spvChain.setMovedFundsSweepRequest(
+		movingFundsTransaction.Hash(),
+		0,
+		&tbtc.MovedFundsSweepRequest{
+			WalletPublicKeyHash: inspectionTargetWalletPublicKeyHash,
+			Value:               99000,
+			State:               tbtc.MovedFundsStatePending,
+		},
+	)
+
+	movingFundsInspection, err := InspectTransaction(
+		movingFundsTransaction.Hash(),
+		btcChain,
+		spvChain,
+		spvChain,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testutils.AssertStringsEqual(
+		t,
+		"moving funds transaction kind",
+		MovingFundsTransaction.String(),
+		movingFundsInspection.Kind.String(),
+	)
+	assertInspectionWallet(t, inspectionWalletPublicKeyHash, movingFundsInspection)
+	testutils.AssertBoolsEqual(t, "proven", true, movingFundsInspection.Proven)
+	if movingFundsInspection.Outputs[0].MovedFundsSweepRequest == nil {
+		t.Errorf("expected moved funds sweep request of output [0]")
+	}
+
+	movedFundsSweepInspection, err := InspectTransaction(
+		movedFundsSweepTransaction.Hash(),
+		btcChain,
+		spvChain,
+		spvChain,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testutils.AssertStringsEqual(
+		t,
+		"moved funds sweep transaction kind",
+		MovedFundsSweepTransaction.String(),
+		movedFundsSweepInspection.Kind.String(),
+	)
+	assertInspectionWallet(
+		t,
+		inspectionTargetWalletPublicKeyHash,
+		movedFundsSweepInspection,
+	)
+	testutils.AssertBoolsEqual(t, "proven", false, movedFundsSweepInspection.Proven)
+	if movedFundsSweepInspection.ProofStatus != nil {
+		t.Errorf("unexpected proof status of unconfirmed transaction")
+	}
+}
+
+func TestInspectTransaction_Unknown(t *testing.T) {
+	btcChain := newLocalBitcoinChain()
+	spvChain := newLocalChain()
+
+	previousTransaction := addInspectionTransaction(
+		t,
+		btcChain,
+		nil,
+		1,
+		inspectionP2WPKHOutput(t, inspectionRefundPublicKeyHash, 100000),
+	)
+
+	transaction := addInspectionTransaction(
+		t,
+		btcChain,
+		[]*bitcoin.TransactionInput{inspectionInput(previousTransaction, 0)},
+		0,
+		inspectionP2WPKHOutput(t, inspectionWalletPublicKeyHash, 99000),
+	)
+
+	inspection, err := InspectTransaction(
+		transaction.Hash(),
+		btcChain,
+		spvChain,
+		spvChain,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testutils.AssertStringsEqual(
+		t,
+		"kind",
+		UnknownTransaction.String(),
+		inspection.Kind.String(),
+	)
+	if inspection.WalletPublicKeyHash != nil {
+		t.Errorf("unexpected wallet")
+	}
+}
+
+// addInspectionTransaction records a transaction with the given inputs and
+// outputs in the given local Bitcoin chain. If no inputs are given, the
+// transaction gets a single input pointing to an arbitrary outpoint.
+func addInspectionTransaction(
+	t *testing.T,
+	btcChain *localBitcoinChain,
+	inputs []*bitcoin.TransactionInput,
+	confirmations uint,
+	outputs ...*bitcoin.TransactionOutput,
+) *bitcoin.Transaction {
+	if len(inputs) == 0 {
+		inputs = []*bitcoin.TransactionInput{
+			{
+				Outpoint: &bitcoin.TransactionOutpoint{
+					TransactionHash: bitcoin.Hash{0x01},
+					OutputIndex:     uint32(len(btcChain.transactions)),
+				},
+				Sequence: 0xffffffff,
+			},
+		}
+	}
+
+	transaction := &bitcoin.Transaction{
+		Version: 1,
+		Inputs:  inputs,
+		Outputs: outputs,
+	}
+
+	if err := btcChain.BroadcastTransaction(transaction); err != nil {
+		t.Fatal(err)
+	}
+
+	err := btcChain.addTransactionConfirmations(
+		transaction.Hash(),
+		confirmations,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return transaction
+}
+
+func inspectionInput(
+	transaction *bitcoin.Transaction,
+	outputIndex uint32,
+) *bitcoin.TransactionInput {
+	return &bitcoin.TransactionInput{
+		Outpoint: &bitcoin.TransactionOutpoint{
+			TransactionHash: transaction.Hash(),
+			OutputIndex:     outputIndex,
+		},
+		Sequence: 0xffffffff,
+	}
+}
+
+func inspectionP2WPKHOutput(
+	t *testing.T,
+	publicKeyHash [20]byte,
+	value int64,
+) *bitcoin.TransactionOutput {
+	script, err := bitcoin.PayToWitnessPublicKeyHash(publicKeyHash)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return &bitcoin.TransactionOutput{Value: value, PublicKeyScript: script}
+}
+
+func assertInspectionWallet(
+	t *testing.T,
+	expectedWalletPublicKeyHash [20]byte,
+	inspection *TransactionInspection,
+) {
+	if inspection.WalletPublicKeyHash == nil {
+		t.Fatal("expected wallet")
+	}
+
+	testutils.AssertBytesEqual(
+		t,
+		expectedWalletPublicKeyHash[:],
+		inspection.WalletPublicKeyHash[:],
+	)
+}
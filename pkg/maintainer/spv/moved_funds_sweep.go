@@ -5,16 +5,20 @@ import (
 	"fmt"
 
 	"github.com/keep-network/keep-core/pkg/bitcoin"
+	"github.com/keep-network/keep-core/pkg/maintainer/btcdiff"
 	"github.com/keep-network/keep-core/pkg/tbtc"
 )
 
 // SubmitMovedFundsSweepProof prepares moved funds sweep proof for the given
 // transaction and submits it to the on-chain contract. If the number of
 // required confirmations is `0`, an error is returned.
+// The assembled proof is verified locally against the relay difficulty
+// before being submitted.
 func SubmitMovedFundsSweepProof(
 	transactionHash bitcoin.Hash,
 	requiredConfirmations uint,
 	btcChain bitcoin.Chain,
+	btcDiffChain btcdiff.Chain,
 	spvChain Chain,
 ) error {
 	return submitMovedFundsSweepProof(
@@ -22,7 +26,11 @@ func SubmitMovedFundsSweepProof(
 		requiredConfirmations,
 		btcChain,
 		spvChain,
-		bitcoin.AssembleSpvProof,
+		newVerifyingSpvProofAssembler(
+			bitcoin.AssembleSpvProof,
+			spvChain,
+			btcDiffChain,
+		),
 	)
 }
 
@@ -4,16 +4,20 @@ import (
 	"bytes"
 	"fmt"
 	"github.com/keep-network/keep-core/pkg/bitcoin"
+	"github.com/keep-network/keep-core/pkg/maintainer/btcdiff"
 	"github.com/keep-network/keep-core/pkg/tbtc"
 )
 
 // SubmitRedemptionProof prepares redemption proof for the given transaction
 // and submits it to the on-chain contract. If the number of required
 // confirmations is `0`, an error is returned.
+// The assembled proof is verified locally against the relay difficulty
+// before being submitted.
 func SubmitRedemptionProof(
 	transactionHash bitcoin.Hash,
 	requiredConfirmations uint,
 	btcChain bitcoin.Chain,
+	btcDiffChain btcdiff.Chain,
 	spvChain Chain,
 ) error {
 	return submitRedemptionProof(
@@ -21,7 +25,11 @@ func SubmitRedemptionProof(
 		requiredConfirmations,
 		btcChain,
 		spvChain,
-		bitcoin.AssembleSpvProof,
+		newVerifyingSpvProofAssembler(
+			bitcoin.AssembleSpvProof,
+			spvChain,
+			btcDiffChain,
+		),
 	)
 }
 
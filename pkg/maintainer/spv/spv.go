@@ -143,6 +143,7 @@ type transactionProofSubmitter func(
 	transactionHash bitcoin.Hash,
 	requiredConfirmations uint,
 	btcChain bitcoin.Chain,
+	btcDiffChain btcdiff.Chain,
 	spvChain Chain,
 ) error
 
@@ -214,6 +215,7 @@ func (sm *spvMaintainer) proveTransactions(
 			transaction.Hash(),
 			requiredConfirmations,
 			sm.btcChain,
+			sm.btcDiffChain,
 			sm.spvChain,
 		)
 		if err != nil {
@@ -465,3 +467,62 @@ type spvProofAssembler func(
 	requiredConfirmations uint,
 	btcChain bitcoin.Chain,
 ) (*bitcoin.Transaction, *bitcoin.SpvProof, error)
+
+// newVerifyingSpvProofAssembler returns an spvProofAssembler that assembles
+// the SPV proof using the given assembler and verifies it locally against
+// the proof difficulty factor and the current and previous epoch
+// difficulties known to the relay. This way, invalid proofs are detected
+// before the host chain transaction is submitted and reverted.
+func newVerifyingSpvProofAssembler(
+	assembleSpvProof spvProofAssembler,
+	spvChain Chain,
+	btcDiffChain btcdiff.Chain,
+) spvProofAssembler {
+	return func(
+		transactionHash bitcoin.Hash,
+		requiredConfirmations uint,
+		btcChain bitcoin.Chain,
+	) (*bitcoin.Transaction, *bitcoin.SpvProof, error) {
+		transaction, proof, err := assembleSpvProof(
+			transactionHash,
+			requiredConfirmations,
+			btcChain,
+		)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		txProofDifficultyFactor, err := spvChain.TxProofDifficultyFactor()
+		if err != nil {
+			return nil, nil, fmt.Errorf(
+				"failed to get transaction proof difficulty factor: [%v]",
+				err,
+			)
+		}
+
+		currentEpochDifficulty, previousEpochDifficulty, err :=
+			btcDiffChain.GetCurrentAndPrevEpochDifficulty()
+		if err != nil {
+			return nil, nil, fmt.Errorf(
+				"failed to get Bitcoin epoch difficulties: [%v]",
+				err,
+			)
+		}
+
+		if err := bitcoin.VerifySpvProof(
+			transaction,
+			proof,
+			requiredConfirmations,
+			txProofDifficultyFactor,
+			currentEpochDifficulty,
+			previousEpochDifficulty,
+		); err != nil {
+			return nil, nil, fmt.Errorf(
+				"assembled SPV proof is invalid: [%v]",
+				err,
+			)
+		}
+
+		return transaction, proof, nil
+	}
+}
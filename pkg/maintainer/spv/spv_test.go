@@ -2,6 +2,7 @@ package spv
 
 import (
 	"encoding/hex"
+	"fmt"
 	"math/big"
 	"reflect"
 	"testing"
@@ -324,3 +325,183 @@ func TestIsInputCurrentWalletsMainUTXO(t *testing.T) {
 		})
 	}
 }
+
+func TestNewVerifyingSpvProofAssembler(t *testing.T) {
+	bytesFromHex := func(str string) []byte {
+		value, err := hex.DecodeString(str)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		return value
+	}
+
+	// Take a transaction and its proof assembled from the Bitcoin testnet:
+	// https://blockstream.info/testnet/api/tx/44c568bc0eac07a2a9c2b46829be5b5d46e7d00e17bfb613f506a75ccf86a473
+	transaction := new(bitcoin.Transaction)
+	err := transaction.Deserialize(bytesFromHex(
+		"01000000000101672ae7c34d6a225797f0e005f6ed53ee40252811a37e90f62b68" +
+			"eb5e587be68e0000000000ffffffff01d0200000000000001600148db50eb52063" +
+			"ea9d98b3eac91489a90f738986f603483045022100b12afadf68ad9781600f065e" +
+			"0b09e22058ca2293aa86ac38add3ca7cfb01b3b7022009ecce0c1c3ebd26569c6b" +
+			"0d60e15b4675860737487d1b7c782439acf4709bdf012103989d253b17a6a0f418" +
+			"38b84ff0d20e8898f9d7b1a98f2564da4cc29dcf8581d95c14934b98637ca318a4" +
+			"d6e7ca6ffd1690b8e77df6377508f9f0c90d000395237576a9148db50eb52063ea" +
+			"9d98b3eac91489a90f738986f68763ac6776a914e257eccafbc07c381642ce6e7e" +
+			"55120fb077fbed8804e0250162b175ac6800000000",
+	))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var coinbasePreimage [32]byte
+	copy(coinbasePreimage[:], bytesFromHex(
+		"8e690235847a80c4d300542a2d27b90bfd13d77b3421c1b5590f4220718cd3fd",
+	))
+
+	proof := &bitcoin.SpvProof{
+		MerkleProof: bytesFromHex(
+			"122b07a0611ce48cf91fdd97af55d5fa42386ccf41da7612869112c6f2afff7b0c" +
+				"33ea7a4510f83b76cec05ffe8a2d196ec62e9b730c65f03f558eeedd76587a1f90" +
+				"4114a4a9cf51b5a53414473ffbfd11fed3af5086effb39bc19557db6172d268033" +
+				"a093cecffa216503032b021959ab572a3e5562fae21c5977b602d17613807c774b" +
+				"d8255f1788338fb3a38bdef77c038e6a84eb598c395e67adad3aad439acf100cd3" +
+				"29feb55131d58f4573db1fb9b90ff2059ce9c9b393871227c269699c12869b3507" +
+				"cbe390e665c3d3a764e39a9ea88b184dbe5723533d8c4dbc760a",
+		),
+		TxIndexInBlock: 11,
+		BitcoinHeaders: bytesFromHex(
+			"04e00020732d33ea35d62f9488cff5d64c0d702afd5d88092230ddfcc45f000000" +
+				"000000196283ba24a3f5bad91ef95338aa6d214c934f2c1392e39a0447377fe5b0" +
+				"a04be7c01c62ffff001df0be0a27040000206c318b23e5c42e86ef3edd080e50c9" +
+				"c233b9f0b6d186bd57e41300000000000021fb8cda200bff4fec1338d85a1e005b" +
+				"b4d729d908a7c5c232ecd0713231d0445ec11c62ed3e031a7b43466e04e00020f4" +
+				"16898d79d4a46fa6c54f190ad3d502bad8aa3afdec0714aa000000000000000603" +
+				"a5cc15e5906cb4eac9f747869fdc9be856e76a110b4f87da90db20f9fbe28fc11c" +
+				"62ed3e031a15dfc3db04000020642125b3910fdaead521b57955e28893d89f8ce7" +
+				"fd3ba1dd6d01000000000000f9e17a266a2267ee02d5ab82a75a76805db821a13a" +
+				"bd2e80e0950d883311e5355dc21c62ed3e031adefc02c4040000205b6de55e069b" +
+				"e71b21a62cd140dc7031225f7258dc758f19ea01000000000000139966d27d9ed0" +
+				"c0c1ed9162c2fea2ccf0ba212706f6bc421d0a2b6211de040d1ac41c62ed3e031a" +
+				"4726538f04e000208475e15e0314635d32abf04c761fee528d6a3f2db3b3d13798" +
+				"000000000000002a3fa06fecd9dd4bf2e25e22a95d4f65435d5c5b42bcf498b4e7" +
+				"56f9f4ea67cea1c51c62ed3e031a9d7bf3ac",
+		),
+		CoinbasePreimage: coinbasePreimage,
+		CoinbaseProof: bytesFromHex(
+			"7a3d6e0a13cfdef3b485e67fb0f974ae23f5221d2840d9afc61850729f535b0995" +
+				"8b3147db829efe0522af7e74f50cdb1081d919f8ecb65110e6b16548636aad2490" +
+				"055c70440cfc2b48b7a5f97dea9722756c5168407ce207a50f6931d0990c7ebadf" +
+				"544efdca499d8d9138cf865d2419bda483a3a66090c3a85ee7a4812a35807c774b" +
+				"d8255f1788338fb3a38bdef77c038e6a84eb598c395e67adad3aad439acf100cd3" +
+				"29feb55131d58f4573db1fb9b90ff2059ce9c9b393871227c269699c12869b3507" +
+				"cbe390e665c3d3a764e39a9ea88b184dbe5723533d8c4dbc760a",
+		),
+	}
+
+	requiredConfirmations := uint(6)
+
+	// The first header of the proof was mined with the minimum difficulty.
+	firstHeaderDifficulty := big.NewInt(1)
+
+	tests := map[string]struct {
+		assemblerErr            error
+		txProofDifficultyFactor *big.Int
+		currentEpochDifficulty  *big.Int
+		previousEpochDifficulty *big.Int
+		expectedErr             bool
+	}{
+		"valid proof": {
+			txProofDifficultyFactor: big.NewInt(6),
+			currentEpochDifficulty:  firstHeaderDifficulty,
+			previousEpochDifficulty: big.NewInt(2),
+		},
+		"assembler error": {
+			assemblerErr:            fmt.Errorf("assembler error"),
+			txProofDifficultyFactor: big.NewInt(6),
+			currentEpochDifficulty:  firstHeaderDifficulty,
+			previousEpochDifficulty: big.NewInt(2),
+			expectedErr:             true,
+		},
+		"difficulty not matching relay epochs": {
+			txProofDifficultyFactor: big.NewInt(6),
+			currentEpochDifficulty:  big.NewInt(2),
+			previousEpochDifficulty: big.NewInt(3),
+			expectedErr:             true,
+		},
+		"accumulated difficulty below requested difficulty": {
+			txProofDifficultyFactor: big.NewInt(1000000000),
+			currentEpochDifficulty:  firstHeaderDifficulty,
+			previousEpochDifficulty: big.NewInt(2),
+			expectedErr:             true,
+		},
+	}
+
+	for testName, test := range tests {
+		t.Run(testName, func(t *testing.T) {
+			spvChain := newLocalChain()
+			spvChain.setTxProofDifficultyFactor(test.txProofDifficultyFactor)
+			spvChain.setCurrentAndPrevEpochDifficulty(
+				test.previousEpochDifficulty,
+				test.currentEpochDifficulty,
+			)
+
+			mockSpvProofAssembler := func(
+				hash bitcoin.Hash,
+				confirmations uint,
+				btcChain bitcoin.Chain,
+			) (*bitcoin.Transaction, *bitcoin.SpvProof, error) {
+				if test.assemblerErr != nil {
+					return nil, nil, test.assemblerErr
+				}
+
+				if hash == transaction.Hash() && confirmations == requiredConfirmations {
+					return transaction, proof, nil
+				}
+
+				return nil, nil, fmt.Errorf("error while assembling spv proof")
+			}
+
+			assembler := newVerifyingSpvProofAssembler(
+				mockSpvProofAssembler,
+				spvChain,
+				spvChain,
+			)
+
+			actualTransaction, actualProof, err := assembler(
+				transaction.Hash(),
+				requiredConfirmations,
+				newLocalBitcoinChain(),
+			)
+
+			if test.expectedErr {
+				if err == nil {
+					t.Fatal("expected error")
+				}
+				if actualTransaction != nil || actualProof != nil {
+					t.Errorf("expected no transaction and proof on error")
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !reflect.DeepEqual(transaction, actualTransaction) {
+				t.Errorf(
+					"unexpected transaction\nexpected: %v\nactual:   %v\n",
+					transaction,
+					actualTransaction,
+				)
+			}
+			if !reflect.DeepEqual(proof, actualProof) {
+				t.Errorf(
+					"unexpected proof\nexpected: %v\nactual:   %v\n",
+					proof,
+					actualProof,
+				)
+			}
+		})
+	}
+}
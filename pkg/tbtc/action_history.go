@@ -0,0 +1,522 @@
+package tbtc
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/keep-network/keep-common/pkg/persistence"
+
+	"github.com/keep-network/keep-core/internal/hexutils"
+	"github.com/keep-network/keep-core/pkg/bitcoin"
+	"github.com/keep-network/keep-core/pkg/clientinfo"
+)
+
+const (
+	// actionHistoryDirectory is the name of the work persistence directory
+	// holding the wallet action history.
+	actionHistoryDirectory = "action_history"
+
+	// actionHistoryDiagnosticsRecords is the number of the latest wallet
+	// action history records exposed by the diagnostics source.
+	actionHistoryDiagnosticsRecords = 20
+)
+
+const (
+	// WalletActionStageStarted denotes the record appended when the action
+	// execution starts.
+	WalletActionStageStarted = "started"
+	// WalletActionStageSigning denotes the record appended right before
+	// the node starts signing the sighashes held by the record.
+	WalletActionStageSigning = "signing"
+	// WalletActionStageFinished denotes the record appended when the action
+	// execution ends.
+	WalletActionStageFinished = "finished"
+)
+
+// WalletActionRecord is an entry of the wallet action history. Each entry
+// describes a single stage of a wallet action executed by the node. Entries
+// are hash-chained, i.e. each entry commits to the hash of the previous entry,
+// so any modification or removal of a past entry can be detected.
+type WalletActionRecord struct {
+	// Sequence is the zero-based position of the record in the history.
+	Sequence uint64 `json:"sequence"`
+	// ActionSequence is the sequence of the record that started the action
+	// this record belongs to.
+	ActionSequence uint64 `json:"actionSequence"`
+	// Stage is the stage of the action described by the record.
+	Stage string `json:"stage"`
+	// WalletPublicKeyHash is the 20-byte public key hash of the wallet
+	// that executed the action, as a 0x-prefixed hex string.
+	WalletPublicKeyHash string `json:"walletPublicKeyHash"`
+	// ActionType is the type of the executed action.
+	ActionType string `json:"actionType"`
+	// Proposal is the JSON encoding of the coordinated proposal
+	// the action was executed for.
+	Proposal json.RawMessage `json:"proposal"`
+	// CoordinationBlock is the coordination block of the window in which
+	// the proposal was coordinated.
+	CoordinationBlock uint64 `json:"coordinationBlock"`
+	// Leader is the address of the coordination leader.
+	Leader string `json:"leader"`
+	// StartBlock is the block at which the action execution started.
+	StartBlock uint64 `json:"startBlock"`
+	// ExpiryBlock is the block at which the action expires.
+	ExpiryBlock uint64 `json:"expiryBlock"`
+	// SigHashes holds the 0x-prefixed hex sighashes. For the signing stage,
+	// these are the sighashes about to be signed. For the finished stage,
+	// these are all sighashes signed during the action execution.
+	SigHashes []string `json:"sigHashes"`
+	// TransactionHash is the hash of the resulting Bitcoin transaction,
+	// in the reversed byte order. Empty if no transaction was produced.
+	TransactionHash string `json:"transactionHash"`
+	// Broadcast is true if the resulting Bitcoin transaction reached
+	// the Bitcoin chain.
+	Broadcast bool `json:"broadcast"`
+	// Error is the error the action execution terminated with. Empty if
+	// the action succeeded.
+	Error string `json:"error"`
+	// StartedAt is the UNIX timestamp of the action execution start.
+	StartedAt int64 `json:"startedAt"`
+	// FinishedAt is the UNIX timestamp of the action execution end.
+	FinishedAt int64 `json:"finishedAt"`
+	// PreviousHash is the hex hash of the previous record. Empty for
+	// the first record.
+	PreviousHash string `json:"previousHash"`
+	// Hash is the hex SHA-256 hash of the JSON encoding of the record with
+	// the Hash field empty.
+	Hash string `json:"hash"`
+}
+
+// computeHash computes the hash of the record. The Hash field is not
+// taken into account.
+func (war *WalletActionRecord) computeHash() (string, error) {
+	recordCopy := *war
+	recordCopy.Hash = ""
+
+	recordBytes, err := json.Marshal(&recordCopy)
+	if err != nil {
+		return "", fmt.Errorf("cannot marshal record: [%v]", err)
+	}
+
+	hash := sha256.Sum256(recordBytes)
+
+	return hex.EncodeToString(hash[:]), nil
+}
+
+// newWalletActionRecord creates a new history record for the action
+// executed as a result of the given coordination.
+func newWalletActionRecord(
+	result *coordinationResult,
+	startBlock uint64,
+	expiryBlock uint64,
+) (*WalletActionRecord, error) {
+	proposal, err := json.Marshal(result.proposal)
+	if err != nil {
+		return nil, fmt.Errorf("cannot marshal proposal: [%v]", err)
+	}
+
+	walletPublicKeyHash := bitcoin.PublicKeyHash(result.wallet.publicKey)
+
+	return &WalletActionRecord{
+		WalletPublicKeyHash: hexutils.Encode(walletPublicKeyHash[:]),
+		ActionType:          result.proposal.ActionType().String(),
+		Proposal:            proposal,
+		CoordinationBlock:   result.window.coordinationBlock,
+		Leader:              result.leader.String(),
+		StartBlock:          startBlock,
+		ExpiryBlock:         expiryBlock,
+		SigHashes:           []string{},
+	}, nil
+}
+
+// walletActionHistory is the persistent, hash-chained history of wallet
+// actions executed by the node.
+type walletActionHistory struct {
+	mutex       sync.Mutex
+	persistence persistence.BasicHandle
+
+	// nextSequence is the sequence number of the next record.
+	nextSequence uint64
+	// lastHash is the hash of the last record.
+	lastHash string
+	// latestRecords holds the latest records of the history, up to
+	// actionHistoryDiagnosticsRecords.
+	latestRecords []*WalletActionRecord
+}
+
+// newWalletActionHistory creates a new wallet action history backed by
+// the given work persistence. Records already stored in the persistence
+// are loaded so new records extend the existing hash chain.
+func newWalletActionHistory(
+	persistence persistence.BasicHandle,
+) (*walletActionHistory, error) {
+	records, err := ReadWalletActionHistory(persistence)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read action history: [%v]", err)
+	}
+
+	if err := VerifyWalletActionHistory(records); err != nil {
+		// The history is meant to be evidence so it must not be silently
+		// rewritten. New records keep extending the existing chain and the
+		// problem remains detectable by verification.
+		logger.Errorf("wallet action history verification failed: [%v]", err)
+	}
+
+	history := &walletActionHistory{
+		persistence:   persistence,
+		latestRecords: make([]*WalletActionRecord, 0),
+	}
+
+	if len(records) > 0 {
+		lastRecord := records[len(records)-1]
+		history.nextSequence = lastRecord.Sequence + 1
+		history.lastHash = lastRecord.Hash
+
+		if len(records) > actionHistoryDiagnosticsRecords {
+			records = records[len(records)-actionHistoryDiagnosticsRecords:]
+		}
+		history.latestRecords = append(history.latestRecords, records...)
+	}
+
+	return history, nil
+}
+
+// append chains the given record to the history and persists it.
+func (wah *walletActionHistory) append(record *WalletActionRecord) error {
+	wah.mutex.Lock()
+	defer wah.mutex.Unlock()
+
+	record.Sequence = wah.nextSequence
+	record.PreviousHash = wah.lastHash
+	if record.Stage == WalletActionStageStarted {
+		record.ActionSequence = record.Sequence
+	}
+
+	hash, err := record.computeHash()
+	if err != nil {
+		return fmt.Errorf("cannot compute record hash: [%v]", err)
+	}
+	record.Hash = hash
+
+	recordBytes, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("cannot marshal record: [%v]", err)
+	}
+
+	err = wah.persistence.Save(
+		recordBytes,
+		actionHistoryDirectory,
+		// Zero-padding keeps the files ordered by the sequence number.
+		fmt.Sprintf("%020d", record.Sequence),
+	)
+	if err != nil {
+		return fmt.Errorf("cannot save record: [%w]", err)
+	}
+
+	wah.nextSequence++
+	wah.lastHash = hash
+
+	wah.latestRecords = append(wah.latestRecords, record)
+	if len(wah.latestRecords) > actionHistoryDiagnosticsRecords {
+		wah.latestRecords = wah.latestRecords[1:]
+	}
+
+	return nil
+}
+
+// diagnostics returns the diagnostic information about the latest
+// wallet actions executed by the node.
+func (wah *walletActionHistory) diagnostics() clientinfo.ApplicationInfo {
+	wah.mutex.Lock()
+	defer wah.mutex.Unlock()
+
+	records := make([]*WalletActionRecord, len(wah.latestRecords))
+	copy(records, wah.latestRecords)
+
+	return clientinfo.ApplicationInfo{
+		"records_count":  wah.nextSequence,
+		"last_hash":      wah.lastHash,
+		"latest_records": records,
+	}
+}
+
+// walletActionRecorder records the progress of a single wallet action in
+// the wallet action history. Each stage of the action is appended as
+// a separate record so the history remains append-only and the sighashes
+// are persisted before the node signs them. All methods are safe to call
+// on a nil recorder.
+type walletActionRecorder struct {
+	mutex   sync.Mutex
+	record  *WalletActionRecord
+	history *walletActionHistory
+}
+
+// newWalletActionRecorder creates a recorder appending the stages of
+// the action described by the given record to the given history. Returns
+// nil if the record is nil.
+func newWalletActionRecorder(
+	record *WalletActionRecord,
+	history *walletActionHistory,
+) *walletActionRecorder {
+	if record == nil {
+		return nil
+	}
+
+	return &walletActionRecorder{
+		record:  record,
+		history: history,
+	}
+}
+
+// start records the start of the action execution.
+func (war *walletActionRecorder) start() error {
+	if war == nil {
+		return nil
+	}
+
+	war.mutex.Lock()
+	defer war.mutex.Unlock()
+
+	war.record.StartedAt = time.Now().Unix()
+
+	sequence, err := war.appendStage(WalletActionStageStarted, []string{})
+	if err != nil {
+		return err
+	}
+
+	war.record.ActionSequence = sequence
+
+	return nil
+}
+
+// recordSigning records the sighashes the node is about to sign. It must
+// be called before the signing starts so the evidence of the signed
+// sighashes survives even if the signing is interrupted.
+func (war *walletActionRecorder) recordSigning(sigHashes []*big.Int) error {
+	if war == nil {
+		return nil
+	}
+
+	war.mutex.Lock()
+	defer war.mutex.Unlock()
+
+	encodedSigHashes := make([]string, len(sigHashes))
+	for i, sigHash := range sigHashes {
+		encodedSigHashes[i] = hexutils.Encode(sigHash.Bytes())
+	}
+
+	_, err := war.appendStage(WalletActionStageSigning, encodedSigHashes)
+	if err != nil {
+		return err
+	}
+
+	war.record.SigHashes = append(war.record.SigHashes, encodedSigHashes...)
+
+	return nil
+}
+
+// recordTransaction records the resulting Bitcoin transaction and its
+// broadcast status. They are persisted along with the finished stage.
+func (war *walletActionRecorder) recordTransaction(
+	transactionHash bitcoin.Hash,
+	broadcast bool,
+) {
+	if war == nil {
+		return
+	}
+
+	war.mutex.Lock()
+	defer war.mutex.Unlock()
+
+	war.record.TransactionHash = transactionHash.Hex(bitcoin.ReversedByteOrder)
+	war.record.Broadcast = broadcast
+}
+
+// finish records the end of the action execution with the given error.
+func (war *walletActionRecorder) finish(actionErr error) error {
+	if war == nil {
+		return nil
+	}
+
+	war.mutex.Lock()
+	defer war.mutex.Unlock()
+
+	war.record.FinishedAt = time.Now().Unix()
+	if actionErr != nil {
+		war.record.Error = actionErr.Error()
+	}
+
+	_, err := war.appendStage(WalletActionStageFinished, war.record.SigHashes)
+
+	return err
+}
+
+// appendStage appends a copy of the action record with the given stage and
+// sighashes to the history. Returns the sequence of the appended record.
+// Must be called with the recorder mutex held.
+func (war *walletActionRecorder) appendStage(
+	stage string,
+	sigHashes []string,
+) (uint64, error) {
+	entry := *war.record
+	entry.Stage = stage
+	entry.SigHashes = append([]string{}, sigHashes...)
+
+	if err := war.history.append(&entry); err != nil {
+		return 0, fmt.Errorf(
+			"cannot append [%s] stage record: [%w]",
+			stage,
+			err,
+		)
+	}
+
+	return entry.Sequence, nil
+}
+
+// recordedWalletAction is a walletAction decorator recording the progress
+// of the action execution in the wallet action history.
+type recordedWalletAction struct {
+	walletAction
+
+	recorder *walletActionRecorder
+}
+
+func (rwa *recordedWalletAction) execute() error {
+	// The action is not executed if its start cannot be recorded. The
+	// sighashes could not be recorded before signing anyway.
+	if err := rwa.recorder.start(); err != nil {
+		return fmt.Errorf(
+			"cannot record action in wallet action history: [%v]",
+			err,
+		)
+	}
+
+	err := rwa.walletAction.execute()
+
+	if historyErr := rwa.recorder.finish(err); historyErr != nil {
+		logger.Errorf(
+			"cannot record action in wallet action history: [%v]",
+			historyErr,
+		)
+	}
+
+	return err
+}
+
+// ReadWalletActionHistory reads all wallet action history records from
+// the given work persistence. Records are returned in the order of their
+// sequence numbers.
+func ReadWalletActionHistory(
+	persistence persistence.BasicHandle,
+) ([]*WalletActionRecord, error) {
+	records := make([]*WalletActionRecord, 0)
+
+	descriptorsChan, errorsChan := persistence.ReadAll()
+
+	// Both channels are unbuffered and must be drained concurrently.
+	var readErrors []error
+	errorsDone := make(chan struct{})
+	go func() {
+		for err := range errorsChan {
+			readErrors = append(readErrors, err)
+		}
+		close(errorsDone)
+	}()
+
+	var contentErr error
+	for descriptor := range descriptorsChan {
+		if descriptor.Directory() != actionHistoryDirectory ||
+			contentErr != nil {
+			continue
+		}
+
+		content, err := descriptor.Content()
+		if err != nil {
+			contentErr = fmt.Errorf(
+				"cannot read record [%s]: [%v]",
+				descriptor.Name(),
+				err,
+			)
+			continue
+		}
+
+		record := &WalletActionRecord{}
+		if err := json.Unmarshal(content, record); err != nil {
+			contentErr = fmt.Errorf(
+				"cannot unmarshal record [%s]: [%v]",
+				descriptor.Name(),
+				err,
+			)
+			continue
+		}
+
+		records = append(records, record)
+	}
+
+	<-errorsDone
+
+	if len(readErrors) > 0 {
+		return nil, fmt.Errorf("cannot read persistence: [%v]", readErrors[0])
+	}
+	if contentErr != nil {
+		return nil, contentErr
+	}
+
+	sort.Slice(records, func(i, j int) bool {
+		return records[i].Sequence < records[j].Sequence
+	})
+
+	return records, nil
+}
+
+// VerifyWalletActionHistory verifies the integrity of the given wallet
+// action history records. The records must be ordered by their sequence
+// numbers. The function checks that there are no gaps in the sequence,
+// that each record's hash matches its content, and that each record
+// commits to the hash of its predecessor.
+func VerifyWalletActionHistory(records []*WalletActionRecord) error {
+	previousHash := ""
+
+	for i, record := range records {
+		if record.Sequence != uint64(i) {
+			return fmt.Errorf(
+				"unexpected sequence [%v] of record at position [%v]",
+				record.Sequence,
+				i,
+			)
+		}
+
+		if record.PreviousHash != previousHash {
+			return fmt.Errorf(
+				"record [%v] does not point to the previous record",
+				record.Sequence,
+			)
+		}
+
+		hash, err := record.computeHash()
+		if err != nil {
+			return fmt.Errorf(
+				"cannot compute hash of record [%v]: [%v]",
+				record.Sequence,
+				err,
+			)
+		}
+
+		if record.Hash != hash {
+			return fmt.Errorf(
+				"hash of record [%v] does not match its content",
+				record.Sequence,
+			)
+		}
+
+		previousHash = record.Hash
+	}
+
+	return nil
+}
@@ -0,0 +1,272 @@
+package tbtc
+
+import (
+	"crypto/ecdsa"
+	"crypto/rand"
+	"fmt"
+	"math/big"
+	"testing"
+
+	"github.com/keep-network/keep-core/internal/testutils"
+	"github.com/keep-network/keep-core/pkg/bitcoin"
+	"github.com/keep-network/keep-core/pkg/chain"
+	"github.com/keep-network/keep-core/pkg/tecdsa"
+)
+
+func TestWalletActionHistory(t *testing.T) {
+	persistenceHandle := &mockPersistenceHandle{}
+
+	history, err := newWalletActionHistory(persistenceHandle)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	walletPrivateKey, err := ecdsa.GenerateKey(tecdsa.Curve, rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result := &coordinationResult{
+		wallet: wallet{publicKey: &walletPrivateKey.PublicKey},
+		window: newCoordinationWindow(900, mainnetProtocolTimings()),
+		leader: chain.Address("0xE1DE4B9EF6B2F6fE3E3E1e5eD7f3bAd3E63d0C8a"),
+		proposal: &RedemptionProposal{
+			RedeemersOutputScripts: []bitcoin.Script{{0x00, 0x14, 0x01}},
+			RedemptionTxFee:        big.NewInt(10000),
+		},
+	}
+
+	// The first action succeeds and produces a broadcast transaction.
+	succeededRecord, err := newWalletActionRecord(result, 1000, 1600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	succeededRecorder := newWalletActionRecorder(succeededRecord, history)
+
+	err = (&recordedWalletAction{
+		walletAction: &mockWalletAction{
+			executeFn: func() error {
+				err := succeededRecorder.recordSigning(
+					[]*big.Int{big.NewInt(0xaabb)},
+				)
+				if err != nil {
+					return err
+				}
+
+				// The sighashes must be persisted before the signing
+				// starts, i.e. before the action finishes.
+				records, err := ReadWalletActionHistory(persistenceHandle)
+				if err != nil {
+					return err
+				}
+				if len(records) != 2 {
+					return fmt.Errorf(
+						"unexpected records count before signing: [%v]",
+						len(records),
+					)
+				}
+
+				succeededRecorder.recordTransaction(bitcoin.Hash{0x01}, true)
+
+				return nil
+			},
+		},
+		recorder: succeededRecorder,
+	}).execute()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The second action fails.
+	failedRecord, err := newWalletActionRecord(result, 1000, 1600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = (&recordedWalletAction{
+		walletAction: &mockWalletAction{
+			executeFn: func() error { return fmt.Errorf("signing failed") },
+		},
+		recorder: newWalletActionRecorder(failedRecord, history),
+	}).execute()
+	if err == nil {
+		t.Fatal("expected error")
+	}
+
+	// Reading the history back must yield a valid hash chain.
+	records, err := ReadWalletActionHistory(persistenceHandle)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testutils.AssertIntsEqual(t, "records count", 5, len(records))
+
+	if err := VerifyWalletActionHistory(records); err != nil {
+		t.Fatalf("unexpected verification error: [%v]", err)
+	}
+
+	expectedStages := []string{
+		WalletActionStageStarted,
+		WalletActionStageSigning,
+		WalletActionStageFinished,
+		WalletActionStageStarted,
+		WalletActionStageFinished,
+	}
+	expectedActionSequences := []uint64{0, 0, 0, 3, 3}
+	for i, record := range records {
+		testutils.AssertStringsEqual(
+			t,
+			fmt.Sprintf("stage of record [%v]", i),
+			expectedStages[i],
+			record.Stage,
+		)
+		testutils.AssertUintsEqual(
+			t,
+			fmt.Sprintf("action sequence of record [%v]", i),
+			expectedActionSequences[i],
+			record.ActionSequence,
+		)
+	}
+
+	testutils.AssertStringsEqual(
+		t,
+		"action type",
+		ActionRedemption.String(),
+		records[0].ActionType,
+	)
+	testutils.AssertUintsEqual(
+		t,
+		"coordination block",
+		900,
+		records[0].CoordinationBlock,
+	)
+	testutils.AssertIntsEqual(
+		t,
+		"started record sighashes count",
+		0,
+		len(records[0].SigHashes),
+	)
+	testutils.AssertStringsEqual(
+		t,
+		"signing record sighash",
+		"0xaabb",
+		records[1].SigHashes[0],
+	)
+	testutils.AssertStringsEqual(
+		t,
+		"signing record transaction hash",
+		"",
+		records[1].TransactionHash,
+	)
+	testutils.AssertStringsEqual(
+		t,
+		"finished record sighash",
+		"0xaabb",
+		records[2].SigHashes[0],
+	)
+	testutils.AssertStringsEqual(
+		t,
+		"transaction hash",
+		bitcoin.Hash{0x01}.Hex(bitcoin.ReversedByteOrder),
+		records[2].TransactionHash,
+	)
+	testutils.AssertBoolsEqual(t, "broadcast", true, records[2].Broadcast)
+	testutils.AssertStringsEqual(t, "error", "", records[2].Error)
+	testutils.AssertStringsEqual(
+		t,
+		"error",
+		"signing failed",
+		records[4].Error,
+	)
+	testutils.AssertStringsEqual(
+		t,
+		"previous hash",
+		records[3].Hash,
+		records[4].PreviousHash,
+	)
+
+	// A new history instance must extend the existing chain.
+	reloadedHistory, err := newWalletActionHistory(persistenceHandle)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testutils.AssertUintsEqual(
+		t,
+		"next sequence",
+		5,
+		reloadedHistory.nextSequence,
+	)
+	testutils.AssertStringsEqual(
+		t,
+		"last hash",
+		records[4].Hash,
+		reloadedHistory.lastHash,
+	)
+}
+
+func TestVerifyWalletActionHistory_Tampered(t *testing.T) {
+	persistenceHandle := &mockPersistenceHandle{}
+
+	history, err := newWalletActionHistory(persistenceHandle)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 3; i++ {
+		err := history.append(&WalletActionRecord{
+			ActionType: ActionDepositSweep.String(),
+			SigHashes:  []string{},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var tests = map[string]struct {
+		tamperFn      func(records []*WalletActionRecord) []*WalletActionRecord
+		expectedError string
+	}{
+		"modified record": {
+			tamperFn: func(records []*WalletActionRecord) []*WalletActionRecord {
+				records[1].Error = "forged"
+				return records
+			},
+			expectedError: "hash of record [1] does not match its content",
+		},
+		"removed record": {
+			tamperFn: func(records []*WalletActionRecord) []*WalletActionRecord {
+				return append(records[:1], records[2:]...)
+			},
+			expectedError: "unexpected sequence [2] of record at position [1]",
+		},
+		"rechained record": {
+			tamperFn: func(records []*WalletActionRecord) []*WalletActionRecord {
+				records[2].PreviousHash = records[0].Hash
+				return records
+			},
+			expectedError: "record [2] does not point to the previous record",
+		},
+	}
+
+	for testName, test := range tests {
+		t.Run(testName, func(t *testing.T) {
+			records, err := ReadWalletActionHistory(persistenceHandle)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			err = VerifyWalletActionHistory(test.tamperFn(records))
+			if err == nil {
+				t.Fatal("expected error")
+			}
+
+			testutils.AssertStringsEqual(
+				t,
+				"error",
+				test.expectedError,
+				err.Error(),
+			)
+		})
+	}
+}
@@ -14,6 +14,9 @@ type localBitcoinChain struct {
 
 	mempoolMutex sync.Mutex
 	mempool      []*bitcoin.Transaction
+
+	latestBlockHeightMutex sync.Mutex
+	latestBlockHeight      uint
 }
 
 func newLocalBitcoinChain() *localBitcoinChain {
@@ -48,6 +51,12 @@ func (lbc *localBitcoinChain) GetTransactionConfirmations(
 		}
 	}
 
+	for _, transaction := range lbc.mempool {
+		if transaction.Hash() == transactionHash {
+			return 0, nil
+		}
+	}
+
 	return 0, fmt.Errorf("transaction not found")
 }
 
@@ -71,7 +80,17 @@ func (lbc *localBitcoinChain) BroadcastTransaction(
 }
 
 func (lbc *localBitcoinChain) GetLatestBlockHeight() (uint, error) {
-	panic("not implemented")
+	lbc.latestBlockHeightMutex.Lock()
+	defer lbc.latestBlockHeightMutex.Unlock()
+
+	return lbc.latestBlockHeight, nil
+}
+
+func (lbc *localBitcoinChain) setLatestBlockHeight(latestBlockHeight uint) {
+	lbc.latestBlockHeightMutex.Lock()
+	defer lbc.latestBlockHeightMutex.Unlock()
+
+	lbc.latestBlockHeight = latestBlockHeight
 }
 
 func (lbc *localBitcoinChain) GetBlockHeader(
@@ -66,6 +66,14 @@ type DistributedKeyGenerationChain interface {
 		func(event *DKGResultSubmittedEvent),
 	) subscription.EventSubscription
 
+	// PastDKGResultSubmittedEvents fetches past DKG result submitted events
+	// according to the provided filter or unfiltered if the filter is nil.
+	// Returned events are sorted by the block number in the ascending order,
+	// i.e. the latest event is at the end of the slice.
+	PastDKGResultSubmittedEvents(
+		filter *DKGResultSubmittedEventFilter,
+	) ([]*DKGResultSubmittedEvent, error)
+
 	// OnDKGResultChallenged registers a callback that is invoked when an
 	// on-chain notification of the DKG result challenge is seen.
 	OnDKGResultChallenged(
@@ -119,6 +127,10 @@ type DistributedKeyGenerationChain interface {
 
 	// DKGParameters gets the current value of DKG-specific control parameters.
 	DKGParameters() (*DKGParameters, error)
+
+	// GroupParameters gets the group parameters enforced by the on-chain
+	// contracts.
+	GroupParameters() (*GroupParameters, error)
 }
 
 // InactivityClaimedEvent represents an inactivity claimed event. It is emitted
@@ -211,6 +223,15 @@ type DKGResultSubmittedEvent struct {
 	BlockNumber uint64
 }
 
+// DKGResultSubmittedEventFilter is a component allowing to filter
+// DKGResultSubmittedEvent.
+type DKGResultSubmittedEventFilter struct {
+	StartBlock uint64
+	EndBlock   *uint64
+	ResultHash []DKGChainResultHash
+	Seed       []*big.Int
+}
+
 // DKGResultChallengedEvent represents a DKG result challenge event. It is
 // emitted after a submitted DKG result is challenged as an invalid result.
 type DKGResultChallengedEvent struct {
@@ -257,6 +278,14 @@ type BridgeChain interface {
 	// if the wallet was not found.
 	GetWallet(walletPublicKeyHash [20]byte) (*WalletChainData, error)
 
+	// PastNewWalletRegisteredEvents fetches past new wallet registered events
+	// according to the provided filter or unfiltered if the filter is nil. Returned
+	// events are sorted by the block number in the ascending order, i.e. the
+	// latest event is at the end of the slice.
+	PastNewWalletRegisteredEvents(
+		filter *NewWalletRegisteredEventFilter,
+	) ([]*NewWalletRegisteredEvent, error)
+
 	// OnWalletClosed registers a callback that is invoked when an on-chain
 	// notification of the wallet closed is seen. The notification occurs when
 	// the wallet is closed or terminated.
@@ -300,6 +329,29 @@ type BridgeChain interface {
 		movingFundsTxOutpointIndex uint32,
 	) (*MovedFundsSweepRequest, bool, error)
 
+	// GetDepositParameters gets the current value of parameters relevant
+	// for the depositing process.
+	GetDepositParameters() (
+		dustThreshold uint64,
+		treasuryFeeDivisor uint64,
+		txMaxFee uint64,
+		revealAheadPeriod uint32,
+		err error,
+	)
+
+	// GetRedemptionParameters gets the current value of parameters relevant
+	// for the redemption process.
+	GetRedemptionParameters() (
+		dustThreshold uint64,
+		treasuryFeeDivisor uint64,
+		txMaxFee uint64,
+		txMaxTotalFee uint64,
+		timeout uint32,
+		timeoutSlashingAmount *big.Int,
+		timeoutNotifierRewardMultiplier uint32,
+		err error,
+	)
+
 	// GetMovingFundsParameters gets the current value of parameters relevant
 	// for the moving funds process.
 	GetMovingFundsParameters() (
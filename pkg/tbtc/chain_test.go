@@ -32,6 +32,23 @@ const (
 	stakingProvider      = chain.Address("0x1111111111111111111111111111111111111111")
 )
 
+type depositParameters = struct {
+	dustThreshold      uint64
+	treasuryFeeDivisor uint64
+	txMaxFee           uint64
+	revealAheadPeriod  uint32
+}
+
+type redemptionParameters = struct {
+	dustThreshold                   uint64
+	treasuryFeeDivisor              uint64
+	txMaxFee                        uint64
+	txMaxTotalFee                   uint64
+	timeout                         uint32
+	timeoutSlashingAmount           *big.Int
+	timeoutNotifierRewardMultiplier uint32
+}
+
 type movingFundsParameters = struct {
 	txMaxTotalFee                        uint64
 	dustThreshold                        uint64
@@ -84,6 +101,12 @@ type localChain struct {
 	pastMovingFundsCommitmentSubmittedEventsMutex sync.Mutex
 	pastMovingFundsCommitmentSubmittedEvents      map[[32]byte][]*MovingFundsCommitmentSubmittedEvent
 
+	pastNewWalletRegisteredEventsMutex sync.Mutex
+	pastNewWalletRegisteredEvents      []*NewWalletRegisteredEvent
+
+	pastDKGResultSubmittedEventsMutex sync.Mutex
+	pastDKGResultSubmittedEvents      []*DKGResultSubmittedEvent
+
 	depositSweepProposalValidationsMutex sync.Mutex
 	depositSweepProposalValidations      map[[32]byte]bool
 
@@ -108,6 +131,12 @@ type localChain struct {
 	depositRequestsMutex sync.Mutex
 	depositRequests      map[[32]byte]*DepositChainRequest
 
+	depositParametersMutex sync.Mutex
+	depositParameters      depositParameters
+
+	redemptionParametersMutex sync.Mutex
+	redemptionParameters      redemptionParameters
+
 	movingFundsParametersMutex sync.Mutex
 	movingFundsParameters      movingFundsParameters
 
@@ -288,6 +317,22 @@ func (lc *localChain) GetMovedFundsSweepRequest(
 	return request, true, nil
 }
 
+func (lc *localChain) setMovedFundsSweepRequest(
+	movingFundsTxHash bitcoin.Hash,
+	movingFundsTxOutpointIndex uint32,
+	request *MovedFundsSweepRequest,
+) {
+	lc.movedFundsSweepRequestsMutex.Lock()
+	defer lc.movedFundsSweepRequestsMutex.Unlock()
+
+	requestKey := buildMovedFundsSweepRequestKey(
+		movingFundsTxHash,
+		movingFundsTxOutpointIndex,
+	)
+
+	lc.movedFundsSweepRequests[requestKey] = request
+}
+
 func (lc *localChain) GetOperatorID(
 	operatorAddress chain.Address,
 ) (chain.OperatorID, error) {
@@ -336,6 +381,32 @@ func (lc *localChain) OnDKGResultSubmitted(
 	})
 }
 
+// PastDKGResultSubmittedEvents returns all DKG result submitted events
+// seen by the local chain. The filter is ignored.
+func (lc *localChain) PastDKGResultSubmittedEvents(
+	filter *DKGResultSubmittedEventFilter,
+) ([]*DKGResultSubmittedEvent, error) {
+	lc.pastDKGResultSubmittedEventsMutex.Lock()
+	defer lc.pastDKGResultSubmittedEventsMutex.Unlock()
+
+	return append(
+		[]*DKGResultSubmittedEvent{},
+		lc.pastDKGResultSubmittedEvents...,
+	), nil
+}
+
+func (lc *localChain) addPastDKGResultSubmittedEvent(
+	event *DKGResultSubmittedEvent,
+) {
+	lc.pastDKGResultSubmittedEventsMutex.Lock()
+	defer lc.pastDKGResultSubmittedEventsMutex.Unlock()
+
+	lc.pastDKGResultSubmittedEvents = append(
+		lc.pastDKGResultSubmittedEvents,
+		event,
+	)
+}
+
 func (lc *localChain) OnDKGResultChallenged(
 	handler func(event *DKGResultChallengedEvent),
 ) subscription.EventSubscription {
@@ -447,13 +518,17 @@ func (lc *localChain) SubmitDKGResult(
 
 	resultHash := computeDkgChainResultHash(dkgResult)
 
+	event := &DKGResultSubmittedEvent{
+		Seed:        nil,
+		ResultHash:  resultHash,
+		Result:      dkgResult,
+		BlockNumber: blockNumber,
+	}
+
+	lc.addPastDKGResultSubmittedEvent(event)
+
 	for _, handler := range lc.dkgResultSubmissionHandlers {
-		handler(&DKGResultSubmittedEvent{
-			Seed:        nil,
-			ResultHash:  resultHash,
-			Result:      dkgResult,
-			BlockNumber: blockNumber,
-		})
+		handler(event)
 	}
 
 	lc.dkgState = Challenge
@@ -594,6 +669,10 @@ func (lc *localChain) DKGParameters() (*DKGParameters, error) {
 	}, nil
 }
 
+func (lc *localChain) GroupParameters() (*GroupParameters, error) {
+	panic("unsupported")
+}
+
 func (lc *localChain) OnInactivityClaimed(
 	handler func(event *InactivityClaimedEvent),
 ) subscription.EventSubscription {
@@ -919,6 +998,32 @@ func (lc *localChain) setWallet(
 	lc.wallets[walletPublicKeyHash] = walletChainData
 }
 
+// PastNewWalletRegisteredEvents returns all new wallet registered events
+// added to the local chain. The filter is ignored.
+func (lc *localChain) PastNewWalletRegisteredEvents(
+	filter *NewWalletRegisteredEventFilter,
+) ([]*NewWalletRegisteredEvent, error) {
+	lc.pastNewWalletRegisteredEventsMutex.Lock()
+	defer lc.pastNewWalletRegisteredEventsMutex.Unlock()
+
+	return append(
+		[]*NewWalletRegisteredEvent{},
+		lc.pastNewWalletRegisteredEvents...,
+	), nil
+}
+
+func (lc *localChain) addPastNewWalletRegisteredEvent(
+	event *NewWalletRegisteredEvent,
+) {
+	lc.pastNewWalletRegisteredEventsMutex.Lock()
+	defer lc.pastNewWalletRegisteredEventsMutex.Unlock()
+
+	lc.pastNewWalletRegisteredEvents = append(
+		lc.pastNewWalletRegisteredEvents,
+		event,
+	)
+}
+
 func (lc *localChain) OnWalletClosed(
 	handler func(event *WalletClosedEvent),
 ) subscription.EventSubscription {
@@ -1307,6 +1412,86 @@ func buildMovedFundsSweepProposalValidationKey(
 	return sha256.Sum256(buffer.Bytes()), nil
 }
 
+func (lc *localChain) GetDepositParameters() (
+	dustThreshold uint64,
+	treasuryFeeDivisor uint64,
+	txMaxFee uint64,
+	revealAheadPeriod uint32,
+	err error,
+) {
+	lc.depositParametersMutex.Lock()
+	defer lc.depositParametersMutex.Unlock()
+
+	return lc.depositParameters.dustThreshold,
+		lc.depositParameters.treasuryFeeDivisor,
+		lc.depositParameters.txMaxFee,
+		lc.depositParameters.revealAheadPeriod,
+		nil
+}
+
+func (lc *localChain) SetDepositParameters(
+	dustThreshold uint64,
+	treasuryFeeDivisor uint64,
+	txMaxFee uint64,
+	revealAheadPeriod uint32,
+) {
+	lc.depositParametersMutex.Lock()
+	defer lc.depositParametersMutex.Unlock()
+
+	lc.depositParameters = depositParameters{
+		dustThreshold:      dustThreshold,
+		treasuryFeeDivisor: treasuryFeeDivisor,
+		txMaxFee:           txMaxFee,
+		revealAheadPeriod:  revealAheadPeriod,
+	}
+}
+
+func (lc *localChain) GetRedemptionParameters() (
+	dustThreshold uint64,
+	treasuryFeeDivisor uint64,
+	txMaxFee uint64,
+	txMaxTotalFee uint64,
+	timeout uint32,
+	timeoutSlashingAmount *big.Int,
+	timeoutNotifierRewardMultiplier uint32,
+	err error,
+) {
+	lc.redemptionParametersMutex.Lock()
+	defer lc.redemptionParametersMutex.Unlock()
+
+	return lc.redemptionParameters.dustThreshold,
+		lc.redemptionParameters.treasuryFeeDivisor,
+		lc.redemptionParameters.txMaxFee,
+		lc.redemptionParameters.txMaxTotalFee,
+		lc.redemptionParameters.timeout,
+		lc.redemptionParameters.timeoutSlashingAmount,
+		lc.redemptionParameters.timeoutNotifierRewardMultiplier,
+		nil
+}
+
+func (lc *localChain) SetRedemptionParameters(
+	dustThreshold uint64,
+	treasuryFeeDivisor uint64,
+	txMaxFee uint64,
+	txMaxTotalFee uint64,
+	timeout uint32,
+	timeoutSlashingAmount *big.Int,
+	timeoutNotifierRewardMultiplier uint32,
+) {
+	lc.redemptionParametersMutex.Lock()
+	defer lc.redemptionParametersMutex.Unlock()
+
+	lc.redemptionParameters = redemptionParameters{
+		dustThreshold:                   dustThreshold,
+		treasuryFeeDivisor:              treasuryFeeDivisor,
+		txMaxFee:                        txMaxFee,
+		txMaxTotalFee:                   txMaxTotalFee,
+		timeout:                         timeout,
+		timeoutSlashingAmount:           timeoutSlashingAmount,
+		timeoutNotifierRewardMultiplier: timeoutNotifierRewardMultiplier,
+	}
+}
+
 func (lc *localChain) GetMovingFundsParameters() (
 	txMaxTotalFee uint64,
 	dustThreshold uint64,
@@ -1475,6 +1660,7 @@ func ConnectWithKey(
 		movedFundsSweepProposalValidations:       make(map[[32]byte]bool),
 		heartbeatProposalValidations:             make(map[[16]byte]bool),
 		depositRequests:                          make(map[[32]byte]*DepositChainRequest),
+		movedFundsSweepRequests:                  make(map[[32]byte]*MovedFundsSweepRequest),
 		eligibleStakes:                           make(map[chain.Address]*big.Int),
 		blockCounter:                             blockCounter,
 		operatorPrivateKey:                       operatorPrivateKey,
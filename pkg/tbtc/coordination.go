@@ -23,24 +23,6 @@ import (
 )
 
 const (
-	// coordinationFrequencyBlocks is the number of blocks between two
-	// consecutive coordination windows.
-	coordinationFrequencyBlocks = 900
-	// coordinationActivePhaseDurationBlocks is the number of blocks in the
-	// active phase of the coordination window. The active phase is the
-	// phase during which the communication between the coordination leader and
-	// their followers is allowed.
-	coordinationActivePhaseDurationBlocks = 80
-	// coordinationPassivePhaseDurationBlocks is the number of blocks in the
-	// passive phase of the coordination window. The passive phase is the
-	// phase during which communication is not allowed. Participants are
-	// expected to validate the result of the coordination and prepare for
-	// execution of the proposed wallet action.
-	coordinationPassivePhaseDurationBlocks = 20
-	// coordinationDurationBlocks is the number of blocks in a single
-	// coordination window.
-	coordinationDurationBlocks = coordinationActivePhaseDurationBlocks +
-		coordinationPassivePhaseDurationBlocks
 	// coordinationSafeBlockShift is the number of blocks by which the
 	// coordination block is shifted to obtain a safe block whose 32-byte
 	// hash can be used as an ingredient for the coordination seed, computed
@@ -50,6 +32,11 @@ const (
 	// heartbeat action during the coordination procedure, assuming no other
 	// higher-priority action is proposed.
 	coordinationHeartbeatProbability = float64(0.0625)
+	// coordinationKeyRefreshFrequencyBlocks is the number of blocks between
+	// subsequent checks of the key refresh action. The actual frequency is
+	// expressed in coordination windows of the active protocol profile. The
+	// value of 50400 blocks is roughly 1 week, assuming 12 seconds per block.
+	coordinationKeyRefreshFrequencyBlocks = 50400
 	// coordinationMessageReceiveBuffer is a buffer for messages received from
 	// the broadcast channel needed when the coordination follower is
 	// temporarily too slow to handle them. Keep in mind that although we
@@ -69,25 +56,33 @@ var errCoordinationExecutorBusy = fmt.Errorf("coordination executor is busy")
 type coordinationWindow struct {
 	// coordinationBlock is the first block of the coordination window.
 	coordinationBlock uint64
+	// timings are the protocol timings determining the length and
+	// frequency of coordination windows.
+	timings *ProtocolTimings
 }
 
 // newCoordinationWindow creates a new coordination window for the given
-// coordination block.
-func newCoordinationWindow(coordinationBlock uint64) *coordinationWindow {
+// coordination block and protocol timings.
+func newCoordinationWindow(
+	coordinationBlock uint64,
+	timings *ProtocolTimings,
+) *coordinationWindow {
 	return &coordinationWindow{
 		coordinationBlock: coordinationBlock,
+		timings:           timings,
 	}
 }
 
 // ActivePhaseEndBlock returns the block number at which the active phase
 // of the coordination window ends.
 func (cw *coordinationWindow) activePhaseEndBlock() uint64 {
-	return cw.coordinationBlock + coordinationActivePhaseDurationBlocks
+	return cw.coordinationBlock +
+		cw.timings.CoordinationActivePhaseDurationBlocks
 }
 
 // EndBlock returns the block number at which the coordination window ends.
 func (cw *coordinationWindow) endBlock() uint64 {
-	return cw.coordinationBlock + coordinationDurationBlocks
+	return cw.coordinationBlock + cw.timings.coordinationDurationBlocks()
 }
 
 // isAfter returns true if this coordination window is after the other
@@ -104,7 +99,7 @@ func (cw *coordinationWindow) isAfter(other *coordinationWindow) bool {
 // by dividing the coordination block number by the coordination frequency.
 // A valid index is a positive integer.
 //
-// For example:
+// For example, for the coordination frequency of 900 blocks:
 // - window starting at block 900 has index 1
 // - window starting at block 1800 has index 2
 // - window starting at block 2700 has index 3
@@ -112,20 +107,24 @@ func (cw *coordinationWindow) isAfter(other *coordinationWindow) bool {
 // If the coordination block number is not a multiple of the coordination
 // frequency, the index is 0.
 func (cw *coordinationWindow) index() uint64 {
-	if cw.coordinationBlock%coordinationFrequencyBlocks == 0 {
-		return cw.coordinationBlock / coordinationFrequencyBlocks
+	frequencyBlocks := cw.timings.CoordinationFrequencyBlocks
+
+	if cw.coordinationBlock%frequencyBlocks == 0 {
+		return cw.coordinationBlock / frequencyBlocks
 	}
 
 	return 0
 }
 
 // watchCoordinationWindows watches for new coordination windows and runs
-// the given callback when a new window is detected. The callback is run
+// the given callback when a new window is detected. Windows are determined
+// by the given protocol timings. The callback is run
 // in a separate goroutine. It is guaranteed that the callback is not run
 // twice for the same window. The context passed as the first parameter
 // is used to cancel the watch.
 func watchCoordinationWindows(
 	ctx context.Context,
+	timings *ProtocolTimings,
 	watchBlocksFn func(ctx context.Context) <-chan uint64,
 	onWindowFn func(window *coordinationWindow),
 ) {
@@ -135,7 +134,7 @@ func watchCoordinationWindows(
 	for {
 		select {
 		case block := <-blocksChan:
-			if window := newCoordinationWindow(block); window.index() > 0 {
+			if window := newCoordinationWindow(block, timings); window.index() > 0 {
 				// Make sure the current window is not the same as the last one.
 				// There is no guarantee that the block channel will not emit
 				// the same block again.
@@ -204,6 +203,10 @@ type CoordinationProposalRequest struct {
 	WalletOperators     []chain.Address
 	ExecutingOperator   chain.Address
 	ActionsChecklist    []WalletActionType
+	// Observer is set only if the node operates in the observer mode. In that
+	// case, the generator must not submit any chain transactions and should
+	// record them using the observer instead.
+	Observer ObserverRecorder
 }
 
 // CoordinationProposalGenerator is a component responsible for generating
@@ -295,6 +298,11 @@ type coordinationExecutor struct {
 	protocolLatch       *generator.ProtocolLatch
 
 	waitForBlockFn waitForBlockFn
+
+	// observer is passed to the proposal generator so it records chain
+	// transactions instead of submitting them if the node operates in the
+	// observer mode. It is nil otherwise.
+	observer *observerRecorder
 }
 
 // newCoordinationExecutor creates a new coordination executor for the
@@ -374,7 +382,7 @@ func (ce *coordinationExecutor) coordinate(
 
 	execLogger.Infof("coordination leader is: [%s]", leader)
 
-	actionsChecklist := ce.getActionsChecklist(window.index(), seed)
+	actionsChecklist := ce.getActionsChecklist(window, seed)
 
 	execLogger.Infof("actions checklist is: [%v]", actionsChecklist)
 
@@ -527,9 +535,11 @@ func (ce *coordinationExecutor) getLeader(seed [32]byte) chain.Address {
 // for the given coordination window. Returns nil for incorrect coordination
 // windows whose index is 0.
 func (ce *coordinationExecutor) getActionsChecklist(
-	windowIndex uint64,
+	window *coordinationWindow,
 	seed [32]byte,
 ) []WalletActionType {
+	windowIndex := window.index()
+
 	// Return nil checklist for incorrect coordination windows.
 	if windowIndex == 0 {
 		return nil
@@ -537,14 +547,20 @@ func (ce *coordinationExecutor) getActionsChecklist(
 
 	var actions []WalletActionType
 
-	// Redemption action is a priority action and should be checked on every
-	// coordination window.
-	actions = append(actions, ActionRedemption)
-
 	// Other actions should be checked with a lower frequency. The default
 	// frequency is every 4 coordination windows.
 	frequencyWindows := uint64(4)
 
+	// Fee bump action goes first as the wallet cannot take any other
+	// Bitcoin action while its previous transaction is stuck in the mempool.
+	if windowIndex%frequencyWindows == 0 {
+		actions = append(actions, ActionFeeBump)
+	}
+
+	// Redemption action is a priority action and should be checked on every
+	// coordination window.
+	actions = append(actions, ActionRedemption)
+
 	if windowIndex%frequencyWindows == 0 {
 		actions = append(actions, ActionDepositSweep)
 	}
@@ -557,6 +573,14 @@ func (ce *coordinationExecutor) getActionsChecklist(
 		actions = append(actions, ActionMovingFunds)
 	}
 
+	// Key refresh does not move funds and is checked rarely, after all
+	// Bitcoin actions so it never delays them.
+	keyRefreshFrequencyWindows :=
+		window.timings.coordinationKeyRefreshFrequencyWindows()
+	if windowIndex%keyRefreshFrequencyWindows == 0 {
+		actions = append(actions, ActionKeyRefresh)
+	}
+
 	// #nosec G404 (insecure random number source (rand))
 	// Drawing a decision about heartbeat does not require secure randomness.
 	// Use first 8 bytes of the seed to initialize the RNG.
@@ -578,13 +602,20 @@ func (ce *coordinationExecutor) executeLeaderRoutine(
 ) (CoordinationProposal, error) {
 	walletPublicKeyHash := ce.walletPublicKeyHash()
 
+	request := &CoordinationProposalRequest{
+		WalletPublicKeyHash: walletPublicKeyHash,
+		WalletOperators:     ce.coordinatedWallet.signingGroupOperators,
+		ExecutingOperator:   ce.operatorAddress,
+		ActionsChecklist:    actionsChecklist,
+	}
+	// Assign the observer only if it is set to avoid a non-nil interface
+	// holding a nil pointer.
+	if ce.observer != nil {
+		request.Observer = ce.observer
+	}
+
 	proposal, err := ce.generateProposal(
-		&CoordinationProposalRequest{
-			WalletPublicKeyHash: walletPublicKeyHash,
-			WalletOperators:     ce.coordinatedWallet.signingGroupOperators,
-			ExecutingOperator:   ce.operatorAddress,
-			ActionsChecklist:    actionsChecklist,
-		},
+		request,
 		2,             // 2 attempts at most
 		1*time.Minute, // 1 minute between attempts
 	)
@@ -26,7 +26,7 @@ import (
 )
 
 func TestCoordinationWindow_ActivePhaseEndBlock(t *testing.T) {
-	window := newCoordinationWindow(900)
+	window := newCoordinationWindow(900, mainnetProtocolTimings())
 
 	testutils.AssertIntsEqual(
 		t,
@@ -37,7 +37,7 @@ func TestCoordinationWindow_ActivePhaseEndBlock(t *testing.T) {
 }
 
 func TestCoordinationWindow_EndBlock(t *testing.T) {
-	window := newCoordinationWindow(900)
+	window := newCoordinationWindow(900, mainnetProtocolTimings())
 
 	testutils.AssertIntsEqual(
 		t,
@@ -48,11 +48,11 @@ func TestCoordinationWindow_EndBlock(t *testing.T) {
 }
 
 func TestCoordinationWindow_IsAfter(t *testing.T) {
-	window := newCoordinationWindow(1800)
+	window := newCoordinationWindow(1800, mainnetProtocolTimings())
 
-	previousWindow := newCoordinationWindow(900)
-	sameWindow := newCoordinationWindow(1800)
-	nextWindow := newCoordinationWindow(2700)
+	previousWindow := newCoordinationWindow(900, mainnetProtocolTimings())
+	sameWindow := newCoordinationWindow(1800, mainnetProtocolTimings())
+	nextWindow := newCoordinationWindow(2700, mainnetProtocolTimings())
 
 	testutils.AssertBoolsEqual(
 		t,
@@ -109,7 +109,7 @@ func TestCoordinationWindow_Index(t *testing.T) {
 
 	for testName, test := range tests {
 		t.Run(testName, func(t *testing.T) {
-			window := newCoordinationWindow(test.coordinationBlock)
+			window := newCoordinationWindow(test.coordinationBlock, mainnetProtocolTimings())
 
 			testutils.AssertIntsEqual(
 				t,
@@ -156,7 +156,12 @@ func TestWatchCoordinationWindows(t *testing.T) {
 	)
 	defer cancelCtx()
 
-	go watchCoordinationWindows(ctx, watchBlocksFn, onWindowFn)
+	go watchCoordinationWindows(
+		ctx,
+		mainnetProtocolTimings(),
+		watchBlocksFn,
+		onWindowFn,
+	)
 
 	<-ctx.Done()
 
@@ -346,7 +351,7 @@ func TestCoordinationExecutor_Coordinate(t *testing.T) {
 		)
 	}
 
-	window := newCoordinationWindow(coordinationBlock)
+	window := newCoordinationWindow(coordinationBlock, mainnetProtocolTimings())
 
 	type report struct {
 		operatorIndex int
@@ -562,6 +567,7 @@ func TestCoordinationExecutor_GetActionsChecklist(t *testing.T) {
 		"block 3600": {
 			coordinationBlock: 3600,
 			expectedChecklist: []WalletActionType{
+				ActionFeeBump,
 				ActionRedemption,
 				ActionDepositSweep,
 				ActionMovedFundsSweep,
@@ -586,6 +592,7 @@ func TestCoordinationExecutor_GetActionsChecklist(t *testing.T) {
 		"block 7200": {
 			coordinationBlock: 7200,
 			expectedChecklist: []WalletActionType{
+				ActionFeeBump,
 				ActionRedemption,
 				ActionDepositSweep,
 				ActionMovedFundsSweep,
@@ -607,6 +614,7 @@ func TestCoordinationExecutor_GetActionsChecklist(t *testing.T) {
 		"block 10800": {
 			coordinationBlock: 10800,
 			expectedChecklist: []WalletActionType{
+				ActionFeeBump,
 				ActionRedemption,
 				ActionDepositSweep,
 				ActionMovedFundsSweep,
@@ -630,10 +638,23 @@ func TestCoordinationExecutor_GetActionsChecklist(t *testing.T) {
 		"block 14400": {
 			coordinationBlock: 14400,
 			expectedChecklist: []WalletActionType{
+				ActionFeeBump,
+				ActionRedemption,
+				ActionDepositSweep,
+				ActionMovedFundsSweep,
+				ActionMovingFunds,
+			},
+		},
+		// Key refresh checked in the 56th coordination window.
+		"block 50400": {
+			coordinationBlock: 50400,
+			expectedChecklist: []WalletActionType{
+				ActionFeeBump,
 				ActionRedemption,
 				ActionDepositSweep,
 				ActionMovedFundsSweep,
 				ActionMovingFunds,
+				ActionKeyRefresh,
 			},
 		},
 	}
@@ -643,14 +664,14 @@ func TestCoordinationExecutor_GetActionsChecklist(t *testing.T) {
 	for testName, test := range tests {
 		t.Run(
 			testName, func(t *testing.T) {
-				window := newCoordinationWindow(test.coordinationBlock)
+				window := newCoordinationWindow(test.coordinationBlock, mainnetProtocolTimings())
 
 				// Build an arbitrary seed based on the coordination block number.
 				seed := sha256.Sum256(
 					big.NewInt(int64(window.coordinationBlock) + 2).Bytes(),
 				)
 
-				checklist := executor.getActionsChecklist(window.index(), seed)
+				checklist := executor.getActionsChecklist(window, seed)
 
 				if diff := deep.Equal(
 					checklist,
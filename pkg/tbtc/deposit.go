@@ -1,6 +1,7 @@
 package tbtc
 
 import (
+	"bytes"
 	"encoding/hex"
 	"fmt"
 	"strings"
@@ -84,3 +85,55 @@ func (d *Deposit) Script() ([]byte, error) {
 
 	return hex.DecodeString(script)
 }
+
+const (
+	// depositScriptLength is the byte length of the deposit script without
+	// extra data.
+	depositScriptLength = 92
+	// depositExtraDataLength is the byte length of the extra data fragment
+	// of the deposit script, i.e. the extra data push followed by OP_DROP.
+	depositExtraDataLength = 34
+)
+
+// ParseDepositScript decodes the given deposit script, being the redeem
+// script of a deposit P2(W)SH output, back into the deposit parameters.
+// The returned Deposit has all fields set except Utxo and Vault that are not
+// part of the deposit script. An error is returned if the given script is
+// not a deposit script.
+func ParseDepositScript(script []byte) (*Deposit, error) {
+	var offset int
+	var extraData *[32]byte
+
+	switch len(script) {
+	case depositScriptLength:
+		offset = 0
+	case depositScriptLength + depositExtraDataLength:
+		offset = depositExtraDataLength
+		extraData = new([32]byte)
+		copy(extraData[:], script[23:55])
+	default:
+		return nil, fmt.Errorf("wrong byte length of deposit script")
+	}
+
+	deposit := &Deposit{
+		Depositor: chain.Address("0x" + hex.EncodeToString(script[1:21])),
+		ExtraData: extraData,
+	}
+	copy(deposit.BlindingFactor[:], script[offset+23:offset+31])
+	copy(deposit.WalletPublicKeyHash[:], script[offset+35:offset+55])
+	copy(deposit.RefundPublicKeyHash[:], script[offset+62:offset+82])
+	copy(deposit.RefundLocktime[:], script[offset+84:offset+88])
+
+	// Fields were extracted from fixed positions so, the opcodes around
+	// them must be verified. The simplest way is rebuilding the script.
+	expectedScript, err := deposit.Script()
+	if err != nil {
+		return nil, fmt.Errorf("cannot rebuild deposit script: [%v]", err)
+	}
+
+	if !bytes.Equal(expectedScript, script) {
+		return nil, fmt.Errorf("script is not a deposit script")
+	}
+
+	return deposit, nil
+}
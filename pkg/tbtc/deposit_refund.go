@@ -0,0 +1,201 @@
+package tbtc
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/rand"
+	"encoding/binary"
+	"fmt"
+
+	"github.com/keep-network/keep-core/pkg/bitcoin"
+)
+
+// depositRefundSequence is the sequence number of the input spending the
+// deposit UTXO through the refund path of the deposit script. The sequence
+// must be lower than the maximum value as otherwise the transaction's
+// locktime is not enforced and OP_CHECKLOCKTIMEVERIFY fails. It also
+// disables the relative locktime and BIP-125 replaceability signaling.
+const depositRefundSequence = 0xfffffffe
+
+// AssembleDepositRefundTransaction constructs an unsigned Bitcoin transaction
+// refunding the given deposit. The transaction spends the deposit UTXO
+// through the refund path of the deposit script and pays the deposit value,
+// reduced by the given fee, to the given output script. The transaction's
+// locktime is set to the deposit's refund locktime so the transaction cannot
+// be mined before the refund locktime passes.
+//
+// The deposit's Utxo field must point to an existing deposit funding output
+// and the deposit's remaining fields must match the ones used to build the
+// deposit script. The fee argument is not validated in any way.
+//
+// The resulting bitcoin.TransactionBuilder instance must be signed using
+// the refund key whose hash is the deposit's refund public key hash.
+func AssembleDepositRefundTransaction(
+	bitcoinChain bitcoin.Chain,
+	deposit *Deposit,
+	outputScript bitcoin.Script,
+	fee int64,
+) (*bitcoin.TransactionBuilder, error) {
+	depositScript, err := deposit.Script()
+	if err != nil {
+		return nil, fmt.Errorf("cannot get deposit script: [%v]", err)
+	}
+
+	fundingTransaction, err := bitcoinChain.GetTransaction(
+		deposit.Utxo.Outpoint.TransactionHash,
+	)
+	if err != nil {
+		return nil, fmt.Errorf(
+			"cannot get deposit funding transaction: [%v]",
+			err,
+		)
+	}
+
+	outputIndex := deposit.Utxo.Outpoint.OutputIndex
+	if int(outputIndex) >= len(fundingTransaction.Outputs) {
+		return nil, fmt.Errorf(
+			"deposit funding transaction has no output with index [%v]",
+			outputIndex,
+		)
+	}
+	fundingOutput := fundingTransaction.Outputs[outputIndex]
+
+	if fundingOutput.Value != deposit.Utxo.Value {
+		return nil, fmt.Errorf(
+			"deposit value [%v] does not match funding output value [%v]",
+			deposit.Utxo.Value,
+			fundingOutput.Value,
+		)
+	}
+
+	isDepositScript, err := isDepositScriptHash(
+		fundingOutput.PublicKeyScript,
+		depositScript,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if !isDepositScript {
+		return nil, fmt.Errorf(
+			"funding output is not locked using the deposit script; " +
+				"check the deposit parameters",
+		)
+	}
+
+	builder := bitcoin.NewTransactionBuilder(bitcoinChain)
+
+	err = builder.AddScriptHashInput(deposit.Utxo, depositScript)
+	if err != nil {
+		return nil, fmt.Errorf(
+			"cannot add input pointing to deposit UTXO: [%v]",
+			err,
+		)
+	}
+
+	err = builder.SetInputSequence(0, depositRefundSequence)
+	if err != nil {
+		return nil, fmt.Errorf("cannot set input sequence: [%v]", err)
+	}
+
+	// The refund locktime is pushed to the deposit script as a 4-byte
+	// little-endian number, the same way the transaction locktime is
+	// serialized.
+	builder.SetLocktime(binary.LittleEndian.Uint32(deposit.RefundLocktime[:]))
+
+	builder.AddOutput(&bitcoin.TransactionOutput{
+		Value:           builder.TotalInputsValue() - fee,
+		PublicKeyScript: outputScript,
+	})
+
+	return builder, nil
+}
+
+// BuildDepositRefundTransaction constructs a Bitcoin transaction refunding
+// the given deposit, as described by AssembleDepositRefundTransaction, and
+// signs it using the given refund private key. The deposit's refund public
+// key hash must be the hash of the refund private key's public key.
+// The returned transaction is ready to be broadcast once the deposit's
+// refund locktime passes.
+func BuildDepositRefundTransaction(
+	bitcoinChain bitcoin.Chain,
+	deposit *Deposit,
+	refundPrivateKey *ecdsa.PrivateKey,
+	outputScript bitcoin.Script,
+	fee int64,
+) (*bitcoin.Transaction, error) {
+	refundPublicKeyHash := bitcoin.PublicKeyHash(&refundPrivateKey.PublicKey)
+	if refundPublicKeyHash != deposit.RefundPublicKeyHash {
+		return nil, fmt.Errorf(
+			"refund key does not match deposit's refund public key hash",
+		)
+	}
+
+	builder, err := AssembleDepositRefundTransaction(
+		bitcoinChain,
+		deposit,
+		outputScript,
+		fee,
+	)
+	if err != nil {
+		return nil, fmt.Errorf(
+			"cannot assemble deposit refund transaction: [%v]",
+			err,
+		)
+	}
+
+	sigHashes, err := builder.ComputeSignatureHashes()
+	if err != nil {
+		return nil, fmt.Errorf(
+			"cannot compute deposit refund transaction sighashes: [%v]",
+			err,
+		)
+	}
+
+	signatures := make([]*bitcoin.SignatureContainer, len(sigHashes))
+	for i, sigHash := range sigHashes {
+		r, s, err := ecdsa.Sign(rand.Reader, refundPrivateKey, sigHash.Bytes())
+		if err != nil {
+			return nil, fmt.Errorf("cannot sign input [%v]: [%v]", i, err)
+		}
+
+		signatures[i] = &bitcoin.SignatureContainer{
+			R:         r,
+			S:         s,
+			PublicKey: &refundPrivateKey.PublicKey,
+		}
+	}
+
+	transaction, err := builder.AddSignatures(signatures)
+	if err != nil {
+		return nil, fmt.Errorf(
+			"cannot add signatures to deposit refund transaction: [%v]",
+			err,
+		)
+	}
+
+	return transaction, nil
+}
+
+// isDepositScriptHash checks whether the given locking script is a P2SH or
+// P2WSH script built upon the given deposit script.
+func isDepositScriptHash(
+	lockingScript bitcoin.Script,
+	depositScript bitcoin.Script,
+) (bool, error) {
+	witnessScriptHash, err := bitcoin.PayToWitnessScriptHash(
+		bitcoin.WitnessScriptHash(depositScript),
+	)
+	if err != nil {
+		return false, fmt.Errorf("cannot compute P2WSH script: [%v]", err)
+	}
+
+	scriptHash, err := bitcoin.PayToScriptHash(
+		bitcoin.ScriptHash(depositScript),
+	)
+	if err != nil {
+		return false, fmt.Errorf("cannot compute P2SH script: [%v]", err)
+	}
+
+	return bytes.Equal(lockingScript, witnessScriptHash) ||
+		bytes.Equal(lockingScript, scriptHash), nil
+}
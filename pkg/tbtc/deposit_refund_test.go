@@ -0,0 +1,282 @@
+package tbtc
+
+import (
+	"crypto/ecdsa"
+	"encoding/binary"
+	"testing"
+
+	"github.com/btcsuite/btcd/btcec"
+
+	"github.com/keep-network/keep-core/internal/testutils"
+	"github.com/keep-network/keep-core/pkg/bitcoin"
+	"github.com/keep-network/keep-core/pkg/bitcoin/simnet"
+	"github.com/keep-network/keep-core/pkg/chain"
+)
+
+func TestBuildDepositRefundTransaction(t *testing.T) {
+	for _, witness := range []bool{true, false} {
+		testName := "P2SH deposit"
+		if witness {
+			testName = "P2WSH deposit"
+		}
+
+		t.Run(testName, func(t *testing.T) {
+			bitcoinChain, err := simnet.New()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			refundPrivateKey := newDepositRefundTestKey(t)
+
+			tipHeader := latestBlockHeader(t, bitcoinChain)
+
+			// The refund locktime passes once six more blocks are mined
+			// on top of the current tip.
+			var refundLocktime [4]byte
+			binary.LittleEndian.PutUint32(
+				refundLocktime[:],
+				tipHeader.Time+6*600,
+			)
+
+			deposit := fundDeposit(
+				t,
+				bitcoinChain,
+				&refundPrivateKey.PublicKey,
+				refundLocktime,
+				witness,
+			)
+
+			outputScript, err := bitcoin.PayToWitnessPublicKeyHash(
+				deposit.RefundPublicKeyHash,
+			)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			transaction, err := BuildDepositRefundTransaction(
+				bitcoinChain,
+				deposit,
+				refundPrivateKey,
+				outputScript,
+				1000,
+			)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			testutils.AssertUintsEqual(
+				t,
+				"locktime",
+				uint64(binary.LittleEndian.Uint32(refundLocktime[:])),
+				uint64(transaction.Locktime),
+			)
+			testutils.AssertIntsEqual(t, "inputs count", 1, len(transaction.Inputs))
+			testutils.AssertUintsEqual(
+				t,
+				"sequence",
+				depositRefundSequence,
+				uint64(transaction.Inputs[0].Sequence),
+			)
+			testutils.AssertIntsEqual(t, "outputs count", 1, len(transaction.Outputs))
+			testutils.AssertIntsEqual(
+				t,
+				"output value",
+				int(deposit.Utxo.Value-1000),
+				int(transaction.Outputs[0].Value),
+			)
+			testutils.AssertBytesEqual(
+				t,
+				outputScript,
+				transaction.Outputs[0].PublicKeyScript,
+			)
+
+			err = bitcoinChain.BroadcastTransaction(transaction)
+			if err == nil {
+				t.Fatal("expected refund to be rejected before the locktime")
+			}
+
+			if err := bitcoinChain.MineBlocks(12); err != nil {
+				t.Fatal(err)
+			}
+
+			// The simulator executes input scripts upon broadcast so,
+			// a successful broadcast means the refund path of the deposit
+			// script is satisfied.
+			if err := bitcoinChain.BroadcastTransaction(transaction); err != nil {
+				t.Fatal(err)
+			}
+
+			if err := bitcoinChain.MineBlocks(1); err != nil {
+				t.Fatal(err)
+			}
+
+			confirmations, err := bitcoinChain.GetTransactionConfirmations(
+				transaction.Hash(),
+			)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			testutils.AssertUintsEqual(
+				t,
+				"confirmations",
+				1,
+				uint64(confirmations),
+			)
+		})
+	}
+}
+
+func TestBuildDepositRefundTransaction_WrongRefundKey(t *testing.T) {
+	bitcoinChain, err := simnet.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	refundPrivateKey := newDepositRefundTestKey(t)
+
+	deposit := fundDeposit(
+		t,
+		bitcoinChain,
+		&refundPrivateKey.PublicKey,
+		[4]byte{0x60, 0xbc, 0xea, 0x61},
+		true,
+	)
+
+	_, err = BuildDepositRefundTransaction(
+		bitcoinChain,
+		deposit,
+		newDepositRefundTestKey(t),
+		[]byte{0x51},
+		1000,
+	)
+	if err == nil {
+		t.Fatal("expected error")
+	}
+
+	testutils.AssertStringsEqual(
+		t,
+		"error",
+		"refund key does not match deposit's refund public key hash",
+		err.Error(),
+	)
+}
+
+func TestAssembleDepositRefundTransaction_WrongDepositParameters(t *testing.T) {
+	bitcoinChain, err := simnet.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	refundPrivateKey := newDepositRefundTestKey(t)
+
+	deposit := fundDeposit(
+		t,
+		bitcoinChain,
+		&refundPrivateKey.PublicKey,
+		[4]byte{0x60, 0xbc, 0xea, 0x61},
+		true,
+	)
+
+	deposit.BlindingFactor = [8]byte{0xff}
+
+	_, err = AssembleDepositRefundTransaction(
+		bitcoinChain,
+		deposit,
+		[]byte{0x51},
+		1000,
+	)
+	if err == nil {
+		t.Fatal("expected error")
+	}
+
+	testutils.AssertStringsEqual(
+		t,
+		"error",
+		"funding output is not locked using the deposit script; "+
+			"check the deposit parameters",
+		err.Error(),
+	)
+}
+
+func newDepositRefundTestKey(t *testing.T) *ecdsa.PrivateKey {
+	privateKey, err := btcec.NewPrivateKey(btcec.S256())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return privateKey.ToECDSA()
+}
+
+func latestBlockHeader(
+	t *testing.T,
+	bitcoinChain *simnet.Chain,
+) *bitcoin.BlockHeader {
+	height, err := bitcoinChain.GetLatestBlockHeight()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	header, err := bitcoinChain.GetBlockHeader(height)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return header
+}
+
+// fundDeposit funds and confirms a deposit refundable using the given
+// refund public key.
+func fundDeposit(
+	t *testing.T,
+	bitcoinChain *simnet.Chain,
+	refundPublicKey *ecdsa.PublicKey,
+	refundLocktime [4]byte,
+	witness bool,
+) *Deposit {
+	deposit := &Deposit{
+		Depositor:           chain.Address("934b98637ca318a4d6e7ca6ffd1690b8e77df637"),
+		BlindingFactor:      [8]byte{0xf9, 0xf0, 0xc9, 0x0d, 0x00, 0x03, 0x95, 0x23},
+		WalletPublicKeyHash: [20]byte{0x8d, 0xb5, 0x0e, 0xb5, 0x20, 0x63},
+		RefundPublicKeyHash: bitcoin.PublicKeyHash(refundPublicKey),
+		RefundLocktime:      refundLocktime,
+	}
+
+	depositScript, err := deposit.Script()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var lockingScript bitcoin.Script
+	if witness {
+		lockingScript, err = bitcoin.PayToWitnessScriptHash(
+			bitcoin.WitnessScriptHash(depositScript),
+		)
+	} else {
+		lockingScript, err = bitcoin.PayToScriptHash(
+			bitcoin.ScriptHash(depositScript),
+		)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fundingTransaction, err := bitcoinChain.Fund(lockingScript, 100000)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := bitcoinChain.MineBlocks(1); err != nil {
+		t.Fatal(err)
+	}
+
+	deposit.Utxo = &bitcoin.UnspentTransactionOutput{
+		Outpoint: &bitcoin.TransactionOutpoint{
+			TransactionHash: fundingTransaction.Hash(),
+			OutputIndex:     0,
+		},
+		Value: 100000,
+	}
+
+	return deposit
+}
@@ -1,7 +1,6 @@
 package tbtc
 
 import (
-	"crypto/ecdsa"
 	"fmt"
 	"math/big"
 	"time"
@@ -170,7 +169,7 @@ func (dsa *depositSweepAction) execute() error {
 
 	unsignedSweepTx, err := assembleDepositSweepTransaction(
 		dsa.btcChain,
-		dsa.wallet().publicKey,
+		walletPublicKeyHash,
 		walletMainUtxo,
 		validatedDeposits,
 		dsa.proposal.SweepTxFee.Int64(),
@@ -424,6 +423,61 @@ func (dsa *depositSweepAction) actionType() WalletActionType {
 	return ActionDepositSweep
 }
 
+// AssembleDepositSweepPSBT validates the given deposit sweep proposal and
+// assembles the unsigned deposit sweep transaction the wallet would sign
+// while executing the proposal. The transaction is returned as a BIP-0174
+// PSBT so it can be inspected using standard Bitcoin tooling.
+func AssembleDepositSweepPSBT(
+	validateProposalLogger log.StandardLogger,
+	walletPublicKeyHash [20]byte,
+	proposal *DepositSweepProposal,
+	chain interface {
+		BridgeChain
+		WalletProposalValidatorChain
+	},
+	btcChain bitcoin.Chain,
+) (*bitcoin.PSBT, error) {
+	validatedDeposits, err := ValidateDepositSweepProposal(
+		validateProposalLogger,
+		walletPublicKeyHash,
+		proposal,
+		DepositSweepRequiredFundingTxConfirmations,
+		chain,
+		btcChain,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("cannot validate proposal: [%v]", err)
+	}
+
+	walletMainUtxo, err := DetermineWalletMainUtxo(
+		walletPublicKeyHash,
+		chain,
+		btcChain,
+	)
+	if err != nil {
+		return nil, fmt.Errorf(
+			"error while determining wallet's main UTXO: [%v]",
+			err,
+		)
+	}
+
+	unsignedSweepTx, err := assembleDepositSweepTransaction(
+		btcChain,
+		walletPublicKeyHash,
+		walletMainUtxo,
+		validatedDeposits,
+		proposal.SweepTxFee.Int64(),
+	)
+	if err != nil {
+		return nil, fmt.Errorf(
+			"error while assembling deposit sweep transaction: [%v]",
+			err,
+		)
+	}
+
+	return unsignedSweepTx.ToPSBT()
+}
+
 // assembleDepositSweepTransaction constructs an unsigned deposit sweep Bitcoin
 // transaction.
 //
@@ -438,7 +492,7 @@ func (dsa *depositSweepAction) actionType() WalletActionType {
 // ready to be spread across the Bitcoin network.
 func assembleDepositSweepTransaction(
 	bitcoinChain bitcoin.Chain,
-	walletPublicKey *ecdsa.PublicKey,
+	walletPublicKeyHash [20]byte,
 	walletMainUtxo *bitcoin.UnspentTransactionOutput,
 	deposits []*Deposit,
 	fee int64,
@@ -479,7 +533,6 @@ func assembleDepositSweepTransaction(
 		}
 	}
 
-	walletPublicKeyHash := bitcoin.PublicKeyHash(walletPublicKey)
 	outputScript, err := bitcoin.PayToWitnessPublicKeyHash(walletPublicKeyHash)
 	if err != nil {
 		return nil, fmt.Errorf("cannot compute output script: [%v]", err)
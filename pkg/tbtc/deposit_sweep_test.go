@@ -270,7 +270,7 @@ func TestAssembleDepositSweepTransaction(t *testing.T) {
 
 			builder, err := assembleDepositSweepTransaction(
 				bitcoinChain,
-				scenario.WalletPublicKey,
+				bitcoin.PublicKeyHash(scenario.WalletPublicKey),
 				scenario.WalletMainUtxo,
 				deposits,
 				scenario.Fee,
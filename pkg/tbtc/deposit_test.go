@@ -2,6 +2,8 @@ package tbtc
 
 import (
 	"encoding/hex"
+	"fmt"
+	"reflect"
 	"testing"
 
 	"github.com/keep-network/keep-core/pkg/chain"
@@ -82,3 +84,121 @@ func TestDeposit_Script(t *testing.T) {
 		})
 	}
 }
+
+func TestParseDepositScript(t *testing.T) {
+	var tests = map[string]struct {
+		script                      string
+		expectedDepositor           string
+		expectedBlindingFactor      string
+		expectedWalletPublicKeyHash string
+		expectedRefundPublicKeyHash string
+		expectedRefundLocktime      string
+		expectedExtraData           string
+		expectedErr                 error
+	}{
+		"no extra data": {
+			script: "14934b98637ca318a4d6e7ca6ffd1690b8e77df637750" +
+				"8f9f0c90d000395237576a9148db50eb52063ea9d98b3eac91489a90f" +
+				"738986f68763ac6776a91428e081f285138ccbe389c1eb89857162301" +
+				"29f89880460bcea61b175ac68",
+			expectedDepositor:           "0x934b98637ca318a4d6e7ca6ffd1690b8e77df637",
+			expectedBlindingFactor:      "f9f0c90d00039523",
+			expectedWalletPublicKeyHash: "8db50eb52063ea9d98b3eac91489a90f738986f6",
+			expectedRefundPublicKeyHash: "28e081f285138ccbe389c1eb8985716230129f89",
+			expectedRefundLocktime:      "60bcea61",
+		},
+		"with extra data": {
+			script: "14934b98637ca318a4d6e7ca6ffd1690b8e77df637752" +
+				"0a9b38ea6435c8941d6eda6a46b68e3e2117196995bd154ab55196396" +
+				"b03d9bda7508f9f0c90d000395237576a9148db50eb52063ea9d98b3e" +
+				"ac91489a90f738986f68763ac6776a91428e081f285138ccbe389c1eb" +
+				"8985716230129f89880460bcea61b175ac68",
+			expectedDepositor:           "0x934b98637ca318a4d6e7ca6ffd1690b8e77df637",
+			expectedBlindingFactor:      "f9f0c90d00039523",
+			expectedWalletPublicKeyHash: "8db50eb52063ea9d98b3eac91489a90f738986f6",
+			expectedRefundPublicKeyHash: "28e081f285138ccbe389c1eb8985716230129f89",
+			expectedRefundLocktime:      "60bcea61",
+			expectedExtraData: "a9b38ea6435c8941d6eda6a46b68e3e2117196995bd154ab55" +
+				"196396b03d9bda",
+		},
+		"wrong length": {
+			script:      "0014751e76e8199196d454941c45d1b3a323f1433bd6",
+			expectedErr: fmt.Errorf("wrong byte length of deposit script"),
+		},
+		"wrong opcode": {
+			script: "14934b98637ca318a4d6e7ca6ffd1690b8e77df637750" +
+				"8f9f0c90d000395237576a9148db50eb52063ea9d98b3eac91489a90f" +
+				"738986f68763ac6776a91428e081f285138ccbe389c1eb89857162301" +
+				"29f89880460bcea61b275ac68",
+			expectedErr: fmt.Errorf("script is not a deposit script"),
+		},
+	}
+
+	for testName, test := range tests {
+		t.Run(testName, func(t *testing.T) {
+			script, err := hex.DecodeString(test.script)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			deposit, err := ParseDepositScript(script)
+			if !reflect.DeepEqual(test.expectedErr, err) {
+				t.Fatalf(
+					"unexpected error\nexpected: %v\nactual:   %v",
+					test.expectedErr,
+					err,
+				)
+			}
+
+			if test.expectedErr != nil {
+				return
+			}
+
+			testutils.AssertStringsEqual(
+				t,
+				"depositor",
+				test.expectedDepositor,
+				deposit.Depositor.String(),
+			)
+			testutils.AssertStringsEqual(
+				t,
+				"blinding factor",
+				test.expectedBlindingFactor,
+				hex.EncodeToString(deposit.BlindingFactor[:]),
+			)
+			testutils.AssertStringsEqual(
+				t,
+				"wallet public key hash",
+				test.expectedWalletPublicKeyHash,
+				hex.EncodeToString(deposit.WalletPublicKeyHash[:]),
+			)
+			testutils.AssertStringsEqual(
+				t,
+				"refund public key hash",
+				test.expectedRefundPublicKeyHash,
+				hex.EncodeToString(deposit.RefundPublicKeyHash[:]),
+			)
+			testutils.AssertStringsEqual(
+				t,
+				"refund locktime",
+				test.expectedRefundLocktime,
+				hex.EncodeToString(deposit.RefundLocktime[:]),
+			)
+
+			if len(test.expectedExtraData) > 0 {
+				if deposit.ExtraData == nil {
+					t.Fatal("expected extra data")
+				}
+
+				testutils.AssertStringsEqual(
+					t,
+					"extra data",
+					test.expectedExtraData,
+					hex.EncodeToString(deposit.ExtraData[:]),
+				)
+			} else if deposit.ExtraData != nil {
+				t.Errorf("unexpected extra data")
+			}
+		})
+	}
+}
@@ -21,15 +21,6 @@ import (
 )
 
 const (
-	// dkgStartedConfirmationBlocks determines the block length of the
-	// confirmation period that is preserved after a DKG start. Once the period
-	// elapses, the DKG state is checked to confirm the protocol can be started.
-	dkgStartedConfirmationBlocks = 20
-	// dkgResultSubmissionDelayStep determines the delay step in blocks that
-	// is used to calculate the submission delay period that should be respected
-	// by the given member to avoid all members submitting the same DKG result
-	// at the same time.
-	dkgResultSubmissionDelayStepBlocks = 3
 	// dkgResultApprovalDelayStepBlocks determines the delay step in blocks
 	// that is used to calculate the approval delay period that should be
 	// respected by the given member to avoid all members approving the same
@@ -51,6 +42,7 @@ const (
 // group, executing off-chain protocol, and publishing the result to the chain.
 type dkgExecutor struct {
 	groupParameters *GroupParameters
+	timings         *ProtocolTimings
 
 	operatorIDFn    func() (chain.OperatorID, error)
 	operatorAddress chain.Address
@@ -64,12 +56,18 @@ type dkgExecutor struct {
 	waitForBlockFn waitForBlockFn
 
 	tecdsaExecutor *dkg.Executor
+
+	// observer records DKG result submissions, challenges, and approvals
+	// instead of executing them if the node operates in the observer mode.
+	// It is nil otherwise.
+	observer *observerRecorder
 }
 
 // newDkgExecutor creates a new instance of dkgExecutor struct. There should
 // be only one instance of dkgExecutor.
 func newDkgExecutor(
 	groupParameters *GroupParameters,
+	timings *ProtocolTimings,
 	operatorIDFn func() (chain.OperatorID, error),
 	operatorAddress chain.Address,
 	chain Chain,
@@ -94,6 +92,7 @@ func newDkgExecutor(
 
 	return &dkgExecutor{
 		groupParameters: groupParameters,
+		timings:         timings,
 		operatorIDFn:    operatorIDFn,
 		operatorAddress: operatorAddress,
 		chain:           chain,
@@ -326,6 +325,7 @@ func (de *dkgExecutor) generateSigningGroup(
 				memberIndex,
 				groupSelectionResult.OperatorsAddresses,
 				de.groupParameters,
+				de.timings,
 				announcer,
 				dkgAttemptsLimit,
 			)
@@ -527,17 +527,30 @@ func (de *dkgExecutor) publishDkgResult(
 		broadcastChannel,
 		membershipValidator,
 		newDkgResultSigner(de.chain, startBlock),
-		newDkgResultSubmitter(
-			dkgLogger,
-			de.chain,
-			de.groupParameters,
-			groupSelectionResult,
-			de.waitForBlockFn,
-		),
+		de.newDkgResultSubmitter(dkgLogger, groupSelectionResult),
 		dkgResult,
 	)
 }
 
+// newDkgResultSubmitter creates a DKG result submitter wired with
+// the executor's components.
+func (de *dkgExecutor) newDkgResultSubmitter(
+	dkgLogger log.StandardLogger,
+	groupSelectionResult *GroupSelectionResult,
+) *dkgResultSubmitter {
+	submitter := newDkgResultSubmitter(
+		dkgLogger,
+		de.chain,
+		de.groupParameters,
+		de.timings,
+		groupSelectionResult,
+		de.waitForBlockFn,
+	)
+	submitter.observer = de.observer
+
+	return submitter
+}
+
 // executeDkgValidation performs the submitted DKG result validation process.
 // If the result is not valid, this function submits an on-chain result
 // challenge. If the result is valid and the given node was involved in the DKG,
@@ -566,6 +579,18 @@ func (de *dkgExecutor) executeDkgValidation(
 	if !isValid {
 		dkgLogger.Infof("DKG result is invalid")
 
+		if de.observer != nil {
+			de.observer.record(
+				ObserverRecordDKGResultChallenge,
+				map[string]interface{}{
+					"groupPublicKey": fmt.Sprintf("0x%x", result.GroupPublicKey),
+					"resultHash":     fmt.Sprintf("0x%x", resultHash),
+				},
+			)
+			dkgLogger.Infof("observer mode; not challenging DKG result")
+			return
+		}
+
 		i := uint64(0)
 
 		// Challenges are done along with DKG state confirmations. This is
@@ -722,6 +747,22 @@ func (de *dkgExecutor) executeDkgValidation(
 				return
 			}
 
+			if de.observer != nil {
+				de.observer.record(
+					ObserverRecordDKGResultApproval,
+					map[string]interface{}{
+						"memberIndex":    memberIndex,
+						"groupPublicKey": fmt.Sprintf("0x%x", result.GroupPublicKey),
+						"resultHash":     fmt.Sprintf("0x%x", resultHash),
+					},
+				)
+				dkgLogger.Infof(
+					"[member:%v] observer mode; not approving DKG result",
+					memberIndex,
+				)
+				return
+			}
+
 			err = de.chain.ApproveDKGResult(result)
 			if err != nil {
 				dkgLogger.Errorf(
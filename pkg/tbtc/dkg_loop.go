@@ -25,23 +25,8 @@ const (
 	// announcement phase that is performed at the beginning of each DKG
 	// attempt.
 	dkgAttemptAnnouncementActiveBlocks = 10
-	// dkgAttemptProtocolBlocks determines the maximum block duration of the
-	// actual protocol computations.
-	dkgAttemptMaximumProtocolBlocks = 200
-	// dkgAttemptCoolDownBlocks determines the duration of the cool down
-	// period that is preserved between subsequent DKG attempts.
-	dkgAttemptCoolDownBlocks = 5
 )
 
-// dkgAttemptMaximumBlocks returns the maximum block duration of a single
-// DKG attempt.
-func dkgAttemptMaximumBlocks() uint {
-	return dkgAttemptAnnouncementDelayBlocks +
-		dkgAttemptAnnouncementActiveBlocks +
-		dkgAttemptMaximumProtocolBlocks +
-		dkgAttemptCoolDownBlocks
-}
-
 // dkgAnnouncer represents a component responsible for exchanging readiness
 // announcements for the given DKG attempt for the given seed.
 type dkgAnnouncer interface {
@@ -64,6 +49,7 @@ type dkgRetryLoop struct {
 	selectedOperators chain.Addresses
 
 	groupParameters *GroupParameters
+	timings         *ProtocolTimings
 
 	announcer dkgAnnouncer
 
@@ -84,6 +70,7 @@ func newDkgRetryLoop(
 	memberIndex group.MemberIndex,
 	selectedOperators chain.Addresses,
 	groupParameters *GroupParameters,
+	timings *ProtocolTimings,
 	announcer dkgAnnouncer,
 	attemptsLimit uint,
 ) *dkgRetryLoop {
@@ -100,6 +87,7 @@ func newDkgRetryLoop(
 		memberIndex:        memberIndex,
 		selectedOperators:  selectedOperators,
 		groupParameters:    groupParameters,
+		timings:            timings,
 		announcer:          announcer,
 		attemptCounter:     0,
 		attemptStartBlock:  initialStartBlock,
@@ -148,14 +136,14 @@ func (drl *dkgRetryLoop) start(
 		// by some additional delay blocks. We need a small cool down in
 		// order to mitigate all corner cases where the actual attempt duration
 		// was slightly longer than the expected duration determined by the
-		// dkgAttemptMaximumProtocolBlocks constant.
+		// DKGAttemptMaximumProtocolBlocks timing.
 		//
 		// For example, the attempt may fail at the end of the protocol but the
 		// error is returned after some time and more blocks than expected are
 		// mined in the meantime.
 		if drl.attemptCounter > 1 {
 			drl.attemptStartBlock = drl.attemptStartBlock +
-				uint64(dkgAttemptMaximumBlocks())
+				uint64(drl.timings.dkgAttemptMaximumBlocks())
 		}
 
 		announcementStartBlock := drl.attemptStartBlock + dkgAttemptAnnouncementDelayBlocks
@@ -260,7 +248,8 @@ func (drl *dkgRetryLoop) start(
 			drl.memberIndex,
 		)
 
-		timeoutBlock := announcementEndBlock + dkgAttemptMaximumProtocolBlocks
+		timeoutBlock := announcementEndBlock +
+			drl.timings.DKGAttemptMaximumProtocolBlocks
 
 		var result *dkg.Result
 		var attemptErr error
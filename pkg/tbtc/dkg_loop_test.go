@@ -279,6 +279,7 @@ func TestDkgRetryLoop(t *testing.T) {
 				test.memberIndex,
 				selectedOperators,
 				groupParameters,
+				mainnetProtocolTimings(),
 				announcer,
 				test.attemptsLimit,
 			)
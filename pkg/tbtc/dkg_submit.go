@@ -80,15 +80,21 @@ type dkgResultSubmitter struct {
 
 	chain                Chain
 	groupParameters      *GroupParameters
+	timings              *ProtocolTimings
 	groupSelectionResult *GroupSelectionResult
 
 	waitForBlockFn waitForBlockFn
+
+	// observer records the DKG result instead of submitting it if the node
+	// operates in the observer mode. It is nil otherwise.
+	observer *observerRecorder
 }
 
 func newDkgResultSubmitter(
 	dkgLogger log.StandardLogger,
 	chain Chain,
 	groupParameters *GroupParameters,
+	timings *ProtocolTimings,
 	groupSelectionResult *GroupSelectionResult,
 	waitForBlockFn waitForBlockFn,
 ) *dkgResultSubmitter {
@@ -97,6 +103,7 @@ func newDkgResultSubmitter(
 		chain:                chain,
 		groupSelectionResult: groupSelectionResult,
 		groupParameters:      groupParameters,
+		timings:              timings,
 		waitForBlockFn:       waitForBlockFn,
 	}
 }
@@ -174,7 +181,8 @@ func (drs *dkgResultSubmitter) SubmitResult(
 	if err != nil {
 		return fmt.Errorf("cannot get current block: [%v]", err)
 	}
-	delayBlocks := uint64(memberIndex-1) * dkgResultSubmissionDelayStepBlocks
+	delayBlocks := uint64(memberIndex-1) *
+		drs.timings.DKGResultSubmissionDelayStepBlocks
 	submissionBlock := currentBlock + delayBlocks
 
 	drs.dkgLogger.Infof(
@@ -203,6 +211,23 @@ func (drs *dkgResultSubmitter) SubmitResult(
 		return nil
 	}
 
+	if drs.observer != nil {
+		drs.observer.record(
+			ObserverRecordDKGResultSubmission,
+			map[string]interface{}{
+				"memberIndex":              memberIndex,
+				"groupPublicKey":           fmt.Sprintf("0x%x", dkgResult.GroupPublicKey),
+				"misbehavedMembersIndexes": dkgResult.MisbehavedMembersIndexes,
+				"signaturesCount":          len(signatures),
+			},
+		)
+		drs.dkgLogger.Infof(
+			"[member:%v] observer mode; not submitting DKG result",
+			memberIndex,
+		)
+		return nil
+	}
+
 	drs.dkgLogger.Infof(
 		"[member:%v] submitting DKG result with [%v] supporting "+
 			"member signatures",
@@ -271,6 +271,7 @@ func TestSubmitResult_MemberSubmitsResult(t *testing.T) {
 		&testutils.MockLogger{},
 		localChain,
 		groupParameters,
+		mainnetProtocolTimings(),
 		groupSelectionResult,
 		testWaitForBlockFn(localChain),
 	)
@@ -323,6 +324,107 @@ func TestSubmitResult_MemberSubmitsResult(t *testing.T) {
 	}
 }
 
+func TestSubmitResult_ObserverMode(t *testing.T) {
+	groupParameters := &GroupParameters{
+		GroupSize:       5,
+		GroupQuorum:     4,
+		HonestThreshold: 3,
+	}
+
+	localChain := Connect()
+
+	err := localChain.startDKG()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	operatorAddress, err := localChain.operatorAddress()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	operatorID, err := localChain.GetOperatorID(operatorAddress)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var operatorsIDs chain.OperatorIDs
+	var operatorsAddresses chain.Addresses
+
+	for memberIndex := uint8(1); int(memberIndex) <= groupParameters.GroupSize; memberIndex++ {
+		operatorsIDs = append(operatorsIDs, operatorID)
+		operatorsAddresses = append(operatorsAddresses, operatorAddress)
+	}
+
+	groupSelectionResult := &GroupSelectionResult{
+		OperatorsIDs:       operatorsIDs,
+		OperatorsAddresses: operatorsAddresses,
+	}
+
+	dkgResultSubmitter := newDkgResultSubmitter(
+		&testutils.MockLogger{},
+		localChain,
+		groupParameters,
+		mainnetProtocolTimings(),
+		groupSelectionResult,
+		testWaitForBlockFn(localChain),
+	)
+
+	persistenceHandle := &mockPersistenceHandle{}
+	dkgResultSubmitter.observer = newObserverRecorder(persistenceHandle)
+
+	testData, err := tecdsatest.LoadPrivateKeyShareTestFixtures(1)
+	if err != nil {
+		t.Fatalf("failed to load test data: [%v]", err)
+	}
+	result := &dkg.Result{
+		Group:           group.NewGroup(groupParameters.DishonestThreshold(), groupParameters.GroupSize),
+		PrivateKeyShare: tecdsa.NewPrivateKeyShare(testData[0]),
+	}
+
+	memberIndex := group.MemberIndex(1)
+	signatures := map[group.MemberIndex][]byte{
+		1: []byte("signature 1"),
+		2: []byte("signature 2"),
+		3: []byte("signature 3"),
+		4: []byte("signature 4"),
+	}
+
+	ctx, cancelCtx := context.WithCancel(context.Background())
+	defer cancelCtx()
+
+	if err = localChain.setDKGResultValidity(true); err != nil {
+		t.Fatal(err)
+	}
+
+	err = dkgResultSubmitter.SubmitResult(
+		ctx,
+		memberIndex,
+		result,
+		signatures,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if localChain.dkgResult != nil {
+		t.Errorf("DKG result should not be submitted in the observer mode")
+	}
+
+	testutils.AssertIntsEqual(
+		t,
+		"saved records",
+		1,
+		len(persistenceHandle.saved),
+	)
+	testutils.AssertStringsEqual(
+		t,
+		"record kind",
+		ObserverRecordDKGResultSubmission,
+		dkgResultSubmitter.observer.latestRecords[0].Kind,
+	)
+}
+
 func TestSubmitResult_AnotherMemberSubmitsResult(t *testing.T) {
 	groupParameters := &GroupParameters{
 		GroupSize:       5,
@@ -364,6 +466,7 @@ func TestSubmitResult_AnotherMemberSubmitsResult(t *testing.T) {
 		&testutils.MockLogger{},
 		localChain,
 		groupParameters,
+		mainnetProtocolTimings(),
 		groupSelectionResult,
 		testWaitForBlockFn(localChain),
 	)
@@ -498,6 +601,7 @@ func TestSubmitResult_InvalidResult(t *testing.T) {
 		&testutils.MockLogger{},
 		localChain,
 		groupParameters,
+		mainnetProtocolTimings(),
 		groupSelectionResult,
 		testWaitForBlockFn(localChain),
 	)
@@ -584,6 +688,7 @@ func TestSubmitResult_ContextCancelled(t *testing.T) {
 		&testutils.MockLogger{},
 		localChain,
 		groupParameters,
+		mainnetProtocolTimings(),
 		groupSelectionResult,
 		testWaitForBlockFn(localChain),
 	)
@@ -666,6 +771,7 @@ func TestSubmitResult_TooFewSignatures(t *testing.T) {
 		&testutils.MockLogger{},
 		localChain,
 		groupParameters,
+		mainnetProtocolTimings(),
 		groupSelectionResult,
 		testWaitForBlockFn(localChain),
 	)
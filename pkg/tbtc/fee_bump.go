@@ -0,0 +1,1007 @@
+package tbtc
+
+import (
+	"bytes"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/ipfs/go-log/v2"
+	"go.uber.org/zap"
+
+	"github.com/keep-network/keep-core/pkg/bitcoin"
+)
+
+const (
+	// feeBumpProposalValidityBlocks determines the fee bump proposal validity
+	// time expressed in blocks. In other words, this is the worst-case time
+	// for a fee bump during which the wallet is busy and cannot take another
+	// actions. The value of 600 blocks is roughly 2 hours, assuming 12 seconds
+	// per block.
+	feeBumpProposalValidityBlocks = 600
+	// feeBumpSigningTimeoutSafetyMarginBlocks determines the duration of the
+	// safety margin that must be preserved between the signing timeout and
+	// the timeout of the entire fee bump action. This safety margin prevents
+	// against the case where signing completes late and there is not enough
+	// time to broadcast the replacement transaction properly. In such a case,
+	// wallet signatures may leak and make the wallet subject of fraud
+	// accusations. Usage of the safety margin ensures there is enough time to
+	// perform post-signing steps of the fee bump action. The value of 300
+	// blocks is roughly 1 hour, assuming 12 seconds per block.
+	feeBumpSigningTimeoutSafetyMarginBlocks = 300
+	// feeBumpBroadcastTimeout determines the time window for replacement
+	// transaction broadcast. It is guaranteed that at least
+	// feeBumpSigningTimeoutSafetyMarginBlocks is preserved for the broadcast
+	// step. However, the happy path for the broadcast step is usually quick
+	// and few retries are needed to recover from temporary problems. That
+	// said, if the broadcast step does not succeed in a tight timeframe,
+	// there is no point to retry for the entire possible time window.
+	// Hence, the timeout for broadcast step is set as 25% of the entire
+	// time widow determined by feeBumpSigningTimeoutSafetyMarginBlocks.
+	feeBumpBroadcastTimeout = 15 * time.Minute
+	// feeBumpBroadcastCheckDelay determines the delay that must be preserved
+	// between transaction broadcast and the check that ensures the
+	// transaction is known on the Bitcoin chain. This delay is needed as
+	// spreading the transaction over the Bitcoin network takes time.
+	feeBumpBroadcastCheckDelay = 1 * time.Minute
+	// FeeBumpMinRelayFeeIncrement is the minimum fee rate, in satoshi per
+	// virtual byte, the replacement transaction must pay on top of the fee
+	// of the replaced transaction in order to be relayed by the Bitcoin
+	// network. It matches the default incremental relay fee of Bitcoin Core.
+	FeeBumpMinRelayFeeIncrement = 1
+)
+
+// FeeBumpProposal represents a proposal to replace a wallet transaction
+// stuck in the Bitcoin mempool with a transaction paying a higher fee,
+// issued by a wallet's coordination leader.
+//
+// The replacement transaction spends the same inputs and pays the same
+// recipients as the stuck transaction so, whichever of them gets confirmed,
+// it can be proven to the Bridge in the same way. Wallet transactions do not
+// signal BIP-125 replaceability so the replacement relies on the full
+// replace-by-fee policy of the Bitcoin network. Child-pays-for-parent is not
+// used as the child transaction would be a wallet's self-transfer that
+// cannot be proven to the Bridge.
+type FeeBumpProposal struct {
+	TransactionHash bitcoin.Hash
+	TxFee           *big.Int
+}
+
+func (fbp *FeeBumpProposal) ActionType() WalletActionType {
+	return ActionFeeBump
+}
+
+func (fbp *FeeBumpProposal) ValidityBlocks() uint64 {
+	return feeBumpProposalValidityBlocks
+}
+
+// feeBumpAction is a fee bump walletAction.
+type feeBumpAction struct {
+	logger   *zap.SugaredLogger
+	chain    Chain
+	btcChain bitcoin.Chain
+
+	feeBumpingWallet    wallet
+	transactionExecutor *walletTransactionExecutor
+
+	proposal                     *FeeBumpProposal
+	proposalProcessingStartBlock uint64
+	proposalExpiryBlock          uint64
+
+	// minPendingBlocks is the minimum number of Bitcoin blocks the replaced
+	// transaction must remain unconfirmed, since it was signed by the
+	// wallet, before its fee can be bumped.
+	minPendingBlocks uint
+
+	signingTimeoutSafetyMarginBlocks uint64
+	broadcastTimeout                 time.Duration
+	broadcastCheckDelay              time.Duration
+}
+
+func newFeeBumpAction(
+	logger *zap.SugaredLogger,
+	chain Chain,
+	btcChain bitcoin.Chain,
+	feeBumpingWallet wallet,
+	signingExecutor walletSigningExecutor,
+	proposal *FeeBumpProposal,
+	minPendingBlocks uint,
+	proposalProcessingStartBlock uint64,
+	proposalExpiryBlock uint64,
+	waitForBlockFn waitForBlockFn,
+) *feeBumpAction {
+	transactionExecutor := newWalletTransactionExecutor(
+		btcChain,
+		feeBumpingWallet,
+		signingExecutor,
+		waitForBlockFn,
+	)
+
+	return &feeBumpAction{
+		logger:                           logger,
+		chain:                            chain,
+		btcChain:                         btcChain,
+		feeBumpingWallet:                 feeBumpingWallet,
+		transactionExecutor:              transactionExecutor,
+		proposal:                         proposal,
+		minPendingBlocks:                 minPendingBlocks,
+		proposalProcessingStartBlock:     proposalProcessingStartBlock,
+		proposalExpiryBlock:              proposalExpiryBlock,
+		signingTimeoutSafetyMarginBlocks: feeBumpSigningTimeoutSafetyMarginBlocks,
+		broadcastTimeout:                 feeBumpBroadcastTimeout,
+		broadcastCheckDelay:              feeBumpBroadcastCheckDelay,
+	}
+}
+
+func (fba *feeBumpAction) execute() error {
+	validateProposalLogger := fba.logger.With(
+		zap.String("step", "validateProposal"),
+	)
+
+	walletPublicKeyHash := bitcoin.PublicKeyHash(fba.wallet().publicKey)
+
+	// The wallet is not synced between chains by definition as its latest
+	// transaction is still in the mempool so, unlike other actions, there
+	// is no point to ensure the wallet is synced here. The validation makes
+	// sure the replaced transaction is produced by the wallet instead.
+	pendingTx, err := validateFeeBumpProposal(
+		validateProposalLogger,
+		walletPublicKeyHash,
+		fba.proposal,
+		fba.chain,
+		fba.btcChain,
+	)
+	if err != nil {
+		return fmt.Errorf("validate proposal step failed: [%v]", err)
+	}
+
+	// The coordination leader proposes a fee bump only if the transaction
+	// is pending long enough but this cannot be taken for granted.
+	err = validateFeeBumpPendingAge(
+		fba.transactionExecutor.pendingTransactions,
+		fba.proposal.TransactionHash,
+		fba.minPendingBlocks,
+	)
+	if err != nil {
+		return fmt.Errorf("validate proposal step failed: [%v]", err)
+	}
+
+	unsignedReplacementTx, err := assembleFeeBumpTransaction(
+		fba.btcChain,
+		walletPublicKeyHash,
+		pendingTx,
+		fba.proposal.TxFee.Int64(),
+	)
+	if err != nil {
+		return fmt.Errorf(
+			"error while assembling replacement transaction: [%v]",
+			err,
+		)
+	}
+
+	signTxLogger := fba.logger.With(
+		zap.String("step", "signTransaction"),
+	)
+
+	// Just in case. This should never happen.
+	if fba.proposalExpiryBlock < fba.signingTimeoutSafetyMarginBlocks {
+		return fmt.Errorf("invalid proposal expiry block")
+	}
+
+	replacementTx, err := fba.transactionExecutor.signTransaction(
+		signTxLogger,
+		unsignedReplacementTx,
+		fba.proposalProcessingStartBlock,
+		fba.proposalExpiryBlock-fba.signingTimeoutSafetyMarginBlocks,
+	)
+	if err != nil {
+		return fmt.Errorf("sign transaction step failed: [%v]", err)
+	}
+
+	broadcastTxLogger := fba.logger.With(
+		zap.String("step", "broadcastTransaction"),
+		zap.String(
+			"replacementTxHash",
+			replacementTx.Hash().Hex(bitcoin.ReversedByteOrder),
+		),
+	)
+
+	err = fba.transactionExecutor.broadcastTransaction(
+		broadcastTxLogger,
+		replacementTx,
+		fba.broadcastTimeout,
+		fba.broadcastCheckDelay,
+	)
+	if err != nil {
+		return fmt.Errorf("broadcast transaction step failed: [%v]", err)
+	}
+
+	return nil
+}
+
+func (fba *feeBumpAction) wallet() wallet {
+	return fba.feeBumpingWallet
+}
+
+func (fba *feeBumpAction) actionType() WalletActionType {
+	return ActionFeeBump
+}
+
+// pendingWalletTransaction represents a wallet transaction awaiting
+// confirmation in the Bitcoin mempool, along with the data necessary to
+// assemble its replacement.
+type pendingWalletTransaction struct {
+	transaction *bitcoin.Transaction
+	// kind is the type of the wallet action that produced the transaction.
+	kind WalletActionType
+	// inputs holds UTXOs spent by the transaction, in the order of
+	// transaction inputs.
+	inputs []*bitcoin.UnspentTransactionOutput
+	// inputScripts holds locking scripts of UTXOs spent by the transaction,
+	// in the order of transaction inputs.
+	inputScripts []bitcoin.Script
+	// walletMainUtxo is the wallet main UTXO registered in the Bridge.
+	// It is nil if the wallet does not have a main UTXO yet.
+	walletMainUtxo *bitcoin.UnspentTransactionOutput
+	// redemptionRequests holds redemption requests handled by the
+	// transaction, in the order of redemption outputs. Set only for
+	// redemption transactions.
+	redemptionRequests []*RedemptionRequest
+	// fee is the fee currently paid by the transaction.
+	fee int64
+	// minFee is the minimum fee the replacement transaction must pay to be
+	// relayed by the Bitcoin network.
+	minFee int64
+	// maxFee is the maximum fee the transaction can pay according to
+	// the Bridge rules.
+	maxFee int64
+}
+
+// FindPendingWalletTransaction finds the wallet transaction that awaits
+// confirmation in the Bitcoin mempool. If the wallet has a main UTXO,
+// this is the mempool transaction spending it. Otherwise, this is the
+// wallet's first transaction, i.e. a mempool transaction sweeping revealed
+// deposits or moved funds to the wallet. Returns nil if there is no such
+// transaction.
+//
+// The lookup relies on the Bitcoin chain reporting mempool transactions
+// spending from the wallet's public key hash scripts, as the most widespread
+// Bitcoin chain backends do.
+func FindPendingWalletTransaction(
+	walletPublicKeyHash [20]byte,
+	walletMainUtxo *bitcoin.UnspentTransactionOutput,
+	bridgeChain BridgeChain,
+	btcChain bitcoin.Chain,
+) (*bitcoin.Transaction, error) {
+	transactions, err := btcChain.GetMempoolForPublicKeyHash(
+		walletPublicKeyHash,
+	)
+	if err != nil {
+		return nil, fmt.Errorf(
+			"cannot get mempool transactions for wallet: [%v]",
+			err,
+		)
+	}
+
+	for _, transaction := range transactions {
+		if walletMainUtxo != nil {
+			for _, input := range transaction.Inputs {
+				if input.Outpoint.TransactionHash ==
+					walletMainUtxo.Outpoint.TransactionHash &&
+					input.Outpoint.OutputIndex ==
+						walletMainUtxo.Outpoint.OutputIndex {
+					return transaction, nil
+				}
+			}
+
+			continue
+		}
+
+		// The wallet's first transaction is either a deposit sweep or a
+		// moved funds sweep so it is enough to check the first input.
+		// Transactions not spending a deposit nor moved funds are spam.
+		input := transaction.Inputs[0]
+
+		_, isDeposit, err := bridgeChain.GetDepositRequest(
+			input.Outpoint.TransactionHash,
+			input.Outpoint.OutputIndex,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("cannot get deposit request: [%v]", err)
+		}
+
+		_, isMovedFunds, err := bridgeChain.GetMovedFundsSweepRequest(
+			input.Outpoint.TransactionHash,
+			input.Outpoint.OutputIndex,
+		)
+		if err != nil {
+			return nil, fmt.Errorf(
+				"cannot get moved funds sweep request: [%v]",
+				err,
+			)
+		}
+
+		if isDeposit || isMovedFunds {
+			return transaction, nil
+		}
+	}
+
+	return nil, nil
+}
+
+// DetermineFeeBumpLimits determines the fee currently paid by the given
+// pending wallet transaction, the minimum fee its replacement must pay to be
+// relayed by the Bitcoin network, and the maximum fee the replacement can
+// pay according to the Bridge rules.
+func DetermineFeeBumpLimits(
+	walletPublicKeyHash [20]byte,
+	transaction *bitcoin.Transaction,
+	bridgeChain BridgeChain,
+	btcChain bitcoin.Chain,
+) (int64, int64, int64, error) {
+	walletMainUtxo, err := DetermineWalletMainUtxo(
+		walletPublicKeyHash,
+		bridgeChain,
+		btcChain,
+	)
+	if err != nil {
+		return 0, 0, 0, fmt.Errorf(
+			"error while determining wallet's main UTXO: [%v]",
+			err,
+		)
+	}
+
+	pendingTx, err := resolvePendingWalletTransaction(
+		walletPublicKeyHash,
+		walletMainUtxo,
+		transaction,
+		bridgeChain,
+		btcChain,
+	)
+	if err != nil {
+		return 0, 0, 0, err
+	}
+
+	return pendingTx.fee, pendingTx.minFee, pendingTx.maxFee, nil
+}
+
+// ValidateFeeBumpProposal checks the fee bump proposal against the Bitcoin
+// chain state and the Bridge rules. As opposed to other proposals, the
+// Bridge does not expose a validation function for fee bumps so the
+// validation is performed entirely off-chain.
+func ValidateFeeBumpProposal(
+	validateProposalLogger log.StandardLogger,
+	walletPublicKeyHash [20]byte,
+	proposal *FeeBumpProposal,
+	bridgeChain BridgeChain,
+	btcChain bitcoin.Chain,
+) error {
+	_, err := validateFeeBumpProposal(
+		validateProposalLogger,
+		walletPublicKeyHash,
+		proposal,
+		bridgeChain,
+		btcChain,
+	)
+
+	return err
+}
+
+func validateFeeBumpProposal(
+	validateProposalLogger log.StandardLogger,
+	walletPublicKeyHash [20]byte,
+	proposal *FeeBumpProposal,
+	bridgeChain BridgeChain,
+	btcChain bitcoin.Chain,
+) (*pendingWalletTransaction, error) {
+	validateProposalLogger.Infof("checking the transaction to replace")
+
+	confirmations, err := btcChain.GetTransactionConfirmations(
+		proposal.TransactionHash,
+	)
+	if err != nil {
+		return nil, fmt.Errorf(
+			"cannot get confirmations of transaction [%s]: [%v]",
+			proposal.TransactionHash.Hex(bitcoin.ReversedByteOrder),
+			err,
+		)
+	}
+
+	if confirmations > 0 {
+		return nil, fmt.Errorf(
+			"transaction [%s] is already confirmed",
+			proposal.TransactionHash.Hex(bitcoin.ReversedByteOrder),
+		)
+	}
+
+	walletMainUtxo, err := DetermineWalletMainUtxo(
+		walletPublicKeyHash,
+		bridgeChain,
+		btcChain,
+	)
+	if err != nil {
+		return nil, fmt.Errorf(
+			"error while determining wallet's main UTXO: [%v]",
+			err,
+		)
+	}
+
+	transaction, err := FindPendingWalletTransaction(
+		walletPublicKeyHash,
+		walletMainUtxo,
+		bridgeChain,
+		btcChain,
+	)
+	if err != nil {
+		return nil, fmt.Errorf(
+			"cannot find pending wallet transaction: [%v]",
+			err,
+		)
+	}
+
+	if transaction == nil || transaction.Hash() != proposal.TransactionHash {
+		return nil, fmt.Errorf(
+			"transaction [%s] is not the pending wallet transaction",
+			proposal.TransactionHash.Hex(bitcoin.ReversedByteOrder),
+		)
+	}
+
+	pendingTx, err := resolvePendingWalletTransaction(
+		walletPublicKeyHash,
+		walletMainUtxo,
+		transaction,
+		bridgeChain,
+		btcChain,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	validateProposalLogger.Infof(
+		"transaction to replace is a [%s] transaction paying fee [%v]",
+		pendingTx.kind,
+		pendingTx.fee,
+	)
+
+	fee := proposal.TxFee.Int64()
+
+	if fee < pendingTx.minFee {
+		return nil, fmt.Errorf(
+			"proposed fee [%v] is lower than the minimum "+
+				"replacement fee [%v]",
+			fee,
+			pendingTx.minFee,
+		)
+	}
+
+	if fee > pendingTx.maxFee {
+		return nil, fmt.Errorf(
+			"proposed fee [%v] exceeds the maximum fee [%v]",
+			fee,
+			pendingTx.maxFee,
+		)
+	}
+
+	if pendingTx.kind == ActionRedemption {
+		feeShares := withRedemptionTotalFee(fee)(pendingTx.redemptionRequests)
+		for i, request := range pendingTx.redemptionRequests {
+			if feeShares[i] > int64(request.TxMaxFee) {
+				return nil, fmt.Errorf(
+					"fee share [%v] of redemption request [%v] exceeds "+
+						"the request's maximum fee [%v]",
+					feeShares[i],
+					i,
+					request.TxMaxFee,
+				)
+			}
+		}
+	}
+
+	validateProposalLogger.Infof("fee bump proposal is valid")
+
+	return pendingTx, nil
+}
+
+// resolvePendingWalletTransaction determines the type of the given pending
+// wallet transaction along with its current and maximum fee.
+func resolvePendingWalletTransaction(
+	walletPublicKeyHash [20]byte,
+	walletMainUtxo *bitcoin.UnspentTransactionOutput,
+	transaction *bitcoin.Transaction,
+	bridgeChain BridgeChain,
+	btcChain bitcoin.Chain,
+) (*pendingWalletTransaction, error) {
+	pendingTx := &pendingWalletTransaction{
+		transaction:    transaction,
+		inputs:         make([]*bitcoin.UnspentTransactionOutput, len(transaction.Inputs)),
+		inputScripts:   make([]bitcoin.Script, len(transaction.Inputs)),
+		walletMainUtxo: walletMainUtxo,
+	}
+
+	depositsCount := 0
+	movedFundsCount := 0
+	inputsValue := int64(0)
+
+	for i, input := range transaction.Inputs {
+		previousTx, err := btcChain.GetTransaction(input.Outpoint.TransactionHash)
+		if err != nil {
+			return nil, fmt.Errorf(
+				"cannot get transaction spent by input [%v]: [%v]",
+				i,
+				err,
+			)
+		}
+
+		if int(input.Outpoint.OutputIndex) >= len(previousTx.Outputs) {
+			return nil, fmt.Errorf(
+				"transaction spent by input [%v] has no output [%v]",
+				i,
+				input.Outpoint.OutputIndex,
+			)
+		}
+
+		previousOutput := previousTx.Outputs[input.Outpoint.OutputIndex]
+
+		pendingTx.inputs[i] = &bitcoin.UnspentTransactionOutput{
+			Outpoint: input.Outpoint,
+			Value:    previousOutput.Value,
+		}
+		pendingTx.inputScripts[i] = previousOutput.PublicKeyScript
+		inputsValue += previousOutput.Value
+
+		if walletMainUtxo != nil &&
+			input.Outpoint.TransactionHash == walletMainUtxo.Outpoint.TransactionHash &&
+			input.Outpoint.OutputIndex == walletMainUtxo.Outpoint.OutputIndex {
+			continue
+		}
+
+		_, isDeposit, err := bridgeChain.GetDepositRequest(
+			input.Outpoint.TransactionHash,
+			input.Outpoint.OutputIndex,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("cannot get deposit request: [%v]", err)
+		}
+		if isDeposit {
+			depositsCount++
+			continue
+		}
+
+		_, isMovedFunds, err := bridgeChain.GetMovedFundsSweepRequest(
+			input.Outpoint.TransactionHash,
+			input.Outpoint.OutputIndex,
+		)
+		if err != nil {
+			return nil, fmt.Errorf(
+				"cannot get moved funds sweep request: [%v]",
+				err,
+			)
+		}
+		if isMovedFunds {
+			movedFundsCount++
+		}
+	}
+
+	outputsValue := int64(0)
+	for _, output := range transaction.Outputs {
+		outputsValue += output.Value
+	}
+
+	pendingTx.fee = inputsValue - outputsValue
+
+	switch {
+	case depositsCount > 0:
+		_, _, depositTxMaxFee, _, err := bridgeChain.GetDepositParameters()
+		if err != nil {
+			return nil, fmt.Errorf("cannot get deposit parameters: [%v]", err)
+		}
+
+		pendingTx.kind = ActionDepositSweep
+		pendingTx.maxFee = int64(depositTxMaxFee) * int64(depositsCount)
+	case movedFundsCount > 0:
+		_, _, _, _, _, _, _, sweepTxMaxTotalFee, _, _, _, err :=
+			bridgeChain.GetMovingFundsParameters()
+		if err != nil {
+			return nil, fmt.Errorf(
+				"cannot get moving funds parameters: [%v]",
+				err,
+			)
+		}
+
+		pendingTx.kind = ActionMovedFundsSweep
+		pendingTx.maxFee = int64(sweepTxMaxTotalFee)
+	default:
+		// Redemptions and moving funds spend just the wallet main UTXO.
+		// They can be distinguished by their outputs.
+		if walletMainUtxo == nil || len(transaction.Inputs) != 1 {
+			return nil, fmt.Errorf("cannot determine transaction type")
+		}
+
+		requests, err := resolveRedemptionRequests(
+			walletPublicKeyHash,
+			transaction,
+			bridgeChain,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(requests) > 0 {
+			maxFee, err := redemptionMaxFee(requests, bridgeChain)
+			if err != nil {
+				return nil, err
+			}
+
+			pendingTx.kind = ActionRedemption
+			pendingTx.redemptionRequests = requests
+			pendingTx.maxFee = maxFee
+			break
+		}
+
+		walletChainData, err := bridgeChain.GetWallet(walletPublicKeyHash)
+		if err != nil {
+			return nil, fmt.Errorf(
+				"cannot get on-chain data for wallet: [%v]",
+				err,
+			)
+		}
+
+		if walletChainData.State != StateMovingFunds {
+			return nil, fmt.Errorf("cannot determine transaction type")
+		}
+
+		txMaxTotalFee, _, _, _, _, _, _, _, _, _, _, err :=
+			bridgeChain.GetMovingFundsParameters()
+		if err != nil {
+			return nil, fmt.Errorf(
+				"cannot get moving funds parameters: [%v]",
+				err,
+			)
+		}
+
+		pendingTx.kind = ActionMovingFunds
+		pendingTx.maxFee = int64(txMaxTotalFee)
+	}
+
+	replacementVirtualSize, err := estimateReplacementVirtualSize(pendingTx)
+	if err != nil {
+		return nil, fmt.Errorf(
+			"cannot estimate replacement transaction size: [%v]",
+			err,
+		)
+	}
+
+	// According to BIP-125 rule 4, the replacement must pay for its own
+	// bandwidth at the incremental relay fee rate, on top of the fee paid
+	// by the replaced transaction.
+	pendingTx.minFee = pendingTx.fee +
+		FeeBumpMinRelayFeeIncrement*replacementVirtualSize
+
+	return pendingTx, nil
+}
+
+// estimateReplacementVirtualSize estimates the virtual size of the signed
+// replacement of the given pending wallet transaction. The replacement
+// spends the same inputs as the pending transaction. A sweep replacement
+// transfers them to a single P2WPKH output of the wallet while replacements
+// of other transactions pay the same outputs as the pending transaction.
+// Outputs the replacement may drop, like a redemption change consumed by
+// the higher fee, are counted anyway so the estimate is never too low.
+func estimateReplacementVirtualSize(
+	pendingTx *pendingWalletTransaction,
+) (int64, error) {
+	sizeEstimator := bitcoin.NewTransactionSizeEstimator()
+
+	for i, input := range pendingTx.transaction.Inputs {
+		switch scriptType := bitcoin.GetScriptType(pendingTx.inputScripts[i]); scriptType {
+		case bitcoin.P2PKHScript, bitcoin.P2WPKHScript:
+			sizeEstimator.AddPublicKeyHashInputs(
+				1,
+				scriptType == bitcoin.P2WPKHScript,
+			)
+		case bitcoin.P2SHScript, bitcoin.P2WSHScript:
+			redeemScript, err := extractRedeemScript(input)
+			if err != nil {
+				return 0, fmt.Errorf(
+					"cannot extract redeem script of input [%v]: [%v]",
+					i,
+					err,
+				)
+			}
+
+			sizeEstimator.AddScriptHashInputs(
+				1,
+				len(redeemScript),
+				scriptType == bitcoin.P2WSHScript,
+			)
+		default:
+			return 0, fmt.Errorf("unsupported script type of input [%v]", i)
+		}
+	}
+
+	switch pendingTx.kind {
+	case ActionDepositSweep, ActionMovedFundsSweep:
+		sizeEstimator.AddPublicKeyHashOutputs(1, true)
+	default:
+		for _, output := range pendingTx.transaction.Outputs {
+			addOutputToSizeEstimator(sizeEstimator, output.PublicKeyScript)
+		}
+	}
+
+	return sizeEstimator.VirtualSize()
+}
+
+// validateFeeBumpPendingAge checks whether the given transaction remains
+// unconfirmed for at least the given number of Bitcoin blocks since it was
+// signed by the wallet. The signing height is taken from the given pending
+// transactions tracker so the fee bump is refused if the node does not know
+// when the transaction was signed.
+func validateFeeBumpPendingAge(
+	pendingTransactions *pendingTransactionsTracker,
+	transactionHash bitcoin.Hash,
+	minPendingBlocks uint,
+) error {
+	if minPendingBlocks == 0 {
+		return nil
+	}
+
+	pendingBlocks, ok, err := pendingTransactions.pendingBlocks(
+		transactionHash,
+	)
+	if err != nil {
+		return fmt.Errorf(
+			"cannot determine for how long transaction [%s] is pending: [%v]",
+			transactionHash.Hex(bitcoin.ReversedByteOrder),
+			err,
+		)
+	}
+
+	if !ok {
+		return fmt.Errorf(
+			"signing block of transaction [%s] is not known",
+			transactionHash.Hex(bitcoin.ReversedByteOrder),
+		)
+	}
+
+	if pendingBlocks < minPendingBlocks {
+		return fmt.Errorf(
+			"transaction [%s] is unconfirmed for [%v] blocks while at "+
+				"least [%v] blocks are required to bump its fee",
+			transactionHash.Hex(bitcoin.ReversedByteOrder),
+			pendingBlocks,
+			minPendingBlocks,
+		)
+	}
+
+	return nil
+}
+
+// resolveRedemptionRequests returns the pending redemption requests handled
+// by the given transaction, in the order of redemption outputs. Returns an
+// empty slice if any of the transaction's outputs, except the wallet's
+// change, does not correspond to a pending redemption request.
+func resolveRedemptionRequests(
+	walletPublicKeyHash [20]byte,
+	transaction *bitcoin.Transaction,
+	bridgeChain BridgeChain,
+) ([]*RedemptionRequest, error) {
+	walletP2PKH, err := bitcoin.PayToPublicKeyHash(walletPublicKeyHash)
+	if err != nil {
+		return nil, fmt.Errorf("cannot construct P2PKH for wallet: [%v]", err)
+	}
+	walletP2WPKH, err := bitcoin.PayToWitnessPublicKeyHash(walletPublicKeyHash)
+	if err != nil {
+		return nil, fmt.Errorf("cannot construct P2WPKH for wallet: [%v]", err)
+	}
+
+	requests := make([]*RedemptionRequest, 0)
+
+	for _, output := range transaction.Outputs {
+		script := output.PublicKeyScript
+
+		if bytes.Equal(script, walletP2PKH) || bytes.Equal(script, walletP2WPKH) {
+			continue
+		}
+
+		request, found, err := bridgeChain.GetPendingRedemptionRequest(
+			walletPublicKeyHash,
+			script,
+		)
+		if err != nil {
+			return nil, fmt.Errorf(
+				"cannot get pending redemption request: [%v]",
+				err,
+			)
+		}
+
+		if !found {
+			return []*RedemptionRequest{}, nil
+		}
+
+		requests = append(requests, request)
+	}
+
+	return requests, nil
+}
+
+// redemptionMaxFee determines the maximum fee of a transaction handling
+// the given redemption requests. The fee is capped by the Bridge's total
+// redemption fee limit as well as by per-request fee limits. The returned
+// fee is divisible by the number of requests so it is distributed evenly
+// by withRedemptionTotalFee.
+func redemptionMaxFee(
+	requests []*RedemptionRequest,
+	bridgeChain BridgeChain,
+) (int64, error) {
+	_, _, _, txMaxTotalFee, _, _, _, err := bridgeChain.GetRedemptionParameters()
+	if err != nil {
+		return 0, fmt.Errorf("cannot get redemption parameters: [%v]", err)
+	}
+
+	requestsCount := int64(len(requests))
+	maxFee := int64(txMaxTotalFee)
+
+	for _, request := range requests {
+		if requestMaxFee := int64(request.TxMaxFee) * requestsCount; requestMaxFee < maxFee {
+			maxFee = requestMaxFee
+		}
+	}
+
+	return maxFee - maxFee%requestsCount, nil
+}
+
+// assembleFeeBumpTransaction constructs an unsigned Bitcoin transaction
+// replacing the given pending wallet transaction and paying the given fee.
+// The replacement has the same shape as the transaction produced by
+// the original wallet action so it can be proven to the Bridge in the same
+// way.
+func assembleFeeBumpTransaction(
+	bitcoinChain bitcoin.Chain,
+	walletPublicKeyHash [20]byte,
+	pendingTx *pendingWalletTransaction,
+	fee int64,
+) (*bitcoin.TransactionBuilder, error) {
+	switch pendingTx.kind {
+	case ActionRedemption:
+		// The change output, if any, keeps its position from the replaced
+		// transaction. If the first output is a redemption output, the
+		// change must be the last one.
+		shape := RedemptionChangeFirst
+		outputs := pendingTx.transaction.Outputs
+		hasChange := len(outputs) > len(pendingTx.redemptionRequests)
+		if hasChange && bytes.Equal(
+			outputs[0].PublicKeyScript,
+			pendingTx.redemptionRequests[0].RedeemerOutputScript,
+		) {
+			shape = RedemptionChangeLast
+		}
+
+		return assembleRedemptionTransaction(
+			bitcoinChain,
+			walletPublicKeyHash,
+			pendingTx.walletMainUtxo,
+			pendingTx.redemptionRequests,
+			withRedemptionTotalFee(fee),
+			shape,
+		)
+	case ActionMovingFunds:
+		targetWallets := make([][20]byte, len(pendingTx.transaction.Outputs))
+		for i, output := range pendingTx.transaction.Outputs {
+			targetWallet, err := bitcoin.ExtractPublicKeyHash(
+				output.PublicKeyScript,
+			)
+			if err != nil {
+				return nil, fmt.Errorf(
+					"cannot extract target wallet from output [%v]: [%v]",
+					i,
+					err,
+				)
+			}
+
+			targetWallets[i] = targetWallet
+		}
+
+		return assembleMovingFundsTransaction(
+			bitcoinChain,
+			pendingTx.walletMainUtxo,
+			targetWallets,
+			fee,
+		)
+	case ActionDepositSweep, ActionMovedFundsSweep:
+		return assembleSweepReplacementTransaction(
+			bitcoinChain,
+			walletPublicKeyHash,
+			pendingTx,
+			fee,
+		)
+	default:
+		return nil, fmt.Errorf(
+			"unsupported transaction type [%s]",
+			pendingTx.kind,
+		)
+	}
+}
+
+// assembleSweepReplacementTransaction constructs an unsigned sweep
+// transaction spending the same inputs, in the same order, as the given
+// pending sweep transaction and transferring them to the wallet itself.
+// Redeem scripts of script hash inputs are taken from the pending
+// transaction.
+func assembleSweepReplacementTransaction(
+	bitcoinChain bitcoin.Chain,
+	walletPublicKeyHash [20]byte,
+	pendingTx *pendingWalletTransaction,
+	fee int64,
+) (*bitcoin.TransactionBuilder, error) {
+	builder := bitcoin.NewTransactionBuilder(bitcoinChain)
+
+	for i, input := range pendingTx.transaction.Inputs {
+		utxo := pendingTx.inputs[i]
+
+		switch bitcoin.GetScriptType(pendingTx.inputScripts[i]) {
+		case bitcoin.P2PKHScript, bitcoin.P2WPKHScript:
+			err := builder.AddPublicKeyHashInput(utxo)
+			if err != nil {
+				return nil, fmt.Errorf(
+					"cannot add public key hash input [%v]: [%v]",
+					i,
+					err,
+				)
+			}
+		case bitcoin.P2SHScript, bitcoin.P2WSHScript:
+			redeemScript, err := extractRedeemScript(input)
+			if err != nil {
+				return nil, fmt.Errorf(
+					"cannot extract redeem script of input [%v]: [%v]",
+					i,
+					err,
+				)
+			}
+
+			err = builder.AddScriptHashInput(utxo, redeemScript)
+			if err != nil {
+				return nil, fmt.Errorf(
+					"cannot add script hash input [%v]: [%v]",
+					i,
+					err,
+				)
+			}
+		default:
+			return nil, fmt.Errorf("unsupported script type of input [%v]", i)
+		}
+	}
+
+	outputScript, err := bitcoin.PayToWitnessPublicKeyHash(walletPublicKeyHash)
+	if err != nil {
+		return nil, fmt.Errorf("cannot compute output script: [%v]", err)
+	}
+
+	builder.AddOutput(&bitcoin.TransactionOutput{
+		Value:           builder.TotalInputsValue() - fee,
+		PublicKeyScript: outputScript,
+	})
+
+	return builder, nil
+}
+
+// extractRedeemScript extracts the plain-text redeem script from the given
+// signed input spending a P2SH or P2WSH UTXO. The redeem script is the last
+// item of the witness for P2WSH or the last data push of the signature
+// script for P2SH.
+func extractRedeemScript(input *bitcoin.TransactionInput) (bitcoin.Script, error) {
+	if len(input.Witness) > 0 {
+		return input.Witness[len(input.Witness)-1], nil
+	}
+
+	pushes, err := txscript.PushedData(input.SignatureScript)
+	if err != nil {
+		return nil, fmt.Errorf("cannot parse signature script: [%v]", err)
+	}
+
+	if len(pushes) == 0 {
+		return nil, fmt.Errorf("signature script is empty")
+	}
+
+	return pushes[len(pushes)-1], nil
+}
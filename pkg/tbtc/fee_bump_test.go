@@ -0,0 +1,569 @@
+package tbtc
+
+import (
+	"fmt"
+	"math/big"
+	"reflect"
+	"testing"
+
+	"github.com/keep-network/keep-core/internal/testutils"
+	"github.com/keep-network/keep-core/pkg/bitcoin"
+	"github.com/keep-network/keep-core/pkg/tbtc/internal/test"
+)
+
+// The tests below make sure a replacement of a pending wallet transaction
+// paying the same fee is the very same transaction. That proves the
+// replacement has the shape expected by the Bridge.
+
+func TestAssembleFeeBumpTransaction_DepositSweep(t *testing.T) {
+	scenarios, err := test.LoadDepositSweepTestScenarios()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, scenario := range scenarios {
+		t.Run(scenario.Title, func(t *testing.T) {
+			localChain := Connect()
+			bitcoinChain := newLocalBitcoinChain()
+
+			for _, transaction := range scenario.InputTransactions {
+				err := bitcoinChain.BroadcastTransaction(transaction)
+				if err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			for _, deposit := range scenario.Deposits {
+				localChain.setDepositRequest(
+					deposit.Utxo.Outpoint.TransactionHash,
+					deposit.Utxo.Outpoint.OutputIndex,
+					&DepositChainRequest{},
+				)
+			}
+
+			localChain.SetDepositParameters(0, 0, 5000, 0)
+
+			assertFeeBumpReproducesTransaction(
+				t,
+				localChain,
+				bitcoinChain,
+				bitcoin.PublicKeyHash(scenario.WalletPublicKey),
+				scenario.WalletMainUtxo,
+				scenario.ExpectedSweepTransaction,
+				ActionDepositSweep,
+				scenario.Fee,
+				5000*int64(len(scenario.Deposits)),
+				scenario.Signatures,
+				scenario.ExpectedSigHashes,
+			)
+		})
+	}
+}
+
+func TestAssembleFeeBumpTransaction_Redemption(t *testing.T) {
+	scenarios, err := test.LoadRedemptionTestScenarios()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, scenario := range scenarios {
+		// Replacements distribute the fee evenly among redemption requests
+		// so only scenarios using an even distribution can be reproduced.
+		if len(scenario.RedemptionRequests) != 1 {
+			continue
+		}
+
+		t.Run(scenario.Title, func(t *testing.T) {
+			localChain := Connect()
+			bitcoinChain := newLocalBitcoinChain()
+
+			err := bitcoinChain.BroadcastTransaction(scenario.InputTransaction)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			walletPublicKeyHash := bitcoin.PublicKeyHash(scenario.WalletPublicKey)
+
+			request := scenario.RedemptionRequests[0]
+			localChain.setPendingRedemptionRequest(
+				walletPublicKeyHash,
+				&RedemptionRequest{
+					Redeemer:             request.Redeemer,
+					RedeemerOutputScript: request.RedeemerOutputScript,
+					RequestedAmount:      request.RequestedAmount,
+					TreasuryFee:          request.TreasuryFee,
+					TxMaxFee:             request.TxMaxFee,
+					RequestedAt:          request.RequestedAt,
+				},
+			)
+
+			localChain.SetRedemptionParameters(0, 0, 0, 10000, 0, nil, 0)
+
+			assertFeeBumpReproducesTransaction(
+				t,
+				localChain,
+				bitcoinChain,
+				walletPublicKeyHash,
+				scenario.WalletMainUtxo,
+				scenario.ExpectedRedemptionTransaction,
+				ActionRedemption,
+				scenario.FeeShares[0],
+				int64(request.TxMaxFee),
+				[]*bitcoin.SignatureContainer{scenario.Signature},
+				[]*big.Int{scenario.ExpectedSigHash},
+			)
+		})
+	}
+}
+
+func TestAssembleFeeBumpTransaction_MovingFunds(t *testing.T) {
+	scenarios, err := test.LoadMovingFundsTestScenarios()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, scenario := range scenarios {
+		t.Run(scenario.Title, func(t *testing.T) {
+			localChain := Connect()
+			bitcoinChain := newLocalBitcoinChain()
+
+			err := bitcoinChain.BroadcastTransaction(scenario.InputTransaction)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			walletPublicKeyHash := bitcoin.PublicKeyHash(scenario.WalletPublicKey)
+
+			localChain.setWallet(
+				walletPublicKeyHash,
+				&WalletChainData{State: StateMovingFunds},
+			)
+
+			localChain.SetMovingFundsParameters(
+				20000, 0, 0, 0, nil, 0, 0, 0, 0, nil, 0,
+			)
+
+			assertFeeBumpReproducesTransaction(
+				t,
+				localChain,
+				bitcoinChain,
+				walletPublicKeyHash,
+				scenario.WalletMainUtxo,
+				scenario.ExpectedMovingFundsTransaction,
+				ActionMovingFunds,
+				scenario.Fee,
+				20000,
+				[]*bitcoin.SignatureContainer{scenario.Signature},
+				[]*big.Int{scenario.ExpectedSigHash},
+			)
+		})
+	}
+}
+
+func TestAssembleFeeBumpTransaction_MovedFundsSweep(t *testing.T) {
+	scenarios, err := test.LoadMovedFundsSweepTestScenarios()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, scenario := range scenarios {
+		t.Run(scenario.Title, func(t *testing.T) {
+			localChain := Connect()
+			bitcoinChain := newLocalBitcoinChain()
+
+			for _, transaction := range scenario.InputTransactions {
+				err := bitcoinChain.BroadcastTransaction(transaction)
+				if err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			localChain.setMovedFundsSweepRequest(
+				scenario.MovedFundsUtxo.Outpoint.TransactionHash,
+				scenario.MovedFundsUtxo.Outpoint.OutputIndex,
+				&MovedFundsSweepRequest{State: MovedFundsStatePending},
+			)
+
+			localChain.SetMovingFundsParameters(
+				0, 0, 0, 0, nil, 0, 0, 15000, 0, nil, 0,
+			)
+
+			assertFeeBumpReproducesTransaction(
+				t,
+				localChain,
+				bitcoinChain,
+				bitcoin.PublicKeyHash(scenario.WalletPublicKey),
+				scenario.WalletMainUtxo,
+				scenario.ExpectedMovedFundsSweepTransaction,
+				ActionMovedFundsSweep,
+				scenario.Fee,
+				15000,
+				scenario.Signatures,
+				scenario.ExpectedSigHashes,
+			)
+		})
+	}
+}
+
+func assertFeeBumpReproducesTransaction(
+	t *testing.T,
+	localChain *localChain,
+	bitcoinChain *localBitcoinChain,
+	walletPublicKeyHash [20]byte,
+	walletMainUtxo *bitcoin.UnspentTransactionOutput,
+	pendingTransaction *bitcoin.Transaction,
+	expectedKind WalletActionType,
+	expectedFee int64,
+	expectedMaxFee int64,
+	signatures []*bitcoin.SignatureContainer,
+	expectedSigHashes []*big.Int,
+) {
+	pendingTx, err := resolvePendingWalletTransaction(
+		walletPublicKeyHash,
+		walletMainUtxo,
+		pendingTransaction,
+		localChain,
+		bitcoinChain,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if expectedKind != pendingTx.kind {
+		t.Errorf(
+			"unexpected transaction kind\nexpected: %s\nactual:   %s\n",
+			expectedKind,
+			pendingTx.kind,
+		)
+	}
+	testutils.AssertIntsEqual(t, "fee", int(expectedFee), int(pendingTx.fee))
+	testutils.AssertIntsEqual(
+		t,
+		"max fee",
+		int(expectedMaxFee),
+		int(pendingTx.maxFee),
+	)
+
+	builder, err := assembleFeeBumpTransaction(
+		bitcoinChain,
+		walletPublicKeyHash,
+		pendingTx,
+		pendingTx.fee,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sigHashes, err := builder.ComputeSignatureHashes()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testutils.AssertIntsEqual(
+		t,
+		"sighash count",
+		len(expectedSigHashes),
+		len(sigHashes),
+	)
+
+	for i, sigHash := range sigHashes {
+		testutils.AssertBigIntsEqual(
+			t,
+			fmt.Sprintf("sighash for input [%v]", i),
+			expectedSigHashes[i],
+			sigHash,
+		)
+	}
+
+	transaction, err := builder.AddSignatures(signatures)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testutils.AssertBytesEqual(
+		t,
+		pendingTransaction.Serialize(),
+		transaction.Serialize(),
+	)
+
+	// The replacement size is estimated with maximum-length signatures so
+	// the BIP-125 increment must cover the actual replacement size.
+	if increment := pendingTx.minFee - pendingTx.fee; increment < int64(transaction.VirtualSize()) {
+		t.Errorf(
+			"replacement fee increment [%v] is lower than the "+
+				"replacement virtual size [%v]",
+			increment,
+			transaction.VirtualSize(),
+		)
+	}
+}
+
+func TestValidateFeeBumpProposal(t *testing.T) {
+	scenarios, err := test.LoadRedemptionTestScenarios()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Single redemption with a change output. The original fee is 1600.
+	scenario := scenarios[0]
+
+	walletPublicKeyHash := bitcoin.PublicKeyHash(scenario.WalletPublicKey)
+	pendingTransaction := scenario.ExpectedRedemptionTransaction
+	// The minimum fee is 1600 + 1 sat/vbyte * 144 vbytes of the
+	// replacement's estimated size.
+	minFee := int64(1744)
+
+	tests := map[string]struct {
+		transactionHash  bitcoin.Hash
+		fee              int64
+		requestTxMaxFee  uint64
+		confirmedPending bool
+		expectedErr      error
+	}{
+		"valid proposal with minimum fee": {
+			transactionHash: pendingTransaction.Hash(),
+			fee:             minFee,
+			requestTxMaxFee: 3000,
+		},
+		"valid proposal with maximum fee": {
+			transactionHash: pendingTransaction.Hash(),
+			fee:             3000,
+			requestTxMaxFee: 3000,
+		},
+		"fee below minimum replacement fee": {
+			transactionHash: pendingTransaction.Hash(),
+			fee:             minFee - 1,
+			requestTxMaxFee: 3000,
+			expectedErr: fmt.Errorf(
+				"proposed fee [1743] is lower than the minimum " +
+					"replacement fee [1744]",
+			),
+		},
+		"fee above request's maximum fee": {
+			transactionHash: pendingTransaction.Hash(),
+			fee:             3001,
+			requestTxMaxFee: 3000,
+			expectedErr: fmt.Errorf(
+				"proposed fee [3001] exceeds the maximum fee [3000]",
+			),
+		},
+		"transaction is a confirmed input transaction": {
+			transactionHash: scenario.InputTransaction.Hash(),
+			fee:             minFee,
+			requestTxMaxFee: 3000,
+			expectedErr: fmt.Errorf(
+				"transaction [%s] is already confirmed",
+				scenario.InputTransaction.Hash().Hex(bitcoin.ReversedByteOrder),
+			),
+		},
+		"pending transaction already confirmed": {
+			transactionHash:  pendingTransaction.Hash(),
+			fee:              minFee,
+			requestTxMaxFee:  3000,
+			confirmedPending: true,
+			expectedErr: fmt.Errorf(
+				"transaction [%s] is already confirmed",
+				pendingTransaction.Hash().Hex(bitcoin.ReversedByteOrder),
+			),
+		},
+	}
+
+	for testName, test := range tests {
+		t.Run(testName, func(t *testing.T) {
+			localChain := Connect()
+			bitcoinChain := newLocalBitcoinChain()
+
+			err := bitcoinChain.BroadcastTransaction(scenario.InputTransaction)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if test.confirmedPending {
+				err := bitcoinChain.BroadcastTransaction(pendingTransaction)
+				if err != nil {
+					t.Fatal(err)
+				}
+			} else {
+				bitcoinChain.mempool = append(
+					bitcoinChain.mempool,
+					pendingTransaction,
+				)
+			}
+
+			localChain.setWallet(
+				walletPublicKeyHash,
+				&WalletChainData{
+					MainUtxoHash: localChain.ComputeMainUtxoHash(
+						scenario.WalletMainUtxo,
+					),
+					State: StateLive,
+				},
+			)
+
+			request := scenario.RedemptionRequests[0]
+			localChain.setPendingRedemptionRequest(
+				walletPublicKeyHash,
+				&RedemptionRequest{
+					Redeemer:             request.Redeemer,
+					RedeemerOutputScript: request.RedeemerOutputScript,
+					RequestedAmount:      request.RequestedAmount,
+					TreasuryFee:          request.TreasuryFee,
+					TxMaxFee:             test.requestTxMaxFee,
+					RequestedAt:          request.RequestedAt,
+				},
+			)
+
+			localChain.SetRedemptionParameters(0, 0, 0, 10000, 0, nil, 0)
+
+			err = ValidateFeeBumpProposal(
+				&testutils.MockLogger{},
+				walletPublicKeyHash,
+				&FeeBumpProposal{
+					TransactionHash: test.transactionHash,
+					TxFee:           big.NewInt(test.fee),
+				},
+				localChain,
+				bitcoinChain,
+			)
+
+			if !reflect.DeepEqual(test.expectedErr, err) {
+				t.Errorf(
+					"unexpected error\nexpected: %v\nactual:   %v\n",
+					test.expectedErr,
+					err,
+				)
+			}
+		})
+	}
+}
+
+func TestValidateFeeBumpPendingAge(t *testing.T) {
+	transactionHash := bitcoin.Hash{0x01}
+
+	tests := map[string]struct {
+		minPendingBlocks uint
+		signedAtBlock    uint
+		tracked          bool
+		expectedErr      error
+	}{
+		"check disabled": {
+			minPendingBlocks: 0,
+		},
+		"transaction not tracked": {
+			minPendingBlocks: 6,
+			expectedErr: fmt.Errorf(
+				"signing block of transaction [%s] is not known",
+				transactionHash.Hex(bitcoin.ReversedByteOrder),
+			),
+		},
+		"signing block not known": {
+			minPendingBlocks: 6,
+			tracked:          true,
+			expectedErr: fmt.Errorf(
+				"signing block of transaction [%s] is not known",
+				transactionHash.Hex(bitcoin.ReversedByteOrder),
+			),
+		},
+		"transaction pending for too short": {
+			minPendingBlocks: 6,
+			signedAtBlock:    995,
+			tracked:          true,
+			expectedErr: fmt.Errorf(
+				"transaction [%s] is unconfirmed for [5] blocks while "+
+					"at least [6] blocks are required to bump its fee",
+				transactionHash.Hex(bitcoin.ReversedByteOrder),
+			),
+		},
+		"transaction pending for long enough": {
+			minPendingBlocks: 6,
+			signedAtBlock:    994,
+			tracked:          true,
+		},
+	}
+
+	for testName, test := range tests {
+		t.Run(testName, func(t *testing.T) {
+			bitcoinChain := newLocalBitcoinChain()
+			bitcoinChain.setLatestBlockHeight(1000)
+
+			tracker := &pendingTransactionsTracker{
+				btcChain:     bitcoinChain,
+				transactions: make(map[bitcoin.Hash]*pendingTransaction),
+			}
+			if test.tracked {
+				tracker.transactions[transactionHash] = &pendingTransaction{
+					signedAtBlock: test.signedAtBlock,
+				}
+			}
+
+			err := validateFeeBumpPendingAge(
+				tracker,
+				transactionHash,
+				test.minPendingBlocks,
+			)
+
+			if !reflect.DeepEqual(test.expectedErr, err) {
+				t.Errorf(
+					"unexpected error\nexpected: %v\nactual:   %v\n",
+					test.expectedErr,
+					err,
+				)
+			}
+		})
+	}
+}
+
+func TestResolvePendingWalletTransaction_MissingPreviousOutput(t *testing.T) {
+	localChain := Connect()
+	bitcoinChain := newLocalBitcoinChain()
+
+	previousTransaction := &bitcoin.Transaction{
+		Version: 1,
+		Inputs: []*bitcoin.TransactionInput{
+			{
+				Outpoint: &bitcoin.TransactionOutpoint{
+					TransactionHash: bitcoin.Hash{0x01},
+					OutputIndex:     0,
+				},
+				Sequence: 0xffffffff,
+			},
+		},
+		Outputs: []*bitcoin.TransactionOutput{
+			{Value: 10000, PublicKeyScript: []byte{0x00, 0x14}},
+		},
+	}
+
+	err := bitcoinChain.BroadcastTransaction(previousTransaction)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The pending transaction points to an output that does not exist
+	// in the previous transaction.
+	pendingTransaction := &bitcoin.Transaction{
+		Version: 1,
+		Inputs: []*bitcoin.TransactionInput{
+			{
+				Outpoint: &bitcoin.TransactionOutpoint{
+					TransactionHash: previousTransaction.Hash(),
+					OutputIndex:     1,
+				},
+				Sequence: 0xffffffff,
+			},
+		},
+		Outputs: []*bitcoin.TransactionOutput{
+			{Value: 9000, PublicKeyScript: []byte{0x00, 0x14}},
+		},
+	}
+
+	_, err = resolvePendingWalletTransaction(
+		[20]byte{},
+		nil,
+		pendingTransaction,
+		localChain,
+		bitcoinChain,
+	)
+	if err == nil {
+		t.Fatal("expected error")
+	}
+}
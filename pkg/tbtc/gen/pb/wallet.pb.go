@@ -83,6 +83,7 @@ type Signer struct {
 	Wallet                  *Wallet `protobuf:"bytes,1,opt,name=wallet,proto3" json:"wallet,omitempty"`
 	SigningGroupMemberIndex uint32  `protobuf:"varint,2,opt,name=signingGroupMemberIndex,proto3" json:"signingGroupMemberIndex,omitempty"`
 	PrivateKeyShare         []byte  `protobuf:"bytes,3,opt,name=privateKeyShare,proto3" json:"privateKeyShare,omitempty"`
+	RefreshEpoch            uint32  `protobuf:"varint,4,opt,name=refreshEpoch,proto3" json:"refreshEpoch,omitempty"`
 }
 
 func (x *Signer) Reset() {
@@ -138,6 +139,13 @@ func (x *Signer) GetPrivateKeyShare() []byte {
 	return nil
 }
 
+func (x *Signer) GetRefreshEpoch() uint32 {
+	if x != nil {
+		return x.RefreshEpoch
+	}
+	return 0
+}
+
 var File_pkg_tbtc_gen_pb_wallet_proto protoreflect.FileDescriptor
 
 var file_pkg_tbtc_gen_pb_wallet_proto_rawDesc = []byte{
@@ -149,7 +157,7 @@ var file_pkg_tbtc_gen_pb_wallet_proto_rawDesc = []byte{
 	0x73, 0x69, 0x67, 0x6e, 0x69, 0x6e, 0x67, 0x47, 0x72, 0x6f, 0x75, 0x70, 0x4f, 0x70, 0x65, 0x72,
 	0x61, 0x74, 0x6f, 0x72, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x09, 0x52, 0x15, 0x73, 0x69, 0x67,
 	0x6e, 0x69, 0x6e, 0x67, 0x47, 0x72, 0x6f, 0x75, 0x70, 0x4f, 0x70, 0x65, 0x72, 0x61, 0x74, 0x6f,
-	0x72, 0x73, 0x22, 0x92, 0x01, 0x0a, 0x06, 0x53, 0x69, 0x67, 0x6e, 0x65, 0x72, 0x12, 0x24, 0x0a,
+	0x72, 0x73, 0x22, 0xb6, 0x01, 0x0a, 0x06, 0x53, 0x69, 0x67, 0x6e, 0x65, 0x72, 0x12, 0x24, 0x0a,
 	0x06, 0x77, 0x61, 0x6c, 0x6c, 0x65, 0x74, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0c, 0x2e,
 	0x74, 0x62, 0x74, 0x63, 0x2e, 0x57, 0x61, 0x6c, 0x6c, 0x65, 0x74, 0x52, 0x06, 0x77, 0x61, 0x6c,
 	0x6c, 0x65, 0x74, 0x12, 0x38, 0x0a, 0x17, 0x73, 0x69, 0x67, 0x6e, 0x69, 0x6e, 0x67, 0x47, 0x72,
@@ -158,8 +166,10 @@ var file_pkg_tbtc_gen_pb_wallet_proto_rawDesc = []byte{
 	0x75, 0x70, 0x4d, 0x65, 0x6d, 0x62, 0x65, 0x72, 0x49, 0x6e, 0x64, 0x65, 0x78, 0x12, 0x28, 0x0a,
 	0x0f, 0x70, 0x72, 0x69, 0x76, 0x61, 0x74, 0x65, 0x4b, 0x65, 0x79, 0x53, 0x68, 0x61, 0x72, 0x65,
 	0x18, 0x03, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x0f, 0x70, 0x72, 0x69, 0x76, 0x61, 0x74, 0x65, 0x4b,
-	0x65, 0x79, 0x53, 0x68, 0x61, 0x72, 0x65, 0x42, 0x06, 0x5a, 0x04, 0x2e, 0x2f, 0x70, 0x62, 0x62,
-	0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+	0x65, 0x79, 0x53, 0x68, 0x61, 0x72, 0x65, 0x12, 0x22, 0x0a, 0x0c, 0x72, 0x65, 0x66, 0x72, 0x65,
+	0x73, 0x68, 0x45, 0x70, 0x6f, 0x63, 0x68, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x0c, 0x72,
+	0x65, 0x66, 0x72, 0x65, 0x73, 0x68, 0x45, 0x70, 0x6f, 0x63, 0x68, 0x42, 0x06, 0x5a, 0x04, 0x2e,
+	0x2f, 0x70, 0x62, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
 }
 
 var (
@@ -60,6 +60,7 @@ type heartbeatSigningExecutor interface {
 		ctx context.Context,
 		message *big.Int,
 		startBlock uint64,
+		actionType WalletActionType,
 	) (*tecdsa.Signature, *signingActivityReport, uint64, error)
 }
 
@@ -93,6 +94,14 @@ type heartbeatAction struct {
 	expiryBlock uint64
 
 	waitForBlockFn waitForBlockFn
+
+	// metrics keeps operational metrics of the executing wallet. It may
+	// be nil.
+	metrics *walletMetrics
+
+	// actionRecorder records the progress of the action in the wallet
+	// action history. It may be nil if the action is not recorded.
+	actionRecorder *walletActionRecorder
 }
 
 func newHeartbeatAction(
@@ -168,10 +177,19 @@ func (ha *heartbeatAction) execute() error {
 	)
 	defer cancelHeartbeatSigningCtx()
 
+	err = ha.actionRecorder.recordSigning([]*big.Int{messageToSign})
+	if err != nil {
+		return fmt.Errorf(
+			"cannot record heartbeat message in action history: [%v]",
+			err,
+		)
+	}
+
 	signature, activityReport, _, err := ha.signingExecutor.sign(
 		heartbeatSigningCtx,
 		messageToSign,
 		ha.startBlock,
+		ActionHeartbeat,
 	)
 	if err != nil {
 		// Do not count this error as heartbeat inactivity failure. If the
@@ -194,6 +212,7 @@ func (ha *heartbeatAction) execute() error {
 
 		// Reset the counter of consecutive heartbeat inactivity failures.
 		ha.failureCounter.reset(walletKey)
+		ha.metrics.recordHeartbeatFailures(walletPublicKey, 0)
 
 		return nil
 	}
@@ -210,6 +229,10 @@ func (ha *heartbeatAction) execute() error {
 
 	// Increment the heartbeat inactivity failure counter.
 	ha.failureCounter.increment(walletKey)
+	ha.metrics.recordHeartbeatFailures(
+		walletPublicKey,
+		ha.failureCounter.get(walletKey),
+	)
 
 	// If the number of consecutive heartbeat inactivity failures does not
 	// exceed the threshold, do not issue an inactivity claim yet.
@@ -73,6 +73,16 @@ func TestHeartbeatAction_HappyPath(t *testing.T) {
 		},
 	)
 
+	persistenceHandle := &mockPersistenceHandle{}
+	history, err := newWalletActionHistory(persistenceHandle)
+	if err != nil {
+		t.Fatal(err)
+	}
+	action.actionRecorder = newWalletActionRecorder(
+		&WalletActionRecord{SigHashes: []string{}},
+		history,
+	)
+
 	err = action.execute()
 	if err != nil {
 		t.Fatal(err)
@@ -90,6 +100,24 @@ func TestHeartbeatAction_HappyPath(t *testing.T) {
 		new(big.Int).SetBytes(sha256d),
 		mockExecutor.requestedMessage,
 	)
+
+	records, err := ReadWalletActionHistory(persistenceHandle)
+	if err != nil {
+		t.Fatal(err)
+	}
+	testutils.AssertIntsEqual(t, "records count", 1, len(records))
+	testutils.AssertStringsEqual(
+		t,
+		"stage",
+		WalletActionStageSigning,
+		records[0].Stage,
+	)
+	testutils.AssertStringsEqual(
+		t,
+		"recorded sighash",
+		"0x"+hex.EncodeToString(sha256d),
+		records[0].SigHashes[0],
+	)
 	testutils.AssertUintsEqual(
 		t,
 		"start block",
@@ -612,6 +640,7 @@ func (mhse *mockHeartbeatSigningExecutor) sign(
 	ctx context.Context,
 	message *big.Int,
 	startBlock uint64,
+	actionType WalletActionType,
 ) (*tecdsa.Signature, *signingActivityReport, uint64, error) {
 	mhse.requestedMessage = message
 	mhse.requestedStartBlock = startBlock
@@ -43,6 +43,13 @@ type inactivityClaimExecutor struct {
 	protocolLatch       *generator.ProtocolLatch
 
 	waitForBlockFn waitForBlockFn
+
+	// metrics keeps operational metrics of the wallet. It may be nil.
+	metrics *walletMetrics
+
+	// observer records inactivity claims instead of submitting them if
+	// the node operates in the observer mode. It is nil otherwise.
+	observer *observerRecorder
 }
 
 func newInactivityClaimExecutor(
@@ -114,6 +121,8 @@ func (ice *inactivityClaimExecutor) claimInactivity(
 		return fmt.Errorf("could not get wallet members info: [%v]", err)
 	}
 
+	ice.metrics.recordInactivityClaim(wallet.publicKey)
+
 	wg := sync.WaitGroup{}
 	wg.Add(len(ice.signers))
 
@@ -235,17 +244,29 @@ func (ice *inactivityClaimExecutor) publishInactivityClaim(
 		dishonestThreshold,
 		membershipValidator,
 		newInactivityClaimSigner(ice.chain),
-		newInactivityClaimSubmitter(
-			inactivityLogger,
-			ice.chain,
-			ice.groupParameters,
-			groupMembers,
-			ice.waitForBlockFn,
-		),
+		ice.newInactivityClaimSubmitter(inactivityLogger, groupMembers),
 		inactivityClaim,
 	)
 }
 
+// newInactivityClaimSubmitter creates an inactivity claim submitter wired
+// with the executor's components.
+func (ice *inactivityClaimExecutor) newInactivityClaimSubmitter(
+	inactivityLogger log.StandardLogger,
+	groupMembers []uint32,
+) *inactivityClaimSubmitter {
+	submitter := newInactivityClaimSubmitter(
+		inactivityLogger,
+		ice.chain,
+		ice.groupParameters,
+		groupMembers,
+		ice.waitForBlockFn,
+	)
+	submitter.observer = ice.observer
+
+	return submitter
+}
+
 func (ice *inactivityClaimExecutor) wallet() wallet {
 	// All signers belong to one wallet. Take that wallet from the
 	// first signer.
@@ -322,6 +343,10 @@ type inactivityClaimSubmitter struct {
 	groupMembers    []uint32
 
 	waitForBlockFn waitForBlockFn
+
+	// observer records the inactivity claim instead of submitting it if
+	// the node operates in the observer mode. It is nil otherwise.
+	observer *observerRecorder
 }
 
 func newInactivityClaimSubmitter(
@@ -436,6 +461,25 @@ func (ics *inactivityClaimSubmitter) SubmitClaim(
 		return nil
 	}
 
+	if ics.observer != nil {
+		ics.observer.record(
+			ObserverRecordInactivityClaimSubmission,
+			map[string]interface{}{
+				"memberIndex":            memberIndex,
+				"wallet":                 fmt.Sprintf("0x%x", walletPublicKeyHash),
+				"nonce":                  inactivityNonce.String(),
+				"inactiveMembersIndexes": claim.InactiveMembersIndexes,
+				"heartbeatFailed":        claim.HeartbeatFailed,
+				"signaturesCount":        len(signatures),
+			},
+		)
+		ics.inactivityLogger.Infof(
+			"[member:%v] observer mode; not submitting inactivity claim",
+			memberIndex,
+		)
+		return nil
+	}
+
 	ics.inactivityLogger.Infof(
 		"[member:%v] submitting inactivity claim with [%v] supporting "+
 			"member signatures",
@@ -172,6 +172,7 @@ func setupInactivityClaimExecutorScenario(t *testing.T) (
 
 	node, err := newNode(
 		groupParameters,
+		mainnetProtocolTimings(),
 		localChain,
 		newLocalBitcoinChain(),
 		localProvider,
@@ -503,6 +504,101 @@ func TestSubmitClaim_MemberSubmitsClaim(t *testing.T) {
 	)
 }
 
+func TestSubmitClaim_ObserverMode(t *testing.T) {
+	testData, err := tecdsatest.LoadPrivateKeyShareTestFixtures(1)
+	if err != nil {
+		t.Fatalf("failed to load test data: [%v]", err)
+	}
+	privateKeyShare := tecdsa.NewPrivateKeyShare(testData[0])
+
+	publicKey := privateKeyShare.PublicKey()
+	walletPublicKeyHash := bitcoin.PublicKeyHash(publicKey)
+	ecdsaWalletID := [32]byte{1, 2, 3}
+
+	chain := Connect()
+
+	chain.setWallet(
+		walletPublicKeyHash,
+		&WalletChainData{
+			EcdsaWalletID: ecdsaWalletID,
+		},
+	)
+
+	groupParameters := &GroupParameters{
+		GroupSize:       5,
+		GroupQuorum:     4,
+		HonestThreshold: 3,
+	}
+
+	groupMembers := []uint32{1, 2, 2, 3, 5}
+
+	inactivityClaimSubmitter := newInactivityClaimSubmitter(
+		&testutils.MockLogger{},
+		chain,
+		groupParameters,
+		groupMembers,
+		testWaitForBlockFn(chain),
+	)
+
+	persistenceHandle := &mockPersistenceHandle{}
+	inactivityClaimSubmitter.observer = newObserverRecorder(persistenceHandle)
+
+	ctx, cancelCtx := context.WithCancel(context.Background())
+	defer cancelCtx()
+
+	memberIndex := group.MemberIndex(1)
+
+	claim := inactivity.NewClaimPreimage(
+		big.NewInt(0),
+		publicKey,
+		[]group.MemberIndex{11, 22, 33},
+		true,
+	)
+
+	signatures := map[group.MemberIndex][]byte{
+		1: []byte("signature 1"),
+		2: []byte("signature 2"),
+		3: []byte("signature 3"),
+		4: []byte("signature 4"),
+	}
+
+	err = inactivityClaimSubmitter.SubmitClaim(
+		ctx,
+		memberIndex,
+		claim,
+		signatures,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The nonce is not bumped as the claim is not submitted.
+	nonce, err := chain.GetInactivityClaimNonce(ecdsaWalletID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testutils.AssertBigIntsEqual(
+		t,
+		"inactivity nonce",
+		big.NewInt(0),
+		nonce,
+	)
+
+	testutils.AssertIntsEqual(
+		t,
+		"saved records",
+		1,
+		len(persistenceHandle.saved),
+	)
+	testutils.AssertStringsEqual(
+		t,
+		"record kind",
+		ObserverRecordInactivityClaimSubmission,
+		inactivityClaimSubmitter.observer.latestRecords[0].Kind,
+	)
+}
+
 func TestSubmitClaim_AnotherMemberSubmitsClaim(t *testing.T) {
 	testData, err := tecdsatest.LoadPrivateKeyShareTestFixtures(1)
 	if err != nil {
@@ -0,0 +1,452 @@
+package tbtc
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"sync"
+
+	"github.com/ipfs/go-log/v2"
+	"github.com/keep-network/keep-core/pkg/bitcoin"
+	"github.com/keep-network/keep-core/pkg/generator"
+	"github.com/keep-network/keep-core/pkg/net"
+	"github.com/keep-network/keep-core/pkg/protocol/announcer"
+	"github.com/keep-network/keep-core/pkg/protocol/group"
+	"github.com/keep-network/keep-core/pkg/tecdsa/refresh"
+	"go.uber.org/zap"
+	"golang.org/x/sync/semaphore"
+)
+
+const (
+	// keyRefreshProposalValidityBlocks determines the wallet key refresh
+	// proposal validity time expressed in blocks. In other words, this is the
+	// worst-case time for a wallet key refresh during which the wallet is busy
+	// and cannot take another actions. The value of 300 blocks is roughly
+	// 1 hour, assuming 12 seconds per block.
+	keyRefreshProposalValidityBlocks = 300
+	// keyRefreshTimeoutSafetyMarginBlocks determines the duration of the
+	// safety margin that must be preserved between the timeout of the key
+	// refresh protocol and the timeout of the entire key refresh action.
+	// This safety margin leaves time for persisting refreshed key shares
+	// before another action can be requested by the coordinator. The value
+	// of 25 blocks is roughly 5 minutes, assuming 12 seconds per block.
+	keyRefreshTimeoutSafetyMarginBlocks = 25
+)
+
+// KeyRefreshProposal represents a proposal to refresh key shares of all
+// wallet signing group members without changing the wallet public key.
+type KeyRefreshProposal struct {
+	// Nonce makes the proposed key refresh session unique.
+	Nonce [16]byte
+}
+
+func (krp *KeyRefreshProposal) ActionType() WalletActionType {
+	return ActionKeyRefresh
+}
+
+func (krp *KeyRefreshProposal) ValidityBlocks() uint64 {
+	return keyRefreshProposalValidityBlocks
+}
+
+// ValidateKeyRefreshProposal checks whether the given key refresh proposal
+// can be executed by the given wallet. Key shares can be refreshed only
+// for live wallets and only if key refresh is enabled.
+func ValidateKeyRefreshProposal(
+	walletPublicKeyHash [20]byte,
+	proposal *KeyRefreshProposal,
+	keyRefreshEnabled bool,
+	bridgeChain BridgeChain,
+) error {
+	if !keyRefreshEnabled {
+		return fmt.Errorf("key refresh is disabled")
+	}
+
+	walletChainData, err := bridgeChain.GetWallet(walletPublicKeyHash)
+	if err != nil {
+		return fmt.Errorf("cannot get wallet's chain data: [%v]", err)
+	}
+
+	if walletChainData.State != StateLive {
+		return fmt.Errorf(
+			"wallet is in the [%v] state; only live wallets can "+
+				"refresh key shares",
+			walletChainData.State,
+		)
+	}
+
+	return nil
+}
+
+// errKeyRefreshExecutorBusy is an error returned when the key refresh executor
+// cannot execute the requested key refresh due to an ongoing one.
+var errKeyRefreshExecutorBusy = fmt.Errorf("key refresh executor is busy")
+
+// keyRefreshExecutor is a component responsible for executing the key refresh
+// of a specific wallet whose part is controlled by this node.
+type keyRefreshExecutor struct {
+	lock *semaphore.Weighted
+
+	signers             []*signer
+	broadcastChannel    net.BroadcastChannel
+	membershipValidator *group.MembershipValidator
+	groupParameters     *GroupParameters
+	protocolLatch       *generator.ProtocolLatch
+
+	// getCurrentBlockFn is a function used to get the current block.
+	getCurrentBlockFn getCurrentBlockFn
+	// waitForBlockFn is a function used to wait for the given block.
+	waitForBlockFn waitForBlockFn
+
+	// observer records key refreshes the node would have participated in if
+	// the node operates in the observer mode. It is nil otherwise.
+	observer *observerRecorder
+}
+
+func newKeyRefreshExecutor(
+	signers []*signer,
+	broadcastChannel net.BroadcastChannel,
+	membershipValidator *group.MembershipValidator,
+	groupParameters *GroupParameters,
+	protocolLatch *generator.ProtocolLatch,
+	getCurrentBlockFn getCurrentBlockFn,
+	waitForBlockFn waitForBlockFn,
+) *keyRefreshExecutor {
+	return &keyRefreshExecutor{
+		lock:                semaphore.NewWeighted(1),
+		signers:             signers,
+		broadcastChannel:    broadcastChannel,
+		membershipValidator: membershipValidator,
+		groupParameters:     groupParameters,
+		protocolLatch:       protocolLatch,
+		getCurrentBlockFn:   getCurrentBlockFn,
+		waitForBlockFn:      waitForBlockFn,
+	}
+}
+
+// refresh performs the key refresh process for all signers controlled by the
+// executor. The process is triggered according to the given start block and
+// is retried until it succeeds or the ctx parameter is done. Returns signers
+// holding refreshed key shares. An error is returned if any of the signers
+// could not refresh its key share; in that case, no signer is returned and
+// the current key shares must be kept.
+//
+// Note that the key refresh requires all members of the signing group to
+// participate. A member that fails at the very end of a successful attempt,
+// e.g. does not receive some confirmations before the attempt timeout, ends
+// up with a key share incompatible with the rest of the group. To minimize
+// that risk, successful members keep retransmitting their messages until the
+// attempt timeout.
+func (kre *keyRefreshExecutor) refresh(
+	ctx context.Context,
+	seed *big.Int,
+	startBlock uint64,
+) ([]*signer, error) {
+	if lockAcquired := kre.lock.TryAcquire(1); !lockAcquired {
+		return nil, errKeyRefreshExecutorBusy
+	}
+	defer kre.lock.Release(1)
+
+	wallet := kre.wallet()
+
+	walletPublicKeyBytes, err := marshalPublicKey(wallet.publicKey)
+	if err != nil {
+		return nil, fmt.Errorf("cannot marshal wallet public key: [%v]", err)
+	}
+
+	keyRefreshLogger := logger.With(
+		zap.String("wallet", fmt.Sprintf("0x%x", walletPublicKeyBytes)),
+		zap.String("seed", fmt.Sprintf("0x%x", seed)),
+		zap.Uint64("keyRefreshStartBlock", startBlock),
+	)
+
+	if kre.observer != nil {
+		kre.observer.record(
+			ObserverRecordKeyRefresh,
+			map[string]interface{}{
+				"wallet":     fmt.Sprintf("0x%x", walletPublicKeyBytes),
+				"seed":       fmt.Sprintf("0x%x", seed),
+				"startBlock": startBlock,
+			},
+		)
+		keyRefreshLogger.Infof("observer mode; not refreshing key shares")
+		return nil, errObserverMode
+	}
+
+	wg := sync.WaitGroup{}
+	wg.Add(len(kre.signers))
+	refreshedSignersChan := make(chan *signer, len(kre.signers))
+
+	for _, currentSigner := range kre.signers {
+		go func(signer *signer) {
+			kre.protocolLatch.Lock()
+			defer kre.protocolLatch.Unlock()
+
+			defer wg.Done()
+
+			announcer := announcer.New(
+				fmt.Sprintf("%v-%v", ProtocolName, "refresh"),
+				kre.broadcastChannel,
+				kre.membershipValidator,
+			)
+
+			retryLoop := newKeyRefreshRetryLoop(
+				keyRefreshLogger,
+				seed,
+				startBlock,
+				signer.signingGroupMemberIndex,
+				wallet.groupSize(),
+				signer.refreshEpoch,
+				announcer,
+			)
+
+			// Set up the loop context. It gets canceled once the loop
+			// fails or once the successful attempt times out. In the latter
+			// case, the context is not canceled immediately so the member
+			// keeps retransmitting its protocol messages to the slower
+			// members until the timeout of the successful attempt.
+			loopCtx, cancelLoopCtx := context.WithCancel(ctx)
+
+			loopResult, err := retryLoop.start(
+				loopCtx,
+				kre.waitForBlockFn,
+				kre.getCurrentBlockFn,
+				func(attempt *keyRefreshAttemptParams) (*refresh.Result, error) {
+					keyRefreshAttemptLogger := keyRefreshLogger.With(
+						zap.Uint("attemptNumber", attempt.number),
+						zap.Uint64("attemptStartBlock", attempt.startBlock),
+						zap.Uint64("attemptTimeoutBlock", attempt.timeoutBlock),
+					)
+
+					keyRefreshAttemptLogger.Infof(
+						"[member:%v] starting key refresh protocol "+
+							"with [%v] group members",
+						signer.signingGroupMemberIndex,
+						wallet.groupSize(),
+					)
+
+					// Set up the attempt timeout signal. Just as for signing,
+					// the context is not canceled earlier, even if the
+					// execution succeeded, so all protocol participants,
+					// even the slowest one, have a chance to receive all
+					// messages sent by this member.
+					attemptCtx, _ := withCancelOnBlock(
+						loopCtx,
+						attempt.timeoutBlock,
+						kre.waitForBlockFn,
+					)
+
+					sessionID := refreshEpochSessionID(
+						fmt.Sprintf("%v-%v", seed.Text(16), attempt.number),
+						signer.refreshEpoch,
+					)
+
+					return refresh.Execute(
+						attemptCtx,
+						keyRefreshAttemptLogger,
+						sessionID,
+						signer.signingGroupMemberIndex,
+						signer.privateKeyShare,
+						wallet.groupSize(),
+						wallet.groupDishonestThreshold(
+							kre.groupParameters.HonestThreshold,
+						),
+						kre.broadcastChannel,
+						kre.membershipValidator,
+					)
+				},
+			)
+			if err != nil {
+				cancelLoopCtx()
+
+				keyRefreshLogger.Errorf(
+					"[member:%v] all retries for the key refresh failed; "+
+						"giving up: [%v]",
+					signer.signingGroupMemberIndex,
+					err,
+				)
+
+				return
+			}
+
+			go func() {
+				defer cancelLoopCtx()
+
+				err := kre.waitForBlockFn(
+					loopCtx,
+					loopResult.attemptTimeoutBlock,
+				)
+				if err != nil {
+					keyRefreshLogger.Warnf(
+						"[member:%v] failed waiting for key refresh "+
+							"loop stop signal: [%v]",
+						signer.signingGroupMemberIndex,
+						err,
+					)
+				}
+			}()
+
+			keyRefreshLogger.Infof(
+				"[member:%v] refreshed key share",
+				signer.signingGroupMemberIndex,
+			)
+
+			refreshedSigner := newSigner(
+				wallet.publicKey,
+				wallet.signingGroupOperators,
+				signer.signingGroupMemberIndex,
+				loopResult.result.PrivateKeyShare,
+			)
+			refreshedSigner.refreshEpoch = signer.refreshEpoch + 1
+
+			refreshedSignersChan <- refreshedSigner
+		}(currentSigner)
+	}
+
+	// Wait until all controlled signers complete their key refresh routines,
+	// regardless of their result.
+	wg.Wait()
+	close(refreshedSignersChan)
+
+	refreshedSigners := make([]*signer, 0)
+	for refreshedSigner := range refreshedSignersChan {
+		refreshedSigners = append(refreshedSigners, refreshedSigner)
+	}
+
+	// The refresh is all or nothing for the controlled signers. Replacing
+	// just some of them would leave the node holding key shares of
+	// different epochs for the same wallet.
+	if len(refreshedSigners) < len(kre.signers) {
+		return nil, fmt.Errorf(
+			"refreshed key shares of [%v/%v] controlled signers",
+			len(refreshedSigners),
+			len(kre.signers),
+		)
+	}
+
+	return refreshedSigners, nil
+}
+
+func (kre *keyRefreshExecutor) wallet() wallet {
+	// All signers belong to one wallet. Take that wallet from the
+	// first signer.
+	return kre.signers[0].wallet
+}
+
+// keyRefreshActionExecutor is an interface meant to decouple the specific
+// implementation of the key refresh executor from the key refresh action.
+type keyRefreshActionExecutor interface {
+	refresh(
+		ctx context.Context,
+		seed *big.Int,
+		startBlock uint64,
+	) ([]*signer, error)
+}
+
+// keyRefreshAction is a walletAction implementation handling key refresh
+// requests from the wallet coordinator.
+type keyRefreshAction struct {
+	logger log.StandardLogger
+	chain  Chain
+
+	executingWallet    wallet
+	keyRefreshExecutor keyRefreshActionExecutor
+
+	proposal *KeyRefreshProposal
+
+	// keyRefreshEnabled determines whether the node takes part in key
+	// refreshes.
+	keyRefreshEnabled bool
+
+	// replaceSignersFn is a function used to replace signers of the
+	// executing wallet with their refreshed counterparts.
+	replaceSignersFn func(refreshedSigners []*signer) error
+
+	startBlock  uint64
+	expiryBlock uint64
+
+	waitForBlockFn waitForBlockFn
+}
+
+func newKeyRefreshAction(
+	logger log.StandardLogger,
+	chain Chain,
+	executingWallet wallet,
+	keyRefreshExecutor keyRefreshActionExecutor,
+	proposal *KeyRefreshProposal,
+	keyRefreshEnabled bool,
+	replaceSignersFn func(refreshedSigners []*signer) error,
+	startBlock uint64,
+	expiryBlock uint64,
+	waitForBlockFn waitForBlockFn,
+) *keyRefreshAction {
+	return &keyRefreshAction{
+		logger:             logger,
+		chain:              chain,
+		executingWallet:    executingWallet,
+		keyRefreshExecutor: keyRefreshExecutor,
+		proposal:           proposal,
+		keyRefreshEnabled:  keyRefreshEnabled,
+		replaceSignersFn:   replaceSignersFn,
+		startBlock:         startBlock,
+		expiryBlock:        expiryBlock,
+		waitForBlockFn:     waitForBlockFn,
+	}
+}
+
+func (kra *keyRefreshAction) execute() error {
+	walletPublicKeyHash := bitcoin.PublicKeyHash(kra.wallet().publicKey)
+
+	err := ValidateKeyRefreshProposal(
+		walletPublicKeyHash,
+		kra.proposal,
+		kra.keyRefreshEnabled,
+		kra.chain,
+	)
+	if err != nil {
+		return fmt.Errorf("key refresh proposal is invalid: [%v]", err)
+	}
+
+	// Just in case. This should never happen.
+	if kra.expiryBlock < keyRefreshTimeoutSafetyMarginBlocks {
+		return fmt.Errorf("invalid proposal expiry block")
+	}
+
+	// The context is deliberately not canceled once the key refresh
+	// completes. Members that completed the key refresh must keep
+	// retransmitting their protocol messages until the successful attempt
+	// times out, so the slower members can complete it as well.
+	keyRefreshCtx, _ := withCancelOnBlock(
+		context.Background(),
+		kra.expiryBlock-keyRefreshTimeoutSafetyMarginBlocks,
+		kra.waitForBlockFn,
+	)
+
+	refreshedSigners, err := kra.keyRefreshExecutor.refresh(
+		keyRefreshCtx,
+		new(big.Int).SetBytes(kra.proposal.Nonce[:]),
+		kra.startBlock,
+	)
+	if err != nil {
+		return fmt.Errorf("key refresh process errored out: [%v]", err)
+	}
+
+	err = kra.replaceSignersFn(refreshedSigners)
+	if err != nil {
+		return fmt.Errorf("cannot replace signers: [%v]", err)
+	}
+
+	kra.logger.Infof(
+		"refreshed key shares of [%v] signers",
+		len(refreshedSigners),
+	)
+
+	return nil
+}
+
+func (kra *keyRefreshAction) wallet() wallet {
+	return kra.executingWallet
+}
+
+func (kra *keyRefreshAction) actionType() WalletActionType {
+	return ActionKeyRefresh
+}
@@ -0,0 +1,269 @@
+package tbtc
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	"github.com/ipfs/go-log/v2"
+	"github.com/keep-network/keep-core/pkg/protocol/announcer"
+	"github.com/keep-network/keep-core/pkg/protocol/group"
+	"github.com/keep-network/keep-core/pkg/tecdsa/refresh"
+)
+
+const (
+	// keyRefreshAttemptAnnouncementDelayBlocks determines the duration of the
+	// announcement phase delay that is preserved before starting the
+	// announcement phase.
+	keyRefreshAttemptAnnouncementDelayBlocks = 1
+	// keyRefreshAttemptAnnouncementActiveBlocks determines the duration of
+	// the announcement phase that is performed at the beginning of each key
+	// refresh attempt.
+	keyRefreshAttemptAnnouncementActiveBlocks = 5
+	// keyRefreshAttemptMaximumProtocolBlocks determines the maximum block
+	// duration of the actual protocol computations. The key refresh protocol
+	// is much lighter than signing so the signing duration is more than
+	// enough.
+	keyRefreshAttemptMaximumProtocolBlocks = 30
+	// keyRefreshAttemptCoolDownBlocks determines the duration of the cool
+	// down period that is preserved between subsequent key refresh attempts.
+	keyRefreshAttemptCoolDownBlocks = 5
+)
+
+// keyRefreshAttemptMaximumBlocks returns the maximum block duration of
+// a single key refresh attempt.
+func keyRefreshAttemptMaximumBlocks() uint {
+	return keyRefreshAttemptAnnouncementDelayBlocks +
+		keyRefreshAttemptAnnouncementActiveBlocks +
+		keyRefreshAttemptMaximumProtocolBlocks +
+		keyRefreshAttemptCoolDownBlocks
+}
+
+// keyRefreshAnnouncer represents a component responsible for exchanging
+// readiness announcements for the given key refresh attempt.
+type keyRefreshAnnouncer interface {
+	Announce(
+		ctx context.Context,
+		memberIndex group.MemberIndex,
+		sessionID string,
+	) ([]group.MemberIndex, error)
+}
+
+// keyRefreshRetryLoop is a struct that encapsulates the key refresh retry
+// logic. In contrast to signing and DKG, the key refresh requires all members
+// of the signing group to participate so an attempt is executed only if all
+// members announced readiness.
+type keyRefreshRetryLoop struct {
+	logger log.StandardLogger
+
+	// seed makes the given key refresh session unique. It never changes.
+	seed *big.Int
+
+	memberIndex group.MemberIndex
+	groupSize   int
+
+	// refreshEpoch is the refresh epoch of the member's key share being
+	// refreshed. Only members holding key shares of the same epoch can
+	// refresh them together.
+	refreshEpoch uint32
+
+	announcer keyRefreshAnnouncer
+
+	attemptCounter    uint
+	attemptStartBlock uint64
+}
+
+func newKeyRefreshRetryLoop(
+	logger log.StandardLogger,
+	seed *big.Int,
+	initialStartBlock uint64,
+	memberIndex group.MemberIndex,
+	groupSize int,
+	refreshEpoch uint32,
+	announcer keyRefreshAnnouncer,
+) *keyRefreshRetryLoop {
+	return &keyRefreshRetryLoop{
+		logger:            logger,
+		seed:              seed,
+		memberIndex:       memberIndex,
+		groupSize:         groupSize,
+		refreshEpoch:      refreshEpoch,
+		announcer:         announcer,
+		attemptCounter:    0,
+		attemptStartBlock: initialStartBlock,
+	}
+}
+
+// keyRefreshAttemptParams represents parameters of a key refresh attempt.
+type keyRefreshAttemptParams struct {
+	number       uint
+	startBlock   uint64
+	timeoutBlock uint64
+}
+
+// keyRefreshAttemptFn represents a function performing a key refresh attempt.
+type keyRefreshAttemptFn func(*keyRefreshAttemptParams) (*refresh.Result, error)
+
+// keyRefreshRetryLoopResult represents the result of the key refresh retry
+// loop.
+type keyRefreshRetryLoopResult struct {
+	// result is the outcome of the key refresh process.
+	result *refresh.Result
+	// attemptTimeoutBlock is the block at which the successful attempt times
+	// out.
+	attemptTimeoutBlock uint64
+}
+
+// start begins the key refresh retry loop using the given key refresh attempt
+// function. The retry loop terminates when the key refresh result is produced
+// or the ctx parameter is done, whatever comes first.
+func (krrl *keyRefreshRetryLoop) start(
+	ctx context.Context,
+	waitForBlockFn waitForBlockFn,
+	getCurrentBlockFn getCurrentBlockFn,
+	keyRefreshAttemptFn keyRefreshAttemptFn,
+) (*keyRefreshRetryLoopResult, error) {
+	for {
+		krrl.attemptCounter++
+
+		// Check the loop stop signal.
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+
+		// Just as for signing, assume the worst case that each previous
+		// attempt failed at the end of the protocol and preserve an
+		// additional cool down between attempts.
+		if krrl.attemptCounter > 1 {
+			krrl.attemptStartBlock = krrl.attemptStartBlock +
+				uint64(keyRefreshAttemptMaximumBlocks())
+		}
+
+		krrl.logger.Infof(
+			"[member:%v] waiting for attempt [%v] start signal",
+			krrl.memberIndex,
+			krrl.attemptCounter,
+		)
+
+		announcementStartBlock := krrl.attemptStartBlock +
+			keyRefreshAttemptAnnouncementDelayBlocks
+		announcementEndBlock := announcementStartBlock +
+			keyRefreshAttemptAnnouncementActiveBlocks
+
+		currentBlock, err := getCurrentBlockFn()
+		if err != nil {
+			krrl.logger.Errorf(
+				"[member:%v] failed to get the current block for attempt [%v]: "+
+					"[%v]; starting next attempt",
+				krrl.memberIndex,
+				krrl.attemptCounter,
+				err,
+			)
+			continue
+		}
+
+		if announcementEndBlock <= currentBlock {
+			krrl.logger.Infof(
+				"[member:%v] skipping attempt [%v]; the current block is [%v] "+
+					"and the end block [%v] for the announcement phase is in the past",
+				krrl.memberIndex,
+				krrl.attemptCounter,
+				currentBlock,
+				announcementEndBlock,
+			)
+			continue
+		}
+
+		err = waitForBlockFn(ctx, announcementStartBlock)
+		if err != nil {
+			krrl.logger.Errorf(
+				"[member:%v] failed waiting for announcement start "+
+					"block [%v] for attempt [%v]: [%v]; starting next attempt",
+				krrl.memberIndex,
+				announcementStartBlock,
+				krrl.attemptCounter,
+				err,
+			)
+			continue
+		}
+
+		// Set up the announcement phase stop signal.
+		announceCtx, _ := withCancelOnBlock(ctx, announcementEndBlock, waitForBlockFn)
+
+		krrl.logger.Infof(
+			"[member:%v] starting announcement phase for attempt [%v]",
+			krrl.memberIndex,
+			krrl.attemptCounter,
+		)
+
+		readyMembersIndexes, err := krrl.announcer.Announce(
+			announceCtx,
+			krrl.memberIndex,
+			refreshEpochSessionID(
+				fmt.Sprintf("%v-%v", krrl.seed, krrl.attemptCounter),
+				krrl.refreshEpoch,
+			),
+		)
+		if err != nil {
+			krrl.logger.Warnf(
+				"[member:%v] announcement for attempt [%v] "+
+					"failed: [%v]; starting next attempt",
+				krrl.memberIndex,
+				krrl.attemptCounter,
+				err,
+			)
+			continue
+		}
+
+		// Check the loop stop signal again. The announcement took some time
+		// and the context may be done now.
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+
+		if len(readyMembersIndexes) < krrl.groupSize {
+			krrl.logger.Warnf(
+				"[member:%v] completed announcement phase for attempt [%v] "+
+					"with [%v] members ready to refresh; all members must "+
+					"be ready; following members are not ready: [%v]; "+
+					"moving to the next attempt",
+				krrl.memberIndex,
+				krrl.attemptCounter,
+				len(readyMembersIndexes),
+				announcer.UnreadyMembers(readyMembersIndexes, krrl.groupSize),
+			)
+			continue
+		}
+
+		krrl.logger.Infof(
+			"[member:%v] completed announcement phase for attempt [%v] "+
+				"with all [%v] members ready to refresh",
+			krrl.memberIndex,
+			krrl.attemptCounter,
+			len(readyMembersIndexes),
+		)
+
+		timeoutBlock := announcementEndBlock + keyRefreshAttemptMaximumProtocolBlocks
+
+		result, err := keyRefreshAttemptFn(&keyRefreshAttemptParams{
+			number:       krrl.attemptCounter,
+			startBlock:   announcementEndBlock,
+			timeoutBlock: timeoutBlock,
+		})
+		if err != nil {
+			krrl.logger.Warnf(
+				"[member:%v] failed attempt [%v]: [%v]; "+
+					"starting next attempt",
+				krrl.memberIndex,
+				krrl.attemptCounter,
+				err,
+			)
+			continue
+		}
+
+		return &keyRefreshRetryLoopResult{
+			result:              result,
+			attemptTimeoutBlock: timeoutBlock,
+		}, nil
+	}
+}
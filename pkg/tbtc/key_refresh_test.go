@@ -0,0 +1,585 @@
+package tbtc
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/keep-network/keep-core/internal/testutils"
+	"github.com/keep-network/keep-core/pkg/bitcoin"
+	"github.com/keep-network/keep-core/pkg/chain"
+	"github.com/keep-network/keep-core/pkg/chain/local_v1"
+	"github.com/keep-network/keep-core/pkg/generator"
+	"github.com/keep-network/keep-core/pkg/internal/tecdsatest"
+	"github.com/keep-network/keep-core/pkg/net/local"
+	"github.com/keep-network/keep-core/pkg/operator"
+	"github.com/keep-network/keep-core/pkg/protocol/group"
+	"github.com/keep-network/keep-core/pkg/tecdsa"
+)
+
+func TestKeyRefreshExecutor_Refresh(t *testing.T) {
+	executor, node := setupKeyRefreshExecutor(t)
+
+	ctx, cancelCtx := context.WithCancel(context.Background())
+	defer cancelCtx()
+
+	seed := big.NewInt(100)
+	startBlock := uint64(0)
+
+	refreshedSigners, err := executor.refresh(ctx, seed, startBlock)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testutils.AssertIntsEqual(
+		t,
+		"refreshed signers count",
+		len(executor.signers),
+		len(refreshedSigners),
+	)
+
+	walletPublicKey := executor.wallet().publicKey
+
+	originalSigners := make(map[group.MemberIndex]*signer)
+	for _, originalSigner := range executor.signers {
+		originalSigners[originalSigner.signingGroupMemberIndex] = originalSigner
+	}
+
+	for _, refreshedSigner := range refreshedSigners {
+		memberIndex := refreshedSigner.signingGroupMemberIndex
+
+		originalSigner, ok := originalSigners[memberIndex]
+		if !ok {
+			t.Fatalf("unexpected member index: [%v]", memberIndex)
+		}
+
+		if !refreshedSigner.wallet.publicKey.Equal(walletPublicKey) {
+			t.Errorf(
+				"unexpected wallet public key of member [%v]",
+				memberIndex,
+			)
+		}
+
+		if !refreshedSigner.privateKeyShare.PublicKey().Equal(walletPublicKey) {
+			t.Errorf(
+				"unexpected private key share public key of member [%v]",
+				memberIndex,
+			)
+		}
+
+		if refreshedSigner.privateKeyShare.Data().LocalPreParams.P == nil {
+			t.Errorf("missing pre-parameters of member [%v]", memberIndex)
+		}
+
+		if refreshedSigner.privateKeyShare.Data().Xi.Cmp(
+			originalSigner.privateKeyShare.Data().Xi,
+		) == 0 {
+			t.Errorf("key share of member [%v] not refreshed", memberIndex)
+		}
+
+		testutils.AssertIntsEqual(
+			t,
+			fmt.Sprintf("refresh epoch of member [%v]", memberIndex),
+			int(originalSigner.refreshEpoch+1),
+			int(refreshedSigner.refreshEpoch),
+		)
+	}
+
+	err = node.replaceSigners(walletPublicKey, refreshedSigners)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The signing executor is created from scratch using the refreshed
+	// signers. Make sure they are still able to produce a valid signature
+	// for the unchanged wallet public key.
+	signingExecutor, ok, err := node.getSigningExecutor(walletPublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !ok {
+		t.Fatal("node is supposed to control wallet signers")
+	}
+
+	// Test block counter is much quicker than the real world one.
+	// Set more attempts to give more time for computations.
+	signingExecutor.signingAttemptsLimit *= 8
+
+	message := big.NewInt(200)
+
+	signature, _, _, err := signingExecutor.sign(
+		ctx,
+		message,
+		0,
+		ActionHeartbeat,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !ecdsa.Verify(
+		walletPublicKey,
+		message.Bytes(),
+		signature.R,
+		signature.S,
+	) {
+		t.Errorf("invalid signature: [%+v]", signature)
+	}
+}
+
+func TestKeyRefreshExecutor_Refresh_MemberFailedAfterShareExchange(t *testing.T) {
+	executor, node := setupKeyRefreshExecutor(t)
+
+	ctx, cancelCtx := context.WithCancel(context.Background())
+	defer cancelCtx()
+
+	seed := big.NewInt(100)
+	startBlock := uint64(0)
+
+	refreshedSigners, err := executor.refresh(ctx, seed, startBlock)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	walletPublicKey := executor.wallet().publicKey
+
+	// Simulate the last member failed after the share exchange, for example
+	// while persisting its refreshed key share. That member keeps its
+	// original key share while all other members switched to the refreshed
+	// ones.
+	failedMemberIndex := group.MemberIndex(len(executor.signers))
+
+	var staleSigner *signer
+	for _, originalSigner := range executor.signers {
+		if originalSigner.signingGroupMemberIndex == failedMemberIndex {
+			staleSigner = originalSigner
+		}
+	}
+
+	var upToDateSigners []*signer
+	for _, refreshedSigner := range refreshedSigners {
+		if refreshedSigner.signingGroupMemberIndex != failedMemberIndex {
+			upToDateSigners = append(upToDateSigners, refreshedSigner)
+		}
+	}
+
+	nodeSigningExecutor, ok, err := node.getSigningExecutor(walletPublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !ok {
+		t.Fatal("node is supposed to control wallet signers")
+	}
+
+	newExecutor := func(signers []*signer) *signingExecutor {
+		executor := newSigningExecutor(
+			signers,
+			nodeSigningExecutor.broadcastChannel,
+			nodeSigningExecutor.membershipValidator,
+			nodeSigningExecutor.groupParameters,
+			nodeSigningExecutor.timings,
+			nodeSigningExecutor.protocolLatch,
+			nodeSigningExecutor.getCurrentBlockFn,
+			nodeSigningExecutor.waitForBlockFn,
+		)
+		// Test block counter is much quicker than the real world one.
+		// Set more attempts to give more time for computations.
+		executor.signingAttemptsLimit *= 8
+		return executor
+	}
+
+	upToDateExecutor := newExecutor(upToDateSigners)
+	staleExecutor := newExecutor([]*signer{staleSigner})
+
+	message := big.NewInt(200)
+
+	// The stale member takes part in signing as well. It can never gather
+	// enough members ready to sign so its signing is canceled once the
+	// up-to-date members produce the signature.
+	staleCtx, cancelStaleCtx := context.WithCancel(ctx)
+	staleErrChan := make(chan error, 1)
+	go func() {
+		_, _, _, err := staleExecutor.sign(
+			staleCtx,
+			message,
+			0,
+			ActionHeartbeat,
+		)
+		staleErrChan <- err
+	}()
+
+	signature, activityReport, _, err := upToDateExecutor.sign(
+		ctx,
+		message,
+		0,
+		ActionHeartbeat,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cancelStaleCtx()
+	if err := <-staleErrChan; err == nil {
+		t.Errorf("expected signing failure of the stale member")
+	}
+
+	if !ecdsa.Verify(
+		walletPublicKey,
+		message.Bytes(),
+		signature.R,
+		signature.S,
+	) {
+		t.Errorf("invalid signature: [%+v]", signature)
+	}
+
+	// The stale member announced readiness for a different refresh epoch
+	// so it must not be considered ready by the up-to-date members.
+	if !reflect.DeepEqual(
+		[]group.MemberIndex{failedMemberIndex},
+		activityReport.inactiveMembers,
+	) {
+		t.Errorf(
+			"unexpected inactive members\nexpected: [%v]\nactual:   [%v]",
+			[]group.MemberIndex{failedMemberIndex},
+			activityReport.inactiveMembers,
+		)
+	}
+}
+
+func TestKeyRefreshExecutor_Refresh_Busy(t *testing.T) {
+	executor, _ := setupKeyRefreshExecutor(t)
+
+	ctx, cancelCtx := context.WithCancel(context.Background())
+	defer cancelCtx()
+
+	seed := big.NewInt(100)
+	startBlock := uint64(0)
+
+	errChan := make(chan error, 1)
+	go func() {
+		_, err := executor.refresh(ctx, seed, startBlock)
+		errChan <- err
+	}()
+
+	time.Sleep(100 * time.Millisecond)
+
+	_, err := executor.refresh(ctx, seed, startBlock)
+	testutils.AssertErrorsSame(t, errKeyRefreshExecutorBusy, err)
+
+	err = <-errChan
+	if err != nil {
+		t.Errorf("unexpected error: [%v]", err)
+	}
+}
+
+func TestKeyRefreshExecutor_Refresh_ObserverMode(t *testing.T) {
+	executor, _ := setupKeyRefreshExecutor(t)
+
+	persistenceHandle := &mockPersistenceHandle{}
+	executor.observer = newObserverRecorder(persistenceHandle)
+
+	ctx, cancelCtx := context.WithCancel(context.Background())
+	defer cancelCtx()
+
+	seed := big.NewInt(100)
+	startBlock := uint64(0)
+
+	_, err := executor.refresh(ctx, seed, startBlock)
+	testutils.AssertErrorsSame(t, errObserverMode, err)
+
+	testutils.AssertIntsEqual(
+		t,
+		"saved records",
+		1,
+		len(persistenceHandle.saved),
+	)
+	testutils.AssertStringsEqual(
+		t,
+		"recorded seed",
+		"0x64",
+		executor.observer.latestRecords[0].Details["seed"].(string),
+	)
+}
+
+func TestKeyRefreshAction_Execute(t *testing.T) {
+	walletPublicKeyHex, err := hex.DecodeString(
+		"0471e30bca60f6548d7b42582a478ea37ada63b402af7b3ddd57f0c95bb6843175" +
+			"aa0d2053a91a050a6797d85c38f2909cb7027f2344a01986aa2f9f8ca7a0c289",
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	walletPublicKey := unmarshalPublicKey(walletPublicKeyHex)
+	walletPublicKeyHash := bitcoin.PublicKeyHash(walletPublicKey)
+
+	startBlock := uint64(10)
+	expiryBlock := startBlock + keyRefreshProposalValidityBlocks
+
+	proposal := &KeyRefreshProposal{
+		Nonce: [16]byte{
+			0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+			0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x01, 0x00,
+		},
+	}
+
+	refreshedSigners := []*signer{{signingGroupMemberIndex: 1}}
+
+	var tests = map[string]struct {
+		walletState          WalletState
+		keyRefreshDisabled   bool
+		executorShouldFail   bool
+		replaceShouldFail    bool
+		expectedExecutorCall bool
+		expectedReplaceCall  bool
+		expectedErr          error
+	}{
+		"happy path": {
+			walletState:          StateLive,
+			expectedExecutorCall: true,
+			expectedReplaceCall:  true,
+			expectedErr:          nil,
+		},
+		"key refresh disabled": {
+			walletState:          StateLive,
+			keyRefreshDisabled:   true,
+			expectedExecutorCall: false,
+			expectedReplaceCall:  false,
+			expectedErr: fmt.Errorf(
+				"key refresh proposal is invalid: [key refresh is disabled]",
+			),
+		},
+		"wallet not live": {
+			walletState:          StateMovingFunds,
+			expectedExecutorCall: false,
+			expectedReplaceCall:  false,
+			expectedErr: fmt.Errorf(
+				"key refresh proposal is invalid: [wallet is in the " +
+					"[MovingFunds] state; only live wallets can refresh " +
+					"key shares]",
+			),
+		},
+		"key refresh failed": {
+			walletState:          StateLive,
+			executorShouldFail:   true,
+			expectedExecutorCall: true,
+			expectedReplaceCall:  false,
+			expectedErr: fmt.Errorf(
+				"key refresh process errored out: [oofta]",
+			),
+		},
+		"signers replacement failed": {
+			walletState:          StateLive,
+			replaceShouldFail:    true,
+			expectedExecutorCall: true,
+			expectedReplaceCall:  true,
+			expectedErr: fmt.Errorf(
+				"cannot replace signers: [oofta]",
+			),
+		},
+	}
+
+	for testName, test := range tests {
+		t.Run(testName, func(t *testing.T) {
+			hostChain := Connect()
+			hostChain.setWallet(
+				walletPublicKeyHash,
+				&WalletChainData{
+					State: test.walletState,
+				},
+			)
+
+			mockExecutor := &mockKeyRefreshExecutor{
+				shouldFail:       test.executorShouldFail,
+				refreshedSigners: refreshedSigners,
+			}
+
+			var replacedSigners []*signer
+			replaceSignersFn := func(signers []*signer) error {
+				replacedSigners = signers
+
+				if test.replaceShouldFail {
+					return fmt.Errorf("oofta")
+				}
+
+				return nil
+			}
+
+			action := newKeyRefreshAction(
+				logger,
+				hostChain,
+				wallet{
+					publicKey: walletPublicKey,
+				},
+				mockExecutor,
+				proposal,
+				!test.keyRefreshDisabled,
+				replaceSignersFn,
+				startBlock,
+				expiryBlock,
+				func(ctx context.Context, blockHeight uint64) error {
+					return nil
+				},
+			)
+
+			err := action.execute()
+			if !reflect.DeepEqual(test.expectedErr, err) {
+				t.Errorf(
+					"unexpected error\nexpected: [%v]\nactual:   [%v]",
+					test.expectedErr,
+					err,
+				)
+			}
+
+			if test.expectedExecutorCall {
+				testutils.AssertBigIntsEqual(
+					t,
+					"key refresh seed",
+					big.NewInt(256),
+					mockExecutor.requestedSeed,
+				)
+				testutils.AssertUintsEqual(
+					t,
+					"start block",
+					startBlock,
+					mockExecutor.requestedStartBlock,
+				)
+			} else if mockExecutor.requestedSeed != nil {
+				t.Errorf("unexpected key refresh executor call")
+			}
+
+			if test.expectedReplaceCall {
+				testutils.AssertIntsEqual(
+					t,
+					"replaced signers count",
+					len(refreshedSigners),
+					len(replacedSigners),
+				)
+			} else if replacedSigners != nil {
+				t.Errorf("unexpected signers replacement")
+			}
+		})
+	}
+}
+
+type mockKeyRefreshExecutor struct {
+	shouldFail       bool
+	refreshedSigners []*signer
+
+	requestedSeed       *big.Int
+	requestedStartBlock uint64
+}
+
+func (mkre *mockKeyRefreshExecutor) refresh(
+	ctx context.Context,
+	seed *big.Int,
+	startBlock uint64,
+) ([]*signer, error) {
+	mkre.requestedSeed = seed
+	mkre.requestedStartBlock = startBlock
+
+	if mkre.shouldFail {
+		return nil, fmt.Errorf("oofta")
+	}
+
+	return mkre.refreshedSigners, nil
+}
+
+func setupKeyRefreshExecutor(t *testing.T) (*keyRefreshExecutor, *node) {
+	groupParameters := &GroupParameters{
+		GroupSize:       5,
+		GroupQuorum:     4,
+		HonestThreshold: 3,
+	}
+
+	operatorPrivateKey, operatorPublicKey, err := operator.GenerateKeyPair(
+		local_v1.DefaultCurve,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	localChain := ConnectWithKey(operatorPrivateKey)
+
+	localProvider := local.ConnectWithKey(operatorPublicKey)
+
+	operatorAddress, err := localChain.Signing().PublicKeyToAddress(
+		operatorPublicKey,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var operators []chain.Address
+	for i := 0; i < groupParameters.GroupSize; i++ {
+		operators = append(operators, operatorAddress)
+	}
+
+	testData, err := tecdsatest.LoadPrivateKeyShareTestFixtures(
+		groupParameters.GroupSize,
+	)
+	if err != nil {
+		t.Fatalf("failed to load test data: [%v]", err)
+	}
+
+	signers := make([]*signer, len(testData))
+	for i := range testData {
+		privateKeyShare := tecdsa.NewPrivateKeyShare(testData[i])
+
+		signers[i] = &signer{
+			wallet: wallet{
+				publicKey:             privateKeyShare.PublicKey(),
+				signingGroupOperators: operators,
+			},
+			signingGroupMemberIndex: group.MemberIndex(i + 1),
+			privateKeyShare:         privateKeyShare,
+		}
+	}
+
+	walletPublicKeyHash := bitcoin.PublicKeyHash(signers[0].wallet.publicKey)
+	walletID, err := localChain.CalculateWalletID(signers[0].wallet.publicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	localChain.setWallet(
+		walletPublicKeyHash,
+		&WalletChainData{
+			EcdsaWalletID: walletID,
+			State:         StateLive,
+		},
+	)
+
+	keyStorePersistence := createMockKeyStorePersistence(t, signers...)
+
+	node, err := newNode(
+		groupParameters,
+		mainnetProtocolTimings(),
+		localChain,
+		newLocalBitcoinChain(),
+		localProvider,
+		keyStorePersistence,
+		&mockPersistenceHandle{},
+		generator.StartScheduler(),
+		&mockCoordinationProposalGenerator{},
+		Config{},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	executor, ok, err := node.getKeyRefreshExecutor(signers[0].wallet.publicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !ok {
+		t.Fatal("node is supposed to control wallet signers")
+	}
+
+	return executor, node
+}
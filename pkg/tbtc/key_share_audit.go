@@ -0,0 +1,393 @@
+package tbtc
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/keep-network/keep-core/internal/hexutils"
+	"github.com/keep-network/keep-core/pkg/bitcoin"
+	"github.com/keep-network/keep-core/pkg/chain"
+	"github.com/keep-network/keep-core/pkg/clientinfo"
+	"github.com/keep-network/keep-core/pkg/protocol/group"
+	"github.com/keep-network/keep-core/pkg/tecdsa"
+)
+
+// Kinds of key share audit findings.
+const (
+	// keyShareAuditUnarchivedShare denotes key shares held for a wallet that
+	// is already closed or terminated on the chain but was never archived.
+	keyShareAuditUnarchivedShare = "unarchived_share"
+	// keyShareAuditMissingShare denotes a wallet where the operator is
+	// a signing group member but the key shares for some of the operator's
+	// seats do not exist locally.
+	keyShareAuditMissingShare = "missing_share"
+	// keyShareAuditDuplicatedMemberIndex denotes a wallet where more than
+	// one local key share is held for the same signing group member index.
+	keyShareAuditDuplicatedMemberIndex = "duplicated_member_index"
+)
+
+// keyShareAuditFinding is a single inconsistency between key shares held
+// by the node and the chain state.
+type keyShareAuditFinding struct {
+	// Kind is the kind of the finding.
+	Kind string `json:"kind"`
+	// WalletPublicKeyHash is the hex-encoded public key hash of the wallet
+	// the finding refers to.
+	WalletPublicKeyHash string `json:"walletPublicKeyHash"`
+	// MemberIndexes are the signing group member indexes the finding refers
+	// to.
+	MemberIndexes []int `json:"memberIndexes"`
+	// WalletState is the on-chain state of the wallet.
+	WalletState string `json:"walletState,omitempty"`
+
+	walletPublicKeyHash [20]byte
+}
+
+// keyShareAuditor compares key shares held by the node with the wallets
+// registered on the chain. The audit is meant to be executed upon the client
+// start so that lost or stale key shares are caught before they lead to
+// heartbeat failures and, eventually, to inactivity claims.
+type keyShareAuditor struct {
+	chain          Chain
+	walletRegistry *walletRegistry
+	operatorIDFn   func() (chain.OperatorID, error)
+}
+
+// audit performs the key share audit and returns the findings. The findings
+// are sorted by the wallet public key hash and the finding kind.
+func (ksa *keyShareAuditor) audit() ([]*keyShareAuditFinding, error) {
+	findings := make([]*keyShareAuditFinding, 0)
+
+	localMemberIndexes := make(map[[20]byte]map[group.MemberIndex]int)
+	for _, walletPublicKey := range ksa.walletRegistry.getWalletsPublicKeys() {
+		walletPublicKeyHash := bitcoin.PublicKeyHash(walletPublicKey)
+
+		memberIndexes := make(map[group.MemberIndex]int)
+		for _, signer := range ksa.walletRegistry.getSigners(walletPublicKey) {
+			memberIndexes[signer.signingGroupMemberIndex]++
+		}
+		localMemberIndexes[walletPublicKeyHash] = memberIndexes
+
+		duplicatedMemberIndexes := make([]group.MemberIndex, 0)
+		for memberIndex, count := range memberIndexes {
+			if count > 1 {
+				duplicatedMemberIndexes = append(
+					duplicatedMemberIndexes,
+					memberIndex,
+				)
+			}
+		}
+
+		if len(duplicatedMemberIndexes) > 0 {
+			findings = append(findings, newKeyShareAuditFinding(
+				keyShareAuditDuplicatedMemberIndex,
+				walletPublicKeyHash,
+				duplicatedMemberIndexes,
+				"",
+			))
+		}
+	}
+
+	registeredWallets, err := ksa.chain.PastNewWalletRegisteredEvents(nil)
+	if err != nil {
+		return nil, fmt.Errorf(
+			"cannot get past new wallet registered events: [%v]",
+			err,
+		)
+	}
+
+	memberIndexes, err := ksa.operatorMemberIndexes()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, event := range registeredWallets {
+		walletPublicKeyHash := event.WalletPublicKeyHash
+
+		local, isLocal := localMemberIndexes[walletPublicKeyHash]
+		expected, isMember := memberIndexes[walletPublicKeyHash]
+
+		if !isLocal && !isMember {
+			continue
+		}
+
+		walletChainData, err := ksa.chain.GetWallet(walletPublicKeyHash)
+		if err != nil {
+			return nil, fmt.Errorf(
+				"cannot get on-chain data of wallet [0x%x]: [%v]",
+				walletPublicKeyHash,
+				err,
+			)
+		}
+
+		if walletChainData.State == StateClosed ||
+			walletChainData.State == StateTerminated {
+			if isLocal {
+				findings = append(findings, newKeyShareAuditFinding(
+					keyShareAuditUnarchivedShare,
+					walletPublicKeyHash,
+					sortedMemberIndexes(local),
+					walletChainData.State.String(),
+				))
+			}
+
+			continue
+		}
+
+		missingMemberIndexes := make([]group.MemberIndex, 0)
+		for _, memberIndex := range expected {
+			if _, ok := local[memberIndex]; !ok {
+				missingMemberIndexes = append(missingMemberIndexes, memberIndex)
+			}
+		}
+
+		if len(missingMemberIndexes) > 0 {
+			findings = append(findings, newKeyShareAuditFinding(
+				keyShareAuditMissingShare,
+				walletPublicKeyHash,
+				missingMemberIndexes,
+				walletChainData.State.String(),
+			))
+		}
+	}
+
+	sort.SliceStable(findings, func(i, j int) bool {
+		if findings[i].WalletPublicKeyHash != findings[j].WalletPublicKeyHash {
+			return findings[i].WalletPublicKeyHash < findings[j].WalletPublicKeyHash
+		}
+		return findings[i].Kind < findings[j].Kind
+	})
+
+	return findings, nil
+}
+
+// operatorMemberIndexes determines the final signing group member indexes
+// of the operator in all wallets created so far, based on the submitted DKG
+// results. The returned map is keyed by the wallet public key hash. If the
+// operator ID cannot be determined, e.g. because the operator has never
+// joined the sortition pool, an empty map is returned.
+func (ksa *keyShareAuditor) operatorMemberIndexes() (
+	map[[20]byte][]group.MemberIndex,
+	error,
+) {
+	memberIndexes := make(map[[20]byte][]group.MemberIndex)
+
+	operatorID, err := ksa.operatorIDFn()
+	if err != nil {
+		logger.Warnf(
+			"cannot determine operator ID; skipping the check of "+
+				"missing key shares: [%v]",
+			err,
+		)
+		return memberIndexes, nil
+	}
+
+	events, err := ksa.chain.PastDKGResultSubmittedEvents(nil)
+	if err != nil {
+		return nil, fmt.Errorf(
+			"cannot get past DKG result submitted events: [%v]",
+			err,
+		)
+	}
+
+	// Events are sorted by the block number so the latest result submitted
+	// for the given group public key takes precedence.
+	for _, event := range events {
+		groupPublicKey, err := unmarshalDKGResultGroupPublicKey(
+			event.Result.GroupPublicKey,
+		)
+		if err != nil {
+			logger.Warnf(
+				"skipping DKG result [0x%x] with unexpected group "+
+					"public key: [%v]",
+				event.ResultHash,
+				err,
+			)
+			continue
+		}
+
+		walletPublicKeyHash := bitcoin.PublicKeyHash(groupPublicKey)
+
+		operatorMemberIndexes := finalOperatorMemberIndexes(
+			event.Result,
+			operatorID,
+		)
+		if len(operatorMemberIndexes) > 0 {
+			memberIndexes[walletPublicKeyHash] = operatorMemberIndexes
+		} else {
+			delete(memberIndexes, walletPublicKeyHash)
+		}
+	}
+
+	return memberIndexes, nil
+}
+
+// finalOperatorMemberIndexes returns the member indexes of the given operator
+// in the final signing group determined by the given DKG result. Members
+// marked as misbehaved are excluded from the final signing group and the
+// remaining members are re-indexed the same way as upon signer registration.
+func finalOperatorMemberIndexes(
+	result *DKGChainResult,
+	operatorID chain.OperatorID,
+) []group.MemberIndex {
+	misbehaved := make(map[group.MemberIndex]bool)
+	for _, memberIndex := range result.MisbehavedMembersIndexes {
+		misbehaved[memberIndex] = true
+	}
+
+	memberIndexes := make([]group.MemberIndex, 0)
+	finalMemberIndex := group.MemberIndex(0)
+	for i, memberOperatorID := range result.Members {
+		if misbehaved[group.MemberIndex(i+1)] {
+			continue
+		}
+
+		finalMemberIndex++
+
+		if memberOperatorID == operatorID {
+			memberIndexes = append(memberIndexes, finalMemberIndex)
+		}
+	}
+
+	return memberIndexes
+}
+
+// unmarshalDKGResultGroupPublicKey converts the group public key of a DKG
+// chain result to an ECDSA public key. The chain may hold the key either in
+// the uncompressed form or as the plain concatenation of X and Y coordinates.
+func unmarshalDKGResultGroupPublicKey(bytes []byte) (*ecdsa.PublicKey, error) {
+	if len(bytes) == 64 {
+		bytes = append([]byte{0x04}, bytes...)
+	}
+
+	x, y := elliptic.Unmarshal(tecdsa.Curve, bytes)
+	if x == nil {
+		return nil, fmt.Errorf("invalid public key")
+	}
+
+	return &ecdsa.PublicKey{
+		Curve: tecdsa.Curve,
+		X:     x,
+		Y:     y,
+	}, nil
+}
+
+func newKeyShareAuditFinding(
+	kind string,
+	walletPublicKeyHash [20]byte,
+	memberIndexes []group.MemberIndex,
+	walletState string,
+) *keyShareAuditFinding {
+	sort.Slice(memberIndexes, func(i, j int) bool {
+		return memberIndexes[i] < memberIndexes[j]
+	})
+
+	indexes := make([]int, len(memberIndexes))
+	for i, memberIndex := range memberIndexes {
+		indexes[i] = int(memberIndex)
+	}
+
+	return &keyShareAuditFinding{
+		Kind:                kind,
+		WalletPublicKeyHash: hexutils.Encode(walletPublicKeyHash[:]),
+		MemberIndexes:       indexes,
+		WalletState:         walletState,
+		walletPublicKeyHash: walletPublicKeyHash,
+	}
+}
+
+func sortedMemberIndexes(
+	memberIndexes map[group.MemberIndex]int,
+) []group.MemberIndex {
+	result := make([]group.MemberIndex, 0, len(memberIndexes))
+	for memberIndex := range memberIndexes {
+		result = append(result, memberIndex)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i] < result[j]
+	})
+
+	return result
+}
+
+// keyShareAuditReport holds the outcome of the latest key share audit.
+type keyShareAuditReport struct {
+	mutex     sync.Mutex
+	findings  []*keyShareAuditFinding
+	err       error
+	auditedAt time.Time
+}
+
+// findingsCount returns the number of findings of the latest audit.
+func (ksar *keyShareAuditReport) findingsCount() int {
+	ksar.mutex.Lock()
+	defer ksar.mutex.Unlock()
+
+	return len(ksar.findings)
+}
+
+// diagnostics returns the diagnostic information about the latest key share
+// audit.
+func (ksar *keyShareAuditReport) diagnostics() clientinfo.ApplicationInfo {
+	ksar.mutex.Lock()
+	defer ksar.mutex.Unlock()
+
+	info := clientinfo.ApplicationInfo{
+		"audited_at": ksar.auditedAt.Unix(),
+		"findings":   ksar.findings,
+	}
+
+	if ksar.err != nil {
+		info["error"] = ksar.err.Error()
+	}
+
+	return info
+}
+
+// auditKeyShares runs the key share audit, logs its findings and records
+// them in wallet metrics. Failure of the audit does not prevent the node
+// from working and is only reported.
+func (n *node) auditKeyShares() *keyShareAuditReport {
+	auditor := &keyShareAuditor{
+		chain:          n.chain,
+		walletRegistry: n.walletRegistry,
+		operatorIDFn:   n.operatorID,
+	}
+
+	findings, err := auditor.audit()
+
+	report := &keyShareAuditReport{
+		findings:  findings,
+		err:       err,
+		auditedAt: time.Now(),
+	}
+
+	if err != nil {
+		logger.Errorf("key share audit failed: [%v]", err)
+		return report
+	}
+
+	for _, finding := range findings {
+		logger.Warnf(
+			"key share audit finding [%s] for wallet [%s] "+
+				"and member indexes %v",
+			finding.Kind,
+			finding.WalletPublicKeyHash,
+			finding.MemberIndexes,
+		)
+
+		n.walletMetrics.recordKeyShareAuditFinding(finding)
+	}
+
+	logger.Infof(
+		"key share audit completed with [%v] finding(s)",
+		len(findings),
+	)
+
+	return report
+}
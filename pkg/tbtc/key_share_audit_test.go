@@ -0,0 +1,275 @@
+package tbtc
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/keep-network/keep-core/internal/testutils"
+	"github.com/keep-network/keep-core/pkg/bitcoin"
+	"github.com/keep-network/keep-core/pkg/chain"
+	"github.com/keep-network/keep-core/pkg/protocol/group"
+)
+
+func TestKeyShareAuditor_Audit(t *testing.T) {
+	localChain := Connect()
+	persistenceHandle := &mockPersistenceHandle{}
+	storage := newWalletStorage(persistenceHandle)
+
+	saveSigner := func(
+		walletPublicKey *ecdsa.PublicKey,
+		memberIndex group.MemberIndex,
+	) {
+		signer := createMockSigner(t)
+		signer.wallet.publicKey = walletPublicKey
+		signer.signingGroupMemberIndex = memberIndex
+
+		if err := storage.saveSigner(signer); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	registerWallet := func(
+		walletPublicKey *ecdsa.PublicKey,
+		state WalletState,
+	) {
+		walletID, err := localChain.CalculateWalletID(walletPublicKey)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		walletPublicKeyHash := bitcoin.PublicKeyHash(walletPublicKey)
+
+		localChain.setWallet(walletPublicKeyHash, &WalletChainData{
+			EcdsaWalletID: walletID,
+			State:         state,
+		})
+		localChain.addPastNewWalletRegisteredEvent(&NewWalletRegisteredEvent{
+			EcdsaWalletID:       walletID,
+			WalletPublicKeyHash: walletPublicKeyHash,
+		})
+	}
+
+	submitResult := func(
+		groupPublicKey []byte,
+		members chain.OperatorIDs,
+		misbehavedMembersIndexes []group.MemberIndex,
+	) {
+		localChain.addPastDKGResultSubmittedEvent(&DKGResultSubmittedEvent{
+			Result: &DKGChainResult{
+				GroupPublicKey:           groupPublicKey,
+				MisbehavedMembersIndexes: misbehavedMembersIndexes,
+				Members:                  members,
+			},
+		})
+	}
+
+	uncompressed := func(publicKey *ecdsa.PublicKey) []byte {
+		return elliptic.Marshal(publicKey.Curve, publicKey.X, publicKey.Y)
+	}
+
+	// The live wallet has a duplicated share for seat 1 and lacks the share
+	// for seat 4. Seat numbering takes the misbehaved member into account.
+	liveWalletKey := createMockSigner(t).wallet.publicKey
+	saveSigner(liveWalletKey, 1)
+	saveSigner(liveWalletKey, 1)
+	saveSigner(liveWalletKey, 2)
+	registerWallet(liveWalletKey, StateLive)
+	submitResult(
+		uncompressed(liveWalletKey),
+		chain.OperatorIDs{localChainOperatorID, 7, localChainOperatorID, 9, localChainOperatorID},
+		[]group.MemberIndex{2},
+	)
+
+	// The closed wallet was never archived.
+	closedWalletKey := &newDepositRefundTestKey(t).PublicKey
+	saveSigner(closedWalletKey, 3)
+	registerWallet(closedWalletKey, StateClosed)
+
+	// The operator is a member of this wallet but has no shares at all.
+	// The group public key is held in the 64-byte chain format.
+	lostWalletKey := &newDepositRefundTestKey(t).PublicKey
+	registerWallet(lostWalletKey, StateMovingFunds)
+	submitResult(
+		uncompressed(lostWalletKey)[1:],
+		chain.OperatorIDs{8, localChainOperatorID, 9},
+		nil,
+	)
+
+	// The operator is not a member of this wallet.
+	foreignWalletKey := &newDepositRefundTestKey(t).PublicKey
+	registerWallet(foreignWalletKey, StateLive)
+	submitResult(
+		uncompressed(foreignWalletKey),
+		chain.OperatorIDs{7, 8, 9},
+		nil,
+	)
+
+	walletRegistry, err := newWalletRegistry(
+		persistenceHandle,
+		localChain.CalculateWalletID,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	auditor := &keyShareAuditor{
+		chain:          localChain,
+		walletRegistry: walletRegistry,
+		operatorIDFn: func() (chain.OperatorID, error) {
+			return localChainOperatorID, nil
+		},
+	}
+
+	findings, err := auditor.audit()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expectedFindings := map[string]string{
+		fmt.Sprintf(
+			"%s|%s",
+			keyShareAuditDuplicatedMemberIndex,
+			encodePublicKeyHash(liveWalletKey),
+		): "[1]",
+		fmt.Sprintf(
+			"%s|%s",
+			keyShareAuditMissingShare,
+			encodePublicKeyHash(liveWalletKey),
+		): "[4]",
+		fmt.Sprintf(
+			"%s|%s",
+			keyShareAuditUnarchivedShare,
+			encodePublicKeyHash(closedWalletKey),
+		): "[3]",
+		fmt.Sprintf(
+			"%s|%s",
+			keyShareAuditMissingShare,
+			encodePublicKeyHash(lostWalletKey),
+		): "[2]",
+	}
+
+	testutils.AssertIntsEqual(
+		t,
+		"findings count",
+		len(expectedFindings),
+		len(findings),
+	)
+
+	for _, finding := range findings {
+		key := fmt.Sprintf("%s|%s", finding.Kind, finding.WalletPublicKeyHash)
+
+		expectedMemberIndexes, ok := expectedFindings[key]
+		if !ok {
+			t.Errorf("unexpected finding [%s]", key)
+			continue
+		}
+
+		memberIndexes, err := json.Marshal(finding.MemberIndexes)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		testutils.AssertStringsEqual(
+			t,
+			fmt.Sprintf("member indexes of finding [%s]", key),
+			expectedMemberIndexes,
+			string(memberIndexes),
+		)
+	}
+}
+
+func TestKeyShareAuditor_Audit_UnknownOperator(t *testing.T) {
+	localChain := Connect()
+	persistenceHandle := &mockPersistenceHandle{}
+
+	walletKey := createMockSigner(t).wallet.publicKey
+	walletID, err := localChain.CalculateWalletID(walletKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	localChain.setWallet(bitcoin.PublicKeyHash(walletKey), &WalletChainData{
+		EcdsaWalletID: walletID,
+		State:         StateLive,
+	})
+	localChain.addPastNewWalletRegisteredEvent(&NewWalletRegisteredEvent{
+		EcdsaWalletID:       walletID,
+		WalletPublicKeyHash: bitcoin.PublicKeyHash(walletKey),
+	})
+
+	walletRegistry, err := newWalletRegistry(
+		persistenceHandle,
+		localChain.CalculateWalletID,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	auditor := &keyShareAuditor{
+		chain:          localChain,
+		walletRegistry: walletRegistry,
+		operatorIDFn: func() (chain.OperatorID, error) {
+			return 0, fmt.Errorf("operator not in the pool")
+		},
+	}
+
+	findings, err := auditor.audit()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testutils.AssertIntsEqual(t, "findings count", 0, len(findings))
+}
+
+func TestKeyShareAuditReport_Diagnostics(t *testing.T) {
+	walletPublicKeyHash := [20]byte{0x01}
+
+	report := &keyShareAuditReport{
+		findings: []*keyShareAuditFinding{
+			newKeyShareAuditFinding(
+				keyShareAuditMissingShare,
+				walletPublicKeyHash,
+				[]group.MemberIndex{5, 2},
+				StateLive.String(),
+			),
+		},
+	}
+
+	testutils.AssertIntsEqual(t, "findings count", 1, report.findingsCount())
+
+	diagnostics, err := json.Marshal(report.diagnostics()["findings"])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testutils.AssertStringsEqual(
+		t,
+		"findings",
+		"[{\"kind\":\"missing_share\","+
+			"\"walletPublicKeyHash\":\"0x0100000000000000000000000000000000000000\","+
+			"\"memberIndexes\":[2,5],\"walletState\":\"Live\"}]",
+		string(diagnostics),
+	)
+
+	metrics := newWalletMetrics()
+	metrics.recordKeyShareAuditFinding(report.findings[0])
+
+	testutils.AssertIntsEqual(
+		t,
+		"metric value",
+		2,
+		int(metrics.get(
+			walletKeyShareAuditFindingsMetricName,
+			walletPublicKeyHash,
+			walletMetricLabel{walletMetricsFindingLabel, keyShareAuditMissingShare},
+		)),
+	)
+}
+
+func encodePublicKeyHash(publicKey *ecdsa.PublicKey) string {
+	walletPublicKeyHash := bitcoin.PublicKeyHash(publicKey)
+	return fmt.Sprintf("0x%x", walletPublicKeyHash)
+}
@@ -0,0 +1,605 @@
+package tbtc
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+	"time"
+
+	"golang.org/x/crypto/scrypt"
+
+	"github.com/keep-network/keep-common/pkg/encryption"
+	"github.com/keep-network/keep-common/pkg/persistence"
+
+	"github.com/keep-network/keep-core/pkg/bitcoin"
+)
+
+// KeyStoreBundleVersion is the version of the key store bundle format
+// produced by ExportKeyStoreBundle.
+const KeyStoreBundleVersion = 1
+
+const (
+	// keyStoreBundleKDF is the name of the key derivation function used to
+	// derive the bundle encryption key from the passphrase.
+	keyStoreBundleKDF = "scrypt"
+	// keyStoreBundleSaltLength is the byte length of the key derivation salt.
+	keyStoreBundleSaltLength = 32
+)
+
+// Parameters of the scrypt key derivation function used for new bundles.
+// Parameters used to produce a bundle are stored in the bundle so they
+// can be changed without breaking existing bundles.
+var (
+	keyStoreBundleScryptN = 1 << 15
+	keyStoreBundleScryptR = 8
+	keyStoreBundleScryptP = 1
+)
+
+// Bounds of the scrypt key derivation function parameters accepted from
+// a bundle. Parameters are read from the bundle file so they must be
+// bounded to prevent a malformed bundle from exhausting memory or CPU.
+// The memory required by scrypt is roughly 128 * N * r bytes so the upper
+// bounds allow up to 2 GiB.
+const (
+	keyStoreBundleScryptMinN = 1 << 15
+	keyStoreBundleScryptMaxN = 1 << 20
+	keyStoreBundleScryptMaxR = 16
+	keyStoreBundleScryptMaxP = 4
+)
+
+// keyStoreBundleEnvelope is the outer, unencrypted part of the key store
+// bundle. It holds everything needed to decrypt the bundle given the
+// passphrase.
+type keyStoreBundleEnvelope struct {
+	Version    int    `json:"version"`
+	KDF        string `json:"kdf"`
+	Salt       []byte `json:"salt"`
+	N          int    `json:"n"`
+	R          int    `json:"r"`
+	P          int    `json:"p"`
+	Ciphertext []byte `json:"ciphertext"`
+}
+
+// keyStoreBundlePayload is the encrypted part of the key store bundle.
+type keyStoreBundlePayload struct {
+	// CreatedAt is the UNIX timestamp of the bundle creation.
+	CreatedAt int64 `json:"createdAt"`
+	// Shares holds the marshaled signers.
+	Shares [][]byte `json:"shares"`
+}
+
+// KeyStoreShare is a single signer key share held in the key store or in
+// a key store bundle.
+type KeyStoreShare struct {
+	signer *signer
+}
+
+// newKeyStoreShare unmarshals the key share from the given content.
+func newKeyStoreShare(content []byte) (*KeyStoreShare, error) {
+	signer := &signer{}
+	if err := signer.Unmarshal(content); err != nil {
+		return nil, fmt.Errorf("cannot unmarshal signer: [%v]", err)
+	}
+
+	return &KeyStoreShare{signer}, nil
+}
+
+// WalletPublicKey returns the public key of the wallet the share belongs to.
+func (kss *KeyStoreShare) WalletPublicKey() *ecdsa.PublicKey {
+	return kss.signer.wallet.publicKey
+}
+
+// WalletPublicKeyHash returns the 20-byte public key hash of the wallet
+// the share belongs to.
+func (kss *KeyStoreShare) WalletPublicKeyHash() [20]byte {
+	return bitcoin.PublicKeyHash(kss.signer.wallet.publicKey)
+}
+
+// MemberIndex returns the index of the share's signer in the wallet
+// signing group.
+func (kss *KeyStoreShare) MemberIndex() uint8 {
+	return uint8(kss.signer.signingGroupMemberIndex)
+}
+
+// GroupSize returns the size of the wallet signing group.
+func (kss *KeyStoreShare) GroupSize() int {
+	return kss.signer.wallet.groupSize()
+}
+
+// RefreshEpoch returns the number of key refreshes the share went through.
+// Shares of the same wallet with a lower epoch are stale.
+func (kss *KeyStoreShare) RefreshEpoch() uint32 {
+	return kss.signer.refreshEpoch
+}
+
+// storageKey returns the key identifying the share in the key store.
+func (kss *KeyStoreShare) storageKey() string {
+	return fmt.Sprintf(
+		"%s/%d",
+		getWalletStorageKey(kss.signer.wallet.publicKey),
+		kss.signer.signingGroupMemberIndex,
+	)
+}
+
+// ReadKeyStoreShares reads all active signer key shares from the given key
+// store persistence. Shares of archived wallets are not returned. Returns
+// an error if any of the shares cannot be read or unmarshaled. Shares are
+// ordered by the wallet and the member index.
+func ReadKeyStoreShares(
+	keyStorePersistence persistence.ProtectedHandle,
+) ([]*KeyStoreShare, error) {
+	shares := make([]*KeyStoreShare, 0)
+
+	descriptorsChan, errorsChan := keyStorePersistence.ReadAll()
+
+	// Both channels are unbuffered and must be drained concurrently.
+	var readErrors []error
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for err := range errorsChan {
+			readErrors = append(readErrors, err)
+		}
+	}()
+
+	var contentErr error
+	for descriptor := range descriptorsChan {
+		if contentErr != nil {
+			continue
+		}
+
+		content, err := descriptor.Content()
+		if err != nil {
+			contentErr = fmt.Errorf(
+				"cannot read share [%s] of wallet [%s]: [%v]",
+				descriptor.Name(),
+				descriptor.Directory(),
+				err,
+			)
+			continue
+		}
+
+		share, err := newKeyStoreShare(content)
+		if err != nil {
+			contentErr = fmt.Errorf(
+				"invalid share [%s] of wallet [%s]: [%v]",
+				descriptor.Name(),
+				descriptor.Directory(),
+				err,
+			)
+			continue
+		}
+
+		shares = append(shares, share)
+	}
+
+	wg.Wait()
+
+	if len(readErrors) > 0 {
+		return nil, fmt.Errorf("cannot read key store: [%v]", readErrors[0])
+	}
+	if contentErr != nil {
+		return nil, contentErr
+	}
+
+	sortKeyStoreShares(shares)
+
+	return shares, nil
+}
+
+// ExportKeyStoreBundle produces a versioned bundle holding the given key
+// shares, encrypted with a key derived from the given passphrase.
+func ExportKeyStoreBundle(
+	shares []*KeyStoreShare,
+	passphrase string,
+) ([]byte, error) {
+	if len(passphrase) == 0 {
+		return nil, fmt.Errorf("passphrase must not be empty")
+	}
+
+	if len(shares) == 0 {
+		return nil, fmt.Errorf("no key shares to export")
+	}
+
+	payload := &keyStoreBundlePayload{
+		CreatedAt: time.Now().Unix(),
+		Shares:    make([][]byte, len(shares)),
+	}
+	for i, share := range shares {
+		content, err := share.signer.Marshal()
+		if err != nil {
+			return nil, fmt.Errorf(
+				"cannot marshal share [%s]: [%v]",
+				share.storageKey(),
+				err,
+			)
+		}
+
+		payload.Shares[i] = content
+	}
+
+	payloadBytes, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("cannot marshal bundle payload: [%v]", err)
+	}
+
+	envelope := &keyStoreBundleEnvelope{
+		Version: KeyStoreBundleVersion,
+		KDF:     keyStoreBundleKDF,
+		Salt:    make([]byte, keyStoreBundleSaltLength),
+		N:       keyStoreBundleScryptN,
+		R:       keyStoreBundleScryptR,
+		P:       keyStoreBundleScryptP,
+	}
+
+	if _, err := io.ReadFull(rand.Reader, envelope.Salt); err != nil {
+		return nil, fmt.Errorf("cannot generate salt: [%v]", err)
+	}
+
+	box, err := envelope.box(passphrase)
+	if err != nil {
+		return nil, err
+	}
+
+	envelope.Ciphertext, err = box.Encrypt(payloadBytes)
+	if err != nil {
+		return nil, fmt.Errorf("cannot encrypt bundle payload: [%v]", err)
+	}
+
+	return json.MarshalIndent(envelope, "", "  ")
+}
+
+// OpenKeyStoreBundle decrypts the given key store bundle using the given
+// passphrase and returns the key shares it holds. Returns an error if the
+// bundle version is not supported, the passphrase is wrong, or any of
+// the shares cannot be unmarshaled.
+func OpenKeyStoreBundle(
+	bundle []byte,
+	passphrase string,
+) ([]*KeyStoreShare, error) {
+	envelope := &keyStoreBundleEnvelope{}
+	if err := json.Unmarshal(bundle, envelope); err != nil {
+		return nil, fmt.Errorf("cannot unmarshal bundle: [%v]", err)
+	}
+
+	if envelope.Version != KeyStoreBundleVersion {
+		return nil, fmt.Errorf(
+			"unsupported bundle version [%v]; supported version is [%v]",
+			envelope.Version,
+			KeyStoreBundleVersion,
+		)
+	}
+
+	if envelope.KDF != keyStoreBundleKDF {
+		return nil, fmt.Errorf(
+			"unsupported key derivation function [%v]",
+			envelope.KDF,
+		)
+	}
+
+	box, err := envelope.box(passphrase)
+	if err != nil {
+		return nil, err
+	}
+
+	payloadBytes, err := box.Decrypt(envelope.Ciphertext)
+	if err != nil {
+		return nil, fmt.Errorf(
+			"cannot decrypt bundle; wrong passphrase or corrupted "+
+				"bundle: [%v]",
+			err,
+		)
+	}
+
+	payload := &keyStoreBundlePayload{}
+	if err := json.Unmarshal(payloadBytes, payload); err != nil {
+		return nil, fmt.Errorf("cannot unmarshal bundle payload: [%v]", err)
+	}
+
+	shares := make([]*KeyStoreShare, len(payload.Shares))
+	for i, content := range payload.Shares {
+		share, err := newKeyStoreShare(content)
+		if err != nil {
+			return nil, fmt.Errorf("invalid share [%v]: [%v]", i, err)
+		}
+
+		shares[i] = share
+	}
+
+	sortKeyStoreShares(shares)
+
+	return shares, nil
+}
+
+// box returns the encryption box using a key derived from the given
+// passphrase with the envelope's key derivation parameters. Returns an
+// error if the parameters are out of the accepted bounds.
+func (kbe *keyStoreBundleEnvelope) box(passphrase string) (encryption.Box, error) {
+	if err := kbe.validateKDFParameters(); err != nil {
+		return nil, fmt.Errorf("invalid key derivation parameters: [%v]", err)
+	}
+
+	key, err := scrypt.Key(
+		[]byte(passphrase),
+		kbe.Salt,
+		kbe.N,
+		kbe.R,
+		kbe.P,
+		encryption.KeyLength,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("cannot derive encryption key: [%v]", err)
+	}
+
+	var boxKey [encryption.KeyLength]byte
+	copy(boxKey[:], key)
+
+	return encryption.NewBox(boxKey), nil
+}
+
+// validateKDFParameters checks the envelope's key derivation parameters
+// against fixed bounds before they are used to derive the key.
+func (kbe *keyStoreBundleEnvelope) validateKDFParameters() error {
+	if len(kbe.Salt) != keyStoreBundleSaltLength {
+		return fmt.Errorf(
+			"salt length [%v] is not [%v]",
+			len(kbe.Salt),
+			keyStoreBundleSaltLength,
+		)
+	}
+
+	if kbe.N < keyStoreBundleScryptMinN ||
+		kbe.N > keyStoreBundleScryptMaxN ||
+		kbe.N&(kbe.N-1) != 0 {
+		return fmt.Errorf(
+			"N [%v] is not a power of two in range [%v, %v]",
+			kbe.N,
+			keyStoreBundleScryptMinN,
+			keyStoreBundleScryptMaxN,
+		)
+	}
+
+	if kbe.R < 1 || kbe.R > keyStoreBundleScryptMaxR {
+		return fmt.Errorf(
+			"r [%v] is not in range [1, %v]",
+			kbe.R,
+			keyStoreBundleScryptMaxR,
+		)
+	}
+
+	if kbe.P < 1 || kbe.P > keyStoreBundleScryptMaxP {
+		return fmt.Errorf(
+			"p [%v] is not in range [1, %v]",
+			kbe.P,
+			keyStoreBundleScryptMaxP,
+		)
+	}
+
+	return nil
+}
+
+// ImportKeyStoreShares saves the given key shares in the given key store
+// persistence. Shares already present in the key store are skipped. If the
+// key store holds a different share for the same wallet and member index,
+// or any share of a wallet, either given or held in the key store, is stale
+// according to its refresh epoch, no share is imported and an error is
+// returned. Returns the number of imported and skipped shares. The client
+// must not be running during the import as it loads shares only upon startup.
+func ImportKeyStoreShares(
+	keyStorePersistence persistence.ProtectedHandle,
+	shares []*KeyStoreShare,
+) (int, int, error) {
+	existingShares, err := ReadKeyStoreShares(keyStorePersistence)
+	if err != nil {
+		return 0, 0, fmt.Errorf("cannot read existing shares: [%v]", err)
+	}
+
+	existingContents := make(map[string][]byte)
+	for _, existingShare := range existingShares {
+		content, err := existingShare.signer.Marshal()
+		if err != nil {
+			return 0, 0, fmt.Errorf(
+				"cannot marshal existing share [%s]: [%v]",
+				existingShare.storageKey(),
+				err,
+			)
+		}
+
+		existingContents[existingShare.storageKey()] = content
+	}
+
+	latestEpochs := latestKeyStoreRefreshEpochs(append(existingShares, shares...))
+
+	for _, existingShare := range existingShares {
+		if err := checkKeyStoreShareEpoch(existingShare, latestEpochs); err != nil {
+			return 0, 0, fmt.Errorf("key store holds a stale share: [%v]", err)
+		}
+	}
+
+	toImport := make([]*KeyStoreShare, 0)
+	for _, share := range shares {
+		if err := checkKeyStoreShareEpoch(share, latestEpochs); err != nil {
+			return 0, 0, err
+		}
+
+		content, err := share.signer.Marshal()
+		if err != nil {
+			return 0, 0, fmt.Errorf(
+				"cannot marshal share [%s]: [%v]",
+				share.storageKey(),
+				err,
+			)
+		}
+
+		existingContent, ok := existingContents[share.storageKey()]
+		if !ok {
+			toImport = append(toImport, share)
+			continue
+		}
+
+		if !bytes.Equal(existingContent, content) {
+			return 0, 0, fmt.Errorf(
+				"key store already holds a different share for member [%v] "+
+					"of wallet [0x%x]",
+				share.MemberIndex(),
+				share.WalletPublicKeyHash(),
+			)
+		}
+	}
+
+	storage := newWalletStorage(keyStorePersistence)
+	for i, share := range toImport {
+		if err := storage.saveSigner(share.signer); err != nil {
+			return i, len(shares) - len(toImport), fmt.Errorf(
+				"cannot save share for member [%v] of wallet [0x%x]: [%v]",
+				share.MemberIndex(),
+				share.WalletPublicKeyHash(),
+				err,
+			)
+		}
+	}
+
+	return len(toImport), len(shares) - len(toImport), nil
+}
+
+// KeyStoreVerificationChain is the subset of the chain interface required
+// to verify key shares.
+type KeyStoreVerificationChain interface {
+	// CalculateWalletID calculates the wallet's ECDSA ID based on the provided
+	// wallet public key.
+	CalculateWalletID(walletPublicKey *ecdsa.PublicKey) ([32]byte, error)
+
+	// IsWalletRegistered checks whether the given wallet is registered in the
+	// ECDSA wallet registry.
+	IsWalletRegistered(EcdsaWalletID [32]byte) (bool, error)
+}
+
+// VerifyKeyStoreShare checks that the given key share is consistent, i.e.
+// the public key of the private key share matches the wallet public key and
+// the member index fits the signing group, and that the wallet is registered
+// on chain.
+func VerifyKeyStoreShare(
+	share *KeyStoreShare,
+	chain KeyStoreVerificationChain,
+) error {
+	walletPublicKey := share.WalletPublicKey()
+
+	sharePublicKey := share.signer.privateKeyShare.PublicKey()
+	if sharePublicKey.X.Cmp(walletPublicKey.X) != 0 ||
+		sharePublicKey.Y.Cmp(walletPublicKey.Y) != 0 {
+		return fmt.Errorf(
+			"public key of the private key share does not match " +
+				"the wallet public key",
+		)
+	}
+
+	if share.MemberIndex() < 1 || int(share.MemberIndex()) > share.GroupSize() {
+		return fmt.Errorf(
+			"member index [%v] is out of the signing group range [1, %v]",
+			share.MemberIndex(),
+			share.GroupSize(),
+		)
+	}
+
+	walletID, err := chain.CalculateWalletID(walletPublicKey)
+	if err != nil {
+		return fmt.Errorf("cannot calculate wallet ID: [%v]", err)
+	}
+
+	isRegistered, err := chain.IsWalletRegistered(walletID)
+	if err != nil {
+		return fmt.Errorf(
+			"cannot check if wallet with ID [0x%x] is registered: [%v]",
+			walletID,
+			err,
+		)
+	}
+
+	if !isRegistered {
+		return fmt.Errorf(
+			"wallet with ID [0x%x] is not registered on chain",
+			walletID,
+		)
+	}
+
+	return nil
+}
+
+// VerifyKeyStoreShares verifies each of the given key shares using
+// VerifyKeyStoreShare. Additionally, a share is rejected if it is stale,
+// i.e. another given share of the same wallet has a higher refresh epoch.
+// Returns verification errors in the order of the given shares. The error
+// is nil for shares that passed the verification.
+func VerifyKeyStoreShares(
+	shares []*KeyStoreShare,
+	chain KeyStoreVerificationChain,
+) []error {
+	latestEpochs := latestKeyStoreRefreshEpochs(shares)
+
+	errs := make([]error, len(shares))
+	for i, share := range shares {
+		if err := checkKeyStoreShareEpoch(share, latestEpochs); err != nil {
+			errs[i] = err
+			continue
+		}
+
+		errs[i] = VerifyKeyStoreShare(share, chain)
+	}
+
+	return errs
+}
+
+// latestKeyStoreRefreshEpochs returns the highest refresh epoch of the given
+// shares, per wallet public key hash.
+func latestKeyStoreRefreshEpochs(shares []*KeyStoreShare) map[[20]byte]uint32 {
+	latestEpochs := make(map[[20]byte]uint32)
+	for _, share := range shares {
+		walletPublicKeyHash := share.WalletPublicKeyHash()
+		if share.RefreshEpoch() > latestEpochs[walletPublicKeyHash] {
+			latestEpochs[walletPublicKeyHash] = share.RefreshEpoch()
+		}
+	}
+
+	return latestEpochs
+}
+
+// checkKeyStoreShareEpoch returns an error if the refresh epoch of the given
+// share is lower than the latest known epoch of the share's wallet. Such
+// a share was replaced by a key refresh and cannot be used for signing.
+func checkKeyStoreShareEpoch(
+	share *KeyStoreShare,
+	latestEpochs map[[20]byte]uint32,
+) error {
+	latestEpoch := latestEpochs[share.WalletPublicKeyHash()]
+	if share.RefreshEpoch() < latestEpoch {
+		return fmt.Errorf(
+			"share for member [%v] of wallet [0x%x] is stale; its refresh "+
+				"epoch [%v] is lower than the latest epoch [%v]",
+			share.MemberIndex(),
+			share.WalletPublicKeyHash(),
+			share.RefreshEpoch(),
+			latestEpoch,
+		)
+	}
+
+	return nil
+}
+
+// sortKeyStoreShares sorts the given shares by the wallet public key hash
+// and the member index.
+func sortKeyStoreShares(shares []*KeyStoreShare) {
+	sort.Slice(shares, func(i, j int) bool {
+		iHash, jHash := shares[i].WalletPublicKeyHash(), shares[j].WalletPublicKeyHash()
+		if c := bytes.Compare(iHash[:], jHash[:]); c != 0 {
+			return c < 0
+		}
+
+		return shares[i].MemberIndex() < shares[j].MemberIndex()
+	})
+}
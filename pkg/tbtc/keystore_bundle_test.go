@@ -0,0 +1,403 @@
+package tbtc
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/keep-network/keep-core/internal/testutils"
+	"github.com/keep-network/keep-core/pkg/bitcoin"
+	"github.com/keep-network/keep-core/pkg/chain"
+	"github.com/keep-network/keep-core/pkg/protocol/group"
+)
+
+func TestKeyStoreBundle_ExportOpen(t *testing.T) {
+	firstSigner := createMockSigner(t)
+	secondSigner := createMockSigner(t)
+	secondSigner.signingGroupMemberIndex = group.MemberIndex(2)
+
+	// Shares are deliberately out of order.
+	shares := []*KeyStoreShare{{secondSigner}, {firstSigner}}
+
+	bundle, err := ExportKeyStoreBundle(shares, "passphrase")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	openedShares, err := OpenKeyStoreBundle(bundle, "passphrase")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testutils.AssertIntsEqual(t, "shares count", 2, len(openedShares))
+	for i, expectedSigner := range []*signer{firstSigner, secondSigner} {
+		if !openedShares[i].signer.wallet.publicKey.Equal(
+			expectedSigner.wallet.publicKey,
+		) {
+			t.Errorf("unexpected wallet public key of share [%v]", i)
+		}
+
+		testutils.AssertIntsEqual(
+			t,
+			"member index",
+			int(expectedSigner.signingGroupMemberIndex),
+			int(openedShares[i].MemberIndex()),
+		)
+		testutils.AssertIntsEqual(
+			t,
+			"group size",
+			5,
+			openedShares[i].GroupSize(),
+		)
+	}
+
+	_, err = OpenKeyStoreBundle(bundle, "wrong passphrase")
+	if err == nil {
+		t.Error("expected error for wrong passphrase")
+	}
+
+	envelope := &keyStoreBundleEnvelope{}
+	if err := json.Unmarshal(bundle, envelope); err != nil {
+		t.Fatal(err)
+	}
+	envelope.Version = KeyStoreBundleVersion + 1
+	unsupportedBundle, err := json.Marshal(envelope)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = OpenKeyStoreBundle(unsupportedBundle, "passphrase")
+	testutils.AssertStringsEqual(
+		t,
+		"error",
+		"unsupported bundle version [2]; supported version is [1]",
+		err.Error(),
+	)
+}
+
+func TestKeyStoreBundle_OpenInvalidKDFParameters(t *testing.T) {
+	bundle, err := ExportKeyStoreBundle(
+		[]*KeyStoreShare{{createMockSigner(t)}},
+		"passphrase",
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var tests = map[string]struct {
+		modifyFn      func(envelope *keyStoreBundleEnvelope)
+		expectedError string
+	}{
+		"N too large": {
+			modifyFn: func(envelope *keyStoreBundleEnvelope) {
+				envelope.N = 1 << 21
+			},
+			expectedError: "N [2097152] is not a power of two in range " +
+				"[32768, 1048576]",
+		},
+		"N too small": {
+			modifyFn: func(envelope *keyStoreBundleEnvelope) {
+				envelope.N = 1 << 14
+			},
+			expectedError: "N [16384] is not a power of two in range " +
+				"[32768, 1048576]",
+		},
+		"N not a power of two": {
+			modifyFn: func(envelope *keyStoreBundleEnvelope) {
+				envelope.N = 1<<15 + 1
+			},
+			expectedError: "N [32769] is not a power of two in range " +
+				"[32768, 1048576]",
+		},
+		"r too large": {
+			modifyFn: func(envelope *keyStoreBundleEnvelope) {
+				envelope.R = 1 << 20
+			},
+			expectedError: "r [1048576] is not in range [1, 16]",
+		},
+		"p zero": {
+			modifyFn: func(envelope *keyStoreBundleEnvelope) {
+				envelope.P = 0
+			},
+			expectedError: "p [0] is not in range [1, 4]",
+		},
+		"p too large": {
+			modifyFn: func(envelope *keyStoreBundleEnvelope) {
+				envelope.P = 1 << 10
+			},
+			expectedError: "p [1024] is not in range [1, 4]",
+		},
+		"salt too short": {
+			modifyFn: func(envelope *keyStoreBundleEnvelope) {
+				envelope.Salt = envelope.Salt[:1]
+			},
+			expectedError: "salt length [1] is not [32]",
+		},
+	}
+
+	for testName, test := range tests {
+		t.Run(testName, func(t *testing.T) {
+			envelope := &keyStoreBundleEnvelope{}
+			if err := json.Unmarshal(bundle, envelope); err != nil {
+				t.Fatal(err)
+			}
+
+			test.modifyFn(envelope)
+
+			modifiedBundle, err := json.Marshal(envelope)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			_, err = OpenKeyStoreBundle(modifiedBundle, "passphrase")
+			testutils.AssertStringsEqual(
+				t,
+				"error",
+				fmt.Sprintf(
+					"invalid key derivation parameters: [%v]",
+					test.expectedError,
+				),
+				err.Error(),
+			)
+		})
+	}
+}
+
+func TestKeyStoreBundle_ExportInvalid(t *testing.T) {
+	shares := []*KeyStoreShare{{createMockSigner(t)}}
+
+	if _, err := ExportKeyStoreBundle(shares, ""); err == nil {
+		t.Error("expected error for empty passphrase")
+	}
+
+	if _, err := ExportKeyStoreBundle(nil, "passphrase"); err == nil {
+		t.Error("expected error for no shares")
+	}
+}
+
+func TestReadKeyStoreShares(t *testing.T) {
+	persistenceHandle := &mockPersistenceHandle{}
+	storage := newWalletStorage(persistenceHandle)
+
+	signer := createMockSigner(t)
+	if err := storage.saveSigner(signer); err != nil {
+		t.Fatal(err)
+	}
+
+	shares, err := ReadKeyStoreShares(persistenceHandle)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testutils.AssertIntsEqual(t, "shares count", 1, len(shares))
+	if shares[0].WalletPublicKeyHash() !=
+		bitcoin.PublicKeyHash(signer.wallet.publicKey) {
+		t.Errorf("unexpected wallet public key hash")
+	}
+
+	// A corrupted share must not be silently skipped.
+	persistenceHandle.saved = append(persistenceHandle.saved, &mockDescriptor{
+		name:      "membership_2",
+		directory: getWalletStorageKey(signer.wallet.publicKey),
+		content:   []byte{0x01, 0x02},
+	})
+
+	_, err = ReadKeyStoreShares(persistenceHandle)
+	if err == nil {
+		t.Fatal("expected error for corrupted share")
+	}
+}
+
+func TestImportKeyStoreShares(t *testing.T) {
+	firstSigner := createMockSigner(t)
+	secondSigner := createMockSigner(t)
+	secondSigner.signingGroupMemberIndex = group.MemberIndex(2)
+
+	shares := []*KeyStoreShare{{firstSigner}, {secondSigner}}
+
+	persistenceHandle := &mockPersistenceHandle{}
+
+	imported, skipped, err := ImportKeyStoreShares(persistenceHandle, shares)
+	if err != nil {
+		t.Fatal(err)
+	}
+	testutils.AssertIntsEqual(t, "imported", 2, imported)
+	testutils.AssertIntsEqual(t, "skipped", 0, skipped)
+	testutils.AssertIntsEqual(t, "saved", 2, len(persistenceHandle.saved))
+
+	// Importing the same shares again is a no-op.
+	imported, skipped, err = ImportKeyStoreShares(persistenceHandle, shares)
+	if err != nil {
+		t.Fatal(err)
+	}
+	testutils.AssertIntsEqual(t, "imported", 0, imported)
+	testutils.AssertIntsEqual(t, "skipped", 2, skipped)
+	testutils.AssertIntsEqual(t, "saved", 2, len(persistenceHandle.saved))
+
+	// A different share for an occupied seat must not be imported.
+	conflictingSigner := createMockSigner(t)
+	conflictingSigner.wallet.signingGroupOperators = []chain.Address{
+		"address-1",
+		"address-2",
+		"address-3",
+		"address-4",
+		"address-5",
+	}
+	thirdSigner := createMockSigner(t)
+	thirdSigner.signingGroupMemberIndex = group.MemberIndex(3)
+
+	_, _, err = ImportKeyStoreShares(
+		persistenceHandle,
+		[]*KeyStoreShare{{thirdSigner}, {conflictingSigner}},
+	)
+	if err == nil {
+		t.Fatal("expected error for conflicting share")
+	}
+	testutils.AssertIntsEqual(t, "saved", 2, len(persistenceHandle.saved))
+}
+
+func TestImportKeyStoreShares_StaleShares(t *testing.T) {
+	firstSigner := createMockSigner(t)
+	firstSigner.refreshEpoch = 1
+
+	persistenceHandle := &mockPersistenceHandle{}
+
+	_, _, err := ImportKeyStoreShares(
+		persistenceHandle,
+		[]*KeyStoreShare{{firstSigner}},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The key store holds a share refreshed once so a share of another
+	// member of the same wallet that was never refreshed is stale.
+	staleSigner := createMockSigner(t)
+	staleSigner.signingGroupMemberIndex = group.MemberIndex(2)
+
+	_, _, err = ImportKeyStoreShares(
+		persistenceHandle,
+		[]*KeyStoreShare{{staleSigner}},
+	)
+	testutils.AssertStringsEqual(
+		t,
+		"error",
+		fmt.Sprintf(
+			"share for member [2] of wallet [0x%x] is stale; its refresh "+
+				"epoch [0] is lower than the latest epoch [1]",
+			bitcoin.PublicKeyHash(staleSigner.wallet.publicKey),
+		),
+		err.Error(),
+	)
+
+	// Shares refreshed later than the key store content are not imported
+	// either as the key store would be left with a stale share.
+	refreshedSigner := createMockSigner(t)
+	refreshedSigner.signingGroupMemberIndex = group.MemberIndex(2)
+	refreshedSigner.refreshEpoch = 2
+
+	_, _, err = ImportKeyStoreShares(
+		persistenceHandle,
+		[]*KeyStoreShare{{refreshedSigner}},
+	)
+	if err == nil {
+		t.Fatal("expected error for stale share in the key store")
+	}
+
+	testutils.AssertIntsEqual(t, "saved", 1, len(persistenceHandle.saved))
+}
+
+func TestVerifyKeyStoreShare(t *testing.T) {
+	localChain := Connect()
+
+	signer := createMockSigner(t)
+	share := &KeyStoreShare{signer}
+
+	walletID, err := localChain.CalculateWalletID(signer.wallet.publicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The wallet is not registered yet.
+	err = VerifyKeyStoreShare(share, localChain)
+	if err == nil {
+		t.Fatal("expected error for unregistered wallet")
+	}
+
+	localChain.setWallet(
+		bitcoin.PublicKeyHash(signer.wallet.publicKey),
+		&WalletChainData{
+			EcdsaWalletID: walletID,
+			State:         StateLive,
+		},
+	)
+
+	err = VerifyKeyStoreShare(share, localChain)
+	if err != nil {
+		t.Fatalf("unexpected error: [%v]", err)
+	}
+
+	outOfRangeSigner := createMockSigner(t)
+	outOfRangeSigner.signingGroupMemberIndex = group.MemberIndex(6)
+
+	err = VerifyKeyStoreShare(&KeyStoreShare{outOfRangeSigner}, localChain)
+	testutils.AssertStringsEqual(
+		t,
+		"error",
+		"member index [6] is out of the signing group range [1, 5]",
+		err.Error(),
+	)
+
+	mismatchedSigner := createMockSigner(t)
+	mismatchedSigner.wallet.publicKey = &newDepositRefundTestKey(t).PublicKey
+
+	err = VerifyKeyStoreShare(&KeyStoreShare{mismatchedSigner}, localChain)
+	if err == nil {
+		t.Fatal("expected error for mismatched public key")
+	}
+}
+
+func TestVerifyKeyStoreShares(t *testing.T) {
+	localChain := Connect()
+
+	refreshedSigner := createMockSigner(t)
+	refreshedSigner.refreshEpoch = 1
+
+	staleSigner := createMockSigner(t)
+	staleSigner.signingGroupMemberIndex = group.MemberIndex(2)
+
+	walletID, err := localChain.CalculateWalletID(
+		refreshedSigner.wallet.publicKey,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	localChain.setWallet(
+		bitcoin.PublicKeyHash(refreshedSigner.wallet.publicKey),
+		&WalletChainData{
+			EcdsaWalletID: walletID,
+			State:         StateLive,
+		},
+	)
+
+	errs := VerifyKeyStoreShares(
+		[]*KeyStoreShare{{refreshedSigner}, {staleSigner}},
+		localChain,
+	)
+
+	testutils.AssertIntsEqual(t, "errors count", 2, len(errs))
+	if errs[0] != nil {
+		t.Errorf("unexpected error: [%v]", errs[0])
+	}
+	testutils.AssertStringsEqual(
+		t,
+		"error",
+		fmt.Sprintf(
+			"share for member [2] of wallet [0x%x] is stale; its refresh "+
+				"epoch [0] is lower than the latest epoch [1]",
+			bitcoin.PublicKeyHash(staleSigner.wallet.publicKey),
+		),
+		errs[1].Error(),
+	)
+}
@@ -51,6 +51,7 @@ func (s *signer) Marshal() ([]byte, error) {
 		Wallet:                  pbWallet,
 		SigningGroupMemberIndex: uint32(s.signingGroupMemberIndex),
 		PrivateKeyShare:         privateKeyShare,
+		RefreshEpoch:            s.refreshEpoch,
 	})
 }
 
@@ -83,6 +84,7 @@ func (s *signer) Unmarshal(bytes []byte) error {
 	}
 	s.signingGroupMemberIndex = group.MemberIndex(pbSigner.SigningGroupMemberIndex)
 	s.privateKeyShare = privateKeyShare
+	s.refreshEpoch = pbSigner.RefreshEpoch
 
 	return nil
 }
@@ -232,6 +234,8 @@ func unmarshalCoordinationProposal(actionType uint32, payload []byte) (
 		ActionRedemption:      &RedemptionProposal{},
 		ActionMovingFunds:     &MovingFundsProposal{},
 		ActionMovedFundsSweep: &MovedFundsSweepProposal{},
+		ActionFeeBump:         &FeeBumpProposal{},
+		ActionKeyRefresh:      &KeyRefreshProposal{},
 	}[parsedActionType]
 	if !ok {
 		return nil, fmt.Errorf(
@@ -463,6 +467,63 @@ func (mfsp *MovedFundsSweepProposal) Unmarshal(data []byte) error {
 	return nil
 }
 
+// Marshal converts the feeBumpProposal to a byte array.
+func (fbp *FeeBumpProposal) Marshal() ([]byte, error) {
+	return proto.Marshal(
+		&pb.FeeBumpProposal{
+			TransactionHash: fbp.TransactionHash[:],
+			TxFee:           fbp.TxFee.Bytes(),
+		})
+}
+
+// Unmarshal converts a byte array back to the feeBumpProposal.
+func (fbp *FeeBumpProposal) Unmarshal(data []byte) error {
+	pbMsg := pb.FeeBumpProposal{}
+	if err := proto.Unmarshal(data, &pbMsg); err != nil {
+		return fmt.Errorf("failed to unmarshal FeeBumpProposal: [%v]", err)
+	}
+
+	if len(pbMsg.TransactionHash) != 32 {
+		return fmt.Errorf(
+			"invalid transaction hash length: [%v]",
+			len(pbMsg.TransactionHash),
+		)
+	}
+
+	copy(fbp.TransactionHash[:], pbMsg.TransactionHash)
+	fbp.TxFee = new(big.Int).SetBytes(pbMsg.TxFee)
+
+	return nil
+}
+
+// Marshal converts the keyRefreshProposal to a byte array.
+func (krp *KeyRefreshProposal) Marshal() ([]byte, error) {
+	return proto.Marshal(
+		&pb.KeyRefreshProposal{
+			Nonce: krp.Nonce[:],
+		},
+	)
+}
+
+// Unmarshal converts a byte array back to the keyRefreshProposal.
+func (krp *KeyRefreshProposal) Unmarshal(data []byte) error {
+	pbMsg := pb.KeyRefreshProposal{}
+	if err := proto.Unmarshal(data, &pbMsg); err != nil {
+		return fmt.Errorf("failed to unmarshal KeyRefreshProposal: [%v]", err)
+	}
+
+	if len(pbMsg.Nonce) != 16 {
+		return fmt.Errorf(
+			"invalid key refresh nonce length: [%v]",
+			len(pbMsg.Nonce),
+		)
+	}
+
+	copy(krp.Nonce[:], pbMsg.Nonce)
+
+	return nil
+}
+
 // marshalPublicKey converts an ECDSA public key to a byte
 // array (uncompressed).
 func marshalPublicKey(publicKey *ecdsa.PublicKey) ([]byte, error) {
@@ -20,26 +20,11 @@ import (
 	"github.com/keep-network/keep-core/pkg/protocol/announcer"
 	"github.com/keep-network/keep-core/pkg/protocol/group"
 	"github.com/keep-network/keep-core/pkg/protocol/inactivity"
+	"github.com/keep-network/keep-core/pkg/tecdsa/refresh"
 	"github.com/keep-network/keep-core/pkg/tecdsa/signing"
 )
 
 const (
-	// signingAttemptsLimit determines the maximum number of signing attempts
-	// that can be performed for the given message being subject of signing.
-	//
-	// The value of `5` should be enough to produce the signature even with
-	// `2` malicious members in a signing group of `100` members. To produce
-	// the signature, `51` members must be selected out of the honest `98`.
-	// The probability of successful signing in that case is:
-	// `P = (98 choose 51) / (100 choose 51) = ~0.24` which means we need
-	// `5` attempts on the worst case.
-	//
-	// A greater limit does not necessarily make sense. Presence of more than
-	// `2` malicious members in the signing group has a very small probability.
-	// Moreover, the signature must be produced in the reasonable time.
-	// That being said, the value `5` seems to be reasonable trade-off.
-	signingAttemptsLimit = 5
-
 	// walletClosureConfirmationBlocks determines the period used when waiting
 	// for the wallet closure confirmation. This period ensures the wallet has
 	// been definitely closed and the closing transaction will not be removed by
@@ -52,6 +37,7 @@ const (
 // node represents the current state of an ECDSA node.
 type node struct {
 	groupParameters *GroupParameters
+	timings         *ProtocolTimings
 
 	chain          Chain
 	btcChain       bitcoin.Chain
@@ -99,6 +85,17 @@ type node struct {
 	// wallet.
 	signingExecutors map[string]*signingExecutor
 
+	keyRefreshExecutorsMutex sync.Mutex
+	// keyRefreshExecutors is the cache holding key refresh executors for
+	// specific wallets. The cache key is the uncompressed public key
+	// (with 04 prefix) of the wallet. The keyRefreshExecutor encapsulates
+	// the logic of refreshing wallet key shares.
+	//
+	// keyRefreshExecutors MUST NOT be used outside this struct. Please use
+	// wallet actions and walletDispatcher to execute an action on an existing
+	// wallet.
+	keyRefreshExecutors map[string]*keyRefreshExecutor
+
 	coordinationExecutorsMutex sync.Mutex
 	// coordinationExecutors is the cache holding coordination executors for
 	// specific wallets. The cache key is the uncompressed public key
@@ -111,10 +108,48 @@ type node struct {
 	// proposalGenerator is the implementation of the coordination proposal
 	// generator used by the node.
 	proposalGenerator CoordinationProposalGenerator
+
+	// walletMonitor reconciles Bitcoin transactions of wallets controlled
+	// by the node with coordinated proposals and the Bridge state.
+	walletMonitor *walletMonitor
+
+	// actionHistory is the persistent history of wallet actions executed
+	// by the node.
+	actionHistory *walletActionHistory
+
+	// pendingTransactions keeps signed wallet transactions until they are
+	// confirmed on the Bitcoin chain.
+	pendingTransactions *pendingTransactionsTracker
+
+	// walletMetrics keeps operational metrics of wallets controlled by
+	// the node.
+	walletMetrics *walletMetrics
+
+	// signingPolicy is the operator-defined policy evaluated against wallet
+	// transactions before signing them. It is nil if no policy is set.
+	signingPolicy *signingPolicyEngine
+
+	// observer records operations the node would have performed if it
+	// operates in the observer mode. It is nil otherwise.
+	observer *observerRecorder
+
+	// keyRefreshEnabled determines whether the node takes part in key
+	// refreshes of its wallets.
+	keyRefreshEnabled bool
+
+	// feeBumpMinPendingBlocks is the minimum number of Bitcoin blocks
+	// a wallet transaction must remain unconfirmed before the node agrees
+	// to bump its fee.
+	feeBumpMinPendingBlocks uint
+
+	// keyShareAudit is the outcome of the key share audit executed upon
+	// the node start.
+	keyShareAudit *keyShareAuditReport
 }
 
 func newNode(
 	groupParameters *GroupParameters,
+	timings *ProtocolTimings,
 	chain Chain,
 	btcChain bitcoin.Chain,
 	netProvider net.Provider,
@@ -132,11 +167,33 @@ func newNode(
 		return nil, fmt.Errorf("cannot create wallet registry: [%v]", err)
 	}
 
+	actionHistory, err := newWalletActionHistory(workPersistence)
+	if err != nil {
+		return nil, fmt.Errorf("cannot create wallet action history: [%v]", err)
+	}
+
+	pendingTransactions, err := newPendingTransactionsTracker(
+		btcChain,
+		workPersistence,
+	)
+	if err != nil {
+		return nil, fmt.Errorf(
+			"cannot create pending transactions tracker: [%v]",
+			err,
+		)
+	}
+
+	signingPolicy, err := loadSigningPolicyEngine(config.SigningPolicyFile)
+	if err != nil {
+		return nil, fmt.Errorf("cannot load signing policy: [%v]", err)
+	}
+
 	latch := generator.NewProtocolLatch()
 	scheduler.RegisterProtocol(latch)
 
 	node := &node{
 		groupParameters:          groupParameters,
+		timings:                  timings,
 		chain:                    chain,
 		btcChain:                 btcChain,
 		netProvider:              netProvider,
@@ -146,10 +203,35 @@ func newNode(
 		heartbeatFailureCounter:  newHeartbeatFailureCounter(),
 		signingExecutors:         make(map[string]*signingExecutor),
 		inactivityClaimExecutors: make(map[string]*inactivityClaimExecutor),
+		keyRefreshExecutors:      make(map[string]*keyRefreshExecutor),
 		coordinationExecutors:    make(map[string]*coordinationExecutor),
 		proposalGenerator:        proposalGenerator,
+		walletMonitor: newWalletMonitor(
+			chain,
+			btcChain,
+			walletRegistry.getWalletsPublicKeys,
+		),
+		actionHistory:       actionHistory,
+		pendingTransactions: pendingTransactions,
+		walletMetrics:       newWalletMetrics(),
+		signingPolicy:       signingPolicy,
+		keyRefreshEnabled:   config.KeyRefreshEnabled,
+
+		feeBumpMinPendingBlocks: config.FeeBumpMinPendingBlocks,
+	}
+
+	if config.ObserverMode {
+		logger.Warnf(
+			"node operates in the observer mode; signature shares and " +
+				"chain transactions will be recorded but not produced",
+		)
+		node.observer = newObserverRecorder(workPersistence)
 	}
 
+	// Audit key shares before archiving closed wallets so that shares of
+	// closed and terminated wallets that were never archived are reported.
+	node.keyShareAudit = node.auditKeyShares()
+
 	// Archive any wallets that might have been closed or terminated while the
 	// client was turned off.
 	err = node.archiveClosedWallets()
@@ -169,6 +251,7 @@ func newNode(
 	// waitForBlockHeight becomes a part of BlockHeightWaiter interface.
 	node.dkgExecutor = newDkgExecutor(
 		node.groupParameters,
+		node.timings,
 		node.operatorID,
 		operatorAddress,
 		chain,
@@ -180,6 +263,7 @@ func newNode(
 		scheduler,
 		node.waitForBlockHeight,
 	)
+	node.dkgExecutor.observer = node.observer
 
 	return node, nil
 }
@@ -333,11 +417,13 @@ func (n *node) getSigningExecutor(
 		broadcastChannel,
 		membershipValidator,
 		n.groupParameters,
+		n.timings,
 		n.protocolLatch,
 		blockCounter.CurrentBlock,
 		n.waitForBlockHeight,
-		signingAttemptsLimit,
 	)
+	executor.metrics = n.walletMetrics
+	executor.observer = n.observer
 
 	n.signingExecutors[executorKey] = executor
 
@@ -433,6 +519,7 @@ func (n *node) getCoordinationExecutor(
 		n.protocolLatch,
 		n.waitForBlockHeight,
 	)
+	executor.observer = n.observer
 
 	n.coordinationExecutors[executorKey] = executor
 
@@ -521,12 +608,142 @@ func (n *node) getInactivityClaimExecutor(
 		n.protocolLatch,
 		n.waitForBlockHeight,
 	)
+	executor.metrics = n.walletMetrics
+	executor.observer = n.observer
 
 	n.inactivityClaimExecutors[executorKey] = executor
 
 	return executor, true, nil
 }
 
+// getKeyRefreshExecutor gets the key refresh executor responsible for
+// refreshing key shares of a specific wallet whose part is controlled by
+// this node. The second boolean return value indicates whether the node
+// controls at least one signer for the given wallet.
+func (n *node) getKeyRefreshExecutor(
+	walletPublicKey *ecdsa.PublicKey,
+) (*keyRefreshExecutor, bool, error) {
+	n.keyRefreshExecutorsMutex.Lock()
+	defer n.keyRefreshExecutorsMutex.Unlock()
+
+	walletPublicKeyBytes, err := marshalPublicKey(walletPublicKey)
+	if err != nil {
+		return nil, false, fmt.Errorf("cannot marshal wallet public key: [%v]", err)
+	}
+
+	executorKey := hex.EncodeToString(walletPublicKeyBytes)
+
+	if executor, exists := n.keyRefreshExecutors[executorKey]; exists {
+		return executor, true, nil
+	}
+
+	executorLogger := logger.With(
+		zap.String("wallet", fmt.Sprintf("0x%x", walletPublicKeyBytes)),
+	)
+
+	signers := n.walletRegistry.getSigners(walletPublicKey)
+	if len(signers) == 0 {
+		// This is not an error because the node simply does not control
+		// the given wallet.
+		return nil, false, nil
+	}
+
+	// All signers belong to one wallet. Take that wallet from the first signer.
+	wallet := signers[0].wallet
+
+	channelName := fmt.Sprintf(
+		"%s-%s-refresh",
+		ProtocolName,
+		hex.EncodeToString(walletPublicKeyBytes),
+	)
+
+	broadcastChannel, err := n.netProvider.BroadcastChannelFor(channelName)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to get broadcast channel: [%v]", err)
+	}
+
+	refresh.RegisterUnmarshallers(broadcastChannel)
+	announcer.RegisterUnmarshaller(broadcastChannel)
+
+	membershipValidator := group.NewMembershipValidator(
+		executorLogger,
+		wallet.signingGroupOperators,
+		n.chain.Signing(),
+	)
+
+	err = broadcastChannel.SetFilter(membershipValidator.IsInGroup)
+	if err != nil {
+		return nil, false, fmt.Errorf(
+			"could not set filter for channel [%v]: [%v]",
+			broadcastChannel.Name(),
+			err,
+		)
+	}
+
+	executorLogger.Infof(
+		"key refresh executor created; controlling [%v] signers",
+		len(signers),
+	)
+
+	blockCounter, err := n.chain.BlockCounter()
+	if err != nil {
+		return nil, false, fmt.Errorf(
+			"could not get block counter: [%v]",
+			err,
+		)
+	}
+
+	executor := newKeyRefreshExecutor(
+		signers,
+		broadcastChannel,
+		membershipValidator,
+		n.groupParameters,
+		n.protocolLatch,
+		blockCounter.CurrentBlock,
+		n.waitForBlockHeight,
+	)
+	executor.observer = n.observer
+
+	n.keyRefreshExecutors[executorKey] = executor
+
+	return executor, true, nil
+}
+
+// replaceSigners replaces signers of the given wallet with their refreshed
+// counterparts in the wallet registry. Executors holding the replaced signers
+// are removed from their caches so they are re-created with the refreshed
+// signers once needed again.
+func (n *node) replaceSigners(
+	walletPublicKey *ecdsa.PublicKey,
+	refreshedSigners []*signer,
+) error {
+	walletPublicKeyBytes, err := marshalPublicKey(walletPublicKey)
+	if err != nil {
+		return fmt.Errorf("cannot marshal wallet public key: [%v]", err)
+	}
+
+	executorKey := hex.EncodeToString(walletPublicKeyBytes)
+
+	err = n.walletRegistry.replaceSigners(walletPublicKey, refreshedSigners)
+	if err != nil {
+		return err
+	}
+
+	n.signingExecutorsMutex.Lock()
+	delete(n.signingExecutors, executorKey)
+	n.signingExecutorsMutex.Unlock()
+
+	n.inactivityClaimExecutorMutex.Lock()
+	delete(n.inactivityClaimExecutors, executorKey)
+	n.inactivityClaimExecutorMutex.Unlock()
+
+	n.keyRefreshExecutorsMutex.Lock()
+	delete(n.keyRefreshExecutors, executorKey)
+	n.keyRefreshExecutorsMutex.Unlock()
+
+	return nil
+}
+
 // handleHeartbeatProposal handles an incoming heartbeat proposal by
 // orchestrating and dispatching an appropriate wallet action.
 func (n *node) handleHeartbeatProposal(
@@ -534,6 +751,7 @@ func (n *node) handleHeartbeatProposal(
 	proposal *HeartbeatProposal,
 	startBlock uint64,
 	expiryBlock uint64,
+	recorder *walletActionRecorder,
 ) {
 	walletPublicKeyBytes, err := marshalPublicKey(wallet.publicKey)
 	if err != nil {
@@ -604,8 +822,10 @@ func (n *node) handleHeartbeatProposal(
 		expiryBlock,
 		n.waitForBlockHeight,
 	)
+	action.metrics = n.walletMetrics
+	action.actionRecorder = recorder
 
-	err = n.walletDispatcher.dispatch(action)
+	err = n.walletDispatcher.dispatch(n.recordedAction(action, recorder))
 	if err != nil {
 		walletActionLogger.Errorf("cannot dispatch wallet action: [%v]", err)
 		return
@@ -621,6 +841,7 @@ func (n *node) handleDepositSweepProposal(
 	proposal *DepositSweepProposal,
 	startBlock uint64,
 	expiryBlock uint64,
+	recorder *walletActionRecorder,
 ) {
 	walletPublicKeyBytes, err := marshalPublicKey(wallet.publicKey)
 	if err != nil {
@@ -672,8 +893,13 @@ func (n *node) handleDepositSweepProposal(
 		expiryBlock,
 		n.waitForBlockHeight,
 	)
+	n.setUpTransactionExecutor(
+		action.transactionExecutor,
+		action.actionType(),
+		recorder,
+	)
 
-	err = n.walletDispatcher.dispatch(action)
+	err = n.walletDispatcher.dispatch(n.recordedAction(action, recorder))
 	if err != nil {
 		walletActionLogger.Errorf("cannot dispatch wallet action: [%v]", err)
 		return
@@ -689,6 +915,7 @@ func (n *node) handleRedemptionProposal(
 	proposal *RedemptionProposal,
 	startBlock uint64,
 	expiryBlock uint64,
+	recorder *walletActionRecorder,
 ) {
 	walletPublicKeyBytes, err := marshalPublicKey(wallet.publicKey)
 	if err != nil {
@@ -740,8 +967,13 @@ func (n *node) handleRedemptionProposal(
 		expiryBlock,
 		n.waitForBlockHeight,
 	)
+	n.setUpTransactionExecutor(
+		action.transactionExecutor,
+		action.actionType(),
+		recorder,
+	)
 
-	err = n.walletDispatcher.dispatch(action)
+	err = n.walletDispatcher.dispatch(n.recordedAction(action, recorder))
 	if err != nil {
 		walletActionLogger.Errorf("cannot dispatch wallet action: [%v]", err)
 		return
@@ -757,6 +989,7 @@ func (n *node) handleMovingFundsProposal(
 	proposal *MovingFundsProposal,
 	startBlock uint64,
 	expiryBlock uint64,
+	recorder *walletActionRecorder,
 ) {
 	walletPublicKeyBytes, err := marshalPublicKey(wallet.publicKey)
 	if err != nil {
@@ -808,8 +1041,13 @@ func (n *node) handleMovingFundsProposal(
 		expiryBlock,
 		n.waitForBlockHeight,
 	)
+	n.setUpTransactionExecutor(
+		action.transactionExecutor,
+		action.actionType(),
+		recorder,
+	)
 
-	err = n.walletDispatcher.dispatch(action)
+	err = n.walletDispatcher.dispatch(n.recordedAction(action, recorder))
 	if err != nil {
 		walletActionLogger.Errorf("cannot dispatch wallet action: [%v]", err)
 		return
@@ -825,6 +1063,7 @@ func (n *node) handleMovedFundsSweepProposal(
 	proposal *MovedFundsSweepProposal,
 	startBlock uint64,
 	expiryBlock uint64,
+	recorder *walletActionRecorder,
 ) {
 	walletPublicKeyBytes, err := marshalPublicKey(wallet.publicKey)
 	if err != nil {
@@ -876,8 +1115,13 @@ func (n *node) handleMovedFundsSweepProposal(
 		expiryBlock,
 		n.waitForBlockHeight,
 	)
+	n.setUpTransactionExecutor(
+		action.transactionExecutor,
+		action.actionType(),
+		recorder,
+	)
 
-	err = n.walletDispatcher.dispatch(action)
+	err = n.walletDispatcher.dispatch(n.recordedAction(action, recorder))
 	if err != nil {
 		walletActionLogger.Errorf("cannot dispatch wallet action: [%v]", err)
 		return
@@ -886,6 +1130,184 @@ func (n *node) handleMovedFundsSweepProposal(
 	walletActionLogger.Infof("wallet action dispatched successfully")
 }
 
+// handleFeeBumpProposal handles an incoming fee bump proposal by
+// orchestrating and dispatching an appropriate wallet action.
+func (n *node) handleFeeBumpProposal(
+	wallet wallet,
+	proposal *FeeBumpProposal,
+	startBlock uint64,
+	expiryBlock uint64,
+	recorder *walletActionRecorder,
+) {
+	walletPublicKeyBytes, err := marshalPublicKey(wallet.publicKey)
+	if err != nil {
+		logger.Errorf("cannot marshal wallet public key: [%v]", err)
+		return
+	}
+
+	signingExecutor, ok, err := n.getSigningExecutor(wallet.publicKey)
+	if err != nil {
+		logger.Errorf("cannot get signing executor: [%v]", err)
+		return
+	}
+	// This check is actually redundant. We know the node controls some
+	// wallet signers as we just got the wallet from the registry using their
+	// public key hash. However, we are doing it just in case. The API
+	// contract of getSigningExecutor may change one day.
+	if !ok {
+		logger.Infof(
+			"node does not control signers of wallet PKH [0x%x]; "+
+				"ignoring the received fee bump proposal",
+			walletPublicKeyBytes,
+		)
+		return
+	}
+
+	logger.Infof(
+		"starting orchestration of the fee bump action for wallet "+
+			"[0x%x]; 20-byte public key hash of that wallet is [0x%x]",
+		walletPublicKeyBytes,
+		bitcoin.PublicKeyHash(wallet.publicKey),
+	)
+
+	walletActionLogger := logger.With(
+		zap.String("wallet", fmt.Sprintf("0x%x", walletPublicKeyBytes)),
+		zap.String("action", ActionFeeBump.String()),
+		zap.Uint64("startBlock", startBlock),
+		zap.Uint64("expiryBlock", expiryBlock),
+	)
+	walletActionLogger.Infof("dispatching wallet action")
+
+	action := newFeeBumpAction(
+		walletActionLogger,
+		n.chain,
+		n.btcChain,
+		wallet,
+		signingExecutor,
+		proposal,
+		n.feeBumpMinPendingBlocks,
+		startBlock,
+		expiryBlock,
+		n.waitForBlockHeight,
+	)
+	n.setUpTransactionExecutor(
+		action.transactionExecutor,
+		action.actionType(),
+		recorder,
+	)
+
+	err = n.walletDispatcher.dispatch(n.recordedAction(action, recorder))
+	if err != nil {
+		walletActionLogger.Errorf("cannot dispatch wallet action: [%v]", err)
+		return
+	}
+
+	walletActionLogger.Infof("wallet action dispatched successfully")
+}
+
+// handleKeyRefreshProposal handles an incoming key refresh proposal by
+// orchestrating and dispatching an appropriate wallet action.
+func (n *node) handleKeyRefreshProposal(
+	wallet wallet,
+	proposal *KeyRefreshProposal,
+	startBlock uint64,
+	expiryBlock uint64,
+	recorder *walletActionRecorder,
+) {
+	walletPublicKeyBytes, err := marshalPublicKey(wallet.publicKey)
+	if err != nil {
+		logger.Errorf("cannot marshal wallet public key: [%v]", err)
+		return
+	}
+
+	keyRefreshExecutor, ok, err := n.getKeyRefreshExecutor(wallet.publicKey)
+	if err != nil {
+		logger.Errorf("cannot get key refresh executor: [%v]", err)
+		return
+	}
+	// This check is actually redundant. We know the node controls some
+	// wallet signers as we just got the wallet from the registry using their
+	// public key hash. However, we are doing it just in case. The API
+	// contract of getKeyRefreshExecutor may change one day.
+	if !ok {
+		logger.Infof(
+			"node does not control signers of wallet [0x%x]; "+
+				"ignoring the received key refresh request",
+			walletPublicKeyBytes,
+		)
+		return
+	}
+
+	logger.Infof(
+		"starting orchestration of the key refresh action for wallet [0x%x]; "+
+			"20-byte public key hash of that wallet is [0x%x]",
+		walletPublicKeyBytes,
+		bitcoin.PublicKeyHash(wallet.publicKey),
+	)
+
+	walletActionLogger := logger.With(
+		zap.String("wallet", fmt.Sprintf("0x%x", walletPublicKeyBytes)),
+		zap.String("action", ActionKeyRefresh.String()),
+		zap.Uint64("startBlock", startBlock),
+		zap.Uint64("expiryBlock", expiryBlock),
+	)
+	walletActionLogger.Infof("dispatching wallet action")
+
+	action := newKeyRefreshAction(
+		walletActionLogger,
+		n.chain,
+		wallet,
+		keyRefreshExecutor,
+		proposal,
+		n.keyRefreshEnabled,
+		func(refreshedSigners []*signer) error {
+			return n.replaceSigners(wallet.publicKey, refreshedSigners)
+		},
+		startBlock,
+		expiryBlock,
+		n.waitForBlockHeight,
+	)
+
+	err = n.walletDispatcher.dispatch(n.recordedAction(action, recorder))
+	if err != nil {
+		walletActionLogger.Errorf("cannot dispatch wallet action: [%v]", err)
+		return
+	}
+
+	walletActionLogger.Infof("wallet action dispatched successfully")
+}
+
+// recordedAction decorates the given action so its progress is recorded
+// in the wallet action history. The action is returned as is if the
+// recorder is nil.
+func (n *node) recordedAction(
+	action walletAction,
+	recorder *walletActionRecorder,
+) walletAction {
+	if recorder == nil {
+		return action
+	}
+
+	return &recordedWalletAction{
+		walletAction: action,
+		recorder:     recorder,
+	}
+}
+
+// setUpTransactionExecutor wires the given wallet transaction executor
+// with node components tracking transactions produced by the executor.
+func (n *node) setUpTransactionExecutor(
+	executor *walletTransactionExecutor,
+	actionType WalletActionType,
+	recorder *walletActionRecorder,
+) {
+	executor.actionType = actionType
+	executor.actionRecorder = recorder
+	executor.pendingTransactions = n.pendingTransactions
+	executor.metrics = n.walletMetrics
+	executor.signingPolicy = n.signingPolicy
+}
+
 // coordinationLayerSettings represents settings for the coordination layer.
 type coordinationLayerSettings struct {
 	// executeCoordinationProcedureFn is a function executing the coordination
@@ -959,6 +1381,7 @@ func (n *node) runCoordinationLayer(
 	// Start the coordination windows watcher.
 	go watchCoordinationWindows(
 		ctx,
+		n.timings,
 		blockCounter.WatchBlocks,
 		onWindowFn,
 	)
@@ -1015,6 +1438,7 @@ func executeCoordinationProcedure(
 	result, err := executor.coordinate(window)
 	if err != nil {
 		procedureLogger.Errorf("coordination procedure failed: [%v]", err)
+		node.walletMetrics.recordCoordinationFailure(walletPublicKey)
 		return nil, false
 	}
 
@@ -1032,6 +1456,7 @@ func processCoordinationResult(node *node, result *coordinationResult) {
 
 	// TODO: In the future, create coordination faults cache and
 	//       record faults from the processed results there.
+	node.walletMetrics.recordCoordinationResult(result)
 
 	proposedAction := result.proposal.ActionType()
 
@@ -1041,9 +1466,20 @@ func processCoordinationResult(node *node, result *coordinationResult) {
 		return
 	}
 
+	// Let the wallet monitor know about the proposal so it can explain
+	// the resulting Bitcoin transaction once it appears on the chain.
+	node.walletMonitor.recordProposal(result.wallet.publicKey, result.proposal)
+
 	startBlock := result.window.endBlock()
 	expiryBlock := startBlock + result.proposal.ValidityBlocks()
 
+	// The action is executed even if it cannot be recorded in the history.
+	record, err := newWalletActionRecord(result, startBlock, expiryBlock)
+	if err != nil {
+		logger.Errorf("cannot create wallet action record: [%v]", err)
+	}
+	recorder := newWalletActionRecorder(record, node.actionHistory)
+
 	switch proposedAction {
 	case ActionHeartbeat:
 		if proposal, ok := result.proposal.(*HeartbeatProposal); ok {
@@ -1052,6 +1488,7 @@ func processCoordinationResult(node *node, result *coordinationResult) {
 				proposal,
 				startBlock,
 				expiryBlock,
+				recorder,
 			)
 		}
 	case ActionDepositSweep:
@@ -1061,6 +1498,7 @@ func processCoordinationResult(node *node, result *coordinationResult) {
 				proposal,
 				startBlock,
 				expiryBlock,
+				recorder,
 			)
 		}
 	case ActionRedemption:
@@ -1070,6 +1508,7 @@ func processCoordinationResult(node *node, result *coordinationResult) {
 				proposal,
 				startBlock,
 				expiryBlock,
+				recorder,
 			)
 		}
 	case ActionMovingFunds:
@@ -1079,6 +1518,7 @@ func processCoordinationResult(node *node, result *coordinationResult) {
 				proposal,
 				startBlock,
 				expiryBlock,
+				recorder,
 			)
 		}
 	case ActionMovedFundsSweep:
@@ -1088,6 +1528,27 @@ func processCoordinationResult(node *node, result *coordinationResult) {
 				proposal,
 				startBlock,
 				expiryBlock,
+				recorder,
+			)
+		}
+	case ActionFeeBump:
+		if proposal, ok := result.proposal.(*FeeBumpProposal); ok {
+			node.handleFeeBumpProposal(
+				result.wallet,
+				proposal,
+				startBlock,
+				expiryBlock,
+				recorder,
+			)
+		}
+	case ActionKeyRefresh:
+		if proposal, ok := result.proposal.(*KeyRefreshProposal); ok {
+			node.handleKeyRefreshProposal(
+				result.wallet,
+				proposal,
+				startBlock,
+				expiryBlock,
+				recorder,
 			)
 		}
 	default:
@@ -52,6 +52,7 @@ func TestNode_GetSigningExecutor(t *testing.T) {
 
 	node, err := newNode(
 		groupParameters,
+		mainnetProtocolTimings(),
 		localChain,
 		newLocalBitcoinChain(),
 		localProvider,
@@ -184,6 +185,7 @@ func TestNode_GetCoordinationExecutor(t *testing.T) {
 
 	node, err := newNode(
 		groupParameters,
+		mainnetProtocolTimings(),
 		localChain,
 		newLocalBitcoinChain(),
 		localProvider,
@@ -321,6 +323,7 @@ func TestNode_RunCoordinationLayer(t *testing.T) {
 
 	n, err := newNode(
 		groupParameters,
+		mainnetProtocolTimings(),
 		localChain,
 		newLocalBitcoinChain(),
 		localProvider,
@@ -0,0 +1,167 @@
+package tbtc
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/keep-network/keep-common/pkg/persistence"
+
+	"github.com/keep-network/keep-core/pkg/clientinfo"
+)
+
+const (
+	// observerRecordsDirectory is the name of the work persistence directory
+	// holding records of the observer mode.
+	observerRecordsDirectory = "observer_records"
+
+	// observerDiagnosticsRecords is the number of the latest observer records
+	// exposed by the diagnostics source.
+	observerDiagnosticsRecords = 20
+)
+
+// Kinds of observer records. Each kind corresponds to an operation the node
+// would have performed if it did not operate in the observer mode.
+const (
+	// ObserverRecordSigning denotes a message the node would have produced
+	// signature shares for.
+	ObserverRecordSigning = "signing"
+	// ObserverRecordDKGResultSubmission denotes a DKG result the node would
+	// have submitted to the chain.
+	ObserverRecordDKGResultSubmission = "dkg_result_submission"
+	// ObserverRecordDKGResultChallenge denotes a DKG result the node would
+	// have challenged on the chain.
+	ObserverRecordDKGResultChallenge = "dkg_result_challenge"
+	// ObserverRecordDKGResultApproval denotes a DKG result the node would
+	// have approved on the chain.
+	ObserverRecordDKGResultApproval = "dkg_result_approval"
+	// ObserverRecordInactivityClaimSubmission denotes an inactivity claim
+	// the node would have submitted to the chain.
+	ObserverRecordInactivityClaimSubmission = "inactivity_claim_submission"
+	// ObserverRecordKeyRefresh denotes a wallet key refresh the node would
+	// have participated in.
+	ObserverRecordKeyRefresh = "key_refresh"
+	// ObserverRecordMovingFundsCommitmentSubmission denotes a moving funds
+	// commitment the node would have submitted to the chain.
+	ObserverRecordMovingFundsCommitmentSubmission = "moving_funds_commitment_submission"
+)
+
+// errObserverMode is an error returned by operations that are not executed
+// because the node operates in the observer mode.
+var errObserverMode = fmt.Errorf("node operates in the observer mode")
+
+// ObserverRecord describes an operation the node would have performed if
+// it did not operate in the observer mode.
+type ObserverRecord struct {
+	// Kind is the kind of the operation.
+	Kind string `json:"kind"`
+	// Details holds operation-specific data, e.g. the message that would
+	// have been signed or the submitted DKG result hash.
+	Details map[string]interface{} `json:"details"`
+	// RecordedAt is the UNIX timestamp of the moment the operation would
+	// have been performed.
+	RecordedAt int64 `json:"recordedAt"`
+}
+
+// ObserverRecorder records operations the node would have performed if it
+// did not operate in the observer mode. It is exposed to components living
+// outside this package, e.g. coordination proposal generators, so they can
+// record their chain transactions instead of submitting them.
+type ObserverRecorder interface {
+	// Record records an operation of the given kind, described by the given
+	// details.
+	Record(kind string, details map[string]interface{})
+}
+
+// observerRecorder records operations the node would have performed
+// if it did not operate in the observer mode. In the observer mode, the node
+// takes part in coordination and listens to protocol messages but never
+// produces signature shares or chain transactions. Instead, the recorder
+// persists what the node would have done so the behavior of a new client
+// version can be validated against the live traffic. The node operates in
+// the observer mode if and only if its recorder is not nil.
+type observerRecorder struct {
+	mutex       sync.Mutex
+	persistence persistence.BasicHandle
+
+	// recordsCount is the number of records created since the client start.
+	recordsCount uint64
+	// latestRecords holds the latest records, up to observerDiagnosticsRecords.
+	latestRecords []*ObserverRecord
+}
+
+func newObserverRecorder(persistence persistence.BasicHandle) *observerRecorder {
+	return &observerRecorder{
+		persistence:   persistence,
+		latestRecords: make([]*ObserverRecord, 0),
+	}
+}
+
+// record records an operation of the given kind, described by the given
+// details. Failures of the persistence layer are logged and do not
+// interrupt the caller.
+func (ob *observerRecorder) record(
+	kind string,
+	details map[string]interface{},
+) {
+	now := time.Now()
+
+	record := &ObserverRecord{
+		Kind:       kind,
+		Details:    details,
+		RecordedAt: now.Unix(),
+	}
+
+	recordBytes, err := json.Marshal(record)
+	if err != nil {
+		logger.Errorf("cannot marshal observer record: [%v]", err)
+		return
+	}
+
+	logger.Infof("observer mode; recorded operation: [%s]", recordBytes)
+
+	ob.mutex.Lock()
+	defer ob.mutex.Unlock()
+
+	err = ob.persistence.Save(
+		recordBytes,
+		observerRecordsDirectory,
+		// The nanosecond timestamp keeps the files ordered by time and the
+		// counter makes names of records created at the same time unique.
+		fmt.Sprintf("%020d-%d-%s", now.UnixNano(), ob.recordsCount, kind),
+	)
+	if err != nil {
+		logger.Errorf("cannot save observer record: [%v]", err)
+	}
+
+	ob.recordsCount++
+
+	ob.latestRecords = append(ob.latestRecords, record)
+	if len(ob.latestRecords) > observerDiagnosticsRecords {
+		ob.latestRecords = ob.latestRecords[1:]
+	}
+}
+
+// Record implements the ObserverRecorder interface.
+func (ob *observerRecorder) Record(
+	kind string,
+	details map[string]interface{},
+) {
+	ob.record(kind, details)
+}
+
+// diagnostics returns the diagnostic information about the latest
+// operations recorded in the observer mode.
+func (ob *observerRecorder) diagnostics() clientinfo.ApplicationInfo {
+	ob.mutex.Lock()
+	defer ob.mutex.Unlock()
+
+	records := make([]*ObserverRecord, len(ob.latestRecords))
+	copy(records, ob.latestRecords)
+
+	return clientinfo.ApplicationInfo{
+		"records_count":  ob.recordsCount,
+		"latest_records": records,
+	}
+}
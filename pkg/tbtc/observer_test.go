@@ -0,0 +1,79 @@
+package tbtc
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/keep-network/keep-core/internal/testutils"
+)
+
+func TestObserverRecorder(t *testing.T) {
+	persistenceHandle := &mockPersistenceHandle{}
+
+	recorder := newObserverRecorder(persistenceHandle)
+
+	recordsCount := observerDiagnosticsRecords + 5
+	for i := 0; i < recordsCount; i++ {
+		recorder.record(
+			ObserverRecordSigning,
+			map[string]interface{}{"message": fmt.Sprintf("0x%x", i)},
+		)
+	}
+
+	testutils.AssertIntsEqual(
+		t,
+		"saved records",
+		recordsCount,
+		len(persistenceHandle.saved),
+	)
+
+	savedRecord := persistenceHandle.saved[0]
+	testutils.AssertStringsEqual(
+		t,
+		"directory",
+		observerRecordsDirectory,
+		savedRecord.Directory(),
+	)
+
+	content, err := savedRecord.Content()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	record := &ObserverRecord{}
+	if err := json.Unmarshal(content, record); err != nil {
+		t.Fatal(err)
+	}
+
+	testutils.AssertStringsEqual(t, "kind", ObserverRecordSigning, record.Kind)
+	testutils.AssertStringsEqual(
+		t,
+		"message",
+		"0x0",
+		record.Details["message"].(string),
+	)
+
+	diagnostics := recorder.diagnostics()
+
+	testutils.AssertUintsEqual(
+		t,
+		"records count",
+		uint64(recordsCount),
+		diagnostics["records_count"].(uint64),
+	)
+
+	latestRecords := diagnostics["latest_records"].([]*ObserverRecord)
+	testutils.AssertIntsEqual(
+		t,
+		"latest records",
+		observerDiagnosticsRecords,
+		len(latestRecords),
+	)
+	testutils.AssertStringsEqual(
+		t,
+		"latest record message",
+		fmt.Sprintf("0x%x", recordsCount-1),
+		latestRecords[len(latestRecords)-1].Details["message"].(string),
+	)
+}
@@ -0,0 +1,485 @@
+package tbtc
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/keep-network/keep-common/pkg/persistence"
+	"go.uber.org/zap"
+
+	"github.com/keep-network/keep-core/internal/hexutils"
+	"github.com/keep-network/keep-core/pkg/bitcoin"
+)
+
+const (
+	// pendingTransactionsDirectory is the name of the work persistence
+	// directory holding signed wallet transactions that are not confirmed
+	// yet.
+	pendingTransactionsDirectory = "pending_transactions"
+
+	// pendingTransactionsCheckInterval determines how often pending
+	// transactions are checked against the Bitcoin chain and rebroadcasted
+	// if needed.
+	pendingTransactionsCheckInterval = 1 * time.Minute
+
+	// pendingTransactionMaxAge determines how long a pending transaction
+	// unknown to the Bitcoin chain is rebroadcasted. Past that time, the
+	// transaction is considered invalidated, e.g. because inputs that
+	// cannot be checked directly, like deposits, were spent by another
+	// transaction.
+	pendingTransactionMaxAge = 7 * 24 * time.Hour
+)
+
+// pendingTransaction is a signed wallet transaction that has not been
+// confirmed on the Bitcoin chain yet.
+type pendingTransaction struct {
+	walletPublicKeyHash [20]byte
+	transaction         *bitcoin.Transaction
+	signedAt            time.Time
+	// signedAtBlock is the Bitcoin block height at which the transaction
+	// was signed. It is zero if the height is not known.
+	signedAtBlock uint
+}
+
+// persistedPendingTransaction is the persistence representation of
+// a pendingTransaction.
+type persistedPendingTransaction struct {
+	WalletPublicKeyHash string `json:"walletPublicKeyHash"`
+	Transaction         string `json:"transaction"`
+	SignedAt            int64  `json:"signedAt"`
+	SignedAtBlock       uint   `json:"signedAtBlock,omitempty"`
+}
+
+// pendingTransactionsTracker keeps signed wallet transactions in the work
+// persistence until they are confirmed on the Bitcoin chain or invalidated.
+// Thanks to that, a transaction signed right before a client restart is
+// not lost. The tracker reloads such a transaction on startup and keeps
+// broadcasting it so the wallet does not need to wait for another
+// coordination window and repeat the signing ceremony.
+type pendingTransactionsTracker struct {
+	btcChain    bitcoin.Chain
+	persistence persistence.BasicHandle
+
+	mutex        sync.Mutex
+	transactions map[bitcoin.Hash]*pendingTransaction
+}
+
+// newPendingTransactionsTracker creates a new tracker and loads pending
+// transactions stored in the given work persistence.
+func newPendingTransactionsTracker(
+	btcChain bitcoin.Chain,
+	persistence persistence.BasicHandle,
+) (*pendingTransactionsTracker, error) {
+	pt := &pendingTransactionsTracker{
+		btcChain:     btcChain,
+		persistence:  persistence,
+		transactions: make(map[bitcoin.Hash]*pendingTransaction),
+	}
+
+	if err := pt.load(); err != nil {
+		return nil, fmt.Errorf("cannot load pending transactions: [%v]", err)
+	}
+
+	return pt, nil
+}
+
+// load reads pending transactions from the persistence. Entries that
+// cannot be decoded are skipped with an error log.
+func (pt *pendingTransactionsTracker) load() error {
+	descriptorsChan, errorsChan := pt.persistence.ReadAll()
+
+	// Both channels are unbuffered and must be drained concurrently.
+	var readErrors []error
+	errorsDone := make(chan struct{})
+	go func() {
+		for err := range errorsChan {
+			readErrors = append(readErrors, err)
+		}
+		close(errorsDone)
+	}()
+
+	for descriptor := range descriptorsChan {
+		if descriptor.Directory() != pendingTransactionsDirectory {
+			continue
+		}
+
+		pending, err := decodePendingTransaction(descriptor)
+		if err != nil {
+			logger.Errorf(
+				"cannot decode pending transaction [%s]: [%v]",
+				descriptor.Name(),
+				err,
+			)
+			continue
+		}
+
+		pt.transactions[pending.transaction.Hash()] = pending
+	}
+
+	<-errorsDone
+
+	if len(readErrors) > 0 {
+		return fmt.Errorf("cannot read persistence: [%v]", readErrors[0])
+	}
+
+	if len(pt.transactions) > 0 {
+		logger.Infof(
+			"loaded [%v] pending wallet transactions",
+			len(pt.transactions),
+		)
+	}
+
+	return nil
+}
+
+func decodePendingTransaction(
+	descriptor persistence.DataDescriptor,
+) (*pendingTransaction, error) {
+	content, err := descriptor.Content()
+	if err != nil {
+		return nil, fmt.Errorf("cannot read content: [%v]", err)
+	}
+
+	persisted := &persistedPendingTransaction{}
+	if err := json.Unmarshal(content, persisted); err != nil {
+		return nil, fmt.Errorf("cannot unmarshal content: [%v]", err)
+	}
+
+	walletPublicKeyHashBytes, err := hexutils.Decode(persisted.WalletPublicKeyHash)
+	if err != nil {
+		return nil, fmt.Errorf("cannot decode wallet public key hash: [%v]", err)
+	}
+	if len(walletPublicKeyHashBytes) != 20 {
+		return nil, fmt.Errorf("wrong wallet public key hash length")
+	}
+
+	var walletPublicKeyHash [20]byte
+	copy(walletPublicKeyHash[:], walletPublicKeyHashBytes)
+
+	transactionBytes, err := hex.DecodeString(persisted.Transaction)
+	if err != nil {
+		return nil, fmt.Errorf("cannot decode transaction: [%v]", err)
+	}
+
+	transaction := &bitcoin.Transaction{}
+	if err := transaction.Deserialize(transactionBytes); err != nil {
+		return nil, fmt.Errorf("cannot deserialize transaction: [%v]", err)
+	}
+
+	return &pendingTransaction{
+		walletPublicKeyHash: walletPublicKeyHash,
+		transaction:         transaction,
+		signedAt:            time.Unix(persisted.SignedAt, 0),
+		signedAtBlock:       persisted.SignedAtBlock,
+	}, nil
+}
+
+// add persists the given signed transaction of the given wallet and starts
+// tracking it. Tracked transactions of the same wallet spending any of
+// the inputs of the given transaction, e.g. transactions replaced by a fee
+// bump, are no longer tracked as they can no longer be confirmed along with
+// the given one. It is safe to call it on a nil tracker.
+func (pt *pendingTransactionsTracker) add(
+	walletPublicKeyHash [20]byte,
+	transaction *bitcoin.Transaction,
+) error {
+	if pt == nil {
+		return nil
+	}
+
+	// The height is used to determine for how long the transaction is
+	// pending. A failure here must not prevent tracking the transaction.
+	signedAtBlock, err := pt.btcChain.GetLatestBlockHeight()
+	if err != nil {
+		logger.Warnf("cannot get latest block height: [%v]", err)
+		signedAtBlock = 0
+	}
+
+	pt.mutex.Lock()
+	defer pt.mutex.Unlock()
+
+	transactionHash := transaction.Hash()
+
+	if _, ok := pt.transactions[transactionHash]; ok {
+		return nil
+	}
+
+	pending := &pendingTransaction{
+		walletPublicKeyHash: walletPublicKeyHash,
+		transaction:         transaction,
+		signedAt:            time.Now(),
+		signedAtBlock:       signedAtBlock,
+	}
+
+	content, err := json.Marshal(&persistedPendingTransaction{
+		WalletPublicKeyHash: hexutils.Encode(walletPublicKeyHash[:]),
+		Transaction:         hex.EncodeToString(transaction.Serialize()),
+		SignedAt:            pending.signedAt.Unix(),
+		SignedAtBlock:       pending.signedAtBlock,
+	})
+	if err != nil {
+		return fmt.Errorf("cannot marshal pending transaction: [%v]", err)
+	}
+
+	err = pt.persistence.Save(
+		content,
+		pendingTransactionsDirectory,
+		transactionHash.Hex(bitcoin.InternalByteOrder),
+	)
+	if err != nil {
+		return fmt.Errorf("cannot save pending transaction: [%w]", err)
+	}
+
+	for replacedHash, replaced := range pt.transactions {
+		if replaced.walletPublicKeyHash == walletPublicKeyHash &&
+			spendsSameInput(replaced.transaction, transaction) {
+			logger.Infof(
+				"pending transaction [%s] replaced by [%s]",
+				replacedHash.Hex(bitcoin.ReversedByteOrder),
+				transactionHash.Hex(bitcoin.ReversedByteOrder),
+			)
+			pt.removeLocked(replacedHash)
+		}
+	}
+
+	pt.transactions[transactionHash] = pending
+
+	return nil
+}
+
+// spendsSameInput checks whether the given transactions spend at least
+// one common outpoint.
+func spendsSameInput(first, second *bitcoin.Transaction) bool {
+	outpoints := make(map[bitcoin.TransactionOutpoint]bool)
+	for _, input := range first.Inputs {
+		outpoints[*input.Outpoint] = true
+	}
+
+	for _, input := range second.Inputs {
+		if outpoints[*input.Outpoint] {
+			return true
+		}
+	}
+
+	return false
+}
+
+// pendingBlocks returns the number of Bitcoin blocks mined since the given
+// tracked transaction was signed. The boolean result is false if the
+// transaction is not tracked or the block at which it was signed is not
+// known. It is safe to call it on a nil tracker.
+func (pt *pendingTransactionsTracker) pendingBlocks(
+	transactionHash bitcoin.Hash,
+) (uint, bool, error) {
+	if pt == nil {
+		return 0, false, nil
+	}
+
+	pt.mutex.Lock()
+	pending, ok := pt.transactions[transactionHash]
+	pt.mutex.Unlock()
+
+	if !ok || pending.signedAtBlock == 0 {
+		return 0, false, nil
+	}
+
+	latestBlockHeight, err := pt.btcChain.GetLatestBlockHeight()
+	if err != nil {
+		return 0, false, fmt.Errorf(
+			"cannot get latest block height: [%v]",
+			err,
+		)
+	}
+
+	if latestBlockHeight < pending.signedAtBlock {
+		return 0, true, nil
+	}
+
+	return latestBlockHeight - pending.signedAtBlock, true, nil
+}
+
+// remove stops tracking the given transaction and removes it from the
+// persistence.
+func (pt *pendingTransactionsTracker) remove(transactionHash bitcoin.Hash) {
+	pt.mutex.Lock()
+	defer pt.mutex.Unlock()
+
+	pt.removeLocked(transactionHash)
+}
+
+// removeLocked does the same as remove but must be called with the tracker
+// mutex held.
+func (pt *pendingTransactionsTracker) removeLocked(
+	transactionHash bitcoin.Hash,
+) {
+	delete(pt.transactions, transactionHash)
+
+	err := pt.persistence.Delete(
+		pendingTransactionsDirectory,
+		transactionHash.Hex(bitcoin.InternalByteOrder),
+	)
+	if err != nil {
+		logger.Errorf(
+			"cannot delete pending transaction [%s]: [%v]",
+			transactionHash.Hex(bitcoin.ReversedByteOrder),
+			err,
+		)
+	}
+}
+
+// run checks pending transactions right away, in order to resume
+// transactions left over by a previous client run, and then periodically
+// until the given context is done.
+func (pt *pendingTransactionsTracker) run(ctx context.Context) {
+	pt.checkAll()
+
+	ticker := time.NewTicker(pendingTransactionsCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			pt.checkAll()
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// checkAll checks all pending transactions.
+func (pt *pendingTransactionsTracker) checkAll() {
+	pt.mutex.Lock()
+	pendings := make([]*pendingTransaction, 0, len(pt.transactions))
+	for _, pending := range pt.transactions {
+		pendings = append(pendings, pending)
+	}
+	pt.mutex.Unlock()
+
+	for _, pending := range pendings {
+		pt.check(pending)
+	}
+}
+
+// check verifies the given pending transaction against the Bitcoin chain.
+// A confirmed or invalidated transaction is no longer tracked. A transaction
+// unknown to the chain is rebroadcasted.
+func (pt *pendingTransactionsTracker) check(pending *pendingTransaction) {
+	transactionHash := pending.transaction.Hash()
+
+	checkLogger := logger.With(
+		zap.String(
+			"transactionHash",
+			transactionHash.Hex(bitcoin.ReversedByteOrder),
+		),
+		zap.String(
+			"walletPKH",
+			fmt.Sprintf("0x%x", pending.walletPublicKeyHash),
+		),
+	)
+
+	confirmations, err := pt.btcChain.GetTransactionConfirmations(
+		transactionHash,
+	)
+	if err == nil {
+		if confirmations > 0 {
+			checkLogger.Infof("pending transaction confirmed")
+			pt.remove(transactionHash)
+		}
+
+		// The transaction is in the mempool; nothing to do until it is
+		// confirmed.
+		return
+	}
+
+	invalidated, err := pt.isInvalidated(pending)
+	if err != nil {
+		checkLogger.Warnf(
+			"cannot determine whether pending transaction is "+
+				"invalidated: [%v]",
+			err,
+		)
+		return
+	}
+
+	if invalidated {
+		checkLogger.Warnf("pending transaction invalidated")
+		pt.remove(transactionHash)
+		return
+	}
+
+	checkLogger.Infof("rebroadcasting pending transaction")
+
+	if err := pt.btcChain.BroadcastTransaction(pending.transaction); err != nil {
+		checkLogger.Warnf(
+			"cannot rebroadcast pending transaction: [%v]; transaction "+
+				"could be broadcasted by another wallet operators though",
+			err,
+		)
+	}
+}
+
+// isInvalidated checks whether the given pending transaction, unknown to
+// the Bitcoin chain, can no longer be included in the chain. This is the
+// case if any of its inputs controlled by the wallet is no longer unspent,
+// or if the transaction exceeded the maximum age.
+func (pt *pendingTransactionsTracker) isInvalidated(
+	pending *pendingTransaction,
+) (bool, error) {
+	if time.Since(pending.signedAt) > pendingTransactionMaxAge {
+		return true, nil
+	}
+
+	confirmedUtxos, err := pt.btcChain.GetUtxosForPublicKeyHash(
+		pending.walletPublicKeyHash,
+	)
+	if err != nil {
+		return false, fmt.Errorf("cannot get confirmed wallet UTXOs: [%v]", err)
+	}
+
+	mempoolUtxos, err := pt.btcChain.GetMempoolUtxosForPublicKeyHash(
+		pending.walletPublicKeyHash,
+	)
+	if err != nil {
+		return false, fmt.Errorf("cannot get mempool wallet UTXOs: [%v]", err)
+	}
+
+	unspent := make(map[bitcoin.TransactionOutpoint]bool)
+	for _, utxo := range append(confirmedUtxos, mempoolUtxos...) {
+		unspent[*utxo.Outpoint] = true
+	}
+
+	for _, input := range pending.transaction.Inputs {
+		previousTransaction, err := pt.btcChain.GetTransaction(
+			input.Outpoint.TransactionHash,
+		)
+		if err != nil {
+			return false, fmt.Errorf(
+				"cannot get previous transaction [%s]: [%v]",
+				input.Outpoint.TransactionHash.Hex(bitcoin.ReversedByteOrder),
+				err,
+			)
+		}
+
+		outputIndex := input.Outpoint.OutputIndex
+		if int(outputIndex) >= len(previousTransaction.Outputs) {
+			return true, nil
+		}
+
+		if !paysPublicKeyHash(
+			previousTransaction.Outputs[outputIndex].PublicKeyScript,
+			pending.walletPublicKeyHash,
+		) {
+			continue
+		}
+
+		if !unspent[*input.Outpoint] {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
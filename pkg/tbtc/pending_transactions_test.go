@@ -0,0 +1,337 @@
+package tbtc
+
+import (
+	"crypto/ecdsa"
+	"crypto/rand"
+	"testing"
+	"time"
+
+	"github.com/keep-network/keep-core/internal/testutils"
+	"github.com/keep-network/keep-core/pkg/bitcoin"
+	"github.com/keep-network/keep-core/pkg/bitcoin/simnet"
+)
+
+func TestPendingTransactionsTracker_ResumeAfterRestart(t *testing.T) {
+	btcChain, err := simnet.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	walletPrivateKey := newDepositRefundTestKey(t)
+	walletPublicKeyHash := bitcoin.PublicKeyHash(&walletPrivateKey.PublicKey)
+
+	utxo := fundPendingTransactionsTestWallet(t, btcChain, walletPublicKeyHash)
+	transaction := signPendingTransactionsTestTransaction(
+		t,
+		btcChain,
+		walletPrivateKey,
+		utxo,
+		bitcoin.Script{0x00, 0x14, 0x01},
+	)
+
+	persistenceHandle := &mockPersistenceHandle{}
+
+	tracker, err := newPendingTransactionsTracker(btcChain, persistenceHandle)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The transaction is signed but the client restarts before broadcasting.
+	if err := tracker.add(walletPublicKeyHash, transaction); err != nil {
+		t.Fatal(err)
+	}
+
+	restartedTracker, err := newPendingTransactionsTracker(
+		btcChain,
+		persistenceHandle,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testutils.AssertIntsEqual(
+		t,
+		"pending transactions count",
+		1,
+		len(restartedTracker.transactions),
+	)
+
+	restartedTracker.checkAll()
+
+	confirmations, err := btcChain.GetTransactionConfirmations(transaction.Hash())
+	if err != nil {
+		t.Fatalf("transaction was not rebroadcasted: [%v]", err)
+	}
+	testutils.AssertUintsEqual(t, "confirmations", 0, uint64(confirmations))
+
+	// The transaction is still pending while in the mempool.
+	restartedTracker.checkAll()
+	testutils.AssertIntsEqual(
+		t,
+		"pending transactions count",
+		1,
+		len(restartedTracker.transactions),
+	)
+
+	if err := btcChain.MineBlocks(1); err != nil {
+		t.Fatal(err)
+	}
+
+	restartedTracker.checkAll()
+
+	testutils.AssertIntsEqual(
+		t,
+		"pending transactions count",
+		0,
+		len(restartedTracker.transactions),
+	)
+	testutils.AssertIntsEqual(
+		t,
+		"persisted pending transactions count",
+		0,
+		len(persistenceHandle.saved),
+	)
+}
+
+func TestPendingTransactionsTracker_Invalidated(t *testing.T) {
+	btcChain, err := simnet.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	walletPrivateKey := newDepositRefundTestKey(t)
+	walletPublicKeyHash := bitcoin.PublicKeyHash(&walletPrivateKey.PublicKey)
+
+	utxo := fundPendingTransactionsTestWallet(t, btcChain, walletPublicKeyHash)
+
+	pendingTransaction := signPendingTransactionsTestTransaction(
+		t,
+		btcChain,
+		walletPrivateKey,
+		utxo,
+		bitcoin.Script{0x00, 0x14, 0x01},
+	)
+	// Another transaction spending the same UTXO gets confirmed.
+	conflictingTransaction := signPendingTransactionsTestTransaction(
+		t,
+		btcChain,
+		walletPrivateKey,
+		utxo,
+		bitcoin.Script{0x00, 0x14, 0x02},
+	)
+
+	if err := btcChain.BroadcastTransaction(conflictingTransaction); err != nil {
+		t.Fatal(err)
+	}
+	if err := btcChain.MineBlocks(1); err != nil {
+		t.Fatal(err)
+	}
+
+	persistenceHandle := &mockPersistenceHandle{}
+
+	tracker, err := newPendingTransactionsTracker(btcChain, persistenceHandle)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := tracker.add(walletPublicKeyHash, pendingTransaction); err != nil {
+		t.Fatal(err)
+	}
+
+	tracker.checkAll()
+
+	testutils.AssertIntsEqual(
+		t,
+		"pending transactions count",
+		0,
+		len(tracker.transactions),
+	)
+	testutils.AssertIntsEqual(
+		t,
+		"persisted pending transactions count",
+		0,
+		len(persistenceHandle.saved),
+	)
+}
+
+func TestPendingTransactionsTracker_Replaced(t *testing.T) {
+	btcChain, err := simnet.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	walletPrivateKey := newDepositRefundTestKey(t)
+	walletPublicKeyHash := bitcoin.PublicKeyHash(&walletPrivateKey.PublicKey)
+
+	utxo := fundPendingTransactionsTestWallet(t, btcChain, walletPublicKeyHash)
+	otherUtxo := fundPendingTransactionsTestWallet(
+		t,
+		btcChain,
+		walletPublicKeyHash,
+	)
+
+	originalTransaction := signPendingTransactionsTestTransaction(
+		t,
+		btcChain,
+		walletPrivateKey,
+		utxo,
+		bitcoin.Script{0x00, 0x14, 0x01},
+	)
+	// An unrelated transaction of the same wallet must remain tracked.
+	unrelatedTransaction := signPendingTransactionsTestTransaction(
+		t,
+		btcChain,
+		walletPrivateKey,
+		otherUtxo,
+		bitcoin.Script{0x00, 0x14, 0x01},
+	)
+	// The replacement, e.g. a fee bump, spends the same UTXO.
+	replacementTransaction := signPendingTransactionsTestTransaction(
+		t,
+		btcChain,
+		walletPrivateKey,
+		utxo,
+		bitcoin.Script{0x00, 0x14, 0x02},
+	)
+
+	persistenceHandle := &mockPersistenceHandle{}
+
+	tracker, err := newPendingTransactionsTracker(btcChain, persistenceHandle)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, transaction := range []*bitcoin.Transaction{
+		originalTransaction,
+		unrelatedTransaction,
+		replacementTransaction,
+	} {
+		if err := tracker.add(walletPublicKeyHash, transaction); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	testutils.AssertIntsEqual(
+		t,
+		"pending transactions count",
+		2,
+		len(tracker.transactions),
+	)
+	testutils.AssertIntsEqual(
+		t,
+		"persisted pending transactions count",
+		2,
+		len(persistenceHandle.saved),
+	)
+
+	if _, ok := tracker.transactions[originalTransaction.Hash()]; ok {
+		t.Errorf("replaced transaction is still tracked")
+	}
+	if _, ok := tracker.transactions[unrelatedTransaction.Hash()]; !ok {
+		t.Errorf("unrelated transaction is not tracked")
+	}
+	if _, ok := tracker.transactions[replacementTransaction.Hash()]; !ok {
+		t.Errorf("replacement transaction is not tracked")
+	}
+}
+
+func TestPendingTransactionsTracker_MaxAge(t *testing.T) {
+	btcChain, err := simnet.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	walletPublicKeyHash := [20]byte{0x01}
+
+	tracker, err := newPendingTransactionsTracker(
+		btcChain,
+		&mockPersistenceHandle{},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	invalidated, err := tracker.isInvalidated(&pendingTransaction{
+		walletPublicKeyHash: walletPublicKeyHash,
+		transaction:         &bitcoin.Transaction{},
+		signedAt:            time.Now().Add(-pendingTransactionMaxAge - time.Hour),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testutils.AssertBoolsEqual(t, "invalidated", true, invalidated)
+}
+
+func fundPendingTransactionsTestWallet(
+	t *testing.T,
+	btcChain *simnet.Chain,
+	walletPublicKeyHash [20]byte,
+) *bitcoin.UnspentTransactionOutput {
+	walletScript, err := bitcoin.PayToWitnessPublicKeyHash(walletPublicKeyHash)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fundingTransaction, err := btcChain.Fund(walletScript, 100000)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := btcChain.MineBlocks(1); err != nil {
+		t.Fatal(err)
+	}
+
+	return &bitcoin.UnspentTransactionOutput{
+		Outpoint: &bitcoin.TransactionOutpoint{
+			TransactionHash: fundingTransaction.Hash(),
+			OutputIndex:     0,
+		},
+		Value: 100000,
+	}
+}
+
+func signPendingTransactionsTestTransaction(
+	t *testing.T,
+	btcChain *simnet.Chain,
+	walletPrivateKey *ecdsa.PrivateKey,
+	utxo *bitcoin.UnspentTransactionOutput,
+	outputScript bitcoin.Script,
+) *bitcoin.Transaction {
+	builder := bitcoin.NewTransactionBuilder(btcChain)
+
+	if err := builder.AddPublicKeyHashInput(utxo); err != nil {
+		t.Fatal(err)
+	}
+
+	builder.AddOutput(&bitcoin.TransactionOutput{
+		Value:           utxo.Value - 1000,
+		PublicKeyScript: outputScript,
+	})
+
+	sigHashes, err := builder.ComputeSignatureHashes()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	signatures := make([]*bitcoin.SignatureContainer, len(sigHashes))
+	for i, sigHash := range sigHashes {
+		r, s, err := ecdsa.Sign(rand.Reader, walletPrivateKey, sigHash.Bytes())
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		signatures[i] = &bitcoin.SignatureContainer{
+			R:         r,
+			S:         s,
+			PublicKey: &walletPrivateKey.PublicKey,
+		}
+	}
+
+	transaction, err := builder.AddSignatures(signatures)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return transaction
+}
@@ -0,0 +1,273 @@
+package tbtc
+
+import (
+	"fmt"
+	"sort"
+)
+
+const (
+	// MainnetProtocolProfile is the name of the protocol profile used on
+	// production networks. This is the default profile.
+	MainnetProtocolProfile = "mainnet"
+	// DeveloperProtocolProfile is the name of the protocol profile meant
+	// for small private networks used for development and integration
+	// testing. It uses short protocol windows meant for tiny groups.
+	DeveloperProtocolProfile = "developer"
+)
+
+// ProtocolProfile groups the group parameters and block timings of the
+// TBTC protocol. The group parameters are always read from the on-chain
+// contracts while the timings come from the named profile which must be
+// consistent across all clients of the network.
+type ProtocolProfile struct {
+	GroupParameters
+	ProtocolTimings
+}
+
+// ProtocolTimings groups the block timings of the TBTC protocol. Timings are
+// passed to the node and its executors upon creation.
+type ProtocolTimings struct {
+	// CoordinationFrequencyBlocks is the number of blocks between two
+	// consecutive coordination windows.
+	CoordinationFrequencyBlocks uint64
+	// CoordinationActivePhaseDurationBlocks is the number of blocks in the
+	// active phase of the coordination window. The active phase is the
+	// phase during which the communication between the coordination leader
+	// and their followers is allowed.
+	CoordinationActivePhaseDurationBlocks uint64
+	// CoordinationPassivePhaseDurationBlocks is the number of blocks in the
+	// passive phase of the coordination window. The passive phase is the
+	// phase during which communication is not allowed. Participants are
+	// expected to validate the result of the coordination and prepare for
+	// execution of the proposed wallet action.
+	CoordinationPassivePhaseDurationBlocks uint64
+	// DKGStartedConfirmationBlocks is the block length of the confirmation
+	// period that is preserved after a DKG start. Once the period elapses,
+	// the DKG state is checked to confirm the protocol can be started.
+	DKGStartedConfirmationBlocks uint64
+	// DKGResultSubmissionDelayStepBlocks is the delay step in blocks used
+	// to calculate the DKG result submission delay of the given member.
+	// It prevents all members from submitting the same DKG result at
+	// the same time.
+	DKGResultSubmissionDelayStepBlocks uint64
+	// DKGAttemptMaximumProtocolBlocks is the maximum block duration of the
+	// actual DKG protocol computations in a single attempt.
+	DKGAttemptMaximumProtocolBlocks uint64
+	// DKGAttemptCoolDownBlocks is the duration of the cool down period that
+	// is preserved between subsequent DKG attempts.
+	DKGAttemptCoolDownBlocks uint64
+	// SigningAttemptMaximumProtocolBlocks is the maximum block duration of
+	// the actual signing protocol computations in a single attempt.
+	SigningAttemptMaximumProtocolBlocks uint64
+	// SigningAttemptCoolDownBlocks is the duration of the cool down period
+	// that is preserved between subsequent signing attempts.
+	SigningAttemptCoolDownBlocks uint64
+	// SigningBatchInterludeBlocks is the block duration of the interlude
+	// preserved between subsequent signings in a signing batch. If the
+	// signing of the previous message completed at block X, the signing of
+	// the next message starts at X + SigningBatchInterludeBlocks. The
+	// interlude gives the slowest signing group members time to learn
+	// the previous signing completed.
+	SigningBatchInterludeBlocks uint64
+	// SigningAttemptsLimit is the maximum number of signing attempts that
+	// can be performed for the given message being subject of signing.
+	SigningAttemptsLimit uint
+}
+
+// protocolTimings holds the block timings of the built-in protocol profiles.
+var protocolTimings = map[string]ProtocolTimings{
+	MainnetProtocolProfile: {
+		CoordinationFrequencyBlocks:            900,
+		CoordinationActivePhaseDurationBlocks:  80,
+		CoordinationPassivePhaseDurationBlocks: 20,
+		DKGStartedConfirmationBlocks:           20,
+		DKGResultSubmissionDelayStepBlocks:     3,
+		DKGAttemptMaximumProtocolBlocks:        200,
+		DKGAttemptCoolDownBlocks:               5,
+		SigningAttemptMaximumProtocolBlocks:    30,
+		SigningAttemptCoolDownBlocks:           5,
+		SigningBatchInterludeBlocks:            2,
+		// The value of `5` should be enough to produce the signature
+		// even with `2` malicious members in a signing group of `100`
+		// members. To produce the signature, `51` members must be
+		// selected out of the honest `98`. The probability of successful
+		// signing in that case is:
+		// `P = (98 choose 51) / (100 choose 51) = ~0.24` which means we
+		// need `5` attempts on the worst case.
+		//
+		// A greater limit does not necessarily make sense. Presence of
+		// more than `2` malicious members in the signing group has a very
+		// small probability. Moreover, the signature must be produced in
+		// the reasonable time. That being said, the value `5` seems to be
+		// reasonable trade-off.
+		SigningAttemptsLimit: 5,
+	},
+	DeveloperProtocolProfile: {
+		CoordinationFrequencyBlocks:            60,
+		CoordinationActivePhaseDurationBlocks:  15,
+		CoordinationPassivePhaseDurationBlocks: 5,
+		DKGStartedConfirmationBlocks:           2,
+		DKGResultSubmissionDelayStepBlocks:     1,
+		DKGAttemptMaximumProtocolBlocks:        30,
+		DKGAttemptCoolDownBlocks:               2,
+		SigningAttemptMaximumProtocolBlocks:    15,
+		SigningAttemptCoolDownBlocks:           2,
+		SigningBatchInterludeBlocks:            1,
+		SigningAttemptsLimit:                   3,
+	},
+}
+
+// resolveProtocolProfile builds the protocol profile from the group
+// parameters loaded from the chain and the timings of the profile named in
+// the given config. The mainnet profile timings are used if the name is
+// empty. All group parameters must be reported by the chain. The resolved
+// profile is validated.
+func resolveProtocolProfile(
+	config Config,
+	chainGroupParameters *GroupParameters,
+) (*ProtocolProfile, error) {
+	name := config.ProtocolProfile
+	if len(name) == 0 {
+		name = MainnetProtocolProfile
+	}
+
+	timings, ok := protocolTimings[name]
+	if !ok {
+		names := make([]string, 0, len(protocolTimings))
+		for name := range protocolTimings {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		return nil, fmt.Errorf(
+			"unknown protocol profile [%v]; available profiles: %v",
+			name,
+			names,
+		)
+	}
+
+	if chainGroupParameters.GroupSize <= 0 {
+		return nil, fmt.Errorf("missing on-chain group size")
+	}
+	if chainGroupParameters.GroupQuorum <= 0 {
+		return nil, fmt.Errorf("missing on-chain group quorum")
+	}
+	if chainGroupParameters.HonestThreshold <= 0 {
+		return nil, fmt.Errorf("missing on-chain honest threshold")
+	}
+
+	profile := ProtocolProfile{
+		GroupParameters: *chainGroupParameters,
+		ProtocolTimings: timings,
+	}
+
+	if err := profile.validate(); err != nil {
+		return nil, fmt.Errorf(
+			"invalid protocol profile [%v]: [%v]",
+			name,
+			err,
+		)
+	}
+
+	return &profile, nil
+}
+
+// validate checks whether the profile is internally consistent.
+func (pp *ProtocolProfile) validate() error {
+	if pp.GroupQuorum < pp.HonestThreshold {
+		return fmt.Errorf(
+			"group quorum [%v] must not be smaller than honest threshold [%v]",
+			pp.GroupQuorum,
+			pp.HonestThreshold,
+		)
+	}
+	if pp.GroupSize < pp.GroupQuorum {
+		return fmt.Errorf(
+			"group size [%v] must not be smaller than group quorum [%v]",
+			pp.GroupSize,
+			pp.GroupQuorum,
+		)
+	}
+	if pp.CoordinationActivePhaseDurationBlocks == 0 {
+		return fmt.Errorf("coordination active phase must not be empty")
+	}
+	if pp.coordinationDurationBlocks() >= pp.CoordinationFrequencyBlocks {
+		return fmt.Errorf(
+			"coordination window duration [%v] must be shorter than "+
+				"coordination frequency [%v]",
+			pp.coordinationDurationBlocks(),
+			pp.CoordinationFrequencyBlocks,
+		)
+	}
+	if pp.DKGAttemptMaximumProtocolBlocks == 0 {
+		return fmt.Errorf("DKG attempt protocol duration must not be zero")
+	}
+	if pp.SigningAttemptMaximumProtocolBlocks == 0 {
+		return fmt.Errorf("signing attempt protocol duration must not be zero")
+	}
+	if pp.SigningAttemptsLimit == 0 {
+		return fmt.Errorf("signing attempts limit must not be zero")
+	}
+
+	return nil
+}
+
+// coordinationDurationBlocks returns the number of blocks in a single
+// coordination window.
+func (pt *ProtocolTimings) coordinationDurationBlocks() uint64 {
+	return pt.CoordinationActivePhaseDurationBlocks +
+		pt.CoordinationPassivePhaseDurationBlocks
+}
+
+// coordinationKeyRefreshFrequencyWindows returns the number of coordination
+// windows between subsequent checks of the key refresh action. It is at
+// least 1 so profiles with long coordination windows check the key refresh
+// in every window.
+func (pt *ProtocolTimings) coordinationKeyRefreshFrequencyWindows() uint64 {
+	windows := coordinationKeyRefreshFrequencyBlocks /
+		pt.CoordinationFrequencyBlocks
+	if windows == 0 {
+		return 1
+	}
+
+	return windows
+}
+
+// dkgAttemptMaximumBlocks returns the maximum block duration of a single
+// DKG attempt.
+func (pt *ProtocolTimings) dkgAttemptMaximumBlocks() uint {
+	return dkgAttemptAnnouncementDelayBlocks +
+		dkgAttemptAnnouncementActiveBlocks +
+		uint(pt.DKGAttemptMaximumProtocolBlocks) +
+		uint(pt.DKGAttemptCoolDownBlocks)
+}
+
+// signingAttemptMaximumBlocks returns the maximum block duration of a single
+// signing attempt.
+func (pt *ProtocolTimings) signingAttemptMaximumBlocks() uint {
+	return signingAttemptAnnouncementDelayBlocks +
+		signingAttemptAnnouncementActiveBlocks +
+		uint(pt.SigningAttemptMaximumProtocolBlocks) +
+		uint(pt.SigningAttemptCoolDownBlocks)
+}
+
+// validateAgainstDKGParameters checks whether the profile is consistent
+// with the given DKG parameters loaded from the chain. The last member must
+// be able to submit the DKG result before the on-chain submission timeout.
+func (pp *ProtocolProfile) validateAgainstDKGParameters(
+	dkgParameters *DKGParameters,
+) error {
+	lastMemberDelayBlocks := uint64(pp.GroupSize-1) *
+		pp.DKGResultSubmissionDelayStepBlocks
+
+	if lastMemberDelayBlocks >= dkgParameters.SubmissionTimeoutBlocks {
+		return fmt.Errorf(
+			"DKG result submission delay of the last member [%v] exceeds "+
+				"the on-chain submission timeout [%v]",
+			lastMemberDelayBlocks,
+			dkgParameters.SubmissionTimeoutBlocks,
+		)
+	}
+
+	return nil
+}
@@ -0,0 +1,237 @@
+package tbtc
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/keep-network/keep-core/internal/testutils"
+)
+
+func TestResolveProtocolProfile(t *testing.T) {
+	chainGroupParameters := &GroupParameters{
+		GroupSize:       100,
+		GroupQuorum:     90,
+		HonestThreshold: 51,
+	}
+
+	var tests = map[string]struct {
+		config               Config
+		chainGroupParameters *GroupParameters
+		expectedProfile      ProtocolProfile
+		expectedError        string
+	}{
+		"default profile": {
+			config:               Config{},
+			chainGroupParameters: chainGroupParameters,
+			expectedProfile: ProtocolProfile{
+				GroupParameters: *chainGroupParameters,
+				ProtocolTimings: protocolTimings[MainnetProtocolProfile],
+			},
+		},
+		"developer profile": {
+			config: Config{ProtocolProfile: DeveloperProtocolProfile},
+			chainGroupParameters: &GroupParameters{
+				GroupSize:       5,
+				GroupQuorum:     4,
+				HonestThreshold: 3,
+			},
+			expectedProfile: ProtocolProfile{
+				GroupParameters: GroupParameters{
+					GroupSize:       5,
+					GroupQuorum:     4,
+					HonestThreshold: 3,
+				},
+				ProtocolTimings: protocolTimings[DeveloperProtocolProfile],
+			},
+		},
+		"unknown profile": {
+			config:               Config{ProtocolProfile: "testnet"},
+			chainGroupParameters: chainGroupParameters,
+			expectedError:        "unknown protocol profile [testnet]; available profiles: [developer mainnet]",
+		},
+		"missing group size": {
+			config: Config{},
+			chainGroupParameters: &GroupParameters{
+				GroupQuorum:     90,
+				HonestThreshold: 51,
+			},
+			expectedError: "missing on-chain group size",
+		},
+		"missing group quorum": {
+			config: Config{},
+			chainGroupParameters: &GroupParameters{
+				GroupSize:       100,
+				HonestThreshold: 51,
+			},
+			expectedError: "missing on-chain group quorum",
+		},
+		"missing honest threshold": {
+			config: Config{},
+			chainGroupParameters: &GroupParameters{
+				GroupSize:   100,
+				GroupQuorum: 90,
+			},
+			expectedError: "missing on-chain honest threshold",
+		},
+		"quorum smaller than honest threshold": {
+			config: Config{ProtocolProfile: DeveloperProtocolProfile},
+			chainGroupParameters: &GroupParameters{
+				GroupSize:       5,
+				GroupQuorum:     2,
+				HonestThreshold: 3,
+			},
+			expectedError: "invalid protocol profile [developer]: [group quorum [2] must not be smaller than honest threshold [3]]",
+		},
+		"group size smaller than quorum": {
+			config: Config{},
+			chainGroupParameters: &GroupParameters{
+				GroupSize:       80,
+				GroupQuorum:     90,
+				HonestThreshold: 51,
+			},
+			expectedError: "invalid protocol profile [mainnet]: [group size [80] must not be smaller than group quorum [90]]",
+		},
+	}
+
+	for testName, test := range tests {
+		t.Run(testName, func(t *testing.T) {
+			profile, err := resolveProtocolProfile(
+				test.config,
+				test.chainGroupParameters,
+			)
+
+			if len(test.expectedError) > 0 {
+				if err == nil {
+					t.Fatal("expected error")
+				}
+
+				testutils.AssertStringsEqual(
+					t,
+					"error",
+					test.expectedError,
+					err.Error(),
+				)
+				return
+			}
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !reflect.DeepEqual(test.expectedProfile, *profile) {
+				t.Errorf(
+					"unexpected profile\nexpected: %+v\nactual:   %+v",
+					test.expectedProfile,
+					*profile,
+				)
+			}
+		})
+	}
+}
+
+func TestProtocolTimings_DeveloperProfile(t *testing.T) {
+	developerTimings := protocolTimings[DeveloperProtocolProfile]
+	timings := &developerTimings
+
+	window := newCoordinationWindow(180, timings)
+
+	testutils.AssertUintsEqual(t, "window index", 3, window.index())
+	testutils.AssertUintsEqual(
+		t,
+		"active phase end block",
+		195,
+		window.activePhaseEndBlock(),
+	)
+	testutils.AssertUintsEqual(t, "end block", 200, window.endBlock())
+	testutils.AssertUintsEqual(
+		t,
+		"signing attempt maximum blocks",
+		uint64(
+			signingAttemptAnnouncementDelayBlocks+
+				signingAttemptAnnouncementActiveBlocks,
+		)+15+2,
+		uint64(timings.signingAttemptMaximumBlocks()),
+	)
+	testutils.AssertUintsEqual(
+		t,
+		"DKG attempt maximum blocks",
+		uint64(
+			dkgAttemptAnnouncementDelayBlocks+
+				dkgAttemptAnnouncementActiveBlocks,
+		)+30+2,
+		uint64(timings.dkgAttemptMaximumBlocks()),
+	)
+	// Key refresh is checked with the same block frequency as on mainnet,
+	// i.e. every 56 windows of 900 blocks.
+	testutils.AssertUintsEqual(
+		t,
+		"key refresh frequency windows",
+		840,
+		timings.coordinationKeyRefreshFrequencyWindows(),
+	)
+	testutils.AssertUintsEqual(
+		t,
+		"mainnet key refresh frequency windows",
+		56,
+		mainnetProtocolTimings().coordinationKeyRefreshFrequencyWindows(),
+	)
+
+	// Windows determined by other timings must not be affected.
+	mainnetWindow := newCoordinationWindow(180, mainnetProtocolTimings())
+	testutils.AssertUintsEqual(t, "mainnet window index", 0, mainnetWindow.index())
+}
+
+func TestProtocolProfile_ValidateAgainstDKGParameters(t *testing.T) {
+	profile := ProtocolProfile{
+		GroupParameters: GroupParameters{
+			GroupSize:       100,
+			GroupQuorum:     90,
+			HonestThreshold: 51,
+		},
+		ProtocolTimings: protocolTimings[MainnetProtocolProfile],
+	}
+
+	var tests = map[string]struct {
+		dkgParameters *DKGParameters
+		expectedError string
+	}{
+		"consistent": {
+			dkgParameters: &DKGParameters{SubmissionTimeoutBlocks: 536},
+		},
+		"submission timeout too short": {
+			dkgParameters: &DKGParameters{SubmissionTimeoutBlocks: 100},
+			expectedError: "DKG result submission delay of the last member [297] exceeds " +
+				"the on-chain submission timeout [100]",
+		},
+	}
+
+	for testName, test := range tests {
+		t.Run(testName, func(t *testing.T) {
+			err := profile.validateAgainstDKGParameters(test.dkgParameters)
+
+			if len(test.expectedError) == 0 {
+				if err != nil {
+					t.Fatalf("unexpected error: [%v]", err)
+				}
+				return
+			}
+
+			if err == nil {
+				t.Fatal("expected error")
+			}
+
+			testutils.AssertStringsEqual(
+				t,
+				"error",
+				test.expectedError,
+				err.Error(),
+			)
+		})
+	}
+}
+
+// mainnetProtocolTimings returns a copy of the mainnet protocol timings.
+func mainnetProtocolTimings() *ProtocolTimings {
+	timings := protocolTimings[MainnetProtocolProfile]
+	return &timings
+}
@@ -1,7 +1,6 @@
 package tbtc
 
 import (
-	"crypto/ecdsa"
 	"fmt"
 	"math/big"
 	"time"
@@ -208,7 +207,7 @@ func (ra *redemptionAction) execute() error {
 
 	unsignedRedemptionTx, err := assembleRedemptionTransaction(
 		ra.btcChain,
-		ra.wallet().publicKey,
+		walletPublicKeyHash,
 		walletMainUtxo,
 		validatedRequests,
 		ra.feeDistribution,
@@ -363,6 +362,64 @@ func withRedemptionTotalFee(totalFee int64) redemptionFeeDistributionFn {
 	}
 }
 
+// AssembleRedemptionPSBT validates the given redemption proposal and
+// assembles the unsigned redemption transaction the wallet would sign
+// while executing the proposal. The transaction is returned as a BIP-0174
+// PSBT so it can be inspected using standard Bitcoin tooling.
+func AssembleRedemptionPSBT(
+	validateProposalLogger log.StandardLogger,
+	walletPublicKeyHash [20]byte,
+	proposal *RedemptionProposal,
+	chain interface {
+		BridgeChain
+		WalletProposalValidatorChain
+	},
+	btcChain bitcoin.Chain,
+) (*bitcoin.PSBT, error) {
+	validatedRequests, err := ValidateRedemptionProposal(
+		validateProposalLogger,
+		walletPublicKeyHash,
+		proposal,
+		chain,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("cannot validate proposal: [%v]", err)
+	}
+
+	walletMainUtxo, err := DetermineWalletMainUtxo(
+		walletPublicKeyHash,
+		chain,
+		btcChain,
+	)
+	if err != nil {
+		return nil, fmt.Errorf(
+			"error while determining wallet's main UTXO: [%v]",
+			err,
+		)
+	}
+
+	if walletMainUtxo == nil {
+		return nil, fmt.Errorf("redeeming wallet has no main UTXO")
+	}
+
+	unsignedRedemptionTx, err := assembleRedemptionTransaction(
+		btcChain,
+		walletPublicKeyHash,
+		walletMainUtxo,
+		validatedRequests,
+		withRedemptionTotalFee(proposal.RedemptionTxFee.Int64()),
+		RedemptionChangeFirst,
+	)
+	if err != nil {
+		return nil, fmt.Errorf(
+			"error while assembling redemption transaction: [%v]",
+			err,
+		)
+	}
+
+	return unsignedRedemptionTx.ToPSBT()
+}
+
 // assembleRedemptionTransaction constructs an unsigned redemption Bitcoin
 // transaction.
 //
@@ -377,7 +434,7 @@ func withRedemptionTotalFee(totalFee int64) redemptionFeeDistributionFn {
 // ready to be spread across the Bitcoin network.
 func assembleRedemptionTransaction(
 	bitcoinChain bitcoin.Chain,
-	walletPublicKey *ecdsa.PublicKey,
+	walletPublicKeyHash [20]byte,
 	walletMainUtxo *bitcoin.UnspentTransactionOutput,
 	requests []*RedemptionRequest,
 	feeDistribution redemptionFeeDistributionFn,
@@ -457,7 +514,7 @@ func assembleRedemptionTransaction(
 	// If we can have a non-zero change, construct it.
 	if changeOutputValue > 0 {
 		changeOutputScript, err := bitcoin.PayToWitnessPublicKeyHash(
-			bitcoin.PublicKeyHash(walletPublicKey),
+			walletPublicKeyHash,
 		)
 		if err != nil {
 			return nil, fmt.Errorf(
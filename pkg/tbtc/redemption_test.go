@@ -212,7 +212,7 @@ func TestAssembleRedemptionTransaction(t *testing.T) {
 
 			builder, err := assembleRedemptionTransaction(
 				bitcoinChain,
-				scenario.WalletPublicKey,
+				bitcoin.PublicKeyHash(scenario.WalletPublicKey),
 				scenario.WalletMainUtxo,
 				requests,
 				feeDistribution,
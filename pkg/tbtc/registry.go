@@ -8,6 +8,7 @@ import (
 	"sync"
 
 	"github.com/keep-network/keep-core/pkg/bitcoin"
+	"github.com/keep-network/keep-core/pkg/protocol/group"
 
 	"github.com/keep-network/keep-common/pkg/persistence"
 )
@@ -153,6 +154,135 @@ func (wr *walletRegistry) registerSigner(signer *signer) error {
 	return nil
 }
 
+// replaceSigners replaces signers of the given wallet with their refreshed
+// counterparts. Each refreshed signer replaces the registered signer with the
+// same signing group member index. The refreshed signers must belong to the
+// same wallet and hold key shares corresponding to the same wallet public key.
+//
+// The replacement is all or nothing. All signers registered for the wallet
+// must be refreshed, each exactly once and with the refresh epoch following
+// the epoch of the current signer. Otherwise, the node would hold key shares
+// of different epochs for the same wallet. Current signers are snapshotted
+// in the wallet storage first so the replaced key shares are never lost.
+// Then, refreshed signers are saved in place of the current ones. If any of
+// them cannot be saved, already saved ones are reverted and the wallet cache
+// is left untouched.
+func (wr *walletRegistry) replaceSigners(
+	walletPublicKey *ecdsa.PublicKey,
+	refreshedSigners []*signer,
+) error {
+	wr.mutex.Lock()
+	defer wr.mutex.Unlock()
+
+	walletStorageKey := getWalletStorageKey(walletPublicKey)
+
+	value, ok := wr.walletCache[walletStorageKey]
+	if !ok {
+		return fmt.Errorf("wallet not found in the wallet cache")
+	}
+
+	currentSigners := make(map[group.MemberIndex]*signer)
+	for _, currentSigner := range value.signers {
+		currentSigners[currentSigner.signingGroupMemberIndex] = currentSigner
+	}
+
+	if len(refreshedSigners) != len(currentSigners) {
+		return fmt.Errorf(
+			"[%v] refreshed signers given for [%v] signers registered "+
+				"for the wallet",
+			len(refreshedSigners),
+			len(currentSigners),
+		)
+	}
+
+	replacedSigners := make([]*signer, len(refreshedSigners))
+	for i, refreshedSigner := range refreshedSigners {
+		if getWalletStorageKey(refreshedSigner.wallet.publicKey) != walletStorageKey ||
+			getWalletStorageKey(refreshedSigner.privateKeyShare.PublicKey()) != walletStorageKey {
+			return fmt.Errorf(
+				"refreshed signer [%v] does not belong to the wallet",
+				refreshedSigner.signingGroupMemberIndex,
+			)
+		}
+
+		currentSigner, ok := currentSigners[refreshedSigner.signingGroupMemberIndex]
+		if !ok {
+			return fmt.Errorf(
+				"signer [%v] is not registered for the wallet",
+				refreshedSigner.signingGroupMemberIndex,
+			)
+		}
+
+		if refreshedSigner.refreshEpoch != currentSigner.refreshEpoch+1 {
+			return fmt.Errorf(
+				"refreshed signer [%v] has refresh epoch [%v]; expected [%v]",
+				refreshedSigner.signingGroupMemberIndex,
+				refreshedSigner.refreshEpoch,
+				currentSigner.refreshEpoch+1,
+			)
+		}
+
+		// Remove the matched signer so a duplicated refreshed signer is
+		// reported as not registered.
+		delete(currentSigners, refreshedSigner.signingGroupMemberIndex)
+
+		replacedSigners[i] = currentSigner
+	}
+
+	for _, replacedSigner := range replacedSigners {
+		err := wr.walletStorage.snapshotSigner(replacedSigner)
+		if err != nil {
+			return fmt.Errorf(
+				"cannot snapshot signer [%v] in the storage: [%w]",
+				replacedSigner.signingGroupMemberIndex,
+				err,
+			)
+		}
+	}
+
+	for i, refreshedSigner := range refreshedSigners {
+		err := wr.walletStorage.saveSigner(refreshedSigner)
+		if err != nil {
+			// Revert signers saved so far. The current signers were
+			// snapshotted so, even if reverting fails, they can be
+			// restored from the snapshot.
+			for _, replacedSigner := range replacedSigners[:i] {
+				if revertErr := wr.walletStorage.saveSigner(
+					replacedSigner,
+				); revertErr != nil {
+					logger.Errorf(
+						"cannot revert signer [%v] in the storage: [%v]",
+						replacedSigner.signingGroupMemberIndex,
+						revertErr,
+					)
+				}
+			}
+
+			return fmt.Errorf(
+				"cannot save signer [%v] in the storage: [%w]",
+				refreshedSigner.signingGroupMemberIndex,
+				err,
+			)
+		}
+	}
+
+	// Build a new slice instead of modifying the current one in place as
+	// the current slice may still be used by the callers of getSigners.
+	signers := make([]*signer, len(value.signers))
+	copy(signers, value.signers)
+	for _, refreshedSigner := range refreshedSigners {
+		for i := range signers {
+			if signers[i].signingGroupMemberIndex ==
+				refreshedSigner.signingGroupMemberIndex {
+				signers[i] = refreshedSigner
+			}
+		}
+	}
+	value.signers = signers
+
+	return nil
+}
+
 // getSigners gets all signers for the given wallet held by the walletRegistry.
 func (wr *walletRegistry) getSigners(
 	walletPublicKey *ecdsa.PublicKey,
@@ -280,6 +410,32 @@ func (ws *walletStorage) saveSigner(signer *signer) error {
 	return nil
 }
 
+// snapshotSigner saves a unique snapshot of the given signer using the
+// underlying persistence layer of the walletStorage. Snapshots are not loaded
+// upon the registry initialization and serve as a backup of signers being
+// replaced. It should not be called from any other place than walletRegistry.
+func (ws *walletStorage) snapshotSigner(signer *signer) error {
+	signerBytes, err := signer.Marshal()
+	if err != nil {
+		return fmt.Errorf("could not marshal signer: [%w]", err)
+	}
+
+	err = ws.persistence.Snapshot(
+		signerBytes,
+		getWalletStorageKey(signer.wallet.publicKey),
+		fmt.Sprintf("/membership_%v", signer.signingGroupMemberIndex),
+	)
+	if err != nil {
+		return fmt.Errorf(
+			"could not snapshot membership using the "+
+				"underlying persistence layer: [%w]",
+			err,
+		)
+	}
+
+	return nil
+}
+
 // archiveWallet archives the given wallet data in the underlying persistence
 // layer of the walletStorage.
 func (ws *walletStorage) archiveWallet(walletStorageKey string) error {
@@ -402,6 +402,288 @@ func TestWalletRegistry_ArchiveWallet_NotFound(t *testing.T) {
 	}
 }
 
+func TestWalletRegistry_ReplaceSigners(t *testing.T) {
+	persistenceHandle := &mockPersistenceHandle{}
+	chain := Connect()
+
+	walletRegistry, err := newWalletRegistry(
+		persistenceHandle,
+		chain.CalculateWalletID,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	originalSigner := createMockSigner(t)
+
+	err = walletRegistry.registerSigner(originalSigner)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	signersBefore := walletRegistry.getSigners(originalSigner.wallet.publicKey)
+
+	refreshedSigner := createMockRefreshedSigner(t, originalSigner)
+
+	err = walletRegistry.replaceSigners(
+		originalSigner.wallet.publicKey,
+		[]*signer{refreshedSigner},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	signers := walletRegistry.getSigners(originalSigner.wallet.publicKey)
+
+	testutils.AssertIntsEqual(t, "signers count", 1, len(signers))
+
+	if signers[0] != refreshedSigner {
+		t.Errorf("registered signer has not been replaced")
+	}
+
+	// The slice obtained before the replacement must not be modified.
+	if signersBefore[0] != originalSigner {
+		t.Errorf("previously obtained signers slice has been modified")
+	}
+
+	testutils.AssertIntsEqual(
+		t,
+		"snapshots count",
+		1,
+		len(persistenceHandle.snapshots),
+	)
+
+	snapshotContent, err := persistenceHandle.snapshots[0].Content()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	snapshottedSigner := &signer{}
+	if err := snapshottedSigner.Unmarshal(snapshotContent); err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(originalSigner, snapshottedSigner) {
+		t.Errorf("snapshotted signer differs from the replaced one")
+	}
+
+	// The mock persistence handle appends saved data so, the last
+	// saved entry must be the refreshed signer.
+	savedContent, err := persistenceHandle.saved[len(persistenceHandle.saved)-1].Content()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	savedSigner := &signer{}
+	if err := savedSigner.Unmarshal(savedContent); err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(refreshedSigner, savedSigner) {
+		t.Errorf("saved signer differs from the refreshed one")
+	}
+}
+
+func TestWalletRegistry_ReplaceSigners_NotRegistered(t *testing.T) {
+	persistenceHandle := &mockPersistenceHandle{}
+	chain := Connect()
+
+	walletRegistry, err := newWalletRegistry(
+		persistenceHandle,
+		chain.CalculateWalletID,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	originalSigner := createMockSigner(t)
+
+	err = walletRegistry.registerSigner(originalSigner)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	refreshedSigner := createMockRefreshedSigner(t, originalSigner)
+	refreshedSigner.signingGroupMemberIndex = 2
+
+	err = walletRegistry.replaceSigners(
+		originalSigner.wallet.publicKey,
+		[]*signer{refreshedSigner},
+	)
+
+	expectedErr := fmt.Errorf("signer [2] is not registered for the wallet")
+	if !reflect.DeepEqual(err, expectedErr) {
+		t.Fatalf(
+			"unexpected error\nexpected: %v\nactual:   %v",
+			expectedErr,
+			err,
+		)
+	}
+
+	testutils.AssertIntsEqual(
+		t,
+		"snapshots count",
+		0,
+		len(persistenceHandle.snapshots),
+	)
+}
+
+func TestWalletRegistry_ReplaceSigners_Partial(t *testing.T) {
+	persistenceHandle := &mockPersistenceHandle{}
+	chain := Connect()
+
+	walletRegistry, err := newWalletRegistry(
+		persistenceHandle,
+		chain.CalculateWalletID,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	signer1 := createMockSigner(t)
+	signer2 := createMockSigner(t)
+	signer2.signingGroupMemberIndex = 2
+
+	for _, registeredSigner := range []*signer{signer1, signer2} {
+		if err := walletRegistry.registerSigner(registeredSigner); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Only one of the controlled signers is refreshed.
+	err = walletRegistry.replaceSigners(
+		signer1.wallet.publicKey,
+		[]*signer{createMockRefreshedSigner(t, signer1)},
+	)
+
+	expectedErr := fmt.Errorf(
+		"[1] refreshed signers given for [2] signers registered for the wallet",
+	)
+	if !reflect.DeepEqual(err, expectedErr) {
+		t.Fatalf(
+			"unexpected error\nexpected: %v\nactual:   %v",
+			expectedErr,
+			err,
+		)
+	}
+
+	signers := walletRegistry.getSigners(signer1.wallet.publicKey)
+	if signers[0] != signer1 || signers[1] != signer2 {
+		t.Errorf("registered signers have been replaced")
+	}
+
+	testutils.AssertIntsEqual(
+		t,
+		"snapshots count",
+		0,
+		len(persistenceHandle.snapshots),
+	)
+}
+
+func TestWalletRegistry_ReplaceSigners_InvalidRefreshEpoch(t *testing.T) {
+	persistenceHandle := &mockPersistenceHandle{}
+	chain := Connect()
+
+	walletRegistry, err := newWalletRegistry(
+		persistenceHandle,
+		chain.CalculateWalletID,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	originalSigner := createMockSigner(t)
+	originalSigner.refreshEpoch = 3
+
+	err = walletRegistry.registerSigner(originalSigner)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Simulate a stale refreshed signer, e.g. restored from a backup.
+	refreshedSigner := createMockRefreshedSigner(t, originalSigner)
+	refreshedSigner.refreshEpoch = 2
+
+	err = walletRegistry.replaceSigners(
+		originalSigner.wallet.publicKey,
+		[]*signer{refreshedSigner},
+	)
+
+	expectedErr := fmt.Errorf(
+		"refreshed signer [1] has refresh epoch [2]; expected [4]",
+	)
+	if !reflect.DeepEqual(err, expectedErr) {
+		t.Fatalf(
+			"unexpected error\nexpected: %v\nactual:   %v",
+			expectedErr,
+			err,
+		)
+	}
+
+	signers := walletRegistry.getSigners(originalSigner.wallet.publicKey)
+	if signers[0] != originalSigner {
+		t.Errorf("registered signer has been replaced")
+	}
+}
+
+func TestWalletRegistry_ReplaceSigners_SaveFailed(t *testing.T) {
+	persistenceHandle := &failingSavePersistenceHandle{
+		mockPersistenceHandle: &mockPersistenceHandle{},
+	}
+	chain := Connect()
+
+	walletRegistry, err := newWalletRegistry(
+		persistenceHandle,
+		chain.CalculateWalletID,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	signer1 := createMockSigner(t)
+	signer2 := createMockSigner(t)
+	signer2.signingGroupMemberIndex = 2
+
+	for _, registeredSigner := range []*signer{signer1, signer2} {
+		if err := walletRegistry.registerSigner(registeredSigner); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	refreshedSigner1 := createMockRefreshedSigner(t, signer1)
+	refreshedSigner2 := createMockRefreshedSigner(t, signer2)
+
+	persistenceHandle.failingName = "/membership_2"
+
+	err = walletRegistry.replaceSigners(
+		signer1.wallet.publicKey,
+		[]*signer{refreshedSigner1, refreshedSigner2},
+	)
+	if err == nil {
+		t.Fatal("expected error")
+	}
+
+	signers := walletRegistry.getSigners(signer1.wallet.publicKey)
+	if signers[0] != signer1 || signers[1] != signer2 {
+		t.Errorf("registered signers have been replaced")
+	}
+
+	// The first refreshed signer must be reverted in the storage.
+	savedContent, err := persistenceHandle.saved[len(persistenceHandle.saved)-1].Content()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	savedSigner := &signer{}
+	if err := savedSigner.Unmarshal(savedContent); err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(signer1, savedSigner) {
+		t.Errorf("refreshed signer has not been reverted in the storage")
+	}
+}
+
 func TestWalletStorage_SaveSigner(t *testing.T) {
 	persistenceHandle := &mockPersistenceHandle{}
 
@@ -464,6 +746,44 @@ func TestWalletStorage_LoadSigners(t *testing.T) {
 	}
 }
 
+func TestWalletStorage_SnapshotSigner(t *testing.T) {
+	persistenceHandle := &mockPersistenceHandle{}
+
+	walletStorage := newWalletStorage(persistenceHandle)
+
+	signer := createMockSigner(t)
+
+	err := walletStorage.snapshotSigner(signer)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testutils.AssertIntsEqual(
+		t,
+		"persisted wallet signers count",
+		0,
+		len(persistenceHandle.saved),
+	)
+	testutils.AssertIntsEqual(
+		t,
+		"wallet signer snapshots count",
+		1,
+		len(persistenceHandle.snapshots),
+	)
+	testutils.AssertStringsEqual(
+		t,
+		"snapshot directory",
+		getWalletStorageKey(signer.wallet.publicKey),
+		persistenceHandle.snapshots[0].Directory(),
+	)
+	testutils.AssertStringsEqual(
+		t,
+		"snapshot name",
+		"/membership_1",
+		persistenceHandle.snapshots[0].Name(),
+	)
+}
+
 func TestWalletStorage_ArchiveWallet(t *testing.T) {
 	persistenceHandle := &mockPersistenceHandle{}
 
@@ -499,8 +819,9 @@ func TestWalletStorage_ArchiveWallet(t *testing.T) {
 }
 
 type mockPersistenceHandle struct {
-	saved    []persistence.DataDescriptor
-	archived []string
+	saved     []persistence.DataDescriptor
+	snapshots []persistence.DataDescriptor
+	archived  []string
 }
 
 func (mph *mockPersistenceHandle) Save(
@@ -522,7 +843,13 @@ func (mph *mockPersistenceHandle) Snapshot(
 	directory string,
 	name string,
 ) error {
-	panic("not implemented")
+	mph.snapshots = append(mph.snapshots, &mockDescriptor{
+		name:      name,
+		directory: directory,
+		content:   data,
+	})
+
+	return nil
 }
 
 func (mph *mockPersistenceHandle) ReadAll() (
@@ -548,7 +875,57 @@ func (mph *mockPersistenceHandle) Archive(directory string) error {
 }
 
 func (mph *mockPersistenceHandle) Delete(directory string, name string) error {
-	panic("not implemented")
+	for i, descriptor := range mph.saved {
+		if descriptor.Directory() == directory && descriptor.Name() == name {
+			mph.saved = append(mph.saved[:i], mph.saved[i+1:]...)
+			return nil
+		}
+	}
+
+	return fmt.Errorf("file [%s] not found in directory [%s]", name, directory)
+}
+
+// failingSavePersistenceHandle is a mockPersistenceHandle that fails to
+// save data with the given name.
+type failingSavePersistenceHandle struct {
+	*mockPersistenceHandle
+
+	failingName string
+}
+
+func (fsph *failingSavePersistenceHandle) Save(
+	data []byte,
+	directory string,
+	name string,
+) error {
+	if name == fsph.failingName {
+		return fmt.Errorf("cannot save [%s]", name)
+	}
+
+	return fsph.mockPersistenceHandle.Save(data, directory, name)
+}
+
+// createMockRefreshedSigner creates a copy of the given signer whose private
+// key share is changed but still corresponds to the same wallet public key.
+func createMockRefreshedSigner(t *testing.T, original *signer) *signer {
+	data := original.privateKeyShare.Data()
+	data.Xi = new(big.Int).Add(data.Xi, big.NewInt(1))
+
+	refreshedPrivateKeyShare := tecdsa.NewPrivateKeyShare(data)
+
+	if !reflect.DeepEqual(
+		original.privateKeyShare.PublicKey(),
+		refreshedPrivateKeyShare.PublicKey(),
+	) {
+		t.Fatal("refreshed private key share has different public key")
+	}
+
+	return &signer{
+		wallet:                  original.wallet,
+		signingGroupMemberIndex: original.signingGroupMemberIndex,
+		privateKeyShare:         refreshedPrivateKeyShare,
+		refreshEpoch:            original.refreshEpoch + 1,
+	}
 }
 
 type mockDescriptor struct {
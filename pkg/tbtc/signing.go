@@ -17,23 +17,6 @@ import (
 	"golang.org/x/sync/semaphore"
 )
 
-const (
-	// signingBatchInterludeBlocks determines the block duration of the
-	// interlude preserved between subsequent signings in a signing batch.
-	// If the signing of the previous message completed at block X, the signing
-	// of the next message starts at `X + signingBatchInterludeBlocks`.
-	// This is the additional time signers have to realize that the signing is
-	// done by receiving the signingDoneMessage. Note that the end block of the
-	// previous signing used to establish the start block of the next signing
-	// comes from signingDoneMessage received and there is no guarantee all
-	// signing group members received signingDoneMessage before the highest
-	// endBlock is reached on the chain. The interlude is an additional time for
-	// the broadcast channel to spread information about signing successfully
-	// completed by the slowest signing group member (the one who sends the
-	// signingDoneMessage as the last one).
-	signingBatchInterludeBlocks = 2
-)
-
 // errSigningExecutorBusy is an error returned when the signing executor
 // cannot execute the requested signature due to an ongoing signing.
 var errSigningExecutorBusy = fmt.Errorf("signing executor is busy")
@@ -47,6 +30,7 @@ type signingExecutor struct {
 	broadcastChannel    net.BroadcastChannel
 	membershipValidator *group.MembershipValidator
 	groupParameters     *GroupParameters
+	timings             *ProtocolTimings
 	protocolLatch       *generator.ProtocolLatch
 
 	// getCurrentBlockFn is a function used to get the current block.
@@ -58,6 +42,13 @@ type signingExecutor struct {
 	// be made by a single signer for the given message. Once the attempts
 	// limit is hit the signer gives up.
 	signingAttemptsLimit uint
+
+	// metrics keeps operational metrics of the wallet. It may be nil.
+	metrics *walletMetrics
+
+	// observer records messages that would have been signed if the node
+	// operates in the observer mode. It is nil otherwise.
+	observer *observerRecorder
 }
 
 func newSigningExecutor(
@@ -65,10 +56,10 @@ func newSigningExecutor(
 	broadcastChannel net.BroadcastChannel,
 	membershipValidator *group.MembershipValidator,
 	groupParameters *GroupParameters,
+	timings *ProtocolTimings,
 	protocolLatch *generator.ProtocolLatch,
 	getCurrentBlockFn getCurrentBlockFn,
 	waitForBlockFn waitForBlockFn,
-	signingAttemptsLimit uint,
 ) *signingExecutor {
 	return &signingExecutor{
 		lock:                 semaphore.NewWeighted(1),
@@ -76,10 +67,11 @@ func newSigningExecutor(
 		broadcastChannel:     broadcastChannel,
 		membershipValidator:  membershipValidator,
 		groupParameters:      groupParameters,
+		timings:              timings,
 		protocolLatch:        protocolLatch,
 		getCurrentBlockFn:    getCurrentBlockFn,
 		waitForBlockFn:       waitForBlockFn,
-		signingAttemptsLimit: signingAttemptsLimit,
+		signingAttemptsLimit: timings.SigningAttemptsLimit,
 	}
 }
 
@@ -88,11 +80,13 @@ func newSigningExecutor(
 // this function returns an error. If all messages were signed successfully,
 // a slice of signatures is returned. Order of the returned signatures matches
 // the order of the messages in the batch, i.e. the first signature corresponds
-// to the first message, and so on.
+// to the first message, and so on. The action type denotes the wallet action
+// the messages are signed for and is used to label signing metrics.
 func (se *signingExecutor) signBatch(
 	ctx context.Context,
 	messages []*big.Int,
 	startBlock uint64,
+	actionType WalletActionType,
 ) ([]*tecdsa.Signature, error) {
 	wallet := se.wallet()
 
@@ -142,10 +136,16 @@ func (se *signingExecutor) signBatch(
 		signingBatchMessageLogger.Infof("generating signature for message")
 
 		if i > 0 {
-			signingStartBlock = endBlocks[i-1] + signingBatchInterludeBlocks
+			signingStartBlock = endBlocks[i-1] +
+				se.timings.SigningBatchInterludeBlocks
 		}
 
-		signature, _, endBlock, err := se.sign(ctx, message, signingStartBlock)
+		signature, _, endBlock, err := se.sign(
+			ctx,
+			message,
+			signingStartBlock,
+			actionType,
+		)
 		if err != nil {
 			return nil, err
 		}
@@ -169,11 +169,13 @@ func (se *signingExecutor) signBatch(
 // signed successfully, this function returns the signature along with the
 // number of active members that participated in signing, the block at which the
 // signature was calculated. The end block is common for all wallet signers so
-// can be used as a synchronization point.
+// can be used as a synchronization point. The action type denotes the wallet
+// action the message is signed for and is used to label signing metrics.
 func (se *signingExecutor) sign(
 	ctx context.Context,
 	message *big.Int,
 	startBlock uint64,
+	actionType WalletActionType,
 ) (*tecdsa.Signature, *signingActivityReport, uint64, error) {
 	if lockAcquired := se.lock.TryAcquire(1); !lockAcquired {
 		return nil, nil, 0, errSigningExecutorBusy
@@ -188,7 +190,7 @@ func (se *signingExecutor) sign(
 	}
 
 	loopTimeoutBlock := startBlock +
-		uint64(se.signingAttemptsLimit*signingAttemptMaximumBlocks())
+		uint64(se.signingAttemptsLimit*se.timings.signingAttemptMaximumBlocks())
 
 	signingLogger := logger.With(
 		zap.String("wallet", fmt.Sprintf("0x%x", walletPublicKeyBytes)),
@@ -197,6 +199,19 @@ func (se *signingExecutor) sign(
 		zap.Uint64("signingTimeoutBlock", loopTimeoutBlock),
 	)
 
+	if se.observer != nil {
+		se.observer.record(
+			ObserverRecordSigning,
+			map[string]interface{}{
+				"wallet":     fmt.Sprintf("0x%x", walletPublicKeyBytes),
+				"message":    fmt.Sprintf("0x%x", message),
+				"startBlock": startBlock,
+			},
+		)
+		signingLogger.Infof("observer mode; not producing signature shares")
+		return nil, nil, 0, errObserverMode
+	}
+
 	type signingOutcome struct {
 		signature      *tecdsa.Signature
 		activityReport *signingActivityReport
@@ -207,6 +222,11 @@ func (se *signingExecutor) sign(
 	wg.Add(len(se.signers))
 	signingOutcomeChan := make(chan *signingOutcome, len(se.signers))
 
+	// highestAttempt is the highest attempt number executed by any of the
+	// controlled signers. It is used to report signing metrics.
+	highestAttemptMutex := sync.Mutex{}
+	highestAttempt := uint(0)
+
 	for _, currentSigner := range se.signers {
 		go func(signer *signer) {
 			se.protocolLatch.Lock()
@@ -232,7 +252,9 @@ func (se *signingExecutor) sign(
 				startBlock,
 				signer.signingGroupMemberIndex,
 				wallet.signingGroupOperators,
+				signer.refreshEpoch,
 				se.groupParameters,
+				se.timings,
 				announcer,
 				doneCheck,
 			)
@@ -266,6 +288,12 @@ func (se *signingExecutor) sign(
 						zap.Uint64("attemptTimeoutBlock", attempt.timeoutBlock),
 					)
 
+					highestAttemptMutex.Lock()
+					if attempt.number > highestAttempt {
+						highestAttempt = attempt.number
+					}
+					highestAttemptMutex.Unlock()
+
 					signingAttemptLogger.Infof(
 						"[member:%v] starting signing protocol "+
 							"with [%v] group members (excluded: [%v])",
@@ -288,10 +316,9 @@ func (se *signingExecutor) sign(
 						se.waitForBlockFn,
 					)
 
-					sessionID := fmt.Sprintf(
-						"%v-%v",
-						message.Text(16),
-						attempt.number,
+					sessionID := refreshEpochSessionID(
+						fmt.Sprintf("%v-%v", message.Text(16), attempt.number),
+						signer.refreshEpoch,
 					)
 
 					result, err := signing.Execute(
@@ -384,10 +411,26 @@ func (se *signingExecutor) sign(
 	// are done by sending a valid `signingDoneMessage` during the signing done
 	// check phase. If the result was not inserted to the channel by any
 	// signer, that means all signers failed and have not produced a signature.
+	highestAttemptMutex.Lock()
+	attempts := highestAttempt
+	highestAttemptMutex.Unlock()
+
 	select {
 	case outcome := <-signingOutcomeChan:
+		se.metrics.recordSigning(
+			wallet.publicKey,
+			actionType,
+			attempts,
+			true,
+		)
 		return outcome.signature, outcome.activityReport, outcome.endBlock, nil
 	default:
+		se.metrics.recordSigning(
+			wallet.publicKey,
+			actionType,
+			attempts,
+			false,
+		)
 		return nil, nil, 0, fmt.Errorf("all signers failed")
 	}
 }
@@ -28,23 +28,8 @@ const (
 	// announcement phase that is performed at the beginning of each signing
 	// attempt.
 	signingAttemptAnnouncementActiveBlocks = 5
-	// signingAttemptProtocolBlocks determines the maximum block duration of the
-	// actual protocol computations.
-	signingAttemptMaximumProtocolBlocks = 30
-	// signingAttemptCoolDownBlocks determines the duration of the cool down
-	// period that is preserved between subsequent signing attempts.
-	signingAttemptCoolDownBlocks = 5
 )
 
-// signingAttemptMaximumBlocks returns the maximum block duration of a single
-// signing attempt.
-func signingAttemptMaximumBlocks() uint {
-	return signingAttemptAnnouncementDelayBlocks +
-		signingAttemptAnnouncementActiveBlocks +
-		signingAttemptMaximumProtocolBlocks +
-		signingAttemptCoolDownBlocks
-}
-
 // signingAnnouncer represents a component responsible for exchanging readiness
 // announcements for the given signing attempt of the given message.
 type signingAnnouncer interface {
@@ -87,7 +72,12 @@ type signingRetryLoop struct {
 	signingGroupMemberIndex group.MemberIndex
 	signingGroupOperators   chain.Addresses
 
+	// refreshEpoch is the refresh epoch of the member's key share. Only
+	// members holding key shares of the same epoch can sign together.
+	refreshEpoch uint32
+
 	groupParameters *GroupParameters
+	timings         *ProtocolTimings
 
 	announcer signingAnnouncer
 
@@ -104,7 +94,9 @@ func newSigningRetryLoop(
 	initialStartBlock uint64,
 	signingGroupMemberIndex group.MemberIndex,
 	signingGroupOperators chain.Addresses,
+	refreshEpoch uint32,
 	groupParameters *GroupParameters,
+	timings *ProtocolTimings,
 	announcer signingAnnouncer,
 	doneCheck signingDoneCheckStrategy,
 ) *signingRetryLoop {
@@ -120,7 +112,9 @@ func newSigningRetryLoop(
 		message:                 message,
 		signingGroupMemberIndex: signingGroupMemberIndex,
 		signingGroupOperators:   signingGroupOperators,
+		refreshEpoch:            refreshEpoch,
 		groupParameters:         groupParameters,
+		timings:                 timings,
 		announcer:               announcer,
 		attemptCounter:          0,
 		attemptStartBlock:       initialStartBlock,
@@ -192,14 +186,14 @@ func (srl *signingRetryLoop) start(
 		// by some additional delay blocks. We need a small cool down in
 		// order to mitigate all corner cases where the actual attempt duration
 		// was slightly longer than the expected duration determined by the
-		// signingAttemptMaximumProtocolBlocks constant.
+		// SigningAttemptMaximumProtocolBlocks timing.
 		//
 		// For example, the attempt may fail at the end of the protocol but the
 		// error is returned after some time and more blocks than expected are
 		// mined in the meantime.
 		if srl.attemptCounter > 1 {
 			srl.attemptStartBlock = srl.attemptStartBlock +
-				uint64(signingAttemptMaximumBlocks())
+				uint64(srl.timings.signingAttemptMaximumBlocks())
 		}
 
 		srl.logger.Infof(
@@ -260,7 +254,10 @@ func (srl *signingRetryLoop) start(
 		readyMembersIndexes, err := srl.announcer.Announce(
 			announceCtx,
 			srl.signingGroupMemberIndex,
-			fmt.Sprintf("%v-%v", srl.message, srl.attemptCounter),
+			refreshEpochSessionID(
+				fmt.Sprintf("%v-%v", srl.message, srl.attemptCounter),
+				srl.refreshEpoch,
+			),
 		)
 		if err != nil {
 			srl.logger.Warnf(
@@ -332,7 +329,8 @@ func (srl *signingRetryLoop) start(
 			srl.signingGroupMemberIndex,
 		)
 
-		timeoutBlock := announcementEndBlock + signingAttemptMaximumProtocolBlocks
+		timeoutBlock := announcementEndBlock +
+			srl.timings.SigningAttemptMaximumProtocolBlocks
 
 		// doneCheckTimeoutCtx is active until the timeout even if the protocol
 		// completed successfully earlier. This is needed to ensure all protocol
@@ -610,7 +610,9 @@ func TestSigningRetryLoop(t *testing.T) {
 				200,
 				test.signingGroupMemberIndex,
 				signingGroupOperators,
+				0,
 				groupParameters,
+				mainnetProtocolTimings(),
 				announcer,
 				doneCheck,
 			)
@@ -0,0 +1,553 @@
+package tbtc
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/keep-network/keep-core/internal/hexutils"
+	"github.com/keep-network/keep-core/pkg/bitcoin"
+)
+
+// signingPolicyDailyWindow is the length of the rolling window used to
+// enforce the maximum daily value of a wallet.
+const signingPolicyDailyWindow = 24 * time.Hour
+
+// Names of signing policy rules. They are used to label vetoes in logs
+// and metrics.
+const (
+	signingPolicyRuleRedeemerDenylist  = "redeemer_denylist"
+	signingPolicyRuleRedeemerAllowlist = "redeemer_allowlist"
+	signingPolicyRuleFundingOutpoint   = "funding_outpoint"
+	signingPolicyRuleMaxProposalValue  = "max_proposal_value"
+	signingPolicyRuleMaxDailyValue     = "max_daily_value"
+	signingPolicyRuleMaxFeeRate        = "max_fee_rate"
+)
+
+// SigningPolicy is an operator-defined policy evaluated against every wallet
+// transaction before the node takes part in signing it. The policy is applied
+// on top of the on-chain proposal validation and allows the operator to veto
+// proposals that are valid from the protocol perspective but not acceptable
+// for the operator. Zero values of limits and empty lists disable the
+// respective rules.
+type SigningPolicy struct {
+	// RedeemerOutputScriptsDenylist holds hex-encoded output scripts
+	// the node refuses to pay out to. The list applies to all outputs
+	// leaving the wallet, regardless of the action producing the
+	// transaction, so it cannot be bypassed by e.g. a fee bump.
+	RedeemerOutputScriptsDenylist []string `json:"redeemerOutputScriptsDenylist"`
+	// RedeemerOutputScriptsAllowlist holds hex-encoded output scripts.
+	// If not empty, the node pays out only to the listed scripts. Just as
+	// the denylist, it applies to all outputs leaving the wallet so other
+	// wallets receiving moved funds must be listed as well.
+	RedeemerOutputScriptsAllowlist []string `json:"redeemerOutputScriptsAllowlist"`
+	// FundingOutpointsDenylist holds funding outpoints the node refuses to
+	// spend. An entry has the form of `<txhash>:<index>` or just `<txhash>`
+	// to deny all outputs of the given transaction. Transaction hashes are
+	// in the reversed byte order, as presented by block explorers.
+	FundingOutpointsDenylist []string `json:"fundingOutpointsDenylist"`
+	// MaxProposalValue is the maximum value in satoshi moved by a single
+	// proposal.
+	MaxProposalValue int64 `json:"maxProposalValue"`
+	// MaxDailyValue is the maximum value in satoshi moved by a single wallet
+	// within the last 24 hours.
+	MaxDailyValue int64 `json:"maxDailyValue"`
+	// MaxFeeRate is the maximum fee rate in satoshi per virtual byte.
+	MaxFeeRate int64 `json:"maxFeeRate"`
+}
+
+// LoadSigningPolicy reads a signing policy from the given JSON file.
+func LoadSigningPolicy(path string) (*SigningPolicy, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read signing policy file: [%w]", err)
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(content))
+	// Typos in rule names must not silently disable rules.
+	decoder.DisallowUnknownFields()
+
+	policy := &SigningPolicy{}
+	if err := decoder.Decode(policy); err != nil {
+		return nil, fmt.Errorf("cannot decode signing policy: [%w]", err)
+	}
+
+	return policy, nil
+}
+
+// loadSigningPolicyEngine loads the signing policy from the given file and
+// creates an engine evaluating it. Returns nil if the path is empty.
+func loadSigningPolicyEngine(path string) (*signingPolicyEngine, error) {
+	if len(path) == 0 {
+		return nil, nil
+	}
+
+	policy, err := LoadSigningPolicy(path)
+	if err != nil {
+		return nil, err
+	}
+
+	engine, err := newSigningPolicyEngine(policy)
+	if err != nil {
+		return nil, fmt.Errorf("invalid signing policy: [%v]", err)
+	}
+
+	logger.Infof("using signing policy from file [%v]", path)
+
+	return engine, nil
+}
+
+// signingPolicyOutpoint is a funding outpoint entry of the signing policy.
+// The output index is nil if all outputs of the transaction are denied.
+type signingPolicyOutpoint struct {
+	transactionHash bitcoin.Hash
+	outputIndex     *uint32
+}
+
+// signingPolicyVetoError is returned when the signing policy vetoes
+// a transaction.
+type signingPolicyVetoError struct {
+	rule   string
+	reason string
+}
+
+func (spve *signingPolicyVetoError) Error() string {
+	return fmt.Sprintf("vetoed by rule [%s]: %s", spve.rule, spve.reason)
+}
+
+// signingPolicyEngine evaluates wallet transactions against the signing
+// policy. Values moved by wallets are kept in memory so the daily limit
+// starts from zero after a client restart. All methods are safe to call on
+// a nil engine which accepts everything.
+type signingPolicyEngine struct {
+	policy *SigningPolicy
+
+	redeemerDenylist  map[string]bool
+	redeemerAllowlist map[string]bool
+	denied            []*signingPolicyOutpoint
+
+	mutex     sync.Mutex
+	movements map[[20]byte][]*signingPolicyMovement
+}
+
+// signingPolicyMovement is a value moved by a wallet transaction at the
+// given time.
+type signingPolicyMovement struct {
+	value int64
+	time  time.Time
+	// outpoints spent by the transaction. Another transaction spending
+	// any of them is a replacement of the transaction, e.g. a fee bump.
+	outpoints map[bitcoin.TransactionOutpoint]bool
+}
+
+// replacedBy determines whether the transaction of the given movement
+// replaces the transaction of this movement.
+func (spm *signingPolicyMovement) replacedBy(
+	other *signingPolicyMovement,
+) bool {
+	for outpoint := range other.outpoints {
+		if spm.outpoints[outpoint] {
+			return true
+		}
+	}
+
+	return false
+}
+
+// newSigningPolicyEngine creates a new engine for the given policy.
+func newSigningPolicyEngine(
+	policy *SigningPolicy,
+) (*signingPolicyEngine, error) {
+	redeemerDenylist, err := decodeSigningPolicyScripts(
+		policy.RedeemerOutputScriptsDenylist,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("invalid redeemer denylist: [%v]", err)
+	}
+
+	redeemerAllowlist, err := decodeSigningPolicyScripts(
+		policy.RedeemerOutputScriptsAllowlist,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("invalid redeemer allowlist: [%v]", err)
+	}
+
+	denied := make([]*signingPolicyOutpoint, len(policy.FundingOutpointsDenylist))
+	for i, entry := range policy.FundingOutpointsDenylist {
+		outpoint, err := parseSigningPolicyOutpoint(entry)
+		if err != nil {
+			return nil, fmt.Errorf(
+				"invalid funding outpoint [%s]: [%v]",
+				entry,
+				err,
+			)
+		}
+		denied[i] = outpoint
+	}
+
+	if policy.MaxProposalValue < 0 || policy.MaxDailyValue < 0 ||
+		policy.MaxFeeRate < 0 {
+		return nil, fmt.Errorf("limits must not be negative")
+	}
+
+	return &signingPolicyEngine{
+		policy:            policy,
+		redeemerDenylist:  redeemerDenylist,
+		redeemerAllowlist: redeemerAllowlist,
+		denied:            denied,
+		movements:         make(map[[20]byte][]*signingPolicyMovement),
+	}, nil
+}
+
+func decodeSigningPolicyScripts(scripts []string) (map[string]bool, error) {
+	result := make(map[string]bool)
+
+	for _, script := range scripts {
+		scriptBytes, err := hexutils.Decode(script)
+		if err != nil {
+			return nil, fmt.Errorf("cannot decode script [%s]: [%v]", script, err)
+		}
+
+		result[hexutils.Encode(scriptBytes)] = true
+	}
+
+	return result, nil
+}
+
+func parseSigningPolicyOutpoint(entry string) (*signingPolicyOutpoint, error) {
+	parts := strings.Split(entry, ":")
+	if len(parts) > 2 {
+		return nil, fmt.Errorf("wrong format")
+	}
+
+	transactionHash, err := bitcoin.NewHashFromString(
+		strings.TrimPrefix(parts[0], "0x"),
+		bitcoin.ReversedByteOrder,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("cannot parse transaction hash: [%v]", err)
+	}
+
+	outpoint := &signingPolicyOutpoint{transactionHash: transactionHash}
+
+	if len(parts) == 2 {
+		outputIndex, err := strconv.ParseUint(parts[1], 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("cannot parse output index: [%v]", err)
+		}
+
+		index := uint32(outputIndex)
+		outpoint.outputIndex = &index
+	}
+
+	return outpoint, nil
+}
+
+// evaluate checks whether the given unsigned transaction of the given wallet
+// satisfies the policy. Returns a *signingPolicyVetoError if the transaction
+// is vetoed. If the transaction is accepted, the returned movement holds the
+// value moved by it. The value is not accounted in the daily value of the
+// wallet until the movement is committed once the transaction is signed.
+func (spe *signingPolicyEngine) evaluate(
+	walletPublicKeyHash [20]byte,
+	unsignedTx *bitcoin.TransactionBuilder,
+) (*signingPolicyMovement, error) {
+	if spe == nil {
+		return nil, nil
+	}
+
+	psbt, err := unsignedTx.ToPSBT()
+	if err != nil {
+		return nil, fmt.Errorf("cannot describe transaction: [%v]", err)
+	}
+
+	transaction := psbt.UnsignedTransaction
+
+	movement := &signingPolicyMovement{
+		outpoints: make(map[bitcoin.TransactionOutpoint]bool),
+	}
+	totalInputsValue := int64(0)
+	totalOutputsValue := int64(0)
+
+	sizeEstimator := bitcoin.NewTransactionSizeEstimator()
+
+	for i, input := range transaction.Inputs {
+		previousOutput, err := psbtInputPreviousOutput(psbt, i)
+		if err != nil {
+			return nil, err
+		}
+
+		totalInputsValue += previousOutput.Value
+		movement.outpoints[*input.Outpoint] = true
+
+		if !paysPublicKeyHash(previousOutput.PublicKeyScript, walletPublicKeyHash) {
+			// Inputs not controlled by the wallet bring value into it.
+			movement.value += previousOutput.Value
+
+			if outpoint := spe.deniedOutpoint(input.Outpoint); outpoint != "" {
+				return nil, &signingPolicyVetoError{
+					rule:   signingPolicyRuleFundingOutpoint,
+					reason: fmt.Sprintf("outpoint [%s] is denied", outpoint),
+				}
+			}
+		}
+
+		addInputToSizeEstimator(
+			sizeEstimator,
+			previousOutput.PublicKeyScript,
+			psbt.Inputs[i],
+		)
+	}
+
+	for _, output := range transaction.Outputs {
+		totalOutputsValue += output.Value
+
+		// All outputs, including the wallet's change or main UTXO, count
+		// towards the transaction size.
+		addOutputToSizeEstimator(sizeEstimator, output.PublicKeyScript)
+
+		if paysPublicKeyHash(output.PublicKeyScript, walletPublicKeyHash) {
+			continue
+		}
+
+		// Outputs not controlled by the wallet take value out of it.
+		// Output scripts are checked regardless of the action producing
+		// the transaction so the lists cannot be bypassed by another
+		// action paying out to the same scripts, e.g. a fee bump.
+		movement.value += output.Value
+
+		script := hexutils.Encode(output.PublicKeyScript)
+
+		if spe.redeemerDenylist[script] {
+			return nil, &signingPolicyVetoError{
+				rule:   signingPolicyRuleRedeemerDenylist,
+				reason: fmt.Sprintf("output script [%s] is denied", script),
+			}
+		}
+
+		if len(spe.redeemerAllowlist) > 0 && !spe.redeemerAllowlist[script] {
+			return nil, &signingPolicyVetoError{
+				rule:   signingPolicyRuleRedeemerAllowlist,
+				reason: fmt.Sprintf("output script [%s] is not allowed", script),
+			}
+		}
+	}
+
+	if spe.policy.MaxProposalValue > 0 &&
+		movement.value > spe.policy.MaxProposalValue {
+		return nil, &signingPolicyVetoError{
+			rule: signingPolicyRuleMaxProposalValue,
+			reason: fmt.Sprintf(
+				"value [%v] exceeds the maximum [%v]",
+				movement.value,
+				spe.policy.MaxProposalValue,
+			),
+		}
+	}
+
+	if spe.policy.MaxFeeRate > 0 {
+		virtualSize, err := sizeEstimator.VirtualSize()
+		if err != nil {
+			return nil, fmt.Errorf(
+				"cannot estimate transaction size: [%v]",
+				err,
+			)
+		}
+
+		fee := totalInputsValue - totalOutputsValue
+		if fee > spe.policy.MaxFeeRate*virtualSize {
+			return nil, &signingPolicyVetoError{
+				rule: signingPolicyRuleMaxFeeRate,
+				reason: fmt.Sprintf(
+					"fee [%v] for virtual size [%v] exceeds the maximum "+
+						"fee rate [%v]",
+					fee,
+					virtualSize,
+					spe.policy.MaxFeeRate,
+				),
+			}
+		}
+	}
+
+	if err := spe.checkDailyValue(walletPublicKeyHash, movement); err != nil {
+		return nil, err
+	}
+
+	return movement, nil
+}
+
+// deniedOutpoint returns the given outpoint as a string if it is denied by
+// the policy. Returns an empty string otherwise.
+func (spe *signingPolicyEngine) deniedOutpoint(
+	outpoint *bitcoin.TransactionOutpoint,
+) string {
+	for _, denied := range spe.denied {
+		if denied.transactionHash != outpoint.TransactionHash {
+			continue
+		}
+
+		if denied.outputIndex == nil || *denied.outputIndex == outpoint.OutputIndex {
+			return fmt.Sprintf(
+				"%s:%v",
+				outpoint.TransactionHash.Hex(bitcoin.ReversedByteOrder),
+				outpoint.OutputIndex,
+			)
+		}
+	}
+
+	return ""
+}
+
+// checkDailyValue checks whether the value of the given movement fits into
+// the daily value limit of the wallet. If the movement replaces an already
+// committed one, the value of the replaced movement is not counted.
+func (spe *signingPolicyEngine) checkDailyValue(
+	walletPublicKeyHash [20]byte,
+	movement *signingPolicyMovement,
+) error {
+	if spe.policy.MaxDailyValue == 0 {
+		return nil
+	}
+
+	spe.mutex.Lock()
+	defer spe.mutex.Unlock()
+
+	dailyValue := movement.value
+	for _, committed := range spe.dailyMovementsLocked(walletPublicKeyHash) {
+		if !committed.replacedBy(movement) {
+			dailyValue += committed.value
+		}
+	}
+
+	if dailyValue > spe.policy.MaxDailyValue {
+		return &signingPolicyVetoError{
+			rule: signingPolicyRuleMaxDailyValue,
+			reason: fmt.Sprintf(
+				"daily value [%v] would exceed the maximum [%v]",
+				dailyValue,
+				spe.policy.MaxDailyValue,
+			),
+		}
+	}
+
+	return nil
+}
+
+// commit accounts the value of the given movement in the daily value of
+// the wallet. It should be called once the evaluated transaction is signed.
+// A movement replacing already committed ones, e.g. a fee bump, takes their
+// place instead of being charged again. Does nothing for a nil movement or
+// if the daily value limit is disabled.
+func (spe *signingPolicyEngine) commit(
+	walletPublicKeyHash [20]byte,
+	movement *signingPolicyMovement,
+) {
+	if spe == nil || movement == nil || spe.policy.MaxDailyValue == 0 {
+		return
+	}
+
+	spe.mutex.Lock()
+	defer spe.mutex.Unlock()
+
+	movement.time = time.Now()
+
+	movements := make([]*signingPolicyMovement, 0)
+	for _, committed := range spe.dailyMovementsLocked(walletPublicKeyHash) {
+		if committed.replacedBy(movement) {
+			// The replacement keeps the time of the original transaction
+			// so the value leaves the rolling window as originally.
+			if committed.time.Before(movement.time) {
+				movement.time = committed.time
+			}
+			continue
+		}
+
+		movements = append(movements, committed)
+	}
+
+	spe.movements[walletPublicKeyHash] = append(movements, movement)
+}
+
+// dailyMovementsLocked prunes movements of the given wallet that are out
+// of the rolling daily window and returns the remaining ones. Must be called
+// with the mutex held.
+func (spe *signingPolicyEngine) dailyMovementsLocked(
+	walletPublicKeyHash [20]byte,
+) []*signingPolicyMovement {
+	now := time.Now()
+
+	movements := make([]*signingPolicyMovement, 0)
+	for _, movement := range spe.movements[walletPublicKeyHash] {
+		if now.Sub(movement.time) < signingPolicyDailyWindow {
+			movements = append(movements, movement)
+		}
+	}
+
+	spe.movements[walletPublicKeyHash] = movements
+
+	return movements
+}
+
+// psbtInputPreviousOutput returns the UTXO spent by the given PSBT input.
+func psbtInputPreviousOutput(
+	psbt *bitcoin.PSBT,
+	index int,
+) (*bitcoin.TransactionOutput, error) {
+	input := psbt.Inputs[index]
+
+	if input.WitnessUtxo != nil {
+		return input.WitnessUtxo, nil
+	}
+
+	outputIndex := psbt.UnsignedTransaction.Inputs[index].Outpoint.OutputIndex
+	if input.NonWitnessUtxo == nil ||
+		int(outputIndex) >= len(input.NonWitnessUtxo.Outputs) {
+		return nil, fmt.Errorf("unknown UTXO of input [%v]", index)
+	}
+
+	return input.NonWitnessUtxo.Outputs[outputIndex], nil
+}
+
+// addInputToSizeEstimator adds an input spending the UTXO with the given
+// locking script to the given size estimator.
+func addInputToSizeEstimator(
+	sizeEstimator *bitcoin.TransactionSizeEstimator,
+	script bitcoin.Script,
+	input *bitcoin.PSBTInput,
+) {
+	switch bitcoin.GetScriptType(script) {
+	case bitcoin.P2PKHScript:
+		sizeEstimator.AddPublicKeyHashInputs(1, false)
+	case bitcoin.P2WPKHScript:
+		sizeEstimator.AddPublicKeyHashInputs(1, true)
+	case bitcoin.P2SHScript:
+		sizeEstimator.AddScriptHashInputs(1, len(input.RedeemScript), false)
+	case bitcoin.P2WSHScript:
+		sizeEstimator.AddScriptHashInputs(1, len(input.WitnessScript), true)
+	}
+}
+
+// addOutputToSizeEstimator adds an output with the given locking script to
+// the given size estimator.
+func addOutputToSizeEstimator(
+	sizeEstimator *bitcoin.TransactionSizeEstimator,
+	script bitcoin.Script,
+) {
+	switch bitcoin.GetScriptType(script) {
+	case bitcoin.P2PKHScript:
+		sizeEstimator.AddPublicKeyHashOutputs(1, false)
+	case bitcoin.P2WPKHScript:
+		sizeEstimator.AddPublicKeyHashOutputs(1, true)
+	case bitcoin.P2SHScript:
+		sizeEstimator.AddScriptHashOutputs(1, false)
+	case bitcoin.P2WSHScript:
+		sizeEstimator.AddScriptHashOutputs(1, true)
+	case bitcoin.P2TRScript:
+		sizeEstimator.AddTaprootOutputs(1)
+	}
+}
@@ -0,0 +1,573 @@
+package tbtc
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/keep-network/keep-core/internal/hexutils"
+	"github.com/keep-network/keep-core/internal/testutils"
+	"github.com/keep-network/keep-core/pkg/bitcoin"
+	"github.com/keep-network/keep-core/pkg/bitcoin/simnet"
+	"github.com/keep-network/keep-core/pkg/tecdsa"
+)
+
+func TestLoadSigningPolicy(t *testing.T) {
+	var tests = map[string]struct {
+		content        string
+		expectedPolicy *SigningPolicy
+		expectedError  bool
+	}{
+		"valid policy": {
+			content: `{
+				"redeemerOutputScriptsDenylist": ["0014aa"],
+				"fundingOutpointsDenylist": ["ab:1"],
+				"maxProposalValue": 100,
+				"maxDailyValue": 200,
+				"maxFeeRate": 10
+			}`,
+			expectedPolicy: &SigningPolicy{
+				RedeemerOutputScriptsDenylist: []string{"0014aa"},
+				FundingOutpointsDenylist:      []string{"ab:1"},
+				MaxProposalValue:              100,
+				MaxDailyValue:                 200,
+				MaxFeeRate:                    10,
+			},
+		},
+		"unknown rule": {
+			content:       `{"maxFeeRates": 10}`,
+			expectedError: true,
+		},
+		"malformed file": {
+			content:       `{"maxFeeRate":`,
+			expectedError: true,
+		},
+	}
+
+	for testName, test := range tests {
+		t.Run(testName, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "policy.json")
+			if err := os.WriteFile(path, []byte(test.content), 0600); err != nil {
+				t.Fatal(err)
+			}
+
+			policy, err := LoadSigningPolicy(path)
+
+			if test.expectedError {
+				if err == nil {
+					t.Fatal("expected error")
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			testutils.AssertStringsEqual(
+				t,
+				"denylist",
+				test.expectedPolicy.RedeemerOutputScriptsDenylist[0],
+				policy.RedeemerOutputScriptsDenylist[0],
+			)
+			testutils.AssertStringsEqual(
+				t,
+				"funding outpoints denylist",
+				test.expectedPolicy.FundingOutpointsDenylist[0],
+				policy.FundingOutpointsDenylist[0],
+			)
+			testutils.AssertIntsEqual(
+				t,
+				"max proposal value",
+				int(test.expectedPolicy.MaxProposalValue),
+				int(policy.MaxProposalValue),
+			)
+			testutils.AssertIntsEqual(
+				t,
+				"max daily value",
+				int(test.expectedPolicy.MaxDailyValue),
+				int(policy.MaxDailyValue),
+			)
+			testutils.AssertIntsEqual(
+				t,
+				"max fee rate",
+				int(test.expectedPolicy.MaxFeeRate),
+				int(policy.MaxFeeRate),
+			)
+		})
+	}
+}
+
+func TestNewSigningPolicyEngine_InvalidPolicy(t *testing.T) {
+	var tests = map[string]*SigningPolicy{
+		"malformed script": {
+			RedeemerOutputScriptsAllowlist: []string{"zz"},
+		},
+		"malformed outpoint hash": {
+			FundingOutpointsDenylist: []string{"ab:1"},
+		},
+		"malformed outpoint index": {
+			FundingOutpointsDenylist: []string{
+				"0000000000000000000000000000000000000000000000000000000000000001:x",
+			},
+		},
+		"negative limit": {
+			MaxDailyValue: -1,
+		},
+	}
+
+	for testName, policy := range tests {
+		t.Run(testName, func(t *testing.T) {
+			_, err := newSigningPolicyEngine(policy)
+			if err == nil {
+				t.Fatal("expected error")
+			}
+		})
+	}
+}
+
+func TestSigningPolicyEngine_Evaluate(t *testing.T) {
+	btcChain, err := simnet.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	walletPublicKeyHash := bitcoin.PublicKeyHash(
+		&newDepositRefundTestKey(t).PublicKey,
+	)
+	walletScript, err := bitcoin.PayToWitnessPublicKeyHash(walletPublicKeyHash)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	redeemerScript, err := bitcoin.PayToPublicKeyHash(
+		bitcoin.PublicKeyHash(&newDepositRefundTestKey(t).PublicKey),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	depositorScript, err := bitcoin.PayToWitnessPublicKeyHash(
+		bitcoin.PublicKeyHash(&newDepositRefundTestKey(t).PublicKey),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	walletUtxo := fundSigningPolicyTestScript(t, btcChain, walletScript)
+	depositUtxo := fundSigningPolicyTestScript(t, btcChain, depositorScript)
+
+	// The redemption moves 90000 satoshi out of the wallet and pays
+	// a 1000 satoshi fee for 144 virtual bytes.
+	redemption := func() *bitcoin.TransactionBuilder {
+		builder := bitcoin.NewTransactionBuilder(btcChain)
+		if err := builder.AddPublicKeyHashInput(walletUtxo); err != nil {
+			t.Fatal(err)
+		}
+		builder.AddOutput(&bitcoin.TransactionOutput{
+			Value:           90000,
+			PublicKeyScript: redeemerScript,
+		})
+		builder.AddOutput(&bitcoin.TransactionOutput{
+			Value:           9000,
+			PublicKeyScript: walletScript,
+		})
+		return builder
+	}
+
+	// The sweep moves 100000 satoshi into the wallet.
+	sweep := func() *bitcoin.TransactionBuilder {
+		builder := bitcoin.NewTransactionBuilder(btcChain)
+		if err := builder.AddPublicKeyHashInput(walletUtxo); err != nil {
+			t.Fatal(err)
+		}
+		if err := builder.AddPublicKeyHashInput(depositUtxo); err != nil {
+			t.Fatal(err)
+		}
+		builder.AddOutput(&bitcoin.TransactionOutput{
+			Value:           199000,
+			PublicKeyScript: walletScript,
+		})
+		return builder
+	}
+
+	depositOutpoint := depositUtxo.Outpoint.TransactionHash.Hex(
+		bitcoin.ReversedByteOrder,
+	)
+
+	var tests = map[string]struct {
+		policy       *SigningPolicy
+		unsignedTx   *bitcoin.TransactionBuilder
+		expectedRule string
+	}{
+		"empty policy": {
+			policy:     &SigningPolicy{},
+			unsignedTx: redemption(),
+		},
+		"redeemer denied": {
+			policy: &SigningPolicy{
+				RedeemerOutputScriptsDenylist: []string{
+					hexutils.Encode(redeemerScript),
+				},
+			},
+			unsignedTx:   redemption(),
+			expectedRule: signingPolicyRuleRedeemerDenylist,
+		},
+		"redeemer not allowed": {
+			policy: &SigningPolicy{
+				RedeemerOutputScriptsAllowlist: []string{
+					hexutils.Encode(depositorScript),
+				},
+			},
+			unsignedTx:   redemption(),
+			expectedRule: signingPolicyRuleRedeemerAllowlist,
+		},
+		"redeemer allowed": {
+			policy: &SigningPolicy{
+				RedeemerOutputScriptsAllowlist: []string{
+					hexutils.Encode(redeemerScript),
+				},
+			},
+			unsignedTx: redemption(),
+		},
+		"redeemer allowlist ignores wallet outputs": {
+			policy: &SigningPolicy{
+				RedeemerOutputScriptsAllowlist: []string{
+					hexutils.Encode(redeemerScript),
+				},
+			},
+			unsignedTx: sweep(),
+		},
+		"funding outpoint denied": {
+			policy: &SigningPolicy{
+				FundingOutpointsDenylist: []string{depositOutpoint + ":0"},
+			},
+			unsignedTx:   sweep(),
+			expectedRule: signingPolicyRuleFundingOutpoint,
+		},
+		"funding transaction denied": {
+			policy: &SigningPolicy{
+				FundingOutpointsDenylist: []string{depositOutpoint},
+			},
+			unsignedTx:   sweep(),
+			expectedRule: signingPolicyRuleFundingOutpoint,
+		},
+		"other funding outpoint denied": {
+			policy: &SigningPolicy{
+				FundingOutpointsDenylist: []string{depositOutpoint + ":1"},
+			},
+			unsignedTx: sweep(),
+		},
+		"proposal value exceeded": {
+			policy: &SigningPolicy{
+				MaxProposalValue: 89999,
+			},
+			unsignedTx:   redemption(),
+			expectedRule: signingPolicyRuleMaxProposalValue,
+		},
+		"proposal value not exceeded": {
+			policy: &SigningPolicy{
+				MaxProposalValue: 90000,
+			},
+			unsignedTx: redemption(),
+		},
+		"swept value exceeded": {
+			policy: &SigningPolicy{
+				MaxProposalValue: 99999,
+			},
+			unsignedTx:   sweep(),
+			expectedRule: signingPolicyRuleMaxProposalValue,
+		},
+		"daily value exceeded": {
+			policy: &SigningPolicy{
+				MaxDailyValue: 89999,
+			},
+			unsignedTx:   redemption(),
+			expectedRule: signingPolicyRuleMaxDailyValue,
+		},
+		"fee rate exceeded": {
+			policy: &SigningPolicy{
+				MaxFeeRate: 5,
+			},
+			unsignedTx:   redemption(),
+			expectedRule: signingPolicyRuleMaxFeeRate,
+		},
+		"fee rate not exceeded": {
+			policy: &SigningPolicy{
+				MaxFeeRate: 10,
+			},
+			unsignedTx: redemption(),
+		},
+		// The redemption is 144 virtual bytes, including 31 virtual bytes
+		// of the wallet change output. The 1000 satoshi fee is just below
+		// the limit of 7 sat/vbyte which allows 1008 satoshi. Without the
+		// change output, the limit would be 791 satoshi.
+		"fee rate close to the limit with wallet change": {
+			policy: &SigningPolicy{
+				MaxFeeRate: 7,
+			},
+			unsignedTx: redemption(),
+		},
+		"fee rate just above the limit with wallet change": {
+			policy: &SigningPolicy{
+				MaxFeeRate: 6,
+			},
+			unsignedTx:   redemption(),
+			expectedRule: signingPolicyRuleMaxFeeRate,
+		},
+	}
+
+	for testName, test := range tests {
+		t.Run(testName, func(t *testing.T) {
+			engine, err := newSigningPolicyEngine(test.policy)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			_, err = engine.evaluate(walletPublicKeyHash, test.unsignedTx)
+
+			if len(test.expectedRule) == 0 {
+				if err != nil {
+					t.Fatalf("unexpected error: [%v]", err)
+				}
+				return
+			}
+
+			var vetoErr *signingPolicyVetoError
+			if !errors.As(err, &vetoErr) {
+				t.Fatalf("expected veto error; got [%v]", err)
+			}
+
+			testutils.AssertStringsEqual(
+				t,
+				"rule",
+				test.expectedRule,
+				vetoErr.rule,
+			)
+		})
+	}
+}
+
+func TestSigningPolicyEngine_EvaluateDailyValue(t *testing.T) {
+	btcChain, err := simnet.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	walletPublicKeyHash := bitcoin.PublicKeyHash(
+		&newDepositRefundTestKey(t).PublicKey,
+	)
+	walletScript, err := bitcoin.PayToWitnessPublicKeyHash(walletPublicKeyHash)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	otherWalletPublicKeyHash := bitcoin.PublicKeyHash(
+		&newDepositRefundTestKey(t).PublicKey,
+	)
+	otherWalletScript, err := bitcoin.PayToWitnessPublicKeyHash(
+		otherWalletPublicKeyHash,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Moves the given value from the given UTXO of the first wallet to
+	// the other wallet.
+	movingFunds := func(
+		utxo *bitcoin.UnspentTransactionOutput,
+		value int64,
+	) *bitcoin.TransactionBuilder {
+		builder := bitcoin.NewTransactionBuilder(btcChain)
+		if err := builder.AddPublicKeyHashInput(utxo); err != nil {
+			t.Fatal(err)
+		}
+		builder.AddOutput(&bitcoin.TransactionOutput{
+			Value:           value,
+			PublicKeyScript: otherWalletScript,
+		})
+		return builder
+	}
+
+	utxo := fundSigningPolicyTestScript(t, btcChain, walletScript)
+	otherUtxo := fundSigningPolicyTestScript(t, btcChain, walletScript)
+
+	engine, err := newSigningPolicyEngine(&SigningPolicy{
+		MaxDailyValue: 150000,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assertVetoed := func(err error) {
+		var vetoErr *signingPolicyVetoError
+		if !errors.As(err, &vetoErr) {
+			t.Fatalf("expected veto error; got [%v]", err)
+		}
+		testutils.AssertStringsEqual(
+			t,
+			"rule",
+			signingPolicyRuleMaxDailyValue,
+			vetoErr.rule,
+		)
+	}
+
+	movement, err := engine.evaluate(walletPublicKeyHash, movingFunds(utxo, 99000))
+	if err != nil {
+		t.Fatalf("unexpected error: [%v]", err)
+	}
+
+	// The value is not accounted until the transaction is signed.
+	_, err = engine.evaluate(walletPublicKeyHash, movingFunds(otherUtxo, 99000))
+	if err != nil {
+		t.Fatalf("unexpected error: [%v]", err)
+	}
+
+	engine.commit(walletPublicKeyHash, movement)
+
+	// Another transaction would move 198000 satoshi within a day.
+	_, err = engine.evaluate(walletPublicKeyHash, movingFunds(otherUtxo, 99000))
+	assertVetoed(err)
+
+	// A replacement of the signed transaction, e.g. a fee bump, is not
+	// charged again.
+	replacement, err := engine.evaluate(
+		walletPublicKeyHash,
+		movingFunds(utxo, 98000),
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: [%v]", err)
+	}
+
+	engine.commit(walletPublicKeyHash, replacement)
+
+	testutils.AssertIntsEqual(
+		t,
+		"accounted movements",
+		1,
+		len(engine.movements[walletPublicKeyHash]),
+	)
+	testutils.AssertIntsEqual(
+		t,
+		"accounted value",
+		98000,
+		int(engine.movements[walletPublicKeyHash][0].value),
+	)
+
+	// The replacement does not free the limit for other transactions.
+	_, err = engine.evaluate(walletPublicKeyHash, movingFunds(otherUtxo, 99000))
+	assertVetoed(err)
+
+	// Values of different wallets are accounted separately.
+	_, err = engine.evaluate(otherWalletPublicKeyHash, movingFunds(utxo, 99000))
+	if err != nil {
+		t.Fatalf("unexpected error: [%v]", err)
+	}
+}
+
+func TestWalletTransactionExecutor_SignTransaction_ObserverMode(t *testing.T) {
+	btcChain, err := simnet.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	walletPublicKey := &newDepositRefundTestKey(t).PublicKey
+	walletPublicKeyHash := bitcoin.PublicKeyHash(walletPublicKey)
+	walletScript, err := bitcoin.PayToWitnessPublicKeyHash(walletPublicKeyHash)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	utxo := fundSigningPolicyTestScript(t, btcChain, walletScript)
+
+	builder := bitcoin.NewTransactionBuilder(btcChain)
+	if err := builder.AddPublicKeyHashInput(utxo); err != nil {
+		t.Fatal(err)
+	}
+	builder.AddOutput(&bitcoin.TransactionOutput{
+		Value:           99000,
+		PublicKeyScript: walletScript,
+	})
+
+	engine, err := newSigningPolicyEngine(&SigningPolicy{
+		MaxDailyValue: 150000,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	executor := newWalletTransactionExecutor(
+		btcChain,
+		wallet{publicKey: walletPublicKey},
+		&observerWalletSigningExecutor{},
+		func(ctx context.Context, _ uint64) error {
+			<-ctx.Done()
+			return nil
+		},
+	)
+	executor.signingPolicy = engine
+
+	_, err = executor.signTransaction(&testutils.MockLogger{}, builder, 0, 0)
+	if err == nil {
+		t.Fatal("expected signing error in the observer mode")
+	}
+
+	// The observed signing must not consume the daily limit.
+	testutils.AssertIntsEqual(
+		t,
+		"accounted movements",
+		0,
+		len(engine.movements[walletPublicKeyHash]),
+	)
+}
+
+// observerWalletSigningExecutor mimics the signing executor of a node
+// operating in the observer mode.
+type observerWalletSigningExecutor struct{}
+
+func (owse *observerWalletSigningExecutor) signBatch(
+	ctx context.Context,
+	messages []*big.Int,
+	startBlock uint64,
+	actionType WalletActionType,
+) ([]*tecdsa.Signature, error) {
+	return nil, errObserverMode
+}
+
+func TestSigningPolicyEngine_Nil(t *testing.T) {
+	var engine *signingPolicyEngine
+
+	movement, err := engine.evaluate([20]byte{}, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: [%v]", err)
+	}
+
+	// Committing must be a no-op as well.
+	engine.commit([20]byte{}, movement)
+}
+
+func fundSigningPolicyTestScript(
+	t *testing.T,
+	btcChain *simnet.Chain,
+	script bitcoin.Script,
+) *bitcoin.UnspentTransactionOutput {
+	fundingTransaction, err := btcChain.Fund(script, 100000)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := btcChain.MineBlocks(1); err != nil {
+		t.Fatal(err)
+	}
+
+	return &bitcoin.UnspentTransactionOutput{
+		Outpoint: &bitcoin.TransactionOutpoint{
+			TransactionHash: fundingTransaction.Hash(),
+			OutputIndex:     0,
+		},
+		Value: 100000,
+	}
+}
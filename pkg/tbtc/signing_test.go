@@ -21,6 +21,7 @@ import (
 
 func TestSigningExecutor_Sign(t *testing.T) {
 	executor := setupSigningExecutor(t)
+	executor.metrics = newWalletMetrics()
 
 	ctx, cancelCtx := context.WithCancel(context.Background())
 	defer cancelCtx()
@@ -28,7 +29,12 @@ func TestSigningExecutor_Sign(t *testing.T) {
 	message := big.NewInt(100)
 	startBlock := uint64(0)
 
-	signature, _, endBlock, err := executor.sign(ctx, message, startBlock)
+	signature, _, endBlock, err := executor.sign(
+		ctx,
+		message,
+		startBlock,
+		ActionHeartbeat,
+	)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -47,6 +53,23 @@ func TestSigningExecutor_Sign(t *testing.T) {
 	if endBlock <= startBlock {
 		t.Errorf("wrong end block")
 	}
+
+	walletPublicKeyHash := bitcoin.PublicKeyHash(walletPublicKey)
+
+	if attempts := executor.metrics.get(
+		walletSigningAttemptsMetricName,
+		walletPublicKeyHash,
+		walletMetricLabel{walletMetricsActionLabel, ActionHeartbeat.String()},
+	); attempts < 1 {
+		t.Errorf("unexpected signing attempts metric: [%v]", attempts)
+	}
+	if failures := executor.metrics.get(
+		walletSigningFailuresMetricName,
+		walletPublicKeyHash,
+		walletMetricLabel{walletMetricsActionLabel, ActionHeartbeat.String()},
+	); failures != 0 {
+		t.Errorf("unexpected signing failures metric: [%v]", failures)
+	}
 }
 
 func TestSigningExecutor_Sign_Busy(t *testing.T) {
@@ -60,13 +83,13 @@ func TestSigningExecutor_Sign_Busy(t *testing.T) {
 
 	errChan := make(chan error, 1)
 	go func() {
-		_, _, _, err := executor.sign(ctx, message, startBlock)
+		_, _, _, err := executor.sign(ctx, message, startBlock, ActionHeartbeat)
 		errChan <- err
 	}()
 
 	time.Sleep(100 * time.Millisecond)
 
-	_, _, _, err := executor.sign(ctx, message, startBlock)
+	_, _, _, err := executor.sign(ctx, message, startBlock, ActionHeartbeat)
 	testutils.AssertErrorsSame(t, errSigningExecutorBusy, err)
 
 	err = <-errChan
@@ -75,6 +98,35 @@ func TestSigningExecutor_Sign_Busy(t *testing.T) {
 	}
 }
 
+func TestSigningExecutor_Sign_ObserverMode(t *testing.T) {
+	executor := setupSigningExecutor(t)
+
+	persistenceHandle := &mockPersistenceHandle{}
+	executor.observer = newObserverRecorder(persistenceHandle)
+
+	ctx, cancelCtx := context.WithCancel(context.Background())
+	defer cancelCtx()
+
+	message := big.NewInt(100)
+	startBlock := uint64(0)
+
+	_, _, _, err := executor.sign(ctx, message, startBlock, ActionHeartbeat)
+	testutils.AssertErrorsSame(t, errObserverMode, err)
+
+	testutils.AssertIntsEqual(
+		t,
+		"saved records",
+		1,
+		len(persistenceHandle.saved),
+	)
+	testutils.AssertStringsEqual(
+		t,
+		"recorded message",
+		"0x64",
+		executor.observer.latestRecords[0].Details["message"].(string),
+	)
+}
+
 func TestSigningExecutor_SignBatch(t *testing.T) {
 	executor := setupSigningExecutor(t)
 
@@ -88,7 +140,12 @@ func TestSigningExecutor_SignBatch(t *testing.T) {
 	}
 	startBlock := uint64(0)
 
-	signatures, err := executor.signBatch(ctx, messages, startBlock)
+	signatures, err := executor.signBatch(
+		ctx,
+		messages,
+		startBlock,
+		ActionHeartbeat,
+	)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -178,6 +235,7 @@ func setupSigningExecutor(t *testing.T) *signingExecutor {
 
 	node, err := newNode(
 		groupParameters,
+		mainnetProtocolTimings(),
 		localChain,
 		newLocalBitcoinChain(),
 		localProvider,
@@ -0,0 +1,398 @@
+package tbtc
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/rand"
+	"encoding/binary"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/keep-network/keep-core/internal/testutils"
+	"github.com/keep-network/keep-core/pkg/bitcoin"
+	"github.com/keep-network/keep-core/pkg/bitcoin/simnet"
+	"github.com/keep-network/keep-core/pkg/chain"
+	"github.com/keep-network/keep-core/pkg/tecdsa"
+)
+
+// TestWalletFlow_Simnet exercises the deposit sweep and redemption actions
+// end-to-end against the Bitcoin network simulator. Unlike scenario-based
+// tests, transactions are signed with a real wallet key so the simulator
+// verifies their input scripts upon broadcast. SPV proofs of both
+// transactions are assembled from the simulated chain and verified the same
+// way the SPV maintainer does before submitting them.
+func TestWalletFlow_Simnet(t *testing.T) {
+	bitcoinChain, err := simnet.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	hostChain := Connect()
+
+	walletPrivateKey := newDepositRefundTestKey(t)
+	wallet := wallet{
+		// Set only relevant fields.
+		publicKey: &walletPrivateKey.PublicKey,
+	}
+	walletPublicKeyHash := bitcoin.PublicKeyHash(wallet.publicKey)
+
+	hostChain.setWallet(walletPublicKeyHash, &WalletChainData{})
+
+	signingExecutor := &localKeySigningExecutor{privateKey: walletPrivateKey}
+
+	// Sweep a P2SH and a P2WSH deposit into the wallet's first main UTXO.
+	deposits := []*Deposit{
+		fundSimnetWalletDeposit(t, bitcoinChain, walletPublicKeyHash, 100000, false),
+		fundSimnetWalletDeposit(t, bitcoinChain, walletPublicKeyHash, 200000, true),
+	}
+
+	depositsKeys := make([]struct {
+		FundingTxHash      bitcoin.Hash
+		FundingOutputIndex uint32
+	}, len(deposits))
+	depositsExtraInfo := make([]struct {
+		*Deposit
+		FundingTx *bitcoin.Transaction
+	}, len(deposits))
+	depositsRevealBlocks := make([]*big.Int, len(deposits))
+
+	for i, deposit := range deposits {
+		fundingTxHash := deposit.Utxo.Outpoint.TransactionHash
+		fundingOutputIndex := deposit.Utxo.Outpoint.OutputIndex
+
+		fundingTx, err := bitcoinChain.GetTransaction(fundingTxHash)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		depositsKeys[i].FundingTxHash = fundingTxHash
+		depositsKeys[i].FundingOutputIndex = fundingOutputIndex
+		depositsExtraInfo[i].Deposit = deposit
+		depositsExtraInfo[i].FundingTx = fundingTx
+
+		depositRevealBlock := uint64(100 * i)
+		depositsRevealBlocks[i] = big.NewInt(int64(depositRevealBlock))
+
+		err = hostChain.setPastDepositRevealedEvents(
+			&DepositRevealedEventFilter{
+				StartBlock:          depositRevealBlock,
+				EndBlock:            &depositRevealBlock,
+				WalletPublicKeyHash: [][20]byte{walletPublicKeyHash},
+			},
+			[]*DepositRevealedEvent{
+				{
+					FundingTxHash:       fundingTxHash,
+					FundingOutputIndex:  fundingOutputIndex,
+					Depositor:           deposit.Depositor,
+					Amount:              uint64(deposit.Utxo.Value),
+					BlindingFactor:      deposit.BlindingFactor,
+					WalletPublicKeyHash: deposit.WalletPublicKeyHash,
+					RefundPublicKeyHash: deposit.RefundPublicKeyHash,
+					RefundLocktime:      deposit.RefundLocktime,
+					BlockNumber:         depositRevealBlock,
+				},
+			},
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		hostChain.setDepositRequest(
+			fundingTxHash,
+			fundingOutputIndex,
+			&DepositChainRequest{
+				// Set only relevant fields.
+				Depositor: deposit.Depositor,
+				Amount:    uint64(deposit.Utxo.Value),
+			},
+		)
+	}
+
+	sweepProposal := &DepositSweepProposal{
+		DepositsKeys:         depositsKeys,
+		SweepTxFee:           big.NewInt(2000),
+		DepositsRevealBlocks: depositsRevealBlocks,
+	}
+
+	err = hostChain.setDepositSweepProposalValidationResult(
+		walletPublicKeyHash,
+		sweepProposal,
+		depositsExtraInfo,
+		true,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sweepAction := newDepositSweepAction(
+		logger.With(),
+		hostChain,
+		bitcoinChain,
+		wallet,
+		signingExecutor,
+		sweepProposal,
+		100,
+		100+depositSweepProposalValidityBlocks,
+		func(ctx context.Context, blockHeight uint64) error {
+			return nil
+		},
+	)
+	sweepAction.requiredFundingTxConfirmations = 1
+	sweepAction.broadcastCheckDelay = 100 * time.Millisecond
+
+	if err := sweepAction.execute(); err != nil {
+		t.Fatal(err)
+	}
+
+	sweepTransaction := assertSimnetTransactionConfirmed(
+		t,
+		bitcoinChain,
+		walletPublicKeyHash,
+	)
+
+	testutils.AssertIntsEqual(
+		t,
+		"sweep transaction inputs count",
+		len(deposits),
+		len(sweepTransaction.Inputs),
+	)
+
+	mainUtxo := &bitcoin.UnspentTransactionOutput{
+		Outpoint: &bitcoin.TransactionOutpoint{
+			TransactionHash: sweepTransaction.Hash(),
+			OutputIndex:     0,
+		},
+		Value: 100000 + 200000 - 2000,
+	}
+	testutils.AssertIntsEqual(
+		t,
+		"main UTXO value",
+		int(mainUtxo.Value),
+		int(sweepTransaction.Outputs[0].Value),
+	)
+
+	hostChain.setWallet(walletPublicKeyHash, &WalletChainData{
+		MainUtxoHash: hostChain.ComputeMainUtxoHash(mainUtxo),
+	})
+
+	// Redeem a part of the swept funds from the wallet's main UTXO.
+	redeemerOutputScript, err := bitcoin.PayToWitnessPublicKeyHash(
+		bitcoin.PublicKeyHash(&newDepositRefundTestKey(t).PublicKey),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	hostChain.setPendingRedemptionRequest(
+		walletPublicKeyHash,
+		&RedemptionRequest{
+			Redeemer:             chain.Address("0x2219eD8aC2AE2fa6E8cB0d2E4D82a8E4d08B6C2a"),
+			RedeemerOutputScript: redeemerOutputScript,
+			RequestedAmount:      150000,
+			TreasuryFee:          1000,
+			TxMaxFee:             5000,
+			RequestedAt:          time.Now(),
+		},
+	)
+
+	redemptionProposal := &RedemptionProposal{
+		RedeemersOutputScripts: []bitcoin.Script{redeemerOutputScript},
+		RedemptionTxFee:        big.NewInt(1500),
+	}
+
+	err = hostChain.setRedemptionProposalValidationResult(
+		walletPublicKeyHash,
+		redemptionProposal,
+		true,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	redemptionAction := newRedemptionAction(
+		logger.With(),
+		hostChain,
+		bitcoinChain,
+		wallet,
+		signingExecutor,
+		redemptionProposal,
+		200,
+		200+redemptionProposalValidityBlocks,
+		func(ctx context.Context, blockHeight uint64) error {
+			return nil
+		},
+	)
+	redemptionAction.broadcastCheckDelay = 100 * time.Millisecond
+
+	if err := redemptionAction.execute(); err != nil {
+		t.Fatal(err)
+	}
+
+	redemptionTransaction := assertSimnetTransactionConfirmed(
+		t,
+		bitcoinChain,
+		walletPublicKeyHash,
+	)
+
+	testutils.AssertBytesEqual(
+		t,
+		mainUtxo.Outpoint.TransactionHash[:],
+		redemptionTransaction.Inputs[0].Outpoint.TransactionHash[:],
+	)
+
+	redeemerOutputFound := false
+	for _, output := range redemptionTransaction.Outputs {
+		if string(output.PublicKeyScript) == string(redeemerOutputScript) {
+			redeemerOutputFound = true
+			testutils.AssertIntsEqual(
+				t,
+				"redeemer output value",
+				150000-1000-1500,
+				int(output.Value),
+			)
+		}
+	}
+	if !redeemerOutputFound {
+		t.Fatal("redemption transaction does not pay the redeemer")
+	}
+}
+
+// localKeySigningExecutor is a walletSigningExecutor producing signatures
+// with a local private key, instead of running the tECDSA signing protocol.
+type localKeySigningExecutor struct {
+	privateKey *ecdsa.PrivateKey
+}
+
+func (lkse *localKeySigningExecutor) signBatch(
+	ctx context.Context,
+	messages []*big.Int,
+	startBlock uint64,
+	actionType WalletActionType,
+) ([]*tecdsa.Signature, error) {
+	signatures := make([]*tecdsa.Signature, len(messages))
+
+	for i, message := range messages {
+		r, s, err := ecdsa.Sign(rand.Reader, lkse.privateKey, message.Bytes())
+		if err != nil {
+			return nil, err
+		}
+
+		signatures[i] = &tecdsa.Signature{R: r, S: s}
+	}
+
+	return signatures, nil
+}
+
+// fundSimnetWalletDeposit funds and confirms a deposit of the given value
+// targeting the given wallet.
+func fundSimnetWalletDeposit(
+	t *testing.T,
+	bitcoinChain *simnet.Chain,
+	walletPublicKeyHash [20]byte,
+	value int64,
+	witness bool,
+) *Deposit {
+	tipHeader := latestBlockHeader(t, bitcoinChain)
+
+	var refundLocktime [4]byte
+	binary.LittleEndian.PutUint32(
+		refundLocktime[:],
+		tipHeader.Time+30*24*3600,
+	)
+
+	deposit := &Deposit{
+		Depositor:           chain.Address("934b98637ca318a4d6e7ca6ffd1690b8e77df637"),
+		BlindingFactor:      [8]byte{0xf9, 0xf0, 0xc9, 0x0d, 0x00, 0x03, 0x95, 0x23},
+		WalletPublicKeyHash: walletPublicKeyHash,
+		RefundPublicKeyHash: bitcoin.PublicKeyHash(
+			&newDepositRefundTestKey(t).PublicKey,
+		),
+		RefundLocktime: refundLocktime,
+	}
+
+	depositScript, err := deposit.Script()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var lockingScript bitcoin.Script
+	if witness {
+		lockingScript, err = bitcoin.PayToWitnessScriptHash(
+			bitcoin.WitnessScriptHash(depositScript),
+		)
+	} else {
+		lockingScript, err = bitcoin.PayToScriptHash(
+			bitcoin.ScriptHash(depositScript),
+		)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fundingTransaction, err := bitcoinChain.Fund(lockingScript, value)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := bitcoinChain.MineBlocks(1); err != nil {
+		t.Fatal(err)
+	}
+
+	deposit.Utxo = &bitcoin.UnspentTransactionOutput{
+		Outpoint: &bitcoin.TransactionOutpoint{
+			TransactionHash: fundingTransaction.Hash(),
+			OutputIndex:     0,
+		},
+		Value: value,
+	}
+
+	return deposit
+}
+
+// assertSimnetTransactionConfirmed mines the wallet transaction waiting in
+// the mempool, along with enough blocks to prove it, and verifies its SPV
+// proof assembled from the simulated chain. Returns the mined transaction.
+func assertSimnetTransactionConfirmed(
+	t *testing.T,
+	bitcoinChain *simnet.Chain,
+	walletPublicKeyHash [20]byte,
+) *bitcoin.Transaction {
+	mempool, err := bitcoinChain.GetMempoolForPublicKeyHash(walletPublicKeyHash)
+	if err != nil {
+		t.Fatal(err)
+	}
+	testutils.AssertIntsEqual(t, "mempool size", 1, len(mempool))
+
+	transactionHash := mempool[0].Hash()
+
+	requiredConfirmations := uint(6)
+	if err := bitcoinChain.MineBlocks(requiredConfirmations); err != nil {
+		t.Fatal(err)
+	}
+
+	transaction, proof, err := bitcoin.AssembleSpvProof(
+		transactionHash,
+		requiredConfirmations,
+		bitcoinChain,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The simulator mines blocks with the minimum difficulty.
+	difficulty := latestBlockHeader(t, bitcoinChain).Difficulty()
+
+	if err := bitcoin.VerifySpvProof(
+		transaction,
+		proof,
+		requiredConfirmations,
+		big.NewInt(int64(requiredConfirmations)),
+		difficulty,
+		difficulty,
+	); err != nil {
+		t.Fatal(err)
+	}
+
+	return transaction
+}
@@ -49,6 +49,7 @@ const (
 	DefaultPreParamsGenerationTimeout     = 2 * time.Minute
 	DefaultPreParamsGenerationDelay       = 10 * time.Second
 	DefaultPreParamsGenerationConcurrency = 1
+	DefaultFeeBumpMinPendingBlocks        = 6
 )
 
 var DefaultKeyGenerationConcurrency = runtime.GOMAXPROCS(0)
@@ -65,6 +66,29 @@ type Config struct {
 	PreParamsGenerationConcurrency int
 	// Concurrency level for key-generation for tECDSA.
 	KeyGenerationConcurrency int
+	// Name of the protocol profile determining protocol timings. The mainnet
+	// profile is used if empty. Group parameters are always read from the
+	// chain.
+	ProtocolProfile string
+	// Path to the JSON file holding the operator-defined signing policy
+	// evaluated against wallet transactions before signing them. No policy
+	// is applied if empty.
+	SigningPolicyFile string
+	// Enables the observer mode. In the observer mode, the node takes part in
+	// coordination and listens to protocol messages but never produces
+	// signature shares nor submits DKG results, DKG result challenges and
+	// approvals, or inactivity claims. Instead, it records what it would have
+	// done in the work persistence.
+	ObserverMode bool
+	// Minimum number of Bitcoin blocks a wallet transaction must remain
+	// unconfirmed before the node, acting as the coordination leader,
+	// proposes to bump its fee.
+	FeeBumpMinPendingBlocks uint
+	// Enables proactive refresh of key shares of live wallets. Key refresh
+	// is disabled by default. When disabled, the node neither proposes key
+	// refresh as the coordination leader nor takes part in key refreshes
+	// proposed by other leaders.
+	KeyRefreshEnabled bool
 }
 
 // Initialize kicks off the TBTC by initializing internal state, ensuring
@@ -82,14 +106,40 @@ func Initialize(
 	config Config,
 	clientInfo *clientinfo.Registry,
 ) error {
-	groupParameters := &GroupParameters{
-		GroupSize:       100,
-		GroupQuorum:     90,
-		HonestThreshold: 51,
+	chainGroupParameters, err := chain.GroupParameters()
+	if err != nil {
+		return fmt.Errorf("cannot get group parameters: [%v]", err)
+	}
+
+	profile, err := resolveProtocolProfile(config, chainGroupParameters)
+	if err != nil {
+		return fmt.Errorf("cannot resolve protocol profile: [%v]", err)
+	}
+
+	dkgParameters, err := chain.DKGParameters()
+	if err != nil {
+		return fmt.Errorf("cannot get DKG parameters: [%v]", err)
 	}
 
+	if err := profile.validateAgainstDKGParameters(dkgParameters); err != nil {
+		return fmt.Errorf(
+			"protocol profile is inconsistent with the chain: [%v]",
+			err,
+		)
+	}
+
+	logger.Infof(
+		"using protocol profile with group size [%v], group quorum [%v], "+
+			"honest threshold [%v], and coordination frequency [%v] blocks",
+		profile.GroupSize,
+		profile.GroupQuorum,
+		profile.HonestThreshold,
+		profile.CoordinationFrequencyBlocks,
+	)
+
 	node, err := newNode(
-		groupParameters,
+		&profile.GroupParameters,
+		&profile.ProtocolTimings,
 		chain,
 		btcChain,
 		netProvider,
@@ -103,6 +153,10 @@ func Initialize(
 		return fmt.Errorf("cannot set up TBTC node: [%v]", err)
 	}
 
+	// Expose per-wallet metrics only if the client info endpoint is
+	// configured. Metrics are kept internally anyway.
+	node.walletMetrics.expose(clientInfo)
+
 	err = node.runCoordinationLayer(ctx)
 	if err != nil {
 		return fmt.Errorf("cannot run coordination layer: [%w]", err)
@@ -118,10 +172,44 @@ func Initialize(
 				"pre_params_count": func() float64 {
 					return float64(node.dkgExecutor.preParamsCount())
 				},
+				"wallet_monitor_unexplained_transactions": func() float64 {
+					return float64(node.walletMonitor.unexplainedTransactionsCount())
+				},
+				"wallet_monitor_unexplained_spends": func() float64 {
+					return float64(node.walletMonitor.unexplainedSpendsCount())
+				},
+				"key_share_audit_findings": func() float64 {
+					return float64(node.keyShareAudit.findingsCount())
+				},
 			},
 		)
+
+		clientInfo.RegisterApplicationSource(
+			"tbtc_wallet_monitor",
+			node.walletMonitor.diagnostics,
+		)
+
+		clientInfo.RegisterApplicationSource(
+			"tbtc_action_history",
+			node.actionHistory.diagnostics,
+		)
+
+		clientInfo.RegisterApplicationSource(
+			"tbtc_key_share_audit",
+			node.keyShareAudit.diagnostics,
+		)
+
+		if node.observer != nil {
+			clientInfo.RegisterApplicationSource(
+				"tbtc_observer",
+				node.observer.diagnostics,
+			)
+		}
 	}
 
+	go node.walletMonitor.run(ctx)
+	go node.pendingTransactions.run(ctx)
+
 	err = sortition.MonitorPool(
 		ctx,
 		logger,
@@ -155,7 +243,8 @@ func Initialize(
 				return
 			}
 
-			confirmationBlock := event.BlockNumber + dkgStartedConfirmationBlocks
+			confirmationBlock := event.BlockNumber +
+				profile.DKGStartedConfirmationBlocks
 
 			logger.Infof(
 				"observed DKG started event with seed [0x%x] and "+
@@ -185,7 +274,8 @@ func Initialize(
 				// we received.
 				pastEvents, err := chain.PastDKGStartedEvents(
 					&DKGStartedEventFilter{
-						StartBlock: event.BlockNumber - dkgStartedConfirmationBlocks,
+						StartBlock: event.BlockNumber -
+							profile.DKGStartedConfirmationBlocks,
 					},
 				)
 				if err != nil {
@@ -217,7 +307,7 @@ func Initialize(
 				node.joinDKGIfEligible(
 					lastEvent.Seed,
 					lastEvent.BlockNumber,
-					dkgStartedConfirmationBlocks,
+					profile.DKGStartedConfirmationBlocks,
 				)
 			} else {
 				logger.Infof(
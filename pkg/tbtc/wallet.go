@@ -6,6 +6,7 @@ import (
 	"crypto/ecdsa"
 	"crypto/elliptic"
 	"encoding/hex"
+	"errors"
 	"fmt"
 	"math/big"
 	"sync"
@@ -31,6 +32,8 @@ const (
 	ActionRedemption
 	ActionMovingFunds
 	ActionMovedFundsSweep
+	ActionFeeBump
+	ActionKeyRefresh
 )
 
 // ParseWalletActionType parses the given value into a WalletActionType.
@@ -48,6 +51,10 @@ func ParseWalletActionType(value uint8) (WalletActionType, error) {
 		return ActionMovingFunds, nil
 	case 5:
 		return ActionMovedFundsSweep, nil
+	case 6:
+		return ActionFeeBump, nil
+	case 7:
+		return ActionKeyRefresh, nil
 	default:
 		return 0, fmt.Errorf("unknown wallet action type [%v]", value)
 	}
@@ -67,6 +74,10 @@ func (wat WalletActionType) String() string {
 		return "MovingFunds"
 	case ActionMovedFundsSweep:
 		return "MovedFundsSweep"
+	case ActionFeeBump:
+		return "FeeBump"
+	case ActionKeyRefresh:
+		return "KeyRefresh"
 	default:
 		panic("unknown wallet action type")
 	}
@@ -190,6 +201,7 @@ type walletSigningExecutor interface {
 		ctx context.Context,
 		messages []*big.Int,
 		startBlock uint64,
+		actionType WalletActionType,
 	) ([]*tecdsa.Signature, error)
 }
 
@@ -202,6 +214,25 @@ type walletTransactionExecutor struct {
 	signingExecutor walletSigningExecutor
 
 	waitForBlockFn waitForBlockFn
+
+	// actionRecorder records the progress of the action using the executor
+	// in the wallet action history. It may be nil if the action is not
+	// recorded.
+	actionRecorder *walletActionRecorder
+
+	// pendingTransactions keeps signed transactions until they are confirmed
+	// so they survive a client restart. It may be nil.
+	pendingTransactions *pendingTransactionsTracker
+
+	// actionType is the type of the action using the executor. It is used
+	// to label metrics.
+	actionType WalletActionType
+	// metrics keeps operational metrics of the executing wallet. It may
+	// be nil.
+	metrics *walletMetrics
+	// signingPolicy is the operator-defined policy evaluated before signing
+	// a transaction. It may be nil if no policy is set.
+	signingPolicy *signingPolicyEngine
 }
 
 func newWalletTransactionExecutor(
@@ -227,6 +258,34 @@ func (wte *walletTransactionExecutor) signTransaction(
 	signingStartBlock uint64,
 	signingTimeoutBlock uint64,
 ) (*bitcoin.Transaction, error) {
+	signTxLogger.Infof("evaluating transaction against the signing policy")
+
+	walletPublicKeyHash := bitcoin.PublicKeyHash(wte.executingWallet.publicKey)
+
+	policyMovement, err := wte.signingPolicy.evaluate(
+		walletPublicKeyHash,
+		unsignedTx,
+	)
+	if err != nil {
+		var vetoErr *signingPolicyVetoError
+		if errors.As(err, &vetoErr) {
+			signTxLogger.Warnf(
+				"transaction vetoed by the signing policy: [%v]",
+				vetoErr,
+			)
+			wte.metrics.recordSigningPolicyVeto(
+				wte.executingWallet.publicKey,
+				wte.actionType,
+				vetoErr.rule,
+			)
+		}
+
+		return nil, fmt.Errorf(
+			"transaction rejected by the signing policy: [%w]",
+			err,
+		)
+	}
+
 	signTxLogger.Infof("computing transaction's sig hashes")
 
 	sigHashes, err := unsignedTx.ComputeSignatureHashes()
@@ -237,6 +296,14 @@ func (wte *walletTransactionExecutor) signTransaction(
 		)
 	}
 
+	err = wte.actionRecorder.recordSigning(sigHashes)
+	if err != nil {
+		return nil, fmt.Errorf(
+			"cannot record transaction's sig hashes in action history: [%v]",
+			err,
+		)
+	}
+
 	signTxLogger.Infof("signing transaction's sig hashes")
 
 	signingCtx, cancelSigningCtx := withCancelOnBlock(
@@ -250,6 +317,7 @@ func (wte *walletTransactionExecutor) signTransaction(
 		signingCtx,
 		sigHashes,
 		signingStartBlock,
+		wte.actionType,
 	)
 	if err != nil {
 		return nil, fmt.Errorf(
@@ -277,6 +345,26 @@ func (wte *walletTransactionExecutor) signTransaction(
 		)
 	}
 
+	wte.actionRecorder.recordTransaction(tx.Hash(), false)
+
+	// The value moved by the transaction counts towards the daily limit
+	// only once the transaction is signed. Vetoed or failed signings, as
+	// well as observed ones, do not consume the limit.
+	wte.signingPolicy.commit(walletPublicKeyHash, policyMovement)
+
+	// A failure here must not prevent the broadcast of the signed
+	// transaction. The transaction just won't be resumed after a restart.
+	err = wte.pendingTransactions.add(
+		bitcoin.PublicKeyHash(wte.executingWallet.publicKey),
+		tx,
+	)
+	if err != nil {
+		signTxLogger.Errorf(
+			"cannot persist signed transaction: [%v]",
+			err,
+		)
+	}
+
 	signTxLogger.Infof("transaction created successfully")
 
 	return tx, nil
@@ -293,13 +381,43 @@ func (wte *walletTransactionExecutor) broadcastTransaction(
 ) error {
 	txHash := tx.Hash()
 
+	broadcastStart := time.Now()
+	broadcastAttempt := 0
+	defer func() {
+		wte.metrics.recordBroadcast(
+			wte.executingWallet.publicKey,
+			wte.actionType,
+			broadcastAttempt,
+			time.Since(broadcastStart),
+		)
+	}()
+
 	broadcastCtx, cancelBroadcastCtx := context.WithTimeout(
 		context.Background(),
 		timeout,
 	)
 	defer cancelBroadcastCtx()
 
-	broadcastAttempt := 0
+	// If the Bitcoin chain supports push notifications, watch the status of
+	// the transaction's first output script to learn about the transaction
+	// reaching the mempool without waiting for the whole check delay.
+	var scriptStatusChanged <-chan struct{}
+	if notifier, ok := wte.btcChain.(bitcoin.ChainNotifier); ok &&
+		len(tx.Outputs) > 0 {
+		statusChangedChan := make(chan struct{}, 1)
+		statusSubscription := notifier.OnScriptStatusChanged(
+			tx.Outputs[0].PublicKeyScript,
+			func(event *bitcoin.ScriptStatusChangedEvent) {
+				select {
+				case statusChangedChan <- struct{}{}:
+				default:
+				}
+			},
+		)
+		defer statusSubscription.Unsubscribe()
+
+		scriptStatusChanged = statusChangedChan
+	}
 
 	for {
 		select {
@@ -332,6 +450,10 @@ func (wte *walletTransactionExecutor) broadcastTransaction(
 
 			select {
 			case <-time.After(checkDelay):
+			case <-scriptStatusChanged:
+				broadcastTxLogger.Infof(
+					"status of the transaction's output script changed",
+				)
 			case <-broadcastCtx.Done():
 				return fmt.Errorf("broadcast timeout exceeded")
 			}
@@ -351,6 +473,7 @@ func (wte *walletTransactionExecutor) broadcastTransaction(
 			}
 
 			broadcastTxLogger.Infof("transaction is known on Bitcoin chain")
+			wte.actionRecorder.recordTransaction(txHash, true)
 			return nil
 		}
 	}
@@ -688,6 +811,12 @@ type signer struct {
 	// privateKeyShare is the tECDSA private key share required to participate
 	// in the signing process.
 	privateKeyShare *tecdsa.PrivateKeyShare
+
+	// refreshEpoch is the number of key refreshes the private key share went
+	// through. It is 0 for key shares produced by DKG. Shares of the same
+	// wallet with a lower epoch are stale and cannot be used for signing
+	// along with the shares of the latest epoch.
+	refreshEpoch uint32
 }
 
 // newSigner constructs a new instance of the wallet's signer.
@@ -709,6 +838,20 @@ func newSigner(
 	}
 }
 
+// refreshEpochSessionID extends the given protocol session ID with the given
+// refresh epoch of key shares. Since members announce readiness for the given
+// session ID, members holding key shares of different refresh epochs never
+// consider each other ready and never execute a protocol together. Session IDs
+// of key shares that were never refreshed are not changed so they remain
+// compatible with clients not aware of refresh epochs.
+func refreshEpochSessionID(sessionID string, refreshEpoch uint32) string {
+	if refreshEpoch == 0 {
+		return sessionID
+	}
+
+	return fmt.Sprintf("%v-epoch-%v", sessionID, refreshEpoch)
+}
+
 func (s *signer) String() string {
 	return fmt.Sprintf(
 		"signer with index [%v] of wallet [%s]",
@@ -0,0 +1,545 @@
+package tbtc
+
+import (
+	"crypto/ecdsa"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/keep-network/keep-core/internal/hexutils"
+	"github.com/keep-network/keep-core/pkg/bitcoin"
+	"github.com/keep-network/keep-core/pkg/clientinfo"
+)
+
+// Names of per-wallet metrics. Each name is prefixed with the application
+// name upon registration.
+const (
+	// walletCoordinationOutcomesMetricName counts coordination procedures
+	// completed by the wallet, labelled by the proposed action type.
+	walletCoordinationOutcomesMetricName = "wallet_coordination_outcomes_total"
+	// walletCoordinationFailuresMetricName counts coordination procedures
+	// of the wallet that failed with an error.
+	walletCoordinationFailuresMetricName = "wallet_coordination_failures_total"
+	// walletCoordinationFaultsMetricName counts coordination faults observed
+	// for the wallet, labelled by the fault type.
+	walletCoordinationFaultsMetricName = "wallet_coordination_faults_total"
+	// walletSigningAttemptsMetricName counts signing attempts executed by
+	// the wallet, labelled by the action type.
+	walletSigningAttemptsMetricName = "wallet_signing_attempts_total"
+	// walletSigningRetriesMetricName counts signing attempts executed by
+	// the wallet after the first attempt for the given message failed,
+	// labelled by the action type.
+	walletSigningRetriesMetricName = "wallet_signing_retries_total"
+	// walletSigningFailuresMetricName counts messages the wallet failed
+	// to sign, labelled by the action type.
+	walletSigningFailuresMetricName = "wallet_signing_failures_total"
+	// walletHeartbeatFailuresMetricName is the current value of the wallet's
+	// consecutive heartbeat failure counter.
+	walletHeartbeatFailuresMetricName = "wallet_heartbeat_failures"
+	// walletInactivityClaimsMetricName counts inactivity claims initiated
+	// by the wallet.
+	walletInactivityClaimsMetricName = "wallet_inactivity_claims_total"
+	// walletBroadcastAttemptsMetricName counts Bitcoin transaction broadcast
+	// attempts made by the wallet, labelled by the action type.
+	walletBroadcastAttemptsMetricName = "wallet_broadcast_attempts_total"
+	// walletBroadcastDurationMetricName is the duration in seconds of the
+	// wallet's last Bitcoin transaction broadcast, labelled by the action
+	// type.
+	walletBroadcastDurationMetricName = "wallet_broadcast_duration_seconds"
+	// walletSigningPolicyVetoesMetricName counts wallet transactions vetoed
+	// by the operator-defined signing policy, labelled by the action type
+	// and the vetoing rule.
+	walletSigningPolicyVetoesMetricName = "wallet_signing_policy_vetoes_total"
+	// walletKeyShareAuditFindingsMetricName is the number of signing group
+	// member indexes of the wallet affected by the given key share audit
+	// finding, labelled by the finding kind.
+	walletKeyShareAuditFindingsMetricName = "wallet_key_share_audit_findings"
+)
+
+// walletMetricsHelp holds descriptions of per-wallet metrics exposed along
+// with them.
+var walletMetricsHelp = map[string]string{
+	walletCoordinationOutcomesMetricName:  "Completed coordination procedures.",
+	walletCoordinationFailuresMetricName:  "Failed coordination procedures.",
+	walletCoordinationFaultsMetricName:    "Observed coordination faults.",
+	walletSigningAttemptsMetricName:       "Executed signing attempts.",
+	walletSigningRetriesMetricName:        "Signing attempts executed after the first one failed.",
+	walletSigningFailuresMetricName:       "Messages that could not be signed.",
+	walletHeartbeatFailuresMetricName:     "Consecutive heartbeat failures.",
+	walletInactivityClaimsMetricName:      "Initiated inactivity claims.",
+	walletBroadcastAttemptsMetricName:     "Bitcoin transaction broadcast attempts.",
+	walletBroadcastDurationMetricName:     "Duration of the last Bitcoin transaction broadcast.",
+	walletSigningPolicyVetoesMetricName:   "Transactions vetoed by the signing policy.",
+	walletKeyShareAuditFindingsMetricName: "Member indexes affected by the key share audit finding.",
+}
+
+const (
+	// walletMetricsWalletLabel is the name of the label holding the wallet
+	// public key hash.
+	walletMetricsWalletLabel = "wallet_pkh"
+	// walletMetricsActionLabel is the name of the label holding the wallet
+	// action type.
+	walletMetricsActionLabel = "action"
+	// walletMetricsFaultLabel is the name of the label holding the
+	// coordination fault type.
+	walletMetricsFaultLabel = "fault"
+	// walletMetricsRuleLabel is the name of the label holding the signing
+	// policy rule.
+	walletMetricsRuleLabel = "rule"
+	// walletMetricsFindingLabel is the name of the label holding the key
+	// share audit finding kind.
+	walletMetricsFindingLabel = "finding"
+)
+
+// walletMetricLabel is an additional label of a per-wallet metric.
+type walletMetricLabel struct {
+	name  string
+	value string
+}
+
+// walletMetricKind determines how a per-wallet metric is exposed.
+type walletMetricKind int
+
+const (
+	// walletMetricCounter is a metric whose value can only be increased.
+	walletMetricCounter walletMetricKind = iota
+	// walletMetricGauge is a metric whose value can be set arbitrarily.
+	walletMetricGauge
+)
+
+// walletMetricSeries is a single series of a per-wallet metric.
+type walletMetricSeries struct {
+	kind  walletMetricKind
+	value float64
+	// counter exposing the series value. It is nil if the metrics are not
+	// exposed or the series is not a counter.
+	counter prometheus.Counter
+	// gauge exposing the series value. It is nil if the metrics are not
+	// exposed or the series is not a gauge.
+	gauge prometheus.Gauge
+}
+
+// walletMetrics keeps operational metrics of wallets controlled by the node.
+// Metrics are labelled by the wallet public key hash and, where applicable,
+// by the wallet action type. Series are created lazily upon their first
+// update and exposed through the client info registry, if one is set.
+// Metrics updated with add are exposed as counters while metrics updated
+// with set are exposed as gauges. All methods are safe to call on a nil
+// walletMetrics.
+type walletMetrics struct {
+	mutex    sync.Mutex
+	registry *clientinfo.Registry
+	series   map[string]*walletMetricSeries
+
+	// counters and gauges hold metrics registered in the client info
+	// registry, by metric names. Each of them exposes all series of the
+	// given metric.
+	counters map[string]*prometheus.CounterVec
+	gauges   map[string]*prometheus.GaugeVec
+}
+
+func newWalletMetrics() *walletMetrics {
+	return &walletMetrics{
+		series:   make(map[string]*walletMetricSeries),
+		counters: make(map[string]*prometheus.CounterVec),
+		gauges:   make(map[string]*prometheus.GaugeVec),
+	}
+}
+
+// expose makes metrics available through the given client info registry.
+// Series already created are registered immediately.
+func (wm *walletMetrics) expose(registry *clientinfo.Registry) {
+	if wm == nil || registry == nil {
+		return
+	}
+
+	wm.mutex.Lock()
+	defer wm.mutex.Unlock()
+
+	wm.registry = registry
+
+	for key, series := range wm.series {
+		wm.exposeSeries(key, series)
+	}
+}
+
+// add adds the given delta to the given metric of the given wallet.
+// The delta must not be negative as the metric is exposed as a counter.
+func (wm *walletMetrics) add(
+	name string,
+	walletPublicKeyHash [20]byte,
+	delta float64,
+	labels ...walletMetricLabel,
+) {
+	wm.update(
+		name,
+		walletMetricCounter,
+		walletPublicKeyHash,
+		labels,
+		func(value float64) float64 {
+			return value + delta
+		},
+	)
+}
+
+// set sets the given metric of the given wallet to the given value.
+func (wm *walletMetrics) set(
+	name string,
+	walletPublicKeyHash [20]byte,
+	value float64,
+	labels ...walletMetricLabel,
+) {
+	wm.update(
+		name,
+		walletMetricGauge,
+		walletPublicKeyHash,
+		labels,
+		func(float64) float64 {
+			return value
+		},
+	)
+}
+
+// get returns the current value of the given metric of the given wallet.
+func (wm *walletMetrics) get(
+	name string,
+	walletPublicKeyHash [20]byte,
+	labels ...walletMetricLabel,
+) float64 {
+	if wm == nil {
+		return 0
+	}
+
+	wm.mutex.Lock()
+	defer wm.mutex.Unlock()
+
+	key := walletMetricSeriesKey(name, walletPublicKeyHash, labels)
+	if series, ok := wm.series[key]; ok {
+		return series.value
+	}
+
+	return 0
+}
+
+func (wm *walletMetrics) update(
+	name string,
+	kind walletMetricKind,
+	walletPublicKeyHash [20]byte,
+	labels []walletMetricLabel,
+	updateFn func(float64) float64,
+) {
+	if wm == nil {
+		return
+	}
+
+	wm.mutex.Lock()
+	defer wm.mutex.Unlock()
+
+	key := walletMetricSeriesKey(name, walletPublicKeyHash, labels)
+
+	series, ok := wm.series[key]
+	if !ok {
+		series = &walletMetricSeries{kind: kind}
+		if wm.registry != nil {
+			wm.exposeSeries(key, series)
+		}
+		wm.series[key] = series
+	}
+
+	previousValue := series.value
+	series.value = updateFn(series.value)
+
+	if series.counter != nil {
+		series.counter.Add(series.value - previousValue)
+	}
+	if series.gauge != nil {
+		series.gauge.Set(series.value)
+	}
+}
+
+// exposeSeries registers the given series in the client info registry and
+// sets its current value. The metric the series belongs to is registered
+// upon its first series. The series is not exposed if the registration
+// failed.
+func (wm *walletMetrics) exposeSeries(key string, series *walletMetricSeries) {
+	name, labels := parseWalletMetricSeriesKey(key)
+
+	labelNames := make([]string, 0, len(labels))
+	for labelName := range labels {
+		labelNames = append(labelNames, labelName)
+	}
+	sort.Strings(labelNames)
+
+	registeredName := fmt.Sprintf("%s_%s", ProtocolName, name)
+
+	var err error
+	switch series.kind {
+	case walletMetricCounter:
+		counterVec, ok := wm.counters[name]
+		if !ok {
+			counterVec, err = wm.registry.NewLabelledMetricCounter(
+				registeredName,
+				walletMetricsHelp[name],
+				labelNames...,
+			)
+			if err != nil {
+				break
+			}
+			wm.counters[name] = counterVec
+		}
+
+		series.counter, err = counterVec.GetMetricWith(labels)
+		if err != nil {
+			break
+		}
+		series.counter.Add(series.value)
+	case walletMetricGauge:
+		gaugeVec, ok := wm.gauges[name]
+		if !ok {
+			gaugeVec, err = wm.registry.NewLabelledMetricGauge(
+				registeredName,
+				walletMetricsHelp[name],
+				labelNames...,
+			)
+			if err != nil {
+				break
+			}
+			wm.gauges[name] = gaugeVec
+		}
+
+		series.gauge, err = gaugeVec.GetMetricWith(labels)
+		if err != nil {
+			break
+		}
+		series.gauge.Set(series.value)
+	}
+
+	if err != nil {
+		logger.Warnf("could not register wallet metric [%v]: [%v]", name, err)
+	}
+}
+
+// walletMetricSeriesKey builds the key identifying the given series. The key
+// has the form of `name|label1=value1|label2=value2`, with labels sorted by
+// their names.
+func walletMetricSeriesKey(
+	name string,
+	walletPublicKeyHash [20]byte,
+	labels []walletMetricLabel,
+) string {
+	labelsStrings := []string{
+		fmt.Sprintf(
+			"%s=%s",
+			walletMetricsWalletLabel,
+			hexutils.Encode(walletPublicKeyHash[:]),
+		),
+	}
+	for _, label := range labels {
+		labelsStrings = append(
+			labelsStrings,
+			fmt.Sprintf("%s=%s", label.name, label.value),
+		)
+	}
+	sort.Strings(labelsStrings)
+
+	return strings.Join(append([]string{name}, labelsStrings...), "|")
+}
+
+// parseWalletMetricSeriesKey is the inverse of walletMetricSeriesKey.
+func parseWalletMetricSeriesKey(key string) (string, map[string]string) {
+	parts := strings.Split(key, "|")
+
+	labels := make(map[string]string)
+	for _, labelString := range parts[1:] {
+		nameValue := strings.SplitN(labelString, "=", 2)
+		labels[nameValue[0]] = nameValue[1]
+	}
+
+	return parts[0], labels
+}
+
+// recordCoordinationResult records the outcome of a coordination procedure
+// and faults observed during it.
+func (wm *walletMetrics) recordCoordinationResult(result *coordinationResult) {
+	if wm == nil {
+		return
+	}
+
+	walletPublicKeyHash := bitcoin.PublicKeyHash(result.wallet.publicKey)
+
+	wm.add(
+		walletCoordinationOutcomesMetricName,
+		walletPublicKeyHash,
+		1,
+		walletMetricLabel{
+			walletMetricsActionLabel,
+			result.proposal.ActionType().String(),
+		},
+	)
+
+	for _, fault := range result.faults {
+		wm.add(
+			walletCoordinationFaultsMetricName,
+			walletPublicKeyHash,
+			1,
+			walletMetricLabel{walletMetricsFaultLabel, fault.faultType.String()},
+		)
+	}
+}
+
+// recordCoordinationFailure records a failed coordination procedure.
+func (wm *walletMetrics) recordCoordinationFailure(
+	walletPublicKey *ecdsa.PublicKey,
+) {
+	if wm == nil {
+		return
+	}
+
+	wm.add(
+		walletCoordinationFailuresMetricName,
+		bitcoin.PublicKeyHash(walletPublicKey),
+		1,
+	)
+}
+
+// recordSigning records the number of attempts executed to sign a single
+// message for the given action and whether the signing succeeded.
+func (wm *walletMetrics) recordSigning(
+	walletPublicKey *ecdsa.PublicKey,
+	actionType WalletActionType,
+	attempts uint,
+	succeeded bool,
+) {
+	if wm == nil {
+		return
+	}
+
+	walletPublicKeyHash := bitcoin.PublicKeyHash(walletPublicKey)
+	actionLabel := walletMetricLabel{walletMetricsActionLabel, actionType.String()}
+
+	wm.add(
+		walletSigningAttemptsMetricName,
+		walletPublicKeyHash,
+		float64(attempts),
+		actionLabel,
+	)
+
+	if attempts > 1 {
+		wm.add(
+			walletSigningRetriesMetricName,
+			walletPublicKeyHash,
+			float64(attempts-1),
+			actionLabel,
+		)
+	}
+
+	if !succeeded {
+		wm.add(
+			walletSigningFailuresMetricName,
+			walletPublicKeyHash,
+			1,
+			actionLabel,
+		)
+	}
+}
+
+// recordHeartbeatFailures records the current value of the consecutive
+// heartbeat failure counter of the wallet.
+func (wm *walletMetrics) recordHeartbeatFailures(
+	walletPublicKey *ecdsa.PublicKey,
+	failures uint,
+) {
+	if wm == nil {
+		return
+	}
+
+	wm.set(
+		walletHeartbeatFailuresMetricName,
+		bitcoin.PublicKeyHash(walletPublicKey),
+		float64(failures),
+	)
+}
+
+// recordInactivityClaim records an inactivity claim initiated by the wallet.
+func (wm *walletMetrics) recordInactivityClaim(
+	walletPublicKey *ecdsa.PublicKey,
+) {
+	if wm == nil {
+		return
+	}
+
+	wm.add(
+		walletInactivityClaimsMetricName,
+		bitcoin.PublicKeyHash(walletPublicKey),
+		1,
+	)
+}
+
+// recordBroadcast records the number of attempts and the duration of
+// a Bitcoin transaction broadcast performed as part of the given action.
+func (wm *walletMetrics) recordBroadcast(
+	walletPublicKey *ecdsa.PublicKey,
+	actionType WalletActionType,
+	attempts int,
+	duration time.Duration,
+) {
+	if wm == nil {
+		return
+	}
+
+	walletPublicKeyHash := bitcoin.PublicKeyHash(walletPublicKey)
+	actionLabel := walletMetricLabel{walletMetricsActionLabel, actionType.String()}
+
+	wm.add(
+		walletBroadcastAttemptsMetricName,
+		walletPublicKeyHash,
+		float64(attempts),
+		actionLabel,
+	)
+	wm.set(
+		walletBroadcastDurationMetricName,
+		walletPublicKeyHash,
+		duration.Seconds(),
+		actionLabel,
+	)
+}
+
+// recordSigningPolicyVeto records a wallet transaction of the given action
+// vetoed by the given signing policy rule.
+func (wm *walletMetrics) recordSigningPolicyVeto(
+	walletPublicKey *ecdsa.PublicKey,
+	actionType WalletActionType,
+	rule string,
+) {
+	if wm == nil {
+		return
+	}
+
+	wm.add(
+		walletSigningPolicyVetoesMetricName,
+		bitcoin.PublicKeyHash(walletPublicKey),
+		1,
+		walletMetricLabel{walletMetricsActionLabel, actionType.String()},
+		walletMetricLabel{walletMetricsRuleLabel, rule},
+	)
+}
+
+// recordKeyShareAuditFinding records the given key share audit finding.
+func (wm *walletMetrics) recordKeyShareAuditFinding(
+	finding *keyShareAuditFinding,
+) {
+	if wm == nil {
+		return
+	}
+
+	wm.set(
+		walletKeyShareAuditFindingsMetricName,
+		finding.walletPublicKeyHash,
+		float64(len(finding.MemberIndexes)),
+		walletMetricLabel{walletMetricsFindingLabel, finding.Kind},
+	)
+}
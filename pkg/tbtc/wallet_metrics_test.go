@@ -0,0 +1,242 @@
+package tbtc
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/rand"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+
+	"github.com/keep-network/keep-core/internal/hexutils"
+	"github.com/keep-network/keep-core/pkg/bitcoin"
+	"github.com/keep-network/keep-core/pkg/chain"
+	"github.com/keep-network/keep-core/pkg/clientinfo"
+	"github.com/keep-network/keep-core/pkg/tecdsa"
+)
+
+func TestWalletMetrics(t *testing.T) {
+	walletPrivateKey, err := ecdsa.GenerateKey(tecdsa.Curve, rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	walletPublicKey := &walletPrivateKey.PublicKey
+	walletPublicKeyHash := bitcoin.PublicKeyHash(walletPublicKey)
+
+	metrics := newWalletMetrics()
+
+	result := &coordinationResult{
+		wallet:   wallet{publicKey: walletPublicKey},
+		window:   newCoordinationWindow(900, mainnetProtocolTimings()),
+		proposal: &HeartbeatProposal{},
+		faults: []*coordinationFault{
+			{
+				culprit:   chain.Address("0x01"),
+				faultType: FaultLeaderIdleness,
+			},
+			{
+				culprit:   chain.Address("0x02"),
+				faultType: FaultLeaderIdleness,
+			},
+		},
+	}
+
+	metrics.recordCoordinationResult(result)
+	metrics.recordCoordinationResult(result)
+	metrics.recordCoordinationFailure(walletPublicKey)
+	metrics.recordSigning(walletPublicKey, ActionRedemption, 1, true)
+	metrics.recordSigning(walletPublicKey, ActionRedemption, 3, false)
+	metrics.recordSigning(walletPublicKey, ActionHeartbeat, 2, true)
+	metrics.recordHeartbeatFailures(walletPublicKey, 2)
+	metrics.recordInactivityClaim(walletPublicKey)
+	metrics.recordBroadcast(walletPublicKey, ActionRedemption, 2, 3*time.Second)
+	metrics.recordBroadcast(walletPublicKey, ActionRedemption, 1, time.Second)
+	metrics.recordSigningPolicyVeto(
+		walletPublicKey,
+		ActionRedemption,
+		signingPolicyRuleMaxFeeRate,
+	)
+
+	var tests = map[string]struct {
+		metric        string
+		labels        []walletMetricLabel
+		expectedValue float64
+	}{
+		"coordination outcomes": {
+			metric: walletCoordinationOutcomesMetricName,
+			labels: []walletMetricLabel{
+				{walletMetricsActionLabel, ActionHeartbeat.String()},
+			},
+			expectedValue: 2,
+		},
+		"coordination outcomes of another action": {
+			metric: walletCoordinationOutcomesMetricName,
+			labels: []walletMetricLabel{
+				{walletMetricsActionLabel, ActionRedemption.String()},
+			},
+			expectedValue: 0,
+		},
+		"coordination faults": {
+			metric: walletCoordinationFaultsMetricName,
+			labels: []walletMetricLabel{
+				{walletMetricsFaultLabel, FaultLeaderIdleness.String()},
+			},
+			expectedValue: 4,
+		},
+		"coordination failures": {
+			metric:        walletCoordinationFailuresMetricName,
+			expectedValue: 1,
+		},
+		"signing attempts": {
+			metric: walletSigningAttemptsMetricName,
+			labels: []walletMetricLabel{
+				{walletMetricsActionLabel, ActionRedemption.String()},
+			},
+			expectedValue: 4,
+		},
+		"signing attempts of another action": {
+			metric: walletSigningAttemptsMetricName,
+			labels: []walletMetricLabel{
+				{walletMetricsActionLabel, ActionHeartbeat.String()},
+			},
+			expectedValue: 2,
+		},
+		"signing retries": {
+			metric: walletSigningRetriesMetricName,
+			labels: []walletMetricLabel{
+				{walletMetricsActionLabel, ActionRedemption.String()},
+			},
+			expectedValue: 2,
+		},
+		"signing failures": {
+			metric: walletSigningFailuresMetricName,
+			labels: []walletMetricLabel{
+				{walletMetricsActionLabel, ActionRedemption.String()},
+			},
+			expectedValue: 1,
+		},
+		"signing failures of another action": {
+			metric: walletSigningFailuresMetricName,
+			labels: []walletMetricLabel{
+				{walletMetricsActionLabel, ActionHeartbeat.String()},
+			},
+			expectedValue: 0,
+		},
+		"heartbeat failures": {
+			metric:        walletHeartbeatFailuresMetricName,
+			expectedValue: 2,
+		},
+		"inactivity claims": {
+			metric:        walletInactivityClaimsMetricName,
+			expectedValue: 1,
+		},
+		"broadcast attempts": {
+			metric: walletBroadcastAttemptsMetricName,
+			labels: []walletMetricLabel{
+				{walletMetricsActionLabel, ActionRedemption.String()},
+			},
+			expectedValue: 3,
+		},
+		"broadcast duration": {
+			metric: walletBroadcastDurationMetricName,
+			labels: []walletMetricLabel{
+				{walletMetricsActionLabel, ActionRedemption.String()},
+			},
+			expectedValue: 1,
+		},
+		"signing policy vetoes": {
+			metric: walletSigningPolicyVetoesMetricName,
+			labels: []walletMetricLabel{
+				{walletMetricsActionLabel, ActionRedemption.String()},
+				{walletMetricsRuleLabel, signingPolicyRuleMaxFeeRate},
+			},
+			expectedValue: 1,
+		},
+	}
+
+	for testName, test := range tests {
+		t.Run(testName, func(t *testing.T) {
+			value := metrics.get(test.metric, walletPublicKeyHash, test.labels...)
+
+			if test.expectedValue != value {
+				t.Errorf(
+					"unexpected value\nexpected: %v\nactual:   %v",
+					test.expectedValue,
+					value,
+				)
+			}
+		})
+	}
+}
+
+func TestWalletMetrics_Expose(t *testing.T) {
+	walletPrivateKey, err := ecdsa.GenerateKey(tecdsa.Curve, rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	walletPublicKey := &walletPrivateKey.PublicKey
+	walletPublicKeyHash := bitcoin.PublicKeyHash(walletPublicKey)
+	walletLabel := hexutils.Encode(walletPublicKeyHash[:])
+
+	registry := clientinfo.NewRegistry(context.Background())
+
+	metrics := newWalletMetrics()
+
+	// Series created before exposing must be registered upon exposing.
+	metrics.recordInactivityClaim(walletPublicKey)
+	metrics.recordBroadcast(walletPublicKey, ActionDepositSweep, 2, time.Second)
+	metrics.expose(registry)
+	// Series created after exposing must be registered right away.
+	metrics.recordInactivityClaim(walletPublicKey)
+	metrics.recordBroadcast(walletPublicKey, ActionRedemption, 1, time.Second)
+
+	err = testutil.CollectAndCompare(
+		metrics.counters[walletInactivityClaimsMetricName],
+		strings.NewReader(`
+# HELP tbtc_wallet_inactivity_claims_total Initiated inactivity claims.
+# TYPE tbtc_wallet_inactivity_claims_total counter
+tbtc_wallet_inactivity_claims_total{wallet_pkh="`+walletLabel+`"} 2
+`),
+	)
+	if err != nil {
+		t.Errorf("unexpected inactivity claims metric: [%v]", err)
+	}
+
+	err = testutil.CollectAndCompare(
+		metrics.counters[walletBroadcastAttemptsMetricName],
+		strings.NewReader(`
+# HELP tbtc_wallet_broadcast_attempts_total Bitcoin transaction broadcast attempts.
+# TYPE tbtc_wallet_broadcast_attempts_total counter
+tbtc_wallet_broadcast_attempts_total{action="DepositSweep",wallet_pkh="`+walletLabel+`"} 2
+tbtc_wallet_broadcast_attempts_total{action="Redemption",wallet_pkh="`+walletLabel+`"} 1
+`),
+	)
+	if err != nil {
+		t.Errorf("unexpected broadcast attempts metric: [%v]", err)
+	}
+
+	err = testutil.CollectAndCompare(
+		metrics.gauges[walletBroadcastDurationMetricName],
+		strings.NewReader(`
+# HELP tbtc_wallet_broadcast_duration_seconds Duration of the last Bitcoin transaction broadcast.
+# TYPE tbtc_wallet_broadcast_duration_seconds gauge
+tbtc_wallet_broadcast_duration_seconds{action="DepositSweep",wallet_pkh="`+walletLabel+`"} 1
+tbtc_wallet_broadcast_duration_seconds{action="Redemption",wallet_pkh="`+walletLabel+`"} 1
+`),
+	)
+	if err != nil {
+		t.Errorf("unexpected broadcast duration metric: [%v]", err)
+	}
+
+	// The registry refuses to register a metric with a name already in use.
+	_, err = registry.NewLabelledMetricCounter(
+		"tbtc_wallet_inactivity_claims_total",
+		"",
+		walletMetricsWalletLabel,
+	)
+	if err == nil {
+		t.Errorf("inactivity claims metric is not registered")
+	}
+}
@@ -0,0 +1,701 @@
+package tbtc
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/keep-network/keep-core/internal/hexutils"
+	"github.com/keep-network/keep-core/pkg/bitcoin"
+	"github.com/keep-network/keep-core/pkg/clientinfo"
+)
+
+const (
+	// walletMonitorCheckInterval determines how often the wallet monitor
+	// checks the Bitcoin transactions of wallets controlled by the node.
+	walletMonitorCheckInterval = 10 * time.Minute
+
+	// walletMonitorTransactionsLimit determines the number of the latest
+	// confirmed transactions fetched for each wallet during a single check.
+	// The value should be big enough to cover all transactions a wallet
+	// may perform between two subsequent checks.
+	walletMonitorTransactionsLimit = 20
+
+	// walletMonitorProposalRetention determines how long coordinated
+	// proposals are kept by the wallet monitor. Transactions resulting from
+	// older proposals are expected to be already proven to the Bridge so
+	// they can be explained using the Bridge state.
+	walletMonitorProposalRetention = 7 * 24 * time.Hour
+)
+
+// monitoredProposal is a proposal agreed upon during the coordination
+// procedure of a wallet, recorded by the wallet monitor.
+type monitoredProposal struct {
+	proposal   CoordinationProposal
+	recordedAt time.Time
+}
+
+// unexplainedTransaction represents a Bitcoin transaction of a wallet
+// that cannot be matched with any coordinated proposal or Bridge state.
+type unexplainedTransaction struct {
+	walletPublicKeyHash [20]byte
+	transactionHash     bitcoin.Hash
+	// spend is true if the transaction spends outputs controlled by the
+	// wallet. Otherwise, the transaction only pays the wallet.
+	spend       bool
+	confirmed   bool
+	firstSeenAt time.Time
+}
+
+// walletMonitor is a background component reconciling Bitcoin transactions
+// of wallets controlled by the node with proposals coordinated by those
+// wallets and with the Bridge state. Contrary to the pre-checks done by
+// wallet actions, the monitor detects unexpected spends of wallet funds as
+// soon as they appear in the mempool, without waiting for the next action.
+//
+// The monitor watches transactions that pay the wallet's P2PKH or P2WPKH
+// script, both unconfirmed and confirmed. A transaction is considered
+// explained if it:
+//   - follows from a proposal coordinated by the wallet,
+//   - spends a revealed deposit or a moved funds sweep request outpoint,
+//   - pays a pending redemption request of the wallet,
+//   - creates an outpoint of a moved funds sweep request,
+//   - pays funds of a moving funds wallet to a live wallet,
+//   - belongs to the wallet's main UTXO history known to the Bridge.
+//
+// All other transactions are reported as unexplained.
+type walletMonitor struct {
+	chain    Chain
+	btcChain bitcoin.Chain
+
+	// walletsPublicKeysFn returns public keys of wallets the node controls
+	// at the given moment.
+	walletsPublicKeysFn func() []*ecdsa.PublicKey
+
+	mutex sync.Mutex
+	// proposals holds coordinated proposals of specific wallets, keyed by
+	// the wallet public key hash.
+	proposals map[[20]byte][]*monitoredProposal
+	// explainedTransactions holds hashes of transactions already explained
+	// so they are not processed again.
+	explainedTransactions map[bitcoin.Hash]bool
+	// spentOutpoints maps outpoints spent by explained transactions to
+	// the hashes of the spending transactions. It is used to recognize
+	// replacements of explained transactions.
+	spentOutpoints map[bitcoin.TransactionOutpoint]bitcoin.Hash
+	// unexplainedTransactions holds transactions that could not be
+	// explained so far, keyed by the transaction hash.
+	unexplainedTransactions map[bitcoin.Hash]*unexplainedTransaction
+}
+
+func newWalletMonitor(
+	chain Chain,
+	btcChain bitcoin.Chain,
+	walletsPublicKeysFn func() []*ecdsa.PublicKey,
+) *walletMonitor {
+	return &walletMonitor{
+		chain:                   chain,
+		btcChain:                btcChain,
+		walletsPublicKeysFn:     walletsPublicKeysFn,
+		proposals:               make(map[[20]byte][]*monitoredProposal),
+		explainedTransactions:   make(map[bitcoin.Hash]bool),
+		spentOutpoints:          make(map[bitcoin.TransactionOutpoint]bitcoin.Hash),
+		unexplainedTransactions: make(map[bitcoin.Hash]*unexplainedTransaction),
+	}
+}
+
+// recordProposal records the proposal coordinated by the given wallet.
+// Heartbeat, key refresh, and no-op proposals do not result in Bitcoin
+// transactions so they are ignored.
+func (wm *walletMonitor) recordProposal(
+	walletPublicKey *ecdsa.PublicKey,
+	proposal CoordinationProposal,
+) {
+	switch proposal.ActionType() {
+	case ActionNoop, ActionHeartbeat, ActionKeyRefresh:
+		return
+	}
+
+	walletPublicKeyHash := bitcoin.PublicKeyHash(walletPublicKey)
+
+	wm.mutex.Lock()
+	defer wm.mutex.Unlock()
+
+	now := time.Now()
+
+	proposals := make([]*monitoredProposal, 0)
+	for _, mp := range wm.proposals[walletPublicKeyHash] {
+		if now.Sub(mp.recordedAt) < walletMonitorProposalRetention {
+			proposals = append(proposals, mp)
+		}
+	}
+
+	wm.proposals[walletPublicKeyHash] = append(
+		proposals,
+		&monitoredProposal{
+			proposal:   proposal,
+			recordedAt: now,
+		},
+	)
+}
+
+// run starts the wallet monitor loop. The loop checks all wallets
+// controlled by the node periodically, until the given context is done.
+func (wm *walletMonitor) run(ctx context.Context) {
+	ticker := time.NewTicker(walletMonitorCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			wm.checkWallets()
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// checkWallets checks transactions of all wallets controlled by the node.
+func (wm *walletMonitor) checkWallets() {
+	for _, walletPublicKey := range wm.walletsPublicKeysFn() {
+		walletPublicKeyHash := bitcoin.PublicKeyHash(walletPublicKey)
+
+		err := wm.checkWallet(walletPublicKeyHash)
+		if err != nil {
+			logger.Errorf(
+				"wallet monitor failed to check wallet with PKH [0x%x]: [%v]",
+				walletPublicKeyHash,
+				err,
+			)
+		}
+	}
+}
+
+// checkWallet fetches unconfirmed and the latest confirmed transactions
+// paying the given wallet and tries to explain all of them.
+func (wm *walletMonitor) checkWallet(walletPublicKeyHash [20]byte) error {
+	mempoolTransactions, err := wm.btcChain.GetMempoolForPublicKeyHash(
+		walletPublicKeyHash,
+	)
+	if err != nil {
+		return fmt.Errorf("cannot get mempool transactions: [%v]", err)
+	}
+
+	confirmedTransactions, err := wm.btcChain.GetTransactionsForPublicKeyHash(
+		walletPublicKeyHash,
+		walletMonitorTransactionsLimit,
+	)
+	if err != nil {
+		return fmt.Errorf("cannot get confirmed transactions: [%v]", err)
+	}
+
+	walletData, err := wm.chain.GetWallet(walletPublicKeyHash)
+	if err != nil {
+		return fmt.Errorf("cannot get wallet data: [%v]", err)
+	}
+
+	mainUtxoHistory := wm.mainUtxoHistory(
+		walletPublicKeyHash,
+		walletData,
+		confirmedTransactions,
+	)
+
+	check := func(transaction *bitcoin.Transaction, confirmed bool) error {
+		transactionHash := transaction.Hash()
+
+		wm.mutex.Lock()
+		explained := wm.explainedTransactions[transactionHash]
+		wm.mutex.Unlock()
+
+		if explained {
+			return nil
+		}
+
+		spentOutpoints, err := wm.walletSpentOutpoints(
+			walletPublicKeyHash,
+			transaction,
+		)
+		if err != nil {
+			return fmt.Errorf(
+				"cannot determine wallet outpoints spent by "+
+					"transaction [%s]: [%v]",
+				transactionHash.Hex(bitcoin.ReversedByteOrder),
+				err,
+			)
+		}
+
+		explained = mainUtxoHistory[transactionHash]
+		if !explained {
+			explained, err = wm.explainTransaction(
+				walletPublicKeyHash,
+				walletData,
+				transaction,
+				len(spentOutpoints) > 0,
+			)
+			if err != nil {
+				return fmt.Errorf(
+					"cannot explain transaction [%s]: [%v]",
+					transactionHash.Hex(bitcoin.ReversedByteOrder),
+					err,
+				)
+			}
+		}
+
+		wm.recordCheckResult(
+			walletPublicKeyHash,
+			transaction,
+			spentOutpoints,
+			explained,
+			confirmed,
+		)
+
+		return nil
+	}
+
+	for _, transaction := range confirmedTransactions {
+		if err := check(transaction, true); err != nil {
+			return err
+		}
+	}
+
+	for _, transaction := range mempoolTransactions {
+		if err := check(transaction, false); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// mainUtxoHistory determines which of the given confirmed transactions
+// belong to the history of the wallet's main UTXO registered in the Bridge.
+// The transaction holding the current main UTXO was proven to the Bridge.
+// Each proven transaction spends the main UTXO that was registered at the
+// time of proof so the history can be followed backwards through the
+// wallet's inputs.
+func (wm *walletMonitor) mainUtxoHistory(
+	walletPublicKeyHash [20]byte,
+	walletData *WalletChainData,
+	confirmedTransactions []*bitcoin.Transaction,
+) map[bitcoin.Hash]bool {
+	history := make(map[bitcoin.Hash]bool)
+
+	if walletData.MainUtxoHash == [32]byte{} {
+		return history
+	}
+
+	transactions := make(map[bitcoin.Hash]*bitcoin.Transaction)
+	var current *bitcoin.Transaction
+
+	for _, transaction := range confirmedTransactions {
+		transactionHash := transaction.Hash()
+		transactions[transactionHash] = transaction
+
+		for outputIndex, output := range transaction.Outputs {
+			if !paysPublicKeyHash(output.PublicKeyScript, walletPublicKeyHash) {
+				continue
+			}
+
+			mainUtxoHash := wm.chain.ComputeMainUtxoHash(
+				&bitcoin.UnspentTransactionOutput{
+					Outpoint: &bitcoin.TransactionOutpoint{
+						TransactionHash: transactionHash,
+						OutputIndex:     uint32(outputIndex),
+					},
+					Value: output.Value,
+				},
+			)
+			if mainUtxoHash == walletData.MainUtxoHash {
+				current = transaction
+			}
+		}
+	}
+
+	for current != nil && !history[current.Hash()] {
+		history[current.Hash()] = true
+
+		var previous *bitcoin.Transaction
+		for _, input := range current.Inputs {
+			transaction, ok := transactions[input.Outpoint.TransactionHash]
+			if !ok {
+				continue
+			}
+
+			outputIndex := input.Outpoint.OutputIndex
+			if int(outputIndex) < len(transaction.Outputs) &&
+				paysPublicKeyHash(
+					transaction.Outputs[outputIndex].PublicKeyScript,
+					walletPublicKeyHash,
+				) {
+				previous = transaction
+				break
+			}
+		}
+
+		current = previous
+	}
+
+	return history
+}
+
+// walletSpentOutpoints returns outpoints controlled by the given wallet
+// that are spent by the given transaction.
+func (wm *walletMonitor) walletSpentOutpoints(
+	walletPublicKeyHash [20]byte,
+	transaction *bitcoin.Transaction,
+) ([]*bitcoin.TransactionOutpoint, error) {
+	spentOutpoints := make([]*bitcoin.TransactionOutpoint, 0)
+
+	for _, input := range transaction.Inputs {
+		previousTransaction, err := wm.btcChain.GetTransaction(
+			input.Outpoint.TransactionHash,
+		)
+		if err != nil {
+			return nil, fmt.Errorf(
+				"cannot get previous transaction [%s]: [%v]",
+				input.Outpoint.TransactionHash.Hex(bitcoin.ReversedByteOrder),
+				err,
+			)
+		}
+
+		outputIndex := input.Outpoint.OutputIndex
+		if int(outputIndex) >= len(previousTransaction.Outputs) {
+			return nil, fmt.Errorf(
+				"previous transaction [%s] has no output [%v]",
+				input.Outpoint.TransactionHash.Hex(bitcoin.ReversedByteOrder),
+				outputIndex,
+			)
+		}
+
+		if paysPublicKeyHash(
+			previousTransaction.Outputs[outputIndex].PublicKeyScript,
+			walletPublicKeyHash,
+		) {
+			spentOutpoints = append(spentOutpoints, input.Outpoint)
+		}
+	}
+
+	return spentOutpoints, nil
+}
+
+// explainTransaction tries to match the given transaction with proposals
+// coordinated by the wallet and the Bridge state. The spend flag must be
+// true if the transaction spends outputs controlled by the wallet.
+func (wm *walletMonitor) explainTransaction(
+	walletPublicKeyHash [20]byte,
+	walletData *WalletChainData,
+	transaction *bitcoin.Transaction,
+	spend bool,
+) (bool, error) {
+	if wm.explainedByProposals(walletPublicKeyHash, transaction, spend) {
+		return true, nil
+	}
+
+	transactionHash := transaction.Hash()
+
+	for _, input := range transaction.Inputs {
+		_, found, err := wm.chain.GetDepositRequest(
+			input.Outpoint.TransactionHash,
+			input.Outpoint.OutputIndex,
+		)
+		if err != nil {
+			return false, fmt.Errorf("cannot get deposit request: [%v]", err)
+		}
+		if found {
+			return true, nil
+		}
+
+		movedFundsSweepRequest, found, err := wm.chain.GetMovedFundsSweepRequest(
+			input.Outpoint.TransactionHash,
+			input.Outpoint.OutputIndex,
+		)
+		if err != nil {
+			return false, fmt.Errorf(
+				"cannot get moved funds sweep request: [%v]",
+				err,
+			)
+		}
+		if found &&
+			movedFundsSweepRequest.WalletPublicKeyHash == walletPublicKeyHash {
+			return true, nil
+		}
+	}
+
+	for outputIndex, output := range transaction.Outputs {
+		movedFundsSweepRequest, found, err := wm.chain.GetMovedFundsSweepRequest(
+			transactionHash,
+			uint32(outputIndex),
+		)
+		if err != nil {
+			return false, fmt.Errorf(
+				"cannot get moved funds sweep request: [%v]",
+				err,
+			)
+		}
+		if found &&
+			movedFundsSweepRequest.WalletPublicKeyHash == walletPublicKeyHash {
+			return true, nil
+		}
+
+		if !spend || paysPublicKeyHash(output.PublicKeyScript, walletPublicKeyHash) {
+			continue
+		}
+
+		_, found, err = wm.chain.GetPendingRedemptionRequest(
+			walletPublicKeyHash,
+			output.PublicKeyScript,
+		)
+		if err != nil {
+			return false, fmt.Errorf(
+				"cannot get pending redemption request: [%v]",
+				err,
+			)
+		}
+		if found {
+			return true, nil
+		}
+
+		if walletData.State == StateMovingFunds &&
+			bitcoin.GetScriptType(output.PublicKeyScript) == bitcoin.P2WPKHScript {
+			targetWalletPublicKeyHash, err := bitcoin.ExtractPublicKeyHash(
+				output.PublicKeyScript,
+			)
+			if err != nil {
+				return false, fmt.Errorf(
+					"cannot extract target wallet public key hash: [%v]",
+					err,
+				)
+			}
+
+			// The target wallet may be unknown to the Bridge, in which
+			// case the output remains unexplained.
+			targetWalletData, err := wm.chain.GetWallet(targetWalletPublicKeyHash)
+			if err == nil && targetWalletData.State == StateLive {
+				return true, nil
+			}
+		}
+	}
+
+	return false, nil
+}
+
+// explainedByProposals checks whether the given transaction follows from
+// any of the proposals coordinated by the wallet.
+func (wm *walletMonitor) explainedByProposals(
+	walletPublicKeyHash [20]byte,
+	transaction *bitcoin.Transaction,
+	spend bool,
+) bool {
+	wm.mutex.Lock()
+	defer wm.mutex.Unlock()
+
+	spendsOutpoint := func(transactionHash bitcoin.Hash, outputIndex uint32) bool {
+		for _, input := range transaction.Inputs {
+			if input.Outpoint.TransactionHash == transactionHash &&
+				input.Outpoint.OutputIndex == outputIndex {
+				return true
+			}
+		}
+		return false
+	}
+
+	paysScript := func(script bitcoin.Script) bool {
+		for _, output := range transaction.Outputs {
+			if bytes.Equal(output.PublicKeyScript, script) {
+				return true
+			}
+		}
+		return false
+	}
+
+	for _, mp := range wm.proposals[walletPublicKeyHash] {
+		switch proposal := mp.proposal.(type) {
+		case *DepositSweepProposal:
+			for _, depositKey := range proposal.DepositsKeys {
+				if spendsOutpoint(
+					depositKey.FundingTxHash,
+					depositKey.FundingOutputIndex,
+				) {
+					return true
+				}
+			}
+		case *RedemptionProposal:
+			if !spend {
+				continue
+			}
+
+			for _, script := range proposal.RedeemersOutputScripts {
+				if paysScript(script) {
+					return true
+				}
+			}
+		case *MovingFundsProposal:
+			if !spend {
+				continue
+			}
+
+			for _, targetWallet := range proposal.TargetWallets {
+				script, err := bitcoin.PayToWitnessPublicKeyHash(targetWallet)
+				if err == nil && paysScript(script) {
+					return true
+				}
+			}
+		case *MovedFundsSweepProposal:
+			if spendsOutpoint(
+				proposal.MovingFundsTxHash,
+				proposal.MovingFundsTxOutputIndex,
+			) {
+				return true
+			}
+		case *FeeBumpProposal:
+			// The fee bump transaction replaces the original transaction
+			// so it must spend at least one outpoint the original one spent.
+			for _, input := range transaction.Inputs {
+				if wm.spentOutpoints[*input.Outpoint] == proposal.TransactionHash {
+					return true
+				}
+			}
+		}
+	}
+
+	return false
+}
+
+// recordCheckResult records the result of the given transaction's check.
+func (wm *walletMonitor) recordCheckResult(
+	walletPublicKeyHash [20]byte,
+	transaction *bitcoin.Transaction,
+	spentOutpoints []*bitcoin.TransactionOutpoint,
+	explained bool,
+	confirmed bool,
+) {
+	wm.mutex.Lock()
+	defer wm.mutex.Unlock()
+
+	transactionHash := transaction.Hash()
+
+	if explained {
+		wm.explainedTransactions[transactionHash] = true
+
+		for _, outpoint := range spentOutpoints {
+			wm.spentOutpoints[*outpoint] = transactionHash
+		}
+
+		if _, ok := wm.unexplainedTransactions[transactionHash]; ok {
+			logger.Infof(
+				"wallet monitor explained previously unexplained "+
+					"transaction [%s] of wallet with PKH [0x%x]",
+				transactionHash.Hex(bitcoin.ReversedByteOrder),
+				walletPublicKeyHash,
+			)
+
+			delete(wm.unexplainedTransactions, transactionHash)
+		}
+
+		return
+	}
+
+	if record, ok := wm.unexplainedTransactions[transactionHash]; ok {
+		record.confirmed = confirmed
+		return
+	}
+
+	wm.unexplainedTransactions[transactionHash] = &unexplainedTransaction{
+		walletPublicKeyHash: walletPublicKeyHash,
+		transactionHash:     transactionHash,
+		spend:               len(spentOutpoints) > 0,
+		confirmed:           confirmed,
+		firstSeenAt:         time.Now(),
+	}
+
+	if len(spentOutpoints) > 0 {
+		logger.Warnf(
+			"wallet monitor observed unexplained transaction [%s] "+
+				"spending funds of wallet with PKH [0x%x]",
+			transactionHash.Hex(bitcoin.ReversedByteOrder),
+			walletPublicKeyHash,
+		)
+	} else {
+		logger.Warnf(
+			"wallet monitor observed unexplained transaction [%s] "+
+				"paying wallet with PKH [0x%x]",
+			transactionHash.Hex(bitcoin.ReversedByteOrder),
+			walletPublicKeyHash,
+		)
+	}
+}
+
+// unexplainedTransactionsCount returns the number of unexplained
+// transactions observed so far.
+func (wm *walletMonitor) unexplainedTransactionsCount() int {
+	wm.mutex.Lock()
+	defer wm.mutex.Unlock()
+
+	return len(wm.unexplainedTransactions)
+}
+
+// unexplainedSpendsCount returns the number of unexplained transactions
+// spending wallet funds observed so far.
+func (wm *walletMonitor) unexplainedSpendsCount() int {
+	wm.mutex.Lock()
+	defer wm.mutex.Unlock()
+
+	count := 0
+	for _, record := range wm.unexplainedTransactions {
+		if record.spend {
+			count++
+		}
+	}
+
+	return count
+}
+
+// diagnostics returns the diagnostic information about unexplained
+// transactions observed by the wallet monitor.
+func (wm *walletMonitor) diagnostics() clientinfo.ApplicationInfo {
+	wm.mutex.Lock()
+	defer wm.mutex.Unlock()
+
+	records := make([]*unexplainedTransaction, 0, len(wm.unexplainedTransactions))
+	for _, record := range wm.unexplainedTransactions {
+		records = append(records, record)
+	}
+
+	sort.Slice(records, func(i, j int) bool {
+		return records[i].firstSeenAt.Before(records[j].firstSeenAt)
+	})
+
+	unexplainedTransactions := make([]map[string]interface{}, len(records))
+	for i, record := range records {
+		unexplainedTransactions[i] = map[string]interface{}{
+			"wallet_public_key_hash": hexutils.Encode(
+				record.walletPublicKeyHash[:],
+			),
+			"transaction_hash": record.transactionHash.Hex(
+				bitcoin.ReversedByteOrder,
+			),
+			"spend":         record.spend,
+			"confirmed":     record.confirmed,
+			"first_seen_at": record.firstSeenAt.Unix(),
+		}
+	}
+
+	return clientinfo.ApplicationInfo{
+		"unexplained_transactions": unexplainedTransactions,
+	}
+}
+
+// paysPublicKeyHash checks whether the given script is a P2PKH or P2WPKH
+// script of the given public key hash.
+func paysPublicKeyHash(script bitcoin.Script, publicKeyHash [20]byte) bool {
+	scriptPublicKeyHash, err := bitcoin.ExtractPublicKeyHash(script)
+	if err != nil {
+		return false
+	}
+
+	return scriptPublicKeyHash == publicKeyHash
+}
@@ -0,0 +1,310 @@
+package tbtc
+
+import (
+	"crypto/ecdsa"
+	"crypto/rand"
+	"testing"
+
+	"github.com/keep-network/keep-core/internal/testutils"
+	"github.com/keep-network/keep-core/pkg/bitcoin"
+	"github.com/keep-network/keep-core/pkg/tecdsa"
+)
+
+func TestWalletMonitor(t *testing.T) {
+	localChain := Connect()
+	btcChain := newLocalBitcoinChain()
+
+	walletPrivateKey, err := ecdsa.GenerateKey(tecdsa.Curve, rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	walletPublicKey := &walletPrivateKey.PublicKey
+	walletPublicKeyHash := bitcoin.PublicKeyHash(walletPublicKey)
+
+	walletScript, err := bitcoin.PayToWitnessPublicKeyHash(walletPublicKeyHash)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	localChain.setWallet(walletPublicKeyHash, &WalletChainData{
+		State: StateLive,
+	})
+
+	monitor := newWalletMonitor(
+		localChain,
+		btcChain,
+		func() []*ecdsa.PublicKey {
+			return []*ecdsa.PublicKey{walletPublicKey}
+		},
+	)
+
+	depositScript := bitcoin.Script{0x00, 0x20, 0x01}
+	redeemerScript := bitcoin.Script{0x00, 0x14, 0x02}
+	unknownScript := bitcoin.Script{0x00, 0x14, 0x03}
+
+	// Revealed deposit swept to the wallet.
+	depositTransaction := walletMonitorTestTransaction(
+		t,
+		btcChain,
+		nil,
+		&bitcoin.TransactionOutput{Value: 100000, PublicKeyScript: depositScript},
+	)
+	localChain.setDepositRequest(
+		depositTransaction.Hash(),
+		0,
+		&DepositChainRequest{Amount: 100000},
+	)
+	sweepTransaction := walletMonitorTestTransaction(
+		t,
+		btcChain,
+		[]*bitcoin.TransactionOutpoint{
+			{TransactionHash: depositTransaction.Hash(), OutputIndex: 0},
+		},
+		&bitcoin.TransactionOutput{Value: 99000, PublicKeyScript: walletScript},
+	)
+
+	// Funds sent to the wallet from an unknown source.
+	unknownFundingTransaction := walletMonitorTestTransaction(
+		t,
+		btcChain,
+		nil,
+		&bitcoin.TransactionOutput{Value: 5000, PublicKeyScript: unknownScript},
+	)
+	incomingTransaction := walletMonitorTestTransaction(
+		t,
+		btcChain,
+		[]*bitcoin.TransactionOutpoint{
+			{TransactionHash: unknownFundingTransaction.Hash(), OutputIndex: 0},
+		},
+		&bitcoin.TransactionOutput{Value: 4000, PublicKeyScript: walletScript},
+	)
+
+	// Redemption of a pending redemption request living in the mempool.
+	localChain.setPendingRedemptionRequest(
+		walletPublicKeyHash,
+		&RedemptionRequest{RedeemerOutputScript: redeemerScript},
+	)
+	redemptionTransaction := &bitcoin.Transaction{
+		Version: 1,
+		Inputs: []*bitcoin.TransactionInput{
+			{
+				Outpoint: &bitcoin.TransactionOutpoint{
+					TransactionHash: sweepTransaction.Hash(),
+					OutputIndex:     0,
+				},
+			},
+		},
+		Outputs: []*bitcoin.TransactionOutput{
+			{Value: 30000, PublicKeyScript: redeemerScript},
+			{Value: 68000, PublicKeyScript: walletScript},
+		},
+	}
+
+	// Spend of the wallet funds no one asked for.
+	unexplainedSpendTransaction := &bitcoin.Transaction{
+		Version: 1,
+		Inputs: []*bitcoin.TransactionInput{
+			{
+				Outpoint: &bitcoin.TransactionOutpoint{
+					TransactionHash: incomingTransaction.Hash(),
+					OutputIndex:     0,
+				},
+			},
+		},
+		Outputs: []*bitcoin.TransactionOutput{
+			{Value: 2000, PublicKeyScript: unknownScript},
+			{Value: 1000, PublicKeyScript: walletScript},
+		},
+	}
+
+	btcChain.mempool = append(
+		btcChain.mempool,
+		redemptionTransaction,
+		unexplainedSpendTransaction,
+	)
+
+	monitor.checkWallets()
+
+	testutils.AssertIntsEqual(
+		t,
+		"unexplained transactions count",
+		2,
+		monitor.unexplainedTransactionsCount(),
+	)
+	testutils.AssertIntsEqual(
+		t,
+		"unexplained spends count",
+		1,
+		monitor.unexplainedSpendsCount(),
+	)
+
+	unexplainedSpend, ok :=
+		monitor.unexplainedTransactions[unexplainedSpendTransaction.Hash()]
+	if !ok {
+		t.Fatal("expected unexplained spend record")
+	}
+	testutils.AssertBoolsEqual(t, "spend", true, unexplainedSpend.spend)
+	testutils.AssertBoolsEqual(t, "confirmed", false, unexplainedSpend.confirmed)
+
+	unexplainedIncoming, ok :=
+		monitor.unexplainedTransactions[incomingTransaction.Hash()]
+	if !ok {
+		t.Fatal("expected unexplained incoming record")
+	}
+	testutils.AssertBoolsEqual(t, "spend", false, unexplainedIncoming.spend)
+	testutils.AssertBoolsEqual(t, "confirmed", true, unexplainedIncoming.confirmed)
+
+	diagnostics := monitor.diagnostics()
+	testutils.AssertIntsEqual(
+		t,
+		"diagnostic entries count",
+		2,
+		len(diagnostics["unexplained_transactions"].([]map[string]interface{})),
+	)
+
+	// The wallet coordinates a redemption proposal covering the spend.
+	monitor.recordProposal(walletPublicKey, &RedemptionProposal{
+		RedeemersOutputScripts: []bitcoin.Script{unknownScript},
+	})
+
+	monitor.checkWallets()
+
+	testutils.AssertIntsEqual(
+		t,
+		"unexplained transactions count",
+		1,
+		monitor.unexplainedTransactionsCount(),
+	)
+	testutils.AssertIntsEqual(
+		t,
+		"unexplained spends count",
+		0,
+		monitor.unexplainedSpendsCount(),
+	)
+}
+
+func TestWalletMonitor_MainUtxoHistory(t *testing.T) {
+	localChain := Connect()
+	btcChain := newLocalBitcoinChain()
+
+	walletPublicKeyHash := [20]byte{0x01}
+
+	walletScript, err := bitcoin.PayToPublicKeyHash(walletPublicKeyHash)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sourceTransaction := walletMonitorTestTransaction(
+		t,
+		btcChain,
+		nil,
+		&bitcoin.TransactionOutput{
+			Value:           101000,
+			PublicKeyScript: bitcoin.Script{0x00, 0x14, 0x03},
+		},
+	)
+	fundingTransaction := walletMonitorTestTransaction(
+		t,
+		btcChain,
+		[]*bitcoin.TransactionOutpoint{
+			{TransactionHash: sourceTransaction.Hash(), OutputIndex: 0},
+		},
+		&bitcoin.TransactionOutput{Value: 100000, PublicKeyScript: walletScript},
+	)
+	// Redemption already proven to the Bridge so the redemption request is
+	// no longer pending.
+	redemptionTransaction := walletMonitorTestTransaction(
+		t,
+		btcChain,
+		[]*bitcoin.TransactionOutpoint{
+			{TransactionHash: fundingTransaction.Hash(), OutputIndex: 0},
+		},
+		&bitcoin.TransactionOutput{
+			Value:           20000,
+			PublicKeyScript: bitcoin.Script{0x00, 0x14, 0x02},
+		},
+		&bitcoin.TransactionOutput{Value: 79000, PublicKeyScript: walletScript},
+	)
+
+	localChain.setWallet(walletPublicKeyHash, &WalletChainData{
+		MainUtxoHash: localChain.ComputeMainUtxoHash(
+			&bitcoin.UnspentTransactionOutput{
+				Outpoint: &bitcoin.TransactionOutpoint{
+					TransactionHash: redemptionTransaction.Hash(),
+					OutputIndex:     1,
+				},
+				Value: 79000,
+			},
+		),
+		State: StateLive,
+	})
+
+	monitor := newWalletMonitor(
+		localChain,
+		btcChain,
+		func() []*ecdsa.PublicKey {
+			return []*ecdsa.PublicKey{}
+		},
+	)
+
+	err = monitor.checkWallet(walletPublicKeyHash)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testutils.AssertIntsEqual(
+		t,
+		"unexplained transactions count",
+		0,
+		monitor.unexplainedTransactionsCount(),
+	)
+	testutils.AssertBoolsEqual(
+		t,
+		"funding transaction explained",
+		true,
+		monitor.explainedTransactions[fundingTransaction.Hash()],
+	)
+	testutils.AssertBoolsEqual(
+		t,
+		"redemption transaction explained",
+		true,
+		monitor.explainedTransactions[redemptionTransaction.Hash()],
+	)
+}
+
+// walletMonitorTestTransaction builds a transaction spending the given
+// outpoints and broadcasts it to the given local Bitcoin chain. If no
+// outpoints are given, the transaction spends an arbitrary outpoint not
+// known to the chain.
+func walletMonitorTestTransaction(
+	t *testing.T,
+	btcChain *localBitcoinChain,
+	outpoints []*bitcoin.TransactionOutpoint,
+	outputs ...*bitcoin.TransactionOutput,
+) *bitcoin.Transaction {
+	inputs := make([]*bitcoin.TransactionInput, 0)
+	for _, outpoint := range outpoints {
+		inputs = append(inputs, &bitcoin.TransactionInput{Outpoint: outpoint})
+	}
+
+	if len(inputs) == 0 {
+		inputs = append(inputs, &bitcoin.TransactionInput{
+			Outpoint: &bitcoin.TransactionOutpoint{
+				TransactionHash: bitcoin.Hash{0xff},
+				OutputIndex:     uint32(len(btcChain.transactions)),
+			},
+		})
+	}
+
+	transaction := &bitcoin.Transaction{
+		Version: 1,
+		Inputs:  inputs,
+		Outputs: outputs,
+	}
+
+	if err := btcChain.BroadcastTransaction(transaction); err != nil {
+		t.Fatal(err)
+	}
+
+	return transaction
+}
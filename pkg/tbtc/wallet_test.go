@@ -51,9 +51,17 @@ func TestParseWalletActionType(t *testing.T) {
 			value:          5,
 			expectedAction: ActionMovedFundsSweep,
 		},
+		"fee bump": {
+			value:          6,
+			expectedAction: ActionFeeBump,
+		},
+		"key refresh": {
+			value:          7,
+			expectedAction: ActionKeyRefresh,
+		},
 		"unknown": {
-			value:       6,
-			expectedErr: fmt.Errorf("unknown wallet action type [6]"),
+			value:       8,
+			expectedErr: fmt.Errorf("unknown wallet action type [8]"),
 		},
 	}
 
@@ -431,6 +439,7 @@ func (mwse *mockWalletSigningExecutor) signBatch(
 	ctx context.Context,
 	messages []*big.Int,
 	startBlock uint64,
+	actionType WalletActionType,
 ) ([]*tecdsa.Signature, error) {
 	mwse.signaturesMutex.Lock()
 	defer mwse.signaturesMutex.Unlock()
@@ -473,3 +482,30 @@ func (mwse *mockWalletSigningExecutor) buildSignaturesKey(
 
 	return sha256.Sum256(buffer.Bytes())
 }
+
+func TestRefreshEpochSessionID(t *testing.T) {
+	var tests = map[string]struct {
+		refreshEpoch      uint32
+		expectedSessionID string
+	}{
+		"never refreshed key shares": {
+			refreshEpoch:      0,
+			expectedSessionID: "100-1",
+		},
+		"refreshed key shares": {
+			refreshEpoch:      2,
+			expectedSessionID: "100-1-epoch-2",
+		},
+	}
+
+	for testName, test := range tests {
+		t.Run(testName, func(t *testing.T) {
+			testutils.AssertStringsEqual(
+				t,
+				"session ID",
+				test.expectedSessionID,
+				refreshEpochSessionID("100-1", test.refreshEpoch),
+			)
+		})
+	}
+}
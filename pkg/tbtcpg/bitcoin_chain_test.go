@@ -13,6 +13,9 @@ type LocalBitcoinChain struct {
 	transactions              map[bitcoin.Hash]*bitcoin.Transaction
 	transactionsConfirmations map[bitcoin.Hash]uint
 	satPerVByteFeeEstimation  map[uint32]int64
+	mempool                   map[[20]byte][]*bitcoin.Transaction
+	txHashes                  map[[20]byte][]bitcoin.Hash
+	latestBlockHeight         uint
 }
 
 func NewLocalBitcoinChain() *LocalBitcoinChain {
@@ -20,6 +23,8 @@ func NewLocalBitcoinChain() *LocalBitcoinChain {
 		transactions:              make(map[bitcoin.Hash]*bitcoin.Transaction),
 		transactionsConfirmations: make(map[bitcoin.Hash]uint),
 		satPerVByteFeeEstimation:  make(map[uint32]int64),
+		mempool:                   make(map[[20]byte][]*bitcoin.Transaction),
+		txHashes:                  make(map[[20]byte][]bitcoin.Hash),
 	}
 }
 
@@ -76,7 +81,17 @@ func (lbc *LocalBitcoinChain) BroadcastTransaction(
 }
 
 func (lbc *LocalBitcoinChain) GetLatestBlockHeight() (uint, error) {
-	panic("unsupported")
+	lbc.mutex.Lock()
+	defer lbc.mutex.Unlock()
+
+	return lbc.latestBlockHeight, nil
+}
+
+func (lbc *LocalBitcoinChain) SetLatestBlockHeight(blockHeight uint) {
+	lbc.mutex.Lock()
+	defer lbc.mutex.Unlock()
+
+	lbc.latestBlockHeight = blockHeight
 }
 
 func (lbc *LocalBitcoinChain) GetBlockHeader(
@@ -102,13 +117,39 @@ func (lbc *LocalBitcoinChain) GetTransactionsForPublicKeyHash(
 func (lbc *LocalBitcoinChain) GetTxHashesForPublicKeyHash(
 	publicKeyHash [20]byte,
 ) ([]bitcoin.Hash, error) {
-	panic("unsupported")
+	lbc.mutex.Lock()
+	defer lbc.mutex.Unlock()
+
+	return lbc.txHashes[publicKeyHash], nil
+}
+
+func (lbc *LocalBitcoinChain) SetTxHashesForPublicKeyHash(
+	publicKeyHash [20]byte,
+	txHashes []bitcoin.Hash,
+) {
+	lbc.mutex.Lock()
+	defer lbc.mutex.Unlock()
+
+	lbc.txHashes[publicKeyHash] = txHashes
 }
 
 func (lbc *LocalBitcoinChain) GetMempoolForPublicKeyHash(
 	publicKeyHash [20]byte,
 ) ([]*bitcoin.Transaction, error) {
-	panic("unsupported")
+	lbc.mutex.Lock()
+	defer lbc.mutex.Unlock()
+
+	return lbc.mempool[publicKeyHash], nil
+}
+
+func (lbc *LocalBitcoinChain) SetMempoolForPublicKeyHash(
+	publicKeyHash [20]byte,
+	transactions []*bitcoin.Transaction,
+) {
+	lbc.mutex.Lock()
+	defer lbc.mutex.Unlock()
+
+	lbc.mempool[publicKeyHash] = transactions
 }
 
 func (lbc *LocalBitcoinChain) GetUtxosForPublicKeyHash(
@@ -1,6 +1,7 @@
 package tbtcpg
 
 import (
+	"crypto/ecdsa"
 	"math/big"
 	"time"
 
@@ -15,14 +16,6 @@ import (
 type Chain interface {
 	tbtc.BridgeChain
 
-	// PastNewWalletRegisteredEvents fetches past new wallet registered events
-	// according to the provided filter or unfiltered if the filter is nil. Returned
-	// events are sorted by the block number in the ascending order, i.e. the
-	// latest event is at the end of the slice.
-	PastNewWalletRegisteredEvents(
-		filter *tbtc.NewWalletRegisteredEventFilter,
-	) ([]*tbtc.NewWalletRegisteredEvent, error)
-
 	// GetWalletParameters gets the current value of parameters relevant to
 	// wallet.
 	GetWalletParameters() (
@@ -43,16 +36,6 @@ type Chain interface {
 	// which is a unique identifier for a deposit on-chain.
 	BuildDepositKey(fundingTxHash bitcoin.Hash, fundingOutputIndex uint32) *big.Int
 
-	// GetDepositParameters gets the current value of parameters relevant
-	// for the depositing process.
-	GetDepositParameters() (
-		dustThreshold uint64,
-		treasuryFeeDivisor uint64,
-		txMaxFee uint64,
-		revealAheadPeriod uint32,
-		err error,
-	)
-
 	// PastRedemptionRequestedEvents fetches past redemption requested events according
 	// to the provided filter or unfiltered if the filter is nil. Returned
 	// events are sorted by the block number in the ascending order, i.e. the
@@ -69,19 +52,6 @@ type Chain interface {
 		redeemerOutputScript bitcoin.Script,
 	) (*big.Int, error)
 
-	// GetRedemptionParameters gets the current value of parameters relevant
-	// for the redemption process.
-	GetRedemptionParameters() (
-		dustThreshold uint64,
-		treasuryFeeDivisor uint64,
-		txMaxFee uint64,
-		txMaxTotalFee uint64,
-		timeout uint32,
-		timeoutSlashingAmount *big.Int,
-		timeoutNotifierRewardMultiplier uint32,
-		err error,
-	)
-
 	// GetRedemptionMaxSize gets the maximum number of redemption requests that
 	// can be a part of a redemption sweep proposal.
 	GetRedemptionMaxSize() (uint16, error)
@@ -188,4 +158,9 @@ type Chain interface {
 	// the deposit reveal before a deposit becomes eligible for
 	// a processing.
 	GetDepositMinAge() (uint32, error)
+
+	// GetWalletPublicKey gets the public key of the wallet with the given
+	// ECDSA wallet ID. Returns an error if the wallet is not registered in
+	// the ECDSA wallet registry.
+	GetWalletPublicKey(EcdsaWalletID [32]byte) (*ecdsa.PublicKey, error)
 }
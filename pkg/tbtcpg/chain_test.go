@@ -82,6 +82,7 @@ type LocalChain struct {
 	redemptionRequestMinAge                  uint32
 	walletParameters                         walletParameters
 	walletChainData                          map[[20]byte]*tbtc.WalletChainData
+	walletPublicKeys                         map[[32]byte]*ecdsa.PublicKey
 	blockCounter                             chain.BlockCounter
 	pastRedemptionRequestedEvents            map[[32]byte][]*tbtc.RedemptionRequestedEvent
 	averageBlockTime                         time.Duration
@@ -98,6 +99,7 @@ type LocalChain struct {
 	operatorIDs                              map[chain.Address]uint32
 	redemptionDelays                         map[[32]byte]time.Duration
 	depositMinAge                            uint32
+	liveWalletsCount                         uint32
 }
 
 func NewLocalChain() *LocalChain {
@@ -108,6 +110,7 @@ func NewLocalChain() *LocalChain {
 		depositSweepProposalValidations:          make(map[[32]byte]bool),
 		pastRedemptionRequestedEvents:            make(map[[32]byte][]*tbtc.RedemptionRequestedEvent),
 		walletChainData:                          make(map[[20]byte]*tbtc.WalletChainData),
+		walletPublicKeys:                         make(map[[32]byte]*ecdsa.PublicKey),
 		pendingRedemptionRequests:                make(map[[32]byte]*tbtc.RedemptionRequest),
 		redemptionProposalValidations:            make(map[[32]byte]bool),
 		heartbeatProposalValidations:             make(map[[16]byte]bool),
@@ -1025,6 +1028,30 @@ func (lc *LocalChain) IsWalletRegistered(EcdsaWalletID [32]byte) (bool, error) {
 	panic("unsupported")
 }
 
+func (lc *LocalChain) GetWalletPublicKey(
+	EcdsaWalletID [32]byte,
+) (*ecdsa.PublicKey, error) {
+	lc.mutex.Lock()
+	defer lc.mutex.Unlock()
+
+	walletPublicKey, ok := lc.walletPublicKeys[EcdsaWalletID]
+	if !ok {
+		return nil, fmt.Errorf("no public key for given wallet ID")
+	}
+
+	return walletPublicKey, nil
+}
+
+func (lc *LocalChain) SetWalletPublicKey(
+	EcdsaWalletID [32]byte,
+	walletPublicKey *ecdsa.PublicKey,
+) {
+	lc.mutex.Lock()
+	defer lc.mutex.Unlock()
+
+	lc.walletPublicKeys[EcdsaWalletID] = walletPublicKey
+}
+
 func (lc *LocalChain) CalculateWalletID(
 	walletPublicKey *ecdsa.PublicKey,
 ) ([32]byte, error) {
@@ -1110,11 +1137,33 @@ func (lc *LocalChain) SetWalletParameters(
 }
 
 func (lc *LocalChain) GetLiveWalletsCount() (uint32, error) {
-	panic("unsupported")
+	lc.mutex.Lock()
+	defer lc.mutex.Unlock()
+
+	return lc.liveWalletsCount, nil
+}
+
+func (lc *LocalChain) SetLiveWalletsCount(liveWalletsCount uint32) {
+	lc.mutex.Lock()
+	defer lc.mutex.Unlock()
+
+	lc.liveWalletsCount = liveWalletsCount
 }
 
 func (lc *LocalChain) ComputeMainUtxoHash(mainUtxo *bitcoin.UnspentTransactionOutput) [32]byte {
-	panic("unsupported")
+	var buffer bytes.Buffer
+
+	buffer.Write(mainUtxo.Outpoint.TransactionHash[:])
+
+	outputIndex := make([]byte, 4)
+	binary.BigEndian.PutUint32(outputIndex, mainUtxo.Outpoint.OutputIndex)
+	buffer.Write(outputIndex)
+
+	value := make([]byte, 8)
+	binary.BigEndian.PutUint64(value, uint64(mainUtxo.Value))
+	buffer.Write(value)
+
+	return sha256.Sum256(buffer.Bytes())
 }
 
 func (lc *LocalChain) ComputeMovingFundsCommitmentHash(targetWallets [][20]byte) [32]byte {
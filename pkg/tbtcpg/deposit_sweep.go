@@ -21,17 +21,20 @@ const depositScriptByteSize = 126
 
 // DepositSweepTask is a task that may produce a deposit sweep proposal.
 type DepositSweepTask struct {
-	chain    Chain
-	btcChain bitcoin.Chain
+	chain            Chain
+	btcChain         bitcoin.Chain
+	feeRateEstimator bitcoin.FeeRateEstimator
 }
 
 func NewDepositSweepTask(
 	chain Chain,
 	btcChain bitcoin.Chain,
+	feeRateEstimator bitcoin.FeeRateEstimator,
 ) *DepositSweepTask {
 	return &DepositSweepTask{
-		chain:    chain,
-		btcChain: btcChain,
+		chain:            chain,
+		btcChain:         btcChain,
+		feeRateEstimator: feeRateEstimator,
 	}
 }
 
@@ -351,7 +354,7 @@ func (dst *DepositSweepTask) ProposeDepositsSweep(
 		}
 
 		estimatedFee, _, err := estimateDepositsSweepFee(
-			dst.btcChain,
+			dst.feeRateEstimator,
 			len(deposits),
 			perDepositMaxFee,
 		)
@@ -425,7 +428,7 @@ func (dst *DepositSweepTask) ProposeDepositsSweep(
 // contract, an error is returned as result.
 func EstimateDepositsSweepFee(
 	chain Chain,
-	btcChain bitcoin.Chain,
+	feeRateEstimator bitcoin.FeeRateEstimator,
 	depositsCount int,
 ) (
 	map[int]struct {
@@ -460,7 +463,7 @@ func EstimateDepositsSweepFee(
 
 	for _, depositsCountKey := range depositsCountKeys {
 		totalFee, satPerVByteFee, err := estimateDepositsSweepFee(
-			btcChain,
+			feeRateEstimator,
 			depositsCountKey,
 			perDepositMaxFee,
 		)
@@ -485,7 +488,7 @@ func EstimateDepositsSweepFee(
 }
 
 func estimateDepositsSweepFee(
-	btcChain bitcoin.Chain,
+	feeRateEstimator bitcoin.FeeRateEstimator,
 	depositsCount int,
 	perDepositMaxFee uint64,
 ) (int64, int64, error) {
@@ -501,7 +504,7 @@ func estimateDepositsSweepFee(
 		return 0, 0, fmt.Errorf("cannot estimate transaction virtual size: [%v]", err)
 	}
 
-	feeEstimator := bitcoin.NewTransactionFeeEstimator(btcChain)
+	feeEstimator := bitcoin.NewTransactionFeeEstimator(feeRateEstimator)
 
 	totalFee, err := feeEstimator.EstimateFee(transactionSize)
 	if err != nil {
@@ -61,7 +61,7 @@ func TestDepositSweepTask_FindDepositsToSweep(t *testing.T) {
 				}
 			}
 
-			task := tbtcpg.NewDepositSweepTask(tbtcChain, btcChain)
+			task := tbtcpg.NewDepositSweepTask(tbtcChain, btcChain, btcChain)
 
 			// Test execution.
 			actualDeposits, err := task.FindDepositsToSweep(
@@ -147,7 +147,7 @@ func TestDepositSweepTask_ProposeDepositsSweep(t *testing.T) {
 
 			btcChain.SetEstimateSatPerVByteFee(1, scenario.EstimateSatPerVByteFee)
 
-			task := tbtcpg.NewDepositSweepTask(tbtcChain, btcChain)
+			task := tbtcpg.NewDepositSweepTask(tbtcChain, btcChain, btcChain)
 
 			// Test execution.
 			proposal, err := task.ProposeDepositsSweep(
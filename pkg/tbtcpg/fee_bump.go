@@ -0,0 +1,257 @@
+package tbtcpg
+
+import (
+	"fmt"
+	"math/big"
+	"sync"
+
+	"go.uber.org/zap"
+
+	"github.com/keep-network/keep-core/pkg/bitcoin"
+	"github.com/keep-network/keep-core/pkg/tbtc"
+)
+
+// FeeBumpTask is a task that may produce a fee bump proposal.
+type FeeBumpTask struct {
+	chain            Chain
+	btcChain         bitcoin.Chain
+	feeRateEstimator bitcoin.FeeRateEstimator
+
+	// minPendingBlocks is the minimum number of Bitcoin blocks the pending
+	// wallet transaction must remain unconfirmed, since it was first seen
+	// by the task, before a fee bump is proposed.
+	minPendingBlocks uint
+
+	pendingTransactionsMutex sync.Mutex
+	// pendingTransactions holds the pending transaction last seen for the
+	// given wallet, keyed by the wallet public key hash.
+	pendingTransactions map[[20]byte]*observedPendingTransaction
+}
+
+// observedPendingTransaction represents a pending wallet transaction along
+// with the Bitcoin block height at which it was first seen.
+type observedPendingTransaction struct {
+	transactionHash bitcoin.Hash
+	firstSeenHeight uint
+}
+
+func NewFeeBumpTask(
+	chain Chain,
+	btcChain bitcoin.Chain,
+	feeRateEstimator bitcoin.FeeRateEstimator,
+	minPendingBlocks uint,
+) *FeeBumpTask {
+	return &FeeBumpTask{
+		chain:               chain,
+		btcChain:            btcChain,
+		feeRateEstimator:    feeRateEstimator,
+		minPendingBlocks:    minPendingBlocks,
+		pendingTransactions: make(map[[20]byte]*observedPendingTransaction),
+	}
+}
+
+func (fbt *FeeBumpTask) Run(request *tbtc.CoordinationProposalRequest) (
+	tbtc.CoordinationProposal,
+	bool,
+	error,
+) {
+	walletPublicKeyHash := request.WalletPublicKeyHash
+
+	taskLogger := logger.With(
+		zap.String("task", fbt.ActionType().String()),
+		zap.String("walletPKH", fmt.Sprintf("0x%x", walletPublicKeyHash)),
+	)
+
+	walletMainUtxo, err := tbtc.DetermineWalletMainUtxo(
+		walletPublicKeyHash,
+		fbt.chain,
+		fbt.btcChain,
+	)
+	if err != nil {
+		return nil, false, fmt.Errorf(
+			"cannot determine wallet's main UTXO: [%w]",
+			err,
+		)
+	}
+
+	transaction, err := tbtc.FindPendingWalletTransaction(
+		walletPublicKeyHash,
+		walletMainUtxo,
+		fbt.chain,
+		fbt.btcChain,
+	)
+	if err != nil {
+		return nil, false, fmt.Errorf(
+			"cannot find pending wallet transaction: [%w]",
+			err,
+		)
+	}
+
+	if transaction == nil {
+		taskLogger.Infof("wallet has no pending transaction")
+		return nil, false, nil
+	}
+
+	taskLogger = taskLogger.With(
+		zap.String(
+			"pendingTxHash",
+			transaction.Hash().Hex(bitcoin.ReversedByteOrder),
+		),
+	)
+
+	pendingBlocks, err := fbt.observePendingTransaction(
+		walletPublicKeyHash,
+		transaction.Hash(),
+	)
+	if err != nil {
+		return nil, false, fmt.Errorf(
+			"cannot determine for how long the transaction is pending: [%w]",
+			err,
+		)
+	}
+
+	if pendingBlocks < fbt.minPendingBlocks {
+		taskLogger.Infof(
+			"pending transaction is unconfirmed for [%v] blocks while "+
+				"at least [%v] blocks are required to bump its fee",
+			pendingBlocks,
+			fbt.minPendingBlocks,
+		)
+		return nil, false, nil
+	}
+
+	proposal, err := fbt.ProposeFeeBump(taskLogger, walletPublicKeyHash, transaction)
+	if err != nil {
+		return nil, false, fmt.Errorf(
+			"cannot prepare fee bump proposal: [%w]",
+			err,
+		)
+	}
+
+	if proposal == nil {
+		return nil, false, nil
+	}
+
+	return proposal, true, nil
+}
+
+// ProposeFeeBump returns a fee bump proposal replacing the given pending
+// wallet transaction if it pays a fee lower than the current estimate.
+// Returns nil if the transaction does not need to be replaced or cannot be
+// replaced due to the Bridge fee limits.
+func (fbt *FeeBumpTask) ProposeFeeBump(
+	taskLogger *zap.SugaredLogger,
+	walletPublicKeyHash [20]byte,
+	transaction *bitcoin.Transaction,
+) (*tbtc.FeeBumpProposal, error) {
+	currentFee, minFee, maxFee, err := tbtc.DetermineFeeBumpLimits(
+		walletPublicKeyHash,
+		transaction,
+		fbt.chain,
+		fbt.btcChain,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("cannot determine fee bump limits: [%w]", err)
+	}
+
+	virtualSize := transaction.VirtualSize()
+
+	estimatedFee, err := bitcoin.NewTransactionFeeEstimator(fbt.feeRateEstimator).
+		EstimateFee(virtualSize)
+	if err != nil {
+		return nil, fmt.Errorf("cannot estimate transaction fee: [%w]", err)
+	}
+
+	if currentFee >= estimatedFee {
+		taskLogger.Infof(
+			"pending transaction fee [%v] is not lower than the "+
+				"estimated fee [%v]; no need to bump it",
+			currentFee,
+			estimatedFee,
+		)
+		return nil, nil
+	}
+
+	fee := estimatedFee
+	if fee < minFee {
+		fee = minFee
+	}
+	if fee > maxFee {
+		fee = maxFee
+	}
+
+	if fee < minFee {
+		taskLogger.Warnf(
+			"pending transaction cannot be replaced as the minimum "+
+				"replacement fee [%v] exceeds the maximum fee [%v]",
+			minFee,
+			maxFee,
+		)
+		return nil, nil
+	}
+
+	taskLogger.Infof(
+		"proposing to replace pending transaction paying fee [%v] "+
+			"with a transaction paying fee [%v]",
+		currentFee,
+		fee,
+	)
+
+	proposal := &tbtc.FeeBumpProposal{
+		TransactionHash: transaction.Hash(),
+		TxFee:           big.NewInt(fee),
+	}
+
+	taskLogger.Infof("validating the fee bump proposal")
+
+	if err := tbtc.ValidateFeeBumpProposal(
+		taskLogger,
+		walletPublicKeyHash,
+		proposal,
+		fbt.chain,
+		fbt.btcChain,
+	); err != nil {
+		return nil, fmt.Errorf("failed to verify fee bump proposal: [%w]", err)
+	}
+
+	return proposal, nil
+}
+
+// observePendingTransaction records the given pending transaction of the
+// given wallet and returns the number of Bitcoin blocks mined since the
+// transaction was first seen by the task. The transaction is considered as
+// first seen now if it differs from the one previously seen for the wallet.
+// Observations are kept in memory so a transaction is considered as first
+// seen again after a restart of the client.
+func (fbt *FeeBumpTask) observePendingTransaction(
+	walletPublicKeyHash [20]byte,
+	transactionHash bitcoin.Hash,
+) (uint, error) {
+	if fbt.minPendingBlocks == 0 {
+		return 0, nil
+	}
+
+	latestBlockHeight, err := fbt.btcChain.GetLatestBlockHeight()
+	if err != nil {
+		return 0, fmt.Errorf("cannot get latest block height: [%w]", err)
+	}
+
+	fbt.pendingTransactionsMutex.Lock()
+	defer fbt.pendingTransactionsMutex.Unlock()
+
+	observed, ok := fbt.pendingTransactions[walletPublicKeyHash]
+	if !ok || observed.transactionHash != transactionHash ||
+		observed.firstSeenHeight > latestBlockHeight {
+		observed = &observedPendingTransaction{
+			transactionHash: transactionHash,
+			firstSeenHeight: latestBlockHeight,
+		}
+		fbt.pendingTransactions[walletPublicKeyHash] = observed
+	}
+
+	return latestBlockHeight - observed.firstSeenHeight, nil
+}
+
+func (fbt *FeeBumpTask) ActionType() tbtc.WalletActionType {
+	return tbtc.ActionFeeBump
+}
@@ -0,0 +1,291 @@
+package tbtcpg_test
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/go-test/deep"
+
+	"github.com/keep-network/keep-core/pkg/bitcoin"
+	"github.com/keep-network/keep-core/pkg/tbtc"
+	"github.com/keep-network/keep-core/pkg/tbtcpg"
+)
+
+func TestFeeBumpTask_Run(t *testing.T) {
+	walletPublicKeyHash := hexToByte20(
+		"92a6ec889a8fa34f731e639edede4c75e184307c",
+	)
+
+	walletOutputScript, err := bitcoin.PayToWitnessPublicKeyHash(
+		walletPublicKeyHash,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	depositTransaction := &bitcoin.Transaction{
+		Version: 1,
+		Inputs: []*bitcoin.TransactionInput{
+			{
+				Outpoint: &bitcoin.TransactionOutpoint{
+					TransactionHash: hexToByte32(
+						"c580e0e352570d90e303d912a506055ceeb0ee06f97dce6988c69941374f5479",
+					),
+					OutputIndex: 0,
+				},
+				Sequence: 0xffffffff,
+			},
+		},
+		Outputs: []*bitcoin.TransactionOutput{
+			{
+				Value:           100000,
+				PublicKeyScript: walletOutputScript,
+			},
+		},
+	}
+
+	// newSweepTransaction returns a wallet's first sweep transaction
+	// spending the deposit and paying the given fee.
+	newSweepTransaction := func(fee int64) *bitcoin.Transaction {
+		return &bitcoin.Transaction{
+			Version: 1,
+			Inputs: []*bitcoin.TransactionInput{
+				{
+					Outpoint: &bitcoin.TransactionOutpoint{
+						TransactionHash: depositTransaction.Hash(),
+						OutputIndex:     0,
+					},
+					Sequence: 0xffffffff,
+				},
+			},
+			Outputs: []*bitcoin.TransactionOutput{
+				{
+					Value:           100000 - fee,
+					PublicKeyScript: walletOutputScript,
+				},
+			},
+		}
+	}
+
+	virtualSize := newSweepTransaction(0).VirtualSize()
+
+	var tests = map[string]struct {
+		pendingTxFee     int64
+		hasPendingTx     bool
+		satPerVByteFee   int64
+		depositTxMaxFee  uint64
+		expectedProposal bool
+		expectedTxFee    int64
+	}{
+		"no pending transaction": {
+			hasPendingTx:     false,
+			satPerVByteFee:   10,
+			depositTxMaxFee:  10000,
+			expectedProposal: false,
+		},
+		"pending transaction fee not lower than the estimate": {
+			pendingTxFee:     10 * virtualSize,
+			hasPendingTx:     true,
+			satPerVByteFee:   10,
+			depositTxMaxFee:  10000,
+			expectedProposal: false,
+		},
+		"replacement fee set to the estimate": {
+			pendingTxFee:     virtualSize,
+			hasPendingTx:     true,
+			satPerVByteFee:   10,
+			depositTxMaxFee:  10000,
+			expectedProposal: true,
+			expectedTxFee:    10 * virtualSize,
+		},
+		"replacement fee set to the minimum replacement fee": {
+			pendingTxFee:     10*virtualSize - 1,
+			hasPendingTx:     true,
+			satPerVByteFee:   10,
+			depositTxMaxFee:  10000,
+			expectedProposal: true,
+			expectedTxFee:    11*virtualSize - 1,
+		},
+		"replacement fee capped at the maximum fee": {
+			pendingTxFee:     virtualSize,
+			hasPendingTx:     true,
+			satPerVByteFee:   1000,
+			depositTxMaxFee:  10000,
+			expectedProposal: true,
+			expectedTxFee:    10000,
+		},
+		"minimum replacement fee exceeds the maximum fee": {
+			pendingTxFee:     virtualSize,
+			hasPendingTx:     true,
+			satPerVByteFee:   10,
+			depositTxMaxFee:  uint64(2*virtualSize - 1),
+			expectedProposal: false,
+		},
+	}
+
+	for testName, test := range tests {
+		t.Run(testName, func(t *testing.T) {
+			tbtcChain := tbtcpg.NewLocalChain()
+			btcChain := tbtcpg.NewLocalBitcoinChain()
+
+			tbtcChain.SetWallet(walletPublicKeyHash, &tbtc.WalletChainData{
+				State: tbtc.StateLive,
+			})
+			tbtcChain.SetDepositRequest(
+				depositTransaction.Hash(),
+				0,
+				&tbtc.DepositChainRequest{},
+			)
+			tbtcChain.SetDepositParameters(0, 0, test.depositTxMaxFee, 0)
+
+			btcChain.SetTransaction(depositTransaction.Hash(), depositTransaction)
+			btcChain.SetTransactionConfirmations(depositTransaction.Hash(), 6)
+			btcChain.SetEstimateSatPerVByteFee(1, test.satPerVByteFee)
+
+			pendingTx := newSweepTransaction(test.pendingTxFee)
+			if test.hasPendingTx {
+				btcChain.SetMempoolForPublicKeyHash(
+					walletPublicKeyHash,
+					[]*bitcoin.Transaction{pendingTx},
+				)
+				btcChain.SetTransactionConfirmations(pendingTx.Hash(), 0)
+			}
+
+			task := tbtcpg.NewFeeBumpTask(tbtcChain, btcChain, btcChain, 0)
+
+			proposal, ok, err := task.Run(&tbtc.CoordinationProposalRequest{
+				WalletPublicKeyHash: walletPublicKeyHash,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if test.expectedProposal != ok {
+				t.Fatalf(
+					"unexpected proposal presence\nexpected: %v\nactual:   %v\n",
+					test.expectedProposal,
+					ok,
+				)
+			}
+
+			if !test.expectedProposal {
+				return
+			}
+
+			expectedProposal := &tbtc.FeeBumpProposal{
+				TransactionHash: pendingTx.Hash(),
+				TxFee:           big.NewInt(test.expectedTxFee),
+			}
+
+			if diff := deep.Equal(proposal, expectedProposal); diff != nil {
+				t.Errorf("unexpected proposal: %v", diff)
+			}
+		})
+	}
+}
+
+func TestFeeBumpTask_Run_MinPendingBlocks(t *testing.T) {
+	walletPublicKeyHash := hexToByte20(
+		"92a6ec889a8fa34f731e639edede4c75e184307c",
+	)
+
+	walletOutputScript, err := bitcoin.PayToWitnessPublicKeyHash(
+		walletPublicKeyHash,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	depositTransaction := &bitcoin.Transaction{
+		Version: 1,
+		Inputs: []*bitcoin.TransactionInput{
+			{
+				Outpoint: &bitcoin.TransactionOutpoint{
+					TransactionHash: hexToByte32(
+						"c580e0e352570d90e303d912a506055ceeb0ee06f97dce6988c69941374f5479",
+					),
+					OutputIndex: 0,
+				},
+				Sequence: 0xffffffff,
+			},
+		},
+		Outputs: []*bitcoin.TransactionOutput{
+			{
+				Value:           100000,
+				PublicKeyScript: walletOutputScript,
+			},
+		},
+	}
+
+	pendingTx := &bitcoin.Transaction{
+		Version: 1,
+		Inputs: []*bitcoin.TransactionInput{
+			{
+				Outpoint: &bitcoin.TransactionOutpoint{
+					TransactionHash: depositTransaction.Hash(),
+					OutputIndex:     0,
+				},
+				Sequence: 0xffffffff,
+			},
+		},
+		Outputs: []*bitcoin.TransactionOutput{
+			{
+				Value:           99900,
+				PublicKeyScript: walletOutputScript,
+			},
+		},
+	}
+
+	tbtcChain := tbtcpg.NewLocalChain()
+	btcChain := tbtcpg.NewLocalBitcoinChain()
+
+	tbtcChain.SetWallet(walletPublicKeyHash, &tbtc.WalletChainData{
+		State: tbtc.StateLive,
+	})
+	tbtcChain.SetDepositRequest(
+		depositTransaction.Hash(),
+		0,
+		&tbtc.DepositChainRequest{},
+	)
+	tbtcChain.SetDepositParameters(0, 0, 10000, 0)
+
+	btcChain.SetTransaction(depositTransaction.Hash(), depositTransaction)
+	btcChain.SetTransactionConfirmations(depositTransaction.Hash(), 6)
+	btcChain.SetEstimateSatPerVByteFee(1, 10)
+	btcChain.SetMempoolForPublicKeyHash(
+		walletPublicKeyHash,
+		[]*bitcoin.Transaction{pendingTx},
+	)
+	btcChain.SetTransactionConfirmations(pendingTx.Hash(), 0)
+
+	task := tbtcpg.NewFeeBumpTask(tbtcChain, btcChain, btcChain, 3)
+
+	request := &tbtc.CoordinationProposalRequest{
+		WalletPublicKeyHash: walletPublicKeyHash,
+	}
+
+	for _, blockHeight := range []uint{100, 102} {
+		btcChain.SetLatestBlockHeight(blockHeight)
+
+		_, ok, err := task.Run(request)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if ok {
+			t.Fatalf(
+				"unexpected proposal for transaction pending for [%v] blocks",
+				blockHeight-100,
+			)
+		}
+	}
+
+	btcChain.SetLatestBlockHeight(103)
+
+	_, ok, err := task.Run(request)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !ok {
+		t.Fatal("expected proposal for transaction pending for [3] blocks")
+	}
+}
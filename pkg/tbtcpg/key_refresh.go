@@ -0,0 +1,97 @@
+package tbtcpg
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+
+	"github.com/keep-network/keep-core/pkg/tbtc"
+	"go.uber.org/zap"
+)
+
+// KeyRefreshTask is a task that may produce a key refresh proposal.
+type KeyRefreshTask struct {
+	chain   Chain
+	enabled bool
+}
+
+func NewKeyRefreshTask(chain Chain, enabled bool) *KeyRefreshTask {
+	return &KeyRefreshTask{
+		chain:   chain,
+		enabled: enabled,
+	}
+}
+
+func (krt *KeyRefreshTask) Run(request *tbtc.CoordinationProposalRequest) (
+	tbtc.CoordinationProposal,
+	bool,
+	error,
+) {
+	walletPublicKeyHash := request.WalletPublicKeyHash
+
+	taskLogger := logger.With(
+		zap.String("task", krt.ActionType().String()),
+		zap.String("walletPKH", fmt.Sprintf("0x%x", walletPublicKeyHash)),
+	)
+
+	if !krt.enabled {
+		taskLogger.Infof("key refresh disabled")
+		return nil, false, nil
+	}
+
+	walletChainData, err := krt.chain.GetWallet(walletPublicKeyHash)
+	if err != nil {
+		return nil, false, fmt.Errorf(
+			"cannot get wallet's chain data: [%w]",
+			err,
+		)
+	}
+
+	// Wallets that are not live anymore are on their way to be closed so
+	// there is no point in refreshing their key shares.
+	if walletChainData.State != tbtc.StateLive {
+		taskLogger.Infof("wallet not in Live state")
+		return nil, false, nil
+	}
+
+	blockCounter, err := krt.chain.BlockCounter()
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to get block counter: [%v]", err)
+	}
+
+	block, err := blockCounter.CurrentBlock()
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to get current block: [%v]", err)
+	}
+	blockBytes := make([]byte, 8)
+	binary.BigEndian.PutUint64(blockBytes, block)
+
+	// The nonce only needs to make the given key refresh session unique,
+	// so it is derived the same way as the heartbeat message.
+	hash := sha256.Sum256(append(walletPublicKeyHash[:], blockBytes...))
+
+	var nonce [16]byte
+	copy(nonce[:], hash[:16])
+
+	proposal := &tbtc.KeyRefreshProposal{
+		Nonce: nonce,
+	}
+
+	if err := tbtc.ValidateKeyRefreshProposal(
+		walletPublicKeyHash,
+		proposal,
+		krt.enabled,
+		krt.chain,
+	); err != nil {
+		return nil, false, fmt.Errorf(
+			"failed to verify key refresh proposal: [%v]",
+			err,
+		)
+	}
+
+	return proposal, true, nil
+}
+
+func (krt *KeyRefreshTask) ActionType() tbtc.WalletActionType {
+	return tbtc.ActionKeyRefresh
+}
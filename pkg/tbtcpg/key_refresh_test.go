@@ -0,0 +1,106 @@
+package tbtcpg
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/keep-network/keep-core/internal/testutils"
+	"github.com/keep-network/keep-core/pkg/tbtc"
+)
+
+func TestKeyRefreshTask_Run(t *testing.T) {
+	walletPublicKeyHash := [20]byte{0x01, 0x02}
+
+	tests := map[string]struct {
+		walletState      tbtc.WalletState
+		walletKnown      bool
+		disabled         bool
+		expectedProposal tbtc.CoordinationProposal
+		expectedOk       bool
+		expectedErr      error
+	}{
+		"live wallet": {
+			walletState: tbtc.StateLive,
+			walletKnown: true,
+			expectedProposal: &tbtc.KeyRefreshProposal{
+				Nonce: [16]byte{
+					0xe0, 0xd7, 0x5a, 0xec, 0xd2, 0x9e, 0x5b, 0xca,
+					0xc7, 0x3a, 0xd4, 0xa7, 0xf3, 0x7e, 0x08, 0xcc,
+				},
+			},
+			expectedOk:  true,
+			expectedErr: nil,
+		},
+		"key refresh disabled": {
+			walletState:      tbtc.StateLive,
+			walletKnown:      true,
+			disabled:         true,
+			expectedProposal: nil,
+			expectedOk:       false,
+			expectedErr:      nil,
+		},
+		"moving funds wallet": {
+			walletState:      tbtc.StateMovingFunds,
+			walletKnown:      true,
+			expectedProposal: nil,
+			expectedOk:       false,
+			expectedErr:      nil,
+		},
+		"unknown wallet": {
+			walletKnown:      false,
+			expectedProposal: nil,
+			expectedOk:       false,
+			expectedErr: fmt.Errorf(
+				"cannot get wallet's chain data: [%w]",
+				fmt.Errorf("wallet chain data not found"),
+			),
+		},
+	}
+
+	for testName, test := range tests {
+		t.Run(testName, func(t *testing.T) {
+			tbtcChain := NewLocalChain()
+			blockCounter := NewMockBlockCounter()
+
+			blockCounter.SetCurrentBlock(900)
+			tbtcChain.SetBlockCounter(blockCounter)
+
+			if test.walletKnown {
+				tbtcChain.SetWallet(
+					walletPublicKeyHash,
+					&tbtc.WalletChainData{
+						State: test.walletState,
+					},
+				)
+			}
+
+			task := NewKeyRefreshTask(tbtcChain, !test.disabled)
+
+			proposal, ok, err := task.Run(
+				&tbtc.CoordinationProposalRequest{
+					// Set only relevant fields.
+					WalletPublicKeyHash: walletPublicKeyHash,
+				},
+			)
+
+			if !reflect.DeepEqual(test.expectedErr, err) {
+				t.Errorf(
+					"unexpected error\nexpected: [%v]\nactual:   [%v]",
+					test.expectedErr,
+					err,
+				)
+			}
+
+			testutils.AssertBoolsEqual(t, "boolean flag", test.expectedOk, ok)
+
+			if !reflect.DeepEqual(test.expectedProposal, proposal) {
+				t.Errorf(
+					"unexpected proposal\nexpected: [%v]\nactual:   [%v]",
+					test.expectedProposal,
+					proposal,
+				)
+			}
+		})
+	}
+}
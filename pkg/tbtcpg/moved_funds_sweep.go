@@ -30,17 +30,20 @@ const MovedFundsSweepLookBackBlocks = uint64(216000)
 
 // MovedFundsSweepTask is a task that may produce a moved funds sweep proposal.
 type MovedFundsSweepTask struct {
-	chain    Chain
-	btcChain bitcoin.Chain
+	chain            Chain
+	btcChain         bitcoin.Chain
+	feeRateEstimator bitcoin.FeeRateEstimator
 }
 
 func NewMovedFundsSweepTask(
 	chain Chain,
 	btcChain bitcoin.Chain,
+	feeRateEstimator bitcoin.FeeRateEstimator,
 ) *MovedFundsSweepTask {
 	return &MovedFundsSweepTask{
-		chain:    chain,
-		btcChain: btcChain,
+		chain:            chain,
+		btcChain:         btcChain,
+		feeRateEstimator: feeRateEstimator,
 	}
 }
 
@@ -333,7 +336,7 @@ func (mfst *MovedFundsSweepTask) ProposeMovedFundsSweep(
 		}
 
 		estimatedFee, err := EstimateMovedFundsSweepFee(
-			mfst.btcChain,
+			mfst.feeRateEstimator,
 			hasMainUtxo,
 			sweepTxMaxTotalFee,
 		)
@@ -376,7 +379,7 @@ func (mfst *MovedFundsSweepTask) ProposeMovedFundsSweep(
 // that merges the received main UTXO from the source wallets with the current
 // wallet's main UTXO.
 func EstimateMovedFundsSweepFee(
-	btcChain bitcoin.Chain,
+	feeRateEstimator bitcoin.FeeRateEstimator,
 	hasMainUtxo bool,
 	sweepTxMaxTotalFee uint64,
 ) (int64, error) {
@@ -400,7 +403,7 @@ func EstimateMovedFundsSweepFee(
 		)
 	}
 
-	feeEstimator := bitcoin.NewTransactionFeeEstimator(btcChain)
+	feeEstimator := bitcoin.NewTransactionFeeEstimator(feeRateEstimator)
 
 	totalFee, err := feeEstimator.EstimateFee(transactionSize)
 	if err != nil {
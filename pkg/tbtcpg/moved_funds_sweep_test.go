@@ -192,6 +192,7 @@ func TestMovedFundsSweepAction_FindMovingFundsTxData_Successful(t *testing.T) {
 	task := tbtcpg.NewMovedFundsSweepTask(
 		tbtcChain,
 		nil,
+		nil,
 	)
 
 	movingFundsTxHash, movingFundsOutputIdx, err := task.FindMovingFundsTxData(
@@ -322,6 +323,7 @@ func TestMovedFundsSweepAction_FindMovingFundsTxData_Failure(t *testing.T) {
 	task := tbtcpg.NewMovedFundsSweepTask(
 		tbtcChain,
 		nil,
+		nil,
 	)
 
 	_, _, err = task.FindMovingFundsTxData(
@@ -400,7 +402,7 @@ func TestMovedFundsSweepAction_ProposeMovedFundsSweep(t *testing.T) {
 				t.Fatal(err)
 			}
 
-			task := tbtcpg.NewMovedFundsSweepTask(tbtcChain, btcChain)
+			task := tbtcpg.NewMovedFundsSweepTask(tbtcChain, btcChain, btcChain)
 
 			proposal, err := task.ProposeMovedFundsSweep(
 				&testutils.MockLogger{},
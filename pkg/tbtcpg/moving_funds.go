@@ -53,17 +53,20 @@ const MovingFundsCommitmentLookBackBlocks = uint64(216000)
 
 // MovingFundsTask is a task that may produce a moving funds proposal.
 type MovingFundsTask struct {
-	chain    Chain
-	btcChain bitcoin.Chain
+	chain            Chain
+	btcChain         bitcoin.Chain
+	feeRateEstimator bitcoin.FeeRateEstimator
 }
 
 func NewMovingFundsTask(
 	chain Chain,
 	btcChain bitcoin.Chain,
+	feeRateEstimator bitcoin.FeeRateEstimator,
 ) *MovingFundsTask {
 	return &MovingFundsTask{
-		chain:    chain,
-		btcChain: btcChain,
+		chain:            chain,
+		btcChain:         btcChain,
+		feeRateEstimator: feeRateEstimator,
 	}
 }
 
@@ -197,6 +200,35 @@ func (mft *MovingFundsTask) Run(request *tbtc.CoordinationProposalRequest) (
 			)
 		}
 
+		// An observer node must not submit chain transactions. Without
+		// the commitment the moving funds proposal would be rejected
+		// anyway so there is nothing to propose.
+		if request.Observer != nil {
+			targetWalletsHex := make([]string, len(targetWallets))
+			for i, targetWallet := range targetWallets {
+				targetWalletsHex[i] = fmt.Sprintf("0x%x", targetWallet)
+			}
+
+			request.Observer.Record(
+				tbtc.ObserverRecordMovingFundsCommitmentSubmission,
+				map[string]interface{}{
+					"wallet": fmt.Sprintf("0x%x", walletPublicKeyHash),
+					"mainUtxo": fmt.Sprintf(
+						"%s:%d",
+						walletMainUtxo.Outpoint.TransactionHash.Hex(bitcoin.ReversedByteOrder),
+						walletMainUtxo.Outpoint.OutputIndex,
+					),
+					"walletMembersIDs":  walletMemberIDs,
+					"walletMemberIndex": walletMemberIndex,
+					"targetWallets":     targetWalletsHex,
+				},
+			)
+			taskLogger.Infof(
+				"observer mode; not submitting moving funds commitment",
+			)
+			return nil, false, nil
+		}
+
 		err = mft.SubmitMovingFundsCommitment(
 			taskLogger,
 			walletPublicKeyHash,
@@ -583,7 +615,7 @@ func (mft *MovingFundsTask) ProposeMovingFunds(
 		}
 
 		estimatedFee, err := EstimateMovingFundsFee(
-			mft.btcChain,
+			mft.feeRateEstimator,
 			len(targetWallets),
 			txMaxTotalFee,
 		)
@@ -629,7 +661,7 @@ func (mft *MovingFundsTask) ActionType() tbtc.WalletActionType {
 // EstimateMovingFundsFee estimates fee for the moving funds transaction that
 // moves funds from the source wallet to target wallets.
 func EstimateMovingFundsFee(
-	btcChain bitcoin.Chain,
+	feeRateEstimator bitcoin.FeeRateEstimator,
 	targetWalletsCount int,
 	txMaxTotalFee uint64,
 ) (int64, error) {
@@ -645,7 +677,7 @@ func EstimateMovingFundsFee(
 		)
 	}
 
-	feeEstimator := bitcoin.NewTransactionFeeEstimator(btcChain)
+	feeEstimator := bitcoin.NewTransactionFeeEstimator(feeRateEstimator)
 
 	totalFee, err := feeEstimator.EstimateFee(transactionSize)
 	if err != nil {
@@ -150,7 +150,7 @@ func TestMovingFundsAction_FindTargetWallets_CommitmentNotSubmittedYet(t *testin
 				)
 			}
 
-			task := tbtcpg.NewMovingFundsTask(tbtcChain, nil)
+			task := tbtcpg.NewMovingFundsTask(tbtcChain, nil, nil)
 
 			// Always simulate the moving funds commitment has not been
 			// submitted yet.
@@ -287,7 +287,7 @@ func TestMovingFundsAction_FindTargetWallets_CommitmentAlreadySubmitted(t *testi
 				t.Fatal(err)
 			}
 
-			task := tbtcpg.NewMovingFundsTask(tbtcChain, nil)
+			task := tbtcpg.NewMovingFundsTask(tbtcChain, nil, nil)
 
 			// Live wallets count and wallet's balance don't matter, as we are
 			// retrieving target wallets from an already submitted commitment.
@@ -366,7 +366,7 @@ func TestMovingFundsAction_GetWalletMembersInfo(t *testing.T) {
 		t.Run(testName, func(t *testing.T) {
 			tbtcChain := tbtcpg.NewLocalChain()
 
-			task := tbtcpg.NewMovingFundsTask(tbtcChain, nil)
+			task := tbtcpg.NewMovingFundsTask(tbtcChain, nil, nil)
 
 			walletOperators := []chain.Address{}
 			for _, operatorInfo := range test.walletOperators {
@@ -469,7 +469,7 @@ func TestMovingFundsAction_SubmitMovingFundsCommitment(t *testing.T) {
 			blockCounter.SetCurrentBlock(currentBlock)
 			tbtcChain.SetBlockCounter(blockCounter)
 
-			task := tbtcpg.NewMovingFundsTask(tbtcChain, nil)
+			task := tbtcpg.NewMovingFundsTask(tbtcChain, nil, nil)
 
 			err := task.SubmitMovingFundsCommitment(
 				&testutils.MockLogger{},
@@ -531,6 +531,204 @@ func TestMovingFundsAction_SubmitMovingFundsCommitment(t *testing.T) {
 	}
 }
 
+func TestMovingFundsTask_Run_ObserverMode(t *testing.T) {
+	walletPublicKeyHash := hexToByte20(
+		"ffb3f7538bfa98a511495dd96027cfbd57baf2fa",
+	)
+
+	liveWallets := [][20]byte{
+		hexToByte20("92a6ec889a8fa34f731e639edede4c75e184307c"),
+		hexToByte20("fdfa28e238734271f5e0d4f53d3843ae6cc09b24"),
+	}
+
+	walletOperators := []operatorInfo{
+		{"5df232b0348928793658dd05dfc6b05a59d11ae8", 3},
+		{"dcc895d32b74b34cef2baa6546884fcda65da1e9", 1},
+	}
+
+	currentBlock := uint64(300000)
+
+	tbtcChain := tbtcpg.NewLocalChain()
+	btcChain := tbtcpg.NewLocalBitcoinChain()
+
+	blockCounter := tbtcpg.NewMockBlockCounter()
+	blockCounter.SetCurrentBlock(currentBlock)
+	tbtcChain.SetBlockCounter(blockCounter)
+
+	tbtcChain.SetMovingFundsParameters(
+		0,
+		0,
+		0,
+		604800,
+		nil,
+		0,
+		0,
+		0,
+		0,
+		nil,
+		0,
+	)
+	tbtcChain.SetWalletParameters(0, 0, 0, 0, 0, 300000, 0)
+
+	// Simulate there are no moving funds commitments our wallet is the
+	// target of so the default safety margin applies.
+	err := tbtcChain.AddPastMovingFundsCommitmentSubmittedEvent(
+		&tbtc.MovingFundsCommitmentSubmittedEventFilter{
+			StartBlock: currentBlock - tbtc.MovingFundsCommitmentLookBackBlocks,
+		},
+		&tbtc.MovingFundsCommitmentSubmittedEvent{
+			WalletPublicKeyHash: liveWallets[0],
+			TargetWallets:       [][20]byte{liveWallets[1]},
+		},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Simulate the only deposit of the wallet has been already swept.
+	depositTxHash := hexToByte32(
+		"5c5a3c0d95c30a8d9a4b4f7e56c2e5b5f1b3e0c4e25ee7a3e5f0d8a6b2e7c4d1",
+	)
+	err = tbtcChain.AddPastDepositRevealedEvent(
+		&tbtc.DepositRevealedEventFilter{
+			WalletPublicKeyHash: [][20]byte{walletPublicKeyHash},
+		},
+		&tbtc.DepositRevealedEvent{
+			WalletPublicKeyHash: walletPublicKeyHash,
+			FundingTxHash:       depositTxHash,
+			FundingOutputIndex:  0,
+		},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tbtcChain.SetDepositRequest(
+		depositTxHash,
+		0,
+		&tbtc.DepositChainRequest{
+			RevealedAt: time.Now().Add(-48 * time.Hour),
+			SweptAt:    time.Now().Add(-24 * time.Hour),
+		},
+	)
+
+	walletP2WPKH, err := bitcoin.PayToWitnessPublicKeyHash(walletPublicKeyHash)
+	if err != nil {
+		t.Fatal(err)
+	}
+	mainUtxoTransaction := &bitcoin.Transaction{
+		Version: 1,
+		Inputs: []*bitcoin.TransactionInput{
+			{
+				Outpoint: &bitcoin.TransactionOutpoint{
+					TransactionHash: depositTxHash,
+					OutputIndex:     0,
+				},
+				Sequence: 0xffffffff,
+			},
+		},
+		Outputs: []*bitcoin.TransactionOutput{
+			{
+				Value:           2000000,
+				PublicKeyScript: walletP2WPKH,
+			},
+		},
+	}
+	btcChain.SetTransaction(mainUtxoTransaction.Hash(), mainUtxoTransaction)
+	btcChain.SetTxHashesForPublicKeyHash(
+		walletPublicKeyHash,
+		[]bitcoin.Hash{mainUtxoTransaction.Hash()},
+	)
+
+	walletMainUtxo := &bitcoin.UnspentTransactionOutput{
+		Outpoint: &bitcoin.TransactionOutpoint{
+			TransactionHash: mainUtxoTransaction.Hash(),
+			OutputIndex:     0,
+		},
+		Value: 2000000,
+	}
+
+	tbtcChain.SetWallet(
+		walletPublicKeyHash,
+		&tbtc.WalletChainData{
+			State:                  tbtc.StateMovingFunds,
+			MainUtxoHash:           tbtcChain.ComputeMainUtxoHash(walletMainUtxo),
+			MovingFundsRequestedAt: time.Now().Add(-7 * 24 * time.Hour),
+		},
+	)
+
+	for _, liveWallet := range liveWallets {
+		tbtcChain.AddPastNewWalletRegisteredEvent(
+			nil,
+			&tbtc.NewWalletRegisteredEvent{
+				WalletPublicKeyHash: liveWallet,
+			},
+		)
+		tbtcChain.SetWallet(
+			liveWallet,
+			&tbtc.WalletChainData{State: tbtc.StateLive},
+		)
+	}
+	tbtcChain.SetLiveWalletsCount(uint32(len(liveWallets)))
+
+	walletOperatorsAddresses := []chain.Address{}
+	for _, operatorInfo := range walletOperators {
+		err := tbtcChain.SetOperatorID(
+			operatorInfo.Address,
+			operatorInfo.OperatorID,
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+		walletOperatorsAddresses = append(
+			walletOperatorsAddresses,
+			operatorInfo.Address,
+		)
+	}
+
+	observer := &mockObserverRecorder{}
+
+	task := tbtcpg.NewMovingFundsTask(tbtcChain, btcChain, nil)
+
+	proposal, ok, err := task.Run(
+		&tbtc.CoordinationProposalRequest{
+			WalletPublicKeyHash: walletPublicKeyHash,
+			WalletOperators:     walletOperatorsAddresses,
+			ExecutingOperator:   walletOperators[1].Address,
+			ActionsChecklist:    []tbtc.WalletActionType{tbtc.ActionMovingFunds},
+			Observer:            observer,
+		},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if proposal != nil {
+		t.Errorf("unexpected proposal: [%v]", proposal)
+	}
+	testutils.AssertBoolsEqual(t, "proposal flag", false, ok)
+
+	testutils.AssertIntsEqual(
+		t,
+		"commitment submission count",
+		0,
+		len(tbtcChain.GetMovingFundsSubmissions()),
+	)
+
+	if len(observer.kinds) != 1 {
+		t.Fatalf(
+			"unexpected observer records count\nexpected: [%v]\nactual:   [%v]",
+			1,
+			len(observer.kinds),
+		)
+	}
+	testutils.AssertStringsEqual(
+		t,
+		"observer record kind",
+		tbtc.ObserverRecordMovingFundsCommitmentSubmission,
+		observer.kinds[0],
+	)
+}
+
 func TestMovingFundsAction_ProposeMovingFunds(t *testing.T) {
 	walletPublicKeyHash := hexToByte20(
 		"ffb3f7538bfa98a511495dd96027cfbd57baf2fa",
@@ -627,7 +825,7 @@ func TestMovingFundsAction_ProposeMovingFunds(t *testing.T) {
 				t.Fatal(err)
 			}
 
-			task := tbtcpg.NewMovingFundsTask(tbtcChain, btcChain)
+			task := tbtcpg.NewMovingFundsTask(tbtcChain, btcChain, btcChain)
 
 			proposal, err := task.ProposeMovingFunds(
 				&testutils.MockLogger{},
@@ -704,6 +902,17 @@ type operatorInfo struct {
 	chain.OperatorID
 }
 
+type mockObserverRecorder struct {
+	kinds []string
+}
+
+func (mor *mockObserverRecorder) Record(
+	kind string,
+	details map[string]interface{},
+) {
+	mor.kinds = append(mor.kinds, kind)
+}
+
 func hexToByte20(hexStr string) [20]byte {
 	if len(hexStr) != 40 {
 		panic("hex string length incorrect")
@@ -16,17 +16,20 @@ import (
 
 // RedemptionTask is a task that may produce a redemption proposal.
 type RedemptionTask struct {
-	chain    Chain
-	btcChain bitcoin.Chain
+	chain            Chain
+	btcChain         bitcoin.Chain
+	feeRateEstimator bitcoin.FeeRateEstimator
 }
 
 func NewRedemptionTask(
 	chain Chain,
 	btcChain bitcoin.Chain,
+	feeRateEstimator bitcoin.FeeRateEstimator,
 ) *RedemptionTask {
 	return &RedemptionTask{
-		chain:    chain,
-		btcChain: btcChain,
+		chain:            chain,
+		btcChain:         btcChain,
+		feeRateEstimator: feeRateEstimator,
 	}
 }
 
@@ -201,7 +204,7 @@ func (rt *RedemptionTask) ProposeRedemption(
 		taskLogger.Infof("estimating redemption transaction fee")
 
 		estimatedFee, err := EstimateRedemptionFee(
-			rt.btcChain,
+			rt.feeRateEstimator,
 			redeemersOutputScripts,
 		)
 		if err != nil {
@@ -447,7 +450,7 @@ redemptionRequestedLoop:
 // EstimateRedemptionFee estimates fee for the redemption transaction that pays
 // the provided redeemers output scripts.
 func EstimateRedemptionFee(
-	btcChain bitcoin.Chain,
+	feeRateEstimator bitcoin.FeeRateEstimator,
 	redeemersOutputScripts []bitcoin.Script,
 ) (int64, error) {
 	sizeEstimator := bitcoin.NewTransactionSizeEstimator().
@@ -466,6 +469,8 @@ func EstimateRedemptionFee(
 			sizeEstimator.AddScriptHashOutputs(1, false)
 		case bitcoin.P2WSHScript:
 			sizeEstimator.AddScriptHashOutputs(1, true)
+		case bitcoin.P2TRScript:
+			sizeEstimator.AddTaprootOutputs(1)
 		default:
 			return 0, fmt.Errorf("non-standard redeemer output script type")
 		}
@@ -476,7 +481,7 @@ func EstimateRedemptionFee(
 		return 0, fmt.Errorf("cannot estimate transaction virtual size: [%v]", err)
 	}
 
-	feeEstimator := bitcoin.NewTransactionFeeEstimator(btcChain)
+	feeEstimator := bitcoin.NewTransactionFeeEstimator(feeRateEstimator)
 
 	totalFee, err := feeEstimator.EstimateFee(transactionSize)
 	if err != nil {
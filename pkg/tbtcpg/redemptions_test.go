@@ -32,6 +32,7 @@ func TestEstimateRedemptionFee(t *testing.T) {
 		fromHex("0014e6f9d74726b19b75f16fe1e9feaec048aa4fa1d0"),                         // P2WPKH
 		fromHex("a914011beb6fb8499e075a57027fb0a58384f2d3f78487"),                       // P2SH
 		fromHex("0020ef0b4d985752aa5ef6243e4c6f6bebc2a007e7d671ef27d4b1d0db8dcc93bc1c"), // P2WSH
+		fromHex("5120a60869f0dbcf1dc659c9cecbaf8050135ea9e8cdc487053f1dc6880949dc684c"), // P2TR
 	}
 
 	actualFee, err := tbtcpg.EstimateRedemptionFee(btcChain, redeemersOutputScripts)
@@ -39,7 +40,7 @@ func TestEstimateRedemptionFee(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	expectedFee := 4000 // transactionVirtualSize * satPerVByteFee = 250 * 16 = 4000
+	expectedFee := 4688 // transactionVirtualSize * satPerVByteFee = 293 * 16 = 4688
 	testutils.AssertIntsEqual(t, "fee", expectedFee, int(actualFee))
 }
 
@@ -115,7 +116,7 @@ func TestRedemptionAction_FindPendingRedemptions(t *testing.T) {
 				)
 			}
 
-			task := tbtcpg.NewRedemptionTask(tbtcChain, nil)
+			task := tbtcpg.NewRedemptionTask(tbtcChain, nil, nil)
 
 			redeemersOutputScripts, err := task.FindPendingRedemptions(
 				&testutils.MockLogger{},
@@ -198,7 +199,7 @@ func TestRedemptionAction_ProposeRedemption(t *testing.T) {
 				t.Fatal(err)
 			}
 
-			task := tbtcpg.NewRedemptionTask(tbtcChain, btcChain)
+			task := tbtcpg.NewRedemptionTask(tbtcChain, btcChain, btcChain)
 
 			proposal, err := task.ProposeRedemption(
 				&testutils.MockLogger{},
@@ -0,0 +1,357 @@
+package tbtcpg
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/keep-network/keep-core/internal/hexutils"
+	"github.com/keep-network/keep-core/pkg/bitcoin"
+	"github.com/keep-network/keep-core/pkg/chain"
+	"github.com/keep-network/keep-core/pkg/tbtc"
+)
+
+// ReservesReport is a machine-readable report reconciling the Bridge
+// accounting of wallets with the actual Bitcoin holdings of those wallets.
+// All values are expressed in satoshis.
+type ReservesReport struct {
+	// GeneratedAt is the UNIX timestamp of the report generation.
+	GeneratedAt int64 `json:"generatedAt"`
+	// Wallets holds reports of the individual wallets.
+	Wallets []*WalletReserves `json:"wallets"`
+	// TotalExpected is the sum of values expected by the Bridge across all
+	// wallets.
+	TotalExpected int64 `json:"totalExpected"`
+	// TotalObserved is the sum of values observed on the Bitcoin chain across
+	// all wallets.
+	TotalObserved int64 `json:"totalObserved"`
+	// TotalUnsweptDeposits is the sum of values of all revealed deposits
+	// not swept yet.
+	TotalUnsweptDeposits int64 `json:"totalUnsweptDeposits"`
+}
+
+// WalletReserves holds the reserves of a single wallet.
+type WalletReserves struct {
+	WalletPublicKeyHash string `json:"walletPublicKeyHash"`
+	State               string `json:"state"`
+	// MainUtxo is the wallet's main UTXO registered in the Bridge. It is nil
+	// if the wallet has no main UTXO or the main UTXO could not be found on
+	// the Bitcoin chain.
+	MainUtxo *ReservesUtxo `json:"mainUtxo"`
+	// MainUtxoUnspent determines whether the wallet's main UTXO is still
+	// among the confirmed UTXOs of the wallet.
+	MainUtxoUnspent bool `json:"mainUtxoUnspent"`
+	// Expected is the wallet's balance according to the Bridge, i.e. the value
+	// of the wallet's main UTXO.
+	Expected int64 `json:"expected"`
+	// Observed is the sum of values of confirmed UTXOs controlled by
+	// the wallet.
+	Observed int64 `json:"observed"`
+	// Unconfirmed is the sum of values of UTXOs controlled by the wallet
+	// coming from mempool transactions.
+	Unconfirmed int64 `json:"unconfirmed"`
+	// PendingRedemptions is the sum of values of pending redemption requests
+	// the wallet is expected to handle.
+	PendingRedemptions int64 `json:"pendingRedemptions"`
+	// Utxos holds confirmed UTXOs controlled by the wallet.
+	Utxos []*ReservesUtxo `json:"utxos"`
+	// UnsweptDeposits holds revealed deposits of the wallet not swept yet.
+	UnsweptDeposits []*ReservesDeposit `json:"unsweptDeposits"`
+	// InFlightTransactions holds hashes of mempool transactions paying
+	// the wallet.
+	InFlightTransactions []string `json:"inFlightTransactions"`
+	// Discrepancies holds human-readable descriptions of discrepancies between
+	// the Bridge accounting and the Bitcoin chain state.
+	Discrepancies []string `json:"discrepancies"`
+}
+
+// ReservesUtxo describes an UTXO in the reserves report.
+type ReservesUtxo struct {
+	TransactionHash string `json:"transactionHash"`
+	OutputIndex     uint32 `json:"outputIndex"`
+	Value           int64  `json:"value"`
+}
+
+// ReservesDeposit describes a revealed deposit in the reserves report.
+type ReservesDeposit struct {
+	FundingTransactionHash string `json:"fundingTransactionHash"`
+	FundingOutputIndex     uint32 `json:"fundingOutputIndex"`
+	Amount                 int64  `json:"amount"`
+	RevealedAt             int64  `json:"revealedAt"`
+}
+
+// SignedReservesReport is a reserves report signed by the operator key.
+// The signature is computed over the compact JSON encoding of the report.
+type SignedReservesReport struct {
+	Report    json.RawMessage `json:"report"`
+	Signer    string          `json:"signer"`
+	PublicKey string          `json:"publicKey"`
+	Signature string          `json:"signature"`
+}
+
+// BuildReservesReport builds a report of reserves of all wallets registered
+// in the Bridge that may hold funds, i.e. wallets in the Live and MovingFunds
+// states. For each wallet, the report compares the value expected by the
+// Bridge, i.e. the value of the wallet's main UTXO, with the value of UTXOs
+// actually controlled by the wallet on the Bitcoin chain. The report also
+// contains revealed deposits not swept yet and mempool transactions paying
+// the wallet.
+func BuildReservesReport(
+	chain Chain,
+	btcChain bitcoin.Chain,
+) (*ReservesReport, error) {
+	logger.Infof("reading registered wallets from chain")
+
+	walletRegisteredEvents, err := chain.PastNewWalletRegisteredEvents(nil)
+	if err != nil {
+		return nil, fmt.Errorf(
+			"failed to get new wallet registered events: [%w]",
+			err,
+		)
+	}
+
+	logger.Infof("reading revealed deposits from chain")
+
+	depositRevealedEvents, err := chain.PastDepositRevealedEvents(nil)
+	if err != nil {
+		return nil, fmt.Errorf(
+			"failed to get past deposit revealed events: [%w]",
+			err,
+		)
+	}
+
+	walletsDepositRevealedEvents := make(
+		map[[20]byte][]*tbtc.DepositRevealedEvent,
+	)
+	for _, event := range depositRevealedEvents {
+		walletsDepositRevealedEvents[event.WalletPublicKeyHash] = append(
+			walletsDepositRevealedEvents[event.WalletPublicKeyHash],
+			event,
+		)
+	}
+
+	report := &ReservesReport{
+		GeneratedAt: time.Now().Unix(),
+		Wallets:     make([]*WalletReserves, 0),
+	}
+
+	for _, event := range walletRegisteredEvents {
+		walletPublicKeyHash := event.WalletPublicKeyHash
+
+		wallet, err := chain.GetWallet(walletPublicKeyHash)
+		if err != nil {
+			return nil, fmt.Errorf(
+				"failed to get wallet data for wallet [0x%x]: [%w]",
+				walletPublicKeyHash,
+				err,
+			)
+		}
+
+		if wallet.State != tbtc.StateLive &&
+			wallet.State != tbtc.StateMovingFunds {
+			continue
+		}
+
+		logger.Infof(
+			"building reserves report for wallet [0x%x]",
+			walletPublicKeyHash,
+		)
+
+		walletReserves, err := buildWalletReserves(
+			chain,
+			btcChain,
+			walletPublicKeyHash,
+			wallet,
+			walletsDepositRevealedEvents[walletPublicKeyHash],
+		)
+		if err != nil {
+			return nil, fmt.Errorf(
+				"failed to build reserves report for wallet [0x%x]: [%w]",
+				walletPublicKeyHash,
+				err,
+			)
+		}
+
+		report.Wallets = append(report.Wallets, walletReserves)
+		report.TotalExpected += walletReserves.Expected
+		report.TotalObserved += walletReserves.Observed
+		for _, deposit := range walletReserves.UnsweptDeposits {
+			report.TotalUnsweptDeposits += deposit.Amount
+		}
+	}
+
+	return report, nil
+}
+
+func buildWalletReserves(
+	chain Chain,
+	btcChain bitcoin.Chain,
+	walletPublicKeyHash [20]byte,
+	wallet *tbtc.WalletChainData,
+	depositRevealedEvents []*tbtc.DepositRevealedEvent,
+) (*WalletReserves, error) {
+	walletReserves := &WalletReserves{
+		WalletPublicKeyHash:  hexutils.Encode(walletPublicKeyHash[:]),
+		State:                wallet.State.String(),
+		PendingRedemptions:   int64(wallet.PendingRedemptionsValue),
+		Utxos:                make([]*ReservesUtxo, 0),
+		UnsweptDeposits:      make([]*ReservesDeposit, 0),
+		InFlightTransactions: make([]string, 0),
+		Discrepancies:        make([]string, 0),
+	}
+
+	utxos, err := btcChain.GetUtxosForPublicKeyHash(walletPublicKeyHash)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get confirmed UTXOs: [%w]", err)
+	}
+
+	var mainUtxo *bitcoin.UnspentTransactionOutput
+	for _, utxo := range utxos {
+		walletReserves.Utxos = append(walletReserves.Utxos, newReservesUtxo(utxo))
+		walletReserves.Observed += utxo.Value
+
+		if wallet.MainUtxoHash != [32]byte{} &&
+			chain.ComputeMainUtxoHash(utxo) == wallet.MainUtxoHash {
+			mainUtxo = utxo
+			walletReserves.MainUtxoUnspent = true
+		}
+	}
+
+	// If the main UTXO is not among the current UTXOs, it was either spent
+	// by a transaction whose SPV proof was not submitted yet or it does not
+	// exist on the Bitcoin chain at all. The full transaction history must
+	// be inspected to tell the difference.
+	if wallet.MainUtxoHash != [32]byte{} && mainUtxo == nil {
+		mainUtxo, err = tbtc.DetermineWalletMainUtxo(
+			walletPublicKeyHash,
+			chain,
+			btcChain,
+		)
+		if err != nil {
+			walletReserves.Discrepancies = append(
+				walletReserves.Discrepancies,
+				fmt.Sprintf(
+					"main UTXO registered in the Bridge cannot be "+
+						"determined: [%v]",
+					err,
+				),
+			)
+		} else {
+			walletReserves.Discrepancies = append(
+				walletReserves.Discrepancies,
+				"main UTXO registered in the Bridge is already spent",
+			)
+		}
+	}
+
+	if mainUtxo != nil {
+		walletReserves.MainUtxo = newReservesUtxo(mainUtxo)
+		walletReserves.Expected = mainUtxo.Value
+	}
+
+	if walletReserves.Observed < walletReserves.Expected {
+		walletReserves.Discrepancies = append(
+			walletReserves.Discrepancies,
+			fmt.Sprintf(
+				"observed value [%v] is lower than expected value [%v]",
+				walletReserves.Observed,
+				walletReserves.Expected,
+			),
+		)
+	}
+
+	mempoolUtxos, err := btcChain.GetMempoolUtxosForPublicKeyHash(
+		walletPublicKeyHash,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get mempool UTXOs: [%w]", err)
+	}
+
+	for _, utxo := range mempoolUtxos {
+		walletReserves.Unconfirmed += utxo.Value
+	}
+
+	mempoolTransactions, err := btcChain.GetMempoolForPublicKeyHash(
+		walletPublicKeyHash,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get mempool transactions: [%w]", err)
+	}
+
+	for _, transaction := range mempoolTransactions {
+		walletReserves.InFlightTransactions = append(
+			walletReserves.InFlightTransactions,
+			transaction.Hash().Hex(bitcoin.ReversedByteOrder),
+		)
+	}
+
+	for _, event := range depositRevealedEvents {
+		depositRequest, found, err := chain.GetDepositRequest(
+			event.FundingTxHash,
+			event.FundingOutputIndex,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get deposit request: [%w]", err)
+		}
+
+		if !found {
+			return nil, fmt.Errorf(
+				"no deposit request for funding outpoint [%s:%d]",
+				event.FundingTxHash.Hex(bitcoin.ReversedByteOrder),
+				event.FundingOutputIndex,
+			)
+		}
+
+		if depositRequest.SweptAt.Unix() != 0 {
+			continue
+		}
+
+		walletReserves.UnsweptDeposits = append(
+			walletReserves.UnsweptDeposits,
+			&ReservesDeposit{
+				FundingTransactionHash: event.FundingTxHash.Hex(
+					bitcoin.ReversedByteOrder,
+				),
+				FundingOutputIndex: event.FundingOutputIndex,
+				Amount:             int64(depositRequest.Amount),
+				RevealedAt:         depositRequest.RevealedAt.Unix(),
+			},
+		)
+	}
+
+	return walletReserves, nil
+}
+
+func newReservesUtxo(utxo *bitcoin.UnspentTransactionOutput) *ReservesUtxo {
+	return &ReservesUtxo{
+		TransactionHash: utxo.Outpoint.TransactionHash.Hex(
+			bitcoin.ReversedByteOrder,
+		),
+		OutputIndex: utxo.Outpoint.OutputIndex,
+		Value:       utxo.Value,
+	}
+}
+
+// SignReservesReport signs the compact JSON encoding of the given reserves
+// report using the given operator signing.
+func SignReservesReport(
+	report *ReservesReport,
+	signing chain.Signing,
+) (*SignedReservesReport, error) {
+	reportJSON, err := json.Marshal(report)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal report: [%w]", err)
+	}
+
+	signature, err := signing.Sign(reportJSON)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign report: [%w]", err)
+	}
+
+	return &SignedReservesReport{
+		Report:    reportJSON,
+		Signer:    signing.Address().String(),
+		PublicKey: hexutils.Encode(signing.PublicKey()),
+		Signature: hexutils.Encode(signature),
+	}, nil
+}
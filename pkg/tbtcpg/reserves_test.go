@@ -0,0 +1,286 @@
+package tbtcpg_test
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/go-test/deep"
+
+	"github.com/keep-network/keep-core/internal/hexutils"
+	"github.com/keep-network/keep-core/internal/testutils"
+	"github.com/keep-network/keep-core/pkg/bitcoin"
+	"github.com/keep-network/keep-core/pkg/bitcoin/simnet"
+	"github.com/keep-network/keep-core/pkg/chain/local_v1"
+	"github.com/keep-network/keep-core/pkg/operator"
+	"github.com/keep-network/keep-core/pkg/tbtc"
+	"github.com/keep-network/keep-core/pkg/tbtcpg"
+)
+
+func TestBuildReservesReport(t *testing.T) {
+	liveWalletPublicKeyHash := hexToByte20(
+		"92a6ec889a8fa34f731e639edede4c75e184307c",
+	)
+	movingFundsWalletPublicKeyHash := hexToByte20(
+		"e257eccafbc07c381642ce6e7e55120fb077fbed",
+	)
+	closedWalletPublicKeyHash := hexToByte20(
+		"8db50eb52063ea9d98b3eac91489a90f738986f6",
+	)
+
+	tbtcChain := tbtcpg.NewLocalChain()
+	btcChain, err := simnet.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	liveWalletScript, err := bitcoin.PayToWitnessPublicKeyHash(
+		liveWalletPublicKeyHash,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mainUtxoTransaction, err := btcChain.Fund(liveWalletScript, 100000)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := btcChain.MineBlocks(1); err != nil {
+		t.Fatal(err)
+	}
+
+	inFlightTransaction, err := btcChain.Fund(liveWalletScript, 5000)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mainUtxo := &bitcoin.UnspentTransactionOutput{
+		Outpoint: &bitcoin.TransactionOutpoint{
+			TransactionHash: mainUtxoTransaction.Hash(),
+			OutputIndex:     0,
+		},
+		Value: 100000,
+	}
+
+	// The moving funds wallet's main UTXO does not exist on the Bitcoin chain.
+	missingMainUtxo := &bitcoin.UnspentTransactionOutput{
+		Outpoint: &bitcoin.TransactionOutpoint{
+			TransactionHash: bitcoin.Hash{0x01},
+			OutputIndex:     0,
+		},
+		Value: 50000,
+	}
+
+	wallets := map[[20]byte]*tbtc.WalletChainData{
+		liveWalletPublicKeyHash: {
+			MainUtxoHash:            tbtcChain.ComputeMainUtxoHash(mainUtxo),
+			PendingRedemptionsValue: 30000,
+			State:                   tbtc.StateLive,
+		},
+		movingFundsWalletPublicKeyHash: {
+			MainUtxoHash: tbtcChain.ComputeMainUtxoHash(missingMainUtxo),
+			State:        tbtc.StateMovingFunds,
+		},
+		closedWalletPublicKeyHash: {
+			State: tbtc.StateClosed,
+		},
+	}
+
+	for _, walletPublicKeyHash := range [][20]byte{
+		liveWalletPublicKeyHash,
+		movingFundsWalletPublicKeyHash,
+		closedWalletPublicKeyHash,
+	} {
+		err := tbtcChain.AddPastNewWalletRegisteredEvent(
+			nil,
+			&tbtc.NewWalletRegisteredEvent{
+				WalletPublicKeyHash: walletPublicKeyHash,
+			},
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		tbtcChain.SetWallet(walletPublicKeyHash, wallets[walletPublicKeyHash])
+	}
+
+	deposits := []struct {
+		fundingTxHash bitcoin.Hash
+		amount        uint64
+		sweptAt       time.Time
+	}{
+		{
+			fundingTxHash: bitcoin.Hash{0x02},
+			amount:        20000,
+			sweptAt:       time.Unix(0, 0),
+		},
+		{
+			fundingTxHash: bitcoin.Hash{0x03},
+			amount:        40000,
+			sweptAt:       time.Unix(1700000100, 0),
+		},
+	}
+
+	for _, deposit := range deposits {
+		err := tbtcChain.AddPastDepositRevealedEvent(
+			nil,
+			&tbtc.DepositRevealedEvent{
+				FundingTxHash:       deposit.fundingTxHash,
+				FundingOutputIndex:  1,
+				WalletPublicKeyHash: liveWalletPublicKeyHash,
+			},
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		tbtcChain.SetDepositRequest(
+			deposit.fundingTxHash,
+			1,
+			&tbtc.DepositChainRequest{
+				Amount:     deposit.amount,
+				RevealedAt: time.Unix(1700000000, 0),
+				SweptAt:    deposit.sweptAt,
+			},
+		)
+	}
+
+	report, err := tbtcpg.BuildReservesReport(tbtcChain, btcChain)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expectedMainUtxo := &tbtcpg.ReservesUtxo{
+		TransactionHash: mainUtxoTransaction.Hash().Hex(bitcoin.ReversedByteOrder),
+		OutputIndex:     0,
+		Value:           100000,
+	}
+
+	expectedWallets := []*tbtcpg.WalletReserves{
+		{
+			WalletPublicKeyHash: hexutils.Encode(liveWalletPublicKeyHash[:]),
+			State:               tbtc.StateLive.String(),
+			MainUtxo:            expectedMainUtxo,
+			MainUtxoUnspent:     true,
+			Expected:            100000,
+			Observed:            100000,
+			Unconfirmed:         5000,
+			PendingRedemptions:  30000,
+			Utxos:               []*tbtcpg.ReservesUtxo{expectedMainUtxo},
+			UnsweptDeposits: []*tbtcpg.ReservesDeposit{
+				{
+					FundingTransactionHash: bitcoin.Hash{0x02}.Hex(
+						bitcoin.ReversedByteOrder,
+					),
+					FundingOutputIndex: 1,
+					Amount:             20000,
+					RevealedAt:         1700000000,
+				},
+			},
+			InFlightTransactions: []string{
+				inFlightTransaction.Hash().Hex(bitcoin.ReversedByteOrder),
+			},
+			Discrepancies: []string{},
+		},
+		{
+			WalletPublicKeyHash:  hexutils.Encode(movingFundsWalletPublicKeyHash[:]),
+			State:                tbtc.StateMovingFunds.String(),
+			Utxos:                []*tbtcpg.ReservesUtxo{},
+			UnsweptDeposits:      []*tbtcpg.ReservesDeposit{},
+			InFlightTransactions: []string{},
+			Discrepancies: []string{
+				"main UTXO registered in the Bridge cannot be determined: " +
+					"[main UTXO not found]",
+			},
+		},
+	}
+
+	if diff := deep.Equal(expectedWallets, report.Wallets); diff != nil {
+		t.Errorf("invalid wallets: %v", diff)
+	}
+
+	testutils.AssertIntsEqual(
+		t,
+		"total expected",
+		100000,
+		int(report.TotalExpected),
+	)
+	testutils.AssertIntsEqual(
+		t,
+		"total observed",
+		100000,
+		int(report.TotalObserved),
+	)
+	testutils.AssertIntsEqual(
+		t,
+		"total unswept deposits",
+		20000,
+		int(report.TotalUnsweptDeposits),
+	)
+}
+
+func TestSignReservesReport(t *testing.T) {
+	operatorPrivateKey, _, err := operator.GenerateKeyPair(
+		local_v1.DefaultCurve,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	signing := local_v1.NewSigner(operatorPrivateKey)
+
+	report := &tbtcpg.ReservesReport{
+		GeneratedAt:   1700000000,
+		Wallets:       []*tbtcpg.WalletReserves{},
+		TotalExpected: 100000,
+		TotalObserved: 100000,
+	}
+
+	signedReport, err := tbtcpg.SignReservesReport(report, signing)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testutils.AssertStringsEqual(
+		t,
+		"signer",
+		signing.Address().String(),
+		signedReport.Signer,
+	)
+
+	// The signed report must survive a round trip through an indented JSON
+	// encoding as verifiers are expected to verify the signature over
+	// the compact encoding of the report.
+	signedReportJSON, err := json.MarshalIndent(signedReport, "", "  ")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var decodedSignedReport tbtcpg.SignedReservesReport
+	if err := json.Unmarshal(signedReportJSON, &decodedSignedReport); err != nil {
+		t.Fatal(err)
+	}
+
+	var decodedReport tbtcpg.ReservesReport
+	if err := json.Unmarshal(decodedSignedReport.Report, &decodedReport); err != nil {
+		t.Fatal(err)
+	}
+
+	compactReport, err := json.Marshal(&decodedReport)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	signature, err := hexutils.Decode(decodedSignedReport.Signature)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	valid, err := signing.Verify(compactReport, signature)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testutils.AssertBoolsEqual(t, "signature validity", true, valid)
+}
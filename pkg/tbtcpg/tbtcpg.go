@@ -35,13 +35,22 @@ type ProposalGenerator struct {
 func NewProposalGenerator(
 	chain Chain,
 	btcChain bitcoin.Chain,
+	feeRateEstimator bitcoin.FeeRateEstimator,
+	config tbtc.Config,
 ) *ProposalGenerator {
 	tasks := []ProposalTask{
-		NewDepositSweepTask(chain, btcChain),
-		NewRedemptionTask(chain, btcChain),
+		NewDepositSweepTask(chain, btcChain, feeRateEstimator),
+		NewRedemptionTask(chain, btcChain, feeRateEstimator),
 		NewHeartbeatTask(chain),
-		NewMovingFundsTask(chain, btcChain),
-		NewMovedFundsSweepTask(chain, btcChain),
+		NewMovingFundsTask(chain, btcChain, feeRateEstimator),
+		NewMovedFundsSweepTask(chain, btcChain, feeRateEstimator),
+		NewFeeBumpTask(
+			chain,
+			btcChain,
+			feeRateEstimator,
+			config.FeeBumpMinPendingBlocks,
+		),
+		NewKeyRefreshTask(chain, config.KeyRefreshEnabled),
 	}
 
 	return &ProposalGenerator{
@@ -0,0 +1,156 @@
+package tbtcpg
+
+import (
+	"crypto/ecdsa"
+	"fmt"
+	"time"
+
+	"github.com/keep-network/keep-core/pkg/bitcoin"
+	"github.com/keep-network/keep-core/pkg/tbtc"
+)
+
+// WalletDescriptors holds the output descriptors of a wallet registered in
+// the Bridge. The descriptors describe all output scripts the wallet can
+// receive funds on and allow watching the wallet's balance using external
+// Bitcoin tooling, e.g. by importing them into a watch-only Bitcoin Core
+// wallet.
+type WalletDescriptors struct {
+	WalletPublicKeyHash [20]byte
+	WalletPublicKey     *ecdsa.PublicKey
+	State               tbtc.WalletState
+	CreatedAt           time.Time
+	// WitnessPublicKeyHashDescriptor is the `wpkh(<pubkey>)` descriptor,
+	// with the checksum, describing the wallet's P2WPKH output script.
+	WitnessPublicKeyHashDescriptor string
+	// PublicKeyHashDescriptor is the `pkh(<pubkey>)` descriptor, with
+	// the checksum, describing the wallet's P2PKH output script.
+	PublicKeyHashDescriptor string
+	// MainUtxo is the wallet's main UTXO currently registered in the Bridge.
+	// It is nil if the wallet has no main UTXO at the moment.
+	MainUtxo *bitcoin.UnspentTransactionOutput
+}
+
+// FindWalletDescriptors finds all wallets registered in the Bridge that may
+// hold funds, i.e. wallets in the Live and MovingFunds states, and returns
+// their output descriptors along with their current main UTXOs. Wallets
+// are returned in the order of their registration.
+func FindWalletDescriptors(
+	chain Chain,
+	btcChain bitcoin.Chain,
+) ([]*WalletDescriptors, error) {
+	logger.Infof("reading registered wallets from chain")
+
+	events, err := chain.PastNewWalletRegisteredEvents(nil)
+	if err != nil {
+		return nil, fmt.Errorf(
+			"failed to get new wallet registered events: [%w]",
+			err,
+		)
+	}
+
+	logger.Infof("found [%v] registered wallets", len(events))
+
+	result := make([]*WalletDescriptors, 0)
+
+	for _, event := range events {
+		walletPublicKeyHash := event.WalletPublicKeyHash
+
+		wallet, err := chain.GetWallet(walletPublicKeyHash)
+		if err != nil {
+			return nil, fmt.Errorf(
+				"failed to get wallet data for wallet [0x%x]: [%w]",
+				walletPublicKeyHash,
+				err,
+			)
+		}
+
+		if wallet.State != tbtc.StateLive &&
+			wallet.State != tbtc.StateMovingFunds {
+			logger.Debugf(
+				"skipping wallet [0x%x] in state [%v]",
+				walletPublicKeyHash,
+				wallet.State,
+			)
+			continue
+		}
+
+		walletDescriptors, err := getWalletDescriptors(
+			chain,
+			btcChain,
+			walletPublicKeyHash,
+			wallet,
+		)
+		if err != nil {
+			return nil, fmt.Errorf(
+				"failed to get descriptors of wallet [0x%x]: [%w]",
+				walletPublicKeyHash,
+				err,
+			)
+		}
+
+		result = append(result, walletDescriptors)
+	}
+
+	return result, nil
+}
+
+func getWalletDescriptors(
+	chain Chain,
+	btcChain bitcoin.Chain,
+	walletPublicKeyHash [20]byte,
+	wallet *tbtc.WalletChainData,
+) (*WalletDescriptors, error) {
+	walletPublicKey, err := chain.GetWalletPublicKey(wallet.EcdsaWalletID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get wallet public key: [%w]", err)
+	}
+
+	// Make sure the public key stored in the wallet registry corresponds to
+	// the public key hash the Bridge knows the wallet under. Otherwise,
+	// the descriptors would describe scripts of a different wallet.
+	if bitcoin.PublicKeyHash(walletPublicKey) != walletPublicKeyHash {
+		return nil, fmt.Errorf(
+			"wallet public key does not match wallet public key hash",
+		)
+	}
+
+	witnessPublicKeyHashDescriptor, err :=
+		bitcoin.PayToWitnessPublicKeyHashDescriptor(walletPublicKey)
+	if err != nil {
+		return nil, fmt.Errorf(
+			"failed to build P2WPKH descriptor: [%w]",
+			err,
+		)
+	}
+
+	publicKeyHashDescriptor, err :=
+		bitcoin.PayToPublicKeyHashDescriptor(walletPublicKey)
+	if err != nil {
+		return nil, fmt.Errorf(
+			"failed to build P2PKH descriptor: [%w]",
+			err,
+		)
+	}
+
+	mainUtxo, err := tbtc.DetermineWalletMainUtxo(
+		walletPublicKeyHash,
+		chain,
+		btcChain,
+	)
+	if err != nil {
+		return nil, fmt.Errorf(
+			"failed to determine wallet main UTXO: [%w]",
+			err,
+		)
+	}
+
+	return &WalletDescriptors{
+		WalletPublicKeyHash:            walletPublicKeyHash,
+		WalletPublicKey:                walletPublicKey,
+		State:                          wallet.State,
+		CreatedAt:                      wallet.CreatedAt,
+		WitnessPublicKeyHashDescriptor: witnessPublicKeyHashDescriptor,
+		PublicKeyHashDescriptor:        publicKeyHashDescriptor,
+		MainUtxo:                       mainUtxo,
+	}, nil
+}
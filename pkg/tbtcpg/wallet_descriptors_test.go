@@ -0,0 +1,194 @@
+package tbtcpg_test
+
+import (
+	"crypto/ecdsa"
+	"encoding/hex"
+	"testing"
+	"time"
+
+	"github.com/btcsuite/btcd/btcec"
+
+	"github.com/keep-network/keep-core/internal/testutils"
+	"github.com/keep-network/keep-core/pkg/bitcoin"
+	"github.com/keep-network/keep-core/pkg/tbtc"
+	"github.com/keep-network/keep-core/pkg/tbtcpg"
+)
+
+func TestFindWalletDescriptors(t *testing.T) {
+	type testWallet struct {
+		ecdsaWalletID [32]byte
+		publicKey     string
+		state         tbtc.WalletState
+	}
+
+	wallets := []*testWallet{
+		{
+			ecdsaWalletID: [32]byte{0x01},
+			publicKey:     "02c6047f9441ed7d6d3045406e95c07cd85c778e4b8cef3ca7abac09b95c709ee5",
+			state:         tbtc.StateLive,
+		},
+		{
+			ecdsaWalletID: [32]byte{0x02},
+			publicKey:     "03e493dbf1c10d80f3581e4904930b1404cc6c13900ee0758474fa94abe8c4cd13",
+			state:         tbtc.StateClosed,
+		},
+		{
+			ecdsaWalletID: [32]byte{0x03},
+			publicKey:     "02f9308a019258c31049344f85f89d5229b531c845836f99b08601f113bce036f9",
+			state:         tbtc.StateMovingFunds,
+		},
+	}
+
+	tbtcChain := tbtcpg.NewLocalChain()
+	btcChain := tbtcpg.NewLocalBitcoinChain()
+
+	createdAt := time.Unix(1700000000, 0)
+
+	for _, wallet := range wallets {
+		publicKey := walletDescriptorsTestPublicKey(t, wallet.publicKey)
+		walletPublicKeyHash := bitcoin.PublicKeyHash(publicKey)
+
+		err := tbtcChain.AddPastNewWalletRegisteredEvent(
+			nil,
+			&tbtc.NewWalletRegisteredEvent{
+				EcdsaWalletID:       wallet.ecdsaWalletID,
+				WalletPublicKeyHash: walletPublicKeyHash,
+			},
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		tbtcChain.SetWallet(walletPublicKeyHash, &tbtc.WalletChainData{
+			EcdsaWalletID: wallet.ecdsaWalletID,
+			CreatedAt:     createdAt,
+			State:         wallet.state,
+		})
+		tbtcChain.SetWalletPublicKey(wallet.ecdsaWalletID, publicKey)
+	}
+
+	walletsDescriptors, err := tbtcpg.FindWalletDescriptors(tbtcChain, btcChain)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expectedDescriptors := []struct {
+		state                          tbtc.WalletState
+		witnessPublicKeyHashDescriptor string
+		publicKeyHashDescriptor        string
+	}{
+		{
+			state:                          tbtc.StateLive,
+			witnessPublicKeyHashDescriptor: "wpkh(02c6047f9441ed7d6d3045406e95c07cd85c778e4b8cef3ca7abac09b95c709ee5)#wg9vgf99",
+			publicKeyHashDescriptor:        "pkh(02c6047f9441ed7d6d3045406e95c07cd85c778e4b8cef3ca7abac09b95c709ee5)#8fhd9pwu",
+		},
+		{
+			state:                          tbtc.StateMovingFunds,
+			witnessPublicKeyHashDescriptor: "wpkh(02f9308a019258c31049344f85f89d5229b531c845836f99b08601f113bce036f9)#8zl0zxma",
+			publicKeyHashDescriptor:        "pkh(02f9308a019258c31049344f85f89d5229b531c845836f99b08601f113bce036f9)#9tvfrq3z",
+		},
+	}
+
+	testutils.AssertIntsEqual(
+		t,
+		"wallets count",
+		len(expectedDescriptors),
+		len(walletsDescriptors),
+	)
+
+	for i, expected := range expectedDescriptors {
+		walletDescriptors := walletsDescriptors[i]
+
+		testutils.AssertStringsEqual(
+			t,
+			"wallet state",
+			expected.state.String(),
+			walletDescriptors.State.String(),
+		)
+		testutils.AssertStringsEqual(
+			t,
+			"P2WPKH descriptor",
+			expected.witnessPublicKeyHashDescriptor,
+			walletDescriptors.WitnessPublicKeyHashDescriptor,
+		)
+		testutils.AssertStringsEqual(
+			t,
+			"P2PKH descriptor",
+			expected.publicKeyHashDescriptor,
+			walletDescriptors.PublicKeyHashDescriptor,
+		)
+		testutils.AssertIntsEqual(
+			t,
+			"created at",
+			int(createdAt.Unix()),
+			int(walletDescriptors.CreatedAt.Unix()),
+		)
+		if walletDescriptors.MainUtxo != nil {
+			t.Errorf("unexpected main UTXO")
+		}
+	}
+}
+
+func TestFindWalletDescriptors_PublicKeyMismatch(t *testing.T) {
+	tbtcChain := tbtcpg.NewLocalChain()
+	btcChain := tbtcpg.NewLocalBitcoinChain()
+
+	ecdsaWalletID := [32]byte{0x01}
+	walletPublicKeyHash := hexToByte20(
+		"92a6ec889a8fa34f731e639edede4c75e184307c",
+	)
+
+	err := tbtcChain.AddPastNewWalletRegisteredEvent(
+		nil,
+		&tbtc.NewWalletRegisteredEvent{
+			EcdsaWalletID:       ecdsaWalletID,
+			WalletPublicKeyHash: walletPublicKeyHash,
+		},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tbtcChain.SetWallet(walletPublicKeyHash, &tbtc.WalletChainData{
+		EcdsaWalletID: ecdsaWalletID,
+		State:         tbtc.StateLive,
+	})
+	tbtcChain.SetWalletPublicKey(
+		ecdsaWalletID,
+		walletDescriptorsTestPublicKey(
+			t,
+			"02c6047f9441ed7d6d3045406e95c07cd85c778e4b8cef3ca7abac09b95c709ee5",
+		),
+	)
+
+	_, err = tbtcpg.FindWalletDescriptors(tbtcChain, btcChain)
+	if err == nil {
+		t.Fatal("expected error")
+	}
+
+	testutils.AssertStringsEqual(
+		t,
+		"error",
+		"failed to get descriptors of wallet "+
+			"[0x92a6ec889a8fa34f731e639edede4c75e184307c]: "+
+			"[wallet public key does not match wallet public key hash]",
+		err.Error(),
+	)
+}
+
+func walletDescriptorsTestPublicKey(
+	t *testing.T,
+	publicKeyHex string,
+) *ecdsa.PublicKey {
+	publicKeyBytes, err := hex.DecodeString(publicKeyHex)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	publicKey, err := btcec.ParsePubKey(publicKeyBytes, btcec.S256())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return publicKey.ToECDSA()
+}
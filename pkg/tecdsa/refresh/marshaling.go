@@ -0,0 +1,171 @@
+package refresh
+
+import (
+	"fmt"
+
+	"google.golang.org/protobuf/proto"
+
+	"github.com/keep-network/keep-core/pkg/crypto/ephemeral"
+	"github.com/keep-network/keep-core/pkg/protocol/group"
+	"github.com/keep-network/keep-core/pkg/tecdsa/refresh/gen/pb"
+)
+
+// Marshal converts this ephemeralPublicKeyMessage to a byte array suitable for
+// network communication.
+func (epkm *ephemeralPublicKeyMessage) Marshal() ([]byte, error) {
+	ephemeralPublicKeys, err := marshalPublicKeyMap(epkm.ephemeralPublicKeys)
+	if err != nil {
+		return nil, err
+	}
+
+	return proto.Marshal(&pb.EphemeralPublicKeyMessage{
+		SenderID:            uint32(epkm.senderID),
+		EphemeralPublicKeys: ephemeralPublicKeys,
+		SessionID:           epkm.sessionID,
+	})
+}
+
+// Unmarshal converts a byte array produced by Marshal to
+// an ephemeralPublicKeyMessage
+func (epkm *ephemeralPublicKeyMessage) Unmarshal(bytes []byte) error {
+	pbMsg := pb.EphemeralPublicKeyMessage{}
+	if err := proto.Unmarshal(bytes, &pbMsg); err != nil {
+		return err
+	}
+
+	if err := validateMemberIndex(pbMsg.SenderID); err != nil {
+		return err
+	}
+	epkm.senderID = group.MemberIndex(pbMsg.SenderID)
+
+	ephemeralPublicKeys, err := unmarshalPublicKeyMap(pbMsg.EphemeralPublicKeys)
+	if err != nil {
+		return err
+	}
+
+	epkm.ephemeralPublicKeys = ephemeralPublicKeys
+	epkm.sessionID = pbMsg.SessionID
+
+	return nil
+}
+
+// Marshal converts this shareDistributionMessage to a byte array suitable for
+// network communication.
+func (sdm *shareDistributionMessage) Marshal() ([]byte, error) {
+	encryptedShares := make(map[uint32][]byte, len(sdm.encryptedShares))
+	for receiverID, encryptedShare := range sdm.encryptedShares {
+		encryptedShares[uint32(receiverID)] = encryptedShare
+	}
+
+	return proto.Marshal(&pb.ShareDistributionMessage{
+		SenderID:        uint32(sdm.senderID),
+		Commitments:     sdm.commitments,
+		EncryptedShares: encryptedShares,
+		SessionID:       sdm.sessionID,
+	})
+}
+
+// Unmarshal converts a byte array produced by Marshal to
+// a shareDistributionMessage.
+func (sdm *shareDistributionMessage) Unmarshal(bytes []byte) error {
+	pbMsg := pb.ShareDistributionMessage{}
+	if err := proto.Unmarshal(bytes, &pbMsg); err != nil {
+		return err
+	}
+
+	if err := validateMemberIndex(pbMsg.SenderID); err != nil {
+		return err
+	}
+
+	encryptedShares := make(
+		map[group.MemberIndex][]byte,
+		len(pbMsg.EncryptedShares),
+	)
+	for receiverID, encryptedShare := range pbMsg.EncryptedShares {
+		if err := validateMemberIndex(receiverID); err != nil {
+			return err
+		}
+
+		encryptedShares[group.MemberIndex(receiverID)] = encryptedShare
+	}
+
+	sdm.senderID = group.MemberIndex(pbMsg.SenderID)
+	sdm.commitments = pbMsg.Commitments
+	sdm.encryptedShares = encryptedShares
+	sdm.sessionID = pbMsg.SessionID
+
+	return nil
+}
+
+// Marshal converts this publicKeySharesConfirmationMessage to a byte array
+// suitable for network communication.
+func (pkscm *publicKeySharesConfirmationMessage) Marshal() ([]byte, error) {
+	return proto.Marshal(&pb.PublicKeySharesConfirmationMessage{
+		SenderID:              uint32(pkscm.senderID),
+		PublicKeySharesDigest: pkscm.publicKeySharesDigest,
+		SessionID:             pkscm.sessionID,
+	})
+}
+
+// Unmarshal converts a byte array produced by Marshal to
+// a publicKeySharesConfirmationMessage.
+func (pkscm *publicKeySharesConfirmationMessage) Unmarshal(bytes []byte) error {
+	pbMsg := pb.PublicKeySharesConfirmationMessage{}
+	if err := proto.Unmarshal(bytes, &pbMsg); err != nil {
+		return err
+	}
+
+	if err := validateMemberIndex(pbMsg.SenderID); err != nil {
+		return err
+	}
+
+	pkscm.senderID = group.MemberIndex(pbMsg.SenderID)
+	pkscm.publicKeySharesDigest = pbMsg.PublicKeySharesDigest
+	pkscm.sessionID = pbMsg.SessionID
+
+	return nil
+}
+
+func validateMemberIndex(protoIndex uint32) error {
+	// Protobuf does not have uint8 type, so we are using uint32. When
+	// unmarshalling message, we need to make sure we do not overflow.
+	if protoIndex > group.MaxMemberIndex {
+		return fmt.Errorf("invalid member index value: [%v]", protoIndex)
+	}
+	return nil
+}
+
+func marshalPublicKeyMap(
+	publicKeys map[group.MemberIndex]*ephemeral.PublicKey,
+) (map[uint32][]byte, error) {
+	marshalled := make(map[uint32][]byte, len(publicKeys))
+	for id, publicKey := range publicKeys {
+		if publicKey == nil {
+			return nil, fmt.Errorf("nil public key for member [%v]", id)
+		}
+
+		marshalled[uint32(id)] = publicKey.Marshal()
+	}
+	return marshalled, nil
+}
+
+func unmarshalPublicKeyMap(
+	publicKeys map[uint32][]byte,
+) (map[group.MemberIndex]*ephemeral.PublicKey, error) {
+	var unmarshalled = make(map[group.MemberIndex]*ephemeral.PublicKey, len(publicKeys))
+	for memberID, publicKeyBytes := range publicKeys {
+		if err := validateMemberIndex(memberID); err != nil {
+			return nil, err
+		}
+
+		publicKey, err := ephemeral.UnmarshalPublicKey(publicKeyBytes)
+		if err != nil {
+			return nil, fmt.Errorf("could not unmarshal public key [%v]", err)
+		}
+
+		unmarshalled[group.MemberIndex(memberID)] = publicKey
+
+	}
+
+	return unmarshalled, nil
+}
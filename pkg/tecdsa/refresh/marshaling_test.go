@@ -0,0 +1,179 @@
+package refresh
+
+import (
+	"reflect"
+	"testing"
+
+	fuzz "github.com/google/gofuzz"
+	"github.com/keep-network/keep-core/pkg/crypto/ephemeral"
+	"github.com/keep-network/keep-core/pkg/internal/pbutils"
+	"github.com/keep-network/keep-core/pkg/protocol/group"
+)
+
+func TestEphemeralPublicKeyMessage_MarshalingRoundtrip(t *testing.T) {
+	keyPair1, err := ephemeral.GenerateKeyPair()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	keyPair2, err := ephemeral.GenerateKeyPair()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	publicKeys := make(map[group.MemberIndex]*ephemeral.PublicKey)
+	publicKeys[group.MemberIndex(211)] = keyPair1.PublicKey
+	publicKeys[group.MemberIndex(19)] = keyPair2.PublicKey
+
+	msg := &ephemeralPublicKeyMessage{
+		senderID:            group.MemberIndex(38),
+		ephemeralPublicKeys: publicKeys,
+		sessionID:           "session-1",
+	}
+	unmarshaled := &ephemeralPublicKeyMessage{}
+
+	err = pbutils.RoundTrip(msg, unmarshaled)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(msg, unmarshaled) {
+		t.Fatalf("unexpected content of unmarshaled message")
+	}
+}
+
+func TestFuzzEphemeralPublicKeyMessage_MarshalingRoundtrip(t *testing.T) {
+	for i := 0; i < 10; i++ {
+		var (
+			senderID            group.MemberIndex
+			ephemeralPublicKeys map[group.MemberIndex]*ephemeral.PublicKey
+			sessionID           string
+		)
+
+		f := fuzz.New().NilChance(0.1).
+			NumElements(0, 512).
+			Funcs(pbutils.FuzzFuncs()...)
+
+		f.Fuzz(&senderID)
+		f.Fuzz(&ephemeralPublicKeys)
+		f.Fuzz(&sessionID)
+
+		message := &ephemeralPublicKeyMessage{
+			senderID:            senderID,
+			ephemeralPublicKeys: ephemeralPublicKeys,
+			sessionID:           sessionID,
+		}
+
+		_ = pbutils.RoundTrip(message, &ephemeralPublicKeyMessage{})
+	}
+}
+
+func TestFuzzEphemeralPublicKeyMessage_Unmarshaler(t *testing.T) {
+	pbutils.FuzzUnmarshaler(&ephemeralPublicKeyMessage{})
+}
+
+func TestShareDistributionMessage_MarshalingRoundtrip(t *testing.T) {
+	msg := &shareDistributionMessage{
+		senderID: group.MemberIndex(50),
+		commitments: [][]byte{
+			{1, 2, 3, 4, 5},
+			{6, 7, 8, 9, 10},
+		},
+		encryptedShares: map[group.MemberIndex][]byte{
+			1: {11, 12, 13, 14, 15},
+			2: {16, 17, 18, 19, 20},
+		},
+		sessionID: "session-1",
+	}
+	unmarshaled := &shareDistributionMessage{}
+
+	err := pbutils.RoundTrip(msg, unmarshaled)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(msg, unmarshaled) {
+		t.Fatalf("unexpected content of unmarshaled message")
+	}
+}
+
+func TestFuzzShareDistributionMessage_MarshalingRoundtrip(t *testing.T) {
+	for i := 0; i < 10; i++ {
+		var (
+			senderID        group.MemberIndex
+			commitments     [][]byte
+			encryptedShares map[group.MemberIndex][]byte
+			sessionID       string
+		)
+
+		f := fuzz.New().NilChance(0.1).
+			NumElements(0, 512).
+			Funcs(pbutils.FuzzFuncs()...)
+
+		f.Fuzz(&senderID)
+		f.Fuzz(&commitments)
+		f.Fuzz(&encryptedShares)
+		f.Fuzz(&sessionID)
+
+		message := &shareDistributionMessage{
+			senderID:        senderID,
+			commitments:     commitments,
+			encryptedShares: encryptedShares,
+			sessionID:       sessionID,
+		}
+
+		_ = pbutils.RoundTrip(message, &shareDistributionMessage{})
+	}
+}
+
+func TestFuzzShareDistributionMessage_Unmarshaler(t *testing.T) {
+	pbutils.FuzzUnmarshaler(&shareDistributionMessage{})
+}
+
+func TestPublicKeySharesConfirmationMessage_MarshalingRoundtrip(t *testing.T) {
+	msg := &publicKeySharesConfirmationMessage{
+		senderID:              group.MemberIndex(50),
+		publicKeySharesDigest: []byte{1, 2, 3, 4, 5},
+		sessionID:             "session-1",
+	}
+	unmarshaled := &publicKeySharesConfirmationMessage{}
+
+	err := pbutils.RoundTrip(msg, unmarshaled)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(msg, unmarshaled) {
+		t.Fatalf("unexpected content of unmarshaled message")
+	}
+}
+
+func TestFuzzPublicKeySharesConfirmationMessage_MarshalingRoundtrip(t *testing.T) {
+	for i := 0; i < 10; i++ {
+		var (
+			senderID              group.MemberIndex
+			publicKeySharesDigest []byte
+			sessionID             string
+		)
+
+		f := fuzz.New().NilChance(0.1).
+			NumElements(0, 512).
+			Funcs(pbutils.FuzzFuncs()...)
+
+		f.Fuzz(&senderID)
+		f.Fuzz(&publicKeySharesDigest)
+		f.Fuzz(&sessionID)
+
+		message := &publicKeySharesConfirmationMessage{
+			senderID:              senderID,
+			publicKeySharesDigest: publicKeySharesDigest,
+			sessionID:             sessionID,
+		}
+
+		_ = pbutils.RoundTrip(message, &publicKeySharesConfirmationMessage{})
+	}
+}
+
+func TestFuzzPublicKeySharesConfirmationMessage_Unmarshaler(t *testing.T) {
+	pbutils.FuzzUnmarshaler(&publicKeySharesConfirmationMessage{})
+}
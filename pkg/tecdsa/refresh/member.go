@@ -0,0 +1,184 @@
+package refresh
+
+import (
+	"math/big"
+
+	"github.com/bnb-chain/tss-lib/crypto"
+	"github.com/ipfs/go-log/v2"
+	"github.com/keep-network/keep-core/pkg/crypto/ephemeral"
+	"github.com/keep-network/keep-core/pkg/protocol/group"
+	"github.com/keep-network/keep-core/pkg/tecdsa"
+)
+
+// Member represents a key refresh protocol member.
+type member struct {
+	// Logger used to produce log messages.
+	logger log.StandardLogger
+	// id of this group member.
+	id group.MemberIndex
+	// Group to which this member belongs.
+	group *group.Group
+	// Validator allowing to check public key and member index against
+	// group members
+	membershipValidator *group.MembershipValidator
+	// Identifier of the particular key refresh session this member is part of.
+	sessionID string
+	// tECDSA private key share of the member that is the subject of the
+	// refresh process.
+	privateKeyShare *tecdsa.PrivateKeyShare
+}
+
+// newMember creates a new member in an initial state
+func newMember(
+	logger log.StandardLogger,
+	memberID group.MemberIndex,
+	groupSize,
+	dishonestThreshold int,
+	membershipValidator *group.MembershipValidator,
+	sessionID string,
+	privateKeyShare *tecdsa.PrivateKeyShare,
+) *member {
+	return &member{
+		logger:              logger,
+		id:                  memberID,
+		group:               group.NewGroup(dishonestThreshold, groupSize),
+		membershipValidator: membershipValidator,
+		sessionID:           sessionID,
+		privateKeyShare:     privateKeyShare,
+	}
+}
+
+// shouldAcceptMessage indicates whether the given member should accept
+// a message from the given sender.
+func (m *member) shouldAcceptMessage(
+	senderID group.MemberIndex,
+	senderPublicKey []byte,
+) bool {
+	isMessageFromSelf := senderID == m.id
+	isSenderValid := m.membershipValidator.IsValidMembership(
+		senderID,
+		senderPublicKey,
+	)
+	isSenderAccepted := m.group.IsOperating(senderID)
+
+	return !isMessageFromSelf && isSenderValid && isSenderAccepted
+}
+
+// shareID returns the identifier the key share of the given member is
+// evaluated at. Share identifiers are the TSS party ID keys used during
+// the key generation and kept in the key share, in the order of member
+// indexes.
+func (m *member) shareID(memberIndex group.MemberIndex) *big.Int {
+	return m.privateKeyShare.Data().Ks[memberIndex-1]
+}
+
+// polynomialDegree returns the degree of the polynomial the wallet key is
+// shared with. Refresh polynomials must have the same degree so the signing
+// threshold of the refreshed key shares does not change.
+func (m *member) polynomialDegree() int {
+	return m.group.HonestThreshold() - 1
+}
+
+// initializeEphemeralKeysGeneration performs a transition of a member state
+// from the initial state to the first phase of the protocol.
+func (m *member) initializeEphemeralKeysGeneration() *ephemeralKeyPairGeneratingMember {
+	return &ephemeralKeyPairGeneratingMember{
+		member:            m,
+		ephemeralKeyPairs: make(map[group.MemberIndex]*ephemeral.KeyPair),
+	}
+}
+
+// ephemeralKeyPairGeneratingMember represents one member in a key refresh
+// group performing ephemeral key pair generation. It has a full list of
+// `memberIndexes` that belong to its threshold group.
+type ephemeralKeyPairGeneratingMember struct {
+	*member
+
+	// Ephemeral key pairs used to create symmetric keys,
+	// generated individually for each other group member.
+	ephemeralKeyPairs map[group.MemberIndex]*ephemeral.KeyPair
+}
+
+// initializeSymmetricKeyGeneration performs a transition of the member state
+// to the next phase. It returns a member instance ready to execute the
+// next phase of the protocol.
+func (ekpgm *ephemeralKeyPairGeneratingMember) initializeSymmetricKeyGeneration() *symmetricKeyGeneratingMember {
+	return &symmetricKeyGeneratingMember{
+		ephemeralKeyPairGeneratingMember: ekpgm,
+		symmetricKeys:                    make(map[group.MemberIndex]ephemeral.SymmetricKey),
+	}
+}
+
+// symmetricKeyGeneratingMember represents one member in a key refresh group
+// performing ephemeral symmetric key generation.
+type symmetricKeyGeneratingMember struct {
+	*ephemeralKeyPairGeneratingMember
+
+	// Symmetric keys used to encrypt confidential information,
+	// generated individually for each other group member by ECDH'ing the
+	// broadcasted ephemeral public key intended for this member and the
+	// ephemeral private key generated for the other member.
+	symmetricKeys map[group.MemberIndex]ephemeral.SymmetricKey
+}
+
+// initializeShareDistribution returns a member to perform next protocol
+// operations.
+func (skgm *symmetricKeyGeneratingMember) initializeShareDistribution() *shareDistributingMember {
+	return &shareDistributingMember{
+		symmetricKeyGeneratingMember: skgm,
+	}
+}
+
+// shareDistributingMember represents one member in a key refresh group
+// generating its zero-constant refresh polynomial and distributing the
+// polynomial evaluations among other group members.
+type shareDistributingMember struct {
+	*symmetricKeyGeneratingMember
+
+	// Commitments to the coefficients of this member's refresh polynomial,
+	// starting from the coefficient of the first degree term. The constant
+	// term is always zero and is not committed to.
+	commitments []*crypto.ECPoint
+	// Evaluation of this member's refresh polynomial for this member.
+	selfShare *big.Int
+}
+
+// initializeSharesCombination returns a member to perform next protocol
+// operations.
+func (sdm *shareDistributingMember) initializeSharesCombination() *sharesCombiningMember {
+	return &sharesCombiningMember{
+		shareDistributingMember: sdm,
+	}
+}
+
+// sharesCombiningMember represents one member in a key refresh group
+// verifying refresh shares received from other members and combining them
+// with its current key share.
+type sharesCombiningMember struct {
+	*shareDistributingMember
+
+	// Refreshed tECDSA private key share of the member.
+	refreshedPrivateKeyShare *tecdsa.PrivateKeyShare
+	// Digest of refreshed public key shares of all group members.
+	publicKeySharesDigest []byte
+}
+
+// initializeFinalization returns a member to perform next protocol operations.
+func (scm *sharesCombiningMember) initializeFinalization() *finalizingMember {
+	return &finalizingMember{
+		sharesCombiningMember: scm,
+	}
+}
+
+// finalizingMember represents one member of the given group, after it
+// completed the key refresh process.
+//
+// Prepares a result in the last phase of the protocol.
+type finalizingMember struct {
+	*sharesCombiningMember
+}
+
+// Result is a successful computation of the refreshed tECDSA key share.
+func (fm *finalizingMember) Result() *Result {
+	return &Result{PrivateKeyShare: fm.refreshedPrivateKeyShare}
+}
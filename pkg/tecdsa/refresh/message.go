@@ -0,0 +1,103 @@
+package refresh
+
+import (
+	"github.com/keep-network/keep-core/pkg/crypto/ephemeral"
+	"github.com/keep-network/keep-core/pkg/protocol/group"
+)
+
+const messageTypePrefix = "tecdsa_refresh/"
+
+// message holds common traits of all key refresh protocol messages.
+type message interface {
+	// SenderID returns protocol-level identifier of the message sender.
+	SenderID() group.MemberIndex
+	// SessionID returns the session identifier of the message.
+	SessionID() string
+	// Type returns the exact type of the message.
+	Type() string
+}
+
+// ephemeralPublicKeyMessage is a message payload that carries the sender's
+// ephemeral public keys generated for all other group members.
+//
+// The receiver performs ECDH on a sender's ephemeral public key intended for
+// the receiver and on the receiver's private ephemeral key, creating a symmetric
+// key used for encrypting a conversation between the sender and the receiver.
+type ephemeralPublicKeyMessage struct {
+	senderID group.MemberIndex
+
+	ephemeralPublicKeys map[group.MemberIndex]*ephemeral.PublicKey
+	sessionID           string
+}
+
+// SenderID returns protocol-level identifier of the message sender.
+func (epkm *ephemeralPublicKeyMessage) SenderID() group.MemberIndex {
+	return epkm.senderID
+}
+
+// SessionID returns the session identifier of the message.
+func (epkm *ephemeralPublicKeyMessage) SessionID() string {
+	return epkm.sessionID
+}
+
+// Type returns a string describing an ephemeralPublicKeyMessage type for
+// marshaling purposes.
+func (epkm *ephemeralPublicKeyMessage) Type() string {
+	return messageTypePrefix + "ephemeral_public_key_message"
+}
+
+// shareDistributionMessage is a message payload that carries the sender's
+// commitments to the coefficients of its zero-constant refresh polynomial
+// along with the polynomial evaluations for all other group members. Each
+// evaluation is encrypted with the symmetric key established between the
+// sender and the given receiver.
+type shareDistributionMessage struct {
+	senderID group.MemberIndex
+
+	commitments     [][]byte
+	encryptedShares map[group.MemberIndex][]byte
+	sessionID       string
+}
+
+// SenderID returns protocol-level identifier of the message sender.
+func (sdm *shareDistributionMessage) SenderID() group.MemberIndex {
+	return sdm.senderID
+}
+
+// SessionID returns the session identifier of the message.
+func (sdm *shareDistributionMessage) SessionID() string {
+	return sdm.sessionID
+}
+
+// Type returns a string describing a shareDistributionMessage type for
+// marshaling purposes.
+func (sdm *shareDistributionMessage) Type() string {
+	return messageTypePrefix + "share_distribution_message"
+}
+
+// publicKeySharesConfirmationMessage is a message payload that carries the
+// digest of refreshed public key shares of all group members, as computed by
+// the sender. Members compare digests to make sure everyone computed the same
+// refreshed public key shares.
+type publicKeySharesConfirmationMessage struct {
+	senderID group.MemberIndex
+
+	publicKeySharesDigest []byte
+	sessionID             string
+}
+
+// SenderID returns protocol-level identifier of the message sender.
+func (pkscm *publicKeySharesConfirmationMessage) SenderID() group.MemberIndex {
+	return pkscm.senderID
+}
+
+// SessionID returns the session identifier of the message.
+func (pkscm *publicKeySharesConfirmationMessage) SessionID() string {
+	return pkscm.sessionID
+}
+
+// Type returns a string describing a publicKeySharesConfirmationMessage type
+// for marshaling purposes.
+func (pkscm *publicKeySharesConfirmationMessage) Type() string {
+	return messageTypePrefix + "public_key_shares_confirmation_message"
+}
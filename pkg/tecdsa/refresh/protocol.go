@@ -0,0 +1,468 @@
+package refresh
+
+import (
+	"bytes"
+	"crypto/elliptic"
+	"crypto/sha256"
+	"fmt"
+	"math/big"
+
+	tsslibcommon "github.com/bnb-chain/tss-lib/common"
+	"github.com/bnb-chain/tss-lib/crypto"
+	"github.com/keep-network/keep-core/pkg/crypto/ephemeral"
+	"github.com/keep-network/keep-core/pkg/protocol/group"
+	"github.com/keep-network/keep-core/pkg/tecdsa"
+)
+
+// generateEphemeralKeyPair takes the group member list and generates an
+// ephemeral ECDH keypair for every other group member. Generated public
+// ephemeral keys are broadcasted within the group.
+func (ekpgm *ephemeralKeyPairGeneratingMember) generateEphemeralKeyPair() (
+	*ephemeralPublicKeyMessage,
+	error,
+) {
+	ephemeralKeys := make(map[group.MemberIndex]*ephemeral.PublicKey)
+
+	// Calculate ephemeral key pair for every other group member
+	for _, member := range ekpgm.group.MemberIndexes() {
+		if member == ekpgm.id {
+			// don’t actually generate a key with ourselves
+			continue
+		}
+
+		ephemeralKeyPair, err := ephemeral.GenerateKeyPair()
+		if err != nil {
+			return nil, err
+		}
+
+		// save the generated ephemeral key to our state
+		ekpgm.ephemeralKeyPairs[member] = ephemeralKeyPair
+
+		// store the public key to the map for the message
+		ephemeralKeys[member] = ephemeralKeyPair.PublicKey
+	}
+
+	return &ephemeralPublicKeyMessage{
+		senderID:            ekpgm.id,
+		ephemeralPublicKeys: ephemeralKeys,
+		sessionID:           ekpgm.sessionID,
+	}, nil
+}
+
+// generateSymmetricKeys attempts to generate symmetric keys for all remote group
+// members via ECDH. It generates this symmetric key for each remote group member
+// by doing an ECDH between the ephemeral private key generated for a remote
+// group member, and the public key for this member, generated and broadcasted by
+// the remote group member.
+func (skgm *symmetricKeyGeneratingMember) generateSymmetricKeys(
+	ephemeralPubKeyMessages []*ephemeralPublicKeyMessage,
+) error {
+	for _, ephemeralPubKeyMessage := range ephemeralPubKeyMessages {
+		otherMember := ephemeralPubKeyMessage.senderID
+
+		if !skgm.isValidEphemeralPublicKeyMessage(ephemeralPubKeyMessage) {
+			return fmt.Errorf(
+				"member [%v] sent invalid ephemeral public key message",
+				otherMember,
+			)
+		}
+
+		// Find the ephemeral key pair generated by this group member for
+		// the other group member.
+		ephemeralKeyPair, ok := skgm.ephemeralKeyPairs[otherMember]
+		if !ok {
+			return fmt.Errorf(
+				"ephemeral key pair does not exist for member [%v]",
+				otherMember,
+			)
+		}
+
+		// Get the ephemeral private key generated by this group member for
+		// the other group member.
+		thisMemberEphemeralPrivateKey := ephemeralKeyPair.PrivateKey
+
+		// Get the ephemeral public key broadcasted by the other group member,
+		// which was intended for this group member.
+		otherMemberEphemeralPublicKey :=
+			ephemeralPubKeyMessage.ephemeralPublicKeys[skgm.id]
+
+		// Create symmetric key for the current group member and the other
+		// group member by ECDH'ing the public and private key.
+		symmetricKey := thisMemberEphemeralPrivateKey.Ecdh(
+			otherMemberEphemeralPublicKey,
+		)
+		skgm.symmetricKeys[otherMember] = symmetricKey
+	}
+
+	return nil
+}
+
+// isValidEphemeralPublicKeyMessage validates a given EphemeralPublicKeyMessage.
+// Message is considered valid if it contains ephemeral public keys for
+// all other group members.
+func (skgm *symmetricKeyGeneratingMember) isValidEphemeralPublicKeyMessage(
+	message *ephemeralPublicKeyMessage,
+) bool {
+	for _, memberID := range skgm.group.MemberIndexes() {
+		if memberID == message.senderID {
+			// Message contains ephemeral public keys only for other group members
+			continue
+		}
+
+		if _, ok := message.ephemeralPublicKeys[memberID]; !ok {
+			skgm.logger.Warnf(
+				"[member:%v] ephemeral public key message from member [%v] "+
+					"does not contain public key for member [%v]",
+				skgm.id,
+				message.senderID,
+				memberID,
+			)
+			return false
+		}
+	}
+
+	return true
+}
+
+// distributeShares generates a random refresh polynomial of the same degree
+// as the polynomial the wallet key is shared with but with the constant term
+// equal to zero. The member commits to the polynomial coefficients and
+// evaluates the polynomial for each group member. Evaluations intended for
+// other members are encrypted with the symmetric keys established with them.
+// Since the constant term is zero, adding evaluations of all refresh
+// polynomials to the current key shares re-randomizes the shares without
+// changing the shared secret.
+func (sdm *shareDistributingMember) distributeShares() (
+	*shareDistributionMessage,
+	error,
+) {
+	curveOrder := tecdsa.Curve.Params().N
+
+	coefficients := make([]*big.Int, sdm.polynomialDegree())
+	sdm.commitments = make([]*crypto.ECPoint, len(coefficients))
+	marshalledCommitments := make([][]byte, len(coefficients))
+	for i := range coefficients {
+		coefficients[i] = tsslibcommon.GetRandomPositiveInt(curveOrder)
+		sdm.commitments[i] = crypto.ScalarBaseMult(tecdsa.Curve, coefficients[i])
+		marshalledCommitments[i] = marshalPoint(sdm.commitments[i])
+	}
+
+	encryptedShares := make(map[group.MemberIndex][]byte)
+	for _, memberID := range sdm.group.MemberIndexes() {
+		share := evaluatePolynomial(coefficients, sdm.shareID(memberID))
+
+		if memberID == sdm.id {
+			sdm.selfShare = share
+			continue
+		}
+
+		symmetricKey, ok := sdm.symmetricKeys[memberID]
+		if !ok {
+			return nil, fmt.Errorf(
+				"symmetric key does not exist for member [%v]",
+				memberID,
+			)
+		}
+
+		encryptedShare, err := symmetricKey.Encrypt(share.Bytes())
+		if err != nil {
+			return nil, fmt.Errorf(
+				"cannot encrypt share for member [%v]: [%v]",
+				memberID,
+				err,
+			)
+		}
+
+		encryptedShares[memberID] = encryptedShare
+	}
+
+	return &shareDistributionMessage{
+		senderID:        sdm.id,
+		commitments:     marshalledCommitments,
+		encryptedShares: encryptedShares,
+		sessionID:       sdm.sessionID,
+	}, nil
+}
+
+// combineShares verifies shares received from other group members against
+// the commitments they broadcasted and adds all of them to the member's
+// current key share. Public key shares of all group members are updated
+// using the commitments. The outcome is a message containing the digest of
+// refreshed public key shares that is used by other members to confirm
+// everyone computed the same refreshed public key shares.
+func (scm *sharesCombiningMember) combineShares(
+	shareDistributionMessages []*shareDistributionMessage,
+) (*publicKeySharesConfirmationMessage, error) {
+	if len(shareDistributionMessages) != len(scm.group.OperatingMemberIndexes())-1 {
+		return nil, fmt.Errorf(
+			"refresh requires shares from all group members; "+
+				"got [%v] share distribution messages",
+			len(shareDistributionMessages),
+		)
+	}
+
+	curveOrder := tecdsa.Curve.Params().N
+	modN := tsslibcommon.ModInt(curveOrder)
+
+	sharesSum := new(big.Int).Set(scm.selfShare)
+	aggregatedCommitments := make([]*crypto.ECPoint, len(scm.commitments))
+	copy(aggregatedCommitments, scm.commitments)
+
+	for _, message := range shareDistributionMessages {
+		senderID := message.senderID
+
+		commitments, err := unmarshalCommitments(
+			message.commitments,
+			scm.polynomialDegree(),
+		)
+		if err != nil {
+			return nil, fmt.Errorf(
+				"member [%v] sent invalid commitments: [%v]",
+				senderID,
+				err,
+			)
+		}
+
+		share, err := scm.decryptShare(message)
+		if err != nil {
+			return nil, err
+		}
+
+		expectedSharePoint, err := evaluateCommitments(
+			commitments,
+			scm.shareID(scm.id),
+		)
+		if err != nil {
+			return nil, fmt.Errorf(
+				"cannot evaluate commitments of member [%v]: [%v]",
+				senderID,
+				err,
+			)
+		}
+
+		if !crypto.ScalarBaseMult(tecdsa.Curve, share).Equals(expectedSharePoint) {
+			return nil, fmt.Errorf(
+				"member [%v] sent share inconsistent with its commitments",
+				senderID,
+			)
+		}
+
+		sharesSum = modN.Add(sharesSum, share)
+
+		for i := range aggregatedCommitments {
+			aggregatedCommitments[i], err = aggregatedCommitments[i].Add(
+				commitments[i],
+			)
+			if err != nil {
+				return nil, fmt.Errorf(
+					"cannot aggregate commitments of member [%v]: [%v]",
+					senderID,
+					err,
+				)
+			}
+		}
+	}
+
+	data := scm.privateKeyShare.Data()
+
+	refreshedBigXj := make([]*crypto.ECPoint, len(data.BigXj))
+	for i, bigX := range data.BigXj {
+		delta, err := evaluateCommitments(aggregatedCommitments, data.Ks[i])
+		if err != nil {
+			return nil, fmt.Errorf(
+				"cannot evaluate aggregated commitments: [%v]",
+				err,
+			)
+		}
+
+		refreshedBigXj[i], err = bigX.Add(delta)
+		if err != nil {
+			return nil, fmt.Errorf(
+				"cannot refresh public key share [%v]: [%v]",
+				i,
+				err,
+			)
+		}
+	}
+
+	refreshedXi := modN.Add(data.Xi, sharesSum)
+
+	if !crypto.ScalarBaseMult(tecdsa.Curve, refreshedXi).Equals(
+		refreshedBigXj[scm.id-1],
+	) {
+		return nil, fmt.Errorf(
+			"refreshed private key share does not match " +
+				"refreshed public key share",
+		)
+	}
+
+	// The key share data is a copy so modifying it does not affect the
+	// original key share.
+	data.Xi = refreshedXi
+	data.BigXj = refreshedBigXj
+
+	scm.refreshedPrivateKeyShare = tecdsa.NewPrivateKeyShare(data)
+	scm.publicKeySharesDigest = publicKeySharesDigest(refreshedBigXj)
+
+	return &publicKeySharesConfirmationMessage{
+		senderID:              scm.id,
+		publicKeySharesDigest: scm.publicKeySharesDigest,
+		sessionID:             scm.sessionID,
+	}, nil
+}
+
+// decryptShare decrypts the share intended for this member from the given
+// share distribution message.
+func (scm *sharesCombiningMember) decryptShare(
+	message *shareDistributionMessage,
+) (*big.Int, error) {
+	senderID := message.senderID
+
+	symmetricKey, ok := scm.symmetricKeys[senderID]
+	if !ok {
+		return nil, fmt.Errorf(
+			"symmetric key does not exist for member [%v]",
+			senderID,
+		)
+	}
+
+	encryptedShare, ok := message.encryptedShares[scm.id]
+	if !ok {
+		return nil, fmt.Errorf(
+			"member [%v] did not send share for this member",
+			senderID,
+		)
+	}
+
+	shareBytes, err := symmetricKey.Decrypt(encryptedShare)
+	if err != nil {
+		return nil, fmt.Errorf(
+			"cannot decrypt share sent by member [%v]: [%v]",
+			senderID,
+			err,
+		)
+	}
+
+	share := new(big.Int).SetBytes(shareBytes)
+	if share.Cmp(tecdsa.Curve.Params().N) >= 0 {
+		return nil, fmt.Errorf(
+			"member [%v] sent share out of the curve order",
+			senderID,
+		)
+	}
+
+	return share, nil
+}
+
+// verifyPublicKeySharesConfirmations makes sure all other group members
+// computed the same refreshed public key shares as this member. Different
+// public key shares mean members received inconsistent commitments and the
+// refreshed key shares must not be used.
+func (fm *finalizingMember) verifyPublicKeySharesConfirmations(
+	confirmationMessages []*publicKeySharesConfirmationMessage,
+) error {
+	for _, message := range confirmationMessages {
+		if !bytes.Equal(
+			message.publicKeySharesDigest,
+			fm.publicKeySharesDigest,
+		) {
+			return fmt.Errorf(
+				"member [%v] computed different refreshed public key shares",
+				message.senderID,
+			)
+		}
+	}
+
+	return nil
+}
+
+// evaluatePolynomial evaluates the polynomial with the given coefficients
+// and zero constant term at the given point. The first coefficient is the
+// coefficient of the first degree term.
+func evaluatePolynomial(coefficients []*big.Int, x *big.Int) *big.Int {
+	modN := tsslibcommon.ModInt(tecdsa.Curve.Params().N)
+
+	// Horner's method: ((a_t * x + a_{t-1}) * x + ... + a_1) * x
+	result := big.NewInt(0)
+	for i := len(coefficients) - 1; i >= 0; i-- {
+		result = modN.Mul(modN.Add(result, coefficients[i]), x)
+	}
+
+	return result
+}
+
+// evaluateCommitments evaluates the polynomial whose coefficients are
+// committed to with the given commitments at the given point, in the exponent.
+// The result is the public counterpart of evaluatePolynomial.
+func evaluateCommitments(
+	commitments []*crypto.ECPoint,
+	x *big.Int,
+) (*crypto.ECPoint, error) {
+	if len(commitments) == 0 {
+		return nil, fmt.Errorf("no commitments to evaluate")
+	}
+
+	modN := tsslibcommon.ModInt(tecdsa.Curve.Params().N)
+
+	power := new(big.Int).Set(x)
+	result := commitments[0].ScalarMult(power)
+	for _, commitment := range commitments[1:] {
+		power = modN.Mul(power, x)
+
+		var err error
+		result, err = result.Add(commitment.ScalarMult(power))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return result, nil
+}
+
+// publicKeySharesDigest computes the digest of the given public key shares.
+func publicKeySharesDigest(publicKeyShares []*crypto.ECPoint) []byte {
+	hash := sha256.New()
+	for _, publicKeyShare := range publicKeyShares {
+		hash.Write(marshalPoint(publicKeyShare))
+	}
+
+	return hash.Sum(nil)
+}
+
+func marshalPoint(point *crypto.ECPoint) []byte {
+	return elliptic.Marshal(tecdsa.Curve, point.X(), point.Y())
+}
+
+func unmarshalPoint(bytes []byte) (*crypto.ECPoint, error) {
+	x, y := elliptic.Unmarshal(tecdsa.Curve, bytes)
+	if x == nil {
+		return nil, fmt.Errorf("invalid point")
+	}
+
+	return crypto.NewECPoint(tecdsa.Curve, x, y)
+}
+
+func unmarshalCommitments(
+	marshalled [][]byte,
+	expectedCount int,
+) ([]*crypto.ECPoint, error) {
+	if len(marshalled) != expectedCount {
+		return nil, fmt.Errorf(
+			"expected [%v] commitments; got [%v]",
+			expectedCount,
+			len(marshalled),
+		)
+	}
+
+	commitments := make([]*crypto.ECPoint, len(marshalled))
+	for i, bytes := range marshalled {
+		commitment, err := unmarshalPoint(bytes)
+		if err != nil {
+			return nil, fmt.Errorf("invalid commitment [%v]: [%v]", i, err)
+		}
+
+		commitments[i] = commitment
+	}
+
+	return commitments, nil
+}
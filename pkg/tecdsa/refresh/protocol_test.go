@@ -0,0 +1,699 @@
+package refresh
+
+import (
+	"fmt"
+	"math/big"
+	"reflect"
+	"strings"
+	"testing"
+
+	tsslibcommon "github.com/bnb-chain/tss-lib/common"
+	"github.com/bnb-chain/tss-lib/crypto"
+
+	"github.com/keep-network/keep-core/internal/testutils"
+	"github.com/keep-network/keep-core/pkg/crypto/ephemeral"
+	"github.com/keep-network/keep-core/pkg/internal/tecdsatest"
+	"github.com/keep-network/keep-core/pkg/protocol/group"
+	"github.com/keep-network/keep-core/pkg/tecdsa"
+)
+
+const (
+	groupSize          = 5
+	dishonestThreshold = 2
+	sessionID          = "session-1"
+)
+
+func TestGenerateSymmetricKeys(t *testing.T) {
+	members, messages, err := initializeSymmetricKeyGeneratingMembersGroup(
+		dishonestThreshold,
+		groupSize,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, member := range members {
+		err := member.generateSymmetricKeys(
+			filterForSender(messages, member.id),
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for _, member := range members {
+		testutils.AssertIntsEqual(
+			t,
+			fmt.Sprintf(
+				"number of symmetric keys for member [%v]",
+				member.id,
+			),
+			groupSize-1,
+			len(member.symmetricKeys),
+		)
+
+		for otherMemberID, symmetricKey := range member.symmetricKeys {
+			otherMember := members[otherMemberID-1]
+
+			if !reflect.DeepEqual(
+				symmetricKey,
+				otherMember.symmetricKeys[member.id],
+			) {
+				t.Errorf(
+					"symmetric keys of members [%v] and [%v] do not match",
+					member.id,
+					otherMemberID,
+				)
+			}
+		}
+	}
+}
+
+func TestDistributeShares(t *testing.T) {
+	members, err := initializeShareDistributingMembersGroup(
+		dishonestThreshold,
+		groupSize,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, member := range members {
+		message, err := member.distributeShares()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		testutils.AssertIntsEqual(
+			t,
+			"message sender",
+			int(member.id),
+			int(message.senderID),
+		)
+		testutils.AssertStringsEqual(
+			t,
+			"message session ID",
+			sessionID,
+			message.sessionID,
+		)
+		testutils.AssertIntsEqual(
+			t,
+			"commitments count",
+			groupSize-dishonestThreshold-1,
+			len(message.commitments),
+		)
+		testutils.AssertIntsEqual(
+			t,
+			"encrypted shares count",
+			groupSize-1,
+			len(message.encryptedShares),
+		)
+
+		if _, ok := message.encryptedShares[member.id]; ok {
+			t.Errorf("found share encrypted for self")
+		}
+
+		if member.selfShare == nil {
+			t.Errorf("self share not set")
+		}
+
+		// Each share must be encrypted with the proper symmetric key and
+		// must be consistent with the broadcasted commitments.
+		commitments, err := unmarshalCommitments(
+			message.commitments,
+			len(message.commitments),
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for receiverID, encryptedShare := range message.encryptedShares {
+			shareBytes, err := member.symmetricKeys[receiverID].Decrypt(
+				encryptedShare,
+			)
+			if err != nil {
+				t.Fatalf(
+					"share for member [%v] is encrypted using "+
+						"the wrong symmetric key: [%v]",
+					receiverID,
+					err,
+				)
+			}
+
+			expectedSharePoint, err := evaluateCommitments(
+				commitments,
+				member.shareID(receiverID),
+			)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			sharePoint := crypto.ScalarBaseMult(
+				tecdsa.Curve,
+				new(big.Int).SetBytes(shareBytes),
+			)
+			if !sharePoint.Equals(expectedSharePoint) {
+				t.Errorf(
+					"share for member [%v] does not match commitments",
+					receiverID,
+				)
+			}
+		}
+	}
+}
+
+func TestCombineShares(t *testing.T) {
+	members, messages, err := initializeSharesCombiningMembersGroup(
+		dishonestThreshold,
+		groupSize,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	confirmations := make([]*publicKeySharesConfirmationMessage, 0)
+	for _, member := range members {
+		confirmation, err := member.combineShares(
+			filterForSender(messages, member.id),
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		confirmations = append(confirmations, confirmation)
+	}
+
+	for i, confirmation := range confirmations {
+		if !reflect.DeepEqual(
+			confirmations[0].publicKeySharesDigest,
+			confirmation.publicKeySharesDigest,
+		) {
+			t.Errorf(
+				"member [%v] computed different public key shares digest",
+				i+1,
+			)
+		}
+	}
+
+	curveOrder := tecdsa.Curve.Params().N
+
+	for _, member := range members {
+		original := member.privateKeyShare.Data()
+		refreshed := member.refreshedPrivateKeyShare.Data()
+
+		if !original.ECDSAPub.Equals(refreshed.ECDSAPub) {
+			t.Errorf(
+				"member [%v] refreshed key share has different public key",
+				member.id,
+			)
+		}
+
+		if original.Xi.Cmp(refreshed.Xi) == 0 {
+			t.Errorf(
+				"member [%v] private key share has not been refreshed",
+				member.id,
+			)
+		}
+
+		if !reflect.DeepEqual(original.Ks, refreshed.Ks) {
+			t.Errorf(
+				"member [%v] refreshed key share has different share IDs",
+				member.id,
+			)
+		}
+
+		if !crypto.ScalarBaseMult(tecdsa.Curve, refreshed.Xi).Equals(
+			refreshed.BigXj[member.id-1],
+		) {
+			t.Errorf(
+				"member [%v] refreshed private key share does not match "+
+					"refreshed public key share",
+				member.id,
+			)
+		}
+	}
+
+	// Any honest threshold subset of refreshed key shares must reconstruct
+	// the original wallet private key.
+	ks := members[0].refreshedPrivateKeyShare.Data().Ks
+	honestThreshold := groupSize - dishonestThreshold
+	for offset := 0; offset+honestThreshold <= groupSize; offset++ {
+		subset := members[offset : offset+honestThreshold]
+
+		secret := big.NewInt(0)
+		modN := tsslibcommon.ModInt(curveOrder)
+		for _, member := range subset {
+			xi := member.refreshedPrivateKeyShare.Data().Xi
+			secret = modN.Add(
+				secret,
+				modN.Mul(xi, lagrangeCoefficient(ks, subset, member.id)),
+			)
+		}
+
+		publicKey := crypto.ScalarBaseMult(tecdsa.Curve, secret)
+		if !publicKey.Equals(members[0].privateKeyShare.Data().ECDSAPub) {
+			t.Errorf(
+				"refreshed key shares of members [%v-%v] do not "+
+					"reconstruct the wallet private key",
+				offset+1,
+				offset+honestThreshold,
+			)
+		}
+	}
+}
+
+func TestCombineShares_IncomingMessageCorrupted_WrongShare(t *testing.T) {
+	members, messages, err := initializeSharesCombiningMembersGroup(
+		dishonestThreshold,
+		groupSize,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	member := members[0]
+	otherMember := members[1]
+
+	// Re-encrypt a random share for the member in the message of the other
+	// member so the share is not consistent with the commitments.
+	for _, message := range messages {
+		if message.senderID != otherMember.id {
+			continue
+		}
+
+		wrongShare := tsslibcommon.GetRandomPositiveInt(
+			tecdsa.Curve.Params().N,
+		)
+		encryptedShare, err := otherMember.symmetricKeys[member.id].Encrypt(
+			wrongShare.Bytes(),
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		message.encryptedShares[member.id] = encryptedShare
+	}
+
+	_, err = member.combineShares(filterForSender(messages, member.id))
+
+	expectedErr := fmt.Sprintf(
+		"member [%v] sent share inconsistent with its commitments",
+		otherMember.id,
+	)
+	if err == nil || err.Error() != expectedErr {
+		t.Errorf(
+			"unexpected error\nexpected: [%v]\nactual:   [%v]",
+			expectedErr,
+			err,
+		)
+	}
+}
+
+func TestCombineShares_IncomingMessageCorrupted_WrongCommitments(t *testing.T) {
+	members, messages, err := initializeSharesCombiningMembersGroup(
+		dishonestThreshold,
+		groupSize,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	member := members[0]
+	otherMember := members[1]
+
+	for _, message := range messages {
+		if message.senderID == otherMember.id {
+			message.commitments = message.commitments[1:]
+		}
+	}
+
+	_, err = member.combineShares(filterForSender(messages, member.id))
+
+	expectedErr := fmt.Sprintf(
+		"member [%v] sent invalid commitments",
+		otherMember.id,
+	)
+	if err == nil || !strings.HasPrefix(err.Error(), expectedErr) {
+		t.Errorf(
+			"unexpected error\nexpected: [%v]\nactual:   [%v]",
+			expectedErr,
+			err,
+		)
+	}
+}
+
+func TestCombineShares_IncomingMessageMissing(t *testing.T) {
+	members, messages, err := initializeSharesCombiningMembersGroup(
+		dishonestThreshold,
+		groupSize,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	member := members[0]
+
+	_, err = member.combineShares(filterForSender(messages, member.id)[1:])
+
+	expectedErr := fmt.Sprintf(
+		"refresh requires shares from all group members; "+
+			"got [%v] share distribution messages",
+		groupSize-2,
+	)
+	if err == nil || err.Error() != expectedErr {
+		t.Errorf(
+			"unexpected error\nexpected: [%v]\nactual:   [%v]",
+			expectedErr,
+			err,
+		)
+	}
+}
+
+func TestVerifyPublicKeySharesConfirmations(t *testing.T) {
+	members, messages, err := initializeFinalizingMembersGroup(
+		dishonestThreshold,
+		groupSize,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, member := range members {
+		err := member.verifyPublicKeySharesConfirmations(
+			filterForSender(messages, member.id),
+		)
+		if err != nil {
+			t.Errorf("unexpected error: [%v]", err)
+		}
+
+		result := member.Result()
+		if result.PrivateKeyShare != member.refreshedPrivateKeyShare {
+			t.Errorf("unexpected result private key share")
+		}
+	}
+}
+
+func TestVerifyPublicKeySharesConfirmations_DigestMismatch(t *testing.T) {
+	members, messages, err := initializeFinalizingMembersGroup(
+		dishonestThreshold,
+		groupSize,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	member := members[0]
+	otherMember := members[1]
+
+	for _, message := range messages {
+		if message.senderID == otherMember.id {
+			message.publicKeySharesDigest = []byte{1, 2, 3}
+		}
+	}
+
+	err = member.verifyPublicKeySharesConfirmations(
+		filterForSender(messages, member.id),
+	)
+
+	expectedErr := fmt.Sprintf(
+		"member [%v] computed different refreshed public key shares",
+		otherMember.id,
+	)
+	if err == nil || err.Error() != expectedErr {
+		t.Errorf(
+			"unexpected error\nexpected: [%v]\nactual:   [%v]",
+			expectedErr,
+			err,
+		)
+	}
+}
+
+func TestEvaluatePolynomialAndCommitments(t *testing.T) {
+	coefficients := []*big.Int{big.NewInt(3), big.NewInt(5)}
+	x := big.NewInt(7)
+
+	// 3*7 + 5*7^2 = 266
+	value := evaluatePolynomial(coefficients, x)
+	testutils.AssertBigIntsEqual(t, "polynomial value", big.NewInt(266), value)
+
+	commitments := []*crypto.ECPoint{
+		crypto.ScalarBaseMult(tecdsa.Curve, coefficients[0]),
+		crypto.ScalarBaseMult(tecdsa.Curve, coefficients[1]),
+	}
+
+	point, err := evaluateCommitments(commitments, x)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !point.Equals(crypto.ScalarBaseMult(tecdsa.Curve, value)) {
+		t.Errorf("commitments evaluation does not match polynomial value")
+	}
+
+	if _, err := evaluateCommitments(nil, x); err == nil {
+		t.Errorf("expected error for empty commitments")
+	}
+}
+
+// lagrangeCoefficient computes the Lagrange coefficient at zero for the
+// given member and the given subset of members.
+func lagrangeCoefficient(
+	ks []*big.Int,
+	subset []*sharesCombiningMember,
+	memberID group.MemberIndex,
+) *big.Int {
+	modN := tsslibcommon.ModInt(tecdsa.Curve.Params().N)
+
+	xi := ks[memberID-1]
+	coefficient := big.NewInt(1)
+	for _, other := range subset {
+		if other.id == memberID {
+			continue
+		}
+
+		xj := ks[other.id-1]
+		coefficient = modN.Mul(
+			coefficient,
+			modN.Mul(xj, modN.ModInverse(modN.Sub(xj, xi))),
+		)
+	}
+
+	return coefficient
+}
+
+type senderMessage interface {
+	SenderID() group.MemberIndex
+}
+
+// filterForSender returns all messages except the ones sent by the given
+// member.
+func filterForSender[T senderMessage](
+	messages []T,
+	senderID group.MemberIndex,
+) []T {
+	var result []T
+	for _, message := range messages {
+		if message.SenderID() != senderID {
+			result = append(result, message)
+		}
+	}
+
+	return result
+}
+
+func initializeEphemeralKeyPairGeneratingMembersGroup(
+	dishonestThreshold int,
+	groupSize int,
+) ([]*ephemeralKeyPairGeneratingMember, error) {
+	refreshGroup := group.NewGroup(dishonestThreshold, groupSize)
+
+	testData, err := tecdsatest.LoadPrivateKeyShareTestFixtures(groupSize)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load test data: [%v]", err)
+	}
+
+	var members []*ephemeralKeyPairGeneratingMember
+	for i := 1; i <= groupSize; i++ {
+		id := group.MemberIndex(i)
+
+		members = append(members, &ephemeralKeyPairGeneratingMember{
+			member: &member{
+				logger:          &testutils.MockLogger{},
+				id:              id,
+				group:           refreshGroup,
+				sessionID:       sessionID,
+				privateKeyShare: tecdsa.NewPrivateKeyShare(testData[i-1]),
+			},
+			ephemeralKeyPairs: make(map[group.MemberIndex]*ephemeral.KeyPair),
+		})
+	}
+
+	return members, nil
+}
+
+func initializeSymmetricKeyGeneratingMembersGroup(
+	dishonestThreshold int,
+	groupSize int,
+) (
+	[]*symmetricKeyGeneratingMember,
+	[]*ephemeralPublicKeyMessage,
+	error,
+) {
+	var symmetricKeyGeneratingMembers []*symmetricKeyGeneratingMember
+	var ephemeralPublicKeyMessages []*ephemeralPublicKeyMessage
+
+	ephemeralKeyPairGeneratingMembers, err :=
+		initializeEphemeralKeyPairGeneratingMembersGroup(
+			dishonestThreshold,
+			groupSize,
+		)
+	if err != nil {
+		return nil, nil, fmt.Errorf(
+			"cannot generate ephemeral key pair generating "+
+				"members group: [%v]",
+			err,
+		)
+	}
+
+	for _, member := range ephemeralKeyPairGeneratingMembers {
+		message, err := member.generateEphemeralKeyPair()
+		if err != nil {
+			return nil, nil, fmt.Errorf(
+				"cannot generate ephemeral key pair for member [%v]: [%v]",
+				member.id,
+				err,
+			)
+		}
+
+		symmetricKeyGeneratingMembers = append(
+			symmetricKeyGeneratingMembers,
+			member.initializeSymmetricKeyGeneration(),
+		)
+		ephemeralPublicKeyMessages = append(ephemeralPublicKeyMessages, message)
+	}
+
+	return symmetricKeyGeneratingMembers, ephemeralPublicKeyMessages, nil
+}
+
+func initializeShareDistributingMembersGroup(
+	dishonestThreshold int,
+	groupSize int,
+) ([]*shareDistributingMember, error) {
+	symmetricKeyGeneratingMembers, ephemeralPublicKeyMessages, err :=
+		initializeSymmetricKeyGeneratingMembersGroup(
+			dishonestThreshold,
+			groupSize,
+		)
+	if err != nil {
+		return nil, fmt.Errorf(
+			"cannot generate symmetric key generating members group: [%v]",
+			err,
+		)
+	}
+
+	var shareDistributingMembers []*shareDistributingMember
+	for _, member := range symmetricKeyGeneratingMembers {
+		err := member.generateSymmetricKeys(
+			filterForSender(ephemeralPublicKeyMessages, member.id),
+		)
+		if err != nil {
+			return nil, fmt.Errorf(
+				"cannot generate symmetric keys for member [%v]: [%v]",
+				member.id,
+				err,
+			)
+		}
+
+		shareDistributingMembers = append(
+			shareDistributingMembers,
+			member.initializeShareDistribution(),
+		)
+	}
+
+	return shareDistributingMembers, nil
+}
+
+func initializeSharesCombiningMembersGroup(
+	dishonestThreshold int,
+	groupSize int,
+) (
+	[]*sharesCombiningMember,
+	[]*shareDistributionMessage,
+	error,
+) {
+	shareDistributingMembers, err := initializeShareDistributingMembersGroup(
+		dishonestThreshold,
+		groupSize,
+	)
+	if err != nil {
+		return nil, nil, fmt.Errorf(
+			"cannot generate share distributing members group: [%v]",
+			err,
+		)
+	}
+
+	var sharesCombiningMembers []*sharesCombiningMember
+	var shareDistributionMessages []*shareDistributionMessage
+	for _, member := range shareDistributingMembers {
+		message, err := member.distributeShares()
+		if err != nil {
+			return nil, nil, fmt.Errorf(
+				"cannot distribute shares of member [%v]: [%v]",
+				member.id,
+				err,
+			)
+		}
+
+		sharesCombiningMembers = append(
+			sharesCombiningMembers,
+			member.initializeSharesCombination(),
+		)
+		shareDistributionMessages = append(shareDistributionMessages, message)
+	}
+
+	return sharesCombiningMembers, shareDistributionMessages, nil
+}
+
+func initializeFinalizingMembersGroup(
+	dishonestThreshold int,
+	groupSize int,
+) (
+	[]*finalizingMember,
+	[]*publicKeySharesConfirmationMessage,
+	error,
+) {
+	sharesCombiningMembers, shareDistributionMessages, err :=
+		initializeSharesCombiningMembersGroup(
+			dishonestThreshold,
+			groupSize,
+		)
+	if err != nil {
+		return nil, nil, fmt.Errorf(
+			"cannot generate shares combining members group: [%v]",
+			err,
+		)
+	}
+
+	var finalizingMembers []*finalizingMember
+	var confirmationMessages []*publicKeySharesConfirmationMessage
+	for _, member := range sharesCombiningMembers {
+		message, err := member.combineShares(
+			filterForSender(shareDistributionMessages, member.id),
+		)
+		if err != nil {
+			return nil, nil, fmt.Errorf(
+				"cannot combine shares of member [%v]: [%v]",
+				member.id,
+				err,
+			)
+		}
+
+		finalizingMembers = append(
+			finalizingMembers,
+			member.initializeFinalization(),
+		)
+		confirmationMessages = append(confirmationMessages, message)
+	}
+
+	return finalizingMembers, confirmationMessages, nil
+}
@@ -0,0 +1,112 @@
+// Package refresh implements the proactive tECDSA key share refresh protocol.
+//
+// Each member of the signing group generates a random polynomial of the same
+// degree as the polynomial the wallet key is shared with, but with the
+// constant term equal to zero. Members exchange Feldman commitments to their
+// polynomials and encrypted polynomial evaluations. Each member adds all
+// evaluations received for them to their current key share and updates
+// public key shares of all members using the commitments. Since all refresh
+// polynomials share the zero constant term, the refreshed key shares
+// correspond to the same wallet public key while the old key shares become
+// useless when combined with the refreshed ones.
+//
+// The refresh changes key shares of all members at once so all members of the
+// signing group must participate. Members that did not take part in a
+// successful refresh end up with key shares incompatible with the rest of
+// the group.
+package refresh
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ipfs/go-log/v2"
+	"github.com/keep-network/keep-core/pkg/net"
+	"github.com/keep-network/keep-core/pkg/protocol/group"
+	"github.com/keep-network/keep-core/pkg/protocol/state"
+	"github.com/keep-network/keep-core/pkg/tecdsa"
+)
+
+// Execute runs the tECDSA key refresh protocol, given a broadcast channel to
+// mediate with, a member index to use in the group, private key share to
+// refresh, group size, and dishonest threshold. The dishonest threshold
+// must be the one the private key share was generated with, i.e. the
+// honest threshold of the group must match the signing threshold of the key.
+//
+// Unlike signing, the key refresh requires all members of the signing group
+// to participate and does not support member exclusion.
+func Execute(
+	ctx context.Context,
+	logger log.StandardLogger,
+	sessionID string,
+	memberIndex group.MemberIndex,
+	privateKeyShare *tecdsa.PrivateKeyShare,
+	groupSize int,
+	dishonestThreshold int,
+	channel net.BroadcastChannel,
+	membershipValidator *group.MembershipValidator,
+) (*Result, error) {
+	logger.Debugf("[member:%v] initializing member", memberIndex)
+
+	if sharesCount := len(privateKeyShare.Data().Ks); sharesCount != groupSize {
+		return nil, fmt.Errorf(
+			"private key share was generated for [%v] members "+
+				"but the group size is [%v]",
+			sharesCount,
+			groupSize,
+		)
+	}
+
+	member := newMember(
+		logger,
+		memberIndex,
+		groupSize,
+		dishonestThreshold,
+		membershipValidator,
+		sessionID,
+		privateKeyShare,
+	)
+
+	if member.polynomialDegree() < 1 {
+		return nil, fmt.Errorf(
+			"key refresh requires honest threshold of at least 2; "+
+				"honest threshold is [%v]",
+			member.group.HonestThreshold(),
+		)
+	}
+
+	initialState := &ephemeralKeyPairGenerationState{
+		BaseAsyncState: state.NewBaseAsyncState(),
+		channel:        channel,
+		member:         member.initializeEphemeralKeysGeneration(),
+	}
+
+	stateMachine := state.NewAsyncMachine(logger, ctx, channel, initialState)
+
+	lastState, err := stateMachine.Execute()
+	if err != nil {
+		return nil, err
+	}
+
+	finalizationState, ok := lastState.(*finalizationState)
+	if !ok {
+		return nil, fmt.Errorf("execution ended on state: %T", lastState)
+	}
+
+	return finalizationState.result(), nil
+}
+
+// RegisterUnmarshallers initializes the given broadcast channel to be able to
+// perform key refresh protocol interactions by registering all the required
+// protocol message unmarshallers.
+func RegisterUnmarshallers(channel net.BroadcastChannel) {
+	channel.SetUnmarshaler(func() net.TaggedUnmarshaler {
+		return &ephemeralPublicKeyMessage{}
+	})
+	channel.SetUnmarshaler(func() net.TaggedUnmarshaler {
+		return &shareDistributionMessage{}
+	})
+	channel.SetUnmarshaler(func() net.TaggedUnmarshaler {
+		return &publicKeySharesConfirmationMessage{}
+	})
+}
@@ -0,0 +1,12 @@
+package refresh
+
+import (
+	"github.com/keep-network/keep-core/pkg/tecdsa"
+)
+
+// Result of the tECDSA key refresh protocol.
+type Result struct {
+	// PrivateKeyShare is the refreshed tECDSA private key share of the member.
+	// It corresponds to the same tECDSA public key as the original key share.
+	PrivateKeyShare *tecdsa.PrivateKeyShare
+}
@@ -0,0 +1,278 @@
+package refresh
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/keep-network/keep-core/pkg/net"
+	"github.com/keep-network/keep-core/pkg/protocol/group"
+	"github.com/keep-network/keep-core/pkg/protocol/state"
+)
+
+// ephemeralKeyPairGenerationState is the state during which members broadcast
+// public ephemeral keys generated for other members of the group.
+// `ephemeralPublicKeyMessage`s are valid in this state.
+type ephemeralKeyPairGenerationState struct {
+	*state.BaseAsyncState
+
+	channel net.BroadcastChannel
+	member  *ephemeralKeyPairGeneratingMember
+}
+
+func (ekpgs *ephemeralKeyPairGenerationState) Initiate(ctx context.Context) error {
+	message, err := ekpgs.member.generateEphemeralKeyPair()
+	if err != nil {
+		return err
+	}
+
+	if err := ekpgs.channel.Send(ctx, message, net.BackoffRetransmissionStrategy); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (ekpgs *ephemeralKeyPairGenerationState) Receive(netMessage net.Message) error {
+	if protocolMessage, ok := netMessage.Payload().(message); ok {
+		if ekpgs.member.shouldAcceptMessage(
+			protocolMessage.SenderID(),
+			netMessage.SenderPublicKey(),
+		) && ekpgs.member.sessionID == protocolMessage.SessionID() {
+			ekpgs.ReceiveToHistory(netMessage)
+		}
+	}
+
+	return nil
+}
+
+func (ekpgs *ephemeralKeyPairGenerationState) CanTransition() bool {
+	messagingDone := len(receivedMessages[*ephemeralPublicKeyMessage](ekpgs.BaseAsyncState)) ==
+		len(ekpgs.member.group.OperatingMemberIndexes())-1
+
+	return messagingDone
+}
+
+func (ekpgs *ephemeralKeyPairGenerationState) Next() (state.AsyncState, error) {
+	return &symmetricKeyGenerationState{
+		BaseAsyncState: ekpgs.BaseAsyncState,
+		channel:        ekpgs.channel,
+		member:         ekpgs.member.initializeSymmetricKeyGeneration(),
+	}, nil
+}
+
+func (ekpgs *ephemeralKeyPairGenerationState) MemberIndex() group.MemberIndex {
+	return ekpgs.member.id
+}
+
+// symmetricKeyGenerationState is the state during which members compute
+// symmetric keys from the previously exchanged ephemeral public keys.
+// No messages are valid in this state.
+type symmetricKeyGenerationState struct {
+	*state.BaseAsyncState
+
+	channel net.BroadcastChannel
+	member  *symmetricKeyGeneratingMember
+}
+
+func (skgs *symmetricKeyGenerationState) Initiate(ctx context.Context) error {
+	return skgs.member.generateSymmetricKeys(
+		receivedMessages[*ephemeralPublicKeyMessage](skgs.BaseAsyncState),
+	)
+}
+
+func (skgs *symmetricKeyGenerationState) Receive(netMessage net.Message) error {
+	if protocolMessage, ok := netMessage.Payload().(message); ok {
+		if skgs.member.shouldAcceptMessage(
+			protocolMessage.SenderID(),
+			netMessage.SenderPublicKey(),
+		) && skgs.member.sessionID == protocolMessage.SessionID() {
+			skgs.ReceiveToHistory(netMessage)
+		}
+	}
+
+	return nil
+}
+
+func (skgs *symmetricKeyGenerationState) CanTransition() bool {
+	return true
+}
+
+func (skgs *symmetricKeyGenerationState) Next() (state.AsyncState, error) {
+	return &shareDistributionState{
+		BaseAsyncState: skgs.BaseAsyncState,
+		channel:        skgs.channel,
+		member:         skgs.member.initializeShareDistribution(),
+	}, nil
+}
+
+func (skgs *symmetricKeyGenerationState) MemberIndex() group.MemberIndex {
+	return skgs.member.id
+}
+
+// shareDistributionState is the state during which members broadcast
+// commitments to their refresh polynomials along with encrypted polynomial
+// evaluations for other members.
+// `shareDistributionMessage`s are valid in this state.
+type shareDistributionState struct {
+	*state.BaseAsyncState
+
+	channel net.BroadcastChannel
+	member  *shareDistributingMember
+}
+
+func (sds *shareDistributionState) Initiate(ctx context.Context) error {
+	message, err := sds.member.distributeShares()
+	if err != nil {
+		return err
+	}
+
+	if err := sds.channel.Send(ctx, message, net.BackoffRetransmissionStrategy); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (sds *shareDistributionState) Receive(netMessage net.Message) error {
+	if protocolMessage, ok := netMessage.Payload().(message); ok {
+		if sds.member.shouldAcceptMessage(
+			protocolMessage.SenderID(),
+			netMessage.SenderPublicKey(),
+		) && sds.member.sessionID == protocolMessage.SessionID() {
+			sds.ReceiveToHistory(netMessage)
+		}
+	}
+
+	return nil
+}
+
+func (sds *shareDistributionState) CanTransition() bool {
+	messagingDone := len(receivedMessages[*shareDistributionMessage](sds.BaseAsyncState)) ==
+		len(sds.member.group.OperatingMemberIndexes())-1
+
+	return messagingDone
+}
+
+func (sds *shareDistributionState) Next() (state.AsyncState, error) {
+	return &sharesCombinationState{
+		BaseAsyncState: sds.BaseAsyncState,
+		channel:        sds.channel,
+		member:         sds.member.initializeSharesCombination(),
+	}, nil
+}
+
+func (sds *shareDistributionState) MemberIndex() group.MemberIndex {
+	return sds.member.id
+}
+
+// sharesCombinationState is the state during which members verify received
+// shares, compute their refreshed key shares and broadcast the digest of
+// refreshed public key shares of the group.
+// `publicKeySharesConfirmationMessage`s are valid in this state.
+type sharesCombinationState struct {
+	*state.BaseAsyncState
+
+	channel net.BroadcastChannel
+	member  *sharesCombiningMember
+}
+
+func (scs *sharesCombinationState) Initiate(ctx context.Context) error {
+	message, err := scs.member.combineShares(
+		receivedMessages[*shareDistributionMessage](scs.BaseAsyncState),
+	)
+	if err != nil {
+		return err
+	}
+
+	if err := scs.channel.Send(ctx, message, net.BackoffRetransmissionStrategy); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (scs *sharesCombinationState) Receive(netMessage net.Message) error {
+	if protocolMessage, ok := netMessage.Payload().(message); ok {
+		if scs.member.shouldAcceptMessage(
+			protocolMessage.SenderID(),
+			netMessage.SenderPublicKey(),
+		) && scs.member.sessionID == protocolMessage.SessionID() {
+			scs.ReceiveToHistory(netMessage)
+		}
+	}
+
+	return nil
+}
+
+func (scs *sharesCombinationState) CanTransition() bool {
+	messagingDone := len(receivedMessages[*publicKeySharesConfirmationMessage](scs.BaseAsyncState)) ==
+		len(scs.member.group.OperatingMemberIndexes())-1
+
+	return messagingDone
+}
+
+func (scs *sharesCombinationState) Next() (state.AsyncState, error) {
+	return &finalizationState{
+		BaseAsyncState: scs.BaseAsyncState,
+		channel:        scs.channel,
+		member:         scs.member.initializeFinalization(),
+	}, nil
+}
+
+func (scs *sharesCombinationState) MemberIndex() group.MemberIndex {
+	return scs.member.id
+}
+
+// finalizationState is the last state of the key refresh protocol - in this
+// state, key refresh is completed. No messages are valid in this state.
+//
+// State prepares a result that is returned to the caller.
+type finalizationState struct {
+	*state.BaseAsyncState
+
+	channel net.BroadcastChannel
+	member  *finalizingMember
+}
+
+func (fs *finalizationState) Initiate(ctx context.Context) error {
+	return fs.member.verifyPublicKeySharesConfirmations(
+		receivedMessages[*publicKeySharesConfirmationMessage](fs.BaseAsyncState),
+	)
+}
+
+func (fs *finalizationState) Receive(net.Message) error {
+	return nil
+}
+
+func (fs *finalizationState) CanTransition() bool {
+	return true
+}
+
+func (fs *finalizationState) Next() (state.AsyncState, error) {
+	return nil, nil
+}
+
+func (fs *finalizationState) MemberIndex() group.MemberIndex {
+	return fs.member.id
+}
+
+func (fs *finalizationState) result() *Result {
+	return fs.member.Result()
+}
+
+// receivedMessages returns all messages of type T that have been received
+// and validated so far. Returned messages are deduplicated so there is a
+// guarantee that only one message of the given type is returned for the
+// given sender.
+func receivedMessages[T message](base *state.BaseAsyncState) []T {
+	var messageTemplate T
+
+	payloads := state.ExtractMessagesPayloads[T](base, messageTemplate.Type())
+
+	return state.DeduplicateMessagesPayloads(
+		payloads,
+		func(message T) string {
+			return strconv.Itoa(int(message.SenderID()))
+		},
+	)
+}